@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/willfong/load-generator/internal/generator"
+	"github.com/willfong/load-generator/internal/ui"
+)
+
+var (
+	benchScales  string
+	benchWorkers string
+	benchYears   int
+	benchSeed    int64
+	benchDir     string
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure generation throughput at several scales",
+	Long: `Benchmark data generation without a database.
+
+Runs a full generation (entities, transactions and audit logs) for every
+combination of --scales and --workers into a temporary directory that is
+removed afterwards, and reports for each table the rows written, rows per
+second over the phase that wrote it and output size, plus each run's peak
+memory. Use it to size runs and to compare builds or machines.
+
+Peak memory is the most memory the Go runtime held from the OS during the run
+(sampled every 100ms), not counting memory it has already returned.
+
+Examples:
+  loadgen bench
+  loadgen bench --scales 1000,10000,100000 --workers 1,4,8
+  loadgen bench --scales 50000 --years 3 --dir /mnt/scratch`,
+	Run: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringVar(&benchScales, "scales", "1000,10000", "comma-separated customer counts to generate")
+	benchCmd.Flags().StringVar(&benchWorkers, "workers", "0", "comma-separated worker counts to run each scale with (0 = auto-detect CPUs)")
+	benchCmd.Flags().IntVar(&benchYears, "years", 1, "years of historical data to generate")
+	benchCmd.Flags().Int64Var(&benchSeed, "seed", 42, "random seed, so every run generates the same data")
+	benchCmd.Flags().StringVar(&benchDir, "dir", "", "directory to create each run's temporary output directory in (default: the system temp directory)")
+}
+
+// benchTable is one table's result in a bench run
+type benchTable struct {
+	name  string
+	rows  int
+	bytes int64
+	phase time.Duration // duration of the phase that wrote the table
+}
+
+// benchRun is the result of one bench run
+type benchRun struct {
+	customers  int
+	workers    int
+	entities   time.Duration
+	txns       time.Duration
+	audit      time.Duration
+	peakMemory uint64
+	tables     []benchTable
+}
+
+func runBench(cmd *cobra.Command, args []string) {
+	u := newUI()
+
+	scales, err := parseBenchList("scales", benchScales, 1)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+		os.Exit(1)
+	}
+	workerCounts, err := parseBenchList("workers", benchWorkers, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+		os.Exit(1)
+	}
+	if benchYears < 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--years must be at least 1"))
+		os.Exit(1)
+	}
+
+	u.Println(u.Header("Bank-in-a-Box Generation Benchmark"))
+	u.Println()
+	u.Println(u.KeyValue("Scales", benchScales+" customers"))
+	u.Println(u.KeyValue("Workers", benchWorkers))
+	u.Println(u.KeyValue("History", fmt.Sprintf("%d years", benchYears)))
+	u.Println(u.KeyValue("Seed", fmt.Sprintf("%d", benchSeed)))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	for _, customers := range scales {
+		for _, workers := range workerCounts {
+			run, err := runBenchOnce(ctx, customers, workers)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("%d customers, %d workers: %v", customers, workers, err)))
+				os.Exit(1)
+			}
+			printBenchRun(u, run)
+		}
+	}
+}
+
+// parseBenchList parses a comma-separated list of counts of at least min
+func parseBenchList(flag, s string, min int) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < min {
+			return nil, fmt.Errorf("invalid --%s value %q (expected comma-separated integers of at least %d)", flag, part, min)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// runBenchOnce generates a data set with default settings into a temporary
+// directory, timing each phase and sampling memory use
+func runBenchOnce(ctx context.Context, customers, workers int) (*benchRun, error) {
+	dir, err := os.MkdirTemp(benchDir, "loadgen-bench-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// Generate's defaults, with the entity counts it derives from the customer count
+	cfg := defaultOrchestratorConfig(customers)
+	cfg.YearsOfHistory = benchYears
+	cfg.OutputDir = dir
+	cfg.Seed = benchSeed
+	cfg.Workers = workers
+	orchestrator, err := generator.NewOrchestrator(cfg, generator.OrchestratorOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	run := &benchRun{customers: customers, workers: generator.GetWorkerCount(workers)}
+	runtime.GC()
+	sampler := startMemorySampler()
+
+	entities, err := timePhase(ctx, orchestrator.GenerateEntities, &run.entities)
+	if err != nil {
+		sampler.stop()
+		return nil, err
+	}
+	txns, err := timePhase(ctx, orchestrator.GenerateTransactions, &run.txns)
+	if err != nil {
+		sampler.stop()
+		return nil, err
+	}
+	audit, err := timePhase(ctx, orchestrator.GenerateAuditLogs, &run.audit)
+	run.peakMemory = sampler.stop()
+	if err != nil {
+		return nil, err
+	}
+
+	// Transaction audit events are written while generating transactions
+	rows := map[string]int{
+		"branches":      entities.BranchCount,
+		"atms":          entities.ATMCount,
+		"atm_events":    entities.ATMEventCount,
+		"customers":     entities.CustomerCount,
+		"businesses":    entities.BusinessCount,
+		"accounts":      entities.AccountCount,
+		"beneficiaries": entities.BeneficiaryCount,
+		"transactions":  txns.TransactionCount,
+		"transfers":     txns.TransferCount,
+		"disputes":      txns.DisputeCount,
+		"audit_logs":    txns.AuditLogCount + audit.AuditLogCount,
+	}
+	for _, table := range statsTables {
+		files := statsFiles(dir, table)
+		if len(files) == 0 {
+			continue
+		}
+		t := benchTable{name: table, rows: rows[table], phase: run.tablePhase(table)}
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil {
+				t.bytes += info.Size()
+			}
+		}
+		run.tables = append(run.tables, t)
+	}
+	return run, nil
+}
+
+// tablePhase returns how long the phase that wrote table took. Transaction
+// audit events are written with the transactions, so audit logs span both.
+func (run *benchRun) tablePhase(table string) time.Duration {
+	switch table {
+	case "transactions", "transfers", "disputes":
+		return run.txns
+	case "audit_logs":
+		return run.txns + run.audit
+	default:
+		return run.entities
+	}
+}
+
+// timePhase runs one generation phase, recording how long it took
+func timePhase(ctx context.Context, phase func(context.Context) (*generator.GenerationResult, error), elapsed *time.Duration) (*generator.GenerationResult, error) {
+	start := time.Now()
+	result, err := phase(ctx)
+	*elapsed = time.Since(start)
+	return result, err
+}
+
+// memorySampler tracks the most memory the runtime holds from the OS
+type memorySampler struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+	peak uint64
+}
+
+func startMemorySampler() *memorySampler {
+	s := &memorySampler{done: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			s.sample()
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+func (s *memorySampler) sample() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	s.peak = max(s.peak, m.Sys-m.HeapReleased)
+}
+
+// stop ends sampling and returns the peak in bytes
+func (s *memorySampler) stop() uint64 {
+	close(s.done)
+	s.wg.Wait()
+	s.sample()
+	return s.peak
+}
+
+// printBenchRun prints one run's phase timings and per-table results
+func printBenchRun(u *ui.UI, run *benchRun) {
+	u.Section(fmt.Sprintf("%d customers, %d workers", run.customers, run.workers))
+	u.Println(u.KeyValue("Entities", formatDuration(run.entities)))
+	u.Println(u.KeyValue("Transactions", formatDuration(run.txns)))
+	u.Println(u.KeyValue("Audit logs", formatDuration(run.audit)))
+	u.Println(u.KeyValue("Total", formatDuration(run.entities+run.txns+run.audit)))
+	u.Println(u.KeyValue("Peak memory", ui.FormatBytes(int64(run.peakMemory))))
+	u.Println()
+
+	u.Println(fmt.Sprintf("  %-14s %12s %12s %12s", "table", "rows", "rows/sec", "size"))
+	var totalRows int
+	var totalBytes int64
+	for _, t := range run.tables {
+		u.Println(fmt.Sprintf("  %-14s %12d %12s %12s", t.name, t.rows, benchRate(t.rows, t.phase), ui.FormatBytes(t.bytes)))
+		totalRows += t.rows
+		totalBytes += t.bytes
+	}
+	u.Println(fmt.Sprintf("  %-14s %12d %12s %12s", "total", totalRows,
+		benchRate(totalRows, run.entities+run.txns+run.audit), ui.FormatBytes(totalBytes)))
+}
+
+// benchRate formats rows per second over a duration
+func benchRate(rows int, d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return formatNumber(int64(float64(rows) / d.Seconds()))
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseBenchList(t *testing.T) {
+	got, err := parseBenchList("scales", "1000, 10000,50000", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1000, 10000, 50000}; !slices.Equal(got, want) {
+		t.Errorf("parseBenchList = %v, want %v", got, want)
+	}
+	if got, err := parseBenchList("workers", "0,4", 0); err != nil || !slices.Equal(got, []int{0, 4}) {
+		t.Errorf("parseBenchList with min 0 = %v, %v", got, err)
+	}
+
+	for _, s := range []string{"", "1000,", "10k", "0", "-5"} {
+		if _, err := parseBenchList("scales", s, 1); err == nil {
+			t.Errorf("parseBenchList(%q) accepted an invalid list", s)
+		}
+	}
+}
+
+func TestBenchThroughput(t *testing.T) {
+	run := &benchRun{entities: 2 * time.Second, txns: 4 * time.Second, audit: time.Second}
+
+	// Each table's rate is over the phase that wrote it
+	for table, want := range map[string]time.Duration{
+		"customers":    2 * time.Second,
+		"atm_events":   2 * time.Second,
+		"transactions": 4 * time.Second,
+		"disputes":     4 * time.Second,
+		"audit_logs":   5 * time.Second,
+	} {
+		if got := run.tablePhase(table); got != want {
+			t.Errorf("tablePhase(%s) = %s, want %s", table, got, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		rows int
+		d    time.Duration
+		want string
+	}{
+		{500, time.Second, "500"},
+		{10000, 4 * time.Second, "2.5K"},
+		{3000000, 2 * time.Second, "1.5M"},
+		{100, 0, "-"},
+	} {
+		if got := benchRate(tc.rows, tc.d); got != tc.want {
+			t.Errorf("benchRate(%d, %s) = %q, want %q", tc.rows, tc.d, got, tc.want)
+		}
+	}
+}
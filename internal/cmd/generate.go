@@ -1,25 +1,98 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/willfong/load-generator/internal/config"
+	"github.com/willfong/load-generator/internal/data"
 	"github.com/willfong/load-generator/internal/generator"
+	"github.com/willfong/load-generator/internal/models"
 	"github.com/willfong/load-generator/internal/ui"
+	"github.com/willfong/load-generator/internal/utils"
 
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Generation parameters (frequently changed)
-	numCustomers int
-	numYears     int
-	outputDir    string
-	seed         int64
-	entitiesOnly bool
-	compress     bool
-	workers      int
+	numCustomers       int
+	numYears           int
+	outputDir          string
+	seed               int64
+	entitiesOnly       bool
+	onlyTables         string
+	compress           bool
+	compressLevel      int
+	workers            int
+	genTimeout         time.Duration
+	countryWeightsFile string
+	verifyBalances     bool
+	sortTransactions   bool
+	auditFromShards    bool
+	sessionTxnRate     float64
+	auditActions       string
+	excludeAudit       string
+	farFromHomeRate    float64
+	geoCoordinates     bool
+	declineRetryRate   float64
+	geoClustering      float64
+	offlineRate        float64
+	branchHours        bool
+	minorUnits         bool
+	rampUpMonths       int
+	interestDay        int
+	transferPayees     int
+	p2pRate            float64
+	retailWeekend      float64
+	businessWeekend    float64
+	kafkaBrokers       string
+	kafkaTopic         string
+	kafkaOnly          bool
+	avroOutput         bool
+	perTableDir        bool
+	partitionBy        string
+	granularity        string
+	asOfDate           string
+	atmEvents          bool
+	enableKYC          bool
+	transfers          bool
+	disputeRate        float64
+	continueFrom       string
+	accountMixFile     string
+	minAccounts        int
+	maxAccounts        int
+	minBeneficiaries   int
+	maxBeneficiaries   int
+	businessMix        string
+	foreignRate        float64
+	foreignCurrencies  string
+	transactionMixFile string
+	feeScheduleFile    string
+	whaleAccounts      int
+	whaleMultiplier    float64
+	monthlyCaps        string
+	settlementLags     string
+	atmDenominations   string
+	tableShards        int
+	maxFileRows        int64
+	summaryJSON        string
+	passwordHash       string
+	piiModeName        string
+	piiMappingFile     string
+	maxWriteRate       string
+	maxMemory          string
+	writeBuffer        string
+	flushInterval      time.Duration
+	startDate          string
+	endDate            string
 )
 
 // generateCmd represents the generate command
@@ -42,7 +115,8 @@ Transaction patterns and error rates are also configured there.
 Example:
   loadgen generate --customers 100000 --years 5
   loadgen generate --customers 10000 --entities   # Static data only
-  loadgen generate --seed 42                      # Reproducible`,
+  loadgen generate --seed 42                      # Reproducible
+  loadgen generate --continue-from ./output --years 1 --output ./output-2`,
 	Run: runGenerate,
 }
 
@@ -51,19 +125,79 @@ func init() {
 
 	generateCmd.Flags().IntVar(&numCustomers, "customers", 10000, "number of customers to generate")
 	generateCmd.Flags().IntVar(&numYears, "years", 3, "years of historical data to generate")
-	generateCmd.Flags().StringVar(&outputDir, "output", "./output", "output directory for CSV files")
+	generateCmd.Flags().StringVar(&outputDir, "output", "./output", "output directory for CSV files, or s3://bucket/prefix to upload directly")
 	generateCmd.Flags().Int64Var(&seed, "seed", 0, "random seed for reproducibility (0 = random)")
 	generateCmd.Flags().BoolVar(&entitiesOnly, "entities", false, "generate only static entities (no transactions)")
+	generateCmd.Flags().StringVar(&onlyTables, "only", "", "generate only these entity tables (e.g. branches,atms) and the tables they depend on; implies --entities")
 	generateCmd.Flags().BoolVar(&compress, "compress", false, "compress output with xz (creates .csv.xz files)")
+	generateCmd.Flags().IntVar(&compressLevel, "compress-level", config.CompressLevel, "with --compress, xz preset 0-9 (higher = smaller but slower), also used as the Avro deflate level 1-9")
 	generateCmd.Flags().IntVar(&workers, "workers", 0, "number of parallel workers (0 = auto-detect CPUs)")
+	generateCmd.Flags().StringVar(&maxMemory, "max-memory", "", "memory budget for generation workers (e.g. 8GB); fewer workers are used if they would not fit (default: available system memory)")
+	generateCmd.Flags().StringVar(&maxWriteRate, "max-write-rate", "", "cap transaction and audit log output at this rate: rows/sec (e.g. 50000) or uncompressed bytes/sec (e.g. 20MB)")
+	generateCmd.Flags().StringVar(&writeBuffer, "write-buffer", "", "write buffer per transaction and audit log shard (e.g. 4MB; default 1MB)")
+	generateCmd.Flags().DurationVar(&flushInterval, "flush-interval", 0, "flush transaction and audit log shards at least this often (e.g. 5s) so their files can be tailed; 0 = only when the buffer fills")
+	generateCmd.Flags().BoolVar(&perTableDir, "output-per-table-dir", false, "write each table's files to its own subdirectory (output/transactions/transactions_001.csv, ...)")
+	generateCmd.Flags().StringVar(&partitionBy, "partition-by", "none", "split transaction files into partition directories: none or month (output/transactions/2023-01/...)")
+	generateCmd.Flags().IntVar(&tableShards, "table-shards", 1, "split each entity table (branches, customers, accounts, ...) into this many CSV shards for parallel import")
+	generateCmd.Flags().Int64Var(&maxFileRows, "max-file-rows", 0, "roll each transaction and audit log shard over into numbered part files of at most this many rows (e.g. transactions_001_002.csv; 0 = unlimited)")
+	generateCmd.Flags().StringVar(&countryWeightsFile, "country-weights", "", "JSON file overriding country weights (e.g. {\"weights\": {\"US\": 90}, \"default_zero\": false})")
+	generateCmd.Flags().StringVar(&accountMixFile, "account-mix", "", "JSON file overriding optional account-type probabilities per segment (e.g. {\"regular\": {\"credit_card\": 0.9}})")
+	generateCmd.Flags().IntVar(&minAccounts, "min-accounts", 0, "minimum accounts per customer, checking included (0 = no minimum; --account-mix min_accounts overrides per segment)")
+	generateCmd.Flags().IntVar(&maxAccounts, "max-accounts", 0, "maximum accounts per customer, checking included (0 = no maximum; --account-mix max_accounts overrides per segment)")
+	generateCmd.Flags().IntVar(&minBeneficiaries, "min-beneficiaries", config.MinBeneficiaries, "fewest beneficiaries per customer (0 lets customers have none)")
+	generateCmd.Flags().IntVar(&maxBeneficiaries, "max-beneficiaries", config.MaxBeneficiaries, "most beneficiaries per customer")
+	generateCmd.Flags().StringVar(&businessMix, "business-mix", "", "fractions of businesses by type, the rest general (e.g. merchant=0.6,employer=0.2,government=0.05; unlisted types keep 0.4 employer, 0.35 merchant, 0.15 utility, 0.1 government)")
+	generateCmd.Flags().Float64Var(&foreignRate, "foreign-currency-rate", config.ForeignCurrencyRate, "fraction of checking, savings and investment accounts opened in a currency other than the customer's country's")
+	generateCmd.Flags().Float64Var(&geoClustering, "geo-clustering", config.GeoClustering, "clustering strength: fraction of customers living in one of the cities nearest their home branch instead of anywhere in its country (0 = none, 1 = all)")
+	generateCmd.Flags().Float64Var(&offlineRate, "offline-customer-rate", config.OfflineCustomerRate, "fraction of customers without online banking (empty username and password), whose sessions and transactions are only at ATMs and branches")
+	generateCmd.Flags().StringVar(&foreignCurrencies, "foreign-currencies", "", "currencies foreign-currency accounts are opened in (e.g. USD,EUR,GBP; default: all supported)")
+	generateCmd.Flags().StringVar(&feeScheduleFile, "fee-schedule", "", "JSON file overriding fee amounts (in cents) and weights per fee type (e.g. {\"wire\": {\"min_cents\": 2500, \"max_cents\": 3500}})")
+	generateCmd.Flags().StringVar(&transactionMixFile, "transaction-mix", "", "JSON file reweighting transaction types per account type (e.g. {\"checking\": [{\"type\": \"withdrawal\", \"channel\": \"atm\", \"weight\": 40}]})")
+	generateCmd.Flags().IntVar(&whaleAccounts, "whale-accounts", 0, "number of whale accounts (merchant accounts first) generating --whale-multiplier times the usual volume")
+	generateCmd.Flags().Float64Var(&whaleMultiplier, "whale-multiplier", config.WhaleMultiplier, "monthly volume multiplier for --whale-accounts")
+	generateCmd.Flags().StringVar(&settlementLags, "settlement-lags", "", "business days each channel takes to post and take value, replacing the defaults for the channels listed (e.g. ach=1,pos=2:0,wire=0; default "+generator.DefaultSettlementLags().String()+")")
+	generateCmd.Flags().StringVar(&atmDenominations, "atm-denominations", "", "note each currency's ATM withdrawals are multiples of, in whole units, replacing the defaults for the currencies listed (e.g. USD=20,EUR=50; 0 = any amount; default "+generator.DefaultATMDenominations().String()+")")
+	generateCmd.Flags().StringVar(&monthlyCaps, "monthly-caps", "", "most transactions per account per month by account type (e.g. merchant=500,business=200; whales scale their cap by --whale-multiplier)")
+	generateCmd.Flags().StringVar(&auditActions, "audit-actions", "", "write only these audit log actions (e.g. login_success,logout or session_*; default all)")
+	generateCmd.Flags().StringVar(&excludeAudit, "exclude-audit-actions", "", "leave these audit log actions out (e.g. transaction_initiated or balance_inquiry,history_viewed)")
+	generateCmd.Flags().Float64Var(&sessionTxnRate, "session-transaction-rate", 0, "fraction of online and ATM transactions made inside a login session whose session_id their audit events share (0 = none)")
+	generateCmd.Flags().BoolVar(&geoCoordinates, "geo-coordinates", false, "add latitude and longitude columns to transactions: the ATM's or branch's coordinates, or near the customer's home city for card and online payments")
+	generateCmd.Flags().Float64Var(&farFromHomeRate, "far-from-home-rate", config.FarFromHomeRate, "with --geo-coordinates, fraction of card and online transactions located in a random city instead of near the customer's home (impossible-travel outliers)")
+	generateCmd.Flags().IntVar(&transferPayees, "transfer-payees", 0, "payees per retail account among other customers' checking accounts, mostly at its home branch, that its transfers repeatedly go to and come from (0 = transfers only between a customer's own accounts)")
+	generateCmd.Flags().Float64Var(&p2pRate, "p2p-rate", config.TransferP2PRate, "with --transfer-payees, fraction of a retail account's transfers with its payees rather than its own accounts")
+	generateCmd.Flags().IntVar(&interestDay, "interest-posting-day", config.InterestPostingDay, "day of the month (1-28) interest-bearing accounts are posted the previous month's interest on their balance (0 = interest drawn at random from the transaction mix)")
+	generateCmd.Flags().IntVar(&rampUpMonths, "ramp-up-months", config.AccountRampUpMonths, "months a new account takes to reach its full transaction volume, rising linearly from its opening month (0 = full activity at once)")
+	generateCmd.Flags().BoolVar(&minorUnits, "currency-minor-units", config.CurrencyMinorUnits, "store balances and amounts in each currency's minor units (whole yen for JPY); false stores hundredths for every currency as before")
+	generateCmd.Flags().BoolVar(&branchHours, "branch-hours", false, "keep branch deposits and withdrawals within the branch's operating hours in its local time; out-of-hours ones move into that day's hours, or to the ATM or online when the branch is closed all day")
+	generateCmd.Flags().Float64Var(&declineRetryRate, "decline-retry-rate", config.DeclineRetryRate, "fraction of declined purchases followed seconds later by a successful retry of the same amount at the same merchant on another of the customer's cards or checking accounts")
+	generateCmd.Flags().Float64Var(&retailWeekend, "retail-weekend-volume", config.RetailWeekendVolume, "transactions per weekend day relative to a weekday for retail accounts (1 = the same)")
+	generateCmd.Flags().Float64Var(&businessWeekend, "business-weekend-volume", config.BusinessWeekendVolume, "transactions per weekend day relative to a weekday for business, merchant and payroll accounts (1 = the same)")
+	generateCmd.Flags().BoolVar(&verifyBalances, "verify-balances", false, "re-read transactions after generation and check running balances and limits")
+	generateCmd.Flags().BoolVar(&sortTransactions, "sort-transactions", false, "rewrite the transaction shards in timestamp order across shards after generation (external sort; slower, needs temporary disk space)")
+	generateCmd.Flags().BoolVar(&auditFromShards, "audit-from-shards", false, "write the transaction audit events in the audit phase by reading the transaction shards back, instead of alongside each transaction (needs a local --output)")
+	generateCmd.Flags().StringVar(&kafkaBrokers, "kafka-brokers", "", "also publish transactions as JSON to Kafka via kcat (comma-separated host:port)")
+	generateCmd.Flags().StringVar(&kafkaTopic, "kafka-topic", "transactions", "Kafka topic for --kafka-brokers")
+	generateCmd.Flags().BoolVar(&kafkaOnly, "kafka-only", false, "publish transactions to Kafka instead of writing transaction CSV shards")
+	generateCmd.Flags().BoolVar(&avroOutput, "avro", false, "also write transactions and audit logs as Avro object container files (.avro, deflate with --compress)")
+	generateCmd.Flags().StringVar(&granularity, "granularity", "monthly", "transaction generation period: monthly, weekly or daily (finer follows daily volume curves more closely)")
+	generateCmd.Flags().StringVar(&asOfDate, "as-of", "", "anchor history at this date (YYYY-MM-DD or RFC 3339) instead of now; with --seed output is reproducible")
+	generateCmd.Flags().StringVar(&startDate, "start-date", "", "start transaction history at this date (YYYY-MM-DD or RFC 3339) instead of --years before the end")
+	generateCmd.Flags().StringVar(&endDate, "end-date", "", "end transaction history at this date (YYYY-MM-DD or RFC 3339); same as --as-of")
+	generateCmd.Flags().StringVar(&passwordHash, "password-hash", "fast", "password hash scheme: fast (unsalted SHA-256), sha256 (salted) or bcrypt (slow, cost set in config)")
+	generateCmd.Flags().StringVar(&piiModeName, "pii-mode", "none", "pseudonymize names, emails, phones and addresses for sharing: none, tag (SYN- prefix, .invalid emails) or tokenize (format-preserving tokens)")
+	generateCmd.Flags().StringVar(&piiMappingFile, "pii-mapping", "", "with --pii-mode, write each pseudonymized value and its original to this CSV file (keep it out of the shared data set)")
+	generateCmd.Flags().BoolVar(&enableKYC, "kyc", false, "give customers a KYC status and identity document, with audit events for submissions and reviews; unverified customers get a single pending checking account")
+	generateCmd.Flags().BoolVar(&atmEvents, "atm-events", false, "also generate atm_events.csv with cash-low, offline and back-online events")
+	generateCmd.Flags().BoolVar(&transfers, "transfers", false, "also write transfers.csv shards with one row per internal transfer, referencing its debit and credit transactions")
+	generateCmd.Flags().Float64Var(&disputeRate, "dispute-rate", 0, "fraction of completed purchases later disputed, written to disputes.csv shards with each case's filed, review and resolution times (0 = none)")
+	generateCmd.Flags().StringVar(&continueFrom, "continue-from", "", "extend an existing output directory by --years: same entities, new transactions and audit logs continuing its IDs and final balances")
+	generateCmd.Flags().StringVar(&summaryJSON, "summary-json", "", "also write the run summary (counts, seed, parameters, file sizes) as JSON to this path")
+	generateCmd.Flags().DurationVar(&genTimeout, "timeout", 0, "abort generation after this duration (e.g., 30m). 0 = no limit")
 }
 
 func runGenerate(cmd *cobra.Command, args []string) {
 	// Initialize UI
-	u := ui.New()
-	if noColor {
-		u.SetNoColor(true)
-	}
+	u := newUI()
 
 	// Check xz availability if compression is requested
 	if compress {
@@ -74,94 +208,884 @@ func runGenerate(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	// Calculate derived counts from customer count
-	numBusinesses := int(float64(numCustomers) * config.BusinessRatio)
-	numBranches := int(float64(numCustomers) * config.BranchRatio)
-	numATMs := int(float64(numCustomers) * config.ATMRatio)
+	// Levels are per codec: xz presets run 0-9, Avro's deflate levels 1-9
+	if cmd.Flags().Changed("compress-level") {
+		switch {
+		case !compress:
+			fmt.Fprintln(os.Stderr, u.Error("--compress-level requires --compress"))
+			os.Exit(1)
+		case compressLevel < 0 || compressLevel > 9:
+			fmt.Fprintln(os.Stderr, u.Error("--compress-level must be an xz preset between 0 and 9"))
+			os.Exit(1)
+		case avroOutput && compressLevel < 1:
+			fmt.Fprintln(os.Stderr, u.Error("--compress-level must be between 1 and 9 with --avro (deflate levels start at 1)"))
+			os.Exit(1)
+		}
+	}
+
+	// s3:// outputs are uploaded through the aws CLI
+	if generator.IsS3Path(outputDir) {
+		if err := generator.CheckAWSCLIAvailable(); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error("S3 output requested but the aws CLI is not available"))
+			fmt.Fprintln(os.Stderr, "Install from https://aws.amazon.com/cli/ and configure credentials")
+			os.Exit(1)
+		}
+		if verifyBalances {
+			fmt.Fprintln(os.Stderr, u.Error("--verify-balances reads output back and requires a local --output directory"))
+			os.Exit(1)
+		}
+		if sortTransactions {
+			fmt.Fprintln(os.Stderr, u.Error("--sort-transactions rewrites output files and requires a local --output directory"))
+			os.Exit(1)
+		}
+		if auditFromShards {
+			fmt.Fprintln(os.Stderr, u.Error("--audit-from-shards reads output back and requires a local --output directory"))
+			os.Exit(1)
+		}
+	}
+
+	// Kafka publishing goes through kcat
+	var kafka *generator.KafkaConfig
+	if kafkaBrokers != "" {
+		if err := generator.CheckKcatAvailable(); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error("Kafka output requested but kcat is not available"))
+			fmt.Fprintln(os.Stderr, "Install with: apt install kcat (Linux) or brew install kcat (macOS)")
+			os.Exit(1)
+		}
+		kafka = &generator.KafkaConfig{Brokers: kafkaBrokers, Topic: kafkaTopic, Only: kafkaOnly}
+	} else if kafkaOnly {
+		fmt.Fprintln(os.Stderr, u.Error("--kafka-only requires --kafka-brokers"))
+		os.Exit(1)
+	}
+	if kafkaOnly && verifyBalances {
+		fmt.Fprintln(os.Stderr, u.Error("--verify-balances needs transaction CSV shards and cannot be used with --kafka-only"))
+		os.Exit(1)
+	}
+	if kafkaOnly && sortTransactions {
+		fmt.Fprintln(os.Stderr, u.Error("--sort-transactions needs transaction CSV shards and cannot be used with --kafka-only"))
+		os.Exit(1)
+	}
+	if kafkaOnly && auditFromShards {
+		fmt.Fprintln(os.Stderr, u.Error("--audit-from-shards needs transaction CSV shards and cannot be used with --kafka-only"))
+		os.Exit(1)
+	}
 
-	// Ensure minimums
-	if numBusinesses < 10 {
-		numBusinesses = 10
+	if tableShards < 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--table-shards must be at least 1"))
+		os.Exit(1)
 	}
-	if numBranches < 5 {
-		numBranches = 5
+
+	if maxFileRows < 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--max-file-rows cannot be negative"))
+		os.Exit(1)
 	}
-	if numATMs < 10 {
-		numATMs = 10
+
+	txnGranularity, err := generator.ParseGranularity(granularity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+		os.Exit(1)
 	}
 
-	fmt.Println(u.Header("Bank-in-a-Box Data Generator"))
-	fmt.Println()
-	fmt.Println(u.KeyValue("Customers", fmt.Sprintf("%d", numCustomers)))
-	fmt.Println(u.KeyValue("Businesses", fmt.Sprintf("%d (%.0f%% of customers)", numBusinesses, config.BusinessRatio*100)))
-	fmt.Println(u.KeyValue("Branches", fmt.Sprintf("%d", numBranches)))
-	fmt.Println(u.KeyValue("ATMs", fmt.Sprintf("%d", numATMs)))
-	fmt.Println(u.KeyValue("Years", fmt.Sprintf("%d", numYears)))
-	fmt.Println(u.KeyValue("Output", outputDir))
-	if seed != 0 {
-		fmt.Println(u.KeyValue("Seed", fmt.Sprintf("%d", seed)))
+	txnPartitioning, err := generator.ParsePartitioning(partitionBy)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+		os.Exit(1)
+	}
+
+	passwordScheme, err := generator.ParsePasswordScheme(passwordHash)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+		os.Exit(1)
+	}
+
+	if foreignRate < 0 || foreignRate > 1 {
+		fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("--foreign-currency-rate %g must be between 0 and 1", foreignRate)))
+		os.Exit(1)
+	}
+	var foreignCurrencyList []models.Currency
+	if foreignCurrencies != "" {
+		if foreignCurrencyList, err = generator.ParseCurrencies(foreignCurrencies); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	var only generator.EntitySelection
+	if onlyTables != "" {
+		if only, err = generator.ParseEntitySelection(onlyTables); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		entitiesOnly = true
+	}
+
+	piiMode, err := generator.ParsePIIMode(piiModeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+		os.Exit(1)
+	}
+	if piiMappingFile != "" && piiMode == generator.PIIModeNone {
+		fmt.Fprintln(os.Stderr, u.Error("--pii-mapping requires --pii-mode tag or tokenize"))
+		os.Exit(1)
+	}
+
+	var memoryBudget int64
+	if maxMemory != "" {
+		if memoryBudget, err = generator.ParseMemorySize(maxMemory); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	var writeBufferSize int64
+	if writeBuffer != "" {
+		if writeBufferSize, err = generator.ParseMemorySize(writeBuffer); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+	}
+	if flushInterval < 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--flush-interval cannot be negative"))
+		os.Exit(1)
+	}
+
+	var writeLimiter *generator.WriteLimiter
+	var writeRate generator.WriteRate
+	if maxWriteRate != "" {
+		if writeRate, err = generator.ParseWriteRate(maxWriteRate); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		writeLimiter = generator.NewWriteLimiter(writeRate)
+	}
+
+	if asOfDate != "" && endDate != "" {
+		fmt.Fprintln(os.Stderr, u.Error("--end-date and --as-of both set the end of history; use one"))
+		os.Exit(1)
+	}
+	var asOf time.Time
+	if asOfDate != "" {
+		if asOf, err = parseDateFlag("as-of", asOfDate); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+	}
+	if endDate != "" {
+		if asOf, err = parseDateFlag("end-date", endDate); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+	}
+	var historyStart time.Time
+	if startDate != "" {
+		if historyStart, err = parseDateFlag("start-date", startDate); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		end := asOf
+		if end.IsZero() {
+			end = time.Now()
+		}
+		if !historyStart.Before(end) {
+			fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("--start-date %s must be before the end of history (%s)",
+				historyStart.Format("2006-01-02"), end.Format("2006-01-02"))))
+			os.Exit(1)
+		}
+	}
+
+	// Calculate derived counts from customer count
+	numBusinesses, numBranches, numATMs := derivedEntityCounts(numCustomers)
+
+	// A continuation keeps the existing data set's entities and picks up at its as-of date
+	var continuation *generator.Continuation
+	var countryWeights *data.CountryWeights
+	var accountMix map[models.CustomerSegment]generator.AccountMix
+	var bizMix *generator.BusinessMix
+	var txnMix map[models.AccountType][]generator.TransactionTypeWeight
+	var feeSchedule generator.FeeSchedule
+	var whaleIDs []int64
+	var txnCaps generator.MonthlyCaps
+	lags := generator.DefaultSettlementLags()
+	denominations := generator.DefaultATMDenominations()
+	weekendVolume := &generator.WeekendVolume{Retail: retailWeekend, Business: businessWeekend}
+	if continueFrom != "" {
+		continuation, err = loadContinuation(cmd)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		m := continuation.Manifest
+		numCustomers, numBusinesses, numBranches, numATMs = m.NumCustomers, m.NumBusinesses, m.NumBranches, m.NumATMs
+		countryWeights = m.CountryWeights
+		accountMix = m.AccountMix
+		bizMix = m.BusinessMix
+		txnMix = m.TransactionMix
+		feeSchedule = m.FeeSchedule
+		if !cmd.Flags().Changed("whale-accounts") {
+			whaleIDs = m.WhaleAccounts
+		}
+		if !cmd.Flags().Changed("whale-multiplier") && m.WhaleMultiplier > 0 {
+			whaleMultiplier = m.WhaleMultiplier
+		}
+		if !cmd.Flags().Changed("monthly-caps") {
+			txnCaps = m.MonthlyCaps
+		}
+		if !cmd.Flags().Changed("settlement-lags") {
+			lags = m.SettlementLags
+		}
+		if !cmd.Flags().Changed("atm-denominations") {
+			denominations = m.ATMDenominations
+		}
+		if !cmd.Flags().Changed("audit-actions") && !cmd.Flags().Changed("exclude-audit-actions") {
+			auditActions = strings.Join(m.AuditActions, ",")
+		}
+		if !cmd.Flags().Changed("retail-weekend-volume") && !cmd.Flags().Changed("business-weekend-volume") {
+			weekendVolume = m.WeekendVolume
+		}
+		geoClustering = m.GeoClustering
+		offlineRate = m.OfflineCustomerRate
+		if m.MaxBeneficiaries > 0 {
+			minBeneficiaries, maxBeneficiaries = m.MinBeneficiaries, m.MaxBeneficiaries
+		}
+		if fc := m.ForeignCurrency; fc != nil {
+			foreignRate, foreignCurrencyList = fc.Rate, fc.Currencies
+			codes := make([]string, len(fc.Currencies))
+			for i, c := range fc.Currencies {
+				codes[i] = string(c)
+			}
+			foreignCurrencies = strings.Join(codes, ",")
+		}
+		minorUnits = m.MinorUnits
+		if !cmd.Flags().Changed("ramp-up-months") {
+			rampUpMonths = m.RampUpMonths
+		}
+		if !cmd.Flags().Changed("interest-posting-day") {
+			interestDay = m.InterestPostingDay
+		}
+		if !cmd.Flags().Changed("transfer-payees") && !cmd.Flags().Changed("p2p-rate") && m.TransferGraph != nil {
+			transferPayees, p2pRate = m.TransferGraph.Payees, m.TransferGraph.P2PRate
+		}
+		if !cmd.Flags().Changed("branch-hours") {
+			branchHours = m.BranchHours
+		}
+		if !cmd.Flags().Changed("geo-coordinates") {
+			geoCoordinates = m.GeoCoordinates
+		}
+		piiMode = m.PIIMode
+		enableKYC = m.KYC != nil
+		asOf = m.AsOfDate.AddDate(numYears, 0, 0)
+	}
+
+	u.Println(u.Header("Bank-in-a-Box Data Generator"))
+	u.Println()
+	u.Println(u.KeyValue("Customers", fmt.Sprintf("%d", numCustomers)))
+	u.Println(u.KeyValue("Businesses", fmt.Sprintf("%d (%.0f%% of customers)", numBusinesses, config.BusinessRatio*100)))
+	u.Println(u.KeyValue("Branches", fmt.Sprintf("%d", numBranches)))
+	u.Println(u.KeyValue("ATMs", fmt.Sprintf("%d", numATMs)))
+	if historyStart.IsZero() {
+		u.Println(u.KeyValue("Years", fmt.Sprintf("%d", numYears)))
+	} else {
+		u.Println(u.KeyValue("Start date", historyStart.Format(time.RFC3339)))
+	}
+	if !asOf.IsZero() {
+		u.Println(u.KeyValue("As of", asOf.Format(time.RFC3339)))
+	}
+	if continuation != nil {
+		u.Println(u.KeyValue("Continuing", fmt.Sprintf("%s from %s (after transaction %d)",
+			continuation.Dir, continuation.Manifest.AsOfDate.Format(time.RFC3339), continuation.LastTransactionID)))
+	}
+	u.Println(u.KeyValue("Output", outputDir))
+	// Resolve seed 0 to a random seed up front so it can always be reported
+	effectiveSeed := utils.ResolveSeed(seed)
+	if seed == 0 {
+		u.Println(u.KeyValue("Seed", fmt.Sprintf("%d (random, pass --seed %d to reproduce)", effectiveSeed, effectiveSeed)))
+	} else {
+		u.Println(u.KeyValue("Seed", fmt.Sprintf("%d", effectiveSeed)))
 	}
 	if compress {
-		fmt.Println(u.KeyValue("Compression", "xz (.csv.xz)"))
+		u.Println(u.KeyValue("Compression", fmt.Sprintf("xz -%d (.csv.xz)", compressLevel)))
+	}
+	if writeLimiter != nil {
+		u.Println(u.KeyValue("Max write rate", writeRate.String()))
+	}
+	if writeBuffer != "" {
+		u.Println(u.KeyValue("Write buffer", writeBuffer+" per transaction/audit log shard"))
+	}
+	if flushInterval > 0 {
+		u.Println(u.KeyValue("Flush interval", flushInterval.String()))
+	}
+	if tableShards > 1 {
+		u.Println(u.KeyValue("Table shards", fmt.Sprintf("%d per entity table", tableShards)))
+	}
+	if maxFileRows > 0 {
+		u.Println(u.KeyValue("Max file rows", fmt.Sprintf("%d per transaction/audit log file", maxFileRows)))
+	}
+	if perTableDir {
+		u.Println(u.KeyValue("Layout", "one subdirectory per table"))
+	}
+	if txnPartitioning != generator.PartitionNone {
+		u.Println(u.KeyValue("Partition by", string(txnPartitioning)))
+	}
+	if kafka != nil {
+		mode := "CSV + Kafka"
+		if kafka.Only {
+			mode = "Kafka only"
+		}
+		u.Println(u.KeyValue("Kafka", fmt.Sprintf("%s topic %s (%s)", kafka.Brokers, kafka.Topic, mode)))
+	}
+	if avroOutput {
+		u.Println(u.KeyValue("Avro", "transactions and audit logs (.avro)"))
+	}
+	if sortTransactions {
+		u.Println(u.KeyValue("Transaction order", "sorted by timestamp"))
+	}
+	if auditFromShards {
+		u.Println(u.KeyValue("Transaction audit", "read back from the transaction shards"))
+	}
+	if sessionTxnRate < 0 || sessionTxnRate > 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--session-transaction-rate must be between 0 and 1"))
+		os.Exit(1)
+	}
+	if sessionTxnRate > 0 {
+		u.Println(u.KeyValue("Session transactions", fmt.Sprintf("%.0f%% of online and ATM transactions", sessionTxnRate*100)))
+	}
+	if farFromHomeRate < 0 || farFromHomeRate > 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--far-from-home-rate must be between 0 and 1"))
+		os.Exit(1)
+	}
+	if cmd.Flags().Changed("far-from-home-rate") && !geoCoordinates {
+		fmt.Fprintln(os.Stderr, u.Error("--far-from-home-rate requires --geo-coordinates"))
+		os.Exit(1)
+	}
+	if geoClustering < 0 || geoClustering > 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--geo-clustering must be between 0 and 1"))
+		os.Exit(1)
+	}
+	if offlineRate < 0 || offlineRate > 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--offline-customer-rate must be between 0 and 1"))
+		os.Exit(1)
+	}
+	if transferPayees < 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--transfer-payees cannot be negative"))
+		os.Exit(1)
+	}
+	if p2pRate < 0 || p2pRate > 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--p2p-rate must be between 0 and 1"))
+		os.Exit(1)
+	}
+	if rampUpMonths < 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--ramp-up-months cannot be negative"))
+		os.Exit(1)
+	}
+	if interestDay < 0 || interestDay > 28 {
+		fmt.Fprintln(os.Stderr, u.Error("--interest-posting-day must be between 1 and 28 (0 = drawn from the transaction mix)"))
+		os.Exit(1)
+	}
+	if disputeRate < 0 || disputeRate > 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--dispute-rate must be between 0 and 1"))
+		os.Exit(1)
+	}
+	if declineRetryRate < 0 || declineRetryRate > 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--decline-retry-rate must be between 0 and 1"))
+		os.Exit(1)
+	}
+	if retailWeekend < 0 || businessWeekend < 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--retail-weekend-volume and --business-weekend-volume cannot be negative"))
+		os.Exit(1)
+	}
+	if geoCoordinates {
+		u.Println(u.KeyValue("Coordinates", "latitude and longitude on transactions"))
+	}
+	if geoCoordinates && farFromHomeRate != config.FarFromHomeRate {
+		u.Println(u.KeyValue("Far from home", fmt.Sprintf("%g%% of card and online transactions", farFromHomeRate*100)))
+	}
+	if geoClustering > 0 {
+		u.Println(u.KeyValue("Geo clustering", fmt.Sprintf("%g%% of customers near their home branch", geoClustering*100)))
+	}
+	if offlineRate > 0 {
+		u.Println(u.KeyValue("Offline customers", fmt.Sprintf("%g%% without online banking", offlineRate*100)))
+	}
+	if transferPayees > 0 {
+		u.Println(u.KeyValue("Transfer payees", fmt.Sprintf("%d per retail account, %g%% of transfers", transferPayees, p2pRate*100)))
+	}
+	if rampUpMonths != config.AccountRampUpMonths {
+		u.Println(u.KeyValue("Account ramp-up", fmt.Sprintf("%d months", rampUpMonths)))
+	}
+	if interestDay == 0 {
+		u.Println(u.KeyValue("Interest", "drawn from the transaction mix"))
+	} else if interestDay != config.InterestPostingDay {
+		u.Println(u.KeyValue("Interest", fmt.Sprintf("posted monthly on day %d", interestDay)))
+	}
+	if !minorUnits {
+		u.Println(u.KeyValue("Amounts", "hundredths for every currency"))
+	}
+	if branchHours {
+		u.Println(u.KeyValue("Branch hours", "branch transactions within operating hours"))
+	}
+	if declineRetryRate != config.DeclineRetryRate {
+		u.Println(u.KeyValue("Decline retries", fmt.Sprintf("%g%% of declined purchases", declineRetryRate*100)))
+	}
+	if weekendVolume != nil && (weekendVolume.Retail != config.RetailWeekendVolume || weekendVolume.Business != config.BusinessWeekendVolume) {
+		u.Println(u.KeyValue("Weekend volume", fmt.Sprintf("%gx retail, %gx business", weekendVolume.Retail, weekendVolume.Business)))
+	}
+	if countryWeightsFile != "" {
+		var err error
+		countryWeights, err = data.LoadCountryWeightsFile(countryWeightsFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		u.Println(u.KeyValue("Country weights", countryWeightsFile))
+	}
+	if accountMixFile != "" {
+		accountMix, err = generator.LoadAccountMixFile(accountMixFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		u.Println(u.KeyValue("Account mix", accountMixFile))
+	}
+	if minAccounts != 0 || maxAccounts != 0 {
+		accountMix, err = generator.WithAccountBounds(accountMix, minAccounts, maxAccounts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		u.Println(u.KeyValue("Accounts per customer", boundsLabel(minAccounts, maxAccounts)))
+	}
+	if minBeneficiaries < 0 || maxBeneficiaries < 1 || minBeneficiaries > maxBeneficiaries {
+		fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("invalid beneficiary bounds %d-%d (expected 0 <= min <= max and max >= 1)", minBeneficiaries, maxBeneficiaries)))
+		os.Exit(1)
+	}
+	if minBeneficiaries != config.MinBeneficiaries || maxBeneficiaries != config.MaxBeneficiaries {
+		u.Println(u.KeyValue("Beneficiaries per customer", boundsLabel(minBeneficiaries, maxBeneficiaries)))
+	}
+	if foreignRate != config.ForeignCurrencyRate || foreignCurrencies != "" {
+		label := fmt.Sprintf("%.0f%% of deposit accounts", foreignRate*100)
+		if foreignCurrencies != "" {
+			label += " in " + foreignCurrencies
+		}
+		u.Println(u.KeyValue("Foreign currency", label))
+	}
+	if businessMix != "" {
+		mix, err := generator.ParseBusinessMix(businessMix)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		bizMix = &mix
+		u.Println(u.KeyValue("Business mix", mix.String()))
+	}
+	if transactionMixFile != "" {
+		txnMix, err = generator.LoadTransactionMixFile(transactionMixFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		u.Println(u.KeyValue("Transaction mix", transactionMixFile))
+	}
+	if feeScheduleFile != "" {
+		feeSchedule, err = generator.LoadFeeScheduleFile(feeScheduleFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		u.Println(u.KeyValue("Fee schedule", feeScheduleFile))
+	}
+	if whaleAccounts < 0 || whaleMultiplier <= 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--whale-accounts must be at least 0 and --whale-multiplier above 0"))
+		os.Exit(1)
+	}
+	if whaleAccounts > 0 || len(whaleIDs) > 0 {
+		u.Println(u.KeyValue("Whale accounts", fmt.Sprintf("%d at %gx volume", max(whaleAccounts, len(whaleIDs)), whaleMultiplier)))
+	}
+	if monthlyCaps != "" {
+		txnCaps, err = generator.ParseMonthlyCaps(monthlyCaps)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+	}
+	if len(txnCaps) > 0 {
+		u.Println(u.KeyValue("Monthly caps", txnCaps.String()))
+	}
+	if settlementLags != "" {
+		if lags, err = generator.ParseSettlementLags(settlementLags); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		u.Println(u.KeyValue("Settlement lags", lags.String()))
+	}
+	if atmDenominations != "" {
+		if denominations, err = generator.ParseATMDenominations(atmDenominations); err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+			os.Exit(1)
+		}
+		u.Println(u.KeyValue("ATM notes", denominations.String()))
+	}
+	var auditFilter generator.AuditActionFilter
+	if auditFilter, err = generator.ParseAuditActionFilter(auditActions, excludeAudit); err != nil {
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+		os.Exit(1)
+	}
+	if auditFilter != nil && auditActions == "" {
+		u.Println(u.KeyValue("Audit actions", "all except "+excludeAudit))
+	} else if auditFilter != nil {
+		u.Println(u.KeyValue("Audit actions", strings.Join(auditFilter.Actions(), ",")))
 	}
 	workerCount := generator.GetWorkerCount(workers)
-	fmt.Println(u.KeyValue("Workers", fmt.Sprintf("%d", workerCount)))
-	if entitiesOnly {
-		fmt.Println(u.KeyValue("Mode", "entities only (no transactions)"))
+	u.Println(u.KeyValue("Workers", fmt.Sprintf("%d", workerCount)))
+	if memoryBudget > 0 {
+		u.Println(u.KeyValue("Max memory", maxMemory))
 	}
-	fmt.Println()
+	if txnGranularity != generator.GranularityMonthly {
+		u.Println(u.KeyValue("Granularity", string(txnGranularity)))
+	}
+	if passwordScheme != generator.PasswordSchemeFast {
+		u.Println(u.KeyValue("Password hash", string(passwordScheme)))
+	}
+	if piiMode != generator.PIIModeNone {
+		u.Println(u.KeyValue("PII mode", string(piiMode)))
+	}
+	var atmEventConfig *generator.ATMEventGeneratorConfig
+	if atmEvents {
+		atmEventConfig = &generator.ATMEventGeneratorConfig{
+			CashCapacity:       config.ATMCashCapacity,
+			ReplenishDays:      config.ATMReplenishDays,
+			CashLowPercent:     config.ATMCashLowPercent,
+			FaultsPerYear:      config.ATMFaultsPerYear,
+			MaintenancePerYear: config.ATMMaintenancePerYear,
+		}
+		u.Println(u.KeyValue("ATM events", "enabled"))
+	}
+	var kycConfig *generator.KYCConfig
+	if enableKYC {
+		kycConfig = &generator.KYCConfig{
+			UnverifiedRate:       config.KYCUnverifiedRate,
+			RejectedRate:         config.KYCRejectedRate,
+			MaxReviewDays:        config.KYCMaxReviewDays,
+			UnverifiedDailyLimit: config.KYCUnverifiedDailyLimit,
+		}
+		if continuation != nil {
+			kycConfig = continuation.Manifest.KYC
+		}
+		u.Println(u.KeyValue("KYC", "enabled"))
+	}
+	if transfers {
+		u.Println(u.KeyValue("Transfers", "enabled"))
+	}
+	if disputeRate > 0 {
+		u.Println(u.KeyValue("Disputes", fmt.Sprintf("%g%% of completed purchases", disputeRate*100)))
+	}
+	switch {
+	case only != nil:
+		u.Println(u.KeyValue("Mode", fmt.Sprintf("entities only (%s)", only)))
+	case entitiesOnly:
+		u.Println(u.KeyValue("Mode", "entities only (no transactions)"))
+	}
+	u.Println()
 
-	// Create orchestrator with defaults from config
-	orchestrator, err := generator.NewOrchestrator(generator.OrchestratorConfig{
-		NumCustomers:                    numCustomers,
-		NumBusinesses:                   numBusinesses,
-		NumBranches:                     numBranches,
-		NumATMs:                         numATMs,
-		YearsOfHistory:                  numYears,
-		OutputDir:                       outputDir,
-		Seed:                            seed,
-		TransactionsPerCustomerPerMonth: config.TransactionsPerCustomerPerMonth,
-		PayrollDay:                      config.PayrollDay,
-		ParetoRatio:                     config.ParetoRatio,
-		DeclinedTransactionRate:         config.DeclinedTransactionRate,
-		InsufficientFundsRate:           config.InsufficientFundsRate,
-		FailedLoginRate:                 config.FailedLoginRate,
-		Compress:                        compress,
-		Workers:                         workers,
-	}, generator.OrchestratorOptions{
-		Verbose:      verbose,
-		ShowProgress: true,
+	// Create orchestrator from the defaults shared with bench, overridden by the flags
+	cfg := defaultOrchestratorConfig(numCustomers)
+	cfg.NumBusinesses, cfg.NumBranches, cfg.NumATMs = numBusinesses, numBranches, numATMs
+	cfg.YearsOfHistory = numYears
+	cfg.OutputDir = outputDir
+	cfg.Seed = effectiveSeed
+	cfg.CountryWeights = countryWeights
+	cfg.AccountMix = accountMix
+	cfg.ForeignCurrencyRate = foreignRate
+	cfg.ForeignCurrencies = foreignCurrencyList
+	cfg.BusinessMix = bizMix
+	cfg.AsOfDate = asOf
+	cfg.StartDate = historyStart
+	cfg.Continuation = continuation
+	cfg.Only = only
+	cfg.Granularity = txnGranularity
+	cfg.DeclineRetryRate = declineRetryRate
+	cfg.FarFromHomeRate = farFromHomeRate
+	cfg.GeoCoordinates = geoCoordinates
+	cfg.BranchHours = branchHours
+	cfg.MinorUnits = minorUnits
+	cfg.RampUpMonths = rampUpMonths
+	cfg.InterestPostingDay = interestDay
+	cfg.TransferGraph = generator.TransferGraph{Payees: transferPayees, P2PRate: p2pRate}
+	cfg.WeekendVolume = weekendVolume
+	cfg.TransactionMix = txnMix
+	cfg.FeeSchedule = feeSchedule
+	cfg.WhaleAccounts = whaleAccounts
+	cfg.WhaleAccountIDs = whaleIDs
+	cfg.WhaleMultiplier = whaleMultiplier
+	cfg.MonthlyCaps = txnCaps
+	cfg.SettlementLags = lags
+	cfg.ATMDenominations = denominations
+	cfg.SessionTransactionRate = sessionTxnRate
+	cfg.AuditActions = auditFilter
+	cfg.GeoClustering = geoClustering
+	cfg.OfflineCustomerRate = offlineRate
+	cfg.MinBeneficiaries, cfg.MaxBeneficiaries = minBeneficiaries, maxBeneficiaries
+	cfg.ATMEvents = atmEventConfig
+	cfg.KYC = kycConfig
+	cfg.Transfers = transfers
+	cfg.DisputeRate = disputeRate
+	cfg.Passwords.Scheme = passwordScheme
+	cfg.PIIMode = piiMode
+	cfg.PIIMappingFile = piiMappingFile
+	cfg.Compress = compress
+	cfg.CompressLevel = compressLevel
+	cfg.TableShards = tableShards
+	cfg.MaxFileRows = maxFileRows
+	cfg.WriteBufferSize = int(writeBufferSize)
+	cfg.FlushInterval = flushInterval
+	cfg.PerTableDir = perTableDir
+	cfg.PartitionBy = txnPartitioning
+	cfg.SortTransactions = sortTransactions
+	cfg.TransactionAuditFromShards = auditFromShards
+	cfg.WriteLimiter = writeLimiter
+	cfg.Kafka = kafka
+	cfg.Avro = avroOutput
+	cfg.Workers = workers
+	cfg.MaxMemory = memoryBudget
+	orchestrator, err := generator.NewOrchestrator(cfg, generator.OrchestratorOptions{
+		Verbose:      u.Enabled(ui.LevelVerbose),
+		ShowProgress: !u.Quiet(),
 	})
 	if err != nil {
 		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
 		os.Exit(1)
 	}
 
+	// Cancel generation cleanly on Ctrl-C/SIGTERM or when the timeout expires
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if genTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, genTimeout)
+		defer cancel()
+	}
+
 	var result *generator.GenerationResult
 
 	if entitiesOnly {
 		spin := u.NewSpinner("Generating entities")
 		spin.Start()
-		result, err = orchestrator.GenerateEntities()
+		result, err = orchestrator.GenerateEntities(ctx)
 		if err != nil {
-			spin.Error(err.Error())
+			spin.Error(generateErrorMessage(err))
 			os.Exit(1)
 		}
 		spin.Success("complete")
 	} else {
 		spin := u.NewSpinner("Generating all data (entities + transactions)")
 		spin.Start()
-		result, err = orchestrator.GenerateAll()
+		result, err = orchestrator.GenerateAll(ctx)
 		if err != nil {
-			spin.Error(err.Error())
+			spin.Error(generateErrorMessage(err))
 			os.Exit(1)
 		}
 		spin.Success("complete")
 	}
 
+	if err := orchestrator.WriteManifest(result); err != nil {
+		fmt.Fprintln(os.Stderr, u.Warning(err.Error()))
+	}
+	if err := orchestrator.WriteMeta(); err != nil {
+		fmt.Fprintln(os.Stderr, u.Warning(err.Error()))
+	}
+
 	printGenerateSummary(u, result)
 	fmt.Println()
 	fmt.Println(u.Success("Output files written to: " + outputDir))
+
+	if summaryJSON != "" {
+		summary, err := orchestrator.Summary(result)
+		if err == nil {
+			err = writeSummaryJSON(summaryJSON, summary)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Warning(err.Error()))
+		}
+	}
+
+	if verifyBalances && !entitiesOnly {
+		if !runBalanceVerification(ctx, u) {
+			os.Exit(1)
+		}
+	}
+}
+
+// runBalanceVerification checks generated running balances and prints the
+// first violations. Returns false if any were found.
+func runBalanceVerification(ctx context.Context, u *ui.UI) bool {
+	u.Println()
+	spin := u.NewSpinner("Verifying balances")
+	spin.Start()
+	report, err := generator.VerifyBalances(ctx, outputDir, config.BalanceVerifyMaxReported)
+	if err != nil {
+		spin.Error(err.Error())
+		return false
+	}
+	if report.OK() {
+		spin.Success(fmt.Sprintf("%d transactions across %d accounts consistent", report.Transactions, report.Accounts))
+		return true
+	}
+
+	spin.Error(fmt.Sprintf("%d violations in %d transactions", report.ViolationCount, report.Transactions))
+	for _, kind := range []string{generator.ViolationBalanceMismatch, generator.ViolationLimitExceeded, generator.ViolationUnknownAccount} {
+		if n := report.ByKind[kind]; n > 0 {
+			fmt.Println(u.KeyValue(kind, fmt.Sprintf("%d", n)))
+		}
+	}
+	for _, v := range report.Violations {
+		fmt.Println(u.Warning(v.String()))
+	}
+	if remaining := report.ViolationCount - int64(len(report.Violations)); remaining > 0 {
+		fmt.Println(u.Muted(fmt.Sprintf("... and %d more", remaining)))
+	}
+	return false
+}
+
+// generateErrorMessage describes a generation failure, calling out interruptions
+func generateErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "interrupted - output in " + outputDir + " is incomplete"
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("timed out after %s - output in %s is incomplete", genTimeout, outputDir)
+	default:
+		return err.Error()
+	}
+}
+
+// loadContinuation validates --continue-from against the other flags and
+// reads the output directory being continued
+func loadContinuation(cmd *cobra.Command) (*generator.Continuation, error) {
+	// These either come from the continued data set or need files it doesn't rewrite.
+	// Business, branch and ATM counts are derived from --customers, so rejecting
+	// it keeps every entity count from the manifest.
+	for _, name := range []string{"customers", "as-of", "start-date", "end-date", "country-weights", "account-mix", "min-accounts", "max-accounts", "business-mix", "min-beneficiaries", "max-beneficiaries", "foreign-currency-rate", "foreign-currencies", "geo-clustering", "offline-customer-rate", "currency-minor-units", "entities", "only", "atm-events", "kyc", "verify-balances", "password-hash", "pii-mode", "pii-mapping"} {
+		if cmd.Flags().Changed(name) {
+			return nil, fmt.Errorf("--continue-from cannot be combined with --%s", name)
+		}
+	}
+
+	c, err := generator.LoadContinuation(context.Background(), continueFrom)
+	if err != nil {
+		return nil, err
+	}
+	if generator.IsS3Path(outputDir) {
+		return c, nil
+	}
+	out, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", outputDir, err)
+	}
+	if out == c.Dir {
+		return nil, fmt.Errorf("--output must differ from --continue-from so existing files are kept")
+	}
+	return c, nil
+}
+
+// derivedEntityCounts returns the businesses, branches and ATMs generated for
+// numCustomers customers
+func derivedEntityCounts(numCustomers int) (businesses, branches, atms int) {
+	businesses = max(int(float64(numCustomers)*config.BusinessRatio), 10)
+	branches = max(int(float64(numCustomers)*config.BranchRatio), 5)
+	atms = max(int(float64(numCustomers)*config.ATMRatio), 10)
+	return businesses, branches, atms
+}
+
+// defaultOrchestratorConfig returns the settings generate runs with when no
+// flag is given, for numCustomers customers and their derived entity counts,
+// leaving the years of history, output and seed to the caller.
+// generate overrides them from its flags; bench uses them as they are.
+func defaultOrchestratorConfig(numCustomers int) generator.OrchestratorConfig {
+	businesses, branches, atms := derivedEntityCounts(numCustomers)
+	return generator.OrchestratorConfig{
+		NumCustomers:                    numCustomers,
+		NumBusinesses:                   businesses,
+		NumBranches:                     branches,
+		NumATMs:                         atms,
+		ForeignCurrencyRate:             config.ForeignCurrencyRate,
+		TransactionsPerCustomerPerMonth: config.TransactionsPerCustomerPerMonth,
+		PayrollDay:                      config.PayrollDay,
+		Granularity:                     generator.GranularityMonthly,
+		ParetoRatio:                     config.ParetoRatio,
+		DeclinedTransactionRate:         config.DeclinedTransactionRate,
+		DeclineRetryRate:                config.DeclineRetryRate,
+		InsufficientFundsRate:           config.InsufficientFundsRate,
+		CashbackRate:                    config.CashbackRate,
+		ReversalRate:                    config.ReversalRate,
+		OverdraftFee:                    config.OverdraftFee,
+		OverdraftFeeDailyCap:            config.OverdraftFeeDailyCap,
+		InternationalWireRate:           config.InternationalWireRate,
+		FarFromHomeRate:                 config.FarFromHomeRate,
+		MinorUnits:                      config.CurrencyMinorUnits,
+		RampUpMonths:                    config.AccountRampUpMonths,
+		InterestPostingDay:              config.InterestPostingDay,
+		TransferGraph:                   generator.TransferGraph{P2PRate: config.TransferP2PRate},
+		WeekendVolume:                   &generator.WeekendVolume{Retail: config.RetailWeekendVolume, Business: config.BusinessWeekendVolume},
+		BusinessHours:                   businessHours(),
+		WhaleMultiplier:                 config.WhaleMultiplier,
+		SettlementLags:                  generator.DefaultSettlementLags(),
+		ATMDenominations:                generator.DefaultATMDenominations(),
+		FailedLoginRate:                 config.FailedLoginRate,
+		NewDeviceRate:                   config.NewDeviceRate,
+		ChurnRate:                       config.ChurnRate,
+		ChurnClosedRatio:                config.ChurnClosedRatio,
+		RelocationRate:                  config.RelocationRate,
+		GeoClustering:                   config.GeoClustering,
+		OfflineCustomerRate:             config.OfflineCustomerRate,
+		MinBeneficiaries:                config.MinBeneficiaries,
+		MaxBeneficiaries:                config.MaxBeneficiaries,
+		ClosedBranchRate:                config.ClosedBranchRate,
+		OfflineATMRate:                  config.OfflineATMRate,
+		MaintenanceATMRate:              config.MaintenanceATMRate,
+		Passwords:                       generator.PasswordHasher{Scheme: generator.PasswordSchemeFast, BcryptCost: config.PasswordBcryptCost},
+		PIIMode:                         generator.PIIModeNone,
+		TableShards:                     1,
+		SortRunRows:                     config.TransactionSortRunRows,
+		GeneratorVersion:                Version,
+	}
+}
+
+// businessHours keeps business, merchant and payroll accounts to office hours
+func businessHours() map[models.AccountType]generator.BusinessHours {
+	hours := generator.BusinessHours{
+		Weekend:   config.BusinessWeekendActivity,
+		Overnight: config.BusinessOvernightActivity,
+		OpenHour:  config.BusinessOpenHour,
+		CloseHour: config.BusinessCloseHour,
+	}
+	return map[models.AccountType]generator.BusinessHours{
+		models.AccountTypeBusiness: hours,
+		models.AccountTypeMerchant: hours,
+		models.AccountTypePayroll:  hours,
+	}
+}
+
+// boundsLabel describes a --min-*/--max-* count pair for display (0 = unbounded)
+func boundsLabel(min, max int) string {
+	switch {
+	case max == 0:
+		return fmt.Sprintf("at least %d", min)
+	case min == 0:
+		return fmt.Sprintf("at most %d", max)
+	case min == max:
+		return fmt.Sprintf("exactly %d", min)
+	}
+	return fmt.Sprintf("%d-%d", min, max)
+}
+
+// parseDateFlag parses a date flag, accepting a date (midnight UTC) or an RFC 3339 timestamp
+func parseDateFlag(name, s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q (expected YYYY-MM-DD or RFC 3339)", name, s)
+	}
+	return t, nil
 }
 
 // printGenerateSummary prints a styled generation summary
@@ -169,15 +1093,29 @@ func printGenerateSummary(u *ui.UI, result *generator.GenerationResult) {
 	items := []ui.KV{
 		{Key: "Branches", Value: fmt.Sprintf("%d", result.BranchCount)},
 		{Key: "ATMs", Value: fmt.Sprintf("%d", result.ATMCount)},
-		{Key: "Customers", Value: fmt.Sprintf("%d", result.CustomerCount)},
-		{Key: "Businesses", Value: fmt.Sprintf("%d", result.BusinessCount)},
-		{Key: "Accounts", Value: fmt.Sprintf("%d", result.AccountCount)},
-		{Key: "Beneficiaries", Value: fmt.Sprintf("%d", result.BeneficiaryCount)},
-		{Key: "Transactions", Value: fmt.Sprintf("%d", result.TransactionCount)},
-		{Key: "Audit Logs", Value: fmt.Sprintf("%d", result.AuditLogCount)},
-		{Key: "Duration", Value: result.Duration.Round(1 * 1e6).String()},
-		{Key: "Status", Value: "Success"},
 	}
+	if result.ATMEventCount > 0 {
+		items = append(items, ui.KV{Key: "ATM Events", Value: fmt.Sprintf("%d", result.ATMEventCount)})
+	}
+	items = append(items,
+		ui.KV{Key: "Customers", Value: fmt.Sprintf("%d", result.CustomerCount)},
+		ui.KV{Key: "Businesses", Value: fmt.Sprintf("%d", result.BusinessCount)},
+		ui.KV{Key: "Accounts", Value: fmt.Sprintf("%d", result.AccountCount)},
+		ui.KV{Key: "Beneficiaries", Value: fmt.Sprintf("%d", result.BeneficiaryCount)},
+		ui.KV{Key: "Transactions", Value: fmt.Sprintf("%d", result.TransactionCount)},
+	)
+	if result.TransferCount > 0 {
+		items = append(items, ui.KV{Key: "Transfers", Value: fmt.Sprintf("%d", result.TransferCount)})
+	}
+	if result.DisputeCount > 0 {
+		items = append(items, ui.KV{Key: "Disputes", Value: fmt.Sprintf("%d", result.DisputeCount)})
+	}
+	items = append(items,
+		ui.KV{Key: "Audit Logs", Value: fmt.Sprintf("%d", result.AuditLogCount)},
+		ui.KV{Key: "Duration", Value: result.Duration.Round(1 * 1e6).String()},
+		ui.KV{Key: "Seed", Value: fmt.Sprintf("%d", result.Seed)},
+		ui.KV{Key: "Status", Value: "Success"},
+	)
 
 	fmt.Println(u.SummaryBox("Generation Complete", items))
 }
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadContinuationRejectsEntityFlags(t *testing.T) {
+	for _, name := range []string{"customers", "offline-customer-rate", "geo-clustering"} {
+		t.Run(name, func(t *testing.T) {
+			f := generateCmd.Flags().Lookup(name)
+			def := f.Value.String()
+			t.Cleanup(func() {
+				f.Value.Set(def)
+				f.Changed = false
+			})
+			if err := generateCmd.Flags().Set(name, def); err != nil {
+				t.Fatal(err)
+			}
+
+			// Rejected before the continued directory is read
+			_, err := loadContinuation(generateCmd)
+			if want := "--continue-from cannot be combined with --" + name; err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("error %v, want %q", err, want)
+			}
+		})
+	}
+}
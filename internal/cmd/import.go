@@ -1,31 +1,48 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/spf13/cobra"
+	"github.com/willfong/load-generator/internal/generator"
 	"github.com/willfong/load-generator/internal/ui"
 )
 
 var (
-	importDBConnection string
-	importInputDir     string
-	importMaxOpenConns int
-	importMaxIdleConns int
+	importDBConnection  string
+	importDriver        string
+	importInputDir      string
+	importMaxOpenConns  int
+	importMaxIdleConns  int
+	importIgnoreSchema  bool
+	importSummaryJSON   string
+	importContinueOnErr bool
+
+	importFieldsTerminatedBy string
+	importFieldsEnclosedBy   string
+	importFieldsEscapedBy    string
+	importLinesTerminatedBy  string
 )
 
 var importCmd = &cobra.Command{
 	Use:   "import",
-	Short: "Import CSV data into MySQL/MariaDB database",
+	Short: "Import CSV data into MySQL/MariaDB or SQLite",
 	Long: `Import generated CSV data into a MySQL/MariaDB database using LOAD DATA LOCAL INFILE.
 
 This command performs bulk data loading with automatic parallelization.
@@ -37,28 +54,46 @@ The import process:
 3. Loads all tables in parallel with progress reporting
 4. Creates indexes and foreign keys after loading
 
+With --driver sqlite3, --db is a SQLite database file. Rows are inserted
+through prepared statements, one transaction per table (SQLite has no LOAD
+DATA), which makes the generate/query loop runnable without a database server.
+
 Examples:
   loadgen import --db "user:pass@tcp(localhost:3306)/bank"
-  loadgen import --db "user:pass@tcp(localhost:3306)/bank" --input ./my-data`,
+  loadgen import --db "user:pass@tcp(localhost:3306)/bank" --input ./my-data
+  loadgen import --driver sqlite3 --db bank.db`,
 	Run: runImport,
 }
 
 func init() {
 	rootCmd.AddCommand(importCmd)
 
-	importCmd.Flags().StringVar(&importDBConnection, "db", "", "database connection string (required)")
+	importCmd.Flags().StringVar(&importDBConnection, "db", "", "database connection string, or database file for sqlite3 (required)")
+	importCmd.Flags().StringVar(&importDriver, "driver", "mysql", "database driver: mysql or sqlite3")
 	importCmd.Flags().StringVar(&importInputDir, "input", "./output", "input directory containing CSV files")
 	importCmd.Flags().IntVar(&importMaxOpenConns, "db-max-open", 10, "max open database connections")
 	importCmd.Flags().IntVar(&importMaxIdleConns, "db-max-idle", 10, "max idle database connections")
+	importCmd.Flags().BoolVar(&importIgnoreSchema, "ignore-schema-version", false, "import even if _meta.csv records a different schema version")
+	importCmd.Flags().BoolVar(&importContinueOnErr, "continue-on-error", false, "keep loading the other tables when one fails, skip indexes on failed tables, and report every failure at the end (still exits non-zero)")
+	importCmd.Flags().StringVar(&importSummaryJSON, "summary-json", "", "also write the import summary (rows and time per table) as JSON to this path")
+
+	// LOAD DATA format, defaulting to what the generator's CSV writer produces
+	writerFormat := csvWriterFormat()
+	importCmd.Flags().StringVar(&importFieldsTerminatedBy, "fields-terminated-by", writerFormat.fieldsTerminatedBy, "LOAD DATA field terminator (backslash escapes such as \\t allowed)")
+	importCmd.Flags().StringVar(&importFieldsEnclosedBy, "fields-enclosed-by", writerFormat.fieldsEnclosedBy, "LOAD DATA field enclosure character (empty for none)")
+	importCmd.Flags().StringVar(&importFieldsEscapedBy, "fields-escaped-by", writerFormat.fieldsEscapedBy, "LOAD DATA escape character (empty for none)")
+	importCmd.Flags().StringVar(&importLinesTerminatedBy, "lines-terminated-by", writerFormat.linesTerminatedBy, "LOAD DATA line terminator (backslash escapes such as \\r\\n allowed)")
 
 	importCmd.MarkFlagRequired("db")
 }
 
 // tableConfig holds metadata for loading a single table
 type tableConfig struct {
-	name    string
-	csvFile string
-	loadSQL string
+	name     string
+	csvFile  string
+	loadSQL  string
+	headers  []string // Expected CSV header row (must match the LOAD DATA column list)
+	optional bool     // Only generated on request; a missing file is skipped, not an error
 }
 
 // loadResult holds the result of loading a table
@@ -74,11 +109,10 @@ var tablesToLoad = []tableConfig{
 	{
 		name:    "branches",
 		csvFile: "branches",
+		headers: generator.BranchHeaders(),
 		loadSQL: `LOAD DATA LOCAL INFILE '%s'
 INTO TABLE branches
-FIELDS TERMINATED BY ','
-ENCLOSED BY '"'
-LINES TERMINATED BY '\n'
+%s
 IGNORE 1 LINES
 (id, branch_code, name, type, status, address_line1, @address_line2, city, @state,
  @postal_code, country, @latitude, @longitude, timezone, @monday_hours, @tuesday_hours,
@@ -104,11 +138,10 @@ SET
 	{
 		name:    "atms",
 		csvFile: "atms",
+		headers: generator.ATMHeaders(),
 		loadSQL: `LOAD DATA LOCAL INFILE '%s'
 INTO TABLE atms
-FIELDS TERMINATED BY ','
-ENCLOSED BY '"'
-LINES TERMINATED BY '\n'
+%s
 IGNORE 1 LINES
 (id, atm_id, @branch_id, status, @location_name, address_line1, city, @state,
  @postal_code, country, @latitude, @longitude, timezone, supports_deposit,
@@ -120,19 +153,30 @@ SET
     postal_code = NULLIF(@postal_code, ''),
     latitude = NULLIF(@latitude, ''),
     longitude = NULLIF(@longitude, '')`,
+	},
+	{
+		name:     "atm_events",
+		csvFile:  "atm_events",
+		headers:  generator.ATMEventHeaders(),
+		optional: true,
+		loadSQL: `LOAD DATA LOCAL INFILE '%s'
+INTO TABLE atm_events
+%s
+IGNORE 1 LINES
+(id, atm_id, type, status, cash_level, timestamp)`,
 	},
 	{
 		name:    "customers",
 		csvFile: "customers",
+		headers: generator.CustomerHeaders(),
 		loadSQL: `LOAD DATA LOCAL INFILE '%s'
 INTO TABLE customers
-FIELDS TERMINATED BY ','
-ENCLOSED BY '"'
-LINES TERMINATED BY '\n'
+%s
 IGNORE 1 LINES
 (id, first_name, last_name, email, @phone, @date_of_birth, @address_line1, @address_line2,
  @city, @state, @postal_code, country, timezone, @home_branch_id, segment, status,
- activity_score, username, password_hash, pin, created_at, updated_at)
+ activity_score, @username, @password_hash, pin, created_at, updated_at,
+ @kyc_status, @kyc_document_type, @kyc_verified_at)
 SET
     phone = NULLIF(@phone, ''),
     date_of_birth = NULLIF(@date_of_birth, ''),
@@ -141,16 +185,20 @@ SET
     city = NULLIF(@city, ''),
     state = NULLIF(@state, ''),
     postal_code = NULLIF(@postal_code, ''),
-    home_branch_id = NULLIF(@home_branch_id, '')`,
+    home_branch_id = NULLIF(@home_branch_id, ''),
+    username = NULLIF(@username, ''),
+    password_hash = NULLIF(@password_hash, ''),
+    kyc_status = NULLIF(@kyc_status, ''),
+    kyc_document_type = NULLIF(@kyc_document_type, ''),
+    kyc_verified_at = NULLIF(@kyc_verified_at, '')`,
 	},
 	{
 		name:    "accounts",
 		csvFile: "accounts",
+		headers: generator.AccountHeaders(),
 		loadSQL: `LOAD DATA LOCAL INFILE '%s'
 INTO TABLE accounts
-FIELDS TERMINATED BY ','
-ENCLOSED BY '"'
-LINES TERMINATED BY '\n'
+%s
 IGNORE 1 LINES
 (id, account_number, customer_id, type, status, currency, balance, credit_limit,
  overdraft_limit, daily_withdraw_limit, daily_transfer_limit, interest_rate,
@@ -162,11 +210,10 @@ SET
 	{
 		name:    "beneficiaries",
 		csvFile: "beneficiaries",
+		headers: generator.BeneficiaryHeaders(),
 		loadSQL: `LOAD DATA LOCAL INFILE '%s'
 INTO TABLE beneficiaries
-FIELDS TERMINATED BY ','
-ENCLOSED BY '"'
-LINES TERMINATED BY '\n'
+%s
 IGNORE 1 LINES
 (id, customer_id, @nickname, name, type, status, @bank_name, @bank_code, @routing_number,
  @account_number, @iban, @address_line1, @address_line2, @city, @state, @postal_code,
@@ -191,11 +238,10 @@ SET
 	{
 		name:    "transactions",
 		csvFile: "transactions",
+		headers: generator.TransactionHeaders(),
 		loadSQL: `LOAD DATA LOCAL INFILE '%s'
 INTO TABLE transactions
-FIELDS TERMINATED BY ','
-ENCLOSED BY '"'
-LINES TERMINATED BY '\n'
+%s
 IGNORE 1 LINES
 (id, reference_number, account_id, @counterparty_account_id, @beneficiary_id,
  type, status, channel, amount, currency, balance_after, description, @metadata,
@@ -209,15 +255,43 @@ SET
     atm_id = NULLIF(@atm_id, ''),
     linked_transaction_id = NULLIF(@linked_transaction_id, ''),
     failure_reason = NULLIF(@failure_reason, '')`,
+	},
+	{
+		name:     "transfers",
+		csvFile:  "transfers",
+		headers:  generator.TransferHeaders(),
+		optional: true,
+		loadSQL: `LOAD DATA LOCAL INFILE '%s'
+INTO TABLE transfers
+%s
+IGNORE 1 LINES
+(id, reference_number, from_account_id, to_account_id, amount, currency, status,
+ debit_transaction_id, @credit_transaction_id, timestamp)
+SET
+    credit_transaction_id = NULLIF(@credit_transaction_id, '')`,
+	},
+	{
+		name:     "disputes",
+		csvFile:  "disputes",
+		headers:  generator.DisputeHeaders(),
+		optional: true,
+		loadSQL: `LOAD DATA LOCAL INFILE '%s'
+INTO TABLE disputes
+%s
+IGNORE 1 LINES
+(id, case_number, transaction_id, account_id, customer_id, amount, currency, reason, status,
+ filed_at, @review_started_at, @resolved_at)
+SET
+    review_started_at = NULLIF(@review_started_at, ''),
+    resolved_at = NULLIF(@resolved_at, '')`,
 	},
 	{
 		name:    "audit_logs",
 		csvFile: "audit_logs",
+		headers: generator.AuditLogHeaders(),
 		loadSQL: `LOAD DATA LOCAL INFILE '%s'
 INTO TABLE audit_logs
-FIELDS TERMINATED BY ','
-ENCLOSED BY '"'
-LINES TERMINATED BY '\n'
+%s
 IGNORE 1 LINES
 (id, timestamp, @customer_id, @employee_id, @system_id, action, outcome, channel,
  @branch_id, @atm_id, @ip_address, @user_agent, @account_id, @transaction_id,
@@ -243,25 +317,94 @@ SET
 	},
 }
 
+// geoTransactionsTable replaces the transactions entry of tablesToLoad for
+// output generated with --geo-coordinates, whose transactions end with
+// latitude and longitude columns. Without them the columns stay NULL.
+var geoTransactionsTable = tableConfig{
+	name:    "transactions",
+	csvFile: "transactions",
+	headers: generator.TransactionGeoHeaders(),
+	loadSQL: `LOAD DATA LOCAL INFILE '%s'
+INTO TABLE transactions
+%s
+IGNORE 1 LINES
+(id, reference_number, account_id, @counterparty_account_id, @beneficiary_id,
+ type, status, channel, amount, currency, balance_after, description, @metadata,
+ @branch_id, @atm_id, @linked_transaction_id, timestamp, posted_at, value_date,
+ @failure_reason, @latitude, @longitude)
+SET
+    counterparty_account_id = NULLIF(@counterparty_account_id, ''),
+    beneficiary_id = NULLIF(@beneficiary_id, ''),
+    metadata = NULLIF(@metadata, ''),
+    branch_id = NULLIF(@branch_id, ''),
+    atm_id = NULLIF(@atm_id, ''),
+    linked_transaction_id = NULLIF(@linked_transaction_id, ''),
+    failure_reason = NULLIF(@failure_reason, ''),
+    latitude = NULLIF(@latitude, ''),
+    longitude = NULLIF(@longitude, '')`,
+}
+
+// tablesForInput returns the tables to load from inputDir, as its manifest
+// describes them. A continuation output holds only new transactions and
+// audit logs, so its entity tables are skipped rather than reported missing.
+func tablesForInput(inputDir string) []tableConfig {
+	m, err := generator.ReadManifest(inputDir)
+	if err != nil {
+		return tablesToLoad
+	}
+	tables := make([]tableConfig, len(tablesToLoad))
+	for i, tbl := range tablesToLoad {
+		if tbl.name == "transactions" && m.GeoCoordinates {
+			tbl = geoTransactionsTable
+		}
+		if m.Continuation != nil {
+			tbl.optional = tbl.optional || (tbl.name != "transactions" && tbl.name != "audit_logs")
+		}
+		tables[i] = tbl
+	}
+	return tables
+}
+
 func runImport(cmd *cobra.Command, args []string) {
 	// Initialize UI
-	u := ui.New()
-	if noColor {
-		u.SetNoColor(true)
+	u := newUI()
+
+	switch importDriver {
+	case "mysql":
+	case "sqlite3":
+		if err := checkSQLiteFormatFlags(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runSQLiteImport(u)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown driver %q (expected mysql or sqlite3)\n", importDriver)
+		os.Exit(1)
+	}
+
+	format, err := parseLoadDataFormat(importFieldsTerminatedBy, importFieldsEnclosedBy, importFieldsEscapedBy, importLinesTerminatedBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
+	importFormat = format
 
-	fmt.Println(u.Header("Bank-in-a-Box Data Importer"))
-	fmt.Println()
-	fmt.Println(u.KeyValue("Database", maskDSN(importDBConnection)))
-	fmt.Println(u.KeyValue("Input", importInputDir))
-	fmt.Println(u.KeyValue("DB Pool", fmt.Sprintf("%d open / %d idle", importMaxOpenConns, importMaxIdleConns)))
-	fmt.Println()
+	u.Println(u.Header("Bank-in-a-Box Data Importer"))
+	u.Println()
+	u.Println(u.KeyValue("Database", maskDSN(importDBConnection)))
+	u.Println(u.KeyValue("Input", importInputDir))
+	u.Println(u.KeyValue("DB Pool", fmt.Sprintf("%d open / %d idle", importMaxOpenConns, importMaxIdleConns)))
+	u.Println()
 
 	// Validate input directory
 	if err := validateInputDir(importInputDir); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if !checkSchemaVersion(u, importInputDir) {
+		os.Exit(1)
+	}
 
 	// Check xz availability if we have compressed files
 	hasCompressed := hasCompressedFiles(importInputDir)
@@ -315,15 +458,19 @@ func runImport(cmd *cobra.Command, args []string) {
 	// Load all tables in parallel
 	u.Section("Loading data...")
 	startTime := time.Now()
-	results, loadErr := loadTablesParallel(ctx, db, importInputDir, u)
+	results, loadErr := loadTablesParallel(ctx, db, importInputDir, tablesForInput(importInputDir), !importContinueOnErr, u)
 	loadDuration := time.Since(startTime)
 
-	// Stop early if any table failed
-	if loadErr != nil {
+	// Stop early if any table failed, unless asked to index what did load
+	if loadErr != nil && !importContinueOnErr {
 		fmt.Fprintln(os.Stderr, u.Error("Import stopped due to error"))
+		writeImportSummary(u, results, loadDuration)
 		printImportSummary(u, results, loadDuration)
 		os.Exit(1)
 	}
+	if loadErr != nil {
+		fmt.Fprintln(os.Stderr, u.Warning(fmt.Sprintf("%d tables failed to load; indexing the rest", len(failedTables(results)))))
+	}
 
 	// Re-enable checks
 	if err := enableChecks(ctx, db); err != nil {
@@ -333,12 +480,13 @@ func runImport(cmd *cobra.Command, args []string) {
 
 	// Create indexes
 	u.Section("Creating indexes...")
-	if err := createIndexes(ctx, db, u); err != nil {
+	if err := createIndexes(ctx, db, failedTables(results), u); err != nil {
 		fmt.Fprintln(os.Stderr, u.Error("Error creating indexes: "+err.Error()))
 		os.Exit(1)
 	}
 
 	// Print summary
+	writeImportSummary(u, results, loadDuration)
 	printImportSummary(u, results, loadDuration)
 }
 
@@ -396,8 +544,9 @@ func createTablesIfNotExist(ctx context.Context, db *sql.DB) error {
 	return nil
 }
 
-// createIndexes creates indexes and foreign keys after data load
-func createIndexes(ctx context.Context, db *sql.DB, u *ui.UI) error {
+// createIndexes creates indexes and foreign keys after data load, skipping
+// statements on the failed tables
+func createIndexes(ctx context.Context, db *sql.DB, failed map[string]bool, u *ui.UI) error {
 	content, err := schemaFS.ReadFile("schemas/schema_indexes.sql")
 	if err != nil {
 		return fmt.Errorf("failed to read index schema: %w", err)
@@ -416,6 +565,9 @@ func createIndexes(ctx context.Context, db *sql.DB, u *ui.UI) error {
 		if strings.HasPrefix(strings.ToUpper(stmt), "USE ") {
 			continue
 		}
+		if failed[indexStatementTable(stmt)] {
+			continue
+		}
 		validStmts = append(validStmts, stmt)
 	}
 
@@ -424,6 +576,7 @@ func createIndexes(ctx context.Context, db *sql.DB, u *ui.UI) error {
 
 	for i, stmt := range validStmts {
 		progress.Update(i + 1)
+		u.Debugf("\n%s\n", stmt)
 
 		if _, err := db.ExecContext(ctx, stmt); err != nil {
 			// Ignore "already exists" errors for indexes and constraints
@@ -440,17 +593,42 @@ func createIndexes(ctx context.Context, db *sql.DB, u *ui.UI) error {
 	return nil
 }
 
-// loadTablesParallel loads all tables concurrently with fail-fast behavior
-func loadTablesParallel(ctx context.Context, db *sql.DB, inputDir string, u *ui.UI) ([]loadResult, error) {
+// indexTablePattern finds the table a CREATE INDEX or ANALYZE TABLE statement applies to
+var indexTablePattern = regexp.MustCompile(`(?i)(?:\bON|ANALYZE\s+TABLE)\s+(\w+)`)
+
+// indexStatementTable returns the table an index schema statement applies to
+// ("" if it names none)
+func indexStatementTable(stmt string) string {
+	if m := indexTablePattern.FindStringSubmatch(stmt); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// failedTables returns the names of the tables that failed to load
+func failedTables(results []loadResult) map[string]bool {
+	failed := make(map[string]bool)
+	for _, r := range results {
+		if r.err != nil {
+			failed[r.table] = true
+		}
+	}
+	return failed
+}
+
+// loadTablesParallel loads all tables concurrently. With failFast the first
+// table error cancels the others; otherwise every table runs to completion and
+// all their errors are returned together.
+func loadTablesParallel(ctx context.Context, db *sql.DB, inputDir string, tables []tableConfig, failFast bool, u *ui.UI) ([]loadResult, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	results := make([]loadResult, len(tablesToLoad))
+	results := make([]loadResult, len(tables))
 	var mu sync.Mutex
-	var firstErr error
+	var errs []error
 	var wg sync.WaitGroup
 
-	for i, table := range tablesToLoad {
+	for i, table := range tables {
 		wg.Add(1)
 		go func(idx int, tbl tableConfig) {
 			defer wg.Done()
@@ -467,17 +645,20 @@ func loadTablesParallel(ctx context.Context, db *sql.DB, inputDir string, u *ui.
 
 			if result.err != nil {
 				mu.Lock()
-				if firstErr == nil {
-					firstErr = result.err
-				}
+				errs = append(errs, fmt.Errorf("%s: %w", tbl.name, result.err))
 				mu.Unlock()
-				cancel() // Immediately cancel all other goroutines
+				if failFast {
+					cancel() // Immediately cancel all other goroutines
+				}
 			}
 		}(i, table)
 	}
 
 	wg.Wait()
-	return results, firstErr
+	if failFast && len(errs) > 0 {
+		return results, errs[0]
+	}
+	return results, errors.Join(errs...)
 }
 
 // loadTable loads a single table from CSV (supports sharded files)
@@ -489,6 +670,17 @@ func loadTable(ctx context.Context, db *sql.DB, inputDir string, tbl tableConfig
 	shardedFiles := findShardedFiles(inputDir, tbl.csvFile)
 	if len(shardedFiles) > 0 {
 		u.PrintShardLoading(tbl.name, len(shardedFiles))
+
+		// Validate every shard before loading any of them
+		for _, f := range shardedFiles {
+			if err := validateCSVHeader(ctx, f, tbl.headers); err != nil {
+				result.err = err
+				result.duration = time.Since(start)
+				u.PrintTableLoadResult(tbl.name, 0, result.duration, len(shardedFiles), result.err)
+				return result
+			}
+		}
+
 		result.rows, result.err = loadShardedFiles(ctx, db, shardedFiles, tbl)
 		result.duration = time.Since(start)
 
@@ -497,8 +689,9 @@ func loadTable(ctx context.Context, db *sql.DB, inputDir string, tbl tableConfig
 	}
 
 	// Fall back to single file (prefer .csv.xz, fall back to .csv)
-	csvPath := filepath.Join(inputDir, tbl.csvFile+".csv")
-	xzPath := filepath.Join(inputDir, tbl.csvFile+".csv.xz")
+	tableDir := generator.TableDir(inputDir, tbl.csvFile)
+	csvPath := filepath.Join(tableDir, tbl.csvFile+".csv")
+	xzPath := filepath.Join(tableDir, tbl.csvFile+".csv.xz")
 
 	var filePath string
 	var isCompressed bool
@@ -510,11 +703,21 @@ func loadTable(ctx context.Context, db *sql.DB, inputDir string, tbl tableConfig
 		filePath = csvPath
 		isCompressed = false
 	} else {
-		result.err = fmt.Errorf("file not found: %s or %s", csvPath, xzPath)
+		if !tbl.optional {
+			result.err = fmt.Errorf("file not found: %s or %s", csvPath, xzPath)
+		}
 		u.PrintSkipped(tbl.name, "no file")
 		return result
 	}
 
+	// Catch schema drift before LOAD DATA silently misaligns columns
+	if err := validateCSVHeader(ctx, filePath, tbl.headers); err != nil {
+		result.err = err
+		result.duration = time.Since(start)
+		u.PrintTableLoadResult(tbl.name, 0, result.duration, 1, result.err)
+		return result
+	}
+
 	// Load the data
 	if isCompressed {
 		result.rows, result.err = loadCompressedFile(ctx, db, filePath, tbl)
@@ -530,22 +733,67 @@ func loadTable(ctx context.Context, db *sql.DB, inputDir string, tbl tableConfig
 	return result
 }
 
-// findShardedFiles finds all shard files matching the pattern basename_*.csv or basename_*.csv.xz
+// validateCSVHeader reads the header row of a CSV (decompressing .xz files on the fly)
+// and checks it matches the expected column list exactly. LOAD DATA maps columns
+// positionally, so any difference would silently misalign data.
+func validateCSVHeader(ctx context.Context, filePath string, expected []string) error {
+	header, err := readCSVHeader(ctx, filePath)
+	if err != nil {
+		return fmt.Errorf("%s: failed to read header: %w", filepath.Base(filePath), err)
+	}
+
+	for i := 0; i < len(expected) || i < len(header); i++ {
+		var want, got string
+		if i < len(expected) {
+			want = expected[i]
+		}
+		if i < len(header) {
+			got = header[i]
+		}
+		if want != got {
+			return fmt.Errorf("%s: column mismatch at column %d: expected %q, found %q (expected %d columns, found %d)",
+				filepath.Base(filePath), i+1, want, got, len(expected), len(header))
+		}
+	}
+	return nil
+}
+
+// readCSVHeader returns the first record of a plain or xz-compressed CSV file
+func readCSVHeader(ctx context.Context, filePath string) ([]string, error) {
+	// Only the first line is needed; closing early stops xz
+	r, err := generator.OpenCSVFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readFirstRecord(r)
+}
+
+// readFirstRecord parses the first CSV record from r
+func readFirstRecord(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	if comma, ok := importFormat.fieldRune(); ok {
+		reader.Comma = comma
+	}
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("file is empty")
+	}
+	return header, err
+}
+
+// findShardedFiles finds all shard files matching the pattern basename_*.csv or basename_*.csv.xz,
+// including part files rolled over by --max-file-rows (transactions_001_002.csv) and those in
+// partition directories (transactions/2023-01/...)
 func findShardedFiles(inputDir, basename string) []string {
-	var files []string
+	inputDir = generator.TableDir(inputDir, basename)
 
 	// Check for compressed shards first
-	xzPattern := filepath.Join(inputDir, basename+"_*.csv.xz")
-	if matches, err := filepath.Glob(xzPattern); err == nil && len(matches) > 0 {
-		files = matches
-	}
+	files := globShards(inputDir, basename+"_*.csv.xz")
 
 	// If no compressed shards, check for uncompressed
 	if len(files) == 0 {
-		csvPattern := filepath.Join(inputDir, basename+"_*.csv")
-		if matches, err := filepath.Glob(csvPattern); err == nil {
-			files = matches
-		}
+		files = globShards(inputDir, basename+"_*.csv")
 	}
 
 	// Sort for consistent ordering (_001, _002, etc.)
@@ -602,7 +850,7 @@ func loadPlainFile(ctx context.Context, db *sql.DB, filePath string, tbl tableCo
 	mysql.RegisterLocalFile(absPath)
 	defer mysql.DeregisterLocalFile(absPath)
 
-	loadSQL := fmt.Sprintf(tbl.loadSQL, absPath)
+	loadSQL := tbl.sql(absPath)
 	res, err := db.ExecContext(ctx, loadSQL)
 	if err != nil {
 		printManualLoadCommand(filePath, tbl, false)
@@ -613,8 +861,63 @@ func loadPlainFile(ctx context.Context, db *sql.DB, filePath string, tbl tableCo
 	return rows, nil
 }
 
-// loadCompressedFile decompresses an xz file to a temp file, then loads it
+// readerSeq keeps reader handler names unique across concurrent loads
+var readerSeq atomic.Int64
+
+// loadCompressedFile streams `xz -d -c` straight into LOAD DATA through a
+// driver reader handler, so nothing is written to disk. If the driver never
+// asks for the reader (the hook isn't usable), it loads via a temp file.
 func loadCompressedFile(ctx context.Context, db *sql.DB, xzPath string, tbl tableConfig) (int64, error) {
+	name := fmt.Sprintf("loadgen_%s_%d", tbl.name, readerSeq.Add(1))
+
+	var stream io.ReadCloser
+	var openErr error
+	mysql.RegisterReaderHandler(name, func() io.Reader {
+		stream, openErr = generator.OpenCSVFile(ctx, xzPath)
+		if openErr != nil {
+			return errReader{openErr}
+		}
+		// Hide Close from the driver; xz's exit status is checked below
+		return struct{ io.Reader }{stream}
+	})
+	defer mysql.DeregisterReaderHandler(name)
+
+	loadSQL := tbl.sql("Reader::" + name)
+	res, err := db.ExecContext(ctx, loadSQL)
+	if stream != nil {
+		if closeErr := stream.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}
+	if stream == nil && openErr == nil && readerUnsupported(err, name) {
+		return loadCompressedFileViaTemp(ctx, db, xzPath, tbl)
+	}
+	if openErr != nil {
+		err = openErr
+	}
+	if err != nil {
+		printManualLoadCommand(xzPath, tbl, true)
+		return 0, fmt.Errorf("LOAD DATA failed: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	return rows, nil
+}
+
+// readerUnsupported reports whether err is the driver refusing the Reader::
+// handler registered as name, so the file has to go through a temp file.
+// Errors from the statement itself (syntax, missing table, permissions) are not.
+func readerUnsupported(err error, name string) bool {
+	return err != nil && strings.Contains(err.Error(), fmt.Sprintf("reader '%s' is not registered", name))
+}
+
+// errReader fails every read with err
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// loadCompressedFileViaTemp decompresses an xz file to a temp file, then loads it
+func loadCompressedFileViaTemp(ctx context.Context, db *sql.DB, xzPath string, tbl tableConfig) (int64, error) {
 	// Create temp file
 	tmpFile, err := os.CreateTemp("", fmt.Sprintf("loadgen_%s_*.csv", tbl.name))
 	if err != nil {
@@ -644,7 +947,7 @@ func loadCompressedFile(ctx context.Context, db *sql.DB, xzPath string, tbl tabl
 	mysql.RegisterLocalFile(absPath)
 	defer mysql.DeregisterLocalFile(absPath)
 
-	loadSQL := fmt.Sprintf(tbl.loadSQL, absPath)
+	loadSQL := tbl.sql(absPath)
 	res, err := db.ExecContext(ctx, loadSQL)
 	if err != nil {
 		printManualLoadCommand(xzPath, tbl, true)
@@ -759,13 +1062,13 @@ func printManualLoadCommand(filePath string, tbl tableConfig, isCompressed bool)
 
 	if isCompressed {
 		// Stream decompressed data directly via /dev/stdin
-		loadSQL := fmt.Sprintf(tbl.loadSQL, "/dev/stdin")
+		loadSQL := tbl.sql("/dev/stdin")
 		fmt.Printf("    xz -d -c %s | mariadb -u%s -p%s -h %s -P %s --local-infile=1 %s -e \"\n", absPath, user, pass, host, port, dbname)
 		fmt.Printf("    SET FOREIGN_KEY_CHECKS = 0;\n")
 		fmt.Printf("    %s;\n", loadSQL)
 		fmt.Println("    \"")
 	} else {
-		loadSQL := fmt.Sprintf(tbl.loadSQL, absPath)
+		loadSQL := tbl.sql(absPath)
 		fmt.Printf("    mariadb -u%s -p%s -h %s -P %s --local-infile=1 %s <<'EOF'\n", user, pass, host, port, dbname)
 		fmt.Printf("    SET FOREIGN_KEY_CHECKS = 0;\n")
 		fmt.Printf("    %s;\n", loadSQL)
@@ -774,6 +1077,46 @@ func printManualLoadCommand(filePath string, tbl tableConfig, isCompressed bool)
 	fmt.Println("    ─────────────────────────────────────────────")
 }
 
+// checkSchemaVersion compares the schema version recorded in _meta.csv with
+// the one this build's embedded schema expects. Returns false if the import
+// should stop; directories from before _meta.csv only get a warning.
+func checkSchemaVersion(u *ui.UI, dir string) bool {
+	meta, err := generator.ReadMeta(context.Background(), dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		u.Println(u.Warning(fmt.Sprintf("No %s in %s; cannot check it matches schema version %d",
+			generator.MetaFilename, dir, generator.SchemaVersion)))
+		return true
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	if meta.SchemaVersion == generator.SchemaVersion {
+		return true
+	}
+
+	msg := fmt.Sprintf("%s was generated with schema version %d (loadgen %s) but this build imports schema version %d",
+		dir, meta.SchemaVersion, meta.GeneratorVersion, generator.SchemaVersion)
+	if importIgnoreSchema {
+		u.Println(u.Warning(msg + "; importing anyway"))
+		return true
+	}
+	fmt.Fprintln(os.Stderr, u.Error(msg))
+	fmt.Fprintln(os.Stderr, "Regenerate with a matching loadgen, or pass --ignore-schema-version to import anyway")
+	return false
+}
+
+// globShards matches pattern in dir and in its partition subdirectories
+func globShards(dir, pattern string) []string {
+	var files []string
+	for _, p := range []string{filepath.Join(dir, pattern), filepath.Join(dir, "*", pattern)} {
+		if matches, err := filepath.Glob(p); err == nil {
+			files = append(files, matches...)
+		}
+	}
+	return files
+}
+
 func validateInputDir(dir string) error {
 	info, err := os.Stat(dir)
 	if os.IsNotExist(err) {
@@ -788,8 +1131,9 @@ func validateInputDir(dir string) error {
 
 	// Check for at least one expected file (including sharded files)
 	for _, tbl := range tablesToLoad {
-		csvPath := filepath.Join(dir, tbl.csvFile+".csv")
-		xzPath := filepath.Join(dir, tbl.csvFile+".csv.xz")
+		tableDir := generator.TableDir(dir, tbl.csvFile)
+		csvPath := filepath.Join(tableDir, tbl.csvFile+".csv")
+		xzPath := filepath.Join(tableDir, tbl.csvFile+".csv.xz")
 		if _, err := os.Stat(csvPath); err == nil {
 			return nil
 		}
@@ -807,13 +1151,13 @@ func validateInputDir(dir string) error {
 
 func hasCompressedFiles(dir string) bool {
 	for _, tbl := range tablesToLoad {
-		xzPath := filepath.Join(dir, tbl.csvFile+".csv.xz")
+		tableDir := generator.TableDir(dir, tbl.csvFile)
+		xzPath := filepath.Join(tableDir, tbl.csvFile+".csv.xz")
 		if _, err := os.Stat(xzPath); err == nil {
 			return true
 		}
 		// Check for sharded compressed files
-		xzPattern := filepath.Join(dir, tbl.csvFile+"_*.csv.xz")
-		if matches, err := filepath.Glob(xzPattern); err == nil && len(matches) > 0 {
+		if len(globShards(tableDir, tbl.csvFile+"_*.csv.xz")) > 0 {
 			return true
 		}
 	}
@@ -861,6 +1205,54 @@ func formatNumber(n int64) string {
 	return fmt.Sprintf("%.1fM", float64(n)/1000000)
 }
 
+// importSummary is the JSON form of the import summary written by --summary-json
+type importSummary struct {
+	Driver     string               `json:"driver"`
+	Input      string               `json:"input"`
+	DurationMS int64                `json:"duration_ms"`
+	TotalRows  int64                `json:"total_rows"`
+	Failed     int                  `json:"failed"`
+	Tables     []importTableSummary `json:"tables"`
+}
+
+type importTableSummary struct {
+	Table      string `json:"table"`
+	Rows       int64  `json:"rows"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// newImportSummary builds the --summary-json form of a finished import
+func newImportSummary(results []loadResult, totalDuration time.Duration) importSummary {
+	summary := importSummary{
+		Driver:     importDriver,
+		Input:      importInputDir,
+		DurationMS: totalDuration.Milliseconds(),
+	}
+	for _, r := range results {
+		table := importTableSummary{Table: r.table, Rows: r.rows, DurationMS: r.duration.Milliseconds()}
+		if r.err != nil {
+			table.Error = r.err.Error()
+			summary.Failed++
+		} else {
+			summary.TotalRows += r.rows
+		}
+		summary.Tables = append(summary.Tables, table)
+	}
+	return summary
+}
+
+// writeImportSummary writes the --summary-json file, if requested. A failed
+// write only warns, since the data is already loaded.
+func writeImportSummary(u *ui.UI, results []loadResult, totalDuration time.Duration) {
+	if importSummaryJSON == "" {
+		return
+	}
+	if err := writeSummaryJSON(importSummaryJSON, newImportSummary(results, totalDuration)); err != nil {
+		fmt.Fprintln(os.Stderr, u.Warning(err.Error()))
+	}
+}
+
 func printImportSummary(u *ui.UI, results []loadResult, totalDuration time.Duration) {
 	var totalRows int64
 	var failures int
@@ -888,6 +1280,11 @@ func printImportSummary(u *ui.UI, results []loadResult, totalDuration time.Durat
 	fmt.Println(u.SummaryBox("Import Summary", items))
 
 	if failures > 0 {
+		for _, r := range results {
+			if r.err != nil {
+				fmt.Fprintln(os.Stderr, u.Error(r.table+": "+r.err.Error()))
+			}
+		}
 		os.Exit(1)
 	}
 }
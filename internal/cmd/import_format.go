@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/willfong/load-generator/internal/generator"
+)
+
+// loadDataFormat holds the FIELDS and LINES options of the LOAD DATA statements
+type loadDataFormat struct {
+	fieldsTerminatedBy string
+	fieldsEnclosedBy   string
+	fieldsEscapedBy    string
+	linesTerminatedBy  string
+}
+
+// importFormat is the format the importer loads with, set from the flags
+var importFormat = csvWriterFormat()
+
+// csvWriterFormat returns the format CSVWriter writes by default. Fields
+// are optionally quoted with embedded quotes doubled, and nothing is
+// backslash-escaped, so ESCAPED BY is empty.
+func csvWriterFormat() loadDataFormat {
+	d := generator.DefaultCSVDialect()
+	return loadDataFormat{
+		fieldsTerminatedBy: string(d.FieldTerminator),
+		fieldsEnclosedBy:   string(generator.CSVEnclosure),
+		linesTerminatedBy:  d.LineTerminator(),
+	}
+}
+
+// clause renders the FIELDS and LINES options
+func (f loadDataFormat) clause() string {
+	return fmt.Sprintf("FIELDS TERMINATED BY %s\nENCLOSED BY %s\nESCAPED BY %s\nLINES TERMINATED BY %s",
+		sqlString(f.fieldsTerminatedBy), sqlString(f.fieldsEnclosedBy),
+		sqlString(f.fieldsEscapedBy), sqlString(f.linesTerminatedBy))
+}
+
+// fieldRune returns the field terminator as a rune for parsing CSV headers,
+// or false if it is longer than one character
+func (f loadDataFormat) fieldRune() (rune, bool) {
+	if utf8.RuneCountInString(f.fieldsTerminatedBy) != 1 {
+		return 0, false
+	}
+	r, _ := utf8.DecodeRuneInString(f.fieldsTerminatedBy)
+	return r, true
+}
+
+// sql fills in a table's LOAD DATA statement for the given file
+func (t tableConfig) sql(path string) string {
+	return fmt.Sprintf(t.loadSQL, path, importFormat.clause())
+}
+
+// parseLoadDataFormat builds the format from flag values, which may use
+// backslash escapes such as \t, \n and \r
+func parseLoadDataFormat(fieldsTerminatedBy, fieldsEnclosedBy, fieldsEscapedBy, linesTerminatedBy string) (loadDataFormat, error) {
+	f := loadDataFormat{
+		fieldsTerminatedBy: flagEscapes.Replace(fieldsTerminatedBy),
+		fieldsEnclosedBy:   flagEscapes.Replace(fieldsEnclosedBy),
+		fieldsEscapedBy:    flagEscapes.Replace(fieldsEscapedBy),
+		linesTerminatedBy:  flagEscapes.Replace(linesTerminatedBy),
+	}
+
+	if f.fieldsTerminatedBy == "" || f.linesTerminatedBy == "" {
+		return f, fmt.Errorf("--fields-terminated-by and --lines-terminated-by must not be empty")
+	}
+	if utf8.RuneCountInString(f.fieldsEnclosedBy) > 1 || utf8.RuneCountInString(f.fieldsEscapedBy) > 1 {
+		return f, fmt.Errorf("--fields-enclosed-by and --fields-escaped-by take at most one character")
+	}
+	return f, nil
+}
+
+// loadDataFormatFlags are the import flags that only shape the LOAD DATA statements
+var loadDataFormatFlags = []string{"fields-terminated-by", "fields-enclosed-by", "fields-escaped-by", "lines-terminated-by"}
+
+// checkSQLiteFormatFlags rejects the LOAD DATA format flags, which the
+// sqlite3 driver's CSV reader doesn't use, rather than ignoring them
+func checkSQLiteFormatFlags(cmd *cobra.Command) error {
+	for _, name := range loadDataFormatFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s only applies to LOAD DATA and cannot be used with --driver sqlite3", name)
+		}
+	}
+	return nil
+}
+
+// flagEscapes expands the backslash escapes accepted in format flags
+var flagEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\r`, "\r", `\0`, "\x00")
+
+// sqlString quotes s as a SQL string literal
+func sqlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case 0:
+			b.WriteString(`\0`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
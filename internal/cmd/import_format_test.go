@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLoadDataFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name                               string
+		terminated, enclosed, escaped, eol string
+		want                               loadDataFormat
+		wantErr                            string
+	}{
+		{
+			name: "writer defaults", terminated: ",", enclosed: `"`, eol: `\n`,
+			want: loadDataFormat{fieldsTerminatedBy: ",", fieldsEnclosedBy: `"`, linesTerminatedBy: "\n"},
+		},
+		{
+			name: "tab separated with CRLF", terminated: `\t`, eol: `\r\n`,
+			want: loadDataFormat{fieldsTerminatedBy: "\t", linesTerminatedBy: "\r\n"},
+		},
+		{
+			name: "escaped backslash and NUL", terminated: `\0`, escaped: `\\`, eol: `\n`,
+			want: loadDataFormat{fieldsTerminatedBy: "\x00", fieldsEscapedBy: `\`, linesTerminatedBy: "\n"},
+		},
+		{
+			name: "multi-character separators", terminated: "||", enclosed: "'", eol: `;\n`,
+			want: loadDataFormat{fieldsTerminatedBy: "||", fieldsEnclosedBy: "'", linesTerminatedBy: ";\n"},
+		},
+		{
+			name: "unknown escape kept", terminated: `\x`, eol: `\n`,
+			want: loadDataFormat{fieldsTerminatedBy: `\x`, linesTerminatedBy: "\n"},
+		},
+		{name: "empty field terminator", terminated: "", eol: `\n`, wantErr: "must not be empty"},
+		{name: "empty line terminator", terminated: ",", eol: "", wantErr: "must not be empty"},
+		{name: "multi-character enclosure", terminated: ",", enclosed: `""`, eol: `\n`, wantErr: "at most one character"},
+		{name: "multi-character escape", terminated: ",", escaped: `\t\t`, eol: `\n`, wantErr: "at most one character"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseLoadDataFormat(tc.terminated, tc.enclosed, tc.escaped, tc.eol)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("parseLoadDataFormat = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSQLString(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", `''`},
+		{",", `','`},
+		{`"`, `'"'`},
+		{"'", `'\''`},
+		{`\`, `'\\'`},
+		{"\t", `'\t'`},
+		{"\r\n", `'\r\n'`},
+		{"\x00", `'\0'`},
+		{"||", `'||'`},
+		{"é;", `'é;'`},
+	} {
+		if got := sqlString(tc.in); got != tc.want {
+			t.Errorf("sqlString(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestCheckSQLiteFormatFlags(t *testing.T) {
+	if err := checkSQLiteFormatFlags(importCmd); err != nil {
+		t.Fatalf("defaults rejected: %v", err)
+	}
+	for _, name := range loadDataFormatFlags {
+		t.Run(name, func(t *testing.T) {
+			f := importCmd.Flags().Lookup(name)
+			def := f.Value.String()
+			t.Cleanup(func() {
+				f.Value.Set(def)
+				f.Changed = false
+			})
+			if err := importCmd.Flags().Set(name, ";"); err != nil {
+				t.Fatal(err)
+			}
+			err := checkSQLiteFormatFlags(importCmd)
+			if err == nil || !strings.Contains(err.Error(), "--"+name) {
+				t.Errorf("error %v, want one naming --%s", err, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/willfong/load-generator/internal/generator"
+	"github.com/willfong/load-generator/internal/ui"
+	_ "modernc.org/sqlite" // Registers the pure-Go "sqlite" driver
+)
+
+// nullifPattern extracts the columns a LOAD DATA statement maps from empty strings to NULL
+var nullifPattern = regexp.MustCompile(`(?m)^\s*(\w+) = NULLIF\(`)
+
+// openSQLite opens (creating if needed) a SQLite database file for bulk
+// loading. SQLite has no LOAD DATA, so rows go in through a prepared INSERT,
+// one transaction per table. A single connection keeps the pragmas
+// in force for every statement.
+func openSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	// Bulk loading settings, mirroring the checks disabled for MySQL
+	for _, pragma := range []string{"PRAGMA journal_mode = MEMORY", "PRAGMA synchronous = OFF", "PRAGMA foreign_keys = OFF"} {
+		if _, err := db.ExecContext(ctx, pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("%s: %w", pragma, err)
+		}
+	}
+	return db, nil
+}
+
+// execSQLiteStatements runs statements one at a time, stopping at the first failure
+func execSQLiteStatements(ctx context.Context, db *sql.DB, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w\nStatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// runSQLiteImport imports the input directory into a local SQLite database file
+func runSQLiteImport(u *ui.UI) {
+	u.Println(u.Header("Bank-in-a-Box Data Importer"))
+	u.Println()
+	u.Println(u.KeyValue("Database", importDBConnection+" (sqlite3)"))
+	u.Println(u.KeyValue("Input", importInputDir))
+	u.Println()
+
+	if err := validateInputDir(importInputDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !checkSchemaVersion(u, importInputDir) {
+		os.Exit(1)
+	}
+	if hasCompressedFiles(importInputDir) {
+		if _, err := exec.LookPath("xz"); err != nil {
+			fmt.Fprintln(os.Stderr, "Error: xz not found but compressed files detected")
+			fmt.Fprintln(os.Stderr, "Install xz-utils (Linux) or xz (macOS via Homebrew)")
+			os.Exit(1)
+		}
+	}
+	content, err := schemaFS.ReadFile("schemas/schema_sqlite.sql")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Tables are created up front; indexes wait until the data is in
+	var tableStmts, indexStmts []string
+	for _, stmt := range splitSQLStatements(string(content)) {
+		stmt = strings.TrimSpace(stmt)
+		switch {
+		case strings.HasPrefix(strings.ToUpper(stmt), "CREATE TABLE"):
+			tableStmts = append(tableStmts, stmt)
+		case strings.HasPrefix(strings.ToUpper(stmt), "CREATE INDEX"):
+			indexStmts = append(indexStmts, stmt)
+		}
+	}
+
+	ctx := context.Background()
+	db, err := openSQLite(ctx, importDBConnection)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	spinTables := u.NewSpinner("Creating tables")
+	spinTables.Start()
+	if err := execSQLiteStatements(ctx, db, tableStmts); err != nil {
+		spinTables.Error("failed: " + err.Error())
+		os.Exit(1)
+	}
+	spinTables.Success("tables ready")
+
+	u.Section("Loading data...")
+	startTime := time.Now()
+	results, loadErr := loadSQLiteTables(ctx, db, importInputDir, tablesForInput(importInputDir), indexStmts, importContinueOnErr, u)
+	loadDuration := time.Since(startTime)
+
+	if loadErr != nil {
+		if importContinueOnErr {
+			fmt.Fprintln(os.Stderr, u.Error("Import finished with errors"))
+		} else {
+			fmt.Fprintln(os.Stderr, u.Error("Import stopped due to error: "+loadErr.Error()))
+		}
+		writeImportSummary(u, results, loadDuration)
+		printImportSummary(u, results, loadDuration)
+		os.Exit(1)
+	}
+
+	writeImportSummary(u, results, loadDuration)
+	printImportSummary(u, results, loadDuration)
+}
+
+// loadSQLiteTables loads the tables one at a time, as SQLite has a single
+// writer, then creates the indexes. The first table error stops the load
+// unless continueOnErr is set, in which case the remaining tables still load
+// and only the failed tables' indexes are skipped.
+func loadSQLiteTables(ctx context.Context, db *sql.DB, inputDir string, tables []tableConfig, indexStmts []string, continueOnErr bool, u *ui.UI) ([]loadResult, error) {
+	var results []loadResult
+	var loadErr error
+	for _, tbl := range tables {
+		result := loadSQLiteTable(ctx, db, inputDir, tbl, u)
+		results = append(results, result)
+		if result.err != nil {
+			loadErr = errors.Join(loadErr, fmt.Errorf("%s: %w", tbl.name, result.err))
+			if !continueOnErr {
+				return results, loadErr
+			}
+		}
+	}
+
+	// Tables that failed keep no indexes
+	failed := failedTables(results)
+	var stmts []string
+	for _, stmt := range indexStmts {
+		if !failed[indexStatementTable(stmt)] {
+			stmts = append(stmts, stmt)
+		}
+	}
+	u.Section("Creating indexes...")
+	if err := execSQLiteStatements(ctx, db, stmts); loadErr == nil {
+		loadErr = err
+	}
+	return results, loadErr
+}
+
+// loadSQLiteTable loads a table's shards (or single file) into SQLite in one
+// transaction, so a table that fails is rolled back and leaves no rows
+func loadSQLiteTable(ctx context.Context, db *sql.DB, inputDir string, tbl tableConfig, u *ui.UI) loadResult {
+	start := time.Now()
+	result := loadResult{table: tbl.name}
+
+	files := findShardedFiles(inputDir, tbl.csvFile)
+	if len(files) > 0 {
+		u.PrintShardLoading(tbl.name, len(files))
+	} else {
+		for _, ext := range []string{".csv.xz", ".csv"} {
+			path := filepath.Join(generator.TableDir(inputDir, tbl.csvFile), tbl.csvFile+ext)
+			if _, err := os.Stat(path); err == nil {
+				files = []string{path}
+				break
+			}
+		}
+	}
+	if len(files) == 0 {
+		if tbl.optional {
+			u.PrintSkipped(tbl.name, "no file")
+			return result
+		}
+		result.err = fmt.Errorf("file not found: %s.csv or %s.csv.xz", tbl.csvFile, tbl.csvFile)
+		u.PrintSkipped(tbl.name, "no file")
+		return result
+	}
+
+	// Validate every file before loading any of them
+	for _, f := range files {
+		if err := validateCSVHeader(ctx, f, tbl.headers); err != nil {
+			result.err = err
+			result.duration = time.Since(start)
+			u.PrintTableLoadResult(tbl.name, 0, result.duration, len(files), result.err)
+			return result
+		}
+	}
+
+	result.rows, result.err = insertSQLiteFiles(ctx, db, files, tbl)
+	result.duration = time.Since(start)
+	u.PrintTableLoadResult(tbl.name, result.rows, result.duration, len(files), result.err)
+	return result
+}
+
+// insertSQLiteFiles inserts the rows of a table's files in one transaction,
+// through a single prepared INSERT executed once per row
+func insertSQLiteFiles(ctx context.Context, db *sql.DB, files []string, tbl tableConfig) (rows int64, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, sqliteInsertSQL(tbl.name, tbl.headers))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	nullable := nullableColumns(tbl)
+	args := make([]any, 0, len(tbl.headers))
+	for i, f := range files {
+		err := generator.ReadCSVRows(ctx, f, tbl.headers, func(row []string) error {
+			args = appendSQLiteArgs(args[:0], row, nullable)
+			if _, err := stmt.ExecContext(ctx, args...); err != nil {
+				return fmt.Errorf("row %d: %w", rows+1, err)
+			}
+			rows++
+			return nil
+		})
+		if err != nil {
+			if len(files) > 1 {
+				return rows, fmt.Errorf("shard %d (%s): %w", i+1, filepath.Base(f), err)
+			}
+			return rows, fmt.Errorf("%s: %w", filepath.Base(f), err)
+		}
+	}
+	return rows, tx.Commit()
+}
+
+// sqliteInsertSQL returns a single-row INSERT with a placeholder per column
+func sqliteInsertSQL(table string, columns []string) string {
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.Repeat("?, ", len(columns)-1) + "?)"
+}
+
+// nullableColumns reports which of a table's columns its LOAD DATA
+// statement maps from empty strings to NULL
+func nullableColumns(tbl tableConfig) []bool {
+	nullable := make([]bool, len(tbl.headers))
+	for _, m := range nullifPattern.FindAllStringSubmatch(tbl.loadSQL, -1) {
+		for i, h := range tbl.headers {
+			if h == m[1] {
+				nullable[i] = true
+			}
+		}
+	}
+	return nullable
+}
+
+// appendSQLiteArgs appends a row's values as statement arguments. Empty
+// nullable fields become NULL, matching the NULLIF mapping used for MySQL.
+// Everything else is bound as text; column affinity converts numeric text.
+func appendSQLiteArgs(args []any, row []string, nullable []bool) []any {
+	for i, v := range row {
+		if v == "" && nullable[i] {
+			args = append(args, nil)
+		} else {
+			args = append(args, v)
+		}
+	}
+	return args
+}
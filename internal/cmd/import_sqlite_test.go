@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willfong/load-generator/internal/ui"
+)
+
+func TestNullableColumns(t *testing.T) {
+	var branches tableConfig
+	for _, tbl := range tablesToLoad {
+		if tbl.name == "branches" {
+			branches = tbl
+		}
+	}
+	nullable := nullableColumns(branches)
+	// Only the columns mapped through NULLIF in the LOAD DATA statement
+	want := map[string]bool{"id": false, "name": false, "address_line2": true, "state": true, "closed_at": true}
+	for i, h := range branches.headers {
+		if w, ok := want[h]; ok && nullable[i] != w {
+			t.Errorf("%s nullable = %v, want %v", h, nullable[i], w)
+		}
+	}
+
+	args := appendSQLiteArgs(nil, []string{"", "", "x"}, []bool{true, false, true})
+	if want := []any{nil, "", "x"}; !slices.Equal(args, want) {
+		t.Errorf("appendSQLiteArgs = %#v, want %#v", args, want)
+	}
+
+	if got, want := sqliteInsertSQL("t", []string{"a", "b"}), "INSERT INTO t (a, b) VALUES (?, ?)"; got != want {
+		t.Errorf("sqliteInsertSQL = %q, want %q", got, want)
+	}
+}
+
+func TestInsertSQLiteFiles(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	db, err := openSQLite(ctx, filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT NOT NULL, memo TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	tbl := tableConfig{
+		name:    "notes",
+		headers: []string{"id", "body", "memo"},
+		loadSQL: "(id, body, @memo)\nSET\n  memo = NULLIF(@memo, '')",
+	}
+
+	// Quotes, commas and empty fields
+	var b strings.Builder
+	b.WriteString("id,body,memo\n")
+	b.WriteString("1,\"it's \"\"quoted\"\", with commas\",\n")
+	b.WriteString("2,,Robert'); DROP TABLE notes;--\n")
+	rows := 50
+	for i := 3; i <= rows; i++ {
+		fmt.Fprintf(&b, "%d,row %d,m\n", i, i)
+	}
+	path := filepath.Join(dir, "notes.csv")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := insertSQLiteFiles(ctx, db, []string{path}, tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(rows) {
+		t.Errorf("inserted %d rows, want %d", n, rows)
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notes").Scan(&count); err != nil || count != rows {
+		t.Errorf("table has %d rows (%v), want %d", count, err, rows)
+	}
+	var body string
+	var memo sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT body, memo FROM notes WHERE id = 1").Scan(&body, &memo); err != nil {
+		t.Fatal(err)
+	}
+	if body != `it's "quoted", with commas` || memo.Valid {
+		t.Errorf("row 1 = %q, %v; want the quoted body and a NULL memo", body, memo)
+	}
+	// An empty field in a column without NULLIF stays an empty string
+	if err := db.QueryRowContext(ctx, "SELECT body, memo FROM notes WHERE id = 2").Scan(&body, &memo); err != nil {
+		t.Fatal(err)
+	}
+	if body != "" || memo.String != "Robert'); DROP TABLE notes;--" {
+		t.Errorf("row 2 = %q, %q", body, memo.String)
+	}
+
+	// A failing file rolls the whole table back
+	if _, err := db.ExecContext(ctx, "DELETE FROM notes"); err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(dir, "bad.csv")
+	if err := os.WriteFile(bad, []byte("id,body,memo\n1,a,\n1,b,\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := insertSQLiteFiles(ctx, db, []string{path, bad}, tbl); err == nil || !strings.Contains(err.Error(), "shard 2 (bad.csv)") {
+		t.Errorf("error %v, want a duplicate key in shard 2", err)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notes").Scan(&count); err != nil || count != 0 {
+		t.Errorf("table kept %d rows after a failed load (%v)", count, err)
+	}
+}
+
+func TestLoadSQLiteTablesContinueOnError(t *testing.T) {
+	ctx := context.Background()
+	input := t.TempDir()
+	// The middle table has a duplicate key, so its insert fails
+	files := map[string]string{
+		"notes.csv": "id,body\n1,a\n2,b\n",
+		"tags.csv":  "id,body\n1,a\n1,b\n",
+		"memos.csv": "id,body\n1,a\n2,b\n3,c\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(input, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var tables []tableConfig
+	var indexStmts []string
+	for _, name := range []string{"notes", "tags", "memos"} {
+		tables = append(tables, tableConfig{name: name, csvFile: name, headers: []string{"id", "body"}})
+		indexStmts = append(indexStmts, fmt.Sprintf("CREATE INDEX idx_%s_body ON %s(body)", name, name))
+	}
+
+	load := func(t *testing.T, continueOnErr bool) (*sql.DB, []loadResult, error) {
+		db, err := openSQLite(ctx, filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		for _, tbl := range tables {
+			if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY, body TEXT)", tbl.name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		results, err := loadSQLiteTables(ctx, db, input, tables, indexStmts, continueOnErr, ui.New())
+		return db, results, err
+	}
+	count := func(t *testing.T, db *sql.DB, query string) int {
+		var n int
+		if err := db.QueryRowContext(ctx, query).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	t.Run("ContinueOnError", func(t *testing.T) {
+		db, results, err := load(t, true)
+		if err == nil || !strings.Contains(err.Error(), "tags:") {
+			t.Fatalf("error %v, want the tags failure", err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("got %d results, want all 3 tables attempted", len(results))
+		}
+		if failed := failedTables(results); len(failed) != 1 || !failed["tags"] {
+			t.Errorf("failed tables = %v, want only tags", failed)
+		}
+		for table, want := range map[string]int{"notes": 2, "tags": 0, "memos": 3} {
+			if got := count(t, db, "SELECT COUNT(*) FROM "+table); got != want {
+				t.Errorf("%s has %d rows, want %d", table, got, want)
+			}
+		}
+		// Only the loaded tables are indexed
+		for table, want := range map[string]int{"notes": 1, "tags": 0, "memos": 1} {
+			if got := count(t, db, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND tbl_name = '"+table+"'"); got != want {
+				t.Errorf("%s has %d indexes, want %d", table, got, want)
+			}
+		}
+	})
+
+	t.Run("StopOnError", func(t *testing.T) {
+		db, results, err := load(t, false)
+		if err == nil {
+			t.Fatal("expected the tags failure")
+		}
+		if len(results) != 2 {
+			t.Errorf("got %d results, want the load to stop at tags", len(results))
+		}
+		if got := count(t, db, "SELECT COUNT(*) FROM memos"); got != 0 {
+			t.Errorf("memos has %d rows after the load stopped, want 0", got)
+		}
+		if got := count(t, db, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'index'"); got != 0 {
+			t.Errorf("%d indexes created after the load stopped, want 0", got)
+		}
+	})
+}
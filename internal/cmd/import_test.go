@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/willfong/load-generator/internal/generator"
+)
+
+func TestValidateCSVHeader(t *testing.T) {
+	dir := t.TempDir()
+	expected := []string{"id", "name", "created_at"}
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		wantErr string // Empty when the header is valid
+	}{
+		{"Match", "id,name,created_at\n1,a,2024-01-01\n", ""},
+		{"Reordered", "id,created_at,name\n", `column 2: expected "name", found "created_at"`},
+		{"Missing", "id,name\n", `column 3: expected "created_at", found "" (expected 3 columns, found 2)`},
+		{"Extra", "id,name,created_at,status\n", `column 4: expected "", found "status"`},
+		{"Empty", "", "file is empty"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name+".csv")
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := validateCSVHeader(context.Background(), path, expected)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("error %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportSummaryJSON(t *testing.T) {
+	results := []loadResult{
+		{table: "customers", rows: 100, duration: 1500 * time.Millisecond},
+		{table: "transactions", rows: 40, duration: 2 * time.Second, err: errors.New("duplicate key")},
+	}
+	path := filepath.Join(t.TempDir(), "summary.json")
+	if err := writeSummaryJSON(path, newImportSummary(results, 5*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Decoded generically so renamed or missing keys are caught
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{"duration_ms": 5000.0, "total_rows": 100.0, "failed": 1.0} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+	for _, key := range []string{"driver", "input"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("missing %s", key)
+		}
+	}
+	tables, _ := got["tables"].([]any)
+	if len(tables) != 2 {
+		t.Fatalf("tables = %v, want 2 entries", got["tables"])
+	}
+	ok := tables[0].(map[string]any)
+	if ok["table"] != "customers" || ok["rows"] != 100.0 || ok["duration_ms"] != 1500.0 {
+		t.Errorf("tables[0] = %v", ok)
+	}
+	if _, has := ok["error"]; has {
+		t.Error("a loaded table should have no error key")
+	}
+	if failed := tables[1].(map[string]any); failed["error"] != "duplicate key" {
+		t.Errorf("tables[1] = %v, want its error", failed)
+	}
+}
+
+func TestTablesForInput(t *testing.T) {
+	headers := func(tables []tableConfig, name string) ([]string, bool) {
+		for _, tbl := range tables {
+			if tbl.name == name {
+				return tbl.headers, tbl.optional
+			}
+		}
+		t.Fatalf("no %s table", name)
+		return nil, false
+	}
+
+	// Without a manifest the default layout is expected
+	if got, _ := headers(tablesForInput(t.TempDir()), "transactions"); len(got) != len(generator.TransactionHeaders()) {
+		t.Errorf("transactions without a manifest have %d columns, want %d", len(got), len(generator.TransactionHeaders()))
+	}
+
+	dir := t.TempDir()
+	if err := generator.WriteManifestFile(dir, generator.Manifest{
+		GeoCoordinates: true,
+		Continuation:   &generator.ManifestContinuation{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tables := tablesForInput(dir)
+	if got, optional := headers(tables, "transactions"); !slices.Equal(got, generator.TransactionGeoHeaders()) || optional {
+		t.Errorf("transactions = %v (optional %v), want the geo headers", got, optional)
+	}
+	if _, optional := headers(tables, "customers"); !optional {
+		t.Error("a continuation's customers should be optional")
+	}
+}
+
+func TestReaderUnsupported(t *testing.T) {
+	const name = "loadgen_customers_1"
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		// go-sql-driver's error when the handler can't be served: fall back to a temp file
+		{fmt.Errorf("reader '%s' is not registered", name), true},
+		// Errors from the statement are returned as they are
+		{&mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax"}, false},
+		{&mysql.MySQLError{Number: 1146, Message: "Table 'bank.customers' doesn't exist"}, false},
+		{&mysql.MySQLError{Number: 1045, Message: "Access denied for user 'loadgen'"}, false},
+		{fmt.Errorf("reader 'loadgen_accounts_2' is not registered"), false},
+		{nil, false},
+	} {
+		if got := readerUnsupported(tc.err, name); got != tc.want {
+			t.Errorf("readerUnsupported(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestIndexStatementTable(t *testing.T) {
+	for _, tc := range []struct {
+		stmt, want string
+	}{
+		{"CREATE INDEX idx_branches_country ON branches(country);", "branches"},
+		{"CREATE INDEX IF NOT EXISTS idx_atm_events_atm_time ON atm_events(atm_id, timestamp)", "atm_events"},
+		{"create index idx_accounts_type on accounts (type)", "accounts"},
+		{"CREATE INDEX idx_transfers_on_date\n  ON transfers(created_at)", "transfers"},
+		{"ANALYZE TABLE audit_logs;", "audit_logs"},
+		{"analyze  table disputes", "disputes"},
+		{"USE bank;", ""},
+		{"", ""},
+	} {
+		if got := indexStatementTable(tc.stmt); got != tc.want {
+			t.Errorf("indexStatementTable(%q) = %q, want %q", tc.stmt, got, tc.want)
+		}
+	}
+
+	// Every statement in the index schemas names a table that is loaded
+	known := make(map[string]bool)
+	for _, tbl := range tablesToLoad {
+		known[tbl.name] = true
+	}
+	for _, file := range []string{"schemas/schema_indexes.sql", "schemas/schema_sqlite.sql"} {
+		content, err := schemaFS.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, stmt := range splitSQLStatements(string(content)) {
+			stmt = strings.TrimSpace(stmt)
+			upper := strings.ToUpper(stmt)
+			if !strings.HasPrefix(upper, "CREATE INDEX") && !strings.HasPrefix(upper, "ANALYZE TABLE") {
+				continue
+			}
+			if table := indexStatementTable(stmt); !known[table] {
+				t.Errorf("%s: %q names table %q, want a loaded table", file, stmt, table)
+			}
+		}
+	}
+}
+
+func TestFailedTables(t *testing.T) {
+	failed := failedTables([]loadResult{
+		{table: "customers", rows: 100},
+		{table: "accounts", err: errors.New("file not found")},
+		{table: "transactions", rows: 40, err: errors.New("duplicate key")},
+		{table: "transfers"},
+	})
+	if len(failed) != 2 || !failed["accounts"] || !failed["transactions"] {
+		t.Errorf("failedTables = %v, want accounts and transactions", failed)
+	}
+	if got := failedTables(nil); len(got) != 0 {
+		t.Errorf("failedTables(nil) = %v, want none", got)
+	}
+}
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/willfong/load-generator/internal/ui"
 )
 
 var verbose bool
+var quiet bool
+var logLevel string
 var noColor bool
 
 // rootCmd represents the base command when called without any subcommands
@@ -26,6 +31,13 @@ Tunable parameters are in internal/config/defaults.go - edit and recompile.
 Example usage:
   loadgen generate --customers 100000 --years 5
   loadgen simulate --concurrency 1000 --db "user:pass@tcp(host:3306)/bank"`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if quiet && verbose {
+			return fmt.Errorf("--quiet and --verbose cannot be combined")
+		}
+		_, err := outputLevel()
+		return err
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -34,7 +46,9 @@ func Execute() error {
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output (same as --log-level verbose)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "print only final summaries and errors (same as --log-level quiet)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "output level: quiet, normal, verbose or debug (overrides --quiet and --verbose)")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colors and animations")
 
 	// Silence usage on error - we'll print our own messages
@@ -46,10 +60,48 @@ func init() {
 
 // Verbose returns whether verbose mode is enabled
 func Verbose() bool {
-	return verbose
+	level, _ := outputLevel()
+	return level >= ui.LevelVerbose
+}
+
+// outputLevel resolves --log-level, --quiet and --verbose
+func outputLevel() (ui.Level, error) {
+	switch {
+	case logLevel != "":
+		return ui.ParseLevel(logLevel)
+	case quiet:
+		return ui.LevelQuiet, nil
+	case verbose:
+		return ui.LevelVerbose, nil
+	}
+	return ui.LevelNormal, nil
+}
+
+// newUI creates the terminal UI with --no-color and the output level applied
+func newUI() *ui.UI {
+	u := ui.New()
+	if noColor {
+		u.SetNoColor(true)
+	}
+	// Validated by the root command before any subcommand runs
+	level, _ := outputLevel()
+	u.SetLevel(level)
+	return u
 }
 
 // Exit with code
 func Exit(code int) {
 	os.Exit(code)
 }
+
+// writeSummaryJSON writes a command's summary as indented JSON for --summary-json
+func writeSummaryJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
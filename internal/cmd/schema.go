@@ -7,7 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
-	"github.com/willfong/load-generator/internal/ui"
+	"github.com/willfong/load-generator/internal/generator"
 )
 
 //go:embed schemas/*.sql
@@ -17,12 +17,15 @@ var schemaFS embed.FS
 var schemaCmd = &cobra.Command{
 	Use:   "schema [type]",
 	Short: "Output database schema files",
-	Long: `Output the SQL schema for setting up the database.
+	Long: `Output the SQL schema for setting up the database, with --format json
+a machine-readable description of the generated CSV tables, or with
+--format csv a data dictionary of their enumerated values.
 
 Available schema types:
   full      Complete schema with tables and indexes (default)
   tables    Tables only, no indexes (for bulk loading)
   indexes   Indexes only (run after bulk data load)
+  sqlite    SQLite-compatible tables and indexes
 
 The schema is designed for MariaDB 11.8+ but should work with MySQL 8+.
 
@@ -32,50 +35,74 @@ Bulk Loading Strategy:
   2. Load data using LOAD DATA INFILE
   3. Create indexes: loadgen schema indexes | mysql ...
 
+JSON Format:
+  --format json lists every table's columns in CSV order with their type
+  (integer, number, boolean, string, date or datetime), nullability and,
+  for enumerated columns such as transaction types, statuses and channels,
+  the allowed values. Transactions are described as generated without
+  --geo-coordinates, which appends nullable number columns latitude and
+  longitude.
+
+CSV Format:
+  --format csv lists every enumerated type (transaction types, channels,
+  statuses, segments, audit actions, ...) with one row per allowed value:
+  enum,value,columns, where columns names the table.column pairs holding it.
+
 Examples:
   loadgen schema                        # Output complete schema
   loadgen schema full > schema.sql      # Save full schema to file
   loadgen schema tables | mysql -u root bank  # Create tables only
-  loadgen schema indexes                # Output index creation SQL`,
+  loadgen schema indexes                # Output index creation SQL
+  loadgen schema sqlite | sqlite3 bank.db     # Create a local SQLite database
+  loadgen schema --format json > tables.json  # Column types and enums for ETL
+  loadgen schema --format csv > enums.csv     # Enum data dictionary`,
 	Args: cobra.MaximumNArgs(1),
 	Run:  runSchema,
 }
 
-var schemaOutputFile string
+var (
+	schemaOutputFile string
+	schemaFormat     string
+)
 
 func init() {
 	rootCmd.AddCommand(schemaCmd)
 	schemaCmd.Flags().StringVarP(&schemaOutputFile, "output", "o", "", "output file (default: stdout)")
+	schemaCmd.Flags().StringVar(&schemaFormat, "format", "sql", "output format: sql (database schema), json (generated table and column description) or csv (enum data dictionary)")
 }
 
 func runSchema(cmd *cobra.Command, args []string) {
-	u := ui.New()
-	if noColor {
-		u.SetNoColor(true)
-	}
+	u := newUI()
 
 	schemaType := "full"
 	if len(args) > 0 {
 		schemaType = args[0]
 	}
 
-	var filename string
-	switch schemaType {
-	case "full":
-		filename = "schemas/schema.sql"
-	case "tables":
-		filename = "schemas/schema_no_indexes.sql"
-	case "indexes":
-		filename = "schemas/schema_indexes.sql"
+	var content []byte
+	var err error
+	switch schemaFormat {
+	case "json":
+		if len(args) > 0 {
+			fmt.Fprintln(os.Stderr, u.Error("Schema types apply to --format sql only"))
+			os.Exit(1)
+		}
+		content, err = generator.DataSchemaJSON()
+	case "csv":
+		if len(args) > 0 {
+			fmt.Fprintln(os.Stderr, u.Error("Schema types apply to --format sql only"))
+			os.Exit(1)
+		}
+		content, err = generator.EnumDictionaryCSV()
+	case "sql":
+		content, err = sqlSchema(schemaType)
 	default:
-		fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("Unknown schema type '%s'", schemaType)))
-		fmt.Fprintln(os.Stderr, "Valid types: full, tables, indexes")
+		fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("Unknown format '%s'", schemaFormat)))
+		fmt.Fprintln(os.Stderr, "Valid formats: sql, json, csv")
 		os.Exit(1)
 	}
-
-	content, err := schemaFS.ReadFile(filename)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("Reading schema: %v", err)))
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
 		os.Exit(1)
 	}
 
@@ -98,3 +125,26 @@ func runSchema(cmd *cobra.Command, args []string) {
 		fmt.Print(string(content))
 	}
 }
+
+// sqlSchema returns the embedded SQL schema of the given type
+func sqlSchema(schemaType string) ([]byte, error) {
+	var filename string
+	switch schemaType {
+	case "full":
+		filename = "schemas/schema.sql"
+	case "tables":
+		filename = "schemas/schema_no_indexes.sql"
+	case "indexes":
+		filename = "schemas/schema_indexes.sql"
+	case "sqlite":
+		filename = "schemas/schema_sqlite.sql"
+	default:
+		return nil, fmt.Errorf("unknown schema type '%s' (valid types: full, tables, indexes, sqlite)", schemaType)
+	}
+
+	content, err := schemaFS.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema: %w", err)
+	}
+	return content, nil
+}
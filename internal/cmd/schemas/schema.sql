@@ -103,6 +103,20 @@ CREATE TABLE IF NOT EXISTS atms (
     FOREIGN KEY (branch_id) REFERENCES branches(id) ON DELETE SET NULL
 ) ENGINE=InnoDB;
 
+CREATE TABLE IF NOT EXISTS atm_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    atm_id BIGINT NOT NULL,
+
+    -- Event and the ATM status it leaves behind
+    type ENUM('cash_low', 'offline', 'online') NOT NULL,
+    status ENUM('online', 'offline', 'maintenance', 'out_of_cash') NOT NULL,
+    cash_level INT NOT NULL,  -- Percent of capacity
+
+    timestamp TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (atm_id) REFERENCES atms(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
 -- ============================================
 -- CUSTOMERS
 -- ============================================
@@ -134,11 +148,16 @@ CREATE TABLE IF NOT EXISTS customers (
     status ENUM('active', 'inactive', 'suspended', 'closed') NOT NULL DEFAULT 'active',
     activity_score DECIMAL(3, 2) DEFAULT 0.50,  -- 0.00 to 1.00
 
-    -- Authentication (hashed values)
-    username VARCHAR(100) NOT NULL UNIQUE,
-    password_hash VARCHAR(255) NOT NULL,
+    -- Authentication (hashed values; NULL username and password without online banking)
+    username VARCHAR(100) UNIQUE,
+    password_hash VARCHAR(255),
     pin VARCHAR(255) NOT NULL,  -- Hashed PIN for ATM
 
+    -- KYC verification (NULL unless generated with --kyc)
+    kyc_status ENUM('unverified', 'pending', 'verified', 'rejected'),
+    kyc_document_type ENUM('passport', 'national_id', 'drivers_license', 'residence_permit'),
+    kyc_verified_at TIMESTAMP NULL,
+
     -- Timestamps
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -284,6 +303,10 @@ CREATE TABLE IF NOT EXISTS transactions (
     -- Error info
     failure_reason VARCHAR(255),
 
+    -- Where the transaction was made (NULL without --geo-coordinates and for channels without a location)
+    latitude DECIMAL(10, 8),
+    longitude DECIMAL(11, 8),
+
     FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
     FOREIGN KEY (counterparty_account_id) REFERENCES accounts(id) ON DELETE SET NULL,
     FOREIGN KEY (beneficiary_id) REFERENCES beneficiaries(id) ON DELETE SET NULL,
@@ -292,6 +315,61 @@ CREATE TABLE IF NOT EXISTS transactions (
     -- Note: linked_transaction_id FK omitted to allow bulk loading
 ) ENGINE=InnoDB;
 
+-- ============================================
+-- TRANSFERS (generate --transfers)
+-- One row per internal transfer, normalized from its transaction legs
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS transfers (
+    id BIGINT PRIMARY KEY,  -- The originating leg's transaction ID
+    reference_number VARCHAR(30) NOT NULL,
+
+    -- Money moves from one account to the other
+    from_account_id BIGINT NOT NULL,
+    to_account_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    status ENUM('pending', 'completed', 'failed', 'reversed', 'declined') NOT NULL DEFAULT 'completed',
+
+    -- The double-entry legs (no credit leg when the transfer was declined)
+    debit_transaction_id BIGINT NOT NULL,
+    credit_transaction_id BIGINT,
+
+    timestamp TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (from_account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (to_account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (debit_transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (credit_transaction_id) REFERENCES transactions(id) ON DELETE SET NULL
+) ENGINE=InnoDB;
+
+-- ============================================
+-- DISPUTES (generate --dispute-rate)
+-- One row per dispute case against a completed purchase
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS disputes (
+    id BIGINT PRIMARY KEY,  -- The disputed purchase's transaction ID
+    case_number VARCHAR(40) NOT NULL,
+
+    transaction_id BIGINT NOT NULL,
+    account_id BIGINT NOT NULL,
+    customer_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    reason ENUM('fraud', 'not_received', 'not_as_described', 'duplicate', 'incorrect_amount') NOT NULL,
+    status ENUM('filed', 'under_review', 'won', 'lost') NOT NULL DEFAULT 'filed',
+
+    -- Lifecycle (stages not reached by the end of the history are NULL)
+    filed_at TIMESTAMP NOT NULL,
+    review_started_at TIMESTAMP NULL,
+    resolved_at TIMESTAMP NULL,
+
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
 -- ============================================
 -- AUDIT LOG
 -- ============================================
@@ -317,6 +395,7 @@ CREATE TABLE IF NOT EXISTS audit_logs (
         -- Account management
         'account_opened', 'account_closed', 'account_updated',
         'beneficiary_added', 'beneficiary_removed',
+        'customer_suspended', 'customer_closed', 'kyc_submitted', 'kyc_verified', 'kyc_rejected',
         -- Profile
         'profile_viewed', 'profile_updated', 'address_changed', 'contact_changed',
         -- Sessions
@@ -372,12 +451,14 @@ CREATE INDEX idx_branches_status ON branches(status);
 -- ATMs
 CREATE INDEX idx_atms_status ON atms(status);
 CREATE INDEX idx_atms_country ON atms(country);
+CREATE INDEX idx_atm_events_atm_time ON atm_events(atm_id, timestamp);
 
 -- Customers
 CREATE INDEX idx_customers_country ON customers(country);
 CREATE INDEX idx_customers_segment ON customers(segment);
 CREATE INDEX idx_customers_status ON customers(status);
 CREATE INDEX idx_customers_email ON customers(email);
+CREATE INDEX idx_customers_kyc_status ON customers(kyc_status);
 
 -- Accounts
 CREATE INDEX idx_accounts_customer ON accounts(customer_id);
@@ -399,6 +480,17 @@ CREATE INDEX idx_transactions_channel ON transactions(channel);
 CREATE INDEX idx_transactions_value_date ON transactions(value_date);
 CREATE INDEX idx_transactions_counterparty ON transactions(counterparty_account_id);
 
+-- Transfers
+CREATE INDEX idx_transfers_from_account ON transfers(from_account_id);
+CREATE INDEX idx_transfers_to_account ON transfers(to_account_id);
+CREATE INDEX idx_transfers_timestamp ON transfers(timestamp);
+
+-- Disputes
+CREATE INDEX idx_disputes_transaction ON disputes(transaction_id);
+CREATE INDEX idx_disputes_account ON disputes(account_id);
+CREATE INDEX idx_disputes_status ON disputes(status);
+CREATE INDEX idx_disputes_filed ON disputes(filed_at);
+
 -- Audit logs
 CREATE INDEX idx_audit_timestamp ON audit_logs(timestamp);
 CREATE INDEX idx_audit_customer ON audit_logs(customer_id);
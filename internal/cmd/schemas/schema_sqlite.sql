@@ -0,0 +1,314 @@
+-- Bank-in-a-Box Load Generator - SQLite Schema
+-- Lightweight local variant for demos and CI (loadgen import --driver sqlite3)
+--
+-- Usage:
+--   sqlite3 bank.db < schema_sqlite.sql
+--
+-- Types follow SQLite affinity rules: BIGINT/INT become INTEGER, VARCHAR/ENUM
+-- become TEXT, DECIMAL becomes REAL. Timestamps are stored as
+-- 'YYYY-MM-DD HH:MM:SS' text, which sorts and compares correctly.
+
+-- ============================================
+-- BRANCHES AND ATMS
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS branches (
+    id INTEGER PRIMARY KEY,
+    branch_code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'full',
+    status TEXT NOT NULL DEFAULT 'open',
+    address_line1 TEXT NOT NULL,
+    address_line2 TEXT,
+    city TEXT NOT NULL,
+    state TEXT,
+    postal_code TEXT,
+    country TEXT NOT NULL,
+    latitude REAL,
+    longitude REAL,
+    timezone TEXT NOT NULL,
+    monday_hours TEXT,
+    tuesday_hours TEXT,
+    wednesday_hours TEXT,
+    thursday_hours TEXT,
+    friday_hours TEXT,
+    saturday_hours TEXT,
+    sunday_hours TEXT,
+    phone TEXT,
+    email TEXT,
+    customer_capacity INTEGER DEFAULT 100,
+    atm_count INTEGER DEFAULT 2,
+    opened_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TEXT,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS atms (
+    id INTEGER PRIMARY KEY,
+    atm_id TEXT NOT NULL UNIQUE,
+    branch_id INTEGER REFERENCES branches(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'online',
+    location_name TEXT,
+    address_line1 TEXT NOT NULL,
+    city TEXT NOT NULL,
+    state TEXT,
+    postal_code TEXT,
+    country TEXT NOT NULL,
+    latitude REAL,
+    longitude REAL,
+    timezone TEXT NOT NULL,
+    supports_deposit INTEGER DEFAULT 0,
+    supports_transfer INTEGER DEFAULT 0,
+    is_24_hours INTEGER DEFAULT 1,
+    avg_daily_transactions INTEGER DEFAULT 50,
+    installed_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS atm_events (
+    id INTEGER PRIMARY KEY,
+    atm_id INTEGER NOT NULL REFERENCES atms(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    status TEXT NOT NULL,
+    cash_level INTEGER NOT NULL,
+    timestamp TEXT NOT NULL
+);
+
+-- ============================================
+-- CUSTOMERS
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS customers (
+    id INTEGER PRIMARY KEY,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    phone TEXT,
+    date_of_birth TEXT,
+    address_line1 TEXT,
+    address_line2 TEXT,
+    city TEXT,
+    state TEXT,
+    postal_code TEXT,
+    country TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    home_branch_id INTEGER REFERENCES branches(id) ON DELETE SET NULL,
+    segment TEXT NOT NULL DEFAULT 'regular',
+    status TEXT NOT NULL DEFAULT 'active',
+    activity_score REAL DEFAULT 0.50,
+    username TEXT UNIQUE,
+    password_hash TEXT,
+    pin TEXT NOT NULL,
+    kyc_status TEXT,
+    kyc_document_type TEXT,
+    kyc_verified_at TEXT,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- ACCOUNTS
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id INTEGER PRIMARY KEY,
+    account_number TEXT NOT NULL UNIQUE,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    currency TEXT NOT NULL DEFAULT 'USD',
+    balance INTEGER NOT NULL DEFAULT 0,
+    credit_limit INTEGER DEFAULT 0,
+    overdraft_limit INTEGER DEFAULT 0,
+    daily_withdraw_limit INTEGER DEFAULT 50000,
+    daily_transfer_limit INTEGER DEFAULT 500000,
+    interest_rate INTEGER DEFAULT 0,
+    branch_id INTEGER REFERENCES branches(id) ON DELETE SET NULL,
+    opened_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TEXT,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- BENEFICIARIES (External Payees)
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS beneficiaries (
+    id INTEGER PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    nickname TEXT,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'individual',
+    status TEXT NOT NULL DEFAULT 'verified',
+    bank_name TEXT,
+    bank_code TEXT,
+    routing_number TEXT,
+    account_number TEXT,
+    iban TEXT,
+    address_line1 TEXT,
+    address_line2 TEXT,
+    city TEXT,
+    state TEXT,
+    postal_code TEXT,
+    country TEXT,
+    currency TEXT DEFAULT 'USD',
+    payment_method TEXT DEFAULT 'ach',
+    account_reference TEXT,
+    last_used_at TEXT,
+    transfer_count INTEGER DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- TRANSACTIONS
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id INTEGER PRIMARY KEY,
+    reference_number TEXT NOT NULL,  -- Shared by both legs of a transfer, so not UNIQUE
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    counterparty_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+    beneficiary_id INTEGER REFERENCES beneficiaries(id) ON DELETE SET NULL,
+    type TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'completed',
+    channel TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    balance_after INTEGER NOT NULL,
+    description TEXT,
+    metadata TEXT,
+    branch_id INTEGER REFERENCES branches(id) ON DELETE SET NULL,
+    atm_id INTEGER REFERENCES atms(id) ON DELETE SET NULL,
+    linked_transaction_id INTEGER,
+    timestamp TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    posted_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    value_date TEXT NOT NULL,
+    failure_reason TEXT,
+
+    -- Where the transaction was made (NULL without --geo-coordinates and for channels without a location)
+    latitude REAL,
+    longitude REAL
+);
+
+-- ============================================
+-- TRANSFERS (generate --transfers)
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS transfers (
+    id INTEGER PRIMARY KEY,  -- The originating leg's transaction ID
+    reference_number TEXT NOT NULL,
+    from_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    to_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    status TEXT NOT NULL DEFAULT 'completed',
+    debit_transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    credit_transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    timestamp TEXT NOT NULL
+);
+
+-- ============================================
+-- DISPUTES (generate --dispute-rate)
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS disputes (
+    id INTEGER PRIMARY KEY,  -- The disputed purchase's transaction ID
+    case_number TEXT NOT NULL,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'filed',
+    filed_at TEXT NOT NULL,
+    review_started_at TEXT,
+    resolved_at TEXT
+);
+
+-- ============================================
+-- AUDIT LOG
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id INTEGER PRIMARY KEY,
+    timestamp TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL,
+    employee_id INTEGER,
+    system_id TEXT,
+    action TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    branch_id INTEGER REFERENCES branches(id) ON DELETE SET NULL,
+    atm_id INTEGER REFERENCES atms(id) ON DELETE SET NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    beneficiary_id INTEGER REFERENCES beneficiaries(id) ON DELETE SET NULL,
+    description TEXT,
+    failure_reason TEXT,
+    metadata TEXT,
+    session_id TEXT,
+    risk_score REAL,
+    request_id TEXT
+);
+
+-- ============================================
+-- INDEXES
+-- Note: For bulk loading, create these AFTER data load
+-- ============================================
+
+-- Branches
+CREATE INDEX IF NOT EXISTS idx_branches_country ON branches(country);
+CREATE INDEX IF NOT EXISTS idx_branches_status ON branches(status);
+
+-- ATMs
+CREATE INDEX IF NOT EXISTS idx_atms_status ON atms(status);
+CREATE INDEX IF NOT EXISTS idx_atms_country ON atms(country);
+CREATE INDEX IF NOT EXISTS idx_atm_events_atm_time ON atm_events(atm_id, timestamp);
+
+-- Customers
+CREATE INDEX IF NOT EXISTS idx_customers_country ON customers(country);
+CREATE INDEX IF NOT EXISTS idx_customers_segment ON customers(segment);
+CREATE INDEX IF NOT EXISTS idx_customers_status ON customers(status);
+CREATE INDEX IF NOT EXISTS idx_customers_email ON customers(email);
+CREATE INDEX IF NOT EXISTS idx_customers_kyc_status ON customers(kyc_status);
+
+-- Accounts
+CREATE INDEX IF NOT EXISTS idx_accounts_customer ON accounts(customer_id);
+CREATE INDEX IF NOT EXISTS idx_accounts_type ON accounts(type);
+CREATE INDEX IF NOT EXISTS idx_accounts_status ON accounts(status);
+CREATE INDEX IF NOT EXISTS idx_accounts_branch ON accounts(branch_id);
+
+-- Beneficiaries
+CREATE INDEX IF NOT EXISTS idx_beneficiaries_customer ON beneficiaries(customer_id);
+CREATE INDEX IF NOT EXISTS idx_beneficiaries_status ON beneficiaries(status);
+
+-- Transactions
+CREATE INDEX IF NOT EXISTS idx_transactions_reference ON transactions(reference_number);
+CREATE INDEX IF NOT EXISTS idx_transactions_account ON transactions(account_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_timestamp ON transactions(timestamp);
+CREATE INDEX IF NOT EXISTS idx_transactions_account_timestamp ON transactions(account_id, timestamp);
+CREATE INDEX IF NOT EXISTS idx_transactions_type ON transactions(type);
+CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions(status);
+CREATE INDEX IF NOT EXISTS idx_transactions_channel ON transactions(channel);
+CREATE INDEX IF NOT EXISTS idx_transactions_value_date ON transactions(value_date);
+CREATE INDEX IF NOT EXISTS idx_transactions_counterparty ON transactions(counterparty_account_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_from_account ON transfers(from_account_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_to_account ON transfers(to_account_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_timestamp ON transfers(timestamp);
+CREATE INDEX IF NOT EXISTS idx_disputes_transaction ON disputes(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_disputes_account ON disputes(account_id);
+CREATE INDEX IF NOT EXISTS idx_disputes_status ON disputes(status);
+CREATE INDEX IF NOT EXISTS idx_disputes_filed ON disputes(filed_at);
+
+-- Audit logs
+CREATE INDEX IF NOT EXISTS idx_audit_timestamp ON audit_logs(timestamp);
+CREATE INDEX IF NOT EXISTS idx_audit_customer ON audit_logs(customer_id);
+CREATE INDEX IF NOT EXISTS idx_audit_action ON audit_logs(action);
+CREATE INDEX IF NOT EXISTS idx_audit_outcome ON audit_logs(outcome);
+CREATE INDEX IF NOT EXISTS idx_audit_session ON audit_logs(session_id);
+CREATE INDEX IF NOT EXISTS idx_audit_account ON audit_logs(account_id);
+CREATE INDEX IF NOT EXISTS idx_audit_transaction ON audit_logs(transaction_id);
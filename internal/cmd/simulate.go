@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -12,19 +15,28 @@ import (
 	"github.com/willfong/load-generator/internal/config"
 	"github.com/willfong/load-generator/internal/database"
 	"github.com/willfong/load-generator/internal/simulator"
-	"github.com/willfong/load-generator/internal/ui"
 )
 
 var (
 	// Simulation parameters (frequently changed)
-	concurrency  int
-	simSeed      int64
-	dbConnection string
-	duration     string
+	concurrency    int
+	simSeed        int64
+	dbConnection   string
+	duration       string
+	maxOps         int64
+	sessionMix     string
+	accelerate     float64
+	drainTimeout   time.Duration
+	simSummaryJSON string
+	opLogFile      string
+	histogramFile  string
 
 	// Database pool settings
-	dbMaxOpenConns int
-	dbMaxIdleConns int
+	dbMaxOpenConns      int
+	dbMaxIdleConns      int
+	warmUp              time.Duration
+	warmUpConns         int
+	healthCheckInterval time.Duration
 )
 
 // simulateCmd represents the simulate command
@@ -38,15 +50,20 @@ This command runs a continuous simulation of:
 - Online banking sessions (logins, transfers, history views)
 - Business account activity (payroll, merchant transactions)
 
-Session ratios, burst settings, and error rates are in config/defaults.go.
+Default session ratios (override with --session-mix), burst settings, and error
+rates are in config/defaults.go.
 
-The simulation runs indefinitely until interrupted (Ctrl+C).
+The simulation runs until interrupted (Ctrl+C), or until --max-duration or
+--max-operations is reached, then shuts down gracefully and prints final stats.
 
 Example:
   loadgen simulate --db "user:pass@tcp(localhost:3306)/bank"
   loadgen simulate --concurrency 1000 --db "..."
   loadgen simulate --duration 1h --db "..."
-  loadgen simulate --seed 42 --db "..."`,
+  loadgen simulate --max-operations 100000 --db "..."
+  loadgen simulate --session-mix atm=1,online=8,business=1 --db "..."
+  loadgen simulate --seed 42 --db "..."
+  loadgen simulate --accelerate 1440 --db "..."   # a simulated day per minute`,
 	Run: runSimulate,
 }
 
@@ -57,44 +74,96 @@ func init() {
 	simulateCmd.Flags().Int64Var(&simSeed, "seed", 0, "random seed for reproducibility (0 = random)")
 	simulateCmd.Flags().StringVar(&dbConnection, "db", "", "database connection string (required)")
 	simulateCmd.Flags().StringVar(&duration, "duration", "", "simulation duration (e.g., 1h, 30m). Empty = run until killed")
+	simulateCmd.Flags().StringVar(&duration, "max-duration", "", "alias for --duration")
+	simulateCmd.Flags().Int64Var(&maxOps, "max-operations", 0, "stop after this many operations (0 = unlimited)")
+	simulateCmd.Flags().StringVar(&sessionMix, "session-mix", "", "relative session type weights, adjusted for time of day (e.g. atm=1,online=8,business=1; unlisted types get 0)")
+	simulateCmd.Flags().Float64Var(&accelerate, "accelerate", 1, "run the scheduler's clock N times faster than real time (1 = real time)")
+	simulateCmd.Flags().DurationVar(&drainTimeout, "drain-timeout", config.GracefulShutdownTimeout, "how long to wait for in-flight sessions on shutdown")
+	simulateCmd.Flags().StringVar(&simSummaryJSON, "summary-json", "", "also write the final statistics as JSON to this path")
+	simulateCmd.Flags().StringVar(&histogramFile, "latency-histogram", "", "also write each operation type's full latency histogram (log-linear buckets, ~1.6% precision) as CSV to this path")
+	simulateCmd.Flags().StringVar(&opLogFile, "op-log", "", "record every operation (type, account, amount, result, latency) to this CSV file for debugging; adds write overhead")
 	simulateCmd.Flags().IntVar(&dbMaxOpenConns, "db-max-open", config.DBMaxOpenConns, "max open database connections")
 	simulateCmd.Flags().IntVar(&dbMaxIdleConns, "db-max-idle", config.DBMaxIdleConns, "max idle database connections")
+	simulateCmd.Flags().DurationVar(&warmUp, "warm-up", config.DBWarmUpDuration, "before sessions start, spend this long opening and pinging pool connections so early latency isn't skewed by cold connections (0 = no warm-up)")
+	simulateCmd.Flags().IntVar(&warmUpConns, "warm-up-connections", 0, "connections --warm-up opens (0 = --db-max-open)")
+	simulateCmd.Flags().DurationVar(&healthCheckInterval, "health-check-interval", config.DBHealthCheckInterval, "ping idle pool connections this often and evict dead ones (0 = never)")
 
 	simulateCmd.MarkFlagRequired("db")
 }
 
 func runSimulate(cmd *cobra.Command, args []string) {
 	// Initialize UI
-	u := ui.New()
-	if noColor {
-		u.SetNoColor(true)
-	}
-
-	fmt.Println(u.Header("Bank-in-a-Box Load Simulator"))
-	fmt.Println()
-	fmt.Println(u.KeyValue("Concurrency", fmt.Sprintf("%d sessions", concurrency)))
-	fmt.Println(u.KeyValue("R/W Ratio", fmt.Sprintf("%.0f:1", config.ReadWriteRatio)))
-	fmt.Println(u.KeyValue("Session Mix", fmt.Sprintf("ATM %.0f%% / Online %.0f%% / Business %.0f%%",
-		config.ATMSessionRatio*100,
-		config.OnlineSessionRatio*100,
-		config.BusinessSessionRatio*100)))
-	fmt.Println(u.KeyValue("DB Pool", fmt.Sprintf("%d open / %d idle", dbMaxOpenConns, dbMaxIdleConns)))
+	u := newUI()
+
+	// Build simulation config from defaults
+	simConfig := buildSimulateConfig()
+
+	if sessionMix != "" {
+		atm, online, business, err := parseSessionMix(sessionMix)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("Invalid --session-mix: %v", err)))
+			return
+		}
+		simConfig.ATMSessionRatio = atm
+		simConfig.OnlineSessionRatio = online
+		simConfig.BusinessSessionRatio = business
+	}
+
+	u.Println(u.Header("Bank-in-a-Box Load Simulator"))
+	u.Println()
+	u.Println(u.KeyValue("Concurrency", fmt.Sprintf("%d sessions", concurrency)))
+	u.Println(u.KeyValue("R/W Ratio", fmt.Sprintf("%.0f:1", config.ReadWriteRatio)))
+	u.Println(u.KeyValue("Session Mix", fmt.Sprintf("ATM %.0f%% / Online %.0f%% / Business %.0f%%",
+		simConfig.ATMSessionRatio*100,
+		simConfig.OnlineSessionRatio*100,
+		simConfig.BusinessSessionRatio*100)))
+	u.Println(u.KeyValue("DB Pool", fmt.Sprintf("%d open / %d idle", dbMaxOpenConns, dbMaxIdleConns)))
+	if warmUp > 0 {
+		u.Println(u.KeyValue("Warm-up", fmt.Sprintf("%s, %d connections", warmUp, cmp.Or(warmUpConns, dbMaxOpenConns))))
+	}
+	if healthCheckInterval > 0 {
+		u.Println(u.KeyValue("Health checks", fmt.Sprintf("every %s", healthCheckInterval)))
+	}
 	if simSeed != 0 {
-		fmt.Println(u.KeyValue("Seed", fmt.Sprintf("%d", simSeed)))
+		u.Println(u.KeyValue("Seed", fmt.Sprintf("%d", simSeed)))
 	}
 	if duration != "" {
-		fmt.Println(u.KeyValue("Duration", duration))
+		u.Println(u.KeyValue("Duration", duration))
 	} else {
-		fmt.Println(u.KeyValue("Duration", "until stopped (Ctrl+C)"))
+		u.Println(u.KeyValue("Duration", "until stopped (Ctrl+C)"))
 	}
-	fmt.Println()
+	if maxOps > 0 {
+		u.Println(u.KeyValue("Max Operations", fmt.Sprintf("%d", maxOps)))
+	}
+	if accelerate > 1 {
+		u.Println(u.KeyValue("Clock", fmt.Sprintf("%gx real time", accelerate)))
+	}
+	if opLogFile != "" {
+		u.Println(u.KeyValue("Op log", opLogFile))
+	}
+	u.Println()
 
-	// Build simulation config from defaults
-	simConfig := buildSimulateConfig()
+	if accelerate < 1 {
+		fmt.Fprintln(os.Stderr, u.Error("--accelerate must be at least 1"))
+		return
+	}
+	if maxOps < 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--max-operations must not be negative"))
+		return
+	}
+	if drainTimeout <= 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--drain-timeout must be positive"))
+		return
+	}
+	if warmUp < 0 || warmUpConns < 0 || healthCheckInterval < 0 {
+		fmt.Fprintln(os.Stderr, u.Error("--warm-up, --warm-up-connections and --health-check-interval must not be negative"))
+		return
+	}
 
 	// Override with CLI values
 	simConfig.NumSessions = concurrency
 	simConfig.Seed = simSeed
+	simConfig.Quiet = u.Quiet()
 
 	if duration != "" {
 		d, err := time.ParseDuration(duration)
@@ -134,7 +203,7 @@ func runSimulate(cmd *cobra.Command, args []string) {
 		return
 	}
 	spin.Success("connected!")
-	fmt.Println()
+	u.Println()
 
 	// Create and start session manager
 	manager := simulator.NewSessionManager(pool, simConfig, simSeed)
@@ -151,29 +220,64 @@ func runSimulate(cmd *cobra.Command, args []string) {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
-	// Duration timer if set
-	var durationCh <-chan time.Time
-	if simConfig.Duration > 0 {
-		timer := time.NewTimer(simConfig.Duration)
-		defer timer.Stop()
-		durationCh = timer.C
+	// Wait for shutdown signal, or for the manager to stop itself when
+	// --duration or --max-operations is reached
+	select {
+	case <-sigCh:
+		u.Println()
+		u.Println(u.Warning("Received shutdown signal"))
+	case <-manager.Done():
+		u.Println()
+		u.Println(u.Success("Stop condition reached"))
 	}
 
-	// Wait for shutdown signal or duration
+	// Graceful shutdown (a stop condition has already run it)
 	select {
-	case <-sigCh:
-		fmt.Println()
-		fmt.Println(u.Warning("Received shutdown signal"))
-	case <-durationCh:
-		fmt.Println()
-		fmt.Println(u.Success(fmt.Sprintf("Duration (%s) reached", duration)))
-	}
-
-	// Graceful shutdown
-	spinStop := u.NewSpinner("Stopping simulation")
-	spinStop.Start()
-	manager.Stop()
-	spinStop.Success("stopped")
+	case <-manager.Done():
+	default:
+		spinStop := u.NewSpinner("Stopping simulation")
+		spinStop.Start()
+		manager.Stop()
+		spinStop.Success("stopped")
+	}
+
+	if simSummaryJSON != "" {
+		if err := writeSummaryJSON(simSummaryJSON, manager.Summary()); err != nil {
+			fmt.Fprintln(os.Stderr, u.Warning(err.Error()))
+		}
+	}
+	if histogramFile != "" {
+		if err := manager.WriteLatencyHistograms(histogramFile); err != nil {
+			fmt.Fprintln(os.Stderr, u.Warning(err.Error()))
+		}
+	}
+}
+
+// parseSessionMix parses "atm=1,online=8,business=1" into weights normalized
+// to sum to 1. Unlisted session types get 0.
+func parseSessionMix(s string) (atm, online, business float64, err error) {
+	weights := map[string]*float64{"atm": &atm, "online": &online, "business": &business}
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return 0, 0, 0, fmt.Errorf("expected type=weight, got %q", part)
+		}
+		w, found := weights[strings.ToLower(strings.TrimSpace(name))]
+		if !found {
+			return 0, 0, 0, fmt.Errorf("unknown session type %q (want atm, online or business)", name)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || v < 0 {
+			return 0, 0, 0, fmt.Errorf("invalid weight %q for %s", value, name)
+		}
+		*w = v
+	}
+
+	total := atm + online + business
+	if total <= 0 {
+		return 0, 0, 0, fmt.Errorf("weights must not all be zero")
+	}
+	return atm / total, online / total, business / total, nil
 }
 
 // buildSimulateConfig creates a SimulateConfig from compile-time defaults
@@ -187,6 +291,7 @@ func buildSimulateConfig() config.SimulateConfig {
 		BusinessSessionRatio:  config.BusinessSessionRatio,
 		ActiveHourStart:       config.ActiveHourStart,
 		ActiveHourEnd:         config.ActiveHourEnd,
+		ClockAcceleration:     accelerate,
 		MinThinkTime:          config.MinThinkTime,
 		MaxThinkTime:          config.MaxThinkTime,
 		EnablePayrollBurst:    config.EnablePayrollBurst,
@@ -197,7 +302,16 @@ func buildSimulateConfig() config.SimulateConfig {
 		FailedLoginRate:        config.SimFailedLoginRate,
 		InsufficientFundsRate:  config.SimInsufficientFundsRate,
 		TimeoutRate:            config.SimTimeoutRate,
+		DeadlockRate:           config.SimDeadlockRate,
+		DuplicateKeyRate:       config.SimDuplicateKeyRate,
+		ConnectionResetRate:    config.SimConnectionResetRate,
+		MaxOperations:          maxOps,
 		MetricsInterval:        config.MetricsInterval,
+		DrainTimeout:           drainTimeout,
+		WarmUpDuration:         warmUp,
+		WarmUpConnections:      warmUpConns,
+		HealthCheckInterval:    healthCheckInterval,
+		OpLogFile:              opLogFile,
 		EnableRamp:             config.EnableRamp,
 		RampUpDuration:         config.RampUpDuration,
 		RampDownDuration:       config.RampDownDuration,
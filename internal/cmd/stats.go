@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/willfong/load-generator/internal/generator"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/ui"
+)
+
+var (
+	statsInputDir     string
+	statsTopMerchants int
+)
+
+// statsTables lists the generated files in the order they are reported
+var statsTables = []string{
+	"branches", "atms", "atm_events", "customers", "businesses", "accounts",
+	"beneficiaries", "transactions", "transfers", "disputes", "audit_logs",
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize a generated data directory",
+	Long: `Summarize generated CSV data without a database.
+
+Streams every file (plain .csv or xz-compressed .csv.xz, including shards) and
+reports row counts per table plus transaction date range, amount statistics,
+type distribution and top merchants. Use it as a sanity check before importing.
+
+Examples:
+  loadgen stats
+  loadgen stats --input ./my-data`,
+	Run: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsInputDir, "input", "./output", "input directory containing CSV files")
+	statsCmd.Flags().IntVar(&statsTopMerchants, "top", 10, "number of top merchants to show")
+}
+
+// transactionStats accumulates the transaction summary in one pass
+type transactionStats struct {
+	rows        int64
+	first, last string // Timestamps sort lexically in the CSV format
+	completed   int64
+	amountSum   int64
+	amountMin   int64
+	amountMax   int64
+	byType      map[string]int64
+	byMerchant  map[string]int64
+}
+
+func (s *transactionStats) add(row []string) error {
+	txnType, status, amountStr, description, ts := row[0], row[1], row[2], row[3], row[4]
+
+	s.rows++
+	s.byType[txnType]++
+	if s.first == "" || ts < s.first {
+		s.first = ts
+	}
+	if ts > s.last {
+		s.last = ts
+	}
+
+	if models.TransactionStatus(status) != models.TxStatusCompleted {
+		return nil
+	}
+	amount, err := strconv.ParseInt(amountStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q: %w", amountStr, err)
+	}
+	if s.completed == 0 || amount < s.amountMin {
+		s.amountMin = amount
+	}
+	if amount > s.amountMax {
+		s.amountMax = amount
+	}
+	s.amountSum += amount
+	s.completed++
+
+	if models.TransactionType(txnType) == models.TxTypePurchase {
+		if merchant, ok := strings.CutPrefix(description, "POS Purchase - "); ok {
+			s.byMerchant[merchant]++
+		}
+	}
+	return nil
+}
+
+func runStats(cmd *cobra.Command, args []string) {
+	u := newUI()
+
+	u.Println(u.Header("Bank-in-a-Box Data Stats"))
+	u.Println()
+	u.Println(u.KeyValue("Input", statsInputDir))
+	u.Println()
+
+	if err := validateInputDir(statsInputDir); err != nil {
+		fmt.Fprintln(os.Stderr, u.Error(err.Error()))
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	txns := &transactionStats{byType: make(map[string]int64), byMerchant: make(map[string]int64)}
+	startTime := time.Now()
+
+	u.Section("Row counts")
+	for _, table := range statsTables {
+		files := statsFiles(statsInputDir, table)
+		if len(files) == 0 {
+			fmt.Println(u.KeyValue(table, u.Muted("not found")))
+			continue
+		}
+
+		var rows int64
+		var err error
+		for _, file := range files {
+			if table == "transactions" {
+				err = generator.ReadCSVRows(ctx, file, []string{"type", "status", "amount", "description", "timestamp"}, txns.add)
+				rows = txns.rows
+			} else {
+				err = generator.ReadCSVRows(ctx, file, nil, func([]string) error {
+					rows++
+					return nil
+				})
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, u.Error(fmt.Sprintf("%s: %v", filepath.Base(file), err)))
+				os.Exit(1)
+			}
+		}
+
+		value := fmt.Sprintf("%d", rows)
+		if len(files) > 1 {
+			value += fmt.Sprintf(" (%d shards)", len(files))
+		}
+		fmt.Println(u.KeyValue(table, value))
+	}
+
+	if txns.rows > 0 {
+		printTransactionStats(u, txns)
+	}
+
+	u.Println()
+	u.Println(u.Muted(fmt.Sprintf("Scanned in %s", time.Since(startTime).Round(time.Millisecond))))
+}
+
+// printTransactionStats prints the transaction summary sections
+func printTransactionStats(u *ui.UI, s *transactionStats) {
+	u.Section("Transactions")
+	fmt.Println(u.KeyValue("Date range", fmt.Sprintf("%s to %s", s.first, s.last)))
+	if s.completed > 0 {
+		// Amounts mix currencies; minor units are shown as major units
+		fmt.Println(u.KeyValue("Completed", fmt.Sprintf("%d of %d", s.completed, s.rows)))
+		fmt.Println(u.KeyValue("Amount min", formatMinorUnits(s.amountMin)))
+		fmt.Println(u.KeyValue("Amount max", formatMinorUnits(s.amountMax)))
+		fmt.Println(u.KeyValue("Amount mean", formatMinorUnits(s.amountSum/s.completed)))
+	}
+
+	u.Section("Transaction types")
+	for _, e := range sortedCounts(s.byType, 0) {
+		fmt.Println(u.KeyValue(e.key, fmt.Sprintf("%d (%.1f%%)", e.count, float64(e.count)/float64(s.rows)*100)))
+	}
+
+	if len(s.byMerchant) > 0 {
+		u.Section("Top merchants")
+		for _, e := range sortedCounts(s.byMerchant, statsTopMerchants) {
+			fmt.Println(u.KeyValue(e.key, fmt.Sprintf("%d purchases", e.count)))
+		}
+	}
+}
+
+// statsFiles returns the shard files for a table, or its single CSV file
+func statsFiles(dir, table string) []string {
+	if shards := findShardedFiles(dir, table); len(shards) > 0 {
+		return shards
+	}
+	for _, ext := range []string{".csv", ".csv.xz"} {
+		path := filepath.Join(generator.TableDir(dir, table), table+ext)
+		if _, err := os.Stat(path); err == nil {
+			return []string{path}
+		}
+	}
+	return nil
+}
+
+type countEntry struct {
+	key   string
+	count int64
+}
+
+// sortedCounts orders a count map by descending count (ties by key), keeping at most limit entries (0 = all)
+func sortedCounts(counts map[string]int64, limit int) []countEntry {
+	entries := make([]countEntry, 0, len(counts))
+	for k, v := range counts {
+		entries = append(entries, countEntry{k, v})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].key < entries[j].key
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// formatMinorUnits formats cents as a decimal amount
+func formatMinorUnits(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
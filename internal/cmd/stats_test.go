@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTransactionStats(t *testing.T) {
+	s := &transactionStats{byType: make(map[string]int64), byMerchant: make(map[string]int64)}
+	rows := [][]string{
+		{"purchase", "completed", "1250", "POS Purchase - Corner Cafe", "2024-03-02 09:00:00"},
+		{"purchase", "completed", "750", "POS Purchase - Corner Cafe", "2024-01-15 12:00:00"},
+		{"purchase", "declined", "999999", "POS Purchase - Jeweller", "2024-02-01 10:00:00"},
+		{"deposit", "completed", "100000", "Payroll", "2024-04-30 08:00:00"},
+	}
+	for _, row := range rows {
+		if err := s.add(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Declined transactions count towards rows and types but not amounts or merchants
+	if s.rows != 4 || s.completed != 3 {
+		t.Errorf("counted %d rows and %d completed, want 4 and 3", s.rows, s.completed)
+	}
+	if s.first != "2024-01-15 12:00:00" || s.last != "2024-04-30 08:00:00" {
+		t.Errorf("date range %s to %s", s.first, s.last)
+	}
+	if s.amountMin != 750 || s.amountMax != 100000 || s.amountSum != 102000 {
+		t.Errorf("amounts min %d, max %d, sum %d", s.amountMin, s.amountMax, s.amountSum)
+	}
+	if s.byType["purchase"] != 3 || s.byType["deposit"] != 1 {
+		t.Errorf("types %v", s.byType)
+	}
+	if len(s.byMerchant) != 1 || s.byMerchant["Corner Cafe"] != 2 {
+		t.Errorf("merchants %v, want Corner Cafe twice", s.byMerchant)
+	}
+
+	if err := s.add([]string{"purchase", "completed", "12.50", "", "2024-01-01 00:00:00"}); err == nil {
+		t.Error("expected an error for a non-integer amount")
+	}
+}
+
+func TestStatsFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"customers.csv", "transactions_002.csv", "transactions_001.csv", "audit_logs.csv.xz"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for table, want := range map[string][]string{
+		"customers":    {"customers.csv"},
+		"transactions": {"transactions_001.csv", "transactions_002.csv"},
+		"audit_logs":   {"audit_logs.csv.xz"},
+		"branches":     nil,
+	} {
+		var got []string
+		for _, f := range statsFiles(dir, table) {
+			got = append(got, filepath.Base(f))
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("statsFiles(%s) = %v, want %v", table, got, want)
+		}
+	}
+}
+
+func TestSortedCounts(t *testing.T) {
+	got := sortedCounts(map[string]int64{"b": 2, "a": 2, "c": 5, "d": 1}, 3)
+	want := []countEntry{{"c", 5}, {"a", 2}, {"b", 2}}
+	if !slices.Equal(got, want) {
+		t.Errorf("sortedCounts = %v, want %v", got, want)
+	}
+	if n := len(sortedCounts(map[string]int64{"a": 1, "b": 1}, 0)); n != 2 {
+		t.Errorf("limit 0 kept %d entries, want all 2", n)
+	}
+}
+
+func TestFormatMinorUnits(t *testing.T) {
+	for cents, want := range map[int64]string{0: "0.00", 5: "0.05", 123456: "1234.56", -250: "-2.50"} {
+		if got := formatMinorUnits(cents); got != want {
+			t.Errorf("formatMinorUnits(%d) = %q, want %q", cents, got, want)
+		}
+	}
+}
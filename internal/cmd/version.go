@@ -5,7 +5,6 @@ import (
 	"runtime"
 
 	"github.com/spf13/cobra"
-	"github.com/willfong/load-generator/internal/ui"
 )
 
 // Version information - set at build time via ldflags
@@ -20,10 +19,7 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
 	Run: func(cmd *cobra.Command, args []string) {
-		u := ui.New()
-		if noColor {
-			u.SetNoColor(true)
-		}
+		u := newUI()
 
 		fmt.Println(u.Header("Bank-in-a-Box Load Generator"))
 		fmt.Println()
@@ -85,8 +85,9 @@ type SimulateConfig struct {
 	OnlineSessionRatio  float64 `mapstructure:"online_session_ratio"`
 	BusinessSessionRatio float64 `mapstructure:"business_session_ratio"`
 
-	// Timing
-	Duration         time.Duration `mapstructure:"duration"` // 0 = run until killed
+	// Timing and stop conditions
+	Duration         time.Duration `mapstructure:"duration"`       // 0 = run until killed
+	MaxOperations    int64         `mapstructure:"max_operations"` // 0 = unlimited
 	MinThinkTime     time.Duration `mapstructure:"min_think_time"`
 	MaxThinkTime     time.Duration `mapstructure:"max_think_time"`
 
@@ -94,6 +95,9 @@ type SimulateConfig struct {
 	ActiveHourStart int `mapstructure:"active_hour_start"` // 0-23
 	ActiveHourEnd   int `mapstructure:"active_hour_end"`   // 0-23
 
+	// Scheduler clock speed relative to wall-clock time (1 = real time)
+	ClockAcceleration float64 `mapstructure:"clock_acceleration"`
+
 	// Burst settings
 	EnablePayrollBurst bool    `mapstructure:"enable_payroll_burst"`
 	EnableLunchBurst   bool    `mapstructure:"enable_lunch_burst"`
@@ -123,8 +127,29 @@ type SimulateConfig struct {
 	InsufficientFundsRate float64 `mapstructure:"insufficient_funds_rate"`
 	TimeoutRate           float64 `mapstructure:"timeout_rate"`
 
+	// Injected write failures (0.0-1.0 per write attempt)
+	DeadlockRate        float64 `mapstructure:"deadlock_rate"`
+	DuplicateKeyRate    float64 `mapstructure:"duplicate_key_rate"`
+	ConnectionResetRate float64 `mapstructure:"connection_reset_rate"`
+
 	// Metrics
 	MetricsInterval time.Duration `mapstructure:"metrics_interval"`
+
+	// Graceful shutdown: how long Stop waits for in-flight sessions
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+
+	// Connection pool warm-up before sessions start (0 = none), the
+	// connections it opens (0 = the pool's max open connections), and how
+	// often idle connections are health-checked (0 = never)
+	WarmUpDuration      time.Duration `mapstructure:"warm_up_duration"`
+	WarmUpConnections   int           `mapstructure:"warm_up_connections"`
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
+
+	// Only print the final summary and errors, not progress and status lines
+	Quiet bool `mapstructure:"quiet"`
+
+	// Record every executed operation to this CSV file (empty = off)
+	OpLogFile string `mapstructure:"op_log_file"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -164,6 +189,7 @@ func DefaultConfig() *Config {
 			MaxThinkTime:         5 * time.Second,
 			ActiveHourStart:      8,
 			ActiveHourEnd:        16,
+			ClockAcceleration:    1,
 			// Burst settings
 			EnablePayrollBurst:       true,
 			EnableLunchBurst:         true,
@@ -188,7 +214,11 @@ func DefaultConfig() *Config {
 			FailedLoginRate:      0.02,
 			InsufficientFundsRate: 0.01,
 			TimeoutRate:          0.001,
+			DeadlockRate:         0.001,
+			DuplicateKeyRate:     0.0005,
+			ConnectionResetRate:  0.0005,
 			MetricsInterval:      5 * time.Second,
+			DrainTimeout:         30 * time.Second,
 		},
 		DataDir: "./data",
 		Verbose: false,
@@ -261,6 +291,15 @@ func (c *Config) Validate() error {
 	if c.Simulate.ActiveHourEnd < 0 || c.Simulate.ActiveHourEnd > 23 {
 		errs = append(errs, "simulate.active_hour_end must be 0-23")
 	}
+	if c.Simulate.MaxOperations < 0 {
+		errs = append(errs, "simulate.max_operations must not be negative")
+	}
+	if c.Simulate.DrainTimeout < 0 {
+		errs = append(errs, "simulate.drain_timeout must not be negative")
+	}
+	if c.Simulate.ClockAcceleration < 1 {
+		errs = append(errs, "simulate.clock_acceleration must be at least 1")
+	}
 
 	// Validate error rates
 	if c.Simulate.FailedLoginRate < 0 || c.Simulate.FailedLoginRate > 1 {
@@ -272,6 +311,15 @@ func (c *Config) Validate() error {
 	if c.Simulate.TimeoutRate < 0 || c.Simulate.TimeoutRate > 1 {
 		errs = append(errs, "simulate.timeout_rate must be between 0.0 and 1.0")
 	}
+	if c.Simulate.DeadlockRate < 0 || c.Simulate.DeadlockRate > 1 {
+		errs = append(errs, "simulate.deadlock_rate must be between 0.0 and 1.0")
+	}
+	if c.Simulate.DuplicateKeyRate < 0 || c.Simulate.DuplicateKeyRate > 1 {
+		errs = append(errs, "simulate.duplicate_key_rate must be between 0.0 and 1.0")
+	}
+	if c.Simulate.ConnectionResetRate < 0 || c.Simulate.ConnectionResetRate > 1 {
+		errs = append(errs, "simulate.connection_reset_rate must be between 0.0 and 1.0")
+	}
 
 	// Validate burst settings
 	if c.Simulate.BurstMultiplier < 1 {
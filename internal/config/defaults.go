@@ -30,6 +30,106 @@ const (
 
 	// ParetoRatio controls activity distribution (0.2 = top 20% generate 80% volume)
 	ParetoRatio = 0.2
+
+	// CashbackRate is the fraction of a credit card's monthly purchases credited back the next month (0 = disabled)
+	CashbackRate = 0.01
+
+	// OverdraftFee is charged (in cents) for each debit that leaves a checking account overdrawn (0 = disabled)
+	OverdraftFee = 3500
+
+	// OverdraftFeeDailyCap is the most overdraft fees charged per account per day (0 = no cap)
+	OverdraftFeeDailyCap = 3
+
+	// InternationalWireRate is the fraction of outgoing wires sent to one of the
+	// customer's beneficiaries abroad, with correspondent routing and FX (0 = all domestic)
+	InternationalWireRate = 0.5
+
+	// FarFromHomeRate is the fraction of card and online transactions made from a
+	// city far from the customer's home, for impossible-travel detection
+	FarFromHomeRate = 0.01
+
+	// WhaleMultiplier scales the monthly volume of --whale-accounts
+	WhaleMultiplier = 50.0
+
+	// TransferP2PRate is the fraction of a retail account's transfers sent to or
+	// received from its --transfer-payees rather than the customer's own accounts
+	TransferP2PRate = 0.3
+
+	// AccountRampUpMonths is how many months a new account takes to reach its
+	// full transaction volume (0 = full activity from the month it opens)
+	AccountRampUpMonths = 3
+
+	// InterestPostingDay is the day of the month interest-bearing accounts are
+	// posted the previous month's interest (0 = drawn at random from the mix)
+	InterestPostingDay = 1
+
+	// CurrencyMinorUnits stores amounts in each currency's own minor units
+	// (whole yen for JPY, thousandths for KWD) instead of hundredths for all
+	CurrencyMinorUnits = true
+)
+
+// Customer lifecycle
+const (
+	// ChurnRate is the fraction of customers suspended or closed at some point in history
+	ChurnRate = 0.05
+
+	// ChurnClosedRatio is the fraction of churned customers who close (the rest are suspended)
+	ChurnClosedRatio = 0.6
+
+	// RelocationRate is the fraction of customers who move to another home branch during history
+	RelocationRate = 0.04
+
+	// GeoClustering is the fraction of customers who live near their home
+	// branch rather than anywhere in its country (0 = no clustering)
+	GeoClustering = 0.0
+
+	// OfflineCustomerRate is the fraction of customers without online banking,
+	// who bank only at ATMs and branches (0 = every customer banks online)
+	OfflineCustomerRate = 0.0
+
+	// MinBeneficiaries and MaxBeneficiaries bound each customer's beneficiaries;
+	// the count is drawn between the minimum and 5 scaled up by activity score
+	MinBeneficiaries = 1
+	MaxBeneficiaries = 10
+)
+
+// Accounts
+const (
+	// ForeignCurrencyRate is the fraction of checking, savings and investment
+	// accounts held in a currency other than the customer's country's
+	ForeignCurrencyRate = 0.02
+)
+
+// Branch and ATM lifecycle
+const (
+	// ClosedBranchRate is the fraction of branches closed at some point in history
+	ClosedBranchRate = 0.03
+
+	// OfflineATMRate and MaintenanceATMRate are the fractions of ATMs out of service at the as-of date
+	OfflineATMRate     = 0.02
+	MaintenanceATMRate = 0.01
+)
+
+// Business hours for business, merchant and payroll accounts (customer local time)
+const (
+	// BusinessOpenHour and BusinessCloseHour bound weekday office hours
+	BusinessOpenHour  = 8
+	BusinessCloseHour = 18
+
+	// BusinessWeekendActivity is weekend activity relative to the time pattern (1 = unsuppressed)
+	BusinessWeekendActivity = 0.02
+
+	// BusinessOvernightActivity is weekday activity outside office hours relative to the time pattern
+	BusinessOvernightActivity = 0.02
+)
+
+// Day-of-week volume: transactions per weekend day relative to a weekday
+const (
+	// RetailWeekendVolume lifts consumer spending on Saturdays and Sundays
+	RetailWeekendVolume = 1.2
+
+	// BusinessWeekendVolume thins out business activity on weekends, on top of business hours
+	BusinessWeekendVolume = 0.5
 )
 
 // Error simulation rates for generated data
@@ -40,8 +140,79 @@ const (
 	// InsufficientFundsRate is the fraction with insufficient funds errors
 	InsufficientFundsRate = 0.02
 
+	// DeclineRetryRate is the fraction of declined purchases retried
+	// successfully at the same merchant seconds later, on another of the
+	// customer's cards or checking accounts (0 = none)
+	DeclineRetryRate = 0.0
+
+	// ReversalRate is the fraction of completed debits later reversed by a linked credit
+	ReversalRate = 0.002
+
 	// FailedLoginRate is the fraction of login attempts that fail
 	FailedLoginRate = 0.02
+
+	// NewDeviceRate is the fraction of digital sessions from a device the customer hasn't used before
+	NewDeviceRate = 0.01
+)
+
+// ATM operational events (generate --atm-events)
+const (
+	// ATMCashCapacity is the number of withdrawals a fully loaded ATM can serve
+	ATMCashCapacity = 2000
+
+	// ATMReplenishDays is the cash replenishment cycle
+	ATMReplenishDays = 7
+
+	// ATMCashLowPercent is the remaining cash level that raises a cash_low event
+	ATMCashLowPercent = 20
+
+	// ATMFaultsPerYear is the average unplanned outages per ATM per year
+	ATMFaultsPerYear = 4
+
+	// ATMMaintenancePerYear is the average scheduled maintenance windows per ATM per year
+	ATMMaintenancePerYear = 2
+)
+
+// Customer KYC verification (generate --kyc)
+const (
+	// KYCUnverifiedRate is the fraction of customers who never submit documents
+	KYCUnverifiedRate = 0.03
+
+	// KYCRejectedRate is the fraction of reviewed submissions that are rejected
+	KYCRejectedRate = 0.02
+
+	// KYCMaxReviewDays is the longest a document review takes
+	KYCMaxReviewDays = 10
+
+	// KYCUnverifiedDailyLimit caps daily withdrawals and transfers (in cents) on
+	// accounts of customers who are not verified
+	KYCUnverifiedDailyLimit = 50000
+)
+
+// Password hashing (generate --password-hash)
+const (
+	// PasswordBcryptCost is the bcrypt work factor for --password-hash bcrypt.
+	// 4 is bcrypt's minimum (~1ms per hash); every +1 doubles generation time
+	// for customers and businesses, so 10 (a typical production cost) takes
+	// about 100x longer.
+	PasswordBcryptCost = 4
+)
+
+// Output compression (generate --compress)
+const (
+	// CompressLevel is the xz preset for --compress. 0 is the fastest and
+	// still shrinks CSV by about 90%; 9 is smallest but several times slower.
+	CompressLevel = 0
+)
+
+// Post-generation checks
+const (
+	// BalanceVerifyMaxReported is how many violations --verify-balances prints
+	BalanceVerifyMaxReported = 20
+
+	// TransactionSortRunRows is how many rows --sort-transactions sorts in
+	// memory before spilling a run to disk (roughly 300 bytes each)
+	TransactionSortRunRows = 500000
 )
 
 // =============================================================================
@@ -133,6 +304,15 @@ const (
 
 	// SimTimeoutRate is the fraction of operations that timeout
 	SimTimeoutRate = 0.001
+
+	// SimDeadlockRate is the fraction of write attempts failed with a deadlock (retried)
+	SimDeadlockRate = 0.001
+
+	// SimDuplicateKeyRate is the fraction of write attempts failed with a duplicate-key collision
+	SimDuplicateKeyRate = 0.0005
+
+	// SimConnectionResetRate is the fraction of write attempts failed with a connection reset (retried)
+	SimConnectionResetRate = 0.0005
 )
 
 // =============================================================================
@@ -154,6 +334,14 @@ const (
 
 	// DBConnMaxIdleTime is how long an idle connection is kept
 	DBConnMaxIdleTime = 1 * time.Minute
+
+	// DBWarmUpDuration is how long the simulator spends opening and pinging
+	// pool connections before sessions start (--warm-up; 0 = no warm-up)
+	DBWarmUpDuration = time.Duration(0)
+
+	// DBHealthCheckInterval is how often the simulator pings idle pool
+	// connections and evicts dead ones (--health-check-interval; 0 = never)
+	DBHealthCheckInterval = time.Duration(0)
 )
 
 // =============================================================================
@@ -164,6 +352,6 @@ const (
 	// MetricsInterval is how often to report real-time metrics
 	MetricsInterval = 5 * time.Second
 
-	// GracefulShutdownTimeout is max wait time for graceful shutdown
+	// GracefulShutdownTimeout is max wait time for graceful shutdown (--drain-timeout)
 	GracefulShutdownTimeout = 30 * time.Second
 )
@@ -83,9 +83,11 @@ type CountryCities struct {
 
 // City represents a single city's data
 type City struct {
-	City         string `json:"city"`
-	State        string `json:"state"`
-	PostalPrefix string `json:"postal_prefix"`
+	City         string  `json:"city"`
+	State        string  `json:"state"`
+	PostalPrefix string  `json:"postal_prefix"`
+	Latitude     float64 `json:"lat"` // City centre, to two decimal places
+	Longitude    float64 `json:"lon"`
 }
 
 var (
@@ -205,6 +207,17 @@ func (r *ReferenceData) GetCities(countryCode string) ([]City, bool) {
 	return cities, ok
 }
 
+// GetCity returns a city by country code and name
+func (r *ReferenceData) GetCity(countryCode, name string) (*City, bool) {
+	cities := r.citiesByCountry[countryCode]
+	for i := range cities {
+		if cities[i].City == name {
+			return &cities[i], true
+		}
+	}
+	return nil, false
+}
+
 // GetPostalFormat returns the postal code format for a country
 func (r *ReferenceData) GetPostalFormat(countryCode string) string {
 	if cc, ok := r.Cities.Countries[countryCode]; ok {
@@ -213,6 +226,27 @@ func (r *ReferenceData) GetPostalFormat(countryCode string) string {
 	return ""
 }
 
+// NameFallbackRegion supplies names for regions without name lists of their own
+const NameFallbackRegion = "north_america"
+
+// FirstNamesForRegion returns first names for a region, falling back to
+// NameFallbackRegion when the region has no list
+func (r *ReferenceData) FirstNamesForRegion(region string, isMale bool) []string {
+	if names := r.GetFirstNames(region, isMale); len(names) > 0 {
+		return names
+	}
+	return r.GetFirstNames(NameFallbackRegion, isMale)
+}
+
+// LastNamesForRegion returns last names for a region, falling back to
+// NameFallbackRegion when the region has no list
+func (r *ReferenceData) LastNamesForRegion(region string) []string {
+	if names := r.GetLastNames(region); len(names) > 0 {
+		return names
+	}
+	return r.GetLastNames(NameFallbackRegion)
+}
+
 // GetFirstNames returns first names for a region and gender
 func (r *ReferenceData) GetFirstNames(region string, isMale bool) []string {
 	if rn, ok := r.FirstNames.Regions[region]; ok {
@@ -80,6 +80,21 @@ func TestLoadReferenceData(t *testing.T) {
 		}
 	})
 
+	// Test regional name lookup with fallback
+	t.Run("NamesForRegion", func(t *testing.T) {
+		eastAsian := data.LastNamesForRegion("east_asia")
+		if len(eastAsian) == 0 || eastAsian[0] != data.GetLastNames("east_asia")[0] {
+			t.Error("Expected east_asia last names for east_asia")
+		}
+		fallback := data.FirstNamesForRegion("atlantis", true)
+		if len(fallback) == 0 || fallback[0] != data.GetFirstNames(NameFallbackRegion, true)[0] {
+			t.Errorf("Expected unknown region to fall back to %s first names", NameFallbackRegion)
+		}
+		if len(data.LastNamesForRegion("atlantis")) == 0 {
+			t.Error("Expected unknown region to fall back to last names")
+		}
+	})
+
 	// Test weighted country selection
 	t.Run("CountryByWeight", func(t *testing.T) {
 		totalWeight := data.TotalWeight()
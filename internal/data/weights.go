@@ -0,0 +1,104 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// CountryWeights overrides the built-in country weights used for weighted
+// country selection (customers, businesses, branches, beneficiaries).
+//
+// Example file:
+//
+//	{
+//	  "default_zero": true,
+//	  "weights": {"US": 90, "CA": 5, "MX": 5}
+//	}
+type CountryWeights struct {
+	// Weights maps ISO country codes to their new relative weight
+	Weights map[string]int `json:"weights"`
+	// DefaultZero sets countries not listed in Weights to zero (excluded)
+	DefaultZero bool `json:"default_zero"`
+}
+
+// LoadCountryWeightsFile reads a country weight override file
+func LoadCountryWeightsFile(path string) (*CountryWeights, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read country weights file: %w", err)
+	}
+	var cw CountryWeights
+	if err := json.Unmarshal(raw, &cw); err != nil {
+		return nil, fmt.Errorf("failed to parse country weights file: %w", err)
+	}
+	weights, err := upperCaseCodes(cw.Weights)
+	if err != nil {
+		return nil, fmt.Errorf("invalid country weights: %w", err)
+	}
+	cw.Weights = weights
+	return &cw, nil
+}
+
+// upperCaseCodes returns the weights keyed by upper-case country code, so
+// "us" and "US" name the same country. Listing a country twice is an error.
+func upperCaseCodes(weights map[string]int) (map[string]int, error) {
+	upper := make(map[string]int, len(weights))
+	for code, weight := range weights {
+		key := strings.ToUpper(strings.TrimSpace(code))
+		if _, ok := upper[key]; ok {
+			return nil, fmt.Errorf("country %s listed more than once", key)
+		}
+		upper[key] = weight
+	}
+	return upper, nil
+}
+
+// WithCountryWeights returns a copy of the reference data with country weights
+// merged from overrides. The shared instance returned by Load is not modified.
+func (r *ReferenceData) WithCountryWeights(overrides *CountryWeights) (*ReferenceData, error) {
+	if overrides == nil {
+		return r, nil
+	}
+
+	weights, err := upperCaseCodes(overrides.Weights)
+	if err != nil {
+		return nil, fmt.Errorf("invalid country weights: %w", err)
+	}
+
+	// Validate before copying so errors name every bad entry at once
+	var errs []string
+	for code, weight := range weights {
+		if _, ok := r.countryByCode[code]; !ok {
+			errs = append(errs, fmt.Sprintf("unknown country code %q", code))
+		}
+		if weight < 0 {
+			errs = append(errs, fmt.Sprintf("negative weight %d for %s", weight, code))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("invalid country weights: %s", strings.Join(errs, "; "))
+	}
+
+	clone := *r
+	clone.Countries.Countries = make([]Country, len(r.Countries.Countries))
+	copy(clone.Countries.Countries, r.Countries.Countries)
+
+	for i := range clone.Countries.Countries {
+		c := &clone.Countries.Countries[i]
+		if weight, ok := weights[c.Code]; ok {
+			c.Weight = weight
+		} else if overrides.DefaultZero {
+			c.Weight = 0
+		}
+	}
+
+	clone.buildLookups()
+	if clone.totalWeight <= 0 {
+		return nil, fmt.Errorf("invalid country weights: all countries have zero weight")
+	}
+	return &clone, nil
+}
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithCountryWeights(t *testing.T) {
+	base, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load reference data: %v", err)
+	}
+	baseUS, _ := base.GetCountry("US")
+	baseGB, _ := base.GetCountry("GB")
+	baseTotal := base.TotalWeight()
+
+	t.Run("MergeKeepsUnlistedDefaults", func(t *testing.T) {
+		rd, err := base.WithCountryWeights(&CountryWeights{Weights: map[string]int{"US": 500}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		us, _ := rd.GetCountry("US")
+		gb, _ := rd.GetCountry("GB")
+		if us.Weight != 500 {
+			t.Errorf("Expected US weight 500, got %d", us.Weight)
+		}
+		if gb.Weight != baseGB.Weight {
+			t.Errorf("Expected GB to keep default weight %d, got %d", baseGB.Weight, gb.Weight)
+		}
+		if rd.TotalWeight() != baseTotal-baseUS.Weight+500 {
+			t.Errorf("Unexpected total weight %d", rd.TotalWeight())
+		}
+	})
+
+	t.Run("DefaultZero", func(t *testing.T) {
+		rd, err := base.WithCountryWeights(&CountryWeights{
+			Weights:     map[string]int{"US": 90, "ca": 10},
+			DefaultZero: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rd.TotalWeight() != 100 {
+			t.Errorf("Expected total weight 100, got %d", rd.TotalWeight())
+		}
+		for w := 1; w <= rd.TotalWeight(); w++ {
+			c := rd.CountryByWeight(w)
+			if c.Code != "US" && c.Code != "CA" {
+				t.Fatalf("Weight %d selected excluded country %s", w, c.Code)
+			}
+		}
+		if rd.CountryByWeight(90).Code != "US" || rd.CountryByWeight(91).Code != "CA" {
+			t.Error("Expected weights 1-90 to select US and 91-100 to select CA")
+		}
+	})
+
+	t.Run("MixedCaseCodes", func(t *testing.T) {
+		rd, err := base.WithCountryWeights(&CountryWeights{Weights: map[string]int{"us": 3, "Gb": 7}, DefaultZero: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		us, _ := rd.GetCountry("US")
+		gb, _ := rd.GetCountry("GB")
+		if us.Weight != 3 || gb.Weight != 7 || rd.TotalWeight() != 10 {
+			t.Errorf("Expected US 3 and GB 7 of 10, got US %d and GB %d of %d", us.Weight, gb.Weight, rd.TotalWeight())
+		}
+	})
+
+	t.Run("SharedInstanceUnchanged", func(t *testing.T) {
+		if _, err := base.WithCountryWeights(&CountryWeights{Weights: map[string]int{"US": 1}, DefaultZero: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		us, _ := base.GetCountry("US")
+		if us.Weight != baseUS.Weight || base.TotalWeight() != baseTotal {
+			t.Error("Expected the shared reference data to be unchanged")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cases := map[string]*CountryWeights{
+			"unknown code": {Weights: map[string]int{"XX": 10}},
+			"negative":     {Weights: map[string]int{"US": -1}},
+			"all zero":     {Weights: map[string]int{"US": 0}, DefaultZero: true},
+			"duplicate":    {Weights: map[string]int{"US": 1, "us": 2}},
+		}
+		for name, cw := range cases {
+			if _, err := base.WithCountryWeights(cw); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestLoadCountryWeightsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.json")
+	content := `{"default_zero": true, "weights": {"us": 90, "Gb": 10}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cw, err := LoadCountryWeightsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Codes are stored upper-case, as recorded in the manifest
+	if !cw.DefaultZero || cw.Weights["US"] != 90 || cw.Weights["GB"] != 10 || len(cw.Weights) != 2 {
+		t.Errorf("Unexpected parsed weights: %+v", cw)
+	}
+
+	if _, err := LoadCountryWeightsFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"strings"
 	"time"
@@ -32,6 +33,10 @@ type Pool struct {
 	db     *sql.DB
 	config config.DatabaseConfig
 
+	// maxIdle is the pool's idle connection limit: MaxIdleConns, database/sql's
+	// default if that is unset, or the target of a larger WarmUp
+	maxIdle int
+
 	// Metrics
 	totalQueries   int64
 	failedQueries  int64
@@ -64,8 +69,10 @@ func NewPool(cfg config.DatabaseConfig) (*Pool, error) {
 	if cfg.MaxOpenConns > 0 {
 		db.SetMaxOpenConns(cfg.MaxOpenConns)
 	}
+	maxIdle := defaultMaxIdleConns
 	if cfg.MaxIdleConns > 0 {
 		db.SetMaxIdleConns(cfg.MaxIdleConns)
+		maxIdle = cfg.MaxIdleConns
 	}
 	if cfg.ConnMaxLifetime > 0 {
 		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
@@ -75,8 +82,9 @@ func NewPool(cfg config.DatabaseConfig) (*Pool, error) {
 	}
 
 	pool := &Pool{
-		db:     db,
-		config: cfg,
+		db:      db,
+		config:  cfg,
+		maxIdle: maxIdle,
 	}
 
 	return pool, nil
@@ -178,3 +186,135 @@ type PoolStats struct {
 	FailedQueries int64
 	AvgLatency    time.Duration
 }
+
+const (
+	// warmUpPingInterval is how often WarmUp pings its connections once they are open
+	warmUpPingInterval = 100 * time.Millisecond
+
+	// healthCheckConnTimeout bounds HealthCheck's wait for each connection and its ping
+	healthCheckConnTimeout = 2 * time.Second
+
+	// defaultMaxIdleConns is database/sql's idle connection limit when none is set
+	defaultMaxIdleConns = 2
+)
+
+// WarmUpReport describes a connection pool warm-up
+type WarmUpReport struct {
+	Target   int           // Connections asked for
+	Opened   int           // Connections opened and pinged
+	Pings    int64         // Pings issued across them
+	Duration time.Duration // Time taken
+}
+
+// WarmUp spends duration opening target connections, pinging each, then
+// pinging them until the time is up, so the first sessions don't pay for
+// connection setup and their latency doesn't spike. MaxIdleConns is raised to
+// target if lower, or the pool would close the warmed connections as soon as
+// they were released. Connections not open when duration runs out (or ctx is
+// done) are left to open on demand.
+func (p *Pool) WarmUp(ctx context.Context, target int, duration time.Duration) (WarmUpReport, error) {
+	start := time.Now()
+	report := WarmUpReport{Target: target}
+	if target <= 0 {
+		return report, nil
+	}
+	if p.maxIdle < target {
+		p.db.SetMaxIdleConns(target)
+		p.maxIdle = target
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	// Hold every connection until all are open, so each one is new
+	conns := make([]*sql.Conn, 0, target)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	var openErr error
+	for len(conns) < target {
+		conn, err := p.db.Conn(ctx)
+		if err == nil {
+			err = conn.PingContext(ctx)
+			if err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			openErr = err
+			break
+		}
+		conns = append(conns, conn)
+		report.Pings++
+	}
+	report.Opened = len(conns)
+
+	// Keep the connections busy with trivial pings for the rest of the warm-up
+	ticker := time.NewTicker(warmUpPingInterval)
+	defer ticker.Stop()
+	for len(conns) > 0 && ctx.Err() == nil {
+		select {
+		case <-ticker.C:
+			for _, conn := range conns {
+				if conn.PingContext(ctx) == nil {
+					report.Pings++
+				}
+			}
+		case <-ctx.Done():
+		}
+	}
+
+	report.Duration = time.Since(start)
+	if report.Opened == 0 && openErr != nil {
+		return report, fmt.Errorf("failed to warm up connection pool: %w", openErr)
+	}
+	return report, nil
+}
+
+// HealthCheck pings the pool's idle connections and evicts those that fail,
+// so sessions don't find dead connections first. It returns how many it
+// checked and evicted.
+//
+// The idle count is a snapshot: sessions may take connections meanwhile, and
+// the wait for one that is no longer idle is cut short by
+// healthCheckConnTimeout. At most maxIdle connections are checked.
+func (p *Pool) HealthCheck(ctx context.Context) (checked, evicted int, err error) {
+	idle := min(p.db.Stats().Idle, p.maxIdle)
+	conns := make([]*sql.Conn, 0, idle)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	// Take the idle connections out of the pool together so each is checked once
+	for len(conns) < idle {
+		conn, pingErr, connErr := p.checkConn(ctx)
+		if connErr != nil {
+			return checked, evicted, connErr
+		}
+		conns = append(conns, conn)
+		checked++
+		if pingErr != nil {
+			// Reporting the connection as bad makes database/sql discard it
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+			evicted++
+		}
+	}
+	return checked, evicted, nil
+}
+
+// checkConn takes a connection from the pool and pings it, each within
+// healthCheckConnTimeout. connErr is set if no connection could be had.
+func (p *Pool) checkConn(ctx context.Context) (conn *sql.Conn, pingErr, connErr error) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckConnTimeout)
+	defer cancel()
+
+	conn, connErr = p.db.Conn(ctx)
+	if connErr != nil {
+		return nil, nil, fmt.Errorf("failed to get an idle connection: %w", connErr)
+	}
+	return conn, conn.PingContext(ctx), nil
+}
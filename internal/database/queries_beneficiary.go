@@ -0,0 +1,125 @@
+// Package database provides database operations for the load generator simulation.
+//
+// FILE: queries_beneficiary.go
+// PURPOSE: Beneficiary-related database queries, including bill payments to
+// a customer's saved payees.
+//
+// KEY FUNCTIONS:
+// - PayBeneficiary: Pays one of a customer's beneficiaries from an account
+//
+// RELATED FILES:
+// - queries.go: Base Queries struct
+// - queries_account.go: Account queries and internal transfers
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// ErrNoBeneficiary is returned when a customer has no verified beneficiary to pay
+var ErrNoBeneficiary = errors.New("no verified beneficiary")
+
+// BillPaymentResult contains the results of a bill payment
+type BillPaymentResult struct {
+	TransactionID   int64
+	BeneficiaryID   int64
+	BeneficiaryName string
+	NewBalance      int64
+}
+
+// PayBeneficiary pays a random verified beneficiary of the customer from the
+// given account: it debits the account, records a bill_payment transaction
+// and bumps the beneficiary's transfer_count and last_used_at
+func (q *Queries) PayBeneficiary(ctx context.Context, customerID, accountID, amount int64, channel models.TransactionChannel) (*BillPaymentResult, error) {
+	tx, err := q.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	// Pick and lock a beneficiary
+	var beneficiaryID int64
+	var name string
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, name FROM beneficiaries
+		WHERE customer_id = ? AND status = 'verified'
+		ORDER BY RAND()
+		LIMIT 1
+		FOR UPDATE`,
+		customerID,
+	).Scan(&beneficiaryID, &name)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoBeneficiary
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock beneficiary: %w", err)
+	}
+
+	// Lock and check the paying account
+	var balance int64
+	var currency string
+	err = tx.QueryRowContext(ctx,
+		`SELECT balance, currency FROM accounts WHERE id = ? FOR UPDATE`,
+		accountID,
+	).Scan(&balance, &currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	if balance < amount {
+		return nil, fmt.Errorf("insufficient funds: balance %d, requested %d", balance, amount)
+	}
+
+	newBalance := balance - amount
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE accounts SET balance = ?, updated_at = ? WHERE id = ?`,
+		newBalance, now, accountID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account: %w", err)
+	}
+
+	// Insert bill payment transaction
+	ref := generateReferenceNumber(now, accountID)
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO transactions (
+			reference_number, account_id, beneficiary_id, type, status, channel,
+			amount, currency, balance_after, description, timestamp, posted_at, value_date
+		) VALUES (?, ?, ?, 'bill_payment', 'completed', ?, ?, ?, ?, ?, ?, ?, ?)`,
+		ref, accountID, beneficiaryID, channel,
+		amount, currency, newBalance, "Bill payment - "+name, now, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	transactionID, _ := result.LastInsertId()
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE beneficiaries SET transfer_count = transfer_count + 1, last_used_at = ?, updated_at = ? WHERE id = ?`,
+		now, now, beneficiaryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update beneficiary: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bill payment: %w", err)
+	}
+
+	return &BillPaymentResult{
+		TransactionID:   transactionID,
+		BeneficiaryID:   beneficiaryID,
+		BeneficiaryName: name,
+		NewBalance:      newBalance,
+	}, nil
+}
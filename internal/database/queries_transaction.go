@@ -5,7 +5,7 @@
 // and batch payroll operations.
 //
 // KEY FUNCTIONS:
-// - GetTransactionHistory: Retrieves recent transactions for an account
+// - GetTransactionHistory: Retrieves one page of an account's history
 // - ExecuteBatchPayroll: Performs batch salary payments
 //
 // RELATED FILES:
@@ -22,8 +22,9 @@ import (
 	"github.com/willfong/load-generator/internal/models"
 )
 
-// GetTransactionHistory retrieves recent transactions for an account
-func (q *Queries) GetTransactionHistory(ctx context.Context, accountID int64, limit int) ([]*models.Transaction, error) {
+// GetTransactionHistory retrieves one page of an account's transactions,
+// newest first. Deeper pages scan further back through the account's history.
+func (q *Queries) GetTransactionHistory(ctx context.Context, accountID int64, limit, offset int) ([]*models.Transaction, error) {
 	query := `
 		SELECT id, reference_number, account_id, counterparty_account_id, beneficiary_id,
 			type, status, channel, amount, currency, balance_after,
@@ -31,10 +32,10 @@ func (q *Queries) GetTransactionHistory(ctx context.Context, accountID int64, li
 			timestamp, posted_at, value_date, failure_reason
 		FROM transactions
 		WHERE account_id = ?
-		ORDER BY timestamp DESC
-		LIMIT ?`
+		ORDER BY timestamp DESC, id DESC
+		LIMIT ? OFFSET ?`
 
-	rows, err := q.pool.QueryContext(ctx, query, accountID, limit)
+	rows, err := q.pool.QueryContext(ctx, query, accountID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query transactions: %w", err)
 	}
@@ -42,13 +42,15 @@ func scanCustomer(row *sql.Row) (*models.Customer, error) {
 		city         sql.NullString
 		state        sql.NullString
 		postalCode   sql.NullString
+		username     sql.NullString
+		passwordHash sql.NullString
 	)
 
 	err := row.Scan(
 		&c.ID, &c.FirstName, &c.LastName, &c.Email, &phone, &dateOfBirth,
 		&addressLine1, &addressLine2, &city, &state, &postalCode, &c.Country,
 		&c.Timezone, &c.HomeBranch, &c.Segment, &c.Status, &c.ActivityScore,
-		&c.Username, &c.PasswordHash, &c.PIN, &c.CreatedAt, &c.UpdatedAt,
+		&username, &passwordHash, &c.PIN, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -62,6 +64,8 @@ func scanCustomer(row *sql.Row) (*models.Customer, error) {
 	c.City = city.String
 	c.State = state.String
 	c.PostalCode = postalCode.String
+	c.Username = username.String
+	c.PasswordHash = passwordHash.String
 
 	return c, nil
 }
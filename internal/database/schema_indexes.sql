@@ -19,12 +19,16 @@ CREATE INDEX idx_atms_status ON atms(status);
 CREATE INDEX idx_atms_country ON atms(country);
 CREATE INDEX idx_atms_branch ON atms(branch_id);
 
+-- ATM events
+CREATE INDEX idx_atm_events_atm_time ON atm_events(atm_id, timestamp);
+
 -- Customers
 CREATE INDEX idx_customers_country ON customers(country);
 CREATE INDEX idx_customers_segment ON customers(segment);
 CREATE INDEX idx_customers_status ON customers(status);
 CREATE INDEX idx_customers_email ON customers(email);
 CREATE INDEX idx_customers_branch ON customers(home_branch_id);
+CREATE INDEX idx_customers_kyc_status ON customers(kyc_status);
 
 -- Accounts
 CREATE INDEX idx_accounts_customer ON accounts(customer_id);
@@ -51,6 +55,17 @@ CREATE INDEX idx_transactions_beneficiary ON transactions(beneficiary_id);
 CREATE INDEX idx_transactions_branch ON transactions(branch_id);
 CREATE INDEX idx_transactions_atm ON transactions(atm_id);
 
+-- Transfers
+CREATE INDEX idx_transfers_from_account ON transfers(from_account_id);
+CREATE INDEX idx_transfers_to_account ON transfers(to_account_id);
+CREATE INDEX idx_transfers_timestamp ON transfers(timestamp);
+
+-- Disputes
+CREATE INDEX idx_disputes_transaction ON disputes(transaction_id);
+CREATE INDEX idx_disputes_account ON disputes(account_id);
+CREATE INDEX idx_disputes_status ON disputes(status);
+CREATE INDEX idx_disputes_filed ON disputes(filed_at);
+
 -- Audit logs (for compliance and debugging)
 CREATE INDEX idx_audit_timestamp ON audit_logs(timestamp);
 CREATE INDEX idx_audit_customer ON audit_logs(customer_id);
@@ -72,4 +87,6 @@ ANALYZE TABLE customers;
 ANALYZE TABLE accounts;
 ANALYZE TABLE beneficiaries;
 ANALYZE TABLE transactions;
+ANALYZE TABLE transfers;
+ANALYZE TABLE disputes;
 ANALYZE TABLE audit_logs;
@@ -14,10 +14,13 @@ USE bank;
 
 -- Drop existing tables (in reverse dependency order)
 DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS disputes;
+DROP TABLE IF EXISTS transfers;
 DROP TABLE IF EXISTS transactions;
 DROP TABLE IF EXISTS beneficiaries;
 DROP TABLE IF EXISTS accounts;
 DROP TABLE IF EXISTS customers;
+DROP TABLE IF EXISTS atm_events;
 DROP TABLE IF EXISTS atms;
 DROP TABLE IF EXISTS branches;
 
@@ -76,6 +79,16 @@ CREATE TABLE atms (
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 ) ENGINE=InnoDB;
 
+-- ATM events
+CREATE TABLE atm_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    atm_id BIGINT NOT NULL,
+    type ENUM('cash_low', 'offline', 'online') NOT NULL,
+    status ENUM('online', 'offline', 'maintenance', 'out_of_cash') NOT NULL,
+    cash_level INT NOT NULL,
+    timestamp TIMESTAMP NOT NULL
+) ENGINE=InnoDB;
+
 -- Customers
 CREATE TABLE customers (
     id BIGINT AUTO_INCREMENT PRIMARY KEY,
@@ -98,6 +111,9 @@ CREATE TABLE customers (
     username VARCHAR(100) NOT NULL UNIQUE,
     password_hash VARCHAR(255) NOT NULL,
     pin VARCHAR(255) NOT NULL,
+    kyc_status ENUM('unverified', 'pending', 'verified', 'rejected'),
+    kyc_document_type ENUM('passport', 'national_id', 'drivers_license', 'residence_permit'),
+    kyc_verified_at TIMESTAMP NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 ) ENGINE=InnoDB;
@@ -174,7 +190,41 @@ CREATE TABLE transactions (
     timestamp TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     posted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     value_date DATE NOT NULL,
-    failure_reason VARCHAR(255)
+    failure_reason VARCHAR(255),
+
+    -- Where the transaction was made (NULL without --geo-coordinates and for channels without a location)
+    latitude DECIMAL(10, 8),
+    longitude DECIMAL(11, 8)
+) ENGINE=InnoDB;
+
+-- Transfers (no indexes for fast bulk insert)
+CREATE TABLE transfers (
+    id BIGINT PRIMARY KEY,
+    reference_number VARCHAR(30) NOT NULL,
+    from_account_id BIGINT NOT NULL,
+    to_account_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    status ENUM('pending', 'completed', 'failed', 'reversed', 'declined') NOT NULL DEFAULT 'completed',
+    debit_transaction_id BIGINT NOT NULL,
+    credit_transaction_id BIGINT,
+    timestamp TIMESTAMP NOT NULL
+) ENGINE=InnoDB;
+
+-- Disputes (no indexes for fast bulk insert)
+CREATE TABLE disputes (
+    id BIGINT PRIMARY KEY,
+    case_number VARCHAR(40) NOT NULL,
+    transaction_id BIGINT NOT NULL,
+    account_id BIGINT NOT NULL,
+    customer_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    reason ENUM('fraud', 'not_received', 'not_as_described', 'duplicate', 'incorrect_amount') NOT NULL,
+    status ENUM('filed', 'under_review', 'won', 'lost') NOT NULL DEFAULT 'filed',
+    filed_at TIMESTAMP NOT NULL,
+    review_started_at TIMESTAMP NULL,
+    resolved_at TIMESTAMP NULL
 ) ENGINE=InnoDB;
 
 -- Audit logs (no indexes for fast bulk insert)
@@ -190,6 +240,7 @@ CREATE TABLE audit_logs (
         'transaction_initiated', 'transaction_completed', 'transaction_failed', 'transaction_declined',
         'account_opened', 'account_closed', 'account_updated',
         'beneficiary_added', 'beneficiary_removed',
+        'customer_suspended', 'customer_closed', 'kyc_submitted', 'kyc_verified', 'kyc_rejected',
         'profile_viewed', 'profile_updated', 'address_changed', 'contact_changed',
         'session_started', 'session_ended', 'session_timeout',
         'balance_inquiry', 'statement_viewed', 'history_viewed'
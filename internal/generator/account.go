@@ -20,10 +20,33 @@ type AccountGenerator struct {
 type AccountGeneratorConfig struct {
 	// Branches for assigning account branch
 	Branches []GeneratedBranch
+	// BaseDate stands in for the current time (zero = now)
+	BaseDate time.Time
+	// AccountMix gives the optional account-type probabilities per segment (nil = DefaultAccountMix)
+	AccountMix map[models.CustomerSegment]AccountMix
+	// ForeignCurrencyRate is the probability a deposit account is held in a
+	// currency other than the customer's country's (0 = never)
+	ForeignCurrencyRate float64
+	// ForeignCurrencies are the currencies foreign accounts are opened in (nil = all supported)
+	ForeignCurrencies []models.Currency
+	// KYC limits the accounts of customers who are not KYC verified (nil = no limits)
+	KYC *KYCConfig
+	// MinorUnits stores balances and limits in each currency's own minor
+	// units (false = hundredths for every currency)
+	MinorUnits bool
 }
 
 // NewAccountGenerator creates a new account generator
 func NewAccountGenerator(rng *utils.Random, refData *data.ReferenceData, config AccountGeneratorConfig) *AccountGenerator {
+	if config.BaseDate.IsZero() {
+		config.BaseDate = time.Now()
+	}
+	if config.AccountMix == nil {
+		config.AccountMix = DefaultAccountMix()
+	}
+	if config.ForeignCurrencies == nil {
+		config.ForeignCurrencies = models.SupportedCurrencies
+	}
 	return &AccountGenerator{
 		rng:     rng,
 		refData: refData,
@@ -69,45 +92,20 @@ func (g *AccountGenerator) GenerateAccountsForBusinesses(businesses []GeneratedB
 func (g *AccountGenerator) generateAccountsForCustomer(customer GeneratedCustomer, currentID *int64) []GeneratedAccount {
 	accounts := make([]GeneratedAccount, 0, 3)
 
-	// Everyone gets a checking account
-	checking := g.generateAccount(*currentID, customer, models.AccountTypeChecking)
-	accounts = append(accounts, checking)
-	*currentID++
-
-	// 70% get a savings account
-	if g.rng.Probability(0.7) {
-		savings := g.generateAccount(*currentID, customer, models.AccountTypeSavings)
-		accounts = append(accounts, savings)
+	// Customers who are not KYC verified only get a checking account
+	if !customer.Customer.KYCVerified() {
+		account := g.generateAccount(*currentID, customer, models.AccountTypeChecking)
+		g.limitUnverified(&account.Account)
 		*currentID++
+		return append(accounts, account)
 	}
 
-	// Based on segment, add more account types
-	switch customer.Customer.Segment {
-	case models.SegmentPremium, models.SegmentPrivate:
-		// High net worth: investment account (50%), credit card (80%)
-		if g.rng.Probability(0.5) {
-			investment := g.generateAccount(*currentID, customer, models.AccountTypeInvestment)
-			accounts = append(accounts, investment)
-			*currentID++
-		}
-		if g.rng.Probability(0.8) {
-			creditCard := g.generateAccount(*currentID, customer, models.AccountTypeCreditCard)
-			accounts = append(accounts, creditCard)
-			*currentID++
-		}
-
-	case models.SegmentRegular:
-		// Regular: credit card (40%), occasional loan (10%)
-		if g.rng.Probability(0.4) {
-			creditCard := g.generateAccount(*currentID, customer, models.AccountTypeCreditCard)
-			accounts = append(accounts, creditCard)
-			*currentID++
-		}
-		if g.rng.Probability(0.1) {
-			loan := g.generateAccount(*currentID, customer, models.AccountTypeLoan)
-			accounts = append(accounts, loan)
-			*currentID++
-		}
+	// Everyone gets a checking account, plus optional accounts by segment
+	// (e.g. investment accounts for high net worth) within the count bounds
+	mix := g.config.AccountMix[customer.Customer.Segment]
+	for _, accountType := range mix.accountTypes(g.rng) {
+		accounts = append(accounts, g.generateAccount(*currentID, customer, accountType))
+		*currentID++
 	}
 
 	return accounts
@@ -155,8 +153,8 @@ func (g *AccountGenerator) generateAccountsForBusiness(business GeneratedBusines
 
 // generateAccount creates a single account
 func (g *AccountGenerator) generateAccount(id int64, customer GeneratedCustomer, accountType models.AccountType) GeneratedAccount {
-	// Get currency from customer's country
-	currency := g.getCurrency(customer.Country.Currency)
+	// Get currency from customer's country, occasionally a foreign one
+	currency := g.accountCurrency(customer.Country.Currency, accountType)
 
 	// Generate account number
 	accountNumber := g.generateAccountNumber(customer.Country.Code, id)
@@ -170,6 +168,13 @@ func (g *AccountGenerator) generateAccount(id int64, customer GeneratedCustomer,
 	// Calculate daily limits
 	dailyWithdraw, dailyTransfer := g.calculateDailyLimits(accountType, customer.Customer.Segment, currency)
 
+	// The ranges above are in hundredths; store them in the currency's minor units
+	if g.config.MinorUnits {
+		balance = toMinorUnits(balance, currency)
+		creditLimit, overdraftLimit = toMinorUnits(creditLimit, currency), toMinorUnits(overdraftLimit, currency)
+		dailyWithdraw, dailyTransfer = toMinorUnits(dailyWithdraw, currency), toMinorUnits(dailyTransfer, currency)
+	}
+
 	// Calculate interest rate
 	interestRate := g.calculateInterestRate(accountType)
 
@@ -194,7 +199,22 @@ func (g *AccountGenerator) generateAccount(id int64, customer GeneratedCustomer,
 		InterestRate:       interestRate,
 		BranchID:           branchID,
 		OpenedAt:           openedAt,
-		UpdatedAt:          time.Now(),
+		UpdatedAt:          g.config.BaseDate,
+	}
+
+	// Accounts of churned customers are frozen (suspended) or closed (closed)
+	if changedAt := customer.StatusChangedAt; changedAt != nil {
+		if account.OpenedAt.After(*changedAt) {
+			account.OpenedAt = customer.Customer.CreatedAt
+		}
+		switch customer.Customer.Status {
+		case models.CustomerStatusClosed:
+			account.Status = models.AccountStatusClosed
+			account.ClosedAt = changedAt
+		case models.CustomerStatusSuspended:
+			account.Status = models.AccountStatusFrozen
+		}
+		account.UpdatedAt = *changedAt
 	}
 
 	return GeneratedAccount{
@@ -204,6 +224,22 @@ func (g *AccountGenerator) generateAccount(id int64, customer GeneratedCustomer,
 	}
 }
 
+// limitUnverified flags the account of a customer who is not KYC verified as
+// pending and caps its daily limits
+func (g *AccountGenerator) limitUnverified(a *models.Account) {
+	if a.Status == models.AccountStatusActive {
+		a.Status = models.AccountStatusPending
+	}
+	if g.config.KYC != nil && g.config.KYC.UnverifiedDailyLimit > 0 {
+		limit := g.config.KYC.UnverifiedDailyLimit
+		if g.config.MinorUnits {
+			limit = toMinorUnits(limit, a.Currency)
+		}
+		a.DailyWithdrawLimit = min(a.DailyWithdrawLimit, limit)
+		a.DailyTransferLimit = min(a.DailyTransferLimit, limit)
+	}
+}
+
 // getCurrency converts currency code string to Currency type
 func (g *AccountGenerator) getCurrency(code string) models.Currency {
 	switch code {
@@ -434,30 +470,32 @@ func (g *AccountGenerator) generateOpenedAt(customerCreatedAt time.Time) time.Ti
 	return customerCreatedAt.Add(time.Duration(daysAfter) * 24 * time.Hour)
 }
 
-// WriteAccountsCSV writes accounts to a CSV file (or .csv.xz if compress=true)
-func WriteAccountsCSV(accounts []GeneratedAccount, outputDir string, compress bool) error {
-	return writeAccountsCSVInternal(accounts, outputDir, compress, false)
-}
-
-// WriteAccountsCSVWithProgress writes accounts with progress reporting
-func WriteAccountsCSVWithProgress(accounts []GeneratedAccount, outputDir string, compress bool) error {
-	return writeAccountsCSVInternal(accounts, outputDir, compress, true)
-}
-
-func writeAccountsCSVInternal(accounts []GeneratedAccount, outputDir string, compress, showProgress bool) error {
-	headers := []string{
+// AccountHeaders returns the CSV headers for accounts
+func AccountHeaders() []string {
+	return []string{
 		"id", "account_number", "customer_id", "type", "status", "currency",
 		"balance", "credit_limit", "overdraft_limit",
 		"daily_withdraw_limit", "daily_transfer_limit", "interest_rate",
 		"branch_id", "opened_at", "closed_at", "updated_at",
 	}
+}
+
+// WriteAccountsCSV writes accounts to a CSV file in cfg.OutputDir (or .csv.xz at preset cfg.XZPreset if cfg.Compress),
+// split into that many shard files if shards > 1
+func WriteAccountsCSV(accounts []GeneratedAccount, cfg CSVWriterConfig, shards int) error {
+	return writeAccountsCSVInternal(accounts, cfg, shards, false)
+}
+
+// WriteAccountsCSVWithProgress writes accounts with progress reporting
+func WriteAccountsCSVWithProgress(accounts []GeneratedAccount, cfg CSVWriterConfig, shards int) error {
+	return writeAccountsCSVInternal(accounts, cfg, shards, true)
+}
+
+func writeAccountsCSVInternal(accounts []GeneratedAccount, cfg CSVWriterConfig, shards int, showProgress bool) error {
+	headers := AccountHeaders()
 
-	writer, err := NewCSVWriter(CSVWriterConfig{
-		OutputDir: outputDir,
-		Filename:  "accounts",
-		Headers:   headers,
-		Compress:  compress,
-	})
+	cfg.Filename, cfg.Headers = "accounts", headers
+	writer, err := newTableWriter(cfg, shards, len(accounts))
 	if err != nil {
 		return err
 	}
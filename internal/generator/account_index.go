@@ -0,0 +1,71 @@
+package generator
+
+import "github.com/willfong/load-generator/internal/models"
+
+// AccountIndex holds the account lookups transaction and audit generation
+// need for counterparties and balance inquiries. It is built once and shared
+// by every worker, which only read it.
+type AccountIndex struct {
+	byID map[int64]GeneratedAccount
+	// Account IDs of each customer, in account order
+	byCustomer map[int64][]int64
+
+	// Merchant account IDs for purchase destinations
+	merchantIDs []int64
+	// Employer account IDs for salary sources
+	employerIDs []int64
+	// Utility account IDs for bill payments (each utility's first account)
+	utilityIDs []int64
+	// Retail checking account IDs by currency, for payroll employees
+	employeeIDs map[models.Currency][]int64
+}
+
+// NewAccountIndex indexes accounts, taking utility companies from businesses
+func NewAccountIndex(accounts []GeneratedAccount, businesses []GeneratedBusiness) *AccountIndex {
+	idx := &AccountIndex{
+		byID:        make(map[int64]GeneratedAccount, len(accounts)),
+		byCustomer:  make(map[int64][]int64),
+		employeeIDs: make(map[models.Currency][]int64),
+	}
+
+	for _, acc := range accounts {
+		idx.byID[acc.Account.ID] = acc
+		idx.byCustomer[acc.Account.CustomerID] = append(idx.byCustomer[acc.Account.CustomerID], acc.Account.ID)
+		switch acc.Account.Type {
+		case models.AccountTypeMerchant:
+			idx.merchantIDs = append(idx.merchantIDs, acc.Account.ID)
+		case models.AccountTypePayroll:
+			idx.employerIDs = append(idx.employerIDs, acc.Account.ID)
+		case models.AccountTypeChecking:
+			if !acc.Customer.Customer.IsBusinessCustomer() {
+				currency := acc.Account.Currency
+				idx.employeeIDs[currency] = append(idx.employeeIDs[currency], acc.Account.ID)
+			}
+		}
+	}
+
+	for _, biz := range businesses {
+		if biz.BusinessType != BusinessTypeUtility {
+			continue
+		}
+		if ids := idx.byCustomer[biz.Customer.ID]; len(ids) > 0 {
+			idx.utilityIDs = append(idx.utilityIDs, ids[0])
+		}
+	}
+
+	return idx
+}
+
+// accountIndex returns the account index for the generated accounts, building
+// it on first use. Call it before starting workers, which share the result.
+func (o *Orchestrator) accountIndex() *AccountIndex {
+	if o.index == nil {
+		o.index = NewAccountIndex(o.accounts, o.businesses)
+	}
+	return o.index
+}
+
+// customerAccountIDs returns the IDs of a customer's accounts
+func (idx *AccountIndex) customerAccountIDs(customerID int64) []int64 {
+	return idx.byCustomer[customerID]
+}
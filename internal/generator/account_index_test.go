@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestNewAccountIndex(t *testing.T) {
+	retail := GeneratedCustomer{Customer: models.Customer{ID: 1, Segment: models.SegmentRegular}}
+	utility := GeneratedCustomer{Customer: models.Customer{ID: 2, Segment: models.SegmentBusiness}}
+	account := func(id, customerID int64, accountType models.AccountType, customer GeneratedCustomer) GeneratedAccount {
+		return GeneratedAccount{Account: models.Account{ID: id, CustomerID: customerID, Type: accountType, Currency: "USD"}, Customer: customer}
+	}
+	idx := NewAccountIndex([]GeneratedAccount{
+		account(10, 1, models.AccountTypeChecking, retail),
+		account(11, 1, models.AccountTypeSavings, retail),
+		account(20, 2, models.AccountTypeBusiness, utility),
+		account(21, 2, models.AccountTypeMerchant, utility),
+	}, []GeneratedBusiness{{Customer: utility.Customer, BusinessType: BusinessTypeUtility}})
+
+	if got := idx.customerAccountIDs(1); !slices.Equal(got, []int64{10, 11}) {
+		t.Errorf("customer 1 accounts %v, expected [10 11]", got)
+	}
+	if idx.byID[21].Account.Type != models.AccountTypeMerchant {
+		t.Error("account 21 not indexed by ID")
+	}
+	if !slices.Equal(idx.merchantIDs, []int64{21}) || !slices.Equal(idx.utilityIDs, []int64{20}) {
+		t.Errorf("merchants %v and utilities %v, expected [21] and [20]", idx.merchantIDs, idx.utilityIDs)
+	}
+	if !slices.Equal(idx.employeeIDs["USD"], []int64{10}) {
+		t.Errorf("employees %v, expected [10]", idx.employeeIDs["USD"])
+	}
+}
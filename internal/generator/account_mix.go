@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// AccountMix holds the probability of each optional account type a customer
+// in a segment opens. Every customer also gets a checking account.
+//
+// MinAccounts and MaxAccounts bound the number of accounts per customer,
+// checking included (0 = no bound). Customers over the maximum drop their
+// least likely optional accounts; customers under the minimum open more of
+// the types their segment is eligible for (probability above 0), repeating
+// types once each has been opened.
+//
+// Example file (segments and fields not listed keep their defaults):
+//
+//	{
+//	  "regular": {"credit_card": 0.9, "max_accounts": 1},
+//	  "premium": {"credit_card": 1, "loan": 0.2, "min_accounts": 3}
+//	}
+type AccountMix struct {
+	Savings    float64 `json:"savings"`
+	Investment float64 `json:"investment"`
+	CreditCard float64 `json:"credit_card"`
+	Loan       float64 `json:"loan"`
+
+	MinAccounts int `json:"min_accounts,omitempty"`
+	MaxAccounts int `json:"max_accounts,omitempty"`
+}
+
+// accountOption is an optional account type and its probability
+type accountOption struct {
+	p           float64
+	accountType models.AccountType
+}
+
+// options returns the optional account types in draw order
+func (m AccountMix) options() []accountOption {
+	return []accountOption{
+		{m.Savings, models.AccountTypeSavings},
+		{m.Investment, models.AccountTypeInvestment},
+		{m.CreditCard, models.AccountTypeCreditCard},
+		{m.Loan, models.AccountTypeLoan},
+	}
+}
+
+// accountTypes draws the account types a customer opens: checking, then
+// each optional type with its probability, adjusted to the count bounds.
+// Probability draws nothing for 0 or 1, so unused types don't shift the RNG.
+func (m AccountMix) accountTypes(rng *utils.Random) []models.AccountType {
+	types := []models.AccountType{models.AccountTypeChecking}
+	for _, opt := range m.options() {
+		if rng.Probability(opt.p) {
+			types = append(types, opt.accountType)
+		}
+	}
+	return m.bound(types)
+}
+
+// bound trims or tops up types to MinAccounts-MaxAccounts. The adjustment
+// is deterministic so bounds don't shift the RNG either.
+func (m AccountMix) bound(types []models.AccountType) []models.AccountType {
+	// Optional types from most to least likely
+	eligible := make([]accountOption, 0, 4)
+	for _, opt := range m.options() {
+		if opt.p > 0 {
+			eligible = append(eligible, opt)
+		}
+	}
+	sort.SliceStable(eligible, func(i, j int) bool { return eligible[i].p > eligible[j].p })
+
+	if m.MaxAccounts > 0 && len(types) > m.MaxAccounts {
+		keep := map[models.AccountType]bool{models.AccountTypeChecking: true}
+		for _, opt := range eligible {
+			if len(keep) == m.MaxAccounts {
+				break
+			}
+			if slices.Contains(types, opt.accountType) {
+				keep[opt.accountType] = true
+			}
+		}
+		types = slices.DeleteFunc(types, func(t models.AccountType) bool { return !keep[t] })
+	}
+
+	// Open missing types first, then second accounts of each, and so on
+	for round := 1; len(types) < m.MinAccounts && len(eligible) > 0; round++ {
+		for _, opt := range eligible {
+			if len(types) >= m.MinAccounts {
+				break
+			}
+			if countType(types, opt.accountType) < round {
+				types = append(types, opt.accountType)
+			}
+		}
+	}
+	return types
+}
+
+// countType counts the accounts of type t
+func countType(types []models.AccountType, t models.AccountType) int {
+	n := 0
+	for _, have := range types {
+		if have == t {
+			n++
+		}
+	}
+	return n
+}
+
+// WithAccountBounds returns a copy of mixes (DefaultAccountMix if nil) with
+// min and max applied to every segment that doesn't set its own bound
+func WithAccountBounds(mixes map[models.CustomerSegment]AccountMix, min, max int) (map[models.CustomerSegment]AccountMix, error) {
+	if err := (AccountMix{MinAccounts: min, MaxAccounts: max}).validateBounds(); err != nil {
+		return nil, fmt.Errorf("invalid account bounds: %w", err)
+	}
+	if mixes == nil {
+		mixes = DefaultAccountMix()
+	}
+	bounded := make(map[models.CustomerSegment]AccountMix, len(mixes))
+	var errs []string
+	for segment, mix := range mixes {
+		if mix.MinAccounts == 0 {
+			mix.MinAccounts = min
+		}
+		if mix.MaxAccounts == 0 {
+			mix.MaxAccounts = max
+		}
+		if err := mix.validateBounds(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", segment, err))
+		}
+		bounded[segment] = mix
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("invalid account bounds: %s", strings.Join(errs, "; "))
+	}
+	return bounded, nil
+}
+
+// validateBounds checks MinAccounts and MaxAccounts
+func (m AccountMix) validateBounds() error {
+	switch {
+	case m.MinAccounts < 0 || m.MaxAccounts < 0:
+		return fmt.Errorf("account bounds must not be negative")
+	case m.MaxAccounts > 0 && m.MinAccounts > m.MaxAccounts:
+		return fmt.Errorf("min_accounts %d exceeds max_accounts %d", m.MinAccounts, m.MaxAccounts)
+	}
+	return nil
+}
+
+// DefaultAccountMix returns the built-in account mix for each customer segment
+func DefaultAccountMix() map[models.CustomerSegment]AccountMix {
+	highNetWorth := AccountMix{Savings: 0.7, Investment: 0.5, CreditCard: 0.8}
+	return map[models.CustomerSegment]AccountMix{
+		models.SegmentRegular:   {Savings: 0.7, CreditCard: 0.4, Loan: 0.1},
+		models.SegmentPremium:   highNetWorth,
+		models.SegmentPrivate:   highNetWorth,
+		models.SegmentBusiness:  {Savings: 0.7},
+		models.SegmentCorporate: {Savings: 0.7},
+	}
+}
+
+// LoadAccountMixFile reads an account mix override file and merges it over
+// DefaultAccountMix
+func LoadAccountMixFile(path string) (map[models.CustomerSegment]AccountMix, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account mix file: %w", err)
+	}
+	var overrides map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse account mix file: %w", err)
+	}
+
+	mixes := DefaultAccountMix()
+	var errs []string
+	for name, override := range overrides {
+		segment := models.CustomerSegment(strings.ToLower(name))
+		mix, ok := mixes[segment]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown segment %q", name))
+			continue
+		}
+		// Decoding over the default keeps fields the file leaves out
+		if err := json.Unmarshal(override, &mix); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		for field, p := range map[string]float64{
+			"savings": mix.Savings, "investment": mix.Investment, "credit_card": mix.CreditCard, "loan": mix.Loan,
+		} {
+			if p < 0 || p > 1 {
+				errs = append(errs, fmt.Sprintf("%s.%s: probability %g outside 0-1", name, field, p))
+			}
+		}
+		if err := mix.validateBounds(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+		mixes[segment] = mix
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("invalid account mix: %s", strings.Join(errs, "; "))
+	}
+	return mixes, nil
+}
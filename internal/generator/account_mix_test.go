@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestLoadAccountMixFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "mix.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	mixes, err := LoadAccountMixFile(write(`{"Regular": {"credit_card": 0.95}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultAccountMix()
+	want := defaults[models.SegmentRegular]
+	want.CreditCard = 0.95
+	if mixes[models.SegmentRegular] != want {
+		t.Errorf("regular mix %+v, expected %+v", mixes[models.SegmentRegular], want)
+	}
+	if mixes[models.SegmentPremium] != defaults[models.SegmentPremium] {
+		t.Errorf("premium mix %+v, expected the default", mixes[models.SegmentPremium])
+	}
+
+	_, err = LoadAccountMixFile(write(`{"vip": {}, "premium": {"loan": 1.5}}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown segment "vip"`) || !strings.Contains(err.Error(), "premium.loan") {
+		t.Errorf("expected unknown segment and range errors, got %v", err)
+	}
+}
+
+func TestAccountMixBounds(t *testing.T) {
+	regular := DefaultAccountMix()[models.SegmentRegular] // Savings 0.7, credit card 0.4, loan 0.1
+	all := []models.AccountType{models.AccountTypeChecking, models.AccountTypeSavings, models.AccountTypeCreditCard, models.AccountTypeLoan}
+
+	for _, tc := range []struct {
+		name     string
+		min, max int
+		types    []models.AccountType
+		want     string
+	}{
+		{"Unbounded", 0, 0, all, "checking savings credit_card loan"},
+		{"MaxDropsLeastLikely", 0, 2, all, "checking savings"},
+		{"CheckingOnly", 0, 1, all, "checking"},
+		{"MinOpensMostLikely", 3, 0, all[:1], "checking savings credit_card"},
+		{"MinRepeatsEligibleTypes", 6, 0, all[:2], "checking savings credit_card loan savings credit_card"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mix := regular
+			mix.MinAccounts, mix.MaxAccounts = tc.min, tc.max
+			var got []string
+			for _, at := range mix.bound(append([]models.AccountType{}, tc.types...)) {
+				got = append(got, string(at))
+			}
+			if strings.Join(got, " ") != tc.want {
+				t.Errorf("Expected %s, got %v", tc.want, got)
+			}
+		})
+	}
+
+	// Segments without eligible optional types can't be topped up
+	none := AccountMix{MinAccounts: 3}
+	if got := none.bound([]models.AccountType{models.AccountTypeChecking}); len(got) != 1 {
+		t.Errorf("Expected only checking, got %v", got)
+	}
+
+	mixes, err := WithAccountBounds(map[models.CustomerSegment]AccountMix{
+		models.SegmentRegular: regular,
+		models.SegmentPremium: {Savings: 1, MaxAccounts: 4},
+	}, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := mixes[models.SegmentRegular]; m.MinAccounts != 2 || m.MaxAccounts != 3 {
+		t.Errorf("Expected flag bounds on regular, got %+v", m)
+	}
+	if m := mixes[models.SegmentPremium]; m.MinAccounts != 2 || m.MaxAccounts != 4 {
+		t.Errorf("Expected premium to keep its own maximum, got %+v", m)
+	}
+	if _, err := WithAccountBounds(nil, 3, 2); err == nil {
+		t.Error("Expected min above max to fail")
+	}
+}
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// ATMDenominations holds the note ATMs dispense in each currency, in whole
+// major units: ATM withdrawals are multiples of it. Currencies without an
+// entry (or with 0) are withdrawn in any amount.
+type ATMDenominations map[models.Currency]int64
+
+// DefaultATMDenominations uses each currency's smallest note commonly
+// stocked in ATMs. ATM amounts are converted from US dollars before they are
+// rounded, so large notes such as JPY 1000 still leave a spread of amounts.
+func DefaultATMDenominations() ATMDenominations {
+	return ATMDenominations{
+		models.CurrencyUSD: 20, models.CurrencyEUR: 10, models.CurrencyGBP: 10,
+		models.CurrencyJPY: 1000, models.CurrencyCHF: 20, models.CurrencyCAD: 20,
+		models.CurrencyAUD: 20, models.CurrencyINR: 100, models.CurrencyCNY: 100,
+		models.CurrencySGD: 10, models.CurrencyHKD: 100, models.CurrencyBRL: 20,
+		models.CurrencyMXN: 100,
+	}
+}
+
+// ParseATMDenominations parses an --atm-denominations value such as
+// "USD=20,EUR=50,JPY=0": the note each currency's ATM withdrawals are
+// multiples of, or 0 for any amount. Listed currencies replace their default.
+func ParseATMDenominations(s string) (ATMDenominations, error) {
+	denominations := DefaultATMDenominations()
+	for _, part := range strings.Split(s, ",") {
+		code, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid ATM denomination entry %q (expected currency=amount)", part)
+		}
+		currency := models.Currency(strings.ToUpper(strings.TrimSpace(code)))
+		if !slices.Contains(models.SupportedCurrencies, currency) {
+			return nil, fmt.Errorf("unsupported currency %q in ATM denominations (expected one of %s)", code, currencyList(models.SupportedCurrencies))
+		}
+		note, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || note < 0 || note > 100000 {
+			return nil, fmt.Errorf("invalid %s ATM denomination %q (expected whole units from 0 to 100000)", currency, value)
+		}
+		denominations[currency] = note
+	}
+	return denominations, nil
+}
+
+// String formats the denominations as an --atm-denominations value
+func (d ATMDenominations) String() string {
+	parts := make([]string, 0, len(d))
+	for _, currency := range models.SupportedCurrencies {
+		if note, ok := d[currency]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%d", currency, note))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// dispense rounds a withdrawal in hundredths to the nearest multiple of the
+// currency's note, and up to one note if it is smaller
+func (d ATMDenominations) dispense(currency models.Currency, cents int64) int64 {
+	note := d[currency] * 100
+	if note <= 0 {
+		return cents
+	}
+	return max((cents+note/2)/note, 1) * note
+}
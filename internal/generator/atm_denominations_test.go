@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestATMWithdrawalDenominations(t *testing.T) {
+	denominations, err := ParseATMDenominations("usd=50, EUR=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if denominations[models.CurrencyUSD] != 50 || denominations[models.CurrencyEUR] != 0 || denominations[models.CurrencyJPY] != 1000 {
+		t.Errorf("parsed %s, want USD=50, EUR=0 and the default JPY=1000", denominations)
+	}
+	for _, value := range []string{"USD", "XYZ=20", "USD=-20", "USD=1.5"} {
+		if _, err := ParseATMDenominations(value); err == nil {
+			t.Errorf("ParseATMDenominations(%q) should fail", value)
+		}
+	}
+
+	usd := GeneratedAccount{Account: models.Account{ID: 1, Type: models.AccountTypeChecking, Currency: models.CurrencyUSD}}
+	eur := GeneratedAccount{Account: models.Account{ID: 2, Type: models.AccountTypeChecking, Currency: models.CurrencyEUR}}
+	jpy := GeneratedAccount{Account: models.Account{ID: 3, Type: models.AccountTypeChecking, Currency: models.CurrencyJPY}}
+	core := newTransactionCore(nil, transactionSettings{
+		MinorUnits:       true,
+		ATMDenominations: ATMDenominations{models.CurrencyUSD: 20, models.CurrencyJPY: 1000},
+	})
+	core.partition = newTransactionPartition(utils.NewRandom(5), nil, 100, 1)
+	core.rng = core.partition.rng
+
+	var unrounded bool
+	for i := 0; i < 200; i++ {
+		if amount := core.generateAmount(models.TxTypeWithdrawal, models.ChannelATM, FeeRate{}, usd, 0, time.Time{}); amount <= 0 || amount%2000 != 0 {
+			t.Fatalf("USD ATM withdrawal of %d cents is not a multiple of $20", amount)
+		}
+		if amount := core.generateAmount(models.TxTypeWithdrawal, models.ChannelATM, FeeRate{}, jpy, 0, time.Time{}); amount <= 0 || amount%1000 != 0 {
+			t.Fatalf("JPY ATM withdrawal of ¥%d is not a multiple of ¥1000", amount)
+		}
+		// Currencies without a note and branch withdrawals keep any amount
+		if amount := core.generateAmount(models.TxTypeWithdrawal, models.ChannelATM, FeeRate{}, eur, 0, time.Time{}); amount%1000 != 0 {
+			unrounded = true
+		}
+		if amount := core.generateAmount(models.TxTypeWithdrawal, models.ChannelBranch, FeeRate{}, usd, 0, time.Time{}); amount%2000 != 0 {
+			unrounded = true
+		}
+	}
+	if !unrounded {
+		t.Error("EUR and branch withdrawals were all rounded")
+	}
+
+	// Rounding is to the nearest note, at least one
+	for _, tc := range []struct{ cents, want int64 }{{4713, 4000}, {5000, 6000}, {999, 2000}, {2000, 2000}} {
+		if got := core.settings.ATMDenominations.dispense(models.CurrencyUSD, tc.cents); got != tc.want {
+			t.Errorf("dispense(%d) = %d, want %d", tc.cents, got, tc.want)
+		}
+	}
+}
+
+func TestATMWithdrawalsSpreadInLargeNoteCurrencies(t *testing.T) {
+	core := newTransactionCore(nil, transactionSettings{MinorUnits: true, ATMDenominations: DefaultATMDenominations()})
+	core.partition = newTransactionPartition(utils.NewRandom(7), nil, 100, 1)
+	core.rng = core.partition.rng
+
+	for _, currency := range []models.Currency{models.CurrencyJPY, models.CurrencyINR, models.CurrencyUSD} {
+		account := GeneratedAccount{Account: models.Account{ID: 1, Type: models.AccountTypeChecking, Currency: currency}}
+		const n = 500
+		counts := make(map[int64]int)
+		var total int64
+		for i := 0; i < n; i++ {
+			amount := core.generateAmount(models.TxTypeWithdrawal, models.ChannelATM, FeeRate{}, account, 0, time.Time{})
+			counts[amount]++
+			total += amount
+		}
+		mostCommon := 0
+		for _, c := range counts {
+			mostCommon = max(mostCommon, c)
+		}
+		// Converted from dollars, withdrawals spread over many notes
+		if len(counts) < 10 || mostCommon > n/4 {
+			t.Errorf("%s ATM withdrawals took %d distinct amounts, the most common %d of %d times", currency, len(counts), mostCommon, n)
+		}
+		// The mean is near $100 in the local currency
+		mean := float64(total) / n / math.Pow10(minorUnits(currency))
+		if want := 100 * fxRate(models.CurrencyUSD, currency); mean < want/2 || mean > want*2 {
+			t.Errorf("%s mean ATM withdrawal %.0f, want about %.0f", currency, mean, want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/willfong/load-generator/internal/generator/patterns"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// ATMEventGenerator simulates ATM cash levels and outages over the history
+// period, producing cash-low, offline and back-online events. Busier ATMs
+// (higher AvgDailyTransactions) drain faster and run out of cash more often.
+type ATMEventGenerator struct {
+	rng    *utils.Random
+	config ATMEventGeneratorConfig
+	hourly *patterns.DailyPattern
+}
+
+// ATMEventGeneratorConfig holds settings for ATM event generation
+type ATMEventGeneratorConfig struct {
+	StartDate time.Time
+	EndDate   time.Time
+
+	CashCapacity       int     // Withdrawals a fully loaded ATM can serve
+	ReplenishDays      int     // Cash replenishment cycle in days
+	CashLowPercent     int     // Cash level that raises a cash_low event
+	FaultsPerYear      float64 // Average unplanned outages per ATM per year
+	MaintenancePerYear float64 // Average maintenance windows per ATM per year
+}
+
+// NewATMEventGenerator creates a new ATM event generator
+func NewATMEventGenerator(rng *utils.Random, config ATMEventGeneratorConfig) *ATMEventGenerator {
+	if config.CashCapacity <= 0 {
+		config.CashCapacity = 2000
+	}
+	if config.ReplenishDays <= 0 {
+		config.ReplenishDays = 7
+	}
+	return &ATMEventGenerator{
+		rng:    rng,
+		config: config,
+		hourly: patterns.NewATMDailyPattern(),
+	}
+}
+
+// atmState tracks one ATM through the simulation
+type atmState struct {
+	atmID     int64
+	status    models.ATMStatus
+	cash      float64   // Withdrawals left before the ATM runs dry
+	cashLow   bool      // cash_low already raised since the last replenishment
+	downUntil time.Time // End of the current fault or maintenance window
+	lastEvent time.Time
+	events    []models.ATMEvent
+}
+
+// GenerateEvents simulates every ATM and returns their events in time order
+// with sequential IDs. Each ATM's Status is updated to its state at the end
+// of the history, so ATMs still down at EndDate are not reported online.
+func (g *ATMEventGenerator) GenerateEvents(atms []GeneratedATM) []models.ATMEvent {
+	var events []models.ATMEvent
+	for i := range atms {
+		state := g.simulateATM(atms[i])
+		atms[i].ATM.Status = state.status
+		events = append(events, state.events...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	for i := range events {
+		events[i].ID = int64(i + 1)
+	}
+	return events
+}
+
+// simulateATM walks one ATM through the history, then takes it out of
+// service if it went offline or into maintenance for good. An ATM already
+// down when that happens stays in its current state.
+func (g *ATMEventGenerator) simulateATM(atm GeneratedATM) *atmState {
+	since := atm.OutOfServiceSince
+	if since == nil || !since.Before(g.config.EndDate) {
+		return g.simulateService(atm.ATM, g.config.EndDate)
+	}
+	if since.Before(g.config.StartDate) {
+		// Down for the whole history
+		return &atmState{atmID: atm.ATM.ID, status: atm.ATM.Status}
+	}
+
+	state := g.simulateService(atm.ATM, *since)
+	if state.status == models.ATMStatusOnline {
+		g.addEvent(state, *since, models.ATMEventOffline, atm.ATM.Status, float64(g.config.CashCapacity))
+	}
+	return state
+}
+
+// simulateService walks one ATM hour by hour from installation (or StartDate) to end
+func (g *ATMEventGenerator) simulateService(atm models.ATM, end time.Time) *atmState {
+	capacity := float64(g.config.CashCapacity)
+	state := &atmState{atmID: atm.ID, status: models.ATMStatusOnline, cash: capacity}
+
+	start := g.config.StartDate
+	if atm.InstalledAt.After(start) {
+		start = atm.InstalledAt
+	}
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	if !day.Before(end) {
+		return state
+	}
+
+	// Cash is replenished on a fixed weekday-style cycle, staggered across ATMs
+	nextReplenish := day.AddDate(0, 0, g.rng.IntN(g.config.ReplenishDays)).Add(time.Duration(g.rng.IntRange(8, 11)) * time.Hour)
+	lowMark := capacity * float64(g.config.CashLowPercent) / 100
+
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		// Daily demand varies around the ATM's average
+		target := int(float64(atm.AvgDailyTransactions) * g.rng.Float64Range(0.7, 1.3))
+		demand := g.hourly.ExpectedTransactionsPerHour(target)
+
+		// Outages start at a random hour; maintenance is scheduled overnight
+		faultHour, maintenanceHour := -1, -1
+		if g.rng.Probability(g.config.FaultsPerYear / 365) {
+			faultHour = g.rng.IntN(24)
+		}
+		if g.rng.Probability(g.config.MaintenancePerYear / 365) {
+			maintenanceHour = g.rng.IntRange(1, 5)
+		}
+
+		for hour := 0; hour < 24; hour++ {
+			t := day.Add(time.Duration(hour) * time.Hour)
+			if !t.Before(end) {
+				break
+			}
+
+			if !t.Before(nextReplenish) {
+				state.cash = capacity
+				state.cashLow = false
+				if state.status == models.ATMStatusOutOfCash {
+					g.addEvent(state, nextReplenish, models.ATMEventOnline, models.ATMStatusOnline, capacity)
+				}
+				nextReplenish = nextReplenish.AddDate(0, 0, g.config.ReplenishDays)
+			}
+
+			if (state.status == models.ATMStatusOffline || state.status == models.ATMStatusMaintenance) &&
+				!t.Before(state.downUntil) {
+				g.addEvent(state, state.downUntil, models.ATMEventOnline, models.ATMStatusOnline, capacity)
+			}
+
+			if state.status != models.ATMStatusOnline {
+				continue
+			}
+
+			switch hour {
+			case faultHour:
+				g.goDown(state, t, models.ATMStatusOffline, g.rng.Duration(time.Hour, 12*time.Hour), capacity)
+				continue
+			case maintenanceHour:
+				g.goDown(state, t, models.ATMStatusMaintenance, g.rng.Duration(2*time.Hour, 6*time.Hour), capacity)
+				continue
+			}
+
+			state.cash -= demand[hour]
+			if !state.cashLow && state.cash <= lowMark {
+				state.cashLow = true
+				g.addEvent(state, g.withinHour(t), models.ATMEventCashLow, models.ATMStatusOnline, capacity)
+			}
+			if state.cash <= 0 {
+				state.cash = 0
+				g.addEvent(state, g.withinHour(t), models.ATMEventOffline, models.ATMStatusOutOfCash, capacity)
+			}
+		}
+	}
+
+	return state
+}
+
+// goDown takes an online ATM out of service for a fault or maintenance window
+func (g *ATMEventGenerator) goDown(state *atmState, t time.Time, status models.ATMStatus, duration time.Duration, capacity float64) {
+	at := g.withinHour(t)
+	g.addEvent(state, at, models.ATMEventOffline, status, capacity)
+	state.downUntil = at.Add(duration)
+}
+
+// withinHour returns a random moment in the hour starting at t
+func (g *ATMEventGenerator) withinHour(t time.Time) time.Time {
+	return t.Add(g.rng.Duration(0, time.Hour-time.Second)).Truncate(time.Second)
+}
+
+// addEvent records an event and the ATM's new status. Events never go
+// backwards in time for an ATM, even when two land in the same hour.
+func (g *ATMEventGenerator) addEvent(state *atmState, at time.Time, eventType models.ATMEventType, status models.ATMStatus, capacity float64) {
+	if !at.After(state.lastEvent) {
+		at = state.lastEvent.Add(time.Minute)
+	}
+	state.lastEvent = at
+	state.status = status
+	state.events = append(state.events, models.ATMEvent{
+		ATMID:     state.atmID,
+		Type:      eventType,
+		Status:    status,
+		CashLevel: int(state.cash / capacity * 100),
+		Timestamp: at,
+	})
+}
+
+// ATMEventHeaders returns the CSV headers for ATM events
+func ATMEventHeaders() []string {
+	return []string{"id", "atm_id", "type", "status", "cash_level", "timestamp"}
+}
+
+// WriteATMEventsCSV writes ATM events to a CSV file in cfg.OutputDir (or .csv.xz at preset cfg.XZPreset if cfg.Compress),
+// split into that many shard files if shards > 1
+func WriteATMEventsCSV(events []models.ATMEvent, cfg CSVWriterConfig, shards int) error {
+	cfg.Filename, cfg.Headers = "atm_events", ATMEventHeaders()
+	writer, err := newTableWriter(cfg, shards, len(events))
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	for _, e := range events {
+		row := []string{
+			FormatInt64(e.ID),
+			FormatInt64(e.ATMID),
+			string(e.Type),
+			string(e.Status),
+			FormatInt(e.CashLevel),
+			FormatTime(e.Timestamp),
+		}
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
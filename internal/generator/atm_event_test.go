@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestATMEventsSequence(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+
+	var atms []GeneratedATM
+	for i := 0; i < 20; i++ {
+		avg := 40
+		if i%2 == 1 {
+			avg = 300
+		}
+		atms = append(atms, GeneratedATM{ATM: models.ATM{
+			ID:                   int64(i + 1),
+			Status:               models.ATMStatusOnline,
+			AvgDailyTransactions: avg,
+			InstalledAt:          start.AddDate(-1, 0, 0),
+		}})
+	}
+
+	gen := NewATMEventGenerator(utils.NewRandom(42), ATMEventGeneratorConfig{
+		StartDate:          start,
+		EndDate:            end,
+		CashCapacity:       2000,
+		ReplenishDays:      7,
+		CashLowPercent:     20,
+		FaultsPerYear:      4,
+		MaintenancePerYear: 2,
+	})
+	events := gen.GenerateEvents(atms)
+	if len(events) == 0 {
+		t.Fatal("expected events")
+	}
+
+	status := make(map[int64]models.ATMStatus)
+	last := make(map[int64]time.Time)
+	cashLow := make(map[int]int) // by avg daily transactions
+	for i, e := range events {
+		if e.ID != int64(i+1) {
+			t.Fatalf("event %d: expected sequential id, got %d", i, e.ID)
+		}
+		if i > 0 && e.Timestamp.Before(events[i-1].Timestamp) {
+			t.Fatalf("event %d: out of time order", e.ID)
+		}
+		if e.Timestamp.Before(start) || !e.Timestamp.Before(end) {
+			t.Errorf("event %d: timestamp %s outside history", e.ID, e.Timestamp)
+		}
+		if !e.Timestamp.After(last[e.ATMID]) {
+			t.Errorf("event %d: not after the ATM's previous event", e.ID)
+		}
+		last[e.ATMID] = e.Timestamp
+
+		prev, ok := status[e.ATMID]
+		if !ok {
+			prev = models.ATMStatusOnline
+		}
+		switch e.Type {
+		case models.ATMEventCashLow, models.ATMEventOffline:
+			if prev != models.ATMStatusOnline {
+				t.Errorf("event %d: %s while ATM is %s", e.ID, e.Type, prev)
+			}
+		case models.ATMEventOnline:
+			if prev == models.ATMStatusOnline {
+				t.Errorf("event %d: online without a preceding outage", e.ID)
+			}
+		}
+		if e.Type == models.ATMEventCashLow {
+			cashLow[atms[e.ATMID-1].ATM.AvgDailyTransactions]++
+		}
+		status[e.ATMID] = e.Status
+	}
+
+	for _, atm := range atms {
+		want, ok := status[atm.ATM.ID]
+		if !ok {
+			want = models.ATMStatusOnline
+		}
+		if atm.ATM.Status != want {
+			t.Errorf("ATM %d: status %s, expected final event status %s", atm.ATM.ID, atm.ATM.Status, want)
+		}
+	}
+
+	if cashLow[300] <= cashLow[40] {
+		t.Errorf("expected busy ATMs to run low more often: %d busy vs %d quiet", cashLow[300], cashLow[40])
+	}
+}
+
+func TestATMEventsStopWhenOutOfService(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	midYear := start.AddDate(0, 6, 0)
+	lastYear := start.AddDate(0, -2, 0)
+
+	atms := []GeneratedATM{
+		{ATM: models.ATM{ID: 1, Status: models.ATMStatusMaintenance, AvgDailyTransactions: 40, InstalledAt: start.AddDate(-1, 0, 0)}, OutOfServiceSince: &midYear},
+		{ATM: models.ATM{ID: 2, Status: models.ATMStatusOffline, AvgDailyTransactions: 40, InstalledAt: start.AddDate(-1, 0, 0)}, OutOfServiceSince: &lastYear},
+	}
+	gen := NewATMEventGenerator(utils.NewRandom(42), ATMEventGeneratorConfig{
+		StartDate:      start,
+		EndDate:        end,
+		CashCapacity:   2000,
+		ReplenishDays:  7,
+		CashLowPercent: 20,
+	})
+	events := gen.GenerateEvents(atms)
+	if len(events) != 1 {
+		t.Fatalf("expected only the maintenance event, got %d events", len(events))
+	}
+	if e := events[0]; e.ATMID != 1 || e.Type != models.ATMEventOffline ||
+		e.Status != models.ATMStatusMaintenance || !e.Timestamp.Equal(midYear) {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if atms[0].ATM.Status != models.ATMStatusMaintenance || atms[1].ATM.Status != models.ATMStatusOffline {
+		t.Errorf("statuses %s and %s, expected maintenance and offline", atms[0].ATM.Status, atms[1].ATM.Status)
+	}
+}
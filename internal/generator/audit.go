@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/willfong/load-generator/internal/data"
@@ -33,10 +34,14 @@ type AuditGeneratorConfig struct {
 	FailedLoginRate      float64 // Rate of failed login attempts (0.0-1.0)
 	LockedAccountRate    float64 // Rate of account lockouts after failures
 	SessionTimeoutRate   float64 // Rate of sessions that timeout vs logout
+	NewDeviceRate        float64 // Rate of sessions from a device the customer hasn't used before
 
 	// Session parameters
 	AvgSessionsPerCustomerPerMonth int // Average login sessions per customer per month
 	AvgBalanceChecksPerSession     int // Average balance inquiries per session
+
+	// Audit actions written (nil = all)
+	Actions AuditActionFilter
 }
 
 // GeneratedAuditLog holds an audit log entry with metadata
@@ -71,7 +76,15 @@ func (g *AuditGenerator) initializeIPPools() {
 		"AF": {{41, 41}, {105, 105}, {154, 154}},           // Africa
 	}
 
-	for region, prefixes := range regionPrefixes {
+	// Fill pools in a fixed region order so they are reproducible for a seed
+	regions := make([]string, 0, len(regionPrefixes))
+	for region := range regionPrefixes {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		prefixes := regionPrefixes[region]
 		ips := make([]string, 0, 100)
 		for i := 0; i < 100; i++ {
 			prefix := prefixes[g.rng.IntN(len(prefixes))]
@@ -102,7 +115,7 @@ func (g *AuditGenerator) GenerateAuditLogs(startID int64) ([]GeneratedAuditLog,
 	sessionLogs := g.generateSessionAuditLogs(&currentID)
 	auditLogs = append(auditLogs, sessionLogs...)
 
-	return auditLogs, currentID
+	return g.config.Actions.filter(auditLogs), currentID
 }
 
 // generateTransactionAuditLogs creates audit entries for each transaction
@@ -134,7 +147,7 @@ func (g *AuditGenerator) createTransactionInitiatedLog(txn GeneratedTransaction,
 	sessionID := fmt.Sprintf("SES%s%08d", t.Timestamp.Format("20060102"), c.ID)
 
 	// Get IP and user agent based on channel
-	ipAddress, userAgent := g.getChannelContext(channel, c)
+	ipAddress, userAgent := g.getChannelContext(channel, txn.Account.Customer, t.Timestamp)
 
 	log := models.AuditLog{
 		ID:            *currentID,
@@ -165,7 +178,7 @@ func (g *AuditGenerator) createTransactionCompletedLog(txn GeneratedTransaction,
 
 	channel := channelToAuditChannel(t.Channel)
 	sessionID := fmt.Sprintf("SES%s%08d", t.Timestamp.Format("20060102"), c.ID)
-	ipAddress, userAgent := g.getChannelContext(channel, c)
+	ipAddress, userAgent := g.getChannelContext(channel, txn.Account.Customer, t.Timestamp)
 
 	// Determine action and outcome based on transaction status
 	var action models.AuditAction
@@ -210,7 +223,7 @@ func (g *AuditGenerator) createTransactionCompletedLog(txn GeneratedTransaction,
 		UserAgent:     userAgent,
 		AccountID:     &t.AccountID,
 		TransactionID: &t.ID,
-		Description:   fmt.Sprintf("Transaction %s: %s %s", outcome, t.Type, t.ReferenceNumber),
+		Description:   transactionOutcomeDescription(t, outcome),
 		FailureReason: failureReason,
 		SessionID:     sessionID,
 		RequestID:     fmt.Sprintf("REQ%d", *currentID),
@@ -229,8 +242,16 @@ func (g *AuditGenerator) generateSessionAuditLogs(currentID *int64) []GeneratedA
 		return logs
 	}
 
+	// Transactions aren't necessarily in timestamp order, so scan for the range
 	startDate := g.config.Transactions[0].Transaction.Timestamp
-	endDate := g.config.Transactions[len(g.config.Transactions)-1].Transaction.Timestamp
+	endDate := startDate
+	for _, t := range g.config.Transactions[1:] {
+		if ts := t.Transaction.Timestamp; ts.Before(startDate) {
+			startDate = ts
+		} else if ts.After(endDate) {
+			endDate = ts
+		}
+	}
 
 	// For each customer, generate sessions
 	for _, customer := range g.config.Customers {
@@ -249,6 +270,14 @@ func (g *AuditGenerator) generateCustomerSessionLogs(
 ) []GeneratedAuditLog {
 	logs := make([]GeneratedAuditLog, 0)
 
+	// Sessions stop once the customer is suspended or closed
+	if changedAt := customer.StatusChangedAt; changedAt != nil && changedAt.Before(endDate) {
+		if !changedAt.After(startDate) {
+			return logs
+		}
+		endDate = *changedAt
+	}
+
 	// Calculate number of sessions for this customer
 	months := int(endDate.Sub(startDate).Hours() / (24 * 30))
 	if months < 1 {
@@ -281,10 +310,32 @@ func (g *AuditGenerator) generateCustomerSessionLogs(
 			hour, minute, g.rng.IntRange(0, 59), 0, time.UTC,
 		)
 
+		if !customer.ActiveAt(sessionTime) {
+			continue
+		}
+
 		sessionLogs := g.generateSingleSession(customer, sessionTime, currentID)
 		logs = append(logs, sessionLogs...)
 	}
 
+	if moved := customer.Relocation; moved != nil && !moved.At.Before(startDate) && moved.At.Before(endDate) {
+		logs = append(logs, GeneratedAuditLog{AuditLog: relocationAuditLog(customer, *currentID)})
+		*currentID++
+	}
+	nextID := func() int64 {
+		id := *currentID
+		*currentID++
+		return id
+	}
+	for _, log := range kycAuditLogs(customer, startDate, endDate, nextID) {
+		logs = append(logs, GeneratedAuditLog{AuditLog: log})
+	}
+
+	// The status change itself closes out the customer's audit trail
+	if customer.StatusChangedAt != nil && customer.StatusChangedAt.Equal(endDate) {
+		logs = append(logs, g.createCustomerStatusChangedLog(customer, currentID))
+	}
+
 	return logs
 }
 
@@ -300,24 +351,19 @@ func (g *AuditGenerator) generateSingleSession(
 	customerID := c.ID
 
 	// Choose channel (mostly online, some ATM)
-	var channel models.AuditChannel
-	var atmID *int64
-
-	if g.rng.Probability(0.7) {
-		channel = models.AuditChannelOnline
-	} else if g.rng.Probability(0.2) {
-		channel = models.AuditChannelMobile
-	} else {
-		channel = models.AuditChannelATM
-		if len(g.config.ATMs) > 0 {
-			atm := g.config.ATMs[g.rng.IntN(len(g.config.ATMs))]
-			atmID = &atm.ATM.ID
-		}
-	}
+	channel, atmID := sessionChannel(g.rng, g.config.ATMs, customer, sessionTime)
 
-	ipAddress, userAgent := g.getChannelContext(channel, c)
+	// One IP and device for every event in the session
+	ipAddress, userAgent := g.getChannelContext(channel, customer, sessionTime)
 	sessionID := fmt.Sprintf("SES%s%08d%04d", sessionTime.Format("20060102150405"), customerID, g.rng.IntN(10000))
 
+	// Occasionally the customer signs in from an unrecognized device, which raises risk
+	var risk *float64
+	if userAgentsFor(channel) != nil && g.rng.Probability(g.config.NewDeviceRate) {
+		userAgent = unfamiliarUserAgent(g.rng, channel, customer)
+		risk = newDeviceRiskScore(g.rng)
+	}
+
 	// Should this be a failed login?
 	isFailedLogin := g.rng.Probability(g.config.FailedLoginRate)
 
@@ -326,7 +372,7 @@ func (g *AuditGenerator) generateSingleSession(
 		failedAttempts := g.rng.IntRange(1, 3)
 		for i := 0; i < failedAttempts; i++ {
 			attemptTime := sessionTime.Add(time.Duration(i*10) * time.Second)
-			failLog := g.createLoginFailedLog(customerID, attemptTime, channel, atmID, ipAddress, userAgent, sessionID, currentID)
+			failLog := g.createLoginFailedLog(customerID, attemptTime, channel, atmID, ipAddress, userAgent, sessionID, risk, currentID)
 			logs = append(logs, failLog)
 		}
 
@@ -341,7 +387,7 @@ func (g *AuditGenerator) generateSingleSession(
 	}
 
 	// Successful login
-	loginLog := g.createLoginSuccessLog(customerID, sessionTime, channel, atmID, ipAddress, userAgent, sessionID, currentID)
+	loginLog := g.createLoginSuccessLog(customerID, sessionTime, channel, atmID, ipAddress, userAgent, sessionID, risk, currentID)
 	logs = append(logs, loginLog)
 
 	// Session started
@@ -389,7 +435,11 @@ func (g *AuditGenerator) generateSingleSession(
 
 // Helper functions to create specific audit log types
 
-func (g *AuditGenerator) createLoginSuccessLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string, currentID *int64) GeneratedAuditLog {
+func (g *AuditGenerator) createLoginSuccessLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string, risk *float64, currentID *int64) GeneratedAuditLog {
+	description := "User logged in successfully"
+	if risk != nil {
+		description = "User logged in from a new device"
+	}
 	log := models.AuditLog{
 		ID:         *currentID,
 		Timestamp:  ts,
@@ -400,15 +450,16 @@ func (g *AuditGenerator) createLoginSuccessLog(customerID int64, ts time.Time, c
 		ATMID:      atmID,
 		IPAddress:  ip,
 		UserAgent:  ua,
-		Description: "User logged in successfully",
+		Description: description,
 		SessionID:  sessionID,
+		RiskScore:  risk,
 		RequestID:  fmt.Sprintf("REQ%d", *currentID),
 	}
 	*currentID++
 	return GeneratedAuditLog{AuditLog: log}
 }
 
-func (g *AuditGenerator) createLoginFailedLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string, currentID *int64) GeneratedAuditLog {
+func (g *AuditGenerator) createLoginFailedLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string, risk *float64, currentID *int64) GeneratedAuditLog {
 	reasons := []string{"invalid_password", "invalid_pin", "expired_credentials", "user_not_found"}
 	reason := reasons[g.rng.IntN(len(reasons))]
 
@@ -425,6 +476,7 @@ func (g *AuditGenerator) createLoginFailedLog(customerID int64, ts time.Time, ch
 		Description:   "Login attempt failed",
 		FailureReason: reason,
 		SessionID:     sessionID,
+		RiskScore:     risk,
 		RequestID:     fmt.Sprintf("REQ%d", *currentID),
 	}
 	*currentID++
@@ -451,6 +503,24 @@ func (g *AuditGenerator) createAccountLockedLog(customerID int64, ts time.Time,
 	return GeneratedAuditLog{AuditLog: log}
 }
 
+func (g *AuditGenerator) createCustomerStatusChangedLog(customer GeneratedCustomer, currentID *int64) GeneratedAuditLog {
+	action, description := customerStatusChangeAction(customer.Customer.Status)
+	customerID := customer.Customer.ID
+	log := models.AuditLog{
+		ID:          *currentID,
+		Timestamp:   *customer.StatusChangedAt,
+		CustomerID:  &customerID,
+		SystemID:    "customer_lifecycle",
+		Action:      action,
+		Outcome:     models.OutcomeSuccess,
+		Channel:     models.AuditChannelSystem,
+		Description: description,
+		RequestID:   fmt.Sprintf("REQ%d", *currentID),
+	}
+	*currentID++
+	return GeneratedAuditLog{AuditLog: log}
+}
+
 func (g *AuditGenerator) createLogoutLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string, currentID *int64) GeneratedAuditLog {
 	log := models.AuditLog{
 		ID:         *currentID,
@@ -548,43 +618,50 @@ func (g *AuditGenerator) createBalanceInquiryLog(customerID, accountID int64, ts
 	return GeneratedAuditLog{AuditLog: log}
 }
 
-// getChannelContext returns IP address and user agent based on channel
-func (g *AuditGenerator) getChannelContext(channel models.AuditChannel, customer models.Customer) (string, string) {
+// sessionChannel picks where a session takes place: mostly online, some on
+// mobile or at an ATM in service. Customers without online banking sign in at
+// an ATM or at a branch counter instead.
+func sessionChannel(rng *utils.Random, atms []GeneratedATM, customer GeneratedCustomer, at time.Time) (models.AuditChannel, *int64) {
+	switch {
+	case customer.Offline:
+		if rng.Probability(0.4) {
+			return models.AuditChannelBranch, nil
+		}
+	case rng.Probability(0.7):
+		return models.AuditChannelOnline, nil
+	case rng.Probability(0.2):
+		return models.AuditChannelMobile, nil
+	}
+	if atm := pickATMInService(rng, atms, at); atm != nil {
+		return models.AuditChannelATM, &atm.ATM.ID
+	}
+	return models.AuditChannelATM, nil
+}
+
+// getChannelContext returns IP address and user agent based on channel.
+// Both are fixed per customer per day, so every event in a session shares them.
+func (g *AuditGenerator) getChannelContext(channel models.AuditChannel, customer GeneratedCustomer, at time.Time) (string, string) {
 	switch channel {
 	case models.AuditChannelATM:
 		// ATM doesn't have user agent, IP is internal
-		return "10.0.0." + fmt.Sprintf("%d", g.rng.IntRange(1, 254)), ""
+		return fmt.Sprintf("10.0.0.%d", 1+dailyPick(customer.Customer.ID, at, 1, 254)), ""
 
 	case models.AuditChannelBranch:
 		// Branch teller systems
-		return "192.168." + fmt.Sprintf("%d.%d", g.rng.IntRange(1, 10), g.rng.IntRange(1, 254)), "BranchTellerSystem/3.2"
-
-	case models.AuditChannelMobile:
-		// Mobile app
-		region := g.getCustomerRegion(customer)
-		ips := g.ipPools[region]
-		ip := ips[g.rng.IntN(len(ips))]
-
-		agents := []string{
-			"BankApp/5.2.1 (iOS 17.0; iPhone14,2)",
-			"BankApp/5.2.0 (Android 14; Pixel 8)",
-			"BankApp/5.1.9 (iOS 16.5; iPhone13,4)",
-			"BankApp/5.1.8 (Android 13; Samsung S23)",
-		}
-		return ip, agents[g.rng.IntN(len(agents))]
+		return fmt.Sprintf("192.168.%d.%d", 1+dailyPick(customer.Customer.ID, at, 2, 10), 1+dailyPick(customer.Customer.ID, at, 3, 254)),
+			"BranchTellerSystem/3.2"
 
-	case models.AuditChannelOnline:
-		// Web browser
-		region := g.getCustomerRegion(customer)
+	case models.AuditChannelMobile, models.AuditChannelOnline:
+		// Mobile app or web browser, on one of the customer's own devices when known.
+		// The IP holds for the day, so it never changes within a session.
+		region := g.getCustomerRegion(customer.Customer)
 		ips := g.ipPools[region]
-		ip := ips[g.rng.IntN(len(ips))]
+		ip := ips[dailyPick(customer.Customer.ID, at, 4, len(ips))]
 
-		agents := []string{
-			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
-			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
-			"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
-			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 Safari/17.2",
+		if device, ok := customer.deviceFor(channel, at); ok {
+			return ip, device.UserAgent
 		}
+		agents := userAgentsFor(channel)
 		return ip, agents[g.rng.IntN(len(agents))]
 
 	default:
@@ -611,6 +688,33 @@ func (g *AuditGenerator) getCustomerRegion(customer models.Customer) string {
 	}
 }
 
+// relocationAuditLog records a customer's move to a new home branch, made at
+// the new branch
+func relocationAuditLog(customer GeneratedCustomer, id int64) models.AuditLog {
+	customerID := customer.Customer.ID
+	branchID := customer.Customer.HomeBranch
+	return models.AuditLog{
+		ID:          id,
+		Timestamp:   customer.Relocation.At,
+		CustomerID:  &customerID,
+		BranchID:    &branchID,
+		SystemID:    "customer_lifecycle",
+		Action:      models.AuditAddressChanged,
+		Outcome:     models.OutcomeSuccess,
+		Channel:     models.AuditChannelBranch,
+		Description: fmt.Sprintf("Customer relocated; home branch changed from %d to %d", customer.Relocation.FromBranch, branchID),
+		RequestID:   fmt.Sprintf("REQ%d", id),
+	}
+}
+
+// customerStatusChangeAction maps a churned customer status to its audit action and description
+func customerStatusChangeAction(status models.CustomerStatus) (models.AuditAction, string) {
+	if status == models.CustomerStatusClosed {
+		return models.AuditCustomerClosed, "Customer relationship closed"
+	}
+	return models.AuditCustomerSuspended, "Customer suspended"
+}
+
 // channelToAuditChannel converts transaction channel to audit channel
 func channelToAuditChannel(txnChannel models.TransactionChannel) models.AuditChannel {
 	switch txnChannel {
@@ -631,31 +735,32 @@ func channelToAuditChannel(txnChannel models.TransactionChannel) models.AuditCha
 	}
 }
 
-// WriteAuditLogsCSV writes audit logs to a CSV file (or .csv.xz if compress=true)
-func WriteAuditLogsCSV(auditLogs []GeneratedAuditLog, outputDir string, compress bool) error {
-	return writeAuditLogsCSVInternal(auditLogs, outputDir, compress, false)
+// transactionOutcomeDescription describes a transaction's outcome, naming the
+// original transaction when it is a reversal
+func transactionOutcomeDescription(t models.Transaction, outcome models.AuditOutcome) string {
+	desc := fmt.Sprintf("Transaction %s: %s %s", outcome, t.Type, t.ReferenceNumber)
+	if isReversal(t) {
+		desc += fmt.Sprintf(" (reversal of transaction %d)", *t.LinkedTransactionID)
+	}
+	return desc
+}
+
+// WriteAuditLogsCSV writes audit logs to a CSV file in cfg.OutputDir (or .csv.xz at preset cfg.XZPreset if cfg.Compress)
+func WriteAuditLogsCSV(auditLogs []GeneratedAuditLog, cfg CSVWriterConfig) error {
+	return writeAuditLogsCSVInternal(auditLogs, cfg, false)
 }
 
 // WriteAuditLogsCSVWithProgress writes audit logs to a CSV file with progress reporting
-func WriteAuditLogsCSVWithProgress(auditLogs []GeneratedAuditLog, outputDir string, compress bool) error {
-	return writeAuditLogsCSVInternal(auditLogs, outputDir, compress, true)
+func WriteAuditLogsCSVWithProgress(auditLogs []GeneratedAuditLog, cfg CSVWriterConfig) error {
+	return writeAuditLogsCSVInternal(auditLogs, cfg, true)
 }
 
 // writeAuditLogsCSVInternal is the internal implementation with optional progress
-func writeAuditLogsCSVInternal(auditLogs []GeneratedAuditLog, outputDir string, compress, showProgress bool) error {
-	headers := []string{
-		"id", "timestamp", "customer_id", "employee_id", "system_id",
-		"action", "outcome", "channel", "branch_id", "atm_id",
-		"ip_address", "user_agent", "account_id", "transaction_id", "beneficiary_id",
-		"description", "failure_reason", "metadata", "session_id", "risk_score", "request_id",
-	}
-
-	writer, err := NewCSVWriter(CSVWriterConfig{
-		OutputDir: outputDir,
-		Filename:  "audit_logs",
-		Headers:   headers,
-		Compress:  compress,
-	})
+func writeAuditLogsCSVInternal(auditLogs []GeneratedAuditLog, cfg CSVWriterConfig, showProgress bool) error {
+	headers := AuditLogHeaders()
+
+	cfg.Filename, cfg.Headers = "audit_logs", headers
+	writer, err := NewCSVWriter(cfg)
 	if err != nil {
 		return err
 	}
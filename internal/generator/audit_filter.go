@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// AuditActionFilter is the set of audit actions written (nil = all). Events
+// of other actions are still generated, so IDs, session IDs and random draws
+// match a full run and the filtered file is a subset of its audit logs.
+type AuditActionFilter map[models.AuditAction]bool
+
+// auditActions lists every audit action, in schema order
+func auditActions() []string {
+	typ := reflect.TypeOf(models.AuditAction(""))
+	for _, e := range schemaEnums {
+		if e.typ == typ {
+			return e.values
+		}
+	}
+	return nil
+}
+
+// ParseAuditActionFilter parses --audit-actions and --exclude-audit-actions
+// values such as "login_success,logout" or "transaction_*": the actions to
+// write (empty = all), then those to leave out. A trailing * matches every
+// action with that prefix. Returns nil when both are empty.
+func ParseAuditActionFilter(include, exclude string) (AuditActionFilter, error) {
+	if strings.TrimSpace(include) == "" && strings.TrimSpace(exclude) == "" {
+		return nil, nil
+	}
+	filter := make(AuditActionFilter)
+	if strings.TrimSpace(include) == "" {
+		for _, action := range auditActions() {
+			filter[models.AuditAction(action)] = true
+		}
+	} else {
+		actions, err := matchAuditActions(include)
+		if err != nil {
+			return nil, err
+		}
+		for _, action := range actions {
+			filter[action] = true
+		}
+	}
+	if strings.TrimSpace(exclude) != "" {
+		actions, err := matchAuditActions(exclude)
+		if err != nil {
+			return nil, err
+		}
+		for _, action := range actions {
+			delete(filter, action)
+		}
+	}
+	if len(filter) == 0 {
+		return nil, fmt.Errorf("audit action filter leaves no actions to write")
+	}
+	return filter, nil
+}
+
+// matchAuditActions resolves a comma-separated list of actions and prefix patterns
+func matchAuditActions(s string) ([]models.AuditAction, error) {
+	var matched []models.AuditAction
+	for _, part := range strings.Split(s, ",") {
+		pattern := strings.ToLower(strings.TrimSpace(part))
+		prefix, wildcard := strings.CutSuffix(pattern, "*")
+		n := len(matched)
+		for _, action := range auditActions() {
+			if action == pattern || (wildcard && strings.HasPrefix(action, prefix)) {
+				matched = append(matched, models.AuditAction(action))
+			}
+		}
+		if len(matched) == n {
+			return nil, fmt.Errorf("unknown audit action %q (expected one of %s)", part, strings.Join(auditActions(), ", "))
+		}
+	}
+	return matched, nil
+}
+
+// Allows reports whether events of an action are written
+func (f AuditActionFilter) Allows(action models.AuditAction) bool {
+	return f == nil || f[action]
+}
+
+// Actions lists the selected actions in schema order (nil = all)
+func (f AuditActionFilter) Actions() []string {
+	if f == nil {
+		return nil
+	}
+	var actions []string
+	for _, action := range auditActions() {
+		if f[models.AuditAction(action)] {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// filter drops the logs whose action isn't selected
+func (f AuditActionFilter) filter(logs []GeneratedAuditLog) []GeneratedAuditLog {
+	if f == nil {
+		return logs
+	}
+	return slices.DeleteFunc(logs, func(l GeneratedAuditLog) bool { return !f[l.AuditLog.Action] })
+}
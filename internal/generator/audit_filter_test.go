@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestParseAuditActionFilter(t *testing.T) {
+	if f, err := ParseAuditActionFilter("", ""); err != nil || f != nil || !f.Allows(models.AuditLogout) {
+		t.Fatalf("empty filter = %v, %v; want nil, allowing every action", f, err)
+	}
+
+	f, err := ParseAuditActionFilter("login_*, LOGOUT", "login_failed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.Actions(), []string{"login_success", "logout"}; !slices.Equal(got, want) {
+		t.Errorf("actions %v, want %v", got, want)
+	}
+
+	f, err = ParseAuditActionFilter("", "transaction_initiated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Allows(models.AuditTransactionInitiated) || !f.Allows(models.AuditTransactionCompleted) || len(f.Actions()) != len(auditActions())-1 {
+		t.Errorf("excluding transaction_initiated selected %v", f.Actions())
+	}
+	logs := []GeneratedAuditLog{
+		{AuditLog: models.AuditLog{ID: 1, Action: models.AuditTransactionInitiated}},
+		{AuditLog: models.AuditLog{ID: 2, Action: models.AuditTransactionCompleted}},
+	}
+	if kept := f.filter(logs); len(kept) != 1 || kept[0].AuditLog.ID != 2 {
+		t.Errorf("filter kept %v, want only the completed event", kept)
+	}
+
+	for _, tc := range []struct{ include, exclude string }{
+		{"login", ""},
+		{"", "session_*,bogus"},
+		{"logout", "logout"},
+	} {
+		if _, err := ParseAuditActionFilter(tc.include, tc.exclude); err == nil {
+			t.Errorf("ParseAuditActionFilter(%q, %q) should fail", tc.include, tc.exclude)
+		}
+	}
+}
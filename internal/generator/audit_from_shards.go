@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// transactionAuditColumns are the transaction columns the audit events are built from
+var transactionAuditColumns = []string{
+	"id", "reference_number", "account_id", "type", "status", "channel",
+	"branch_id", "atm_id", "linked_transaction_id", "timestamp", "failure_reason",
+}
+
+// parseAuditTransaction converts a row of transactionAuditColumns back into
+// the parts of a transaction its audit events use
+func parseAuditTransaction(row []string) (models.Transaction, error) {
+	var t models.Transaction
+	var err error
+	if t.ID, err = strconv.ParseInt(row[0], 10, 64); err != nil {
+		return t, fmt.Errorf("invalid id %q: %w", row[0], err)
+	}
+	t.ReferenceNumber = row[1]
+	if t.AccountID, err = strconv.ParseInt(row[2], 10, 64); err != nil {
+		return t, fmt.Errorf("txn %d: invalid account_id %q: %w", t.ID, row[2], err)
+	}
+	t.Type = models.TransactionType(row[3])
+	t.Status = models.TransactionStatus(row[4])
+	t.Channel = models.TransactionChannel(row[5])
+	for i, field := range []**int64{&t.BranchID, &t.ATMID, &t.LinkedTransactionID} {
+		col := 6 + i
+		if row[col] == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(row[col], 10, 64)
+		if err != nil {
+			return t, fmt.Errorf("txn %d: invalid %s %q: %w", t.ID, transactionAuditColumns[col], row[col], err)
+		}
+		*field = &id
+	}
+	if t.Timestamp, err = time.Parse("2006-01-02 15:04:05", row[9]); err != nil {
+		return t, fmt.Errorf("txn %d: invalid timestamp %q: %w", t.ID, row[9], err)
+	}
+	if row[10] != "" {
+		reason := row[10]
+		t.FailureReason = &reason
+	}
+	return t, nil
+}
+
+// generateTransactionAuditFromShards reads the transaction shards back and
+// writes their initiated and outcome audit events, for transactions generated
+// with TransactionAuditFromShards. Each transaction shard file gets its own
+// audit_logs_txn shard; event IDs come from the transaction IDs exactly as
+// when they are written alongside each transaction.
+func (o *Orchestrator) generateTransactionAuditFromShards(ctx context.Context) (int64, error) {
+	files, err := FindShardedFiles(o.tableDir("transactions"), "transactions")
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, fmt.Errorf("no transaction shards to read audit events from in %s", o.tableDir("transactions"))
+	}
+	if o.showProgress {
+		fmt.Printf("Writing transaction audit events from %d transaction shards...\n", len(files))
+	}
+
+	accountIndex := o.accountIndex()
+	auditRNG := o.rng.Derive("transaction_audit_logs")
+	fileRNGs := auditRNG.Derive("shards").ForkN(len(files))
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	counts := make([]int64, len(files))
+	errChan := make(chan error, len(files))
+	next := make(chan int, len(files))
+	for i := range files {
+		next <- i
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(o.workerCount(), len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if workerCtx.Err() != nil {
+					return
+				}
+
+				// IP address pools are drawn as the transaction workers draw them
+				gen, err := NewStreamingAuditGenerator(auditRNG.Clone().Fork(), o.refData, StreamingAuditConfig{
+					ATMs:          o.atms,
+					WorkerID:      i,
+					WorkerCount:   len(files),
+					OutputDir:     o.tableDir("audit_logs"),
+					Compress:      o.config.Compress,
+					CompressLevel: o.config.CompressLevel,
+					Limiter:       o.config.WriteLimiter,
+					Avro:          o.config.Avro,
+					Filename:      TransactionAuditBasename,
+					MaxFileRows:   o.config.MaxFileRows,
+					BufferSize:    o.config.WriteBufferSize,
+					FlushInterval: o.config.FlushInterval,
+
+					TransactionAuditIDBase: o.transactionAuditIDBase,
+					SessionTransactionRate: o.config.SessionTransactionRate,
+					Actions:                o.config.AuditActions,
+				})
+				if err != nil {
+					errChan <- fmt.Errorf("shard %d: failed to create generator: %w", i+1, err)
+					cancel()
+					return
+				}
+				gen.rng = fileRNGs[i]
+
+				err = ReadCSVRows(workerCtx, files[i], transactionAuditColumns, func(row []string) error {
+					t, err := parseAuditTransaction(row)
+					if err != nil {
+						return err
+					}
+					acc, ok := accountIndex.byID[t.AccountID]
+					if !ok {
+						return nil
+					}
+					return gen.WriteTransactionAuditLogs(t, acc.Customer)
+				})
+				if closeErr := gen.Close(); err == nil {
+					err = closeErr
+				}
+				if err != nil {
+					errChan <- fmt.Errorf("%s: %w", files[i], err)
+					cancel()
+					return
+				}
+				counts[i] = gen.Count()
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := firstWorkerError(errChan); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	return total, nil
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// Audit IDs each transaction reserves: initiated and outcome, plus login,
+// session_started, logout and session_ended when sessions are enabled
+const (
+	transactionAuditSlots        = 2
+	sessionTransactionAuditSlots = 6
+)
+
+// transactionSession is a login session opened around a customer's online or
+// ATM transactions. Later transactions on the same channel join it while it
+// is still open.
+type transactionSession struct {
+	customerID int64
+	channel    models.AuditChannel
+	atmID      *int64
+	id         string
+	ip         string
+	userAgent  string
+	last       time.Time     // Latest transaction in the session
+	idle       time.Duration // How long after the latest transaction the customer logs out
+	closeID    int64         // IDs for the logout and session_ended events
+}
+
+// transactionAuditSlots returns how many audit IDs each transaction reserves
+func (g *StreamingAuditGenerator) transactionAuditSlots() int64 {
+	return auditSlotsPerTransaction(g.config.SessionTransactionRate)
+}
+
+// auditSlotsPerTransaction returns how many audit IDs each transaction
+// reserves at the given session transaction rate
+func auditSlotsPerTransaction(sessionTransactionRate float64) int64 {
+	if sessionTransactionRate > 0 {
+		return sessionTransactionAuditSlots
+	}
+	return transactionAuditSlots
+}
+
+// transactionSession picks the login session txn is made in, if any: the open
+// session when txn falls inside it, otherwise (at SessionTransactionRate) a
+// new one whose login and session_started events take the IDs from firstID.
+// Returns nil for transactions made outside a session.
+func (g *StreamingAuditGenerator) transactionSession(txn models.Transaction, customer GeneratedCustomer, firstID int64) (*transactionSession, error) {
+	channel := channelToAuditChannel(txn.Channel)
+	if g.config.SessionTransactionRate <= 0 || (channel != models.AuditChannelOnline && channel != models.AuditChannelATM) {
+		return nil, nil
+	}
+	if !g.rng.Probability(g.config.SessionTransactionRate) {
+		return nil, nil
+	}
+
+	customerID := customer.Customer.ID
+	if s := g.session; s != nil && s.customerID == customerID && s.channel == channel && sameATM(s.atmID, txn.ATMID) &&
+		!txn.Timestamp.Before(s.last) && !txn.Timestamp.After(s.last.Add(s.idle)) {
+		s.last = txn.Timestamp
+		return s, nil
+	}
+	if err := g.closeTransactionSession(); err != nil {
+		return nil, err
+	}
+
+	// Log in shortly before the transaction; its initiated event is at most 30s before it
+	start := txn.Timestamp.Add(-time.Duration(g.rng.IntRange(60, 300)) * time.Second)
+	ipAddress, userAgent := g.getChannelContext(channel, customer, start)
+	s := &transactionSession{
+		customerID: customerID,
+		channel:    channel,
+		atmID:      txn.ATMID,
+		id:         fmt.Sprintf("SES%s%08d%04d", start.Format("20060102150405"), customerID, g.rng.IntN(10000)),
+		ip:         ipAddress,
+		userAgent:  userAgent,
+		last:       txn.Timestamp,
+		idle:       time.Duration(g.rng.IntRange(60, 900)) * time.Second,
+		closeID:    firstID + 2,
+	}
+
+	g.currentID = firstID
+	if err := g.writeLoginSuccessLog(customerID, start, channel, s.atmID, s.ip, s.userAgent, s.id, nil); err != nil {
+		return nil, err
+	}
+	if err := g.writeSessionStartedLog(customerID, start.Add(time.Second), channel, s.atmID, s.ip, s.userAgent, s.id); err != nil {
+		return nil, err
+	}
+	g.session = s
+	return s, nil
+}
+
+// closeTransactionSession writes the open session's logout and session_ended
+// events. Everything about them was drawn when the session was opened, so the
+// output doesn't depend on when it is closed.
+func (g *StreamingAuditGenerator) closeTransactionSession() error {
+	s := g.session
+	if s == nil {
+		return nil
+	}
+	g.session = nil
+
+	currentID := g.currentID
+	defer func() { g.currentID = currentID }()
+
+	g.currentID = s.closeID
+	end := s.last.Add(s.idle)
+	if err := g.writeLogoutLog(s.customerID, end, s.channel, s.atmID, s.ip, s.userAgent, s.id); err != nil {
+		return err
+	}
+	return g.writeSessionEndedLog(s.customerID, end.Add(time.Second), s.channel, s.atmID, s.ip, s.userAgent, s.id)
+}
+
+func sameATM(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
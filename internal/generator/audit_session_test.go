@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestTransactionsShareLoginSessions(t *testing.T) {
+	dir := t.TempDir()
+	const base = 1000
+	gen, err := NewStreamingAuditGenerator(utils.NewRandom(42), nil, StreamingAuditConfig{
+		OutputDir:              dir,
+		Filename:               TransactionAuditBasename,
+		WorkerCount:            1,
+		TransactionAuditIDBase: base,
+		SessionTransactionRate: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customer := GeneratedCustomer{Customer: models.Customer{ID: 3, Country: "DE"}}
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	txns := []models.Transaction{
+		{ID: 1, Channel: models.ChannelOnline, Timestamp: day.Add(9 * time.Hour)},
+		{ID: 2, Channel: models.ChannelOnline, Timestamp: day.Add(9*time.Hour + 30*time.Second)},
+		{ID: 3, Channel: models.ChannelOnline, Timestamp: day.Add(18 * time.Hour)},
+		{ID: 4, Channel: models.ChannelBranch, Timestamp: day.Add(19 * time.Hour)},
+	}
+	for _, txn := range txns {
+		txn.Status = models.TxStatusCompleted
+		txn.AccountID = 30
+		if err := gen.WriteTransactionAuditLogs(txn, customer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gen.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	type event struct {
+		action, session, ts string
+		txn                 int64
+	}
+	var events []event
+	ids := make(map[int64]bool)
+	err = ReadCSVRows(context.Background(), gen.ShardFile(), []string{"id", "action", "session_id", "timestamp", "transaction_id"}, func(row []string) error {
+		id, _ := strconv.ParseInt(row[0], 10, 64)
+		if ids[id] || id <= base || id > base+6*int64(len(txns)) {
+			t.Errorf("audit ID %d is duplicated or outside the reserved range", id)
+		}
+		ids[id] = true
+		txn, _ := strconv.ParseInt(row[4], 10, 64)
+		events = append(events, event{row[1], row[2], row[3], txn})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionOf := make(map[int64]string)
+	spans := make(map[string][2]string)
+	for _, e := range events {
+		if e.txn > 0 {
+			sessionOf[e.txn] = e.session
+		}
+		switch models.AuditAction(e.action) {
+		case models.AuditLoginSuccess:
+			s := spans[e.session]
+			s[0] = e.ts
+			spans[e.session] = s
+		case models.AuditSessionEnded:
+			s := spans[e.session]
+			s[1] = e.ts
+			spans[e.session] = s
+		}
+	}
+
+	if sessionOf[1] != sessionOf[2] {
+		t.Errorf("transactions 30s apart are in sessions %s and %s, expected one", sessionOf[1], sessionOf[2])
+	}
+	if sessionOf[3] == sessionOf[1] {
+		t.Error("transaction hours later joined the morning session")
+	}
+	if _, ok := spans[sessionOf[4]]; ok {
+		t.Error("branch transaction was placed in a login session")
+	}
+	if len(spans) != 2 {
+		t.Fatalf("got %d login sessions, expected 2", len(spans))
+	}
+	for _, txn := range txns[:3] {
+		span := spans[sessionOf[txn.ID]]
+		if ts := FormatTime(txn.Timestamp); ts < span[0] || ts > span[1] {
+			t.Errorf("transaction %d at %s is outside its session %s to %s", txn.ID, ts, span[0], span[1])
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package generator
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/willfong/load-generator/internal/data"
@@ -9,6 +11,10 @@ import (
 	"github.com/willfong/load-generator/internal/utils"
 )
 
+// TransactionAuditBasename is the shard basename for transaction audit events.
+// Shards are named audit_logs_txn_NNN so they load with the audit_logs table.
+const TransactionAuditBasename = "audit_logs_txn"
+
 // StreamingAuditGenerator generates audit logs and writes them directly
 // to a CSV file, minimizing memory usage for large datasets.
 type StreamingAuditGenerator struct {
@@ -19,30 +25,34 @@ type StreamingAuditGenerator struct {
 	// IP address pools for realistic distribution
 	ipPools map[string][]string
 
+	// Login session transactions are being placed in (nil = none open)
+	session *transactionSession
+
 	// Streaming output
 	writer   *CSVWriter
+	avro     *AvroWriter // Optional Avro shard (nil unless Avro is set)
 	workerID int
 
 	// Progress reporting
 	progressChan chan<- workerProgress
 	count        int64
 
-	// ID tracking
+	// ID tracking: the next ID, and the step to the one after (0 = 1)
 	currentID int64
-	endID     int64
+	idStride  int64
 }
 
 // StreamingAuditConfig holds settings for streaming audit log generation
 type StreamingAuditConfig struct {
-	// Reference data
-	Customers []GeneratedCustomer
-	Accounts  []GeneratedAccount
-	ATMs      []GeneratedATM
+	// Reference data (customers come from the generated partitions)
+	Accounts *AccountIndex // Shared read-only by every worker
+	ATMs     []GeneratedATM
 
 	// Error injection rates
 	FailedLoginRate    float64
 	LockedAccountRate  float64
 	SessionTimeoutRate float64
+	NewDeviceRate      float64 // Sessions from a device the customer hasn't used before
 
 	// Session parameters
 	AvgSessionsPerCustomerPerMonth int
@@ -55,12 +65,32 @@ type StreamingAuditConfig struct {
 	// Worker configuration
 	WorkerID    int
 	WorkerCount int
-	StartID     int64
-	EndID       int64
 
 	// Output configuration
-	OutputDir string
-	Compress  bool
+	OutputDir     string
+	Compress      bool
+	CompressLevel int           // xz preset and Avro deflate level with Compress
+	Filename      string        // Shard basename (default "audit_logs")
+	Limiter       *WriteLimiter // Optional write rate cap (nil = unlimited)
+	Avro          bool          // Also write an Avro OCF shard
+	MaxFileRows   int64         // Roll the shard over into part files of this many rows (0 = unlimited)
+	ExpectedRows  int64         // Rows the shard is expected to hold, at most, sizing S3 uploads (0 = unknown)
+	BufferSize    int           // Write buffer in bytes (0 = 1MB)
+	FlushInterval time.Duration // Flush the shard at least this often (0 = only when the buffer fills)
+
+	// TransactionAuditIDBase numbers transaction audit events from the
+	// transaction ID (base+2*id-1 and base+2*id), so they are unique across
+	// workers whenever transaction IDs are. With SessionTransactionRate set
+	// each transaction reserves six IDs instead. Only used by
+	// WriteTransactionAuditLogs.
+	TransactionAuditIDBase int64
+
+	// SessionTransactionRate is the fraction of online and ATM transactions
+	// made inside a login session, whose session_id their audit events share
+	SessionTransactionRate float64
+
+	// Audit actions written (nil = all)
+	Actions AuditActionFilter
 
 	// Progress channel
 	ProgressChan chan<- workerProgress
@@ -78,28 +108,55 @@ func AuditLogHeaders() []string {
 
 // NewStreamingAuditGenerator creates a new streaming audit generator
 func NewStreamingAuditGenerator(rng *utils.Random, refData *data.ReferenceData, config StreamingAuditConfig) (*StreamingAuditGenerator, error) {
+	filename := config.Filename
+	if filename == "" {
+		filename = "audit_logs"
+	}
+
 	// Create shard writer
 	writer, err := NewShardedCSVWriter(CSVWriterConfig{
-		OutputDir: config.OutputDir,
-		Filename:  "audit_logs",
-		Headers:   AuditLogHeaders(),
-		Compress:  config.Compress,
+		OutputDir:     config.OutputDir,
+		Filename:      filename,
+		Headers:       AuditLogHeaders(),
+		Compress:      config.Compress,
+		XZPreset:      config.CompressLevel,
+		Limiter:       config.Limiter,
+		MaxRows:       config.MaxFileRows,
+		ExpectedRows:  config.ExpectedRows,
+		BufferSize:    streamingBuffer(config.BufferSize),
+		FlushInterval: config.FlushInterval,
 	}, config.WorkerID+1, config.WorkerCount) // 1-indexed shard numbers
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create shard writer: %w", err)
 	}
 
+	var avro *AvroWriter
+	if config.Avro {
+		avro, err = NewAvroWriter(AvroWriterConfig{
+			OutputDir: config.OutputDir,
+			Filename:  ShardFilename(filename, config.WorkerID+1, config.WorkerCount),
+			Record:    auditLogAvro,
+			Deflate:   config.Compress,
+			Level:     config.CompressLevel,
+
+			ExpectedRows: config.ExpectedRows,
+		})
+		if err != nil {
+			writer.Close()
+			return nil, fmt.Errorf("failed to create avro writer: %w", err)
+		}
+	}
+
 	sag := &StreamingAuditGenerator{
 		rng:          rng,
 		refData:      refData,
 		config:       config,
 		ipPools:      make(map[string][]string),
 		writer:       writer,
+		avro:         avro,
 		workerID:     config.WorkerID,
 		progressChan: config.ProgressChan,
-		currentID:    config.StartID,
-		endID:        config.EndID,
 	}
 
 	sag.initializeIPPools()
@@ -118,7 +175,15 @@ func (g *StreamingAuditGenerator) initializeIPPools() {
 		"AF": {{41, 41}, {105, 105}, {154, 154}},
 	}
 
-	for region, prefixes := range regionPrefixes {
+	// Fill pools in a fixed region order so they are reproducible for a seed
+	regions := make([]string, 0, len(regionPrefixes))
+	for region := range regionPrefixes {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		prefixes := regionPrefixes[region]
 		ips := make([]string, 0, 100)
 		for i := 0; i < 100; i++ {
 			prefix := prefixes[g.rng.IntN(len(prefixes))]
@@ -132,16 +197,27 @@ func (g *StreamingAuditGenerator) initializeIPPools() {
 	}
 }
 
-// GenerateAndStream generates audit logs for the assigned customers and streams them to CSV.
+// GenerateAndStream generates audit logs for the customers of the assigned
+// partitions and streams them to CSV. Each partition draws from its own RNG
+// and numbers its logs from its FirstID in steps of its IDStride.
 // This generates session-based audit logs (logins, logouts, balance checks).
-// Transaction-based audit logs should be generated inline during transaction streaming.
-func (g *StreamingAuditGenerator) GenerateAndStream() (int64, error) {
-	defer g.writer.Close()
-
-	// Generate session audit logs for each customer
-	for _, customer := range g.config.Customers {
-		if err := g.generateCustomerSessionLogs(customer); err != nil {
-			return g.count, err
+// Transaction-based audit logs are written by the transaction workers through
+// WriteTransactionAuditLogs into separate audit_logs_txn shards.
+// The context is checked between customers so cancellation returns promptly.
+func (g *StreamingAuditGenerator) GenerateAndStream(ctx context.Context, partitions []GenerationPartition) (int64, error) {
+	defer g.Close()
+
+	for _, p := range partitions {
+		g.rng, g.currentID, g.idStride = p.RNG, p.FirstID, p.IDStride
+
+		// Generate session audit logs for each customer
+		for _, customer := range p.Customers {
+			if err := ctx.Err(); err != nil {
+				return g.count, err
+			}
+			if err := g.generateCustomerSessionLogs(customer); err != nil {
+				return g.count, err
+			}
 		}
 	}
 
@@ -150,20 +226,46 @@ func (g *StreamingAuditGenerator) GenerateAndStream() (int64, error) {
 
 // WriteTransactionAuditLogs writes audit logs for a transaction.
 // Call this from the transaction streaming generator for each transaction.
-func (g *StreamingAuditGenerator) WriteTransactionAuditLogs(txn models.Transaction, customer models.Customer) error {
+func (g *StreamingAuditGenerator) WriteTransactionAuditLogs(txn models.Transaction, customer GeneratedCustomer) error {
+	slots := g.transactionAuditSlots()
+	first := g.config.TransactionAuditIDBase + slots*(txn.ID-1) + 1
+
+	// Session events use the IDs after the two transaction events
+	session, err := g.transactionSession(txn, customer, first+2)
+	if err != nil {
+		return err
+	}
+	g.currentID = first
+
 	// Transaction initiated event
-	if err := g.writeTransactionInitiatedLog(txn, customer); err != nil {
+	if err := g.writeTransactionInitiatedLog(txn, customer, session); err != nil {
 		return err
 	}
 
 	// Transaction completed/failed/declined event
-	return g.writeTransactionCompletedLog(txn, customer)
+	if err := g.writeTransactionCompletedLog(txn, customer, session); err != nil {
+		return err
+	}
+	g.currentID = first + slots
+	return nil
 }
 
-func (g *StreamingAuditGenerator) writeTransactionInitiatedLog(t models.Transaction, c models.Customer) error {
+// transactionEventContext returns the session ID, IP address and user agent
+// for a transaction's audit events: the login session's if it was made in
+// one, otherwise a day-scoped session ID
+func (g *StreamingAuditGenerator) transactionEventContext(t models.Transaction, customer GeneratedCustomer, channel models.AuditChannel, session *transactionSession) (string, string, string) {
+	if session != nil {
+		return session.id, session.ip, session.userAgent
+	}
+	sessionID := fmt.Sprintf("SES%s%08d", t.Timestamp.Format("20060102"), customer.Customer.ID)
+	ipAddress, userAgent := g.getChannelContext(channel, customer, t.Timestamp)
+	return sessionID, ipAddress, userAgent
+}
+
+func (g *StreamingAuditGenerator) writeTransactionInitiatedLog(t models.Transaction, customer GeneratedCustomer, session *transactionSession) error {
+	c := customer.Customer
 	channel := channelToAuditChannel(t.Channel)
-	sessionID := fmt.Sprintf("SES%s%08d", t.Timestamp.Format("20060102"), c.ID)
-	ipAddress, userAgent := g.getChannelContext(channel, c)
+	sessionID, ipAddress, userAgent := g.transactionEventContext(t, customer, channel, session)
 
 	log := models.AuditLog{
 		ID:            g.currentID,
@@ -182,15 +284,15 @@ func (g *StreamingAuditGenerator) writeTransactionInitiatedLog(t models.Transact
 		SessionID:     sessionID,
 		RequestID:     fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 
 	return g.writeAuditLog(log)
 }
 
-func (g *StreamingAuditGenerator) writeTransactionCompletedLog(t models.Transaction, c models.Customer) error {
+func (g *StreamingAuditGenerator) writeTransactionCompletedLog(t models.Transaction, customer GeneratedCustomer, session *transactionSession) error {
+	c := customer.Customer
 	channel := channelToAuditChannel(t.Channel)
-	sessionID := fmt.Sprintf("SES%s%08d", t.Timestamp.Format("20060102"), c.ID)
-	ipAddress, userAgent := g.getChannelContext(channel, c)
+	sessionID, ipAddress, userAgent := g.transactionEventContext(t, customer, channel, session)
 
 	var action models.AuditAction
 	var outcome models.AuditOutcome
@@ -234,18 +336,27 @@ func (g *StreamingAuditGenerator) writeTransactionCompletedLog(t models.Transact
 		UserAgent:     userAgent,
 		AccountID:     &t.AccountID,
 		TransactionID: &t.ID,
-		Description:   fmt.Sprintf("Transaction %s: %s %s", outcome, t.Type, t.ReferenceNumber),
+		Description:   transactionOutcomeDescription(t, outcome),
 		FailureReason: failureReason,
 		SessionID:     sessionID,
 		RequestID:     fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 
 	return g.writeAuditLog(log)
 }
 
 func (g *StreamingAuditGenerator) generateCustomerSessionLogs(customer GeneratedCustomer) error {
-	months := int(g.config.EndDate.Sub(g.config.StartDate).Hours() / (24 * 30))
+	// Sessions stop once the customer is suspended or closed
+	endDate := g.config.EndDate
+	if changedAt := customer.StatusChangedAt; changedAt != nil && changedAt.Before(endDate) {
+		endDate = *changedAt
+	}
+	if !endDate.After(g.config.StartDate) {
+		return g.writeStatusChangeLogIfInRange(customer)
+	}
+
+	months := int(endDate.Sub(g.config.StartDate).Hours() / (24 * 30))
 	if months < 1 {
 		months = 1
 	}
@@ -261,7 +372,7 @@ func (g *StreamingAuditGenerator) generateCustomerSessionLogs(customer Generated
 	}
 
 	for i := 0; i < sessionCount; i++ {
-		duration := g.config.EndDate.Sub(g.config.StartDate)
+		duration := endDate.Sub(g.config.StartDate)
 		offset := time.Duration(g.rng.Float64() * float64(duration))
 		sessionTime := g.config.StartDate.Add(offset)
 
@@ -271,44 +382,80 @@ func (g *StreamingAuditGenerator) generateCustomerSessionLogs(customer Generated
 			sessionTime.Year(), sessionTime.Month(), sessionTime.Day(),
 			hour, minute, g.rng.IntRange(0, 59), 0, time.UTC,
 		)
+		if !customer.ActiveAt(sessionTime) {
+			continue
+		}
 
 		if err := g.generateSingleSession(customer, sessionTime); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	if moved := customer.Relocation; moved != nil && !moved.At.Before(g.config.StartDate) && moved.At.Before(endDate) {
+		log := relocationAuditLog(customer, g.currentID)
+		g.advanceID()
+		if err := g.writeAuditLog(log); err != nil {
+			return err
+		}
+	}
+	for _, log := range kycAuditLogs(customer, g.config.StartDate, endDate, g.nextID) {
+		if err := g.writeAuditLog(log); err != nil {
+			return err
+		}
+	}
+
+	return g.writeStatusChangeLogIfInRange(customer)
+}
+
+// writeStatusChangeLogIfInRange records the customer's suspension or closure
+// if it happened inside the generated history window
+func (g *StreamingAuditGenerator) writeStatusChangeLogIfInRange(customer GeneratedCustomer) error {
+	changedAt := customer.StatusChangedAt
+	if changedAt == nil || changedAt.Before(g.config.StartDate) || !changedAt.Before(g.config.EndDate) {
+		return nil
+	}
+
+	action, description := customerStatusChangeAction(customer.Customer.Status)
+	customerID := customer.Customer.ID
+	log := models.AuditLog{
+		ID:          g.currentID,
+		Timestamp:   *changedAt,
+		CustomerID:  &customerID,
+		SystemID:    "customer_lifecycle",
+		Action:      action,
+		Outcome:     models.OutcomeSuccess,
+		Channel:     models.AuditChannelSystem,
+		Description: description,
+		RequestID:   fmt.Sprintf("REQ%d", g.currentID),
+	}
+	g.advanceID()
+	return g.writeAuditLog(log)
 }
 
 func (g *StreamingAuditGenerator) generateSingleSession(customer GeneratedCustomer, sessionTime time.Time) error {
 	c := customer.Customer
 	customerID := c.ID
 
-	var channel models.AuditChannel
-	var atmID *int64
-
-	if g.rng.Probability(0.7) {
-		channel = models.AuditChannelOnline
-	} else if g.rng.Probability(0.2) {
-		channel = models.AuditChannelMobile
-	} else {
-		channel = models.AuditChannelATM
-		if len(g.config.ATMs) > 0 {
-			atm := g.config.ATMs[g.rng.IntN(len(g.config.ATMs))]
-			atmID = &atm.ATM.ID
-		}
-	}
+	channel, atmID := sessionChannel(g.rng, g.config.ATMs, customer, sessionTime)
 
-	ipAddress, userAgent := g.getChannelContext(channel, c)
+	// One IP and device for every event in the session
+	ipAddress, userAgent := g.getChannelContext(channel, customer, sessionTime)
 	sessionID := fmt.Sprintf("SES%s%08d%04d", sessionTime.Format("20060102150405"), customerID, g.rng.IntN(10000))
 
+	// Occasionally the customer signs in from an unrecognized device, which raises risk
+	var risk *float64
+	if userAgentsFor(channel) != nil && g.rng.Probability(g.config.NewDeviceRate) {
+		userAgent = unfamiliarUserAgent(g.rng, channel, customer)
+		risk = newDeviceRiskScore(g.rng)
+	}
+
 	isFailedLogin := g.rng.Probability(g.config.FailedLoginRate)
 
 	if isFailedLogin {
 		failedAttempts := g.rng.IntRange(1, 3)
 		for i := 0; i < failedAttempts; i++ {
 			attemptTime := sessionTime.Add(time.Duration(i*10) * time.Second)
-			if err := g.writeLoginFailedLog(customerID, attemptTime, channel, atmID, ipAddress, userAgent, sessionID); err != nil {
+			if err := g.writeLoginFailedLog(customerID, attemptTime, channel, atmID, ipAddress, userAgent, sessionID, risk); err != nil {
 				return err
 			}
 		}
@@ -323,7 +470,7 @@ func (g *StreamingAuditGenerator) generateSingleSession(customer GeneratedCustom
 	}
 
 	// Successful login
-	if err := g.writeLoginSuccessLog(customerID, sessionTime, channel, atmID, ipAddress, userAgent, sessionID); err != nil {
+	if err := g.writeLoginSuccessLog(customerID, sessionTime, channel, atmID, ipAddress, userAgent, sessionID, risk); err != nil {
 		return err
 	}
 
@@ -338,12 +485,7 @@ func (g *StreamingAuditGenerator) generateSingleSession(customer GeneratedCustom
 	}
 	numChecks := g.rng.IntRange(1, avgChecks*2)
 
-	var customerAccountIDs []int64
-	for _, acc := range g.config.Accounts {
-		if acc.Account.CustomerID == customerID {
-			customerAccountIDs = append(customerAccountIDs, acc.Account.ID)
-		}
-	}
+	customerAccountIDs := g.config.Accounts.customerAccountIDs(customerID)
 
 	for i := 0; i < numChecks && len(customerAccountIDs) > 0; i++ {
 		checkTime := sessionTime.Add(time.Duration(30+i*20) * time.Second)
@@ -368,6 +510,9 @@ func (g *StreamingAuditGenerator) generateSingleSession(customer GeneratedCustom
 }
 
 func (g *StreamingAuditGenerator) writeAuditLog(a models.AuditLog) error {
+	if !g.config.Actions.Allows(a.Action) {
+		return nil
+	}
 	row := []string{
 		FormatInt64(a.ID),
 		FormatTime(a.Timestamp),
@@ -395,6 +540,11 @@ func (g *StreamingAuditGenerator) writeAuditLog(a models.AuditLog) error {
 	if err := g.writer.WriteRow(row); err != nil {
 		return err
 	}
+	if g.avro != nil {
+		if err := g.avro.Write(a); err != nil {
+			return err
+		}
+	}
 
 	g.count++
 
@@ -408,7 +558,11 @@ func (g *StreamingAuditGenerator) writeAuditLog(a models.AuditLog) error {
 	return nil
 }
 
-func (g *StreamingAuditGenerator) writeLoginSuccessLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string) error {
+func (g *StreamingAuditGenerator) writeLoginSuccessLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string, risk *float64) error {
+	description := "User logged in successfully"
+	if risk != nil {
+		description = "User logged in from a new device"
+	}
 	log := models.AuditLog{
 		ID:          g.currentID,
 		Timestamp:   ts,
@@ -419,15 +573,16 @@ func (g *StreamingAuditGenerator) writeLoginSuccessLog(customerID int64, ts time
 		ATMID:       atmID,
 		IPAddress:   ip,
 		UserAgent:   ua,
-		Description: "User logged in successfully",
+		Description: description,
 		SessionID:   sessionID,
+		RiskScore:   risk,
 		RequestID:   fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 	return g.writeAuditLog(log)
 }
 
-func (g *StreamingAuditGenerator) writeLoginFailedLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string) error {
+func (g *StreamingAuditGenerator) writeLoginFailedLog(customerID int64, ts time.Time, channel models.AuditChannel, atmID *int64, ip, ua, sessionID string, risk *float64) error {
 	reasons := []string{"invalid_password", "invalid_pin", "expired_credentials", "user_not_found"}
 	reason := reasons[g.rng.IntN(len(reasons))]
 
@@ -444,9 +599,10 @@ func (g *StreamingAuditGenerator) writeLoginFailedLog(customerID int64, ts time.
 		Description:   "Login attempt failed",
 		FailureReason: reason,
 		SessionID:     sessionID,
+		RiskScore:     risk,
 		RequestID:     fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 	return g.writeAuditLog(log)
 }
 
@@ -466,7 +622,7 @@ func (g *StreamingAuditGenerator) writeAccountLockedLog(customerID int64, ts tim
 		SessionID:     sessionID,
 		RequestID:     fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 	return g.writeAuditLog(log)
 }
 
@@ -485,7 +641,7 @@ func (g *StreamingAuditGenerator) writeLogoutLog(customerID int64, ts time.Time,
 		SessionID:   sessionID,
 		RequestID:   fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 	return g.writeAuditLog(log)
 }
 
@@ -504,7 +660,7 @@ func (g *StreamingAuditGenerator) writeSessionStartedLog(customerID int64, ts ti
 		SessionID:   sessionID,
 		RequestID:   fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 	return g.writeAuditLog(log)
 }
 
@@ -523,7 +679,7 @@ func (g *StreamingAuditGenerator) writeSessionEndedLog(customerID int64, ts time
 		SessionID:   sessionID,
 		RequestID:   fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 	return g.writeAuditLog(log)
 }
 
@@ -543,7 +699,7 @@ func (g *StreamingAuditGenerator) writeSessionTimeoutLog(customerID int64, ts ti
 		SessionID:     sessionID,
 		RequestID:     fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 	return g.writeAuditLog(log)
 }
 
@@ -563,37 +719,25 @@ func (g *StreamingAuditGenerator) writeBalanceInquiryLog(customerID, accountID i
 		SessionID:   sessionID,
 		RequestID:   fmt.Sprintf("REQ%d", g.currentID),
 	}
-	g.currentID++
+	g.advanceID()
 	return g.writeAuditLog(log)
 }
 
-func (g *StreamingAuditGenerator) getChannelContext(channel models.AuditChannel, customer models.Customer) (string, string) {
+func (g *StreamingAuditGenerator) getChannelContext(channel models.AuditChannel, customer GeneratedCustomer, at time.Time) (string, string) {
 	switch channel {
 	case models.AuditChannelATM:
-		return "10.0.0." + fmt.Sprintf("%d", g.rng.IntRange(1, 254)), ""
+		return fmt.Sprintf("10.0.0.%d", 1+dailyPick(customer.Customer.ID, at, 1, 254)), ""
 	case models.AuditChannelBranch:
-		return "192.168." + fmt.Sprintf("%d.%d", g.rng.IntRange(1, 10), g.rng.IntRange(1, 254)), "BranchTellerSystem/3.2"
-	case models.AuditChannelMobile:
-		region := g.getCustomerRegion(customer)
-		ips := g.ipPools[region]
-		ip := ips[g.rng.IntN(len(ips))]
-		agents := []string{
-			"BankApp/5.2.1 (iOS 17.0; iPhone14,2)",
-			"BankApp/5.2.0 (Android 14; Pixel 8)",
-			"BankApp/5.1.9 (iOS 16.5; iPhone13,4)",
-			"BankApp/5.1.8 (Android 13; Samsung S23)",
-		}
-		return ip, agents[g.rng.IntN(len(agents))]
-	case models.AuditChannelOnline:
-		region := g.getCustomerRegion(customer)
+		return fmt.Sprintf("192.168.%d.%d", 1+dailyPick(customer.Customer.ID, at, 2, 10), 1+dailyPick(customer.Customer.ID, at, 3, 254)),
+			"BranchTellerSystem/3.2"
+	case models.AuditChannelMobile, models.AuditChannelOnline:
+		region := g.getCustomerRegion(customer.Customer)
 		ips := g.ipPools[region]
-		ip := ips[g.rng.IntN(len(ips))]
-		agents := []string{
-			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
-			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
-			"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
-			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 Safari/17.2",
+		ip := ips[dailyPick(customer.Customer.ID, at, 4, len(ips))]
+		if device, ok := customer.deviceFor(channel, at); ok {
+			return ip, device.UserAgent
 		}
+		agents := userAgentsFor(channel)
 		return ip, agents[g.rng.IntN(len(agents))]
 	default:
 		return "0.0.0.0", ""
@@ -617,6 +761,33 @@ func (g *StreamingAuditGenerator) getCustomerRegion(customer models.Customer) st
 	}
 }
 
+// advanceID moves on to the next audit log ID
+func (g *StreamingAuditGenerator) advanceID() {
+	g.currentID += max(g.idStride, 1)
+}
+
+// nextID returns the next audit log ID and advances past it
+func (g *StreamingAuditGenerator) nextID() int64 {
+	id := g.currentID
+	g.advanceID()
+	return id
+}
+
+// Close flushes and closes the shard writers. Only needed when the generator is
+// driven through WriteTransactionAuditLogs rather than GenerateAndStream.
+func (g *StreamingAuditGenerator) Close() error {
+	err := g.closeTransactionSession()
+	if closeErr := g.writer.Close(); err == nil {
+		err = closeErr
+	}
+	if g.avro != nil {
+		if avroErr := g.avro.Close(); err == nil {
+			err = avroErr
+		}
+	}
+	return err
+}
+
 // ShardFile returns the path to the shard file created by this generator
 func (g *StreamingAuditGenerator) ShardFile() string {
 	return g.writer.Path()
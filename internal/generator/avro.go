@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// AvroNamespace is the namespace of the record schemas derived from models
+const AvroNamespace = "com.willfong.loadgen"
+
+// Avro records for the streamed tables
+var (
+	transactionAvro = mustAvroRecord("Transaction", models.Transaction{})
+	auditLogAvro    = mustAvroRecord("AuditLog", models.AuditLog{})
+)
+
+// avroEncoder appends the Avro binary encoding of v to buf
+type avroEncoder func(buf []byte, v reflect.Value) []byte
+
+// AvroRecord is an Avro record schema derived from a models struct, along
+// with the binary encoder for values of that struct
+type AvroRecord struct {
+	typ      reflect.Type
+	schema   []byte
+	encoders []avroEncoder
+}
+
+// avroFieldSchema is one field of a record schema
+type avroFieldSchema struct {
+	Name    string          `json:"name"`
+	Type    any             `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+// NewAvroRecord derives a record schema from the json tags of sample's
+// struct type: int64 is long, float64 double, bool boolean, string types
+// string and time.Time a timestamp-micros long. Pointer fields become
+// ["null", T] unions defaulting to null.
+func NewAvroRecord(name string, sample any) (*AvroRecord, error) {
+	typ := reflect.TypeOf(sample)
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("avro record %s: %s is not a struct", name, typ)
+	}
+
+	r := &AvroRecord{typ: typ}
+	var fields []avroFieldSchema
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		field := avroFieldSchema{Name: tag}
+		var enc avroEncoder
+		var err error
+		if f.Type.Kind() == reflect.Pointer {
+			var elemType any
+			var elemEnc avroEncoder
+			elemType, elemEnc, err = avroType(f.Type.Elem())
+			field.Type = []any{"null", elemType}
+			field.Default = json.RawMessage("null")
+			enc = func(buf []byte, v reflect.Value) []byte {
+				if v.IsNil() {
+					return appendAvroLong(buf, 0)
+				}
+				return elemEnc(appendAvroLong(buf, 1), v.Elem())
+			}
+		} else {
+			field.Type, enc, err = avroType(f.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("avro record %s: field %s: %w", name, f.Name, err)
+		}
+
+		index := i
+		fields = append(fields, field)
+		r.encoders = append(r.encoders, func(buf []byte, v reflect.Value) []byte {
+			return enc(buf, v.Field(index))
+		})
+	}
+
+	schema, err := json.Marshal(map[string]any{
+		"type":      "record",
+		"name":      name,
+		"namespace": AvroNamespace,
+		"fields":    fields,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("avro record %s: %w", name, err)
+	}
+	r.schema = schema
+	return r, nil
+}
+
+func mustAvroRecord(name string, sample any) *AvroRecord {
+	r, err := NewAvroRecord(name, sample)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// avroType maps a non-pointer Go type to its Avro schema and encoder
+func avroType(t reflect.Type) (any, avroEncoder, error) {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]string{"type": "long", "logicalType": "timestamp-micros"},
+			func(buf []byte, v reflect.Value) []byte {
+				return appendAvroLong(buf, v.Interface().(time.Time).UnixMicro())
+			}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "long", func(buf []byte, v reflect.Value) []byte {
+			return appendAvroLong(buf, v.Int())
+		}, nil
+	case reflect.Float64:
+		return "double", func(buf []byte, v reflect.Value) []byte {
+			return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float()))
+		}, nil
+	case reflect.Bool:
+		return "boolean", func(buf []byte, v reflect.Value) []byte {
+			if v.Bool() {
+				return append(buf, 1)
+			}
+			return append(buf, 0)
+		}, nil
+	case reflect.String:
+		return "string", func(buf []byte, v reflect.Value) []byte {
+			return appendAvroString(buf, v.String())
+		}, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// Schema returns the record schema as JSON
+func (r *AvroRecord) Schema() []byte {
+	return r.schema
+}
+
+// Append appends the binary encoding of v, which must be the record's struct type
+func (r *AvroRecord) Append(buf []byte, v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Type() != r.typ {
+		return buf, fmt.Errorf("avro: cannot encode %s as %s", rv.Type(), r.typ)
+	}
+	for _, enc := range r.encoders {
+		buf = enc(buf, rv)
+	}
+	return buf, nil
+}
+
+// appendAvroLong appends n as a zigzag varint
+func appendAvroLong(buf []byte, n int64) []byte {
+	return binary.AppendUvarint(buf, uint64((n<<1)^(n>>63)))
+}
+
+// appendAvroString appends a length-prefixed string (or bytes)
+func appendAvroString(buf []byte, s string) []byte {
+	return append(appendAvroLong(buf, int64(len(s))), s...)
+}
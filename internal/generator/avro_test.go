@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// avroReader decodes just enough of the Avro binary encoding to check the writer
+type avroReader struct {
+	t   *testing.T
+	buf *bytes.Reader
+}
+
+func (r avroReader) long() int64 {
+	u, err := binary.ReadUvarint(r.buf)
+	if err != nil {
+		r.t.Fatalf("Failed to read long: %v", err)
+	}
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r avroReader) bytes() []byte {
+	b := make([]byte, r.long())
+	if _, err := io.ReadFull(r.buf, b); err != nil {
+		r.t.Fatalf("Failed to read bytes: %v", err)
+	}
+	return b
+}
+
+// value decodes one value of the given field type from the schema JSON
+func (r avroReader) value(typ any) any {
+	switch typ := typ.(type) {
+	case string:
+		switch typ {
+		case "long":
+			return r.long()
+		case "string":
+			return string(r.bytes())
+		case "double":
+			var b [8]byte
+			io.ReadFull(r.buf, b[:])
+			return math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+		case "boolean":
+			b, _ := r.buf.ReadByte()
+			return b == 1
+		}
+	case []any: // ["null", T]
+		if r.long() == 0 {
+			return nil
+		}
+		return r.value(typ[1])
+	case map[string]any: // timestamp-micros
+		return time.UnixMicro(r.long()).UTC()
+	}
+	r.t.Fatalf("Unexpected schema type %v", typ)
+	return nil
+}
+
+// readAvroFile decodes an OCF file into one map per record
+func readAvroFile(t *testing.T, path string) (codec string, records []map[string]any) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, avroMagic) {
+		t.Fatalf("Missing Avro magic: %q", data[:4])
+	}
+	r := avroReader{t, bytes.NewReader(data[4:])}
+
+	meta := map[string]string{}
+	for n := r.long(); n != 0; n = r.long() {
+		for ; n > 0; n-- {
+			key := string(r.bytes())
+			meta[key] = string(r.bytes())
+		}
+	}
+	var schema struct {
+		Fields []struct {
+			Name string `json:"name"`
+			Type any    `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(meta["avro.schema"]), &schema); err != nil {
+		t.Fatalf("Invalid schema: %v", err)
+	}
+	sync := make([]byte, 16)
+	io.ReadFull(r.buf, sync)
+
+	for r.buf.Len() > 0 {
+		count := r.long()
+		block := r.bytes()
+		if meta["avro.codec"] == "deflate" {
+			if block, err = io.ReadAll(flate.NewReader(bytes.NewReader(block))); err != nil {
+				t.Fatalf("Failed to inflate block: %v", err)
+			}
+		}
+		br := avroReader{t, bytes.NewReader(block)}
+		for ; count > 0; count-- {
+			rec := map[string]any{}
+			for _, f := range schema.Fields {
+				rec[f.Name] = br.value(f.Type)
+			}
+			records = append(records, rec)
+		}
+		marker := make([]byte, 16)
+		io.ReadFull(r.buf, marker)
+		if !bytes.Equal(marker, sync) {
+			t.Fatalf("Block sync marker mismatch")
+		}
+	}
+	return meta["avro.codec"], records
+}
+
+func TestAvroWriterRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 6, 1, 12, 30, 0, 123000, time.UTC)
+	counterparty := int64(42)
+
+	for _, tc := range []struct {
+		deflate bool
+		level   int
+	}{{false, 0}, {true, 0}, {true, 1}, {true, 9}} {
+		dir := t.TempDir()
+		w, err := NewAvroWriter(AvroWriterConfig{OutputDir: dir, Filename: "transactions_001", Record: transactionAvro, Deflate: tc.deflate, Level: tc.level})
+		if err != nil {
+			t.Fatalf("NewAvroWriter: %v", err)
+		}
+		for i := int64(1); i <= 3; i++ {
+			txn := models.Transaction{
+				ID:              i,
+				ReferenceNumber: "TXN-1",
+				AccountID:       7,
+				Type:            models.TransactionType("card_purchase"),
+				Amount:          -i * 1050,
+				Timestamp:       ts,
+			}
+			if i == 2 {
+				txn.CounterpartyAccountID = &counterparty
+			}
+			if err := w.Write(txn); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+		}
+		if err := w.Write(models.AuditLog{}); err == nil {
+			t.Error("Expected writing an audit log to a transaction file to fail")
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+
+		codec, records := readAvroFile(t, filepath.Join(dir, "transactions_001.avro"))
+		if want := map[bool]string{false: "null", true: "deflate"}[tc.deflate]; codec != want {
+			t.Errorf("Expected codec %s, got %s", want, codec)
+		}
+		if len(records) != 3 || w.RowCount() != 3 {
+			t.Fatalf("Expected 3 records, read %d (RowCount %d)", len(records), w.RowCount())
+		}
+		rec := records[1]
+		if rec["id"] != int64(2) || rec["amount"] != int64(-2100) || rec["type"] != "card_purchase" {
+			t.Errorf("Unexpected record %v", rec)
+		}
+		if rec["counterparty_account_id"] != int64(42) || records[0]["counterparty_account_id"] != nil {
+			t.Errorf("Expected nullable counterparty to round-trip, got %v and %v", rec["counterparty_account_id"], records[0]["counterparty_account_id"])
+		}
+		if got := rec["timestamp"]; got != ts {
+			t.Errorf("Expected timestamp %v, got %v", ts, got)
+		}
+	}
+}
+
+func TestAvroSchemaMatchesModels(t *testing.T) {
+	var schema struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		Fields    []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(auditLogAvro.Schema(), &schema); err != nil {
+		t.Fatalf("Invalid schema: %v", err)
+	}
+	if schema.Name != "AuditLog" || schema.Namespace != AvroNamespace {
+		t.Errorf("Unexpected record name %s.%s", schema.Namespace, schema.Name)
+	}
+	// Every CSV column has a field of the same name, in the same order
+	headers := AuditLogHeaders()
+	if len(schema.Fields) < len(headers) {
+		t.Fatalf("Expected at least %d fields, got %d", len(headers), len(schema.Fields))
+	}
+	for i, h := range headers {
+		if schema.Fields[i].Name != h {
+			t.Errorf("Field %d: expected %s, got %s", i, h, schema.Fields[i].Name)
+		}
+	}
+}
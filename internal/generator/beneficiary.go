@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,8 +22,14 @@ type BeneficiaryGenerator struct {
 type BeneficiaryGeneratorConfig struct {
 	// Average beneficiaries per customer
 	AvgBeneficiariesPerCustomer int
+	// Fewest beneficiaries per customer (0 lets customers have none)
+	MinBeneficiaries int
+	// Most beneficiaries per customer (0 = twice the average)
+	MaxBeneficiaries int
 	// Businesses to use as internal beneficiaries
 	Businesses []GeneratedBusiness
+	// BaseDate stands in for the current time (zero = now)
+	BaseDate time.Time
 }
 
 // NewBeneficiaryGenerator creates a new beneficiary generator
@@ -30,6 +37,13 @@ func NewBeneficiaryGenerator(rng *utils.Random, refData *data.ReferenceData, con
 	if config.AvgBeneficiariesPerCustomer <= 0 {
 		config.AvgBeneficiariesPerCustomer = 5
 	}
+	if config.MaxBeneficiaries <= 0 {
+		config.MaxBeneficiaries = 2 * config.AvgBeneficiariesPerCustomer
+	}
+	config.MinBeneficiaries = min(max(config.MinBeneficiaries, 0), config.MaxBeneficiaries)
+	if config.BaseDate.IsZero() {
+		config.BaseDate = time.Now()
+	}
 	return &BeneficiaryGenerator{
 		rng:     rng,
 		refData: refData,
@@ -57,11 +71,7 @@ func (g *BeneficiaryGenerator) GenerateBeneficiariesForCustomers(customers []Gen
 
 // generateBeneficiariesForCustomer creates 0-10 beneficiaries for a customer
 func (g *BeneficiaryGenerator) generateBeneficiariesForCustomer(customer GeneratedCustomer, currentID *int64) []GeneratedBeneficiary {
-	// Vary count based on activity score
-	// Active customers have more beneficiaries
-	baseCount := g.config.AvgBeneficiariesPerCustomer
-	countVariation := int(float64(baseCount) * customer.Customer.ActivityScore)
-	numBeneficiaries := g.rng.IntRange(1, baseCount+countVariation)
+	numBeneficiaries := g.beneficiaryCount(customer)
 
 	beneficiaries := make([]GeneratedBeneficiary, 0, numBeneficiaries)
 
@@ -75,6 +85,16 @@ func (g *BeneficiaryGenerator) generateBeneficiariesForCustomer(customer Generat
 	return beneficiaries
 }
 
+// beneficiaryCount draws uniformly between MinBeneficiaries and the average
+// scaled up by the customer's activity score, capped at MaxBeneficiaries, so
+// active customers have more beneficiaries
+func (g *BeneficiaryGenerator) beneficiaryCount(customer GeneratedCustomer) int {
+	baseCount := g.config.AvgBeneficiariesPerCustomer
+	countVariation := int(float64(baseCount) * customer.Customer.ActivityScore)
+	upper := min(max(baseCount+countVariation, g.config.MinBeneficiaries), g.config.MaxBeneficiaries)
+	return g.rng.IntRange(g.config.MinBeneficiaries, upper)
+}
+
 // generateBeneficiary creates a single beneficiary
 func (g *BeneficiaryGenerator) generateBeneficiary(id int64, customer GeneratedCustomer) GeneratedBeneficiary {
 	// Pick beneficiary type with distribution
@@ -159,7 +179,7 @@ func (g *BeneficiaryGenerator) generateInternalBeneficiary(id int64, customer Ge
 		AccountReference: g.rng.NumericString(10),
 		TransferCount:    g.rng.IntRange(0, 50),
 		CreatedAt:        createdAt,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        g.config.BaseDate,
 	}
 }
 
@@ -180,8 +200,6 @@ func (g *BeneficiaryGenerator) generateExternalBeneficiary(id int64, customer Ge
 		name, nickname = g.generateIndividualName(customer.Country.Region)
 	}
 
-	// External bank details
-	bankName, bankCode := g.generateExternalBankDetails(customer.Country)
 	accountNumber := g.generateExternalAccountNumber()
 
 	// Address (same country as customer 70% of the time)
@@ -193,6 +211,9 @@ func (g *BeneficiaryGenerator) generateExternalBeneficiary(id int64, customer Ge
 	}
 	city := g.pickCity(country.Code)
 
+	// External bank details, at a bank in the payee's country
+	bankName, bankCode := g.generateExternalBankDetails(country)
+
 	// Payment method
 	paymentMethod := g.pickPaymentMethod(customer.Country.Code, country.Code)
 
@@ -226,15 +247,15 @@ func (g *BeneficiaryGenerator) generateExternalBeneficiary(id int64, customer Ge
 		AccountReference: g.rng.NumericString(10),
 		TransferCount:    g.rng.IntRange(0, 30),
 		CreatedAt:        createdAt,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        g.config.BaseDate,
 	}
 }
 
 // generateIndividualName creates a person's name for beneficiary
 func (g *BeneficiaryGenerator) generateIndividualName(region string) (name, nickname string) {
-	// Use western names for simplicity
-	firstNames := g.refData.GetFirstNames("western", g.rng.Bool())
-	lastNames := g.refData.GetLastNames("western")
+	// Payees usually share the customer's region
+	firstNames := g.refData.FirstNamesForRegion(region, g.rng.Bool())
+	lastNames := g.refData.LastNamesForRegion(region)
 
 	firstName := "John"
 	lastName := "Doe"
@@ -303,25 +324,10 @@ func (g *BeneficiaryGenerator) generateGovernmentName(country *data.Country) (na
 	return
 }
 
-// generateExternalBankDetails creates external bank info
+// generateExternalBankDetails picks a bank in the country and its SWIFT/BIC code
 func (g *BeneficiaryGenerator) generateExternalBankDetails(country *data.Country) (bankName, bankCode string) {
-	banks := []struct {
-		name string
-		code string
-	}{
-		{"Chase Bank", "CHASUS33"},
-		{"Bank of America", "BOFAUS3N"},
-		{"Wells Fargo", "WFBIUS6S"},
-		{"Citibank", "CITIUS33"},
-		{"HSBC", "HSBCGB2L"},
-		{"Barclays", "BARCGB22"},
-		{"Deutsche Bank", "DEUTDEFF"},
-		{"BNP Paribas", "BNPAFRPP"},
-		{"Santander", "BSCHESMM"},
-		{"ING", "INGBNL2A"},
-	}
-	bank := banks[g.rng.IntN(len(banks))]
-	return bank.name, bank.code
+	bank := bankForCountry(g.rng, country)
+	return bank.name, bank.bic
 }
 
 // generateExternalAccountNumber creates an external account number
@@ -332,28 +338,89 @@ func (g *BeneficiaryGenerator) generateExternalAccountNumber() string {
 // generateRoutingNumber creates a routing number for US banks
 func (g *BeneficiaryGenerator) generateRoutingNumber(countryCode string) string {
 	if countryCode == "US" {
-		return g.rng.NumericString(9)
+		// Federal Reserve district prefix (01-12), then institution digits
+		prefix := fmt.Sprintf("%02d", g.rng.IntRange(1, 12)) + g.rng.NumericString(6)
+		return prefix + abaCheckDigit(prefix)
 	}
 	return ""
 }
 
-// generateIBAN creates an IBAN for European countries
+// abaCheckDigit returns the ninth digit of an ABA routing number: the one that
+// makes 3*(d1+d4+d7) + 7*(d2+d5+d8) + (d3+d6+d9) a multiple of 10
+func abaCheckDigit(prefix string) string {
+	weights := [8]int{3, 7, 1, 3, 7, 1, 3, 7}
+	sum := 0
+	for i, w := range weights {
+		sum += int(prefix[i]-'0') * w
+	}
+	return FormatInt((10 - sum%10) % 10)
+}
+
+// ibanFormats is each IBAN country's BBAN layout from the SWIFT IBAN
+// registry: runs of n digits, a upper-case letters or c letters and digits,
+// so generated IBANs have the country's length (GB 22, FR 27, NO 15, ...)
+var ibanFormats = map[string]string{
+	"AT": "16!n",
+	"BE": "12!n",
+	"CH": "5!n12!c",
+	"CZ": "20!n",
+	"DE": "18!n",
+	"DK": "14!n",
+	"ES": "20!n",
+	"FI": "14!n",
+	"FR": "10!n11!c2!n",
+	"GB": "4!a14!n",
+	"GR": "7!n16!c",
+	"IE": "4!a14!n",
+	"IT": "1!a10!n12!c",
+	"NL": "4!a10!n",
+	"NO": "11!n",
+	"PL": "24!n",
+	"PT": "21!n",
+	"SE": "20!n",
+}
+
+// generateIBAN creates an IBAN in the country's format
 func (g *BeneficiaryGenerator) generateIBAN(countryCode string) string {
-	checkDigits := g.rng.NumericString(2)
-	bankCode := g.rng.NumericString(4)
-	accountNum := g.rng.NumericString(14)
-	return fmt.Sprintf("%s%s%s%s", countryCode, checkDigits, bankCode, accountNum)
+	var bban strings.Builder
+	format := ibanFormats[countryCode]
+	for format != "" {
+		count, rest, _ := strings.Cut(format, "!")
+		n, _ := strconv.Atoi(count)
+		kind := rest[0]
+		format = rest[1:]
+		for range n {
+			switch {
+			case kind == 'n', kind == 'c' && g.rng.IntN(36) < 10:
+				bban.WriteByte(g.rng.Digit())
+			default:
+				bban.WriteByte(g.rng.Letter())
+			}
+		}
+	}
+	return countryCode + ibanCheckDigits(countryCode, bban.String()) + bban.String()
+}
+
+// ibanCheckDigits computes the ISO 13616 mod-97 check digits for an IBAN:
+// the BBAN followed by the country code and "00", with letters as 10-35,
+// taken mod 97 and subtracted from 98
+func ibanCheckDigits(countryCode, bban string) string {
+	mod := 0
+	for _, c := range bban + countryCode + "00" {
+		switch {
+		case c >= '0' && c <= '9':
+			mod = (mod*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			mod = (mod*100 + int(c-'A') + 10) % 97
+		}
+	}
+	return fmt.Sprintf("%02d", 98-mod)
 }
 
 // isEuropean checks if country uses IBAN
 func (g *BeneficiaryGenerator) isEuropean(countryCode string) bool {
-	european := map[string]bool{
-		"GB": true, "DE": true, "FR": true, "ES": true, "IT": true,
-		"NL": true, "BE": true, "CH": true, "AT": true, "SE": true,
-		"NO": true, "DK": true, "FI": true, "IE": true, "PT": true,
-		"PL": true, "CZ": true, "GR": true,
-	}
-	return european[countryCode]
+	_, ok := ibanFormats[countryCode]
+	return ok
 }
 
 // pickPaymentMethod determines payment method based on countries
@@ -448,18 +515,9 @@ func (g *BeneficiaryGenerator) generateCreatedAt(customerCreatedAt time.Time) ti
 	return customerCreatedAt.Add(time.Duration(daysAfter) * 24 * time.Hour)
 }
 
-// WriteBeneficiariesCSV writes beneficiaries to a CSV file (or .csv.xz if compress=true)
-func WriteBeneficiariesCSV(beneficiaries []GeneratedBeneficiary, outputDir string, compress bool) error {
-	return writeBeneficiariesCSVInternal(beneficiaries, outputDir, compress, false)
-}
-
-// WriteBeneficiariesCSVWithProgress writes beneficiaries with progress reporting
-func WriteBeneficiariesCSVWithProgress(beneficiaries []GeneratedBeneficiary, outputDir string, compress bool) error {
-	return writeBeneficiariesCSVInternal(beneficiaries, outputDir, compress, true)
-}
-
-func writeBeneficiariesCSVInternal(beneficiaries []GeneratedBeneficiary, outputDir string, compress, showProgress bool) error {
-	headers := []string{
+// BeneficiaryHeaders returns the CSV headers for beneficiaries
+func BeneficiaryHeaders() []string {
+	return []string{
 		"id", "customer_id", "nickname", "name", "type", "status",
 		"bank_name", "bank_code", "routing_number", "account_number", "iban",
 		"address_line1", "address_line2", "city", "state", "postal_code", "country",
@@ -467,13 +525,24 @@ func writeBeneficiariesCSVInternal(beneficiaries []GeneratedBeneficiary, outputD
 		"last_used_at", "transfer_count",
 		"created_at", "updated_at",
 	}
+}
+
+// WriteBeneficiariesCSV writes beneficiaries to a CSV file in cfg.OutputDir (or .csv.xz at preset cfg.XZPreset if cfg.Compress),
+// split into that many shard files if shards > 1
+func WriteBeneficiariesCSV(beneficiaries []GeneratedBeneficiary, cfg CSVWriterConfig, shards int) error {
+	return writeBeneficiariesCSVInternal(beneficiaries, cfg, shards, false)
+}
+
+// WriteBeneficiariesCSVWithProgress writes beneficiaries with progress reporting
+func WriteBeneficiariesCSVWithProgress(beneficiaries []GeneratedBeneficiary, cfg CSVWriterConfig, shards int) error {
+	return writeBeneficiariesCSVInternal(beneficiaries, cfg, shards, true)
+}
+
+func writeBeneficiariesCSVInternal(beneficiaries []GeneratedBeneficiary, cfg CSVWriterConfig, shards int, showProgress bool) error {
+	headers := BeneficiaryHeaders()
 
-	writer, err := NewCSVWriter(CSVWriterConfig{
-		OutputDir: outputDir,
-		Filename:  "beneficiaries",
-		Headers:   headers,
-		Compress:  compress,
-	})
+	cfg.Filename, cfg.Headers = "beneficiaries", headers
+	writer, err := newTableWriter(cfg, shards, len(beneficiaries))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// validIBAN checks an IBAN the way a receiving system would: move the first
+// four characters to the end, convert letters to numbers and expect mod 97 == 1
+func validIBAN(iban string) bool {
+	if len(iban) < 5 {
+		return false
+	}
+	var digits strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+		case c >= 'A' && c <= 'Z':
+			digits.WriteString(FormatInt(int(c-'A') + 10))
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func TestIBANCheckDigits(t *testing.T) {
+	// Published example IBANs
+	for _, iban := range []string{"GB82WEST12345698765432", "DE89370400440532013000", "FR1420041010050500013M02606"} {
+		if got := ibanCheckDigits(iban[:2], iban[4:]); got != iban[2:4] {
+			t.Errorf("%s: check digits %s, expected %s", iban, got, iban[2:4])
+		}
+	}
+
+	// IBAN lengths from the SWIFT IBAN registry
+	lengths := map[string]int{
+		"AT": 20, "BE": 16, "CH": 21, "CZ": 24, "DE": 22, "DK": 18, "ES": 24, "FI": 18, "FR": 27,
+		"GB": 22, "GR": 27, "IE": 22, "IT": 27, "NL": 18, "NO": 15, "PL": 28, "PT": 25, "SE": 24,
+	}
+	if len(ibanFormats) != len(lengths) {
+		t.Errorf("%d IBAN formats, expected %d", len(ibanFormats), len(lengths))
+	}
+	gen := &BeneficiaryGenerator{rng: utils.NewRandom(42)}
+	for country, length := range lengths {
+		for i := 0; i < 100; i++ {
+			iban := gen.generateIBAN(country)
+			if !validIBAN(iban) || !strings.HasPrefix(iban, country) {
+				t.Fatalf("generated invalid IBAN %s", iban)
+			}
+			if len(iban) != length {
+				t.Fatalf("generated %s IBAN %s of length %d, expected %d", country, iban, len(iban), length)
+			}
+		}
+	}
+
+	// Bank codes that are letters stay letters
+	for i := 0; i < 100; i++ {
+		if iban := gen.generateIBAN("NL"); strings.ContainsAny(iban[4:8], "0123456789") {
+			t.Fatalf("NL IBAN %s has digits in its bank code", iban)
+		}
+	}
+}
+
+// validRoutingNumber applies the ABA checksum with weights 3, 7, 1
+func validRoutingNumber(rn string) bool {
+	if len(rn) != 9 {
+		return false
+	}
+	sum := 0
+	for i, c := range rn {
+		if c < '0' || c > '9' {
+			return false
+		}
+		sum += int(c-'0') * [3]int{3, 7, 1}[i%3]
+	}
+	return sum%10 == 0
+}
+
+func TestRoutingNumberChecksum(t *testing.T) {
+	// A published routing number
+	if got := abaCheckDigit("02100002"); got != "1" {
+		t.Errorf("check digit for 02100002 is %s, expected 1", got)
+	}
+
+	gen := &BeneficiaryGenerator{rng: utils.NewRandom(42)}
+	for i := 0; i < 1000; i++ {
+		if rn := gen.generateRoutingNumber("US"); !validRoutingNumber(rn) {
+			t.Fatalf("generated invalid routing number %s", rn)
+		}
+	}
+	if rn := gen.generateRoutingNumber("GB"); rn != "" {
+		t.Errorf("expected no routing number outside the US, got %s", rn)
+	}
+}
+
+func TestBeneficiaryCountBounds(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		min, max int
+		wantMin  int
+		wantMax  int
+	}{
+		{"defaults", 0, 0, 0, 10},
+		{"at least one", 1, 10, 1, 10},
+		{"capped", 0, 3, 0, 3},
+		{"exact", 4, 4, 4, 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gen := NewBeneficiaryGenerator(utils.NewRandom(42), nil, BeneficiaryGeneratorConfig{
+				MinBeneficiaries: tc.min,
+				MaxBeneficiaries: tc.max,
+			})
+			lo, hi := tc.wantMax, tc.wantMin
+			for _, score := range []float64{0.1, 0.5, 1.0} {
+				customer := GeneratedCustomer{Customer: models.Customer{ActivityScore: score}}
+				for i := 0; i < 1000; i++ {
+					n := gen.beneficiaryCount(customer)
+					lo, hi = min(lo, n), max(hi, n)
+				}
+			}
+			if lo != tc.wantMin || hi != tc.wantMax {
+				t.Errorf("counts ranged %d-%d, expected %d-%d", lo, hi, tc.wantMin, tc.wantMax)
+			}
+		})
+	}
+}
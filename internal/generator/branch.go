@@ -2,6 +2,8 @@ package generator
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/willfong/load-generator/internal/data"
@@ -24,10 +26,21 @@ type BranchGeneratorConfig struct {
 	BaseDate time.Time
 	// YearsBack is how many years of history (branches opened throughout this period)
 	YearsBack int
+
+	// ClosedBranchRate is the fraction of branches closed before BaseDate (0 = none)
+	ClosedBranchRate float64
+	// OfflineATMRate and MaintenanceATMRate are the fractions of ATMs taken out
+	// of service shortly before BaseDate (0 = none). ATMs at closed branches
+	// go offline when the branch closes.
+	OfflineATMRate     float64
+	MaintenanceATMRate float64
 }
 
 // NewBranchGenerator creates a new branch generator
 func NewBranchGenerator(rng *utils.Random, refData *data.ReferenceData, config BranchGeneratorConfig) *BranchGenerator {
+	if config.BaseDate.IsZero() {
+		config.BaseDate = time.Now()
+	}
 	return &BranchGenerator{
 		rng:     rng,
 		refData: refData,
@@ -41,10 +54,164 @@ type GeneratedBranch struct {
 	Country *data.Country
 }
 
+// OpenAt reports whether the branch is serving customers at t
+func (b GeneratedBranch) OpenAt(t time.Time) bool {
+	return b.Branch.ClosedAt == nil || t.Before(*b.Branch.ClosedAt)
+}
+
+// hoursOn returns the branch's operating hours on a weekday
+func (b GeneratedBranch) hoursOn(day time.Weekday) string {
+	switch day {
+	case time.Monday:
+		return b.Branch.MondayHours
+	case time.Tuesday:
+		return b.Branch.TuesdayHours
+	case time.Wednesday:
+		return b.Branch.WednesdayHours
+	case time.Thursday:
+		return b.Branch.ThursdayHours
+	case time.Friday:
+		return b.Branch.FridayHours
+	case time.Saturday:
+		return b.Branch.SaturdayHours
+	default:
+		return b.Branch.SundayHours
+	}
+}
+
+// HoursOn returns when the branch opens and closes on the day t falls on in
+// the branch's local time, or ok = false if it is closed all that day
+func (b GeneratedBranch) HoursOn(t time.Time) (opens, closes time.Time, ok bool) {
+	local := t.In(zone(b.Branch.Timezone))
+	from, to, ok := parseOperatingHours(b.hoursOn(local.Weekday()))
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	return day.Add(from), day.Add(to), true
+}
+
+// InHoursAt reports whether t is within the branch's operating hours, in the
+// branch's local time. Regional weekends (closed Fridays in the Middle East)
+// are carried by the per-day hours.
+func (b GeneratedBranch) InHoursAt(t time.Time) bool {
+	opens, closes, ok := b.HoursOn(t)
+	return ok && !t.Before(opens) && t.Before(closes)
+}
+
+// parseOperatingHours parses a day's operating hours such as "09:00-17:00"
+// into opening and closing times of day. ok is false when the branch is
+// closed that day ("" or "Closed") or the hours can't be parsed.
+func parseOperatingHours(hours string) (opens, closes time.Duration, ok bool) {
+	from, to, found := strings.Cut(strings.TrimSpace(hours), "-")
+	if !found {
+		return 0, 0, false
+	}
+	opens, okOpens := parseClock(from)
+	closes, okCloses := parseClock(to)
+	if !okOpens || !okCloses || closes <= opens {
+		return 0, 0, false
+	}
+	return opens, closes, true
+}
+
+// parseClock parses an "HH:MM" time of day, allowing "24:00" for midnight at the end of the day
+func parseClock(clock string) (time.Duration, bool) {
+	var hour, minute int
+	if n, err := fmt.Sscanf(strings.TrimSpace(clock), "%d:%d", &hour, &minute); err != nil || n != 2 {
+		return 0, false
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, false
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+// zones caches loaded time zones by name; branch hours are checked for every
+// branch transaction
+var zones sync.Map // string -> *time.Location
+
+// zone returns the named time zone, or UTC if it can't be loaded
+func zone(name string) *time.Location {
+	if loc, ok := zones.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = time.UTC
+	}
+	zones.Store(name, loc)
+	return loc
+}
+
 // GeneratedATM holds a generated ATM with its country info
 type GeneratedATM struct {
 	ATM     models.ATM
 	Country *data.Country
+	// OutOfServiceSince is when an offline or maintenance ATM went down (nil = online)
+	OutOfServiceSince *time.Time
+}
+
+// InServiceAt reports whether the ATM is serving customers at t
+func (a GeneratedATM) InServiceAt(t time.Time) bool {
+	return a.OutOfServiceSince == nil || t.Before(*a.OutOfServiceSince)
+}
+
+// locationPicks bounds the random draws made looking for an open branch or ATM
+const locationPicks = 8
+
+// branchByID returns the branch with the given ID, or nil if there is none.
+// Branch IDs are assigned in order from 1, so the lookup is usually direct.
+func branchByID(branches []GeneratedBranch, id int64) *GeneratedBranch {
+	if i := int(id - 1); i >= 0 && i < len(branches) && branches[i].Branch.ID == id {
+		return &branches[i]
+	}
+	for i := range branches {
+		if branches[i].Branch.ID == id {
+			return &branches[i]
+		}
+	}
+	return nil
+}
+
+// atmByID returns the ATM with the given ID, or nil if it is not in atms
+func atmByID(atms []GeneratedATM, id int64) *GeneratedATM {
+	if i := int(id - 1); i >= 0 && i < len(atms) && atms[i].ATM.ID == id {
+		return &atms[i]
+	}
+	for i := range atms {
+		if atms[i].ATM.ID == id {
+			return &atms[i]
+		}
+	}
+	return nil
+}
+
+// pickOpenBranch picks a random branch open at t, or nil if none was found.
+// With inHours the branch must also be within its operating hours at t.
+func pickOpenBranch(rng *utils.Random, branches []GeneratedBranch, t time.Time, inHours bool) *GeneratedBranch {
+	if len(branches) == 0 {
+		return nil
+	}
+	for i := 0; i < locationPicks; i++ {
+		if b := &branches[rng.IntN(len(branches))]; b.OpenAt(t) && (!inHours || b.InHoursAt(t)) {
+			return b
+		}
+	}
+	return nil
+}
+
+// pickATMInService picks a random ATM in service at t, or nil if none was found
+func pickATMInService(rng *utils.Random, atms []GeneratedATM, t time.Time) *GeneratedATM {
+	if len(atms) == 0 {
+		return nil
+	}
+	for i := 0; i < locationPicks; i++ {
+		if a := &atms[rng.IntN(len(atms))]; a.InServiceAt(t) {
+			return a
+		}
+	}
+	return nil
 }
 
 // GenerateBranches creates all branches with global distribution
@@ -119,6 +286,9 @@ func (g *BranchGenerator) generateBranch(id int64, countryBranchCount map[string
 	// Generate operating hours based on country/region
 	hours := g.generateOperatingHours(country)
 
+	// Place the branch in its city
+	latitude, longitude := jitterCoordinates(g.rng, city.Latitude, city.Longitude, locationJitter)
+
 	branch := models.Branch{
 		ID:               id,
 		BranchCode:       branchCode,
@@ -130,8 +300,8 @@ func (g *BranchGenerator) generateBranch(id int64, countryBranchCount map[string
 		State:            city.State,
 		PostalCode:       g.generatePostalCode(country.Code, city.PostalPrefix),
 		Country:          country.Code,
-		Latitude:         g.generateLatitude(),
-		Longitude:        g.generateLongitude(),
+		Latitude:         latitude,
+		Longitude:        longitude,
 		Timezone:         country.Timezone,
 		MondayHours:      hours.weekday,
 		TuesdayHours:     hours.weekday,
@@ -145,7 +315,14 @@ func (g *BranchGenerator) generateBranch(id int64, countryBranchCount map[string
 		CustomerCapacity: g.rng.IntRange(500, 5000),
 		ATMCount:         0, // Will be updated when ATMs are assigned
 		OpenedAt:         openedAt,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        g.config.BaseDate,
+	}
+
+	if g.rng.Probability(g.config.ClosedBranchRate) {
+		closedAt := g.rng.Date(openedAt, g.config.BaseDate).Truncate(time.Second)
+		branch.Status = models.BranchStatusClosed
+		branch.ClosedAt = &closedAt
+		branch.UpdatedAt = closedAt
 	}
 
 	return GeneratedBranch{Branch: branch, Country: country}
@@ -176,10 +353,20 @@ func (g *BranchGenerator) generateBranchATM(id int64, branch GeneratedBranch) Ge
 		Is24Hours:            g.rng.Probability(0.3), // 30% are 24-hour
 		AvgDailyTransactions: g.rng.IntRange(50, 300),
 		InstalledAt:          branch.Branch.OpenedAt.Add(g.rng.Duration(0, 365*24*time.Hour)),
-		UpdatedAt:            time.Now(),
+		UpdatedAt:            g.config.BaseDate,
 	}
 
-	return GeneratedATM{ATM: atm, Country: branch.Country}
+	generated := GeneratedATM{ATM: atm, Country: branch.Country}
+	if closedAt := branch.Branch.ClosedAt; closedAt != nil {
+		// The ATM goes with the branch
+		if generated.ATM.InstalledAt.After(*closedAt) {
+			generated.ATM.InstalledAt = *closedAt
+		}
+		g.takeOutOfService(&generated, models.ATMStatusOffline, *closedAt)
+		return generated
+	}
+	g.maybeTakeOutOfService(&generated)
+	return generated
 }
 
 // generateStandaloneATM creates a standalone ATM (mall, gas station, etc.)
@@ -205,6 +392,7 @@ func (g *BranchGenerator) generateStandaloneATM(id int64) GeneratedATM {
 	locationName := fmt.Sprintf("%s %s", city.City, g.rng.PickString(locations))
 
 	installedDate := g.generateOpeningDate()
+	latitude, longitude := jitterCoordinates(g.rng, city.Latitude, city.Longitude, locationJitter)
 
 	atm := models.ATM{
 		ID:                   id,
@@ -217,18 +405,43 @@ func (g *BranchGenerator) generateStandaloneATM(id int64) GeneratedATM {
 		State:                city.State,
 		PostalCode:           g.generatePostalCode(country.Code, city.PostalPrefix),
 		Country:              country.Code,
-		Latitude:             g.generateLatitude(),
-		Longitude:            g.generateLongitude(),
+		Latitude:             latitude,
+		Longitude:            longitude,
 		Timezone:             country.Timezone,
 		SupportsDeposit:      g.rng.Probability(0.3), // Less likely for standalone
 		SupportsTransfer:     g.rng.Probability(0.2),
 		Is24Hours:            g.rng.Probability(0.6), // More likely to be 24-hour
 		AvgDailyTransactions: g.rng.IntRange(20, 150),
 		InstalledAt:          installedDate,
-		UpdatedAt:            time.Now(),
+		UpdatedAt:            g.config.BaseDate,
+	}
+
+	generated := GeneratedATM{ATM: atm, Country: country}
+	g.maybeTakeOutOfService(&generated)
+	return generated
+}
+
+// maybeTakeOutOfService takes a fraction of ATMs offline or into maintenance
+// shortly before BaseDate: offline outages last up to a month, maintenance up to a week
+func (g *BranchGenerator) maybeTakeOutOfService(atm *GeneratedATM) {
+	switch {
+	case g.rng.Probability(g.config.OfflineATMRate):
+		g.takeOutOfService(atm, models.ATMStatusOffline, g.config.BaseDate.Add(-g.rng.Duration(time.Hour, 30*24*time.Hour)))
+	case g.rng.Probability(g.config.MaintenanceATMRate):
+		g.takeOutOfService(atm, models.ATMStatusMaintenance, g.config.BaseDate.Add(-g.rng.Duration(time.Hour, 7*24*time.Hour)))
 	}
+}
 
-	return GeneratedATM{ATM: atm, Country: country}
+// takeOutOfService marks an ATM down with the given status from since
+// (never before it was installed)
+func (g *BranchGenerator) takeOutOfService(atm *GeneratedATM, status models.ATMStatus, since time.Time) {
+	since = since.Truncate(time.Second)
+	if since.Before(atm.ATM.InstalledAt) {
+		since = atm.ATM.InstalledAt
+	}
+	atm.ATM.Status = status
+	atm.ATM.UpdatedAt = since
+	atm.OutOfServiceSince = &since
 }
 
 // pickCountry selects a country weighted by banking activity
@@ -390,16 +603,6 @@ func (g *BranchGenerator) generatePhone(phoneCode string) string {
 	return fmt.Sprintf("+%s %s", phoneCode, g.rng.NumericString(10))
 }
 
-// generateLatitude generates a random latitude
-func (g *BranchGenerator) generateLatitude() float64 {
-	return g.rng.Float64Range(-60, 70) // Avoid extreme latitudes
-}
-
-// generateLongitude generates a random longitude
-func (g *BranchGenerator) generateLongitude() float64 {
-	return g.rng.Float64Range(-180, 180)
-}
-
 // generateOpeningDate creates an opening date within the history period
 func (g *BranchGenerator) generateOpeningDate() time.Time {
 	yearsBack := g.config.YearsBack
@@ -409,21 +612,12 @@ func (g *BranchGenerator) generateOpeningDate() time.Time {
 
 	// Most branches opened throughout the history period
 	daysBack := yearsBack * 365
-	return g.rng.DateInPast(daysBack)
-}
-
-// WriteBranchesCSV writes branches to a CSV file (or .csv.xz if compress=true)
-func WriteBranchesCSV(branches []GeneratedBranch, outputDir string, compress bool) error {
-	return writeBranchesCSVInternal(branches, outputDir, compress, false)
+	return g.rng.Date(g.config.BaseDate.AddDate(0, 0, -daysBack), g.config.BaseDate)
 }
 
-// WriteBranchesCSVWithProgress writes branches with progress reporting
-func WriteBranchesCSVWithProgress(branches []GeneratedBranch, outputDir string, compress bool) error {
-	return writeBranchesCSVInternal(branches, outputDir, compress, true)
-}
-
-func writeBranchesCSVInternal(branches []GeneratedBranch, outputDir string, compress, showProgress bool) error {
-	headers := []string{
+// BranchHeaders returns the CSV headers for branches
+func BranchHeaders() []string {
+	return []string{
 		"id", "branch_code", "name", "type", "status",
 		"address_line1", "address_line2", "city", "state", "postal_code", "country",
 		"latitude", "longitude", "timezone",
@@ -432,13 +626,24 @@ func writeBranchesCSVInternal(branches []GeneratedBranch, outputDir string, comp
 		"phone", "email", "customer_capacity", "atm_count",
 		"opened_at", "closed_at", "updated_at",
 	}
+}
+
+// WriteBranchesCSV writes branches to a CSV file in cfg.OutputDir (or .csv.xz at preset cfg.XZPreset if cfg.Compress),
+// split into that many shard files if shards > 1
+func WriteBranchesCSV(branches []GeneratedBranch, cfg CSVWriterConfig, shards int) error {
+	return writeBranchesCSVInternal(branches, cfg, shards, false)
+}
 
-	writer, err := NewCSVWriter(CSVWriterConfig{
-		OutputDir: outputDir,
-		Filename:  "branches",
-		Headers:   headers,
-		Compress:  compress,
-	})
+// WriteBranchesCSVWithProgress writes branches with progress reporting
+func WriteBranchesCSVWithProgress(branches []GeneratedBranch, cfg CSVWriterConfig, shards int) error {
+	return writeBranchesCSVInternal(branches, cfg, shards, true)
+}
+
+func writeBranchesCSVInternal(branches []GeneratedBranch, cfg CSVWriterConfig, shards int, showProgress bool) error {
+	headers := BranchHeaders()
+
+	cfg.Filename, cfg.Headers = "branches", headers
+	writer, err := newTableWriter(cfg, shards, len(branches))
 	if err != nil {
 		return err
 	}
@@ -504,31 +709,33 @@ func writeBranchesCSVInternal(branches []GeneratedBranch, outputDir string, comp
 	return writer.Close()
 }
 
-// WriteATMsCSV writes ATMs to a CSV file (or .csv.xz if compress=true)
-func WriteATMsCSV(atms []GeneratedATM, outputDir string, compress bool) error {
-	return writeATMsCSVInternal(atms, outputDir, compress, false)
-}
-
-// WriteATMsCSVWithProgress writes ATMs with progress reporting
-func WriteATMsCSVWithProgress(atms []GeneratedATM, outputDir string, compress bool) error {
-	return writeATMsCSVInternal(atms, outputDir, compress, true)
-}
-
-func writeATMsCSVInternal(atms []GeneratedATM, outputDir string, compress, showProgress bool) error {
-	headers := []string{
+// ATMHeaders returns the CSV headers for ATMs
+func ATMHeaders() []string {
+	return []string{
 		"id", "atm_id", "branch_id", "status",
 		"location_name", "address_line1", "city", "state", "postal_code", "country",
 		"latitude", "longitude", "timezone",
 		"supports_deposit", "supports_transfer", "is_24_hours",
 		"avg_daily_transactions", "installed_at", "updated_at",
 	}
+}
+
+// WriteATMsCSV writes ATMs to a CSV file in cfg.OutputDir (or .csv.xz at preset cfg.XZPreset if cfg.Compress),
+// split into that many shard files if shards > 1
+func WriteATMsCSV(atms []GeneratedATM, cfg CSVWriterConfig, shards int) error {
+	return writeATMsCSVInternal(atms, cfg, shards, false)
+}
+
+// WriteATMsCSVWithProgress writes ATMs with progress reporting
+func WriteATMsCSVWithProgress(atms []GeneratedATM, cfg CSVWriterConfig, shards int) error {
+	return writeATMsCSVInternal(atms, cfg, shards, true)
+}
+
+func writeATMsCSVInternal(atms []GeneratedATM, cfg CSVWriterConfig, shards int, showProgress bool) error {
+	headers := ATMHeaders()
 
-	writer, err := NewCSVWriter(CSVWriterConfig{
-		OutputDir: outputDir,
-		Filename:  "atms",
-		Headers:   headers,
-		Compress:  compress,
-	})
+	cfg.Filename, cfg.Headers = "atms", headers
+	writer, err := newTableWriter(cfg, shards, len(atms))
 	if err != nil {
 		return err
 	}
@@ -36,10 +36,19 @@ type BusinessGeneratorConfig struct {
 	StartID int64
 	// Branches to assign businesses to
 	Branches []GeneratedBranch
+	// BaseDate stands in for the current time (zero = now)
+	BaseDate time.Time
+	// Passwords hashes business login passwords (zero value = unsalted SHA-256)
+	Passwords PasswordHasher
+	// Mix gives the fraction of each business type (nil = DefaultBusinessMix)
+	Mix *BusinessMix
 }
 
 // NewBusinessGenerator creates a new business generator
 func NewBusinessGenerator(rng *utils.Random, refData *data.ReferenceData, config BusinessGeneratorConfig) *BusinessGenerator {
+	if config.BaseDate.IsZero() {
+		config.BaseDate = time.Now()
+	}
 	return &BusinessGenerator{
 		rng:     rng,
 		refData: refData,
@@ -59,8 +68,8 @@ type GeneratedBusiness struct {
 func (g *BusinessGenerator) GenerateBusinesses() []GeneratedBusiness {
 	businesses := make([]GeneratedBusiness, 0, g.config.NumBusinesses)
 
-	// Distribution of business types:
-	// 40% employers, 35% merchants, 15% utilities, 10% government
+	// Distribution of business types (by default 40% employers, 35% merchants,
+	// 15% utilities, 10% government), the remainder general
 	distribution := g.calculateDistribution()
 
 	for i := 0; i < g.config.NumBusinesses; i++ {
@@ -74,13 +83,16 @@ func (g *BusinessGenerator) GenerateBusinesses() []GeneratedBusiness {
 
 // calculateDistribution determines how many of each business type to create
 func (g *BusinessGenerator) calculateDistribution() map[BusinessType]int {
+	mix := DefaultBusinessMix()
+	if g.config.Mix != nil {
+		mix = *g.config.Mix
+	}
 	n := g.config.NumBusinesses
-	return map[BusinessType]int{
-		BusinessTypeEmployer:   int(float64(n) * 0.40),
-		BusinessTypeMerchant:   int(float64(n) * 0.35),
-		BusinessTypeUtility:    int(float64(n) * 0.15),
-		BusinessTypeGovernment: int(float64(n) * 0.10),
+	dist := make(map[BusinessType]int)
+	for _, s := range mix.shares() {
+		dist[s.businessType] = int(float64(n) * s.share)
 	}
+	return dist
 }
 
 // pickBusinessType determines business type based on distribution
@@ -129,7 +141,7 @@ func (g *BusinessGenerator) generateBusiness(id int64, bizType BusinessType) Gen
 
 	// Generate auth data (businesses also have online banking access)
 	username := g.generateUsername(businessName, id)
-	passwordHash := hashString(g.rng.String(16))
+	passwordHash := g.config.Passwords.Hash(g.rng.String(16))
 
 	customer := models.Customer{
 		ID:            id,
@@ -153,7 +165,7 @@ func (g *BusinessGenerator) generateBusiness(id int64, bizType BusinessType) Gen
 		PasswordHash:  passwordHash,
 		PIN:           "", // Businesses don't use ATM PINs
 		CreatedAt:     createdAt,
-		UpdatedAt:     time.Now(),
+		UpdatedAt:     g.config.BaseDate,
 	}
 
 	return GeneratedBusiness{
@@ -420,13 +432,13 @@ func (g *BusinessGenerator) generateSuiteNumber() string {
 func (g *BusinessGenerator) generateIncorporationDate() time.Time {
 	// Businesses are 1-30 years old
 	yearsBack := g.rng.IntRange(1, 30)
-	return time.Now().AddDate(-yearsBack, 0, 0)
+	return g.config.BaseDate.AddDate(-yearsBack, 0, 0)
 }
 
 // generateCreatedAt creates a customer record creation date
 func (g *BusinessGenerator) generateCreatedAt() time.Time {
 	daysBack := g.rng.IntRange(30, 5*365)
-	return time.Now().AddDate(0, 0, -daysBack)
+	return g.config.BaseDate.AddDate(0, 0, -daysBack)
 }
 
 // generatePostalCode creates a postal code based on country format
@@ -463,43 +475,22 @@ func (g *BusinessGenerator) generatePostalCode(countryCode, prefix string) strin
 	return result
 }
 
-// hashString creates a simple hash for a string
-func hashString(s string) string {
-	// Reuse the hashPassword logic from customer.go
-	// For simplicity, inline a basic implementation
-	sum := 0
-	for _, c := range s {
-		sum = sum*31 + int(c)
-	}
-	return fmt.Sprintf("%064x", sum)
-}
-
-// WriteBusinessesCSV writes businesses to the customers CSV file (or .csv.xz if compress=true)
+// WriteBusinessesCSV writes businesses to the customers CSV file in cfg.OutputDir (or .csv.xz at preset cfg.XZPreset if cfg.Compress)
 // (businesses are stored in the same table as customers)
-func WriteBusinessesCSV(businesses []GeneratedBusiness, outputDir string, compress bool) error {
-	return writeBusinessesCSVInternal(businesses, outputDir, compress, false)
+func WriteBusinessesCSV(businesses []GeneratedBusiness, cfg CSVWriterConfig, shards int) error {
+	return writeBusinessesCSVInternal(businesses, cfg, shards, false)
 }
 
 // WriteBusinessesCSVWithProgress writes businesses with progress reporting
-func WriteBusinessesCSVWithProgress(businesses []GeneratedBusiness, outputDir string, compress bool) error {
-	return writeBusinessesCSVInternal(businesses, outputDir, compress, true)
+func WriteBusinessesCSVWithProgress(businesses []GeneratedBusiness, cfg CSVWriterConfig, shards int) error {
+	return writeBusinessesCSVInternal(businesses, cfg, shards, true)
 }
 
-func writeBusinessesCSVInternal(businesses []GeneratedBusiness, outputDir string, compress, showProgress bool) error {
-	headers := []string{
-		"id", "first_name", "last_name", "email", "phone", "date_of_birth",
-		"address_line1", "address_line2", "city", "state", "postal_code", "country",
-		"timezone", "home_branch_id", "segment", "status", "activity_score",
-		"username", "password_hash", "pin",
-		"created_at", "updated_at",
-	}
+func writeBusinessesCSVInternal(businesses []GeneratedBusiness, cfg CSVWriterConfig, shards int, showProgress bool) error {
+	headers := CustomerHeaders()
 
-	writer, err := NewCSVWriter(CSVWriterConfig{
-		OutputDir: outputDir,
-		Filename:  "businesses",
-		Headers:   headers,
-		Compress:  compress,
-	})
+	cfg.Filename, cfg.Headers = "businesses", headers
+	writer, err := newTableWriter(cfg, shards, len(businesses))
 	if err != nil {
 		return err
 	}
@@ -514,32 +505,7 @@ func writeBusinessesCSVInternal(businesses []GeneratedBusiness, outputDir string
 	}
 
 	for i, gb := range businesses {
-		c := gb.Customer
-		row := []string{
-			FormatInt64(c.ID),
-			c.FirstName,
-			c.LastName,
-			c.Email,
-			c.Phone,
-			FormatDate(c.DateOfBirth),
-			c.AddressLine1,
-			c.AddressLine2,
-			c.City,
-			c.State,
-			c.PostalCode,
-			c.Country,
-			c.Timezone,
-			FormatInt64(c.HomeBranch),
-			string(c.Segment),
-			string(c.Status),
-			FormatFloat64(c.ActivityScore),
-			c.Username,
-			c.PasswordHash,
-			c.PIN,
-			FormatTime(c.CreatedAt),
-			FormatTime(c.UpdatedAt),
-		}
-		if err := writer.WriteRow(row); err != nil {
+		if err := writer.WriteRow(customerRow(gb.Customer)); err != nil {
 			return err
 		}
 
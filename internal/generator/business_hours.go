@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// minOffHoursActivity keeps suppression above zero: a period that is entirely
+// off-hours (a weekend day at daily granularity) would otherwise never accept
+// a timestamp.
+const minOffHoursActivity = 0.001
+
+// BusinessHours suppresses an account type's activity outside business hours,
+// in the customer's local time. Factors scale the time pattern (1 = unchanged).
+// Counterparty legs (e.g. a weekend card sale credited to a merchant) follow
+// the originating account instead.
+type BusinessHours struct {
+	Weekend   float64 // Relative activity on Saturdays and Sundays
+	Overnight float64 // Relative activity on weekdays before OpenHour or from CloseHour
+	OpenHour  int
+	CloseHour int
+}
+
+// factor returns the activity multiplier at t (local time)
+func (b *BusinessHours) factor(t time.Time) float64 {
+	if b == nil {
+		return 1
+	}
+	if weekend(t) {
+		return b.Weekend
+	}
+	if t.Hour() < b.OpenHour || t.Hour() >= b.CloseHour {
+		return b.Overnight
+	}
+	return 1
+}
+
+// dayFactor returns the activity multiplier for a whole day
+func (b *BusinessHours) dayFactor(day time.Time) float64 {
+	if b == nil || !weekend(day) {
+		return 1
+	}
+	return b.Weekend
+}
+
+// WeekendVolume is the transaction volume on a Saturday or Sunday relative to
+// a weekday (1 = the same), for retail and for business accounts. It weights
+// the days a period's count is spread across, on top of the time patterns.
+type WeekendVolume struct {
+	Retail   float64 `json:"retail"`
+	Business float64 `json:"business"`
+}
+
+// multiplier returns the weekend volume for retail or business accounts,
+// clamped above zero like BusinessHours (nil = weekends weighted as weekdays)
+func (v *WeekendVolume) multiplier(business bool) float64 {
+	if v == nil {
+		return 1
+	}
+	if business {
+		return max(v.Business, minOffHoursActivity)
+	}
+	return max(v.Retail, minOffHoursActivity)
+}
+
+// weekendWeight returns the weight of the day t falls on given the weekend multiplier
+func weekendWeight(t time.Time, multiplier float64) float64 {
+	if weekend(t) {
+		return multiplier
+	}
+	return 1
+}
+
+func weekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// businessHoursByType clamps the configured factors and indexes them for lookup
+func businessHoursByType(config map[models.AccountType]BusinessHours) map[models.AccountType]*BusinessHours {
+	byType := make(map[models.AccountType]*BusinessHours, len(config))
+	for accountType, hours := range config {
+		hours.Weekend = max(hours.Weekend, minOffHoursActivity)
+		hours.Overnight = max(hours.Overnight, minOffHoursActivity)
+		byType[accountType] = &hours
+	}
+	return byType
+}
@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestBusinessAccountsQuietOnWeekends(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 6, 0)
+
+	var accounts []GeneratedAccount
+	for i, accountType := range []models.AccountType{models.AccountTypeBusiness, models.AccountTypeMerchant, models.AccountTypePayroll} {
+		for j := 0; j < 5; j++ {
+			id := int64(i*5 + j + 1)
+			customer := GeneratedCustomer{Customer: models.Customer{
+				ID: id, Segment: models.SegmentBusiness, ActivityScore: 1, Timezone: "Europe/Berlin",
+			}}
+			accounts = append(accounts, GeneratedAccount{
+				Account: models.Account{
+					ID: id, CustomerID: id, Type: accountType, Currency: "EUR",
+					Balance: 100000000, OpenedAt: start.AddDate(-1, 0, 0),
+				},
+				Customer: customer,
+			})
+		}
+	}
+
+	office := BusinessHours{Weekend: 0.02, Overnight: 0.02, OpenHour: 8, CloseHour: 18}
+	hours := map[models.AccountType]BusinessHours{
+		models.AccountTypeBusiness: office,
+		models.AccountTypeMerchant: office,
+		models.AccountTypePayroll:  office,
+	}
+
+	for _, gr := range []Granularity{GranularityMonthly, GranularityDaily} {
+		// Per-day activity, weekend vs weekday and overnight vs office hours
+		var weekendTxns, weekdayTxns, overnightTxns, officeTxns int
+		gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+			StartDate:                       start,
+			EndDate:                         end,
+			TransactionsPerCustomerPerMonth: 30,
+			ParetoRatio:                     0.2,
+			Granularity:                     gr,
+			Accounts:                        accounts,
+			BusinessHours:                   hours,
+		})
+		txns, _ := gen.GenerateTransactionsForAccounts(accounts, 1)
+		for _, txn := range txns {
+			ts := txn.Transaction.Timestamp
+			switch {
+			case weekend(ts):
+				weekendTxns++
+			case ts.Hour() < 8 || ts.Hour() >= 18:
+				overnightTxns++
+			default:
+				officeTxns++
+			}
+			if !weekend(ts) {
+				weekdayTxns++
+			}
+		}
+		if weekdayTxns == 0 {
+			t.Fatalf("%s: expected weekday business transactions", gr)
+		}
+
+		// 2 weekend days vs 5 weekdays; 14 overnight hours vs 10 office hours
+		weekendRate, weekdayRate := float64(weekendTxns)/2, float64(weekdayTxns)/5
+		if weekendRate > weekdayRate/10 {
+			t.Errorf("%s: %d weekend vs %d weekday transactions, expected weekends well below weekdays",
+				gr, weekendTxns, weekdayTxns)
+		}
+		if overnightRate, officeRate := float64(overnightTxns)/14, float64(officeTxns)/10; overnightRate > officeRate/10 {
+			t.Errorf("%s: %d overnight vs %d office-hours weekday transactions, expected nights well below office hours",
+				gr, overnightTxns, officeTxns)
+		}
+	}
+}
+
+func TestWeekendVolume(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 3, 0)
+
+	var accounts []GeneratedAccount
+	for id := int64(1); id <= 10; id++ {
+		accounts = append(accounts, GeneratedAccount{
+			Account: models.Account{
+				ID: id, CustomerID: id, Type: models.AccountTypeChecking, Currency: "USD",
+				Balance: 100000000, OpenedAt: start.AddDate(-1, 0, 0),
+			},
+			Customer: GeneratedCustomer{Customer: models.Customer{ID: id, ActivityScore: 1, Timezone: "UTC"}},
+		})
+	}
+
+	// weekendShare returns weekend transactions per day relative to weekday ones
+	weekendShare := func(gr Granularity, volume float64) float64 {
+		gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+			StartDate:                       start,
+			EndDate:                         end,
+			TransactionsPerCustomerPerMonth: 30,
+			ParetoRatio:                     0.2,
+			Granularity:                     gr,
+			Accounts:                        accounts,
+			WeekendVolume:                   &WeekendVolume{Retail: volume, Business: 1},
+		})
+		txns, _ := gen.GenerateTransactionsForAccounts(accounts, 1)
+		var weekendTxns, weekdayTxns int
+		for _, txn := range txns {
+			if weekend(txn.Transaction.Timestamp) {
+				weekendTxns++
+			} else {
+				weekdayTxns++
+			}
+		}
+		if weekdayTxns == 0 {
+			t.Fatalf("%s: expected weekday transactions", gr)
+		}
+		return (float64(weekendTxns) / 2) / (float64(weekdayTxns) / 5)
+	}
+
+	for _, gr := range []Granularity{GranularityMonthly, GranularityDaily} {
+		quiet, busy := weekendShare(gr, 0.5), weekendShare(gr, 2)
+		if busy < quiet*2 {
+			t.Errorf("%s: weekend/weekday rate %.2f at 2x weekend volume vs %.2f at 0.5x, expected a clear rise",
+				gr, busy, quiet)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BusinessMix holds the fraction of businesses of each type. The fractions
+// must not add up to more than 1; the remainder are general businesses.
+// Employers, merchants and utilities are the counterparty pools for salary,
+// purchase and bill payment transactions.
+type BusinessMix struct {
+	Employer   float64 `json:"employer"`
+	Merchant   float64 `json:"merchant"`
+	Utility    float64 `json:"utility"`
+	Government float64 `json:"government"`
+}
+
+// DefaultBusinessMix returns the built-in business type mix
+func DefaultBusinessMix() BusinessMix {
+	return BusinessMix{Employer: 0.40, Merchant: 0.35, Utility: 0.15, Government: 0.10}
+}
+
+// businessShare is a business type and its fraction of businesses
+type businessShare struct {
+	businessType BusinessType
+	share        float64
+}
+
+// shares returns each type's fraction in assignment order
+func (m BusinessMix) shares() []businessShare {
+	return []businessShare{
+		{BusinessTypeEmployer, m.Employer},
+		{BusinessTypeMerchant, m.Merchant},
+		{BusinessTypeUtility, m.Utility},
+		{BusinessTypeGovernment, m.Government},
+	}
+}
+
+// Validate checks each fraction is within 0-1 and they leave a non-negative remainder
+func (m BusinessMix) Validate() error {
+	var total float64
+	for _, s := range m.shares() {
+		if s.share < 0 || s.share > 1 {
+			return fmt.Errorf("%s fraction %g must be between 0 and 1", s.businessType, s.share)
+		}
+		total += s.share
+	}
+	// Allow for rounding in fractions written to add up to exactly 1
+	if total > 1+1e-9 {
+		return fmt.Errorf("business type fractions add up to %g, more than 1", total)
+	}
+	return nil
+}
+
+// ParseBusinessMix parses a --business-mix value such as
+// "merchant=0.6,employer=0.25". Types not listed keep their DefaultBusinessMix
+// fraction; the result is validated.
+func ParseBusinessMix(s string) (BusinessMix, error) {
+	mix := DefaultBusinessMix()
+	fields := map[BusinessType]*float64{
+		BusinessTypeEmployer:   &mix.Employer,
+		BusinessTypeMerchant:   &mix.Merchant,
+		BusinessTypeUtility:    &mix.Utility,
+		BusinessTypeGovernment: &mix.Government,
+	}
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return BusinessMix{}, fmt.Errorf("invalid business mix entry %q (expected type=fraction)", part)
+		}
+		field, ok := fields[BusinessType(strings.ToLower(strings.TrimSpace(name)))]
+		if !ok {
+			return BusinessMix{}, fmt.Errorf("unknown business type %q (expected employer, merchant, utility or government)", name)
+		}
+		share, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return BusinessMix{}, fmt.Errorf("invalid %s fraction %q: %w", name, value, err)
+		}
+		*field = share
+	}
+	if err := mix.Validate(); err != nil {
+		return BusinessMix{}, err
+	}
+	return mix, nil
+}
+
+// String formats the mix as a --business-mix value
+func (m BusinessMix) String() string {
+	parts := make([]string, 0, 4)
+	for _, s := range m.shares() {
+		parts = append(parts, fmt.Sprintf("%s=%g", s.businessType, s.share))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestParseBusinessMix(t *testing.T) {
+	mix, err := ParseBusinessMix("merchant=0.55, Employer=0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (BusinessMix{Employer: 0.2, Merchant: 0.55, Utility: 0.15, Government: 0.10}); mix != want {
+		t.Errorf("got %+v, want %+v", mix, want)
+	}
+	if got, err := ParseBusinessMix(mix.String()); err != nil || got != mix {
+		t.Errorf("String() does not round-trip: %q -> %+v, %v", mix.String(), got, err)
+	}
+
+	for _, bad := range []string{"merchant", "bank=0.1", "merchant=x", "merchant=-0.1", "merchant=0.9"} {
+		if _, err := ParseBusinessMix(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestBusinessMixDistribution(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(5)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 3, BaseDate: asOf}).GenerateBranches()
+
+	mix := BusinessMix{Merchant: 0.7, Employer: 0.2}
+	businesses := NewBusinessGenerator(rng.Fork(), refData, BusinessGeneratorConfig{
+		NumBusinesses: 100, StartID: 1, Branches: branches, BaseDate: asOf, Mix: &mix,
+	}).GenerateBusinesses()
+
+	counts := make(map[BusinessType]int)
+	for _, b := range businesses {
+		counts[b.BusinessType]++
+	}
+	want := map[BusinessType]int{BusinessTypeMerchant: 70, BusinessTypeEmployer: 20, BusinessTypeGeneral: 10}
+	if len(counts) != len(want) {
+		t.Errorf("got types %v, want %v", counts, want)
+	}
+	for bt, n := range want {
+		if counts[bt] != n {
+			t.Errorf("%s: got %d businesses, want %d", bt, counts[bt], n)
+		}
+	}
+}
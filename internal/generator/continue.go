@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Continuation describes an existing output directory that a run extends.
+// Entities are regenerated in memory from the original seed and as-of date
+// (their files are not written again); transactions and audit logs continue
+// after the last IDs, from each account's final balance.
+type Continuation struct {
+	Dir      string   // Output directory being continued
+	Manifest Manifest // Its manifest
+
+	// Settings the entities were originally generated with
+	EntitySeed  int64
+	EntityAsOf  time.Time
+	EntityYears int
+
+	Balances          map[int64]int64 // Final balance per account that has transactions
+	LastTransactionID int64
+	LastAuditLogID    int64
+}
+
+// ManifestContinuation is recorded in the manifest of a continuation run so
+// it can be continued in turn
+type ManifestContinuation struct {
+	From         string    `json:"from"`          // Output directory that was continued
+	EntitySeed   int64     `json:"entity_seed"`   // Seed the entities were generated with
+	EntityAsOf   time.Time `json:"entity_as_of"`  // As-of date the entities were generated at
+	EntityYears  int       `json:"entity_years"`  // History years the entities were generated with
+	HistoryStart time.Time `json:"history_start"` // Start of this run's transactions (the previous as-of)
+}
+
+// LoadContinuation reads the manifest, last IDs and final account balances
+// from an existing output directory. If that directory was itself a
+// continuation, balances for accounts it didn't touch come from its parent.
+func LoadContinuation(ctx context.Context, dir string) (*Continuation, error) {
+	if IsS3Path(dir) {
+		return nil, fmt.Errorf("cannot continue from %s: only local directories are supported", dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+
+	m, err := ReadManifest(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Continuation{
+		Dir:         abs,
+		Manifest:    *m,
+		EntitySeed:  m.Seed,
+		EntityAsOf:  m.AsOfDate,
+		EntityYears: m.YearsOfHistory,
+		Balances:    make(map[int64]int64),
+	}
+	if m.RNGVersion < RNGVersion {
+		// Older builds forked the streams in sequence, so the same seed no
+		// longer regenerates the entities the data set was written with
+		if m.RNGVersion == 0 {
+			return nil, fmt.Errorf("%s: manifest has no rng_version, so it predates this build's random streams and cannot be continued", abs)
+		}
+		return nil, fmt.Errorf("%s: manifest rng_version %d is older than this build's %d, so its entities cannot be regenerated", abs, m.RNGVersion, RNGVersion)
+	}
+	if m.AsOfDate.IsZero() {
+		return nil, fmt.Errorf("%s: manifest has no as_of date to continue from", abs)
+	}
+	if len(m.Only) > 0 {
+		return nil, fmt.Errorf("%s: only %s were generated, so there is no history to continue", abs, strings.Join(m.Only, ", "))
+	}
+
+	var parent *Continuation
+	if m.Continuation != nil {
+		c.EntitySeed = m.Continuation.EntitySeed
+		c.EntityAsOf = m.Continuation.EntityAsOf
+		c.EntityYears = m.Continuation.EntityYears
+
+		parent, err = LoadContinuation(ctx, m.Continuation.From)
+		if err != nil {
+			return nil, fmt.Errorf("continued data set %s: %w", m.Continuation.From, err)
+		}
+	}
+
+	if err := c.readTransactions(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.readAuditLogs(ctx); err != nil {
+		return nil, err
+	}
+
+	if parent != nil {
+		for id, balance := range parent.Balances {
+			if _, ok := c.Balances[id]; !ok {
+				c.Balances[id] = balance
+			}
+		}
+		c.LastTransactionID = max(c.LastTransactionID, parent.LastTransactionID)
+		c.LastAuditLogID = max(c.LastAuditLogID, parent.LastAuditLogID)
+	}
+	return c, nil
+}
+
+// checkEntities verifies the regenerated entities match the continued data set,
+// which fails if the generator's entity logic changed between the two runs
+func (c *Continuation) checkEntities(result *GenerationResult) error {
+	want := c.Manifest.Counts
+	for _, check := range []struct {
+		table     string
+		got, want int
+	}{
+		{"branches", result.BranchCount, want.Branches},
+		{"atms", result.ATMCount, want.ATMs},
+		{"customers", result.CustomerCount, want.Customers},
+		{"businesses", result.BusinessCount, want.Businesses},
+		{"accounts", result.AccountCount, want.Accounts},
+		{"beneficiaries", result.BeneficiaryCount, want.Beneficiaries},
+	} {
+		if check.got != check.want {
+			return fmt.Errorf("cannot continue %s: regenerated %d %s, expected %d", c.Dir, check.got, check.table, check.want)
+		}
+	}
+	return nil
+}
+
+// readTransactions finds the last transaction ID and each account's final balance
+func (c *Continuation) readTransactions(ctx context.Context) error {
+	files, err := FindShardedFiles(c.Dir, "transactions")
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		if c.Manifest.Counts.Transactions > 0 {
+			return fmt.Errorf("%s: manifest lists %d transactions but no transaction files were found",
+				c.Dir, c.Manifest.Counts.Transactions)
+		}
+		return nil
+	}
+
+	type last struct {
+		id int64
+		ts string // Timestamps sort lexically in the CSV format
+	}
+	latest := make(map[int64]last)
+
+	for _, file := range files {
+		err := ReadCSVRows(ctx, file, []string{"id", "account_id", "balance_after", "timestamp"}, func(row []string) error {
+			var vals [3]int64
+			for i := range vals {
+				v, err := strconv.ParseInt(row[i], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid value %q: %w", row[i], err)
+				}
+				vals[i] = v
+			}
+			id, accountID, balance, ts := vals[0], vals[1], vals[2], row[3]
+
+			c.LastTransactionID = max(c.LastTransactionID, id)
+			// Ties on timestamp resolve by ID, as verification replays them
+			if prev, ok := latest[accountID]; !ok || ts > prev.ts || (ts == prev.ts && id > prev.id) {
+				latest[accountID] = last{id: id, ts: ts}
+				c.Balances[accountID] = balance
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
+
+// readAuditLogs finds the last audit log ID across the session and transaction shards
+func (c *Continuation) readAuditLogs(ctx context.Context) error {
+	// audit_logs_* also matches the audit_logs_txn_* shards
+	files, err := FindShardedFiles(c.Dir, "audit_logs")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		err := ReadCSVRows(ctx, file, []string{"id"}, func(row []string) error {
+			id, err := strconv.ParseInt(row[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid id %q: %w", row[0], err)
+			}
+			c.LastAuditLogID = max(c.LastAuditLogID, id)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
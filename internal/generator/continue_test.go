@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestLoadContinuation(t *testing.T) {
+	asOf := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent := t.TempDir()
+	if err := WriteManifestFile(parent, Manifest{Seed: 7, RNGVersion: RNGVersion, AsOfDate: asOf, YearsOfHistory: 3,
+		Counts: ManifestCounts{Transactions: 3, AuditLogs: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	// Account 1's last transaction shares its timestamp with an earlier ID
+	writeVerifyFixture(t, parent, "transactions_001.csv", TransactionHeaders(),
+		verifyTxnRow("5", "1", "purchase", "completed", "100", "900", "2023-12-31 10:00:00"),
+		verifyTxnRow("2", "1", "deposit", "completed", "1000", "1000", "2023-12-31 10:00:00"),
+		verifyTxnRow("3", "2", "deposit", "completed", "50", "50", "2023-06-01 08:00:00"),
+	)
+	writeVerifyFixture(t, parent, "audit_logs_txn_001.csv", []string{"id"}, "40")
+
+	c, err := LoadContinuation(context.Background(), parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.LastTransactionID != 5 || c.LastAuditLogID != 40 {
+		t.Errorf("last IDs %d/%d, expected 5/40", c.LastTransactionID, c.LastAuditLogID)
+	}
+	if c.Balances[1] != 900 || c.Balances[2] != 50 {
+		t.Errorf("balances %v, expected 1:900 2:50", c.Balances)
+	}
+
+	// A continuation of the continuation keeps the original entity settings
+	// and falls back to the parent for accounts without new transactions
+	child := t.TempDir()
+	if err := WriteManifestFile(child, Manifest{Seed: 8, RNGVersion: RNGVersion, AsOfDate: asOf.AddDate(1, 0, 0), YearsOfHistory: 1,
+		Continuation: &ManifestContinuation{From: parent, EntitySeed: 7, EntityAsOf: asOf, EntityYears: 3, HistoryStart: asOf},
+		Counts:       ManifestCounts{Transactions: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	writeVerifyFixture(t, child, "transactions_001.csv", TransactionHeaders(),
+		verifyTxnRow("6", "1", "deposit", "completed", "100", "1000", "2024-02-01 10:00:00"),
+	)
+
+	c, err = LoadContinuation(context.Background(), child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.EntitySeed != 7 || !c.EntityAsOf.Equal(asOf) || c.EntityYears != 3 {
+		t.Errorf("entity settings %d/%s/%d, expected the original run's", c.EntitySeed, c.EntityAsOf, c.EntityYears)
+	}
+	if c.LastTransactionID != 6 || c.LastAuditLogID != 40 {
+		t.Errorf("last IDs %d/%d, expected 6/40", c.LastTransactionID, c.LastAuditLogID)
+	}
+	if c.Balances[1] != 1000 || c.Balances[2] != 50 {
+		t.Errorf("balances %v, expected 1:1000 2:50", c.Balances)
+	}
+}
+
+func TestLoadContinuationRejectsOlderRNGVersion(t *testing.T) {
+	asOf := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		version int
+		want    string
+	}{
+		{0, "has no rng_version"},
+		{RNGVersion - 1, "is older than"},
+	} {
+		dir := t.TempDir()
+		if err := WriteManifestFile(dir, Manifest{Seed: 7, RNGVersion: tc.version, AsOfDate: asOf, YearsOfHistory: 1}); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadContinuation(context.Background(), dir)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("rng_version %d: error %v, expected one containing %q", tc.version, err, tc.want)
+		}
+	}
+}
+
+// TestContinuationEntitySettings continues a run generated with non-default
+// beneficiary bounds and foreign currency accounts, which only regenerates the
+// same entities when those settings come back from the manifest
+func TestContinuationEntitySettings(t *testing.T) {
+	ctx := context.Background()
+	asOf := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := OrchestratorConfig{
+		NumCustomers:                    40,
+		NumBranches:                     2,
+		NumATMs:                         2,
+		YearsOfHistory:                  1,
+		TransactionsPerCustomerPerMonth: 2,
+		PayrollDay:                      25,
+		Workers:                         1,
+	}
+
+	parent := base
+	parent.OutputDir = t.TempDir()
+	parent.Seed = 3
+	parent.AsOfDate = asOf
+	parent.MinBeneficiaries, parent.MaxBeneficiaries = 7, 9
+	parent.ForeignCurrencyRate = 0.5
+	parent.ForeignCurrencies = []models.Currency{models.CurrencyEUR, models.CurrencyGBP}
+	o, err := NewOrchestrator(parent, OrchestratorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := o.GenerateAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.WriteManifest(result); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadContinuation(ctx, parent.OutputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := c.Manifest
+	if m.MinBeneficiaries != 7 || m.MaxBeneficiaries != 9 {
+		t.Errorf("manifest beneficiary bounds %d-%d, expected 7-9", m.MinBeneficiaries, m.MaxBeneficiaries)
+	}
+	if fc := m.ForeignCurrency; fc == nil || fc.Rate != 0.5 || !slices.Equal(fc.Currencies, parent.ForeignCurrencies) {
+		t.Errorf("manifest foreign currency %+v, expected 50%% in EUR,GBP", fc)
+	}
+
+	continueWith := func(cfg OrchestratorConfig) error {
+		cfg.OutputDir = t.TempDir()
+		cfg.Seed = 4
+		cfg.AsOfDate = asOf.AddDate(1, 0, 0)
+		cfg.Continuation = c
+		o, err := NewOrchestrator(cfg, OrchestratorOptions{})
+		if err != nil {
+			return err
+		}
+		_, err = o.GenerateAll(ctx)
+		return err
+	}
+	restored := base
+	restored.MinBeneficiaries, restored.MaxBeneficiaries = m.MinBeneficiaries, m.MaxBeneficiaries
+	restored.ForeignCurrencyRate, restored.ForeignCurrencies = m.ForeignCurrency.Rate, m.ForeignCurrency.Currencies
+	if err := continueWith(restored); err != nil {
+		t.Errorf("continuation with the manifest's settings: %v", err)
+	}
+	// The defaults regenerate different entities, so the check catches them
+	if err := continueWith(base); err == nil || !strings.Contains(err.Error(), "cannot continue") {
+		t.Errorf("continuation with default settings: error %v, expected an entity mismatch", err)
+	}
+}
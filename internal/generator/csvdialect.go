@@ -0,0 +1,25 @@
+package generator
+
+// CSVEnclosure is the quote character CSVWriter encloses fields in when they
+// need it. Embedded quotes are doubled; nothing is backslash-escaped.
+const CSVEnclosure = '"'
+
+// CSVDialect describes how CSVWriter lays out rows, so loaders can parse
+// them the same way
+type CSVDialect struct {
+	FieldTerminator rune // Separates fields
+	UseCRLF         bool // End lines with \r\n instead of \n
+}
+
+// DefaultCSVDialect returns the dialect CSVWriter uses unless configured otherwise
+func DefaultCSVDialect() CSVDialect {
+	return CSVDialect{FieldTerminator: ','}
+}
+
+// LineTerminator returns the string that ends each row
+func (d CSVDialect) LineTerminator() string {
+	if d.UseCRLF {
+		return "\r\n"
+	}
+	return "\n"
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// xzReader streams the output of `xz -d -c`
+type xzReader struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	eof    bool
+}
+
+func (r *xzReader) Read(p []byte) (int, error) {
+	n, err := r.stdout.Read(p)
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+// Close reports xz's exit status after a full read; a partial read stops xz
+func (r *xzReader) Close() error {
+	if r.eof {
+		if err := r.cmd.Wait(); err != nil {
+			return fmt.Errorf("xz decompression failed: %w", err)
+		}
+		return nil
+	}
+	r.cmd.Process.Kill()
+	r.cmd.Wait()
+	return nil
+}
+
+// OpenCSVFile opens a .csv file, or decompresses a .csv.xz file through the
+// external xz command, for streaming reads.
+func OpenCSVFile(ctx context.Context, path string) (io.ReadCloser, error) {
+	if !strings.HasSuffix(path, ".xz") {
+		return os.Open(path)
+	}
+
+	xzCmd := exec.CommandContext(ctx, "xz", "-d", "-c", path)
+	stdout, err := xzCmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := xzCmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start xz: %w", err)
+	}
+	return &xzReader{cmd: xzCmd, stdout: stdout}, nil
+}
+
+// ReadCSVRows streams a CSV (or .csv.xz) file, calling fn with the named
+// columns of each data row in the order given. The row slice is reused
+// between calls. Returns an error if a named column is missing.
+func ReadCSVRows(ctx context.Context, path string, columns []string, fn func(row []string) error) (err error) {
+	r, err := OpenCSVFile(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := r.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	reader := csv.NewReader(bufio.NewReaderSize(r, 1024*1024))
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	index := make([]int, len(columns))
+	for i, col := range columns {
+		index[i] = -1
+		for j, h := range header {
+			if h == col {
+				index[i] = j
+				break
+			}
+		}
+		if index[i] < 0 {
+			return fmt.Errorf("missing column %q", col)
+		}
+	}
+
+	row := make([]string, len(columns))
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if line%100000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		for i, j := range index {
+			row[i] = record[j]
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
@@ -11,24 +11,50 @@ import (
 	"time"
 )
 
+// Write buffer sizes: 64KB suits the entity tables, while the transaction and
+// audit log shards default to 1MB so the xz pipe and disk see fewer, larger
+// writes
+const (
+	defaultBufferSize   = 64 << 10
+	streamingBufferSize = 1 << 20
+)
+
+// streamingBuffer returns the write buffer size for a transaction or audit
+// log shard: size if set, otherwise streamingBufferSize
+func streamingBuffer(size int) int {
+	if size > 0 {
+		return size
+	}
+	return streamingBufferSize
+}
+
 // CSVWriter provides a streaming, memory-efficient CSV writer for large data files.
 // It uses buffered I/O and writes rows immediately to minimize memory usage.
-// Optionally supports xz compression via external xz process.
+// Optionally supports xz compression via external xz process, and writing
+// straight to S3 when the output directory is an s3:// prefix. With MaxRows
+// set, rows roll over into numbered part files (basename_001, basename_002, ...).
 type CSVWriter struct {
-	file       *os.File      // Only used for uncompressed output
-	xzWriter   *XZWriter     // Only used for compressed output
+	cfg        CSVWriterConfig
+	file       io.WriteCloser // Only used for uncompressed output (local file or S3 upload)
+	path       string         // Only used for uncompressed output
+	xzWriter   *XZWriter      // Only used for compressed output
 	buffer     *bufio.Writer
 	writer     *csv.Writer
 	mu         sync.Mutex
 	rowCount   int64
+	fileRows   int64 // Rows in the current part file
+	part       int   // Current part number (MaxRows only)
+	lastFlush  time.Time
 	headers    []string
+	dialect    CSVDialect
+	limiter    *WriteLimiter
 	closed     bool
 	compressed bool // Track if using compression
 }
 
 // CSVWriterConfig holds configuration for creating a CSV writer
 type CSVWriterConfig struct {
-	// Directory where the file will be created
+	// Directory where the file will be created, or an s3://bucket/prefix
 	OutputDir string
 	// Filename without extension (e.g., "customers")
 	Filename string
@@ -36,81 +62,180 @@ type CSVWriterConfig struct {
 	Headers []string
 	// Buffer size in bytes (default: 64KB)
 	BufferSize int
+	// Flush buffered rows at least this often (0 = only when the buffer fills)
+	FlushInterval time.Duration
 	// Enable xz compression (creates .csv.xz files)
 	Compress bool
-	// XZ compression preset 0-9 (default: 6). Higher = smaller but slower
+	// XZ compression preset 0-9 (default: 0, the fastest). Higher = smaller but slower
 	XZPreset int
+	// Field and line terminators (default: DefaultCSVDialect)
+	Dialect CSVDialect
+	// Paces writes to a maximum rate (nil = unlimited)
+	Limiter *WriteLimiter
+	// Rows per file before rolling over to the next part file (0 = unlimited)
+	MaxRows int64
+	// Rows the file is expected to hold, at most, sizing S3 multipart uploads (0 = unknown)
+	ExpectedRows int64
 }
 
 // NewCSVWriter creates a new streaming CSV writer.
 // The file is created immediately and headers are written.
 // If Compress is true, output is piped through xz for compression.
+// If MaxRows is set, the first file is basename_001 and later rows roll over
+// into basename_002, basename_003, ... as each file fills.
 func NewCSVWriter(cfg CSVWriterConfig) (*CSVWriter, error) {
 	// Ensure output directory exists
-	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	if !IsS3Path(cfg.OutputDir) {
+		if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	dialect := cfg.Dialect
+	if dialect.FieldTerminator == 0 {
+		dialect = DefaultCSVDialect()
+	}
+
+	cw := &CSVWriter{
+		cfg:        cfg,
+		headers:    cfg.Headers,
+		dialect:    dialect,
+		limiter:    cfg.Limiter,
+		compressed: cfg.Compress,
+	}
+	if err := cw.openFile(); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// openFile creates the next output file and writes its headers
+func (w *CSVWriter) openFile() error {
+	cfg := w.cfg
+	if cfg.MaxRows > 0 {
+		w.part++
+		cfg.Filename = ShardFilename(cfg.Filename, w.part, w.part)
 	}
 
 	// Set buffer size
 	bufSize := cfg.BufferSize
 	if bufSize <= 0 {
-		bufSize = 64 * 1024 // 64KB default
+		bufSize = defaultBufferSize
 	}
 
 	// Determine underlying writer based on compression setting
 	var underlying io.Writer
-	var file *os.File
-	var xzWriter *XZWriter
+	w.file, w.path, w.xzWriter = nil, "", nil
 
 	if cfg.Compress {
 		// Use XZ compression - pipe through external xz process
-		var err error
-		xzWriter, err = NewXZWriter(XZWriterConfig{
+		xzWriter, err := NewXZWriter(XZWriterConfig{
 			OutputDir: cfg.OutputDir,
 			Filename:  cfg.Filename,
 			Preset:    cfg.XZPreset,
+
+			ExpectedSize: expectedUploadSize(cfg.ExpectedRows, cfg.MaxRows),
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create xz writer: %w", err)
+			return fmt.Errorf("failed to create xz writer: %w", err)
 		}
+		w.xzWriter = xzWriter
 		underlying = xzWriter
+	} else if IsS3Path(cfg.OutputDir) {
+		// Direct upload (uncompressed)
+		w.path = JoinOutputPath(cfg.OutputDir, cfg.Filename+".csv")
+		upload, err := NewS3Writer(w.path, expectedUploadSize(cfg.ExpectedRows, cfg.MaxRows))
+		if err != nil {
+			return fmt.Errorf("failed to create S3 writer: %w", err)
+		}
+		w.file = upload
+		underlying = upload
 	} else {
 		// Direct file writing (uncompressed)
-		path := filepath.Join(cfg.OutputDir, cfg.Filename+".csv")
-		var err error
-		file, err = os.Create(path)
+		w.path = filepath.Join(cfg.OutputDir, cfg.Filename+".csv")
+		f, err := os.Create(w.path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create file %s: %w", path, err)
+			return fmt.Errorf("failed to create file %s: %w", w.path, err)
 		}
-		underlying = file
+		w.file = f
+		underlying = f
 	}
 
-	buffer := bufio.NewWriterSize(underlying, bufSize)
-	writer := csv.NewWriter(buffer)
+	w.buffer = bufio.NewWriterSize(underlying, bufSize)
+	w.writer = csv.NewWriter(w.buffer)
+	w.writer.Comma = w.dialect.FieldTerminator
+	w.writer.UseCRLF = w.dialect.UseCRLF
+	w.fileRows = 0
+	w.lastFlush = time.Now()
 
-	cw := &CSVWriter{
-		file:       file,
-		xzWriter:   xzWriter,
-		buffer:     buffer,
-		writer:     writer,
-		headers:    cfg.Headers,
-		compressed: cfg.Compress,
+	// Write headers
+	if len(w.headers) > 0 {
+		if err := w.writer.Write(w.headers); err != nil {
+			w.closeUnderlying()
+			return fmt.Errorf("failed to write headers: %w", err)
+		}
 	}
 
-	// Write headers
-	if len(cfg.Headers) > 0 {
-		if err := writer.Write(cfg.Headers); err != nil {
-			cw.closeUnderlying()
-			return nil, fmt.Errorf("failed to write headers: %w", err)
+	return nil
+}
+
+// closeFile flushes and closes the current output file
+func (w *CSVWriter) closeFile() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		w.closeUnderlying()
+		return fmt.Errorf("csv flush error: %w", err)
+	}
+
+	if err := w.buffer.Flush(); err != nil {
+		w.closeUnderlying()
+		return fmt.Errorf("buffer flush error: %w", err)
+	}
+
+	return w.closeUnderlying()
+}
+
+// write writes one row, first rolling over to the next part file if the
+// current one holds MaxRows rows. The caller holds the lock.
+func (w *CSVWriter) write(row []string) error {
+	if w.cfg.MaxRows > 0 && w.fileRows >= w.cfg.MaxRows {
+		if err := w.closeFile(); err != nil {
+			return err
+		}
+		if err := w.openFile(); err != nil {
+			w.closed = true // Nothing is left open to write to or close
+			return err
 		}
 	}
 
-	return cw, nil
+	if err := w.writer.Write(row); err != nil {
+		return fmt.Errorf("failed to write row: %w", err)
+	}
+	w.rowCount++
+	w.fileRows++
+
+	if w.cfg.FlushInterval > 0 && time.Since(w.lastFlush) >= w.cfg.FlushInterval {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush writes buffered rows through to the underlying file. The caller
+// holds the lock.
+func (w *CSVWriter) flush() error {
+	w.lastFlush = time.Now()
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return fmt.Errorf("csv flush error: %w", err)
+	}
+	return w.buffer.Flush()
 }
 
 // WriteRow writes a single row to the CSV file.
 // This method is thread-safe.
 func (w *CSVWriter) WriteRow(row []string) error {
+	w.limiter.Wait(1, rowBytes(row))
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
@@ -118,17 +243,20 @@ func (w *CSVWriter) WriteRow(row []string) error {
 		return fmt.Errorf("writer is closed")
 	}
 
-	if err := w.writer.Write(row); err != nil {
-		return fmt.Errorf("failed to write row: %w", err)
-	}
-	w.rowCount++
-
-	return nil
+	return w.write(row)
 }
 
 // WriteRows writes multiple rows to the CSV file.
 // This method is thread-safe.
 func (w *CSVWriter) WriteRows(rows [][]string) error {
+	if w.limiter != nil {
+		bytes := 0
+		for _, row := range rows {
+			bytes += rowBytes(row)
+		}
+		w.limiter.Wait(len(rows), bytes)
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
@@ -137,10 +265,9 @@ func (w *CSVWriter) WriteRows(rows [][]string) error {
 	}
 
 	for _, row := range rows {
-		if err := w.writer.Write(row); err != nil {
-			return fmt.Errorf("failed to write row: %w", err)
+		if err := w.write(row); err != nil {
+			return err
 		}
-		w.rowCount++
 	}
 
 	return nil
@@ -156,11 +283,7 @@ func (w *CSVWriter) Flush() error {
 		return nil
 	}
 
-	w.writer.Flush()
-	if err := w.writer.Error(); err != nil {
-		return fmt.Errorf("csv flush error: %w", err)
-	}
-	return w.buffer.Flush()
+	return w.flush()
 }
 
 // Close flushes remaining data and closes the file.
@@ -174,21 +297,10 @@ func (w *CSVWriter) Close() error {
 	}
 	w.closed = true
 
-	w.writer.Flush()
-	if err := w.writer.Error(); err != nil {
-		w.closeUnderlying()
-		return fmt.Errorf("csv flush error: %w", err)
-	}
-
-	if err := w.buffer.Flush(); err != nil {
-		w.closeUnderlying()
-		return fmt.Errorf("buffer flush error: %w", err)
-	}
-
-	return w.closeUnderlying()
+	return w.closeFile()
 }
 
-// closeUnderlying closes the underlying writer (file or xz process)
+// closeUnderlying closes the underlying writer (file, S3 upload or xz process)
 func (w *CSVWriter) closeUnderlying() error {
 	if w.compressed {
 		return w.xzWriter.Close()
@@ -203,12 +315,17 @@ func (w *CSVWriter) RowCount() int64 {
 	return w.rowCount
 }
 
-// Path returns the full path to the output file (.csv or .csv.xz)
+// Path returns the full path (or s3:// URL) of the current output file (.csv or .csv.xz)
 func (w *CSVWriter) Path() string {
 	if w.compressed {
 		return w.xzWriter.Path()
 	}
-	return w.file.Name()
+	return w.path
+}
+
+// Dialect returns the field and line terminators this writer uses
+func (w *CSVWriter) Dialect() CSVDialect {
+	return w.dialect
 }
 
 // FormatBool converts a boolean to "1" or "0" for CSV/database compatibility
@@ -252,6 +369,14 @@ func FormatFloat64(f float64) string {
 	return fmt.Sprintf("%.6f", f)
 }
 
+// FormatFloat64Ptr formats a *float64 for CSV, returning empty string for nil
+func FormatFloat64Ptr(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return FormatFloat64(*f)
+}
+
 // FormatInt64Ptr formats an *int64 for CSV, returning empty string for nil
 func FormatInt64Ptr(n *int64) string {
 	if n == nil {
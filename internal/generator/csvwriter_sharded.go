@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"sort"
 )
@@ -25,30 +26,105 @@ func ShardFilePath(outputDir, basename string, shardNum, totalShards int, compre
 	if compress {
 		ext = ".csv.xz"
 	}
-	return filepath.Join(outputDir, name+ext)
+	return JoinOutputPath(outputDir, name+ext)
 }
 
 // NewShardedCSVWriter creates a CSVWriter for a specific shard.
 // The filename will be basename_NNN where NNN is the zero-padded shard number.
 func NewShardedCSVWriter(cfg CSVWriterConfig, shardNum, totalShards int) (*CSVWriter, error) {
-	shardedCfg := CSVWriterConfig{
-		OutputDir:  cfg.OutputDir,
-		Filename:   ShardFilename(cfg.Filename, shardNum, totalShards),
-		Headers:    cfg.Headers,
-		BufferSize: cfg.BufferSize,
-		Compress:   cfg.Compress,
-		XZPreset:   cfg.XZPreset,
-	}
+	shardedCfg := cfg
+	shardedCfg.Filename = ShardFilename(cfg.Filename, shardNum, totalShards)
 	return NewCSVWriter(shardedCfg)
 }
 
+// tableWriter writes a table's rows to a single basename.csv, or split across
+// basename_NNN shards that each hold a contiguous run of rows. Shards are
+// opened one at a time, so at most one file (and xz process) is open.
+type tableWriter struct {
+	cfg     CSVWriterConfig
+	shards  int
+	rows    int
+	written int
+	shard   int
+	current *CSVWriter
+}
+
+// newTableWriter creates a writer for rows rows. With shards > 1 the rows are
+// split across that many shard files (fewer if there are fewer rows).
+func newTableWriter(cfg CSVWriterConfig, shards, rows int) (*tableWriter, error) {
+	if shards <= 1 {
+		w, err := NewCSVWriter(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &tableWriter{cfg: cfg, shards: 1, rows: rows, current: w}, nil
+	}
+
+	t := &tableWriter{cfg: cfg, shards: max(min(shards, rows), 1), rows: rows}
+	// Open the first shard up front so an empty table still gets a file
+	if err := t.openShard(1); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *tableWriter) openShard(shard int) error {
+	w, err := NewShardedCSVWriter(t.cfg, shard, t.shards)
+	if err != nil {
+		return err
+	}
+	t.shard = shard
+	t.current = w
+	return nil
+}
+
+// WriteRow writes a row, moving on to the next shard once this one is full
+func (t *tableWriter) WriteRow(row []string) error {
+	if t.shard > 0 {
+		if want := t.written*t.shards/max(t.rows, 1) + 1; want > t.shard && want <= t.shards {
+			if err := t.current.Close(); err != nil {
+				return err
+			}
+			if err := t.openShard(want); err != nil {
+				return err
+			}
+		}
+	}
+	if err := t.current.WriteRow(row); err != nil {
+		return err
+	}
+	t.written++
+	return nil
+}
+
+// Close closes the open file. It is safe to call more than once.
+func (t *tableWriter) Close() error {
+	return t.current.Close()
+}
+
+// TableDir returns the directory holding a table's files: dir/table when the
+// output was written with one subdirectory per table, otherwise dir itself
+func TableDir(dir, table string) string {
+	sub := filepath.Join(dir, table)
+	if info, err := os.Stat(sub); err == nil && info.IsDir() {
+		return sub
+	}
+	return dir
+}
+
 // FindShardedFiles finds all shard files matching the pattern basename_*.csv or basename_*.csv.xz
-// Returns the files sorted in order (001, 002, etc.)
+// in inputDir or its per-table subdirectory, including partition directories
+// below it. Returns the files sorted in order (partition, then 001, 002, etc.,
+// with a shard's MaxRows part files 001_001, 001_002, ... kept together)
 func FindShardedFiles(inputDir, basename string) ([]string, error) {
+	inputDir = TableDir(inputDir, basename)
+
 	// Try both compressed and uncompressed patterns
 	patterns := []string{
 		filepath.Join(inputDir, basename+"_*.csv.xz"),
 		filepath.Join(inputDir, basename+"_*.csv"),
+		filepath.Join(inputDir, "*", basename+"_*.csv.xz"),
+		filepath.Join(inputDir, "*", basename+"_*.csv"),
 	}
 
 	var files []string
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTableWriterShards(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		shards int
+		rows   int
+		want   map[string]int // File -> data rows
+	}{
+		{"Single", 1, 5, map[string]int{"things.csv": 5}},
+		{"Contiguous", 3, 10, map[string]int{"things_001.csv": 4, "things_002.csv": 3, "things_003.csv": 3}},
+		{"FewerRowsThanShards", 8, 2, map[string]int{"things_001.csv": 1, "things_002.csv": 1}},
+		{"Empty", 4, 0, map[string]int{"things_001.csv": 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := newTableWriter(CSVWriterConfig{OutputDir: dir, Filename: "things", Headers: []string{"id"}}, tc.shards, tc.rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tc.rows; i++ {
+				if err := w.WriteRow([]string{FormatInt(i)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tc.want) {
+				t.Fatalf("expected %d files, got %d", len(tc.want), len(entries))
+			}
+			next := 0
+			for _, e := range entries {
+				content, err := os.ReadFile(filepath.Join(dir, e.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+				if lines[0] != "id" {
+					t.Errorf("%s: expected header row, got %q", e.Name(), lines[0])
+				}
+				if got := len(lines) - 1; got != tc.want[e.Name()] {
+					t.Errorf("%s: expected %d rows, got %d", e.Name(), tc.want[e.Name()], got)
+				}
+				// Shards hold consecutive rows in order
+				for _, line := range lines[1:] {
+					if line != FormatInt(next) {
+						t.Errorf("%s: expected row %d, got %s", e.Name(), next, line)
+					}
+					next++
+				}
+			}
+		})
+	}
+}
+
+func TestFindShardedFilesPerTableDir(t *testing.T) {
+	dir := t.TempDir()
+	tableDir := filepath.Join(dir, "audit_logs")
+	for _, name := range []string{"audit_logs_002.csv", "audit_logs_001.csv", "audit_logs_txn_001.csv"} {
+		w, err := NewCSVWriter(CSVWriterConfig{OutputDir: tableDir, Filename: strings.TrimSuffix(name, ".csv"), Headers: []string{"id"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+	}
+
+	if got := TableDir(dir, "audit_logs"); got != tableDir {
+		t.Errorf("Expected TableDir %s, got %s", tableDir, got)
+	}
+	if got := TableDir(dir, "transactions"); got != dir {
+		t.Errorf("Expected a flat table to stay in %s, got %s", dir, got)
+	}
+
+	files, err := FindShardedFiles(dir, "audit_logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	if want := "audit_logs_001.csv audit_logs_002.csv audit_logs_txn_001.csv"; strings.Join(names, " ") != want {
+		t.Errorf("Expected %s, got %v", want, names)
+	}
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestCSVWriterDialect(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		dialect CSVDialect
+		want    string
+	}{
+		{"Default", CSVDialect{}, "id,name\n1,\"a,b\"\n"},
+		{"TSVWithCRLF", CSVDialect{FieldTerminator: '\t', UseCRLF: true}, "id\tname\r\n1\ta,b\r\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := NewCSVWriter(CSVWriterConfig{OutputDir: dir, Filename: "things", Headers: []string{"id", "name"}, Dialect: tc.dialect})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.WriteRow([]string{"1", "a,b"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "things.csv"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+			if d := w.Dialect(); d.FieldTerminator == 0 {
+				t.Errorf("Expected the writer to report its field terminator, got %+v", d)
+			}
+		})
+	}
+}
+
+func TestCSVWriterMaxRows(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewShardedCSVWriter(CSVWriterConfig{OutputDir: dir, Filename: "things", Headers: []string{"id"}, MaxRows: 2}, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRows([][]string{{"1"}, {"2"}, {"3"}, {"4"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.RowCount() != 5 {
+		t.Errorf("Expected 5 rows, got %d", w.RowCount())
+	}
+
+	// Parts are found in order, each with its own header
+	files, err := FindShardedFiles(dir, "things")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"things_001_001.csv", "things_001_002.csv", "things_001_003.csv"}
+	if len(files) != len(want) {
+		t.Fatalf("Expected files %v, got %v", want, files)
+	}
+	contents := []string{"id\n0\n1\n", "id\n2\n3\n", "id\n4\n"}
+	for i, file := range files {
+		if filepath.Base(file) != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], filepath.Base(file))
+		}
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != contents[i] {
+			t.Errorf("%s: expected %q, got %q", want[i], contents[i], got)
+		}
+	}
+}
+
+func TestCSVWriterFlushInterval(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewCSVWriter(CSVWriterConfig{OutputDir: dir, Filename: "things", Headers: []string{"id"}, FlushInterval: time.Nanosecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.WriteRow([]string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The row reaches the file before Close
+	got, err := os.ReadFile(filepath.Join(dir, "things.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "id\n1\n" {
+		t.Errorf("Expected the row to be flushed, got %q", got)
+	}
+}
+
+// BenchmarkCSVWriter writes transaction rows through the default entity-table
+// buffer and the larger streaming buffer, plain and xz-compressed
+func BenchmarkCSVWriter(b *testing.B) {
+	row := transactionRow(models.Transaction{
+		ID:              1,
+		AccountID:       42,
+		Type:            models.TxTypePurchase,
+		Channel:         models.ChannelPOS,
+		Status:          models.TxStatusCompleted,
+		Amount:          12345,
+		Currency:        models.CurrencyUSD,
+		BalanceAfter:    1_000_000,
+		Description:     "Purchase at Corner Store",
+		ReferenceNumber: "TXN000000000001",
+		Timestamp:       time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}, false)
+	rowBytes := int64(len(strings.Join(row, ",")) + 1)
+
+	for _, compress := range []bool{false, true} {
+		for _, size := range []int{defaultBufferSize, streamingBufferSize, 4 << 20} {
+			name := fmt.Sprintf("Buffer%dKB", size>>10)
+			if compress {
+				name += "/xz"
+			}
+			b.Run(name, func(b *testing.B) {
+				w, err := NewCSVWriter(CSVWriterConfig{
+					OutputDir:  b.TempDir(),
+					Filename:   "transactions",
+					Headers:    TransactionHeaders(),
+					BufferSize: size,
+					Compress:   compress,
+					XZPreset:   1,
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.SetBytes(rowBytes)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if err := w.WriteRow(row); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := w.Close(); err != nil {
+					b.Fatal(err)
+				}
+				b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "rows/s")
+			})
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package generator
 
 import (
+	"cmp"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,6 +20,16 @@ type CustomerGenerator struct {
 	rng     *utils.Random
 	refData *data.ReferenceData
 	config  CustomerGeneratorConfig
+
+	// Relocation draws come from their own stream, so the relocation rate
+	// leaves every other customer field unchanged
+	relocationRNG *utils.Random
+
+	// Emails handed out so far, so each customer's is unique
+	emails map[string]bool
+
+	// Cities closest to each branch, for geographic clustering
+	nearby map[int64][]data.City
 }
 
 // CustomerGeneratorConfig holds settings for customer generation
@@ -29,6 +41,19 @@ type CustomerGeneratorConfig struct {
 	BaseDate time.Time
 	// ParetoRatio: top X% of customers have high activity (default 0.2)
 	ParetoRatio float64
+	// ChurnRate is the fraction of customers suspended or closed during history (0 = none)
+	ChurnRate float64
+	// ChurnClosedRatio is the fraction of churned customers who close rather than get suspended
+	ChurnClosedRatio float64
+	// RelocationRate is the fraction of customers who move to another home branch during history (0 = none)
+	RelocationRate float64
+	// GeoClustering is the fraction of customers who live in one of the cities
+	// nearest their home branch rather than anywhere in its country (0 = none)
+	GeoClustering float64
+	// Passwords hashes customer passwords (zero value = unsalted SHA-256)
+	Passwords PasswordHasher
+	// OfflineRate is the fraction of customers without online banking (0 = none)
+	OfflineRate float64
 }
 
 // NewCustomerGenerator creates a new customer generator
@@ -36,10 +61,16 @@ func NewCustomerGenerator(rng *utils.Random, refData *data.ReferenceData, config
 	if config.ParetoRatio <= 0 {
 		config.ParetoRatio = 0.2
 	}
+	if config.BaseDate.IsZero() {
+		config.BaseDate = time.Now()
+	}
 	return &CustomerGenerator{
-		rng:     rng,
-		refData: refData,
-		config:  config,
+		rng:           rng,
+		refData:       refData,
+		relocationRNG: rng.Derive("relocations"),
+		config:        config,
+		emails:        make(map[string]bool),
+		nearby:        make(map[int64][]data.City),
 	}
 }
 
@@ -47,6 +78,37 @@ func NewCustomerGenerator(rng *utils.Random, refData *data.ReferenceData, config
 type GeneratedCustomer struct {
 	Customer models.Customer
 	Country  *data.Country
+	// StatusChangedAt is when the customer was suspended or closed (nil = always active)
+	StatusChangedAt *time.Time
+	// Relocation is when the customer moved to their current home branch (nil = never moved)
+	Relocation *BranchRelocation
+	// Devices the customer banks from on the mobile and online channels.
+	// Empty for businesses, whose staff sign in from many devices.
+	Devices []models.Device
+	// KYC is the customer's KYC submission and review (nil = never submitted or KYC not generated)
+	KYC *KYCReview
+	// Offline customers have no online banking credentials or devices and
+	// bank only at ATMs and branches
+	Offline bool
+}
+
+// BranchRelocation records a customer's move to a new home branch
+type BranchRelocation struct {
+	At         time.Time
+	FromBranch int64
+}
+
+// ActiveAt reports whether the customer could still transact at time t
+func (c GeneratedCustomer) ActiveAt(t time.Time) bool {
+	return c.StatusChangedAt == nil || t.Before(*c.StatusChangedAt)
+}
+
+// HomeBranchAt returns the customer's home branch at time t
+func (c GeneratedCustomer) HomeBranchAt(t time.Time) int64 {
+	if c.Relocation != nil && t.Before(c.Relocation.At) {
+		return c.Relocation.FromBranch
+	}
+	return c.Customer.HomeBranch
 }
 
 // GenerateCustomers creates all customers with global distribution
@@ -95,12 +157,12 @@ func (g *CustomerGenerator) generateCustomer(id int64) GeneratedCustomer {
 	homeBranch := g.pickHomeBranch(country.Code)
 
 	// Generate contact info
-	email := g.generateEmail(firstName, lastName, id)
+	email := g.generateEmail(firstName, lastName)
 	phone := g.generatePhone(country.PhoneCode)
 
 	// Generate auth data
 	username := g.generateUsername(firstName, lastName, id)
-	passwordHash := g.hashPassword(g.rng.String(12))
+	passwordHash := g.config.Passwords.Hash(g.rng.String(12))
 	pin := g.hashPIN(g.rng.NumericString(4))
 
 	customer := models.Customer{
@@ -125,10 +187,143 @@ func (g *CustomerGenerator) generateCustomer(id int64) GeneratedCustomer {
 		PasswordHash:  passwordHash,
 		PIN:           pin,
 		CreatedAt:     createdAt,
-		UpdatedAt:     time.Now(),
+		UpdatedAt:     g.config.BaseDate,
+	}
+
+	generated := GeneratedCustomer{Customer: customer, Country: country}
+	g.applyChurn(&generated)
+	g.applyRelocation(&generated)
+	g.applyClustering(&generated)
+	generated.Devices = generateDevices(g.rng)
+	g.applyOfflineBanking(&generated)
+	return generated
+}
+
+// applyOfflineBanking leaves a fraction of customers without online banking:
+// no username, password or devices to sign in from
+func (g *CustomerGenerator) applyOfflineBanking(c *GeneratedCustomer) {
+	if g.config.OfflineRate <= 0 || !g.rng.Probability(g.config.OfflineRate) {
+		return
+	}
+	c.Customer.Username, c.Customer.PasswordHash = "", ""
+	c.Devices = nil
+	c.Offline = true
+}
+
+// applyChurn suspends or closes a fraction of customers at a random point
+// after they joined, recording when their status changed
+func (g *CustomerGenerator) applyChurn(c *GeneratedCustomer) {
+	if g.config.ChurnRate <= 0 || !g.rng.Probability(g.config.ChurnRate) {
+		return
+	}
+
+	// Customers stay at least a month before churning
+	earliest := c.Customer.CreatedAt.AddDate(0, 1, 0)
+	latest := g.config.BaseDate
+	if !latest.After(earliest) {
+		return
+	}
+
+	changedAt := earliest.Add(time.Duration(g.rng.Float64() * float64(latest.Sub(earliest))))
+	if g.rng.Probability(g.config.ChurnClosedRatio) {
+		c.Customer.Status = models.CustomerStatusClosed
+	} else {
+		c.Customer.Status = models.CustomerStatusSuspended
+	}
+	c.Customer.UpdatedAt = changedAt
+	c.StatusChangedAt = &changedAt
+}
+
+// applyRelocation moves a fraction of customers to another open branch in
+// their country at a random point while they were active
+func (g *CustomerGenerator) applyRelocation(c *GeneratedCustomer) {
+	rng := g.relocationRNG
+	if g.config.RelocationRate <= 0 || !rng.Probability(g.config.RelocationRate) {
+		return
+	}
+
+	earliest := c.Customer.CreatedAt.AddDate(0, 1, 0)
+	latest := g.config.BaseDate
+	if c.StatusChangedAt != nil {
+		latest = *c.StatusChangedAt
+	}
+	if !latest.After(earliest) {
+		return
+	}
+	movedAt := earliest.Add(time.Duration(rng.Float64() * float64(latest.Sub(earliest))))
+
+	// Like pickHomeBranch, any branch will do in a country without one
+	from := c.Customer.HomeBranch
+	var domestic, candidates []int64
+	for _, b := range g.config.Branches {
+		if b.Branch.ID == from || !b.OpenAt(movedAt) {
+			continue
+		}
+		candidates = append(candidates, b.Branch.ID)
+		if b.Country.Code == c.Customer.Country {
+			domestic = append(domestic, b.Branch.ID)
+		}
+	}
+	if len(domestic) > 0 {
+		candidates = domestic
+	} else if slices.ContainsFunc(g.config.Branches, func(b GeneratedBranch) bool { return b.Country.Code == c.Customer.Country }) {
+		return // The customer's country has no other open branch
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	c.Customer.HomeBranch = candidates[rng.IntN(len(candidates))]
+	c.Relocation = &BranchRelocation{At: movedAt, FromBranch: from}
+}
+
+// nearbyCities is how many of the cities closest to a branch a clustered
+// customer may live in, the closest most likely
+const nearbyCities = 3
+
+// applyClustering moves a GeoClustering fraction of customers' addresses to a
+// city near their current home branch, so each branch's customer base is
+// concentrated around it. Customers banking in another country keep theirs.
+func (g *CustomerGenerator) applyClustering(c *GeneratedCustomer) {
+	if !g.rng.Probability(g.config.GeoClustering) {
+		return
 	}
+	cities := g.citiesNear(c.Customer.HomeBranch, c.Customer.Country)
+	if len(cities) == 0 {
+		return
+	}
+	weights := make([]int, len(cities))
+	for i := range weights {
+		weights[i] = 1 << (len(cities) - 1 - i)
+	}
+	city := cities[g.rng.WeightedPick(weights)]
+	c.Customer.City = city.City
+	c.Customer.State = city.State
+	c.Customer.PostalCode = g.generatePostalCode(c.Customer.Country, city.PostalPrefix)
+}
 
-	return GeneratedCustomer{Customer: customer, Country: country}
+// citiesNear returns the nearbyCities cities in countryCode closest to a
+// branch there, nearest first (nil if the branch is elsewhere)
+func (g *CustomerGenerator) citiesNear(branchID int64, countryCode string) []data.City {
+	if cities, ok := g.nearby[branchID]; ok {
+		return cities
+	}
+	var nearest []data.City
+	branch := branchByID(g.config.Branches, branchID)
+	if branch != nil && branch.Country.Code == countryCode {
+		cities, _ := g.refData.GetCities(countryCode)
+		nearest = slices.Clone(cities)
+		lat, lon := branch.Branch.Latitude, branch.Branch.Longitude
+		scale := math.Cos(lat * math.Pi / 180)
+		distance := func(city data.City) float64 {
+			return math.Hypot(city.Latitude-lat, (city.Longitude-lon)*scale)
+		}
+		slices.SortStableFunc(nearest, func(a, b data.City) int {
+			return cmp.Compare(distance(a), distance(b))
+		})
+		nearest = nearest[:min(len(nearest), nearbyCities)]
+	}
+	g.nearby[branchID] = nearest
+	return nearest
 }
 
 // pickCountry selects a country weighted by banking activity
@@ -149,11 +344,7 @@ func (g *CustomerGenerator) pickCity(countryCode string) data.City {
 
 // generateFirstName creates a first name based on region
 func (g *CustomerGenerator) generateFirstName(region string, isMale bool) string {
-	names := g.refData.GetFirstNames(region, isMale)
-	if len(names) == 0 {
-		// Fallback to western names
-		names = g.refData.GetFirstNames("western", isMale)
-	}
+	names := g.refData.FirstNamesForRegion(region, isMale)
 	if len(names) == 0 {
 		if isMale {
 			return "John"
@@ -165,10 +356,7 @@ func (g *CustomerGenerator) generateFirstName(region string, isMale bool) string
 
 // generateLastName creates a last name based on region
 func (g *CustomerGenerator) generateLastName(region string) string {
-	names := g.refData.GetLastNames(region)
-	if len(names) == 0 {
-		names = g.refData.GetLastNames("western")
-	}
+	names := g.refData.LastNamesForRegion(region)
 	if len(names) == 0 {
 		return "Smith"
 	}
@@ -219,14 +407,14 @@ func (g *CustomerGenerator) generateActivityScore(id int64) float64 {
 func (g *CustomerGenerator) generateDateOfBirth() time.Time {
 	// Age range 18-80
 	ageInDays := g.rng.IntRange(18*365, 80*365)
-	return time.Now().AddDate(0, 0, -ageInDays)
+	return g.config.BaseDate.AddDate(0, 0, -ageInDays)
 }
 
 // generateCreatedAt creates a customer creation date in the history period
 func (g *CustomerGenerator) generateCreatedAt() time.Time {
 	// Spread customers across 5 years of history
 	daysBack := g.rng.IntRange(1, 5*365)
-	return time.Now().AddDate(0, 0, -daysBack)
+	return g.config.BaseDate.AddDate(0, 0, -daysBack)
 }
 
 // pickHomeBranch selects a home branch, preferring same country
@@ -251,11 +439,13 @@ func (g *CustomerGenerator) pickHomeBranch(countryCode string) int64 {
 	return g.config.Branches[g.rng.IntN(len(g.config.Branches))].Branch.ID
 }
 
-// generateEmail creates a realistic email address
-func (g *CustomerGenerator) generateEmail(firstName, lastName string, id int64) string {
-	// Lowercase and clean names
-	first := strings.ToLower(strings.ReplaceAll(firstName, " ", ""))
-	last := strings.ToLower(strings.ReplaceAll(lastName, " ", ""))
+// generateEmail creates a realistic email address derived from the
+// customer's name (e.g. jane.doe47@gmail.com). An address already taken gets
+// a numeric suffix (jane.doe2@gmail.com), so emails are unique and always
+// match the name.
+func (g *CustomerGenerator) generateEmail(firstName, lastName string) string {
+	first := emailLocalPart(firstName)
+	last := emailLocalPart(lastName)
 
 	domains := []string{
 		"gmail.com",
@@ -270,17 +460,38 @@ func (g *CustomerGenerator) generateEmail(firstName, lastName string, id int64)
 
 	// Variations
 	patterns := []string{
-		"%s.%s@%s",
-		"%s%s@%s",
-		"%s.%s%d@%s",
-		"%s_%s@%s",
+		"%s.%s",
+		"%s%s",
+		"%s.%s%d",
+		"%s_%s",
 	}
 	pattern := g.rng.PickString(patterns)
 
+	var local string
 	if strings.Contains(pattern, "%d") {
-		return fmt.Sprintf(pattern, first, last, id%1000, domain)
+		local = fmt.Sprintf(pattern, first, last, g.rng.IntRange(1, 99))
+	} else {
+		local = fmt.Sprintf(pattern, first, last)
+	}
+
+	email := local + "@" + domain
+	for n := 2; g.emails[email]; n++ {
+		email = fmt.Sprintf("%s%d@%s", local, n, domain)
+	}
+	g.emails[email] = true
+	return email
+}
+
+// emailLocalPart lowercases a name and drops everything but letters and
+// digits (O'Reilly -> oreilly, van der Merwe -> vandermerwe)
+func emailLocalPart(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
 	}
-	return fmt.Sprintf(pattern, first, last, domain)
+	return b.String()
 }
 
 // generatePhone creates a phone number with country code
@@ -290,6 +501,12 @@ func (g *CustomerGenerator) generatePhone(phoneCode string) string {
 
 // generateUsername creates a unique username
 func (g *CustomerGenerator) generateUsername(firstName, lastName string, id int64) string {
+	return customerUsername(firstName, id)
+}
+
+// customerUsername is the first four letters of the first name followed by
+// the customer ID, which keeps it unique
+func customerUsername(firstName string, id int64) string {
 	first := strings.ToLower(firstName)
 	if len(first) > 4 {
 		first = first[:4]
@@ -297,12 +514,6 @@ func (g *CustomerGenerator) generateUsername(firstName, lastName string, id int6
 	return fmt.Sprintf("%s%d", first, id)
 }
 
-// hashPassword creates a SHA-256 hash of the password (simulated - not for production)
-func (g *CustomerGenerator) hashPassword(password string) string {
-	hash := sha256.Sum256([]byte(password))
-	return hex.EncodeToString(hash[:])
-}
-
 // hashPIN creates a SHA-256 hash of the PIN (simulated - not for production)
 func (g *CustomerGenerator) hashPIN(pin string) string {
 	hash := sha256.Sum256([]byte(pin))
@@ -377,31 +588,72 @@ func (g *CustomerGenerator) generatePostalCode(countryCode, prefix string) strin
 	return result
 }
 
-// WriteCustomersCSV writes customers to a CSV file (or .csv.xz if compress=true)
-func WriteCustomersCSV(customers []GeneratedCustomer, outputDir string, compress bool) error {
-	return writeCustomersCSVInternal(customers, outputDir, compress, false)
-}
-
-// WriteCustomersCSVWithProgress writes customers with progress reporting
-func WriteCustomersCSVWithProgress(customers []GeneratedCustomer, outputDir string, compress bool) error {
-	return writeCustomersCSVInternal(customers, outputDir, compress, true)
-}
-
-func writeCustomersCSVInternal(customers []GeneratedCustomer, outputDir string, compress, showProgress bool) error {
-	headers := []string{
+// CustomerHeaders returns the CSV headers for customers (and businesses, which share the table)
+func CustomerHeaders() []string {
+	return []string{
 		"id", "first_name", "last_name", "email", "phone", "date_of_birth",
 		"address_line1", "address_line2", "city", "state", "postal_code", "country",
 		"timezone", "home_branch_id", "segment", "status", "activity_score",
 		"username", "password_hash", "pin",
 		"created_at", "updated_at",
+		"kyc_status", "kyc_document_type", "kyc_verified_at",
 	}
+}
 
-	writer, err := NewCSVWriter(CSVWriterConfig{
-		OutputDir: outputDir,
-		Filename:  "customers",
-		Headers:   headers,
-		Compress:  compress,
-	})
+// customerRow formats a customer (or business) as a CSV row in CustomerHeaders order
+func customerRow(c models.Customer) []string {
+	var kycStatus, kycDocument string
+	if c.KYCStatus != nil {
+		kycStatus = string(*c.KYCStatus)
+	}
+	if c.KYCDocumentType != nil {
+		kycDocument = string(*c.KYCDocumentType)
+	}
+	return []string{
+		FormatInt64(c.ID),
+		c.FirstName,
+		c.LastName,
+		c.Email,
+		c.Phone,
+		FormatDate(c.DateOfBirth),
+		c.AddressLine1,
+		c.AddressLine2,
+		c.City,
+		c.State,
+		c.PostalCode,
+		c.Country,
+		c.Timezone,
+		FormatInt64(c.HomeBranch),
+		string(c.Segment),
+		string(c.Status),
+		FormatFloat64(c.ActivityScore),
+		c.Username,
+		c.PasswordHash,
+		c.PIN,
+		FormatTime(c.CreatedAt),
+		FormatTime(c.UpdatedAt),
+		kycStatus,
+		kycDocument,
+		FormatTimePtr(c.KYCVerifiedAt),
+	}
+}
+
+// WriteCustomersCSV writes customers to a CSV file in cfg.OutputDir (or .csv.xz at preset cfg.XZPreset if cfg.Compress),
+// split into that many shard files if shards > 1
+func WriteCustomersCSV(customers []GeneratedCustomer, cfg CSVWriterConfig, shards int) error {
+	return writeCustomersCSVInternal(customers, cfg, shards, false)
+}
+
+// WriteCustomersCSVWithProgress writes customers with progress reporting
+func WriteCustomersCSVWithProgress(customers []GeneratedCustomer, cfg CSVWriterConfig, shards int) error {
+	return writeCustomersCSVInternal(customers, cfg, shards, true)
+}
+
+func writeCustomersCSVInternal(customers []GeneratedCustomer, cfg CSVWriterConfig, shards int, showProgress bool) error {
+	headers := CustomerHeaders()
+
+	cfg.Filename, cfg.Headers = "customers", headers
+	writer, err := newTableWriter(cfg, shards, len(customers))
 	if err != nil {
 		return err
 	}
@@ -416,32 +668,7 @@ func writeCustomersCSVInternal(customers []GeneratedCustomer, outputDir string,
 	}
 
 	for i, gc := range customers {
-		c := gc.Customer
-		row := []string{
-			FormatInt64(c.ID),
-			c.FirstName,
-			c.LastName,
-			c.Email,
-			c.Phone,
-			FormatDate(c.DateOfBirth),
-			c.AddressLine1,
-			c.AddressLine2,
-			c.City,
-			c.State,
-			c.PostalCode,
-			c.Country,
-			c.Timezone,
-			FormatInt64(c.HomeBranch),
-			string(c.Segment),
-			string(c.Status),
-			FormatFloat64(c.ActivityScore),
-			c.Username,
-			c.PasswordHash,
-			c.PIN,
-			FormatTime(c.CreatedAt),
-			FormatTime(c.UpdatedAt),
-		}
-		if err := writer.WriteRow(row); err != nil {
+		if err := writer.WriteRow(customerRow(gc.Customer)); err != nil {
 			return err
 		}
 
@@ -0,0 +1,283 @@
+package generator
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestCustomerEmailsMatchNamesAndAreUnique(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(11)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 3, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 5000, Branches: branches, BaseDate: asOf,
+	}).GenerateCustomers()
+
+	seen := make(map[string]int64)
+	for _, c := range customers {
+		email := c.Customer.Email
+		if other, ok := seen[email]; ok {
+			t.Fatalf("customers %d and %d share email %s", other, c.Customer.ID, email)
+		}
+		seen[email] = c.Customer.ID
+
+		local, _, _ := strings.Cut(email, "@")
+		first, last := emailLocalPart(c.Customer.FirstName), emailLocalPart(c.Customer.LastName)
+		if !strings.HasPrefix(local, first) || !strings.Contains(local, last) {
+			t.Errorf("customer %d: email %s does not match %s %s", c.Customer.ID, email, c.Customer.FirstName, c.Customer.LastName)
+		}
+	}
+}
+
+func TestEmailLocalPart(t *testing.T) {
+	for in, want := range map[string]string{"O'Reilly": "oreilly", "van der Merwe": "vandermerwe", "Al-Shamsi": "alshamsi", "Jane": "jane"} {
+		if got := emailLocalPart(in); got != want {
+			t.Errorf("emailLocalPart(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCustomerRelocation(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(11)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 20, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 500, Branches: branches, BaseDate: asOf, ChurnRate: 0.2, RelocationRate: 1,
+	}).GenerateCustomers()
+
+	relocated := 0
+	for _, c := range customers {
+		moved := c.Relocation
+		if moved == nil {
+			continue
+		}
+		relocated++
+		if moved.FromBranch == c.Customer.HomeBranch {
+			t.Errorf("customer %d relocated to the same branch %d", c.Customer.ID, moved.FromBranch)
+		}
+		if !moved.At.After(c.Customer.CreatedAt) || !moved.At.Before(asOf) || !c.ActiveAt(moved.At) {
+			t.Errorf("customer %d relocated at %s, outside its active life", c.Customer.ID, moved.At)
+		}
+		if before := c.HomeBranchAt(moved.At.Add(-time.Second)); before != moved.FromBranch {
+			t.Errorf("customer %d: home branch %d before the move, expected %d", c.Customer.ID, before, moved.FromBranch)
+		}
+		if after := c.HomeBranchAt(moved.At); after != c.Customer.HomeBranch {
+			t.Errorf("customer %d: home branch %d after the move, expected %d", c.Customer.ID, after, c.Customer.HomeBranch)
+		}
+	}
+	if relocated < len(customers)/2 {
+		t.Errorf("only %d of %d customers relocated at rate 1", relocated, len(customers))
+	}
+}
+
+func TestCustomerRelocationKeepsOtherFields(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	branches := NewBranchGenerator(utils.NewRandom(11), refData, BranchGeneratorConfig{NumBranches: 20, BaseDate: asOf}).GenerateBranches()
+	generate := func(rate float64) []GeneratedCustomer {
+		return NewCustomerGenerator(utils.NewRandom(12), refData, CustomerGeneratorConfig{
+			NumCustomers: 300, Branches: branches, BaseDate: asOf, ChurnRate: 0.2, RelocationRate: rate,
+		}).GenerateCustomers()
+	}
+
+	stayed, moved := generate(0), generate(0.5)
+	relocated := 0
+	for i := range stayed {
+		a, b := stayed[i], moved[i]
+		if b.Relocation != nil {
+			relocated++
+			if a.Customer.HomeBranch != b.Relocation.FromBranch {
+				t.Errorf("customer %d moved from branch %d, expected %d", b.Customer.ID, b.Relocation.FromBranch, a.Customer.HomeBranch)
+			}
+			b.Customer.HomeBranch, b.Relocation = a.Customer.HomeBranch, nil
+		}
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("customer %d differs beyond its relocation:\n%+v\n%+v", a.Customer.ID, a.Customer, b.Customer)
+		}
+	}
+	if relocated == 0 {
+		t.Fatal("expected some customers to relocate")
+	}
+}
+
+func TestCustomerGeoClustering(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	branches := NewBranchGenerator(utils.NewRandom(11), refData, BranchGeneratorConfig{NumBranches: 20, BaseDate: asOf}).GenerateBranches()
+	// nearBranch counts customers living in one of the cities nearest their
+	// home branch, among those banking in their own country
+	nearBranch := func(clustering float64) float64 {
+		gen := NewCustomerGenerator(utils.NewRandom(12), refData, CustomerGeneratorConfig{
+			NumCustomers: 2000, Branches: branches, BaseDate: asOf, RelocationRate: 0.2, GeoClustering: clustering,
+		})
+		near, domestic := 0, 0
+		for _, c := range gen.GenerateCustomers() {
+			cities := gen.citiesNear(c.Customer.HomeBranch, c.Customer.Country)
+			if len(cities) == 0 {
+				continue
+			}
+			domestic++
+			if slices.ContainsFunc(cities, func(city data.City) bool { return city.City == c.Customer.City }) {
+				near++
+			}
+		}
+		if domestic == 0 {
+			t.Fatal("no customers bank in their own country")
+		}
+		return float64(near) / float64(domestic)
+	}
+
+	if got := nearBranch(1); got != 1 {
+		t.Errorf("with full clustering %.2f of customers live near their home branch, expected all", got)
+	}
+	if unclustered, half := nearBranch(0), nearBranch(0.5); half <= unclustered || half >= 1 {
+		t.Errorf("near-branch share %.2f at half clustering, expected between %.2f and 1", half, unclustered)
+	}
+}
+
+func TestOfflineCustomers(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	branches := NewBranchGenerator(utils.NewRandom(11), refData, BranchGeneratorConfig{NumBranches: 5, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(utils.NewRandom(13), refData, CustomerGeneratorConfig{
+		NumCustomers: 1000, Branches: branches, BaseDate: asOf, OfflineRate: 0.3,
+	}).GenerateCustomers()
+
+	atms := []GeneratedATM{{ATM: models.ATM{ID: 1, Status: models.ATMStatusOnline}}}
+	rng := utils.NewRandom(14)
+	offline := 0
+	for _, c := range customers {
+		if !c.Offline {
+			if c.Customer.Username == "" || c.Customer.PasswordHash == "" {
+				t.Fatalf("online customer %d has no credentials", c.Customer.ID)
+			}
+			continue
+		}
+		offline++
+		if c.Customer.Username != "" || c.Customer.PasswordHash != "" || len(c.Devices) > 0 {
+			t.Fatalf("offline customer %d has username %q, a password or devices", c.Customer.ID, c.Customer.Username)
+		}
+		for i := 0; i < 20; i++ {
+			if channel, _ := sessionChannel(rng, atms, c, asOf); channel != models.AuditChannelATM && channel != models.AuditChannelBranch {
+				t.Fatalf("offline customer %d signed in on the %s channel", c.Customer.ID, channel)
+			}
+		}
+	}
+	if offline < 240 || offline > 360 {
+		t.Errorf("%d of 1000 customers offline, expected about 300", offline)
+	}
+}
+
+func TestCustomerChurn(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(11)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 3, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 1000, Branches: branches, BaseDate: asOf, ChurnRate: 0.2, ChurnClosedRatio: 0.6,
+	}).GenerateCustomers()
+	accounts, _ := NewAccountGenerator(rng.Fork(), refData, AccountGeneratorConfig{
+		Branches: branches, BaseDate: asOf,
+	}).GenerateAccountsForCustomers(customers, 1)
+
+	churned := make(map[int64]GeneratedCustomer)
+	var closed int
+	for _, c := range customers {
+		changedAt := c.StatusChangedAt
+		if changedAt == nil {
+			if c.Customer.Status != models.CustomerStatusActive {
+				t.Errorf("customer %d is %s without a status change", c.Customer.ID, c.Customer.Status)
+			}
+			continue
+		}
+		churned[c.Customer.ID] = c
+		if c.Customer.Status == models.CustomerStatusClosed {
+			closed++
+		} else if c.Customer.Status != models.CustomerStatusSuspended {
+			t.Errorf("churned customer %d is %s", c.Customer.ID, c.Customer.Status)
+		}
+		if changedAt.Before(c.Customer.CreatedAt.AddDate(0, 1, 0)) || changedAt.After(asOf) {
+			t.Errorf("customer %d joined %s and churned %s", c.Customer.ID, c.Customer.CreatedAt, changedAt)
+		}
+		if c.ActiveAt(*changedAt) || !c.ActiveAt(changedAt.Add(-time.Second)) {
+			t.Errorf("customer %d is not active just until %s", c.Customer.ID, changedAt)
+		}
+	}
+	if n := len(churned); n < 150 || n > 250 {
+		t.Errorf("%d of 1000 customers churned, want about 200", n)
+	}
+	if ratio := float64(closed) / float64(len(churned)); ratio < 0.5 || ratio > 0.7 {
+		t.Errorf("%.2f of churned customers closed, want about 0.6", ratio)
+	}
+
+	// Their accounts are closed or frozen when the customer churns
+	for _, a := range accounts {
+		c, ok := churned[a.Account.CustomerID]
+		if !ok {
+			continue
+		}
+		want := models.AccountStatusFrozen
+		if c.Customer.Status == models.CustomerStatusClosed {
+			want = models.AccountStatusClosed
+			if a.Account.ClosedAt == nil || !a.Account.ClosedAt.Equal(*c.StatusChangedAt) {
+				t.Errorf("account %d of closed customer %d closed at %v, want %s", a.Account.ID, c.Customer.ID, a.Account.ClosedAt, c.StatusChangedAt)
+			}
+		}
+		if a.Account.Status != want || a.Account.OpenedAt.After(*c.StatusChangedAt) {
+			t.Errorf("account %d of %s customer %d is %s, opened %s", a.Account.ID, c.Customer.Status, c.Customer.ID, a.Account.Status, a.Account.OpenedAt)
+		}
+	}
+
+	// Sessions stop at the status change, which is the last audit event
+	auditGen := NewAuditGenerator(utils.NewRandom(5), refData, AuditGeneratorConfig{AvgSessionsPerCustomerPerMonth: 4})
+	id := int64(1)
+	for _, c := range churned {
+		if !c.StatusChangedAt.After(asOf.AddDate(-1, 0, 0)) {
+			continue
+		}
+		logs := auditGen.generateCustomerSessionLogs(c, asOf.AddDate(-1, 0, 0), asOf, &id)
+		last := logs[len(logs)-1].AuditLog
+		wantAction, _ := customerStatusChangeAction(c.Customer.Status)
+		if last.Action != wantAction || !last.Timestamp.Equal(*c.StatusChangedAt) {
+			t.Errorf("customer %d: last audit event is %s at %s, want %s at %s", c.Customer.ID, last.Action, last.Timestamp, wantAction, c.StatusChangedAt)
+		}
+		for _, l := range logs[:len(logs)-1] {
+			if !l.AuditLog.Timestamp.Before(*c.StatusChangedAt) {
+				t.Errorf("customer %d: %s at %s after churning at %s", c.Customer.ID, l.AuditLog.Action, l.AuditLog.Timestamp, c.StatusChangedAt)
+			}
+		}
+	}
+}
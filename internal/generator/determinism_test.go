@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// generateRowsByID generates a small data set with the given worker count and
+// returns its transaction and audit log rows, each table sorted by ID
+func generateRowsByID(t *testing.T, workers int) map[string][]string {
+	t.Helper()
+	dir := t.TempDir()
+	o, err := NewOrchestrator(OrchestratorConfig{
+		NumCustomers:                    150,
+		NumBusinesses:                   8,
+		NumBranches:                     3,
+		NumATMs:                         6,
+		YearsOfHistory:                  1,
+		OutputDir:                       dir,
+		Seed:                            7,
+		AsOfDate:                        time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		TransactionsPerCustomerPerMonth: 10,
+		PayrollDay:                      25,
+		ParetoRatio:                     0.2,
+		DeclinedTransactionRate:         0.01,
+		InsufficientFundsRate:           0.02,
+		CashbackRate:                    0.01,
+		ReversalRate:                    0.01,
+		OverdraftFee:                    3500,
+		OverdraftFeeDailyCap:            3,
+		FailedLoginRate:                 0.02,
+		NewDeviceRate:                   0.05,
+		Workers:                         workers,
+	}, OrchestratorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := o.GenerateAll(ctx); err != nil {
+		t.Fatalf("workers=%d: %v", workers, err)
+	}
+
+	tables := map[string][]string{
+		"transactions": TransactionHeaders(),
+		"audit_logs":   AuditLogHeaders(), // Includes the audit_logs_txn shards
+	}
+	rows := make(map[string][]string)
+	for table, headers := range tables {
+		files, err := FindShardedFiles(dir, table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			err := ReadCSVRows(ctx, f, headers, func(row []string) error {
+				rows[table] = append(rows[table], strings.Join(row, ","))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		slices.SortFunc(rows[table], func(a, b string) int {
+			return int(rowID(t, a) - rowID(t, b))
+		})
+	}
+	return rows
+}
+
+func rowID(t *testing.T, row string) int64 {
+	id, _, _ := strings.Cut(row, ",")
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		t.Fatalf("bad id in row %q", row)
+	}
+	return n
+}
+
+func TestGenerationIndependentOfWorkerCount(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates a full data set per worker count")
+	}
+
+	want := generateRowsByID(t, 1)
+	for table, rows := range want {
+		if len(rows) == 0 {
+			t.Fatalf("no %s generated", table)
+		}
+		for i := 1; i < len(rows); i++ {
+			if rowID(t, rows[i]) == rowID(t, rows[i-1]) {
+				t.Fatalf("%s: duplicate id %d", table, rowID(t, rows[i]))
+			}
+		}
+	}
+
+	for _, workers := range []int{4, 16} {
+		got := generateRowsByID(t, workers)
+		for table, rows := range want {
+			if len(got[table]) != len(rows) {
+				t.Errorf("workers=%d: %d %s rows, expected %d", workers, len(got[table]), table, len(rows))
+				continue
+			}
+			for i := range rows {
+				if got[table][i] != rows[i] {
+					t.Errorf("workers=%d: %s differ at row %d:\n 1 worker: %s\n %d workers: %s",
+						workers, table, i, rows[i], workers, got[table][i])
+					break
+				}
+			}
+		}
+	}
+}
+
+// TestEntitiesIndependentOfATMEvents checks that generating ATM events, which
+// draws from its own stream, leaves every other entity table unchanged
+func TestEntitiesIndependentOfATMEvents(t *testing.T) {
+	generate := func(events *ATMEventGeneratorConfig) string {
+		dir := t.TempDir()
+		o, err := NewOrchestrator(OrchestratorConfig{
+			NumCustomers:  30,
+			NumBusinesses: 3,
+			NumBranches:   2,
+			NumATMs:       4,
+			OutputDir:     dir,
+			Seed:          11,
+			AsOfDate:      time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			ATMEvents:     events,
+		}, OrchestratorOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := o.GenerateEntities(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	without := generate(nil)
+	with := generate(&ATMEventGeneratorConfig{CashCapacity: 500, ReplenishDays: 7, CashLowPercent: 20, FaultsPerYear: 4, MaintenancePerYear: 2})
+
+	if _, err := os.Stat(filepath.Join(with, "atm_events.csv")); err != nil {
+		t.Fatalf("no ATM events written: %v", err)
+	}
+	for _, table := range []string{"customers", "businesses", "accounts", "beneficiaries"} {
+		got, err := os.ReadFile(filepath.Join(with, table+".csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := os.ReadFile(filepath.Join(without, table+".csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s.csv differs when ATM events are generated", table)
+		}
+	}
+}
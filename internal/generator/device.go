@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// User agents for the mobile app and web browsers
+var (
+	mobileUserAgents = []string{
+		"BankApp/5.2.1 (iOS 17.0; iPhone14,2)",
+		"BankApp/5.2.0 (Android 14; Pixel 8)",
+		"BankApp/5.1.9 (iOS 16.5; iPhone13,4)",
+		"BankApp/5.1.8 (Android 13; Samsung S23)",
+		"BankApp/5.2.1 (iOS 17.1; iPhone15,3)",
+		"BankApp/5.2.0 (Android 14; Samsung S24)",
+		"BankApp/5.1.7 (Android 12; Pixel 6a)",
+		"BankApp/5.2.1 (iPadOS 17.0; iPad13,18)",
+	}
+	webUserAgents = []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 Safari/17.2",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
+	}
+)
+
+// userAgentsFor returns the user agent pool for a digital channel (nil otherwise)
+func userAgentsFor(channel models.AuditChannel) []string {
+	switch channel {
+	case models.AuditChannelMobile:
+		return mobileUserAgents
+	case models.AuditChannelOnline:
+		return webUserAgents
+	default:
+		return nil
+	}
+}
+
+// generateDevices picks a customer's stable device set: one or two phones and a browser
+func generateDevices(rng *utils.Random) []models.Device {
+	devices := make([]models.Device, 0, 3)
+	first := rng.IntN(len(mobileUserAgents))
+	devices = append(devices, models.Device{Channel: models.AuditChannelMobile, UserAgent: mobileUserAgents[first]})
+	if rng.Bool() {
+		second := rng.IntN(len(mobileUserAgents) - 1)
+		if second >= first {
+			second++
+		}
+		devices = append(devices, models.Device{Channel: models.AuditChannelMobile, UserAgent: mobileUserAgents[second]})
+	}
+	return append(devices, models.Device{
+		Channel:   models.AuditChannelOnline,
+		UserAgent: webUserAgents[rng.IntN(len(webUserAgents))],
+	})
+}
+
+// deviceFor returns the customer's device for a channel on the day of t.
+// Keying on the day keeps a session and that day's transactions on one device.
+func (c GeneratedCustomer) deviceFor(channel models.AuditChannel, t time.Time) (models.Device, bool) {
+	var matches []models.Device
+	for _, d := range c.Devices {
+		if d.Channel == channel {
+			matches = append(matches, d)
+		}
+	}
+	if len(matches) == 0 {
+		return models.Device{}, false
+	}
+	return matches[dailyPick(c.Customer.ID, t, 0, len(matches))], true
+}
+
+// dailyPick picks one of n options for a customer on the day of t without
+// drawing from the RNG, so every event in a day-scoped session agrees.
+// Different salts give independent picks for the same customer and day.
+func dailyPick(customerID int64, t time.Time, salt uint64, n int) int {
+	// Key on t's own calendar date, which is what day-scoped session IDs use
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+
+	// Mix the inputs so customers with two options don't switch in lockstep
+	x := uint64(day)*0x9e3779b97f4a7c15 + uint64(customerID) + salt*0xbf58476d1ce4e5b9
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	return int(x % uint64(n))
+}
+
+// unfamiliarUserAgent picks a user agent for the channel that is not one of the customer's devices
+func unfamiliarUserAgent(rng *utils.Random, channel models.AuditChannel, c GeneratedCustomer) string {
+	agents := userAgentsFor(channel)
+	known := make(map[string]bool, len(c.Devices))
+	for _, d := range c.Devices {
+		known[d.UserAgent] = true
+	}
+
+	candidates := make([]string, 0, len(agents))
+	for _, ua := range agents {
+		if !known[ua] {
+			candidates = append(candidates, ua)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = agents
+	}
+	return candidates[rng.IntN(len(candidates))]
+}
+
+// newDeviceRiskScore scores a login from an unrecognized device
+func newDeviceRiskScore(rng *utils.Random) *float64 {
+	score := rng.Float64Range(0.5, 0.9)
+	return &score
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestSessionsReuseCustomerDevices(t *testing.T) {
+	rng := utils.NewRandom(42)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+
+	gen := NewAuditGenerator(rng.Fork(), nil, AuditGeneratorConfig{
+		AvgSessionsPerCustomerPerMonth: 10,
+		NewDeviceRate:                  0.1,
+	})
+
+	var newDevices int
+	for id := int64(1); id <= 20; id++ {
+		customer := GeneratedCustomer{
+			Customer: models.Customer{ID: id, Country: "US", ActivityScore: 1},
+			Devices:  generateDevices(rng),
+		}
+		known := make(map[string]bool)
+		for _, d := range customer.Devices {
+			known[d.UserAgent] = true
+		}
+
+		nextID := int64(1)
+		for _, l := range gen.generateCustomerSessionLogs(customer, start, end, &nextID) {
+			log := l.AuditLog
+			if log.Channel != models.AuditChannelOnline && log.Channel != models.AuditChannelMobile {
+				continue
+			}
+			isLogin := log.Action == models.AuditLoginSuccess || log.Action == models.AuditLoginFailed
+			switch {
+			case known[log.UserAgent]:
+				if log.RiskScore != nil {
+					t.Errorf("customer %d: %s on a known device has risk score %v", id, log.Action, *log.RiskScore)
+				}
+			case isLogin && log.RiskScore == nil:
+				t.Errorf("customer %d: login from unknown device %q has no risk score", id, log.UserAgent)
+			case log.Action == models.AuditLoginSuccess:
+				newDevices++
+			}
+		}
+	}
+	if newDevices == 0 {
+		t.Error("expected some logins from new devices")
+	}
+}
+
+func TestDeviceForIsStableWithinDay(t *testing.T) {
+	customer := GeneratedCustomer{
+		Customer: models.Customer{ID: 7},
+		Devices:  generateDevices(utils.NewRandom(1)),
+	}
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	morning, _ := customer.deviceFor(models.AuditChannelMobile, day.Add(8*time.Hour))
+	evening, _ := customer.deviceFor(models.AuditChannelMobile, day.Add(21*time.Hour))
+	if morning != evening {
+		t.Errorf("expected the same device all day, got %q and %q", morning.UserAgent, evening.UserAgent)
+	}
+
+	if _, ok := customer.deviceFor(models.AuditChannelATM, day); ok {
+		t.Error("expected no device for the ATM channel")
+	}
+}
+
+func TestTransactionAuditEventsShareSessionContext(t *testing.T) {
+	gen := NewAuditGenerator(utils.NewRandom(42), nil, AuditGeneratorConfig{})
+	customer := GeneratedCustomer{
+		Customer: models.Customer{ID: 3, Country: "DE"},
+		Devices:  generateDevices(utils.NewRandom(3)),
+	}
+
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i, channel := range []models.TransactionChannel{models.ChannelOnline, models.ChannelATM, models.ChannelBranch} {
+		var ips, agents []string
+		for hour := 8; hour < 20; hour += 3 {
+			txn := GeneratedTransaction{
+				Transaction: models.Transaction{ID: int64(i*10 + hour), Channel: channel, Status: models.TxStatusCompleted,
+					Timestamp: day.Add(time.Duration(hour) * time.Hour)},
+				Account: GeneratedAccount{Customer: customer},
+			}
+			nextID := int64(1)
+			for _, l := range []GeneratedAuditLog{gen.createTransactionInitiatedLog(txn, &nextID), gen.createTransactionCompletedLog(txn, &nextID)} {
+				ips = append(ips, l.AuditLog.IPAddress)
+				agents = append(agents, l.AuditLog.UserAgent)
+			}
+		}
+		for j := range ips {
+			if ips[j] != ips[0] || agents[j] != agents[0] {
+				t.Errorf("%s: event %d has %s %q, session started on %s %q", channel, j, ips[j], agents[j], ips[0], agents[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// Dispute lifecycle timing: a purchase is disputed up to a month after it was
+// made, picked up for review within a few days and resolved within two weeks
+const (
+	disputeMaxFilingDelay = 30 * 24 * time.Hour
+	disputeMaxReviewDelay = 3 * 24 * time.Hour
+	disputeMinResolution  = 2 * 24 * time.Hour
+	disputeMaxResolution  = 14 * 24 * time.Hour
+)
+
+// disputeWonRate is the fraction of resolved disputes decided in the customer's favor
+const disputeWonRate = 0.6
+
+// disputeReasons and their relative weights
+var (
+	disputeReasons = []models.DisputeReason{
+		models.DisputeFraud, models.DisputeNotReceived, models.DisputeNotAsDescribed,
+		models.DisputeDuplicate, models.DisputeIncorrectAmount,
+	}
+	disputeReasonWeights = []int{35, 25, 20, 10, 10}
+)
+
+// DisputeHeaders returns the CSV headers for disputes
+func DisputeHeaders() []string {
+	return []string{
+		"id", "case_number", "transaction_id", "account_id", "customer_id", "amount", "currency",
+		"reason", "status", "filed_at", "review_started_at", "resolved_at",
+	}
+}
+
+// openDispute files a dispute case against a completed purchase and walks it
+// through review and resolution. Stages falling after the end of the history
+// are left open; ok is false when even the filing would.
+func (g *transactionCore) openDispute(purchase models.Transaction, account GeneratedAccount) (d models.Dispute, ok bool) {
+	filed := purchase.Timestamp.Add(g.rng.Duration(time.Hour, disputeMaxFilingDelay))
+	if !filed.Before(g.settings.EndDate) {
+		return models.Dispute{}, false
+	}
+	d = models.Dispute{
+		ID:            purchase.ID,
+		CaseNumber:    "DSP-" + purchase.ReferenceNumber,
+		TransactionID: purchase.ID,
+		AccountID:     purchase.AccountID,
+		CustomerID:    account.Account.CustomerID,
+		Amount:        purchase.Amount,
+		Currency:      purchase.Currency,
+		Reason:        disputeReasons[g.rng.WeightedPick(disputeReasonWeights)],
+		Status:        models.DisputeStatusFiled,
+		FiledAt:       filed,
+	}
+
+	review := filed.Add(g.rng.Duration(time.Hour, disputeMaxReviewDelay))
+	if !review.Before(g.settings.EndDate) {
+		return d, true
+	}
+	d.Status = models.DisputeStatusUnderReview
+	d.ReviewStartedAt = &review
+
+	resolved := review.Add(g.rng.Duration(disputeMinResolution, disputeMaxResolution))
+	if !resolved.Before(g.settings.EndDate) {
+		return d, true
+	}
+	d.Status = models.DisputeStatusLost
+	if g.rng.Probability(disputeWonRate) {
+		d.Status = models.DisputeStatusWon
+	}
+	d.ResolvedAt = &resolved
+	return d, true
+}
+
+// disputeRow formats a dispute as a CSV row
+func disputeRow(d models.Dispute) []string {
+	return []string{
+		FormatInt64(d.ID),
+		d.CaseNumber,
+		FormatInt64(d.TransactionID),
+		FormatInt64(d.AccountID),
+		FormatInt64(d.CustomerID),
+		FormatInt64(d.Amount),
+		string(d.Currency),
+		string(d.Reason),
+		string(d.Status),
+		FormatTime(d.FiledAt),
+		FormatTimePtr(d.ReviewStartedAt),
+		FormatTimePtr(d.ResolvedAt),
+	}
+}
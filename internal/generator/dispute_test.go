@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestDisputeLifecycle(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(17)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 3, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 40, Branches: branches, BaseDate: asOf,
+	}).GenerateCustomers()
+	accounts, _ := NewAccountGenerator(rng.Fork(), refData, AccountGeneratorConfig{
+		Branches: branches, BaseDate: asOf,
+	}).GenerateAccountsForCustomers(customers, 1)
+
+	gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       asOf.AddDate(0, -6, 0),
+		EndDate:                         asOf,
+		TransactionsPerCustomerPerMonth: 20,
+		ParetoRatio:                     0.2,
+		DisputeRate:                     0.2,
+		Accounts:                        accounts,
+	})
+	var disputes []models.Dispute
+	gen.emitDispute = func(d models.Dispute) error {
+		disputes = append(disputes, d)
+		return nil
+	}
+	txns, _ := gen.GenerateTransactionsForAccounts(accounts, 1)
+
+	byID := make(map[int64]models.Transaction, len(txns))
+	for _, gt := range txns {
+		byID[gt.Transaction.ID] = gt.Transaction
+	}
+	if len(disputes) == 0 {
+		t.Fatal("expected disputes")
+	}
+
+	stages := make(map[models.DisputeStatus]int)
+	for _, d := range disputes {
+		stages[d.Status]++
+		purchase, ok := byID[d.TransactionID]
+		if !ok || purchase.Type != models.TxTypePurchase || purchase.Status != models.TxStatusCompleted {
+			t.Fatalf("dispute %s does not reference a completed purchase", d.CaseNumber)
+		}
+		if d.AccountID != purchase.AccountID || d.Amount != purchase.Amount || d.Currency != purchase.Currency {
+			t.Errorf("dispute %s does not match purchase %d", d.CaseNumber, purchase.ID)
+		}
+		if !d.FiledAt.After(purchase.Timestamp) || !d.FiledAt.Before(asOf) {
+			t.Errorf("dispute %s filed at %s, purchase at %s", d.CaseNumber, d.FiledAt, purchase.Timestamp)
+		}
+
+		switch d.Status {
+		case models.DisputeStatusFiled:
+			if d.ReviewStartedAt != nil || d.ResolvedAt != nil {
+				t.Errorf("filed dispute %s has later stages", d.CaseNumber)
+			}
+		case models.DisputeStatusUnderReview:
+			if d.ReviewStartedAt == nil || d.ResolvedAt != nil {
+				t.Errorf("dispute %s under review has stages %v, %v", d.CaseNumber, d.ReviewStartedAt, d.ResolvedAt)
+			}
+		default:
+			if d.ReviewStartedAt == nil || d.ResolvedAt == nil {
+				t.Fatalf("resolved dispute %s is missing a stage", d.CaseNumber)
+			}
+			if !d.ReviewStartedAt.After(d.FiledAt) || !d.ResolvedAt.After(*d.ReviewStartedAt) || !d.ResolvedAt.Before(asOf) {
+				t.Errorf("dispute %s stages out of order: %s, %s, %s", d.CaseNumber, d.FiledAt, d.ReviewStartedAt, d.ResolvedAt)
+			}
+		}
+	}
+	if stages[models.DisputeStatusWon] == 0 || stages[models.DisputeStatusLost] == 0 {
+		t.Errorf("expected both won and lost disputes, got %v", stages)
+	}
+}
@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// FeeType is a kind of bank fee in the fee schedule
+type FeeType string
+
+const (
+	FeeMaintenance       FeeType = "maintenance"
+	FeeATM               FeeType = "atm"
+	FeeWire              FeeType = "wire"
+	FeeInternationalWire FeeType = "international_wire"
+	FeeOverdraft         FeeType = "overdraft"
+	FeePaperStatement    FeeType = "paper_statement"
+	FeeForeign           FeeType = "foreign"
+)
+
+// feeTypes lists the fee types in the order they are drawn from
+var feeTypes = []FeeType{FeeMaintenance, FeeATM, FeeWire, FeeOverdraft, FeePaperStatement, FeeForeign, FeeInternationalWire}
+
+// FeeRate is one fee type's entry in the fee schedule: its description, the
+// range its amount is drawn from (in cents), and how often (relative to the
+// other fee types) a fee transaction is of this type
+type FeeRate struct {
+	Description string `json:"description"`
+	MinCents    int64  `json:"min_cents"`
+	MaxCents    int64  `json:"max_cents"`
+	Weight      int    `json:"weight"`
+}
+
+// FeeSchedule maps each fee type charged by fee transactions to its rate
+type FeeSchedule map[FeeType]FeeRate
+
+// DefaultFeeSchedule returns the built-in fee schedule (weights are percentages).
+// Overdraft fees triggered by overdrawing debits are charged separately at
+// their own fixed amount. Outgoing wires are also charged the wire or
+// international wire rate; the latter is never drawn on its own by default.
+func DefaultFeeSchedule() FeeSchedule {
+	return FeeSchedule{
+		FeeMaintenance:       {Description: "Monthly Maintenance Fee", MinCents: 500, MaxCents: 1500, Weight: 35},
+		FeeATM:               {Description: "ATM Fee", MinCents: 200, MaxCents: 500, Weight: 25},
+		FeeWire:              {Description: "Wire Transfer Fee", MinCents: 1500, MaxCents: 4500, Weight: 10},
+		FeeOverdraft:         {Description: "Overdraft Fee", MinCents: 2500, MaxCents: 3500, Weight: 10},
+		FeePaperStatement:    {Description: "Paper Statement Fee", MinCents: 200, MaxCents: 500, Weight: 10},
+		FeeForeign:           {Description: "Foreign Transaction Fee", MinCents: 100, MaxCents: 3000, Weight: 10},
+		FeeInternationalWire: {Description: "International Wire Fee", MinCents: 3500, MaxCents: 6500},
+	}
+}
+
+// feePicker draws a fee type from a schedule
+type feePicker struct {
+	rates   []FeeRate
+	weights []int
+}
+
+// newFeePicker creates a picker over schedule, falling back to
+// DefaultFeeSchedule when none is configured
+func newFeePicker(schedule FeeSchedule) *feePicker {
+	if schedule == nil {
+		schedule = DefaultFeeSchedule()
+	}
+	p := &feePicker{}
+	for _, feeType := range feeTypes {
+		if rate, ok := schedule[feeType]; ok && rate.Weight > 0 {
+			p.rates = append(p.rates, rate)
+			p.weights = append(p.weights, rate.Weight)
+		}
+	}
+	return p
+}
+
+// Pick draws a fee type's rate
+func (p *feePicker) Pick(rng *utils.Random) FeeRate {
+	return p.rates[rng.WeightedPick(p.weights)]
+}
+
+// Amount draws a fee amount in cents from the rate's range
+func (r FeeRate) Amount(rng *utils.Random) int64 {
+	return rng.Int64Range(r.MinCents, r.MaxCents)
+}
+
+// feeRateOverride is a fee schedule file entry; omitted fields keep the
+// built-in rate's values
+type feeRateOverride struct {
+	Description *string `json:"description"`
+	MinCents    *int64  `json:"min_cents"`
+	MaxCents    *int64  `json:"max_cents"`
+	Weight      *int    `json:"weight"`
+}
+
+// LoadFeeScheduleFile reads a fee schedule override file and merges it over
+// DefaultFeeSchedule. Each fee type sets any of its description, amount range
+// and weight; a weight of 0 stops the fee from being charged.
+func LoadFeeScheduleFile(path string) (FeeSchedule, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fee schedule file: %w", err)
+	}
+	var overrides map[string]feeRateOverride
+	if err := json.Unmarshal(raw, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse fee schedule file: %w", err)
+	}
+
+	schedule := DefaultFeeSchedule()
+	var errs []string
+	for name, o := range overrides {
+		feeType := FeeType(strings.ToLower(name))
+		if !slices.Contains(feeTypes, feeType) {
+			errs = append(errs, fmt.Sprintf("unknown fee type %q", name))
+			continue
+		}
+		rate := schedule[feeType]
+		if o.Description != nil {
+			rate.Description = *o.Description
+		}
+		if o.MinCents != nil {
+			rate.MinCents = *o.MinCents
+		}
+		if o.MaxCents != nil {
+			rate.MaxCents = *o.MaxCents
+		}
+		if o.Weight != nil {
+			rate.Weight = *o.Weight
+		}
+		switch {
+		case rate.Weight < 0:
+			errs = append(errs, fmt.Sprintf("%s: weight %d is negative", name, rate.Weight))
+		case rate.MinCents <= 0 || rate.MaxCents < rate.MinCents:
+			errs = append(errs, fmt.Sprintf("%s: amount range %d-%d cents is invalid", name, rate.MinCents, rate.MaxCents))
+		case strings.TrimSpace(rate.Description) == "":
+			errs = append(errs, fmt.Sprintf("%s: description is empty", name))
+		}
+		schedule[feeType] = rate
+	}
+	if len(errs) == 0 && totalFeeWeight(schedule) == 0 {
+		errs = append(errs, "no fee type has a positive weight")
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("invalid fee schedule: %s", strings.Join(errs, "; "))
+	}
+	return schedule, nil
+}
+
+// feeRate returns the schedule's rate for a fee type, falling back to the
+// built-in rate for types the schedule predates
+func (g *transactionCore) feeRate(feeType FeeType) FeeRate {
+	if rate, ok := g.settings.FeeSchedule[feeType]; ok {
+		return rate
+	}
+	return DefaultFeeSchedule()[feeType]
+}
+
+func totalFeeWeight(schedule FeeSchedule) int {
+	total := 0
+	for _, rate := range schedule {
+		total += rate.Weight
+	}
+	return total
+}
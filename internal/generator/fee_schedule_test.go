@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestLoadFeeScheduleFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "fees.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	schedule, err := LoadFeeScheduleFile(write(`{
+		"Wire": {"min_cents": 2500, "max_cents": 3500},
+		"paper_statement": {"weight": 0},
+		"atm": {"description": "Out-of-Network ATM Fee"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultFeeSchedule()
+	if wire := schedule[FeeWire]; wire.MinCents != 2500 || wire.MaxCents != 3500 || wire.Weight != defaults[FeeWire].Weight {
+		t.Errorf("wire rate %+v, expected the new range with the default weight", wire)
+	}
+	if schedule[FeePaperStatement].Weight != 0 {
+		t.Error("expected paper statement fees to be switched off")
+	}
+	if atm := schedule[FeeATM]; atm.Description != "Out-of-Network ATM Fee" || atm.MinCents != defaults[FeeATM].MinCents {
+		t.Errorf("atm rate %+v, expected only the description changed", atm)
+	}
+	if schedule[FeeMaintenance] != defaults[FeeMaintenance] {
+		t.Errorf("maintenance rate %+v, expected the default", schedule[FeeMaintenance])
+	}
+
+	_, err = LoadFeeScheduleFile(write(`{"stamp": {}, "wire": {"min_cents": 500, "max_cents": 100}, "atm": {"weight": -1}}`))
+	for _, want := range []string{`unknown fee type "stamp"`, "wire: amount range 500-100", "atm: weight -1"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestFeeAmountsMatchDescriptions(t *testing.T) {
+	g := newTestTransactionGenerator(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	g.typePickers = typePickersByType(map[models.AccountType][]TransactionTypeWeight{
+		models.AccountTypeSavings: {{models.TxTypeFee, models.ChannelInternal, 1}},
+	})
+	account := GeneratedAccount{Account: models.Account{
+		ID:       1,
+		Type:     models.AccountTypeSavings,
+		Currency: "USD",
+		Balance:  1_000_000,
+		OpenedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, Customer: GeneratedCustomer{Customer: models.Customer{ActivityScore: 1}}}
+	txns, _ := g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+
+	byDescription := make(map[string]FeeRate)
+	for _, rate := range DefaultFeeSchedule() {
+		byDescription[rate.Description] = rate
+	}
+	seen := make(map[string]bool)
+	for _, gt := range txns {
+		txn := gt.Transaction
+		if txn.Type != models.TxTypeFee || txn.Status != models.TxStatusCompleted {
+			continue
+		}
+		rate, ok := byDescription[txn.Description]
+		if !ok {
+			t.Fatalf("fee description %q is not in the schedule", txn.Description)
+		}
+		if txn.Amount < rate.MinCents || txn.Amount > rate.MaxCents {
+			t.Errorf("%s of %d cents is outside %d-%d", txn.Description, txn.Amount, rate.MinCents, rate.MaxCents)
+		}
+		seen[txn.Description] = true
+	}
+	if len(seen) < 3 {
+		t.Errorf("only saw fee types %v, expected a spread", seen)
+	}
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// foreignCurrencyTypes are the account types that may be held in a foreign
+// currency. Cards, loans and the business operating accounts stay in the
+// customer's home currency.
+var foreignCurrencyTypes = []models.AccountType{
+	models.AccountTypeChecking, models.AccountTypeSavings, models.AccountTypeInvestment,
+}
+
+// ParseCurrencies parses a comma-separated list of currency codes
+// (e.g. "USD,EUR,GBP"), each of which must be supported
+func ParseCurrencies(s string) ([]models.Currency, error) {
+	var currencies []models.Currency
+	for _, part := range strings.Split(s, ",") {
+		currency := models.Currency(strings.ToUpper(strings.TrimSpace(part)))
+		if !slices.Contains(models.SupportedCurrencies, currency) {
+			return nil, fmt.Errorf("unsupported currency %q (expected one of %s)", part, currencyList(models.SupportedCurrencies))
+		}
+		if !slices.Contains(currencies, currency) {
+			currencies = append(currencies, currency)
+		}
+	}
+	return currencies, nil
+}
+
+func currencyList(currencies []models.Currency) string {
+	codes := make([]string, len(currencies))
+	for i, c := range currencies {
+		codes[i] = string(c)
+	}
+	return strings.Join(codes, ", ")
+}
+
+// accountCurrency returns the currency for a new account: the home country's,
+// or with probability ForeignCurrencyRate one of the other ForeignCurrencies
+func (g *AccountGenerator) accountCurrency(homeCode string, accountType models.AccountType) models.Currency {
+	home := g.getCurrency(homeCode)
+	if g.config.ForeignCurrencyRate <= 0 || !slices.Contains(foreignCurrencyTypes, accountType) {
+		return home
+	}
+	if !g.rng.Probability(g.config.ForeignCurrencyRate) {
+		return home
+	}
+	foreign := make([]models.Currency, 0, len(g.config.ForeignCurrencies))
+	for _, c := range g.config.ForeignCurrencies {
+		if c != home {
+			foreign = append(foreign, c)
+		}
+	}
+	if len(foreign) == 0 {
+		return home
+	}
+	return foreign[g.rng.IntN(len(foreign))]
+}
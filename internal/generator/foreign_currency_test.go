@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestAccountCurrency(t *testing.T) {
+	g := NewAccountGenerator(utils.NewRandom(1), nil, AccountGeneratorConfig{
+		ForeignCurrencyRate: 1,
+		ForeignCurrencies:   []models.Currency{models.CurrencyUSD, models.CurrencyEUR},
+	})
+	for i := 0; i < 20; i++ {
+		if got := g.accountCurrency("USD", models.AccountTypeSavings); got != models.CurrencyEUR {
+			t.Fatalf("savings account in %s, expected the only foreign currency EUR", got)
+		}
+	}
+	if got := g.accountCurrency("USD", models.AccountTypeCreditCard); got != models.CurrencyUSD {
+		t.Errorf("credit card in %s, expected the home currency", got)
+	}
+
+	g.config.ForeignCurrencyRate = 0.1
+	foreign := 0
+	for i := 0; i < 2000; i++ {
+		if g.accountCurrency("GBP", models.AccountTypeChecking) != models.CurrencyGBP {
+			foreign++
+		}
+	}
+	if foreign < 150 || foreign > 250 {
+		t.Errorf("%d of 2000 checking accounts foreign, expected about 200", foreign)
+	}
+}
+
+func TestParseCurrencies(t *testing.T) {
+	got, err := ParseCurrencies("usd, EUR,USD")
+	if err != nil || !slices.Equal(got, []models.Currency{models.CurrencyUSD, models.CurrencyEUR}) {
+		t.Errorf("ParseCurrencies = %v, %v", got, err)
+	}
+	if _, err := ParseCurrencies("USD,XYZ"); err == nil {
+		t.Error("expected an error for an unsupported currency")
+	}
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"math"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// Jitter, in degrees either way, around a city centre: branches and ATMs sit
+// within a few kilometres of it, card and online payments spread across the
+// wider metro area
+const (
+	locationJitter = 0.05
+	homeJitter     = 0.2
+)
+
+// jitterCoordinates offsets a point by up to spread degrees in each
+// direction, rounded to the six decimal places written to the CSV
+func jitterCoordinates(rng *utils.Random, lat, lon, spread float64) (float64, float64) {
+	lat = math.Max(-90, math.Min(90, lat+rng.Float64Range(-spread, spread)))
+	lon += rng.Float64Range(-spread, spread)
+	if lon > 180 {
+		lon -= 360
+	} else if lon < -180 {
+		lon += 360
+	}
+	return math.Round(lat*1e6) / 1e6, math.Round(lon*1e6) / 1e6
+}
+
+// transactionLocation returns where a transaction was made: at the ATM or
+// branch for those channels, and near the customer's home city for card and
+// online payments, except at FarFromHomeRate, when they come from a city
+// drawn by country weight (an outlier for impossible-travel detection).
+// ACH, wire and internal transactions have no location.
+func (g *transactionCore) transactionLocation(
+	channel models.TransactionChannel,
+	account GeneratedAccount,
+	branchID, atmID *int64,
+) (lat, lon *float64) {
+	switch channel {
+	case models.ChannelATM:
+		if atmID == nil {
+			return nil, nil
+		}
+		atm := atmByID(g.atms, *atmID)
+		if atm == nil {
+			return nil, nil
+		}
+		latitude, longitude := atm.ATM.Latitude, atm.ATM.Longitude
+		return &latitude, &longitude
+	case models.ChannelBranch:
+		if branchID == nil {
+			return nil, nil
+		}
+		branch := branchByID(g.branches, *branchID)
+		if branch == nil {
+			return nil, nil
+		}
+		latitude, longitude := branch.Branch.Latitude, branch.Branch.Longitude
+		return &latitude, &longitude
+	case models.ChannelPOS, models.ChannelOnline:
+		customer := account.Customer.Customer
+		countryCode, cityName := customer.Country, customer.City
+		if g.rng.Probability(g.settings.FarFromHomeRate) {
+			if country := g.refData.CountryByWeight(g.rng.IntRange(1, g.refData.TotalWeight())); country != nil {
+				if cities, ok := g.refData.GetCities(country.Code); ok && len(cities) > 0 {
+					countryCode, cityName = country.Code, cities[g.rng.IntN(len(cities))].City
+				}
+			}
+		}
+		city, ok := g.refData.GetCity(countryCode, cityName)
+		if !ok {
+			return nil, nil
+		}
+		latitude, longitude := jitterCoordinates(g.rng, city.Latitude, city.Longitude, homeJitter)
+		return &latitude, &longitude
+	}
+	return nil, nil
+}
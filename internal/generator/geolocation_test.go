@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestTransactionLocation(t *testing.T) {
+	start, end := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	refData := newTestTransactionGenerator(t, start, end).refData
+	fr, ok := refData.GetCountry("FR")
+	if !ok {
+		t.Fatal("FR missing from reference data")
+	}
+	paris, ok := refData.GetCity("FR", "Paris")
+	if !ok || paris.Latitude == 0 {
+		t.Fatal("Paris has no coordinates")
+	}
+	account := GeneratedAccount{Account: models.Account{
+		ID:         1,
+		CustomerID: 7,
+		Type:       models.AccountTypeChecking,
+		Currency:   models.CurrencyEUR,
+		Balance:    1_000_000_000,
+		OpenedAt:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, Customer: GeneratedCustomer{Customer: models.Customer{ID: 7, ActivityScore: 1, City: "Paris", Country: "FR"}, Country: fr}}
+	atm := GeneratedATM{ATM: models.ATM{ID: 1, Latitude: 48.85, Longitude: 2.3}, Country: fr}
+
+	for _, tc := range []struct {
+		name        string
+		farFromHome float64
+	}{
+		{"Home", 0},
+		{"FarFromHome", 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newTestTransactionGenerator(t, start, end)
+			g.typePickers = typePickersByType(map[models.AccountType][]TransactionTypeWeight{
+				models.AccountTypeChecking: {
+					{models.TxTypePurchase, models.ChannelPOS, 1},
+					{models.TxTypeWithdrawal, models.ChannelATM, 1},
+					{models.TxTypeBillPayment, models.ChannelACH, 1},
+				},
+			})
+			g.atms = []GeneratedATM{atm}
+			g.settings.GeoCoordinates = true
+			g.settings.FarFromHomeRate = tc.farFromHome
+			txns, _ := g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+
+			counts := make(map[models.TransactionChannel]int)
+			far := 0
+			for _, gt := range txns {
+				txn := gt.Transaction
+				counts[txn.Channel]++
+				switch txn.Channel {
+				case models.ChannelATM:
+					if txn.Latitude == nil || *txn.Latitude != atm.ATM.Latitude || *txn.Longitude != atm.ATM.Longitude {
+						t.Errorf("ATM transaction %d is not at the ATM", txn.ID)
+					}
+				case models.ChannelPOS, models.ChannelOnline:
+					if txn.Latitude == nil || txn.Longitude == nil {
+						t.Fatalf("%s transaction %d has no location", txn.Channel, txn.ID)
+					}
+					if math.Abs(*txn.Latitude-paris.Latitude) > homeJitter || math.Abs(*txn.Longitude-paris.Longitude) > homeJitter {
+						far++
+					}
+				default:
+					if txn.Latitude != nil || txn.Longitude != nil {
+						t.Errorf("%s transaction %d has a location", txn.Channel, txn.ID)
+					}
+				}
+			}
+			if counts[models.ChannelPOS] == 0 || counts[models.ChannelATM] == 0 || counts[models.ChannelACH] == 0 {
+				t.Fatalf("expected POS, ATM and ACH transactions, got %v", counts)
+			}
+			if tc.farFromHome == 0 && far > 0 {
+				t.Errorf("%d card and online transactions are away from home", far)
+			}
+			if tc.farFromHome == 1 && far == 0 {
+				t.Errorf("no card or online transactions away from home")
+			}
+		})
+	}
+
+	// Without GeoCoordinates no transaction is located
+	g := newTestTransactionGenerator(t, start, end)
+	g.atms = []GeneratedATM{atm}
+	txns, _ := g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+	for _, gt := range txns {
+		if gt.Transaction.Latitude != nil || gt.Transaction.Longitude != nil {
+			t.Fatalf("%s transaction %d has a location without GeoCoordinates", gt.Transaction.Channel, gt.Transaction.ID)
+		}
+	}
+	if row := transactionRow(txns[0].Transaction, false); len(row) != len(TransactionHeaders()) {
+		t.Errorf("row has %d columns, want %d", len(row), len(TransactionHeaders()))
+	}
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/willfong/load-generator/internal/generator/patterns"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// Granularity is the length of the periods transactions are generated in.
+// Periods never span a month boundary, so monthly events such as cashback
+// postings happen at the same points regardless of granularity.
+type Granularity string
+
+// Supported granularities
+const (
+	GranularityMonthly Granularity = "monthly" // One batch per month (default)
+	GranularityWeekly  Granularity = "weekly"
+	GranularityDaily   Granularity = "daily"
+)
+
+// ParseGranularity validates a granularity name; an empty string means monthly
+func ParseGranularity(s string) (Granularity, error) {
+	switch gr := Granularity(s); gr {
+	case "":
+		return GranularityMonthly, nil
+	case GranularityMonthly, GranularityWeekly, GranularityDaily:
+		return gr, nil
+	default:
+		return "", fmt.Errorf("invalid granularity %q (expected monthly, weekly or daily)", s)
+	}
+}
+
+// periodEnd returns the end of the period starting at start, capped at monthEnd.
+// Daily and weekly periods end at midnight so each period covers whole days.
+func (gr Granularity) periodEnd(start, monthEnd time.Time) time.Time {
+	midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	var end time.Time
+	switch gr {
+	case GranularityDaily:
+		end = midnight.AddDate(0, 0, 1)
+	case GranularityWeekly:
+		end = midnight.AddDate(0, 0, 7)
+	default:
+		end = monthEnd
+	}
+	if end.After(monthEnd) {
+		end = monthEnd
+	}
+	return end
+}
+
+// periodShare returns the fraction of a month's activity that falls in
+// [start, end), weighting each day by the pattern's weekday and day-of-month
+// multipliers so weekend dips and month-end spikes shape per-period volume.
+// hours additionally suppresses weekends (nil = no suppression), and weekend
+// days are weighted by the weekendVolume multiplier.
+func periodShare(pattern *patterns.FullPattern, hours *BusinessHours, weekendVolume float64, monthStart, monthEnd, start, end time.Time) float64 {
+	var total, share float64
+	for day := monthStart; day.Before(monthEnd); day = day.AddDate(0, 0, 1) {
+		w := pattern.DayMultiplier(day) * hours.dayFactor(day) * weekendWeight(day, weekendVolume)
+		total += w
+		if !day.Before(start) && day.Before(end) {
+			share += w
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return share / total
+}
+
+// scaleCount apportions a monthly transaction count to a period. The fractional
+// part is rounded randomly so short periods still average the right volume.
+func scaleCount(rng *utils.Random, monthlyCount int, share float64) int {
+	expected := float64(monthlyCount) * share
+	count := int(expected)
+	if rng.Probability(expected - float64(count)) {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/generator/patterns"
+)
+
+func TestParseGranularity(t *testing.T) {
+	for in, want := range map[string]Granularity{
+		"":        GranularityMonthly,
+		"monthly": GranularityMonthly,
+		"weekly":  GranularityWeekly,
+		"daily":   GranularityDaily,
+	} {
+		got, err := ParseGranularity(in)
+		if err != nil || got != want {
+			t.Errorf("ParseGranularity(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseGranularity("hourly"); err == nil {
+		t.Error("expected an error for an unknown granularity")
+	}
+}
+
+func TestPeriodsCoverMonth(t *testing.T) {
+	monthStart := time.Date(2024, 2, 10, 14, 30, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	pattern := patterns.NewDefaultFullPattern()
+
+	for _, gr := range []Granularity{GranularityMonthly, GranularityWeekly, GranularityDaily} {
+		var periods int
+		var total float64
+		for start := monthStart; start.Before(monthEnd); {
+			end := gr.periodEnd(start, monthEnd)
+			if !end.After(start) || end.After(monthEnd) {
+				t.Fatalf("%s: invalid period %s - %s", gr, start, end)
+			}
+			if end.Before(monthEnd) && (end.Hour() != 0 || end.Minute() != 0) {
+				t.Errorf("%s: expected period to end at midnight, got %s", gr, end)
+			}
+			total += periodShare(pattern, nil, 1, monthStart, monthEnd, start, end)
+			periods++
+			start = end
+		}
+
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("%s: expected period shares to sum to 1, got %f", gr, total)
+		}
+		if gr == GranularityMonthly && periods != 1 {
+			t.Errorf("monthly: expected a single period, got %d", periods)
+		}
+		if gr == GranularityDaily && periods != 30 { // 29 whole days plus the partial first and last days
+			t.Errorf("daily: expected 30 periods, got %d", periods)
+		}
+	}
+}
+
+func TestDailyGranularityFollowsWeeklyPattern(t *testing.T) {
+	monthStart := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	pattern := patterns.NewDefaultFullPattern()
+
+	day := func(d int) float64 {
+		start := monthStart.AddDate(0, 0, d-1)
+		return periodShare(pattern, nil, 1, monthStart, monthEnd, start, start.AddDate(0, 0, 1))
+	}
+
+	// Mar 6 2024 was a Wednesday and Mar 10 a Sunday; neither is a month-day spike
+	if day(10) >= day(6) {
+		t.Errorf("expected a weekend dip: Sunday share %f >= Wednesday share %f", day(10), day(6))
+	}
+}
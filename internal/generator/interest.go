@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"math"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// interestPostingTypes maps each interest-bearing account type to the
+// interest posted to it: deposit accounts earn interest on a positive
+// balance, credit cards and loans are charged it on the amount owed
+var interestPostingTypes = map[models.AccountType]models.TransactionType{
+	models.AccountTypeChecking:   models.TxTypeInterestCredit,
+	models.AccountTypeSavings:    models.TxTypeInterestCredit,
+	models.AccountTypeCreditCard: models.TxTypeInterestDebit,
+	models.AccountTypeLoan:       models.TxTypeInterestDebit,
+	models.AccountTypeMortgage:   models.TxTypeInterestDebit,
+}
+
+// transactionMix returns the transaction mix to draw from. With scheduled
+// interest postings the mix's interest rows are dropped, along with account
+// types left without any rows, so interest is only posted on schedule.
+func (s transactionSettings) transactionMix() map[models.AccountType][]TransactionTypeWeight {
+	if s.InterestPostingDay == 0 {
+		return s.TransactionMix
+	}
+	mix := s.TransactionMix
+	if mix == nil {
+		mix = DefaultTransactionTypeWeights()
+	}
+	filtered := make(map[models.AccountType][]TransactionTypeWeight, len(mix))
+	for accountType, rows := range mix {
+		var kept []TransactionTypeWeight
+		for _, row := range rows {
+			if row.Type != models.TxTypeInterestCredit && row.Type != models.TxTypeInterestDebit && row.Weight > 0 {
+				kept = append(kept, row)
+			}
+		}
+		if len(kept) > 0 {
+			filtered[accountType] = kept
+		}
+	}
+	return filtered
+}
+
+// nextInterestPosting returns the first posting date (midnight UTC on the
+// posting day of a month) after t
+func nextInterestPosting(t time.Time, day int) time.Time {
+	t = t.UTC()
+	posting := time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC)
+	if !posting.After(t) {
+		posting = posting.AddDate(0, 1, 0)
+	}
+	return posting
+}
+
+// emitDueInterest posts the account's scheduled interest falling due before
+// the given time. Each posting covers the month since the previous one (or
+// since the account opened), at the account's rate on the running balance at
+// the posting date.
+func (g *transactionCore) emitDueInterest(account GeneratedAccount, balances map[int64]int64, before time.Time) error {
+	day := g.settings.InterestPostingDay
+	txnType, ok := interestPostingTypes[account.Account.Type]
+	if day == 0 || !ok || account.Account.InterestRate <= 0 {
+		return nil
+	}
+
+	// Accounts already open when the history begins accrue since the posting
+	// before it, so a continued run posts the whole month the last one left open
+	from, ok := g.interestAccruedAt[account.Account.ID]
+	if !ok {
+		from = nextInterestPosting(g.settings.StartDate, day).AddDate(0, -1, 0)
+		if account.Account.OpenedAt.After(from) {
+			from = account.Account.OpenedAt
+		}
+	}
+	for posting := nextInterestPosting(from, day); posting.Before(before) && posting.Before(g.settings.EndDate); posting = posting.AddDate(0, 1, 0) {
+		// A posting for part of a month is prorated by time
+		fraction := min(posting.Sub(from).Hours()/posting.Sub(posting.AddDate(0, -1, 0)).Hours(), 1)
+		g.interestAccruedAt[account.Account.ID] = posting
+		balance := balances[account.Account.ID]
+		if txnType == models.TxTypeInterestDebit {
+			balance = -balance
+		}
+		amount := monthlyInterest(balance, account.Account.InterestRate, fraction)
+		from = posting
+		if amount == 0 || !account.Customer.ActiveAt(posting) {
+			continue
+		}
+		if err := g.emit(g.interestTransaction(account, txnType, balances, amount, posting), account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interestTransaction creates a completed interest posting and updates the running balance
+func (g *transactionCore) interestTransaction(
+	account GeneratedAccount,
+	txnType models.TransactionType,
+	balances map[int64]int64,
+	amount int64,
+	ts time.Time,
+) models.Transaction {
+	if isDebitType(txnType) {
+		balances[account.Account.ID] -= amount
+	} else {
+		balances[account.Account.ID] += amount
+	}
+	id := g.nextID()
+	return models.Transaction{
+		ID:              id,
+		ReferenceNumber: g.generateReferenceNumber(id, ts),
+		AccountID:       account.Account.ID,
+		Type:            txnType,
+		Status:          models.TxStatusCompleted,
+		Channel:         models.ChannelInternal,
+		Amount:          amount,
+		Currency:        account.Account.Currency,
+		BalanceAfter:    balances[account.Account.ID],
+		Description:     g.generateDescription(txnType, FeeRate{}, models.ChannelInternal, account),
+		Metadata:        "{}",
+		Timestamp:       ts,
+		PostedAt:        ts,
+		ValueDate:       ts,
+	}
+}
+
+// monthlyInterest returns a month's interest on balance at an annual rate in
+// basis points, for the given fraction of the month, rounded to the nearest
+// minor unit: a twelfth of the annual rate, so postings reconcile as
+// balance × rate / 12
+func monthlyInterest(balance int64, annualRateBps int, fraction float64) int64 {
+	if balance <= 0 || annualRateBps <= 0 || fraction <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(balance) * float64(annualRateBps) / 10000 / 12 * fraction))
+}
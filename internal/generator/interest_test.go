@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestScheduledInterestPostings(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 4, 15, 9, 0, 0, 0, time.UTC)
+	savings := GeneratedAccount{Account: models.Account{ID: 1, Type: models.AccountTypeSavings, InterestRate: 1200, Currency: models.CurrencyUSD}}
+	card := GeneratedAccount{Account: models.Account{ID: 2, Type: models.AccountTypeCreditCard, InterestRate: 2400, Currency: models.CurrencyUSD}}
+	opened := GeneratedAccount{Account: models.Account{ID: 3, Type: models.AccountTypeSavings, InterestRate: 1200, Currency: models.CurrencyUSD, OpenedAt: time.Date(2024, 2, 15, 12, 0, 0, 0, time.UTC)}}
+
+	core := newTransactionCore(nil, transactionSettings{StartDate: start, EndDate: end, InterestPostingDay: 1})
+	core.partition = newTransactionPartition(utils.NewRandom(1), nil, 100, 1)
+	core.rng = core.partition.rng
+	var posted []models.Transaction
+	core.emit = func(txn models.Transaction, _ GeneratedAccount) error {
+		posted = append(posted, txn)
+		return nil
+	}
+
+	balances := map[int64]int64{1: 1000000, 2: -100000, 3: 1000000}
+	for _, account := range []GeneratedAccount{savings, card, opened} {
+		if err := core.emitDueInterest(account, balances, end); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A whole month at 12% is 1% of the balance, compounding monthly; January
+	// is posted in full although the history starts mid-month. The account
+	// opened halfway through February earns half of it.
+	want := []struct {
+		account int64
+		txnType models.TransactionType
+		month   time.Month
+		amount  int64
+	}{
+		{1, models.TxTypeInterestCredit, time.February, 10000},
+		{1, models.TxTypeInterestCredit, time.March, 10100},
+		{1, models.TxTypeInterestCredit, time.April, 10201},
+		{2, models.TxTypeInterestDebit, time.February, 2000},
+		{2, models.TxTypeInterestDebit, time.March, 2040},
+		{2, models.TxTypeInterestDebit, time.April, 2081},
+		{3, models.TxTypeInterestCredit, time.March, 5000},
+		{3, models.TxTypeInterestCredit, time.April, 10050},
+	}
+	if len(posted) != len(want) {
+		t.Fatalf("posted %d interest transactions, want %d", len(posted), len(want))
+	}
+	for i, w := range want {
+		txn := posted[i]
+		if txn.AccountID != w.account || txn.Type != w.txnType || txn.Amount != w.amount ||
+			!txn.Timestamp.Equal(time.Date(2024, w.month, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("posting %d: %s of %d to account %d at %s, want %s of %d to account %d on %s 1",
+				i, txn.Type, txn.Amount, txn.AccountID, txn.Timestamp, w.txnType, w.amount, w.account, w.month)
+		}
+	}
+	if balances[2] != -106121 {
+		t.Errorf("card balance %d after interest charges, want -106121", balances[2])
+	}
+
+	// Interest is no longer drawn from the transaction mix
+	for accountType, rows := range core.settings.transactionMix() {
+		for _, row := range rows {
+			if row.Type == models.TxTypeInterestCredit || row.Type == models.TxTypeInterestDebit {
+				t.Errorf("%s mix still draws %s", accountType, row.Type)
+			}
+		}
+	}
+}
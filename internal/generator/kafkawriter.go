@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+)
+
+// KafkaConfig selects a Kafka topic that generated transactions are published to
+type KafkaConfig struct {
+	Brokers string // Comma-separated bootstrap brokers (host:port)
+	Topic   string
+	Only    bool // Publish instead of writing transaction CSV shards
+}
+
+// KafkaProducer publishes keyed messages through the external kcat command.
+// Messages are piped to `kcat -P` as key<TAB>value lines; a blocked pipe
+// provides backpressure when the brokers fall behind.
+type KafkaProducer struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	buffer  *bufio.Writer
+	mu      sync.Mutex
+	closed  bool
+	waitErr error         // Error from kcat process
+	waitCh  chan struct{} // Signal when kcat completes
+}
+
+// NewKafkaProducer starts a kcat producer for the configured topic
+func NewKafkaProducer(cfg KafkaConfig) (*KafkaProducer, error) {
+	if cfg.Brokers == "" || cfg.Topic == "" {
+		return nil, fmt.Errorf("kafka brokers and topic are required")
+	}
+
+	// -K sets the key delimiter so each account's messages share a partition
+	cmd := exec.Command("kcat", "-P", "-b", cfg.Brokers, "-t", cfg.Topic, "-K", "\t")
+	cmd.Stderr = os.Stderr // Surface kcat errors to user
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		stdin.Close()
+		return nil, fmt.Errorf("failed to start kcat: %w", err)
+	}
+
+	p := &KafkaProducer{
+		cmd:    cmd,
+		stdin:  stdin,
+		buffer: bufio.NewWriterSize(stdin, 64*1024),
+		waitCh: make(chan struct{}),
+	}
+
+	// Wait for kcat in background goroutine
+	go func() {
+		p.waitErr = cmd.Wait()
+		close(p.waitCh)
+	}()
+
+	return p, nil
+}
+
+// Publish queues a message keyed by key. The value must not contain newlines
+// (JSON from encoding/json never does).
+func (p *KafkaProducer) Publish(key int64, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return fmt.Errorf("producer is closed")
+	}
+
+	p.buffer.WriteString(strconv.FormatInt(key, 10))
+	p.buffer.WriteByte('\t')
+	p.buffer.Write(value)
+	if err := p.buffer.WriteByte('\n'); err != nil {
+		return fmt.Errorf("failed to publish to kcat: %w", err)
+	}
+	return nil
+}
+
+// Close flushes buffered messages and waits for kcat to deliver them and exit
+func (p *KafkaProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	flushErr := p.buffer.Flush()
+	stdinErr := p.stdin.Close()
+	<-p.waitCh
+
+	if p.waitErr != nil {
+		return fmt.Errorf("kcat process failed: %w", p.waitErr)
+	}
+	if flushErr != nil {
+		return fmt.Errorf("failed to flush to kcat: %w", flushErr)
+	}
+	if stdinErr != nil {
+		return fmt.Errorf("failed to close kcat stdin: %w", stdinErr)
+	}
+	return nil
+}
+
+// CheckKcatAvailable verifies that kcat is installed and accessible.
+// Returns nil if kcat is available, or an error with installation guidance.
+func CheckKcatAvailable() error {
+	cmd := exec.Command("kcat", "-V")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("kcat not found: %w\nInstall with: apt install kcat (Linux) or brew install kcat (macOS)", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// KYCConfig holds settings for know-your-customer verification
+type KYCConfig struct {
+	// UnverifiedRate is the fraction of customers who never submit documents
+	UnverifiedRate float64 `json:"unverified_rate"`
+	// RejectedRate is the fraction of reviewed submissions that are rejected
+	RejectedRate float64 `json:"rejected_rate"`
+	// MaxReviewDays is the longest a review takes; submissions still under
+	// review at the as-of date are pending
+	MaxReviewDays int `json:"max_review_days"`
+	// UnverifiedDailyLimit caps the daily withdraw and transfer limits, in
+	// cents, of accounts held by customers who are not verified
+	UnverifiedDailyLimit int64 `json:"unverified_daily_limit"`
+}
+
+// KYCReview records when a customer submitted KYC documents and when the
+// review decided on them
+type KYCReview struct {
+	SubmittedAt time.Time
+	DecidedAt   *time.Time // nil while pending
+}
+
+// kycDocuments are the identity documents customers submit, with relative weights
+var kycDocuments = []struct {
+	docType models.KYCDocumentType
+	weight  int
+}{
+	{models.KYCDocumentPassport, 35},
+	{models.KYCDocumentNationalID, 35},
+	{models.KYCDocumentDriversLicense, 25},
+	{models.KYCDocumentResidencePermit, 5},
+}
+
+// applyKYC gives each customer a KYC status as of asOf. Customers submit a
+// document within a few days of joining and are verified or rejected after a
+// review of up to MaxReviewDays; UnverifiedRate of them never submit, and
+// those who joined just before asOf may not have yet. rng is the KYC phase's
+// own stream, so the rest of each customer is unchanged.
+func applyKYC(rng *utils.Random, customers []GeneratedCustomer, config KYCConfig, asOf time.Time) {
+	weights := make([]int, len(kycDocuments))
+	for i, d := range kycDocuments {
+		weights[i] = d.weight
+	}
+	maxReview := time.Duration(max(config.MaxReviewDays, 1)) * 24 * time.Hour
+
+	for i := range customers {
+		c := &customers[i].Customer
+		status := models.KYCStatusUnverified
+		c.KYCStatus = &status
+		if rng.Probability(config.UnverifiedRate) {
+			continue
+		}
+
+		docType := kycDocuments[rng.WeightedPick(weights)].docType
+		submittedAt := c.CreatedAt.Add(rng.Duration(0, 72*time.Hour)).Truncate(time.Second)
+		if !submittedAt.Before(asOf) {
+			// Joined too recently to have submitted anything yet
+			continue
+		}
+		c.KYCDocumentType = &docType
+		review := &KYCReview{SubmittedAt: submittedAt}
+		customers[i].KYC = review
+
+		decidedAt := submittedAt.Add(rng.Duration(time.Hour, maxReview)).Truncate(time.Second)
+		rejected := rng.Probability(config.RejectedRate)
+		switch {
+		case !decidedAt.Before(asOf):
+			status = models.KYCStatusPending
+		case rejected:
+			status = models.KYCStatusRejected
+			review.DecidedAt = &decidedAt
+		default:
+			status = models.KYCStatusVerified
+			review.DecidedAt = &decidedAt
+			c.KYCVerifiedAt = &decidedAt
+		}
+	}
+}
+
+// kycAuditLogs records a customer's KYC submission (at their home branch)
+// and the review's decision, for those falling in [start, end). IDs are
+// taken from nextID.
+func kycAuditLogs(customer GeneratedCustomer, start, end time.Time, nextID func() int64) []models.AuditLog {
+	review := customer.KYC
+	if review == nil {
+		return nil
+	}
+	inRange := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+	customerID := customer.Customer.ID
+
+	var logs []models.AuditLog
+	if inRange(review.SubmittedAt) {
+		branchID := customer.HomeBranchAt(review.SubmittedAt)
+		id := nextID()
+		logs = append(logs, models.AuditLog{
+			ID:          id,
+			Timestamp:   review.SubmittedAt,
+			CustomerID:  &customerID,
+			SystemID:    "kyc",
+			Action:      models.AuditKYCSubmitted,
+			Outcome:     models.OutcomeSuccess,
+			Channel:     models.AuditChannelBranch,
+			BranchID:    &branchID,
+			Description: fmt.Sprintf("KYC documents submitted (%s)", *customer.Customer.KYCDocumentType),
+			RequestID:   fmt.Sprintf("REQ%d", id),
+		})
+	}
+
+	if decided := review.DecidedAt; decided != nil && inRange(*decided) {
+		id := nextID()
+		log := models.AuditLog{
+			ID:          id,
+			Timestamp:   *decided,
+			CustomerID:  &customerID,
+			SystemID:    "kyc_review",
+			Action:      models.AuditKYCVerified,
+			Outcome:     models.OutcomeSuccess,
+			Channel:     models.AuditChannelSystem,
+			Description: "KYC verification passed",
+			RequestID:   fmt.Sprintf("REQ%d", id),
+		}
+		if *customer.Customer.KYCStatus == models.KYCStatusRejected {
+			log.Action = models.AuditKYCRejected
+			log.Outcome = models.OutcomeDenied
+			log.Description = "KYC verification rejected"
+			log.FailureReason = "Document could not be verified"
+		}
+		logs = append(logs, log)
+	}
+	return logs
+}
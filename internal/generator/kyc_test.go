@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestApplyKYC(t *testing.T) {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	customers := make([]GeneratedCustomer, 2000)
+	for i := range customers {
+		customers[i].Customer = models.Customer{
+			ID:        int64(i + 1),
+			CreatedAt: asOf.AddDate(0, 0, -i%60),
+		}
+	}
+	applyKYC(utils.NewRandom(7), customers, KYCConfig{
+		UnverifiedRate: 0.1,
+		RejectedRate:   0.1,
+		MaxReviewDays:  10,
+	}, asOf)
+
+	counts := make(map[models.KYCStatus]int)
+	actions := make(map[models.AuditAction]int)
+	var lastID int64
+	nextID := func() int64 {
+		lastID++
+		return lastID
+	}
+	for _, gc := range customers {
+		c := gc.Customer
+		if c.KYCStatus == nil {
+			t.Fatalf("customer %d has no KYC status", c.ID)
+		}
+		counts[*c.KYCStatus]++
+		if (c.KYCVerifiedAt != nil) != (*c.KYCStatus == models.KYCStatusVerified) {
+			t.Errorf("customer %d is %s with verified_at %v", c.ID, *c.KYCStatus, c.KYCVerifiedAt)
+		}
+		if (gc.KYC == nil) != (*c.KYCStatus == models.KYCStatusUnverified) {
+			t.Errorf("customer %d is %s with review %v", c.ID, *c.KYCStatus, gc.KYC)
+		}
+		if gc.KYC != nil {
+			if gc.KYC.SubmittedAt.Before(c.CreatedAt) || !gc.KYC.SubmittedAt.Before(asOf) {
+				t.Errorf("customer %d submitted at %v, outside [%v, %v)", c.ID, gc.KYC.SubmittedAt, c.CreatedAt, asOf)
+			}
+			if d := gc.KYC.DecidedAt; d != nil && (!d.After(gc.KYC.SubmittedAt) || !d.Before(asOf)) {
+				t.Errorf("customer %d decided at %v", c.ID, *d)
+			}
+		}
+
+		for _, log := range kycAuditLogs(gc, asOf.AddDate(0, -3, 0), asOf, nextID) {
+			actions[log.Action]++
+			if log.CustomerID == nil || *log.CustomerID != c.ID {
+				t.Errorf("audit log %d is not for customer %d", log.ID, c.ID)
+			}
+			if log.Action == models.AuditKYCRejected && log.Outcome != models.OutcomeDenied {
+				t.Errorf("rejection %d has outcome %s", log.ID, log.Outcome)
+			}
+		}
+	}
+
+	for _, status := range []models.KYCStatus{
+		models.KYCStatusUnverified, models.KYCStatusPending, models.KYCStatusVerified, models.KYCStatusRejected,
+	} {
+		if counts[status] == 0 {
+			t.Errorf("no %s customers: %v", status, counts)
+		}
+	}
+	if actions[models.AuditKYCSubmitted] != len(customers)-counts[models.KYCStatusUnverified] {
+		t.Errorf("got %d submissions for %d reviewed customers", actions[models.AuditKYCSubmitted], len(customers)-counts[models.KYCStatusUnverified])
+	}
+	if actions[models.AuditKYCVerified] != counts[models.KYCStatusVerified] || actions[models.AuditKYCRejected] != counts[models.KYCStatusRejected] {
+		t.Errorf("decision events %v do not match statuses %v", actions, counts)
+	}
+}
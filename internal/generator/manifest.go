@@ -0,0 +1,294 @@
+package generator
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// ManifestFilename is the name of the run manifest written to the output directory
+const ManifestFilename = "manifest.json"
+
+// RNGVersion identifies how this build derives each table's random stream
+// from the seed. Bump it whenever the same seed would regenerate different
+// entities, since --continue-from relies on regenerating them exactly.
+// Version 2 derives each generation phase's stream by name.
+const RNGVersion = 2
+
+// Manifest records the parameters and results of a generation run so the
+// same data set can be reproduced later.
+type Manifest struct {
+	Seed           int64     `json:"seed"`
+	RNGVersion     int       `json:"rng_version,omitempty"`
+	GeneratedAt    time.Time `json:"generated_at"`
+	NumCustomers   int       `json:"num_customers"`
+	NumBusinesses  int       `json:"num_businesses"`
+	NumBranches    int       `json:"num_branches"`
+	NumATMs        int       `json:"num_atms"`
+	YearsOfHistory int       `json:"years_of_history"`
+	Workers        int       `json:"workers"`
+	Compress       bool      `json:"compress"`
+	CompressLevel  int       `json:"compress_level,omitempty"`
+	TableShards    int       `json:"table_shards,omitempty"`
+	MaxFileRows    int64     `json:"max_file_rows,omitempty"`
+	Avro           bool      `json:"avro,omitempty"`
+	PerTableDir    bool      `json:"per_table_dir,omitempty"`
+
+	// Entity tables generated, if limited with --only
+	Only []string `json:"only,omitempty"`
+
+	// Audit log actions written, if limited with --audit-actions or --exclude-audit-actions
+	AuditActions []string `json:"audit_actions,omitempty"`
+
+	// Transaction partitioning (month), if any
+	PartitionBy Partitioning `json:"partition_by,omitempty"`
+
+	// Transaction shards were rewritten in timestamp order
+	SortedTransactions bool `json:"sorted_transactions,omitempty"`
+
+	// History anchor; pass it back with --as-of to reproduce the run
+	AsOfDate time.Time `json:"as_of"`
+
+	// Explicit history start (--start-date), if one replaced the years-based range
+	StartDate *time.Time `json:"start_date,omitempty"`
+
+	// Transaction generation period (monthly, weekly or daily)
+	Granularity Granularity `json:"granularity,omitempty"`
+
+	// Password hash scheme (fast, sha256 or bcrypt)
+	PasswordScheme PasswordScheme `json:"password_scheme,omitempty"`
+
+	// Country weight overrides, if any were used
+	CountryWeights *data.CountryWeights `json:"country_weights,omitempty"`
+
+	// Account mix overrides, if any were used
+	AccountMix map[models.CustomerSegment]AccountMix `json:"account_mix,omitempty"`
+
+	// Business type mix override, if one was used
+	BusinessMix *BusinessMix `json:"business_mix,omitempty"`
+
+	// Transaction type mix override, if one was used
+	TransactionMix map[models.AccountType][]TransactionTypeWeight `json:"transaction_mix,omitempty"`
+
+	// Fee schedule override, if one was used
+	FeeSchedule FeeSchedule `json:"fee_schedule,omitempty"`
+
+	// Beneficiaries per customer bounds (absent = the defaults)
+	MinBeneficiaries int `json:"min_beneficiaries,omitempty"`
+	MaxBeneficiaries int `json:"max_beneficiaries,omitempty"`
+
+	// Deposit accounts opened in a foreign currency (absent = the defaults)
+	ForeignCurrency *ManifestForeignCurrency `json:"foreign_currency,omitempty"`
+
+	// Fraction of customers living near their home branch, if clustered
+	GeoClustering float64 `json:"geo_clustering,omitempty"`
+
+	// Fraction of customers generated without online banking, if any
+	OfflineCustomerRate float64 `json:"offline_customer_rate,omitempty"`
+
+	// Whether amounts are in each currency's own minor units (absent = hundredths for all)
+	MinorUnits bool `json:"minor_units,omitempty"`
+
+	// Months new accounts took to ramp up to full activity, if any
+	RampUpMonths int `json:"ramp_up_months,omitempty"`
+
+	// Day of the month interest was posted on (absent = drawn from the transaction mix)
+	InterestPostingDay int `json:"interest_posting_day,omitempty"`
+
+	// Business days each channel took to post and take value (absent = immediately)
+	SettlementLags SettlementLags `json:"settlement_lags,omitempty"`
+
+	// Note each currency's ATM withdrawals were multiples of (absent = any amount)
+	ATMDenominations ATMDenominations `json:"atm_denominations,omitempty"`
+
+	// Payees per retail account and their share of its transfers, if any
+	TransferGraph *TransferGraph `json:"transfer_graph,omitempty"`
+
+	// Whether branch transactions were kept within operating hours
+	BranchHours bool `json:"branch_hours,omitempty"`
+
+	// Whether transactions have latitude and longitude columns
+	GeoCoordinates bool `json:"geo_coordinates,omitempty"`
+
+	// KYC verification settings, if KYC was generated
+	KYC *KYCConfig `json:"kyc,omitempty"`
+
+	// Weekend volume relative to weekdays, if set
+	WeekendVolume *WeekendVolume `json:"weekend_volume,omitempty"`
+
+	// Whale accounts and their volume multiplier, if any
+	WhaleAccounts   []int64 `json:"whale_accounts,omitempty"`
+	WhaleMultiplier float64 `json:"whale_multiplier,omitempty"`
+
+	// Monthly transaction caps by account type, if any
+	MonthlyCaps MonthlyCaps `json:"monthly_caps,omitempty"`
+
+	// How personal fields were pseudonymized (tag or tokenize), if they were
+	PIIMode PIIMode `json:"pii_mode,omitempty"`
+
+	// Set when the run extended an existing data set
+	Continuation *ManifestContinuation `json:"continuation,omitempty"`
+
+	// Row counts from the run
+	Counts ManifestCounts `json:"counts"`
+}
+
+// ManifestForeignCurrency holds the foreign currency account settings
+type ManifestForeignCurrency struct {
+	Rate       float64           `json:"rate"`                 // Fraction of deposit accounts in a foreign currency
+	Currencies []models.Currency `json:"currencies,omitempty"` // Currencies they used (absent = all supported)
+}
+
+// ManifestCounts holds per-table row counts
+type ManifestCounts struct {
+	Branches      int `json:"branches"`
+	ATMs          int `json:"atms"`
+	ATMEvents     int `json:"atm_events,omitempty"`
+	Customers     int `json:"customers"`
+	Businesses    int `json:"businesses"`
+	Accounts      int `json:"accounts"`
+	Beneficiaries int `json:"beneficiaries"`
+	Transactions  int `json:"transactions"`
+	Transfers     int `json:"transfers,omitempty"`
+	Disputes      int `json:"disputes,omitempty"`
+	AuditLogs     int `json:"audit_logs"`
+}
+
+// WriteManifest writes manifest.json describing this run to the output directory
+func (o *Orchestrator) WriteManifest(result *GenerationResult) error {
+	return WriteManifestFile(o.config.OutputDir, o.manifest(result))
+}
+
+// manifest describes this run
+func (o *Orchestrator) manifest(result *GenerationResult) Manifest {
+	m := Manifest{
+		Seed:           o.config.Seed,
+		RNGVersion:     RNGVersion,
+		GeneratedAt:    time.Now().UTC(),
+		NumCustomers:   o.config.NumCustomers,
+		NumBusinesses:  o.config.NumBusinesses,
+		NumBranches:    o.config.NumBranches,
+		NumATMs:        o.config.NumATMs,
+		YearsOfHistory: o.config.YearsOfHistory,
+		Workers:        cmp.Or(o.workers, GetWorkerCount(o.config.Workers)),
+		Compress:       o.config.Compress,
+		CompressLevel:  o.config.CompressLevel,
+		TableShards:    o.config.TableShards,
+		MaxFileRows:    o.config.MaxFileRows,
+		Avro:           o.config.Avro,
+		PerTableDir:    o.config.PerTableDir,
+		PartitionBy:    o.config.PartitionBy,
+		AsOfDate:       o.config.AsOfDate,
+		Granularity:    o.config.Granularity,
+		PasswordScheme: o.config.Passwords.Scheme,
+		CountryWeights: o.config.CountryWeights,
+		AccountMix:     o.config.AccountMix,
+		BusinessMix:    o.config.BusinessMix,
+		TransactionMix: o.config.TransactionMix,
+		FeeSchedule:    o.config.FeeSchedule,
+		GeoClustering:  o.config.GeoClustering,
+		BranchHours:    o.config.BranchHours,
+		GeoCoordinates: o.config.GeoCoordinates,
+		MinorUnits:     o.config.MinorUnits,
+		RampUpMonths:   o.config.RampUpMonths,
+		SettlementLags: o.config.SettlementLags,
+		KYC:            o.config.KYC,
+		WeekendVolume:  o.config.WeekendVolume,
+		WhaleAccounts:  o.whales,
+		MonthlyCaps:    o.config.MonthlyCaps,
+		PIIMode:        o.config.PIIMode,
+		Counts: ManifestCounts{
+			Branches:      result.BranchCount,
+			ATMs:          result.ATMCount,
+			ATMEvents:     result.ATMEventCount,
+			Customers:     result.CustomerCount,
+			Businesses:    result.BusinessCount,
+			Accounts:      result.AccountCount,
+			Beneficiaries: result.BeneficiaryCount,
+			Transactions:  result.TransactionCount,
+			Transfers:     result.TransferCount,
+			Disputes:      result.DisputeCount,
+			AuditLogs:     result.AuditLogCount,
+		},
+	}
+	if len(o.whales) > 0 {
+		m.WhaleMultiplier = o.config.WhaleMultiplier
+	}
+	if o.config.Only != nil {
+		m.Only = o.config.Only.Tables()
+	}
+	m.AuditActions = o.config.AuditActions.Actions()
+	m.ATMDenominations = o.config.ATMDenominations
+	m.OfflineCustomerRate = o.config.OfflineCustomerRate
+	m.MinBeneficiaries, m.MaxBeneficiaries = o.config.MinBeneficiaries, o.config.MaxBeneficiaries
+	m.ForeignCurrency = &ManifestForeignCurrency{Rate: o.config.ForeignCurrencyRate, Currencies: o.config.ForeignCurrencies}
+	if o.config.TransferGraph.Payees > 0 {
+		graph := o.config.TransferGraph
+		m.TransferGraph = &graph
+	}
+	m.SortedTransactions = o.sortsTransactions()
+	m.InterestPostingDay = o.config.InterestPostingDay
+	if c := o.config.Continuation; c != nil {
+		m.Continuation = &ManifestContinuation{
+			From:         c.Dir,
+			EntitySeed:   c.EntitySeed,
+			EntityAsOf:   c.EntityAsOf,
+			EntityYears:  c.EntityYears,
+			HistoryStart: c.Manifest.AsOfDate,
+		}
+	}
+	if !o.config.StartDate.IsZero() {
+		start := o.config.StartDate
+		m.StartDate = &start
+	}
+	return m
+}
+
+// WriteManifestFile writes a manifest to outputDir/manifest.json
+func WriteManifestFile(outputDir string, m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	data = append(data, '\n')
+
+	if IsS3Path(outputDir) {
+		upload, err := NewS3Writer(JoinOutputPath(outputDir, ManifestFilename), 0)
+		if err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		_, err = upload.Write(data)
+		if closeErr := upload.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		return nil
+	}
+
+	path := filepath.Join(outputDir, ManifestFilename)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// ReadManifest reads manifest.json from an output directory
+func ReadManifest(outputDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(outputDir, ManifestFilename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// indexBytesPerAccount estimates the shared account index's memory per account
+const indexBytesPerAccount = 768
+
+// workerBaseBytes covers a worker's write buffers and other fixed state
+const workerBaseBytes = 32 << 20
+
+// ParseMemorySize parses a size such as "8GB" or "512MB" (B, KB, MB and GB
+// are accepted; a bare number is bytes)
+func ParseMemorySize(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	unit := 1.0
+	for _, u := range byteUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v, unit = strings.TrimSpace(strings.TrimSuffix(v, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory size %q (expected e.g. 8GB or 512MB)", s)
+	}
+	return int64(n * unit), nil
+}
+
+// formatMemorySize formats a byte count for display
+func formatMemorySize(bytes int64) string {
+	if bytes >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	}
+	return fmt.Sprintf("%.0f MB", float64(bytes)/(1<<20))
+}
+
+// AvailableMemory returns the memory the system reports as available to new
+// work (MemAvailable in /proc/meminfo), or 0 where that isn't known
+func AvailableMemory() int64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb << 10
+		}
+	}
+	return 0
+}
+
+// indexMemory estimates the shared account index's memory for numAccounts accounts
+func indexMemory(numAccounts int) int64 {
+	return int64(numAccounts) * indexBytesPerAccount
+}
+
+// WorkersForMemory returns how many workers fit in budget bytes alongside the
+// account index for numAccounts accounts (at least 1)
+func WorkersForMemory(budget int64, numAccounts int) int {
+	return max(int((budget-indexMemory(numAccounts))/workerBaseBytes), 1)
+}
+
+// workerCount returns the worker count for transaction and audit log
+// generation: the configured count, reduced with a warning if the workers and
+// the account index would exceed MaxMemory (less what the entities already
+// use) or, without MaxMemory, the system's available memory
+func (o *Orchestrator) workerCount() int {
+	if o.workers > 0 {
+		return o.workers
+	}
+	o.workers = GetWorkerCount(o.config.Workers)
+
+	budget := o.config.MaxMemory
+	if budget > 0 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		budget -= int64(stats.HeapInuse)
+	} else {
+		budget = AvailableMemory()
+		if budget == 0 {
+			return o.workers
+		}
+	}
+
+	if fit := WorkersForMemory(budget, len(o.accounts)); fit < o.workers {
+		fmt.Printf("Warning: reducing workers from %d to %d; each needs about %s and %s is available after the index of %d accounts\n",
+			o.workers, fit, formatMemorySize(workerBaseBytes), formatMemorySize(max(budget-indexMemory(len(o.accounts)), 0)), len(o.accounts))
+		o.workers = fit
+	}
+	return o.workers
+}
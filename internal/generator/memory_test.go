@@ -0,0 +1,38 @@
+package generator
+
+import "testing"
+
+func TestParseMemorySize(t *testing.T) {
+	for in, want := range map[string]int64{"8GB": 8 << 30, "512 mb": 512 << 20, "1.5GB": 3 << 29, "4096": 4096} {
+		if got, err := ParseMemorySize(in); err != nil || got != want {
+			t.Errorf("ParseMemorySize(%q) = %d, %v; expected %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "lots", "-1GB", "0"} {
+		if _, err := ParseMemorySize(in); err == nil {
+			t.Errorf("ParseMemorySize(%q): expected an error", in)
+		}
+	}
+}
+
+func TestWorkerCountCappedByMemory(t *testing.T) {
+	accounts := make([]GeneratedAccount, 100_000)
+	index := indexMemory(len(accounts))
+
+	if got := WorkersForMemory(index+3*workerBaseBytes+workerBaseBytes/2, len(accounts)); got != 3 {
+		t.Errorf("WorkersForMemory = %d, expected 3", got)
+	}
+	if got := WorkersForMemory(index, len(accounts)); got != 1 {
+		t.Errorf("WorkersForMemory = %d, expected at least 1", got)
+	}
+
+	// The budget the workers get is what's left after the entities in memory
+	o := &Orchestrator{accounts: accounts, config: OrchestratorConfig{Workers: 16, MaxMemory: 1 << 40}}
+	if got := o.workerCount(); got != 16 {
+		t.Errorf("workerCount = %d with a large budget, expected 16", got)
+	}
+	o = &Orchestrator{accounts: accounts, config: OrchestratorConfig{Workers: 16, MaxMemory: 1}}
+	if got := o.workerCount(); got != 1 {
+		t.Errorf("workerCount = %d with a tiny budget, expected 1", got)
+	}
+}
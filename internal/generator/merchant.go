@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"github.com/willfong/load-generator/internal/generator/patterns"
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// everydayMerchants are where most customers spend
+var everydayMerchants = []string{
+	"AMAZON", "WALMART", "TARGET", "STARBUCKS", "UBER",
+	"NETFLIX", "SPOTIFY", "APPLE", "GOOGLE", "DOORDASH",
+	"COSTCO", "WHOLE FOODS", "CVS PHARMACY", "SHELL GAS",
+	"MCDONALDS", "SUBWAY", "HOME DEPOT", "BEST BUY",
+}
+
+// merchantProfile shapes card spending for a customer segment: where they
+// shop and how purchase sizes split. The size fields are cumulative
+// probabilities; whatever is left over is a luxury purchase.
+type merchantProfile struct {
+	merchants []string
+	small     float64 // P(small)
+	medium    float64 // P(small or medium)
+	large     float64 // P(small, medium or large)
+}
+
+// defaultMerchantProfile is the neutral profile for customers without a
+// retail segment (e.g. business owners)
+var defaultMerchantProfile = merchantProfile{merchants: everydayMerchants, small: 0.5, medium: 0.85, large: 1}
+
+// merchantProfiles holds the profiles that differ from the default
+var merchantProfiles = map[models.CustomerSegment]merchantProfile{
+	models.SegmentRegular: {merchants: everydayMerchants, small: 0.55, medium: 0.9, large: 1},
+	models.SegmentPremium: {
+		merchants: []string{
+			"AMAZON", "TARGET", "STARBUCKS", "UBER", "NETFLIX", "APPLE",
+			"WHOLE FOODS", "TRADER JOES", "NORDSTROM", "SEPHORA", "LULULEMON",
+			"DELTA AIR LINES", "MARRIOTT", "OPENTABLE", "EQUINOX", "CRATE & BARREL",
+		},
+		small: 0.4, medium: 0.78, large: 0.98,
+	},
+	models.SegmentPrivate: {
+		merchants: []string{
+			"WHOLE FOODS", "APPLE", "UBER BLACK", "NET-A-PORTER", "NEIMAN MARCUS",
+			"SAKS FIFTH AVENUE", "LOUIS VUITTON", "TIFFANY & CO", "HERMES",
+			"FOUR SEASONS", "RITZ-CARLTON", "EMIRATES", "NOBU", "SOTHEBYS",
+		},
+		small: 0.2, medium: 0.5, large: 0.85,
+	},
+}
+
+// merchantProfileFor returns the spending profile for a segment
+func merchantProfileFor(segment models.CustomerSegment) merchantProfile {
+	if p, ok := merchantProfiles[segment]; ok {
+		return p
+	}
+	return defaultMerchantProfile
+}
+
+// purchaseDistribution picks a purchase size distribution for draw r in [0, 1)
+func (p merchantProfile) purchaseDistribution(amounts *patterns.TransactionTypeAmounts, r float64) *patterns.AmountDistribution {
+	switch {
+	case r < p.small:
+		return amounts.SmallPurchase
+	case r < p.medium:
+		return amounts.MediumPurchase
+	case r < p.large:
+		return amounts.LargePurchase
+	default:
+		return amounts.LuxuryPurchase
+	}
+}
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/willfong/load-generator/internal/generator/patterns"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestMerchantProfilesShiftWithSegment(t *testing.T) {
+	amounts := patterns.NewTransactionTypeAmounts()
+	rng := utils.NewRandom(7)
+
+	meanPurchase := func(segment models.CustomerSegment) float64 {
+		profile := merchantProfileFor(segment)
+		var total int64
+		const n = 20000
+		for i := 0; i < n; i++ {
+			dist := profile.purchaseDistribution(amounts, rng.Float64())
+			total += dist.GenerateAmount(rng.Float64(), rng.NormalFloat64())
+		}
+		return float64(total) / n
+	}
+
+	regular := meanPurchase(models.SegmentRegular)
+	premium := meanPurchase(models.SegmentPremium)
+	private := meanPurchase(models.SegmentPrivate)
+	if !(regular < premium && premium < private) {
+		t.Errorf("Expected mean purchase to rise with segment, got regular=%.0f premium=%.0f private=%.0f", regular, premium, private)
+	}
+
+	for segment, p := range merchantProfiles {
+		if !(0 <= p.small && p.small <= p.medium && p.medium <= p.large && p.large <= 1) {
+			t.Errorf("%s: size probabilities not cumulative: %+v", segment, p)
+		}
+		if len(p.merchants) == 0 {
+			t.Errorf("%s: no merchants", segment)
+		}
+	}
+
+	// Segments without a profile of their own get the neutral default
+	if got := merchantProfileFor(models.SegmentCorporate); got.small != defaultMerchantProfile.small || got.large != 1 {
+		t.Errorf("Expected the default profile for corporate customers, got %+v", got)
+	}
+}
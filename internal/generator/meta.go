@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SchemaVersion identifies the CSV layout this build writes and the database
+// schema it imports into. Bump it whenever a table's columns change.
+const SchemaVersion = 4
+
+// MetaFilename is the name of the schema metadata file written to the output directory
+const MetaFilename = "_meta.csv"
+
+// Meta records which schema and generator version produced an output directory
+type Meta struct {
+	SchemaVersion    int
+	GeneratorVersion string
+	GeneratedAt      time.Time
+}
+
+// MetaHeaders returns the CSV headers for the metadata file
+func MetaHeaders() []string {
+	return []string{"schema_version", "generator_version", "generated_at"}
+}
+
+// WriteMeta writes _meta.csv recording this build's schema version
+func (o *Orchestrator) WriteMeta() error {
+	return WriteMetaFile(o.config.OutputDir, Meta{
+		SchemaVersion:    SchemaVersion,
+		GeneratorVersion: o.config.GeneratorVersion,
+		GeneratedAt:      time.Now().UTC(),
+	})
+}
+
+// WriteMetaFile writes a metadata file to outputDir/_meta.csv. It is never
+// compressed so tools can check it before deciding how to read the rest.
+func WriteMetaFile(outputDir string, m Meta) error {
+	writer, err := NewCSVWriter(CSVWriterConfig{
+		OutputDir: outputDir,
+		Filename:  "_meta",
+		Headers:   MetaHeaders(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", MetaFilename, err)
+	}
+	defer writer.Close()
+
+	if err := writer.WriteRow([]string{FormatInt(m.SchemaVersion), m.GeneratorVersion, FormatTime(m.GeneratedAt)}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", MetaFilename, err)
+	}
+	return writer.Close()
+}
+
+// ReadMeta reads _meta.csv from an output directory. The error wraps
+// fs.ErrNotExist when the directory predates the metadata file.
+func ReadMeta(ctx context.Context, outputDir string) (*Meta, error) {
+	var m *Meta
+	err := ReadCSVRows(ctx, filepath.Join(outputDir, MetaFilename), MetaHeaders(), func(row []string) error {
+		version, err := strconv.Atoi(row[0])
+		if err != nil {
+			return fmt.Errorf("invalid schema_version %q: %w", row[0], err)
+		}
+		generatedAt, err := time.Parse("2006-01-02 15:04:05", row[2])
+		if err != nil {
+			return fmt.Errorf("invalid generated_at %q: %w", row[2], err)
+		}
+		m = &Meta{SchemaVersion: version, GeneratorVersion: row[1], GeneratedAt: generatedAt}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", MetaFilename, err)
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%s has no rows", MetaFilename)
+	}
+	return m, nil
+}
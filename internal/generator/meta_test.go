@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestMetaRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	if _, err := ReadMeta(ctx, dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a not-exist error for a directory without %s, got %v", MetaFilename, err)
+	}
+
+	want := Meta{SchemaVersion: SchemaVersion, GeneratorVersion: "v1.2.3", GeneratedAt: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)}
+	if err := WriteMetaFile(dir, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadMeta(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != want {
+		t.Errorf("read %+v, expected %+v", *got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// Amount ranges and distributions are written in hundredths of a unit
+// (cents). With MinorUnits set, generated amounts are stored in each
+// currency's own minor units instead: whole yen for JPY, fils for KWD.
+
+// minorUnits returns the number of decimal places the currency's amounts
+// carry, from the currency reference table (2 for unknown currencies)
+func minorUnits(currency models.Currency) int {
+	return utils.GetCurrency(string(currency)).DecimalPlaces
+}
+
+// toMinorUnits converts an amount in hundredths to the currency's minor units
+func toMinorUnits(cents int64, currency models.Currency) int64 {
+	return rescaleMinorUnits(cents, 2, minorUnits(currency))
+}
+
+// convertMinorUnits converts an amount in from's minor units to to's at par,
+// for a leg posted to an account in another currency
+func convertMinorUnits(amount int64, from, to models.Currency) int64 {
+	return rescaleMinorUnits(amount, minorUnits(from), minorUnits(to))
+}
+
+// rescaleMinorUnits moves amount from one number of decimal places to
+// another, rounding half away from zero. A nonzero amount stays nonzero, so
+// a 35 cent charge is still ¥1 rather than free.
+func rescaleMinorUnits(amount int64, from, to int) int64 {
+	for ; from < to; from++ {
+		amount *= 10
+	}
+	if from == to || amount == 0 {
+		return amount
+	}
+	divisor := int64(1)
+	for ; from > to; from-- {
+		divisor *= 10
+	}
+	negative := amount < 0
+	if negative {
+		amount = -amount
+	}
+	scaled := max((amount+divisor/2)/divisor, 1)
+	if negative {
+		return -scaled
+	}
+	return scaled
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestToMinorUnits(t *testing.T) {
+	for _, tc := range []struct {
+		cents    int64
+		currency models.Currency
+		want     int64
+	}{
+		{100000, models.CurrencyJPY, 1000},   // ¥1000, not ¥100000
+		{3549, models.CurrencyJPY, 35},       // rounded to whole yen
+		{3550, models.CurrencyJPY, 36},       // half away from zero
+		{-500000, models.CurrencyJPY, -5000}, // amounts owed keep their sign
+		{20, models.CurrencyJPY, 1},          // a nonzero charge stays nonzero
+		{0, models.CurrencyJPY, 0},
+		{3500, models.CurrencyUSD, 3500},
+		{3500, "KWD", 35000},
+	} {
+		if got := toMinorUnits(tc.cents, tc.currency); got != tc.want {
+			t.Errorf("toMinorUnits(%d, %s) = %d, want %d", tc.cents, tc.currency, got, tc.want)
+		}
+	}
+	if got := convertMinorUnits(1000, models.CurrencyUSD, models.CurrencyJPY); got != 10 {
+		t.Errorf("$10.00 moves a JPY balance by %d, want 10", got)
+	}
+	if got := convertMinorUnits(10, models.CurrencyJPY, models.CurrencyUSD); got != 1000 {
+		t.Errorf("¥10 moves a USD balance by %d, want 1000", got)
+	}
+}
+
+func TestJPYAccountInWholeYen(t *testing.T) {
+	customer := GeneratedCustomer{
+		Customer: models.Customer{ID: 1, Segment: models.SegmentPremium, CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Country:  &data.Country{Code: "JP", Name: "Japan", Currency: "JPY"},
+	}
+	generate := func(minorUnits bool) models.Account {
+		g := NewAccountGenerator(utils.NewRandom(7), nil, AccountGeneratorConfig{
+			BaseDate:   time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+			MinorUnits: minorUnits,
+		})
+		return g.generateAccount(1, customer, models.AccountTypeChecking).Account
+	}
+	cents, yen := generate(false), generate(true)
+
+	if yen.Currency != models.CurrencyJPY {
+		t.Fatalf("account in %s, expected JPY", yen.Currency)
+	}
+	for _, f := range []struct {
+		name       string
+		cents, yen int64
+	}{
+		{"balance", cents.Balance, yen.Balance},
+		{"overdraft limit", cents.OverdraftLimit, yen.OverdraftLimit},
+		{"daily withdraw limit", cents.DailyWithdrawLimit, yen.DailyWithdrawLimit},
+		{"daily transfer limit", cents.DailyTransferLimit, yen.DailyTransferLimit},
+	} {
+		if want := toMinorUnits(f.cents, models.CurrencyJPY); f.yen != want {
+			t.Errorf("%s = ¥%d, want ¥%d (%d hundredths)", f.name, f.yen, want, f.cents)
+		}
+	}
+}
+
+func TestJPYTransactionAmounts(t *testing.T) {
+	usd := GeneratedAccount{Account: models.Account{ID: 1, Type: models.AccountTypeChecking, Currency: models.CurrencyUSD}}
+	jpy := GeneratedAccount{Account: models.Account{ID: 2, Type: models.AccountTypeChecking, Currency: models.CurrencyJPY, OverdraftLimit: 50000}}
+	newCore := func(minorUnits bool) *transactionCore {
+		core := newTransactionCore(nil, transactionSettings{
+			OverdraftFee: 3500,
+			MinorUnits:   minorUnits,
+			Accounts:     NewAccountIndex([]GeneratedAccount{usd, jpy}, nil),
+		})
+		core.partition = newTransactionPartition(utils.NewRandom(3), nil, 100, 1)
+		core.rng = core.partition.rng
+		return &core
+	}
+	legacy, core := newCore(false), newCore(true)
+
+	// The same draws, stored as whole yen
+	for _, txnType := range []models.TransactionType{models.TxTypePurchase, models.TxTypeWithdrawal, models.TxTypeSalary, models.TxTypeCashback} {
+		for i := 0; i < 20; i++ {
+			cents := legacy.generateAmount(txnType, "", FeeRate{}, jpy, 0, time.Time{})
+			yen := core.generateAmount(txnType, "", FeeRate{}, jpy, 0, time.Time{})
+			if want := toMinorUnits(cents, models.CurrencyJPY); yen != want {
+				t.Fatalf("%s of %d hundredths stored as ¥%d, want ¥%d", txnType, cents, yen, want)
+			}
+		}
+	}
+
+	// Overdraft fees are charged in yen
+	balances := map[int64]int64{2: -100}
+	debit := models.Transaction{ID: 9, AccountID: 2, Type: models.TxTypeWithdrawal, Status: models.TxStatusCompleted, Amount: 200, Currency: models.CurrencyJPY}
+	if fee, ok := core.overdraftFee(jpy, balances, debit); !ok || fee.Amount != 35 {
+		t.Errorf("overdraft fee %d (charged %t), want ¥35", fee.Amount, ok)
+	}
+
+	// A $10.00 transfer to a JPY account credits ¥10
+	balances[2] = 0
+	transfer := models.Transaction{ID: 10, AccountID: 1, Type: models.TxTypeTransferOut, Status: models.TxStatusCompleted, Amount: 1000, Currency: models.CurrencyUSD}
+	if leg := core.counterpartyTransaction(transfer, 2, balances); leg.BalanceAfter != 10 {
+		t.Errorf("JPY balance after a $10.00 transfer is %d, want 10", leg.BalanceAfter)
+	}
+
+	// Wires convert into the payee currency's minor units: $100.00 is about ¥15000
+	wire := &wireTransfer{International: true, currency: models.CurrencyJPY}
+	wire.convert(utils.NewRandom(1), models.CurrencyUSD, 10000, true)
+	if want := 100 * fxRate(models.CurrencyUSD, models.CurrencyJPY); math.Abs(float64(wire.FX.Amount)-want) > want*fxSpread+1 {
+		t.Errorf("$100.00 converted to ¥%d, want about ¥%.0f", wire.FX.Amount, want)
+	}
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// MonthlyCaps limits the transactions an account of each type generates in a
+// month, so activity, type and variance multipliers can't compound into
+// runaway counts. Types without a cap are unlimited.
+type MonthlyCaps map[models.AccountType]int
+
+// cappableAccountTypes are the account types --monthly-caps accepts
+var cappableAccountTypes = []models.AccountType{
+	models.AccountTypeChecking, models.AccountTypeSavings, models.AccountTypeCreditCard,
+	models.AccountTypeLoan, models.AccountTypeMortgage, models.AccountTypeInvestment,
+	models.AccountTypeBusiness, models.AccountTypeMerchant, models.AccountTypePayroll,
+}
+
+// ParseMonthlyCaps parses a --monthly-caps value such as
+// "merchant=500,business=200"
+func ParseMonthlyCaps(s string) (MonthlyCaps, error) {
+	caps := make(MonthlyCaps)
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid monthly cap entry %q (expected type=count)", part)
+		}
+		accountType := models.AccountType(strings.ToLower(strings.TrimSpace(name)))
+		if !slices.Contains(cappableAccountTypes, accountType) {
+			return nil, fmt.Errorf("unknown account type %q in monthly caps", name)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid %s monthly cap %q (expected a positive count)", name, value)
+		}
+		caps[accountType] = limit
+	}
+	return caps, nil
+}
+
+// String formats the caps as a --monthly-caps value
+func (c MonthlyCaps) String() string {
+	parts := make([]string, 0, len(c))
+	for _, accountType := range cappableAccountTypes {
+		if limit, ok := c[accountType]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%d", accountType, limit))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// apply limits an account's monthly count to its type's cap, scaled by
+// multiplier (a whale's volume multiplier, else 1)
+func (c MonthlyCaps) apply(accountType models.AccountType, count int, multiplier float64) int {
+	limit, ok := c[accountType]
+	if !ok {
+		return count
+	}
+	return min(count, max(int(float64(limit)*multiplier), 1))
+}
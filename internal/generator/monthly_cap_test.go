@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestParseMonthlyCaps(t *testing.T) {
+	caps, err := ParseMonthlyCaps("Merchant=500, business=200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (MonthlyCaps{models.AccountTypeMerchant: 500, models.AccountTypeBusiness: 200}); !maps.Equal(caps, want) {
+		t.Errorf("got %v, want %v", caps, want)
+	}
+	if got, err := ParseMonthlyCaps(caps.String()); err != nil || !maps.Equal(got, caps) {
+		t.Errorf("String() does not round-trip: %q -> %v, %v", caps.String(), got, err)
+	}
+
+	for _, bad := range []string{"merchant", "vault=10", "merchant=x", "merchant=0", "merchant=-5"} {
+		if _, err := ParseMonthlyCaps(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestMonthlyCapsLimitCounts(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestTransactionGenerator(t, start, start.AddDate(0, 1, 0))
+	g.settings.TransactionsPerCustomerPerMonth = 100
+	g.settings.MonthlyCaps = MonthlyCaps{models.AccountTypeMerchant: 50}
+	g.settings.WhaleMultiplier = 3
+	g.whales = map[int64]bool{2: true}
+
+	customer := GeneratedCustomer{Customer: models.Customer{ActivityScore: 1}}
+	merchant := GeneratedAccount{Account: models.Account{ID: 1, Type: models.AccountTypeMerchant}, Customer: customer}
+	whale := GeneratedAccount{Account: models.Account{ID: 2, Type: models.AccountTypeMerchant}, Customer: customer}
+	checking := GeneratedAccount{Account: models.Account{ID: 3, Type: models.AccountTypeChecking}, Customer: customer}
+	for i := 0; i < 20; i++ {
+		if n := g.calculateMonthlyTransactionCount(merchant, start); n != 50 {
+			t.Fatalf("expected capped merchant count 50, got %d", n)
+		}
+		if n := g.calculateMonthlyTransactionCount(whale, start); n != 150 {
+			t.Fatalf("expected whale cap scaled to 150, got %d", n)
+		}
+		if n := g.calculateMonthlyTransactionCount(checking, start); n <= 50 {
+			t.Fatalf("expected uncapped checking count above 50, got %d", n)
+		}
+	}
+
+	var accounts []GeneratedAccount
+	for range 10 {
+		accounts = append(accounts, merchant, checking)
+	}
+	uncapped := EstimateTransactionCount(accounts, start, 12, 100, nil, 0)
+	capped := EstimateTransactionCount(accounts, start, 12, 100, g.settings.MonthlyCaps, 0)
+	if want := int64(float64((10*100+10*50)*12) * 1.5); capped != want || uncapped <= capped {
+		t.Errorf("expected estimate %d with caps (below %d without), got %d", want, uncapped, capped)
+	}
+}
@@ -1,17 +1,20 @@
 package generator
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
 	"github.com/willfong/load-generator/internal/utils"
 )
 
 // Orchestrator coordinates all entity generators for bulk data generation.
 type Orchestrator struct {
-	rng     *utils.Random
+	rng     *utils.Random // Root stream; each phase derives its own from it by name
 	refData *data.ReferenceData
 	config  OrchestratorConfig
 	verbose bool
@@ -23,6 +26,31 @@ type Orchestrator struct {
 	customers  []GeneratedCustomer
 	businesses []GeneratedBusiness
 	accounts   []GeneratedAccount
+
+	// Wire beneficiaries per customer, paid by international wires
+	wireBeneficiaries map[int64][]WireBeneficiary
+
+	// pii rewrites personal fields as entities are written (nil = disabled)
+	pii *Pseudonymizer
+
+	// workers is the worker count once workerCount has settled it
+	workers int
+
+	// index is the shared account index, built on first use
+	index *AccountIndex
+
+	// whales are the whale account IDs once GenerateTransactions has chosen them
+	whales []int64
+
+	// lastTransactionAuditID is the highest audit ID GenerateTransactions
+	// reserved for transaction audit events; session audit logs follow it
+	lastTransactionAuditID int64
+
+	// transactionAuditIDBase numbers the transaction audit events from the
+	// transaction IDs. pendingTransactionAudit is set when GenerateTransactions
+	// left those events for GenerateAuditLogs to read back from the shards.
+	transactionAuditIDBase  int64
+	pendingTransactionAudit bool
 }
 
 // OrchestratorConfig holds settings for the orchestrator
@@ -35,37 +63,208 @@ type OrchestratorConfig struct {
 	OutputDir     string
 	Seed          int64
 
+	// CountryWeights optionally overrides built-in country weights (nil = defaults)
+	CountryWeights *data.CountryWeights
+
+	// AccountMix optionally overrides optional account-type probabilities per segment (nil = defaults)
+	AccountMix map[models.CustomerSegment]AccountMix
+
+	// Foreign currency accounts
+	ForeignCurrencyRate float64           // Fraction of deposit accounts in a foreign currency (0 = none)
+	ForeignCurrencies   []models.Currency // Currencies foreign accounts use (nil = all supported)
+
+	// BusinessMix optionally overrides the fraction of each business type (nil = defaults)
+	BusinessMix *BusinessMix
+
+	// AsOfDate anchors the history period in place of the current time (zero = now)
+	AsOfDate time.Time
+
+	// StartDate starts transaction and session history at this date instead of
+	// YearsOfHistory before AsOfDate (zero = years-based). YearsOfHistory is
+	// then rounded up to cover the range, for entity dates.
+	StartDate time.Time
+
 	// Transaction generation settings
 	TransactionsPerCustomerPerMonth int
 	PayrollDay                      int     // Day of month for payroll (1-31)
 	ParetoRatio                     float64 // 0.2 = 20% accounts generate 80% volume
 	DeclinedTransactionRate         float64 // 0.0-1.0
+	DeclineRetryRate                float64 // Fraction of declined purchases retried successfully (0 = none)
 	InsufficientFundsRate           float64 // 0.0-1.0
+	CashbackRate                    float64 // Credit card cashback on prior month's purchases (0 = disabled)
+	ReversalRate                    float64 // Fraction of completed debits later reversed (0 = none)
+	OverdraftFee                    int64   // Fee in cents per overdrawing checking debit (0 = none)
+	OverdraftFeeDailyCap            int     // Most overdraft fees per account per day (0 = no cap)
+
+	// BusinessHours suppresses off-hours activity per account type (nil = none)
+	BusinessHours map[models.AccountType]BusinessHours
+
+	// TransactionMix optionally overrides the transaction type weights per account type (nil = defaults)
+	TransactionMix map[models.AccountType][]TransactionTypeWeight
+
+	// FeeSchedule optionally overrides the fee types charged by fee transactions (nil = defaults)
+	FeeSchedule FeeSchedule
+
+	// InternationalWireRate is the fraction of outgoing wires paying one of the
+	// customer's wire beneficiaries abroad (0 = all domestic)
+	InternationalWireRate float64
+
+	// FarFromHomeRate is the fraction of card and online transactions located
+	// in a random city rather than near the customer's home
+	FarFromHomeRate float64
+
+	// GeoCoordinates writes where each transaction was made in its latitude
+	// and longitude columns (false = the columns are left out)
+	GeoCoordinates bool
+
+	// BranchHours keeps branch transactions within the branches' operating
+	// hours, in their local time
+	BranchHours bool
+
+	// WeekendVolume is retail and business transaction volume on weekends
+	// relative to weekdays (nil = weekends weighted as weekdays)
+	WeekendVolume *WeekendVolume
+
+	// Whale accounts generate WhaleMultiplier times their usual volume:
+	// WhaleAccountIDs if set (a continuation's), else WhaleAccounts chosen by SelectWhaleAccounts
+	WhaleAccounts   int
+	WhaleAccountIDs []int64
+	WhaleMultiplier float64
+
+	// Most transactions per account per month, by account type (nil = no caps)
+	MonthlyCaps MonthlyCaps
+
+	// MinorUnits stores balances, limits and amounts in each currency's own
+	// minor units (whole yen for JPY) instead of hundredths for every currency
+	MinorUnits bool
+
+	// SettlementLags is how many business days each channel takes to post and
+	// take value (nil = immediately)
+	SettlementLags SettlementLags
+
+	// ATMDenominations is the note each currency's ATM withdrawals are
+	// multiples of (nil = any amount)
+	ATMDenominations ATMDenominations
+
+	// TransferGraph gives retail accounts stable payees among other customers
+	// that transfers go to and come from (zero value = own accounts only)
+	TransferGraph TransferGraph
+
+	// RampUpMonths is how many months a new account takes to reach its full
+	// transaction volume, starting from a fraction in its opening month (0 = none)
+	RampUpMonths int
+
+	// InterestPostingDay is the day of the month (1-28) each interest-bearing
+	// account is posted its interest for the month before (0 = interest is
+	// drawn at random from the transaction mix)
+	InterestPostingDay int
+
+	// Period transactions are generated in (empty = monthly)
+	Granularity Granularity
+
+	// Customer lifecycle settings
+	ChurnRate        float64 // Fraction of customers suspended/closed during history (0 = none)
+	ChurnClosedRatio float64 // Fraction of churned customers who close rather than get suspended
+	RelocationRate   float64 // Fraction of customers who change home branch during history (0 = none)
+	GeoClustering    float64 // Fraction of customers living near their home branch (0 = none)
+
+	// OfflineCustomerRate is the fraction of customers without online banking,
+	// who sign in and transact only at ATMs and branches (0 = none)
+	OfflineCustomerRate float64
+
+	// Beneficiaries per customer (see BeneficiaryGeneratorConfig)
+	MinBeneficiaries int // Fewest per customer (0 lets customers have none)
+	MaxBeneficiaries int // Most per customer (0 = twice the average)
+
+	// Branch and ATM lifecycle settings
+	ClosedBranchRate   float64 // Fraction of branches closed during history (0 = none)
+	OfflineATMRate     float64 // Fraction of ATMs offline at the as-of date (0 = none)
+	MaintenanceATMRate float64 // Fraction of ATMs in maintenance at the as-of date (0 = none)
+
+	// GeneratorVersion is the loadgen build recorded in _meta.csv
+	GeneratorVersion string
+
+	// Continuation extends an existing data set instead of starting a new one (nil = new)
+	Continuation *Continuation
+
+	// Only limits GenerateEntities to these tables and their dependencies (nil = all)
+	Only EntitySelection
+
+	// ATMEvents also generates atm_events.csv (nil = disabled); dates come from the history period
+	ATMEvents *ATMEventGeneratorConfig
+
+	// KYC gives retail customers a KYC status, document and review audit events (nil = disabled)
+	KYC *KYCConfig
+
+	// Transfers also writes transfers.csv shards, one row per internal transfer
+	Transfers bool
+
+	// DisputeRate is the fraction of completed purchases the customer later
+	// disputes, written to disputes.csv shards (0 = none)
+	DisputeRate float64
+
+	// Passwords hashes customer and business passwords (zero value = unsalted SHA-256)
+	Passwords PasswordHasher
 
 	// Audit log generation settings
 	FailedLoginRate                float64 // Rate of failed login attempts (0.0-1.0)
+	NewDeviceRate                  float64 // Rate of sessions from an unrecognized device (0 = none)
 	SessionsPerCustomerPerMonth    int     // Average login sessions per customer per month
 	BalanceChecksPerSession        int     // Average balance inquiries per session
+	SessionTransactionRate         float64 // Fraction of online and ATM transactions made inside a login session (0 = none)
+
+	// AuditActions limits audit logs to these actions (nil = all)
+	AuditActions AuditActionFilter
 
 	// Performance settings
-	Parallel bool // Enable parallel CSV writing for independent tables
-	Workers  int  // Number of parallel workers (0 = auto-detect CPUs)
+	Parallel  bool  // Enable parallel CSV writing for independent tables
+	Workers   int   // Number of parallel workers (0 = auto-detect CPUs)
+	MaxMemory int64 // Memory budget in bytes that caps the worker count (0 = available system memory)
 
 	// Output settings
-	Compress bool // Enable xz compression (creates .csv.xz files)
+	Compress         bool          // Enable xz compression (creates .csv.xz files)
+	CompressLevel    int           // xz preset 0-9, and Avro deflate level 1-9 (0 = deflate's default)
+	TableShards      int           // Split each entity table into this many shard files (0 or 1 = single file)
+	MaxFileRows      int64         // Roll transaction and audit log shards over into part files of this many rows (0 = unlimited)
+	WriteBufferSize  int           // Write buffer per transaction and audit log shard in bytes (0 = 1MB)
+	FlushInterval    time.Duration // Flush transaction and audit log shards at least this often (0 = only when the buffer fills)
+	Kafka            *KafkaConfig  // Also (or only) publish transactions to Kafka (nil = disabled)
+	Avro             bool          // Also write transactions and audit logs as Avro OCF shards (.avro)
+	PerTableDir      bool          // Write each table's files to its own subdirectory (output/transactions/...)
+	PartitionBy      Partitioning  // Split transactions into month directories (output/transactions/YYYY-MM/...)
+	SortTransactions bool          // Rewrite the transaction shards in timestamp order after generation
+	SortRunRows      int           // Rows sorted in memory at a time by SortTransactions (0 = default)
+
+	// TransactionAuditFromShards writes the transaction audit events in the
+	// audit phase, by reading the transaction shards back, instead of
+	// alongside each transaction (needs local transaction CSV shards)
+	TransactionAuditFromShards bool
+
+	// PIIMode pseudonymizes names, emails, phones and addresses in the entity files (empty = none)
+	PIIMode PIIMode
+	// PIIMappingFile is where to write the original value of every pseudonymized field (empty = not written)
+	PIIMappingFile string
+
+	// WriteLimiter caps the transaction and audit log write rate (nil = unlimited)
+	WriteLimiter *WriteLimiter
 }
 
 // GenerationResult holds statistics from the generation run
 type GenerationResult struct {
 	BranchCount      int
 	ATMCount         int
+	ATMEventCount    int
 	CustomerCount    int
 	BusinessCount    int
 	AccountCount     int
 	BeneficiaryCount int
 	TransactionCount int
+	TransferCount    int
+	DisputeCount     int
 	AuditLogCount    int
 	Duration         time.Duration
+	// Seed is the effective seed used for the run (resolved if 0 was requested)
+	Seed int64
 }
 
 // OrchestratorOptions holds optional settings for the orchestrator
@@ -81,31 +280,67 @@ func NewOrchestrator(config OrchestratorConfig, opts OrchestratorOptions) (*Orch
 	if err != nil {
 		return nil, fmt.Errorf("failed to load reference data: %w", err)
 	}
+	refData, err = refData.WithCountryWeights(config.CountryWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve seed 0 to a concrete random seed so the run can be reproduced
+	config.Seed = utils.ResolveSeed(config.Seed)
 
-	// Create RNG with seed
+	// Pin the clock once so every generator shares the same "now"
+	if config.AsOfDate.IsZero() {
+		config.AsOfDate = time.Now()
+	}
+	if !config.StartDate.IsZero() {
+		if !config.StartDate.Before(config.AsOfDate) {
+			return nil, fmt.Errorf("start date %s must be before end date %s",
+				config.StartDate.Format(time.RFC3339), config.AsOfDate.Format(time.RFC3339))
+		}
+		config.YearsOfHistory = spanYears(config.StartDate, config.AsOfDate)
+	}
+	if config.Only != nil && config.Continuation != nil {
+		return nil, fmt.Errorf("a continuation regenerates every entity table and can't be limited to %s", config.Only)
+	}
 	rng := utils.NewRandom(config.Seed)
 
+	// A continuation regenerates the original entities from their own seed
+	if config.Continuation != nil {
+		rng = utils.NewRandom(config.Continuation.EntitySeed)
+	}
+
 	return &Orchestrator{
 		rng:          rng,
 		refData:      refData,
 		config:       config,
 		verbose:      opts.Verbose,
 		showProgress: opts.ShowProgress,
+		pii:          NewPseudonymizer(config.PIIMode, config.Seed),
 	}, nil
 }
 
-// GenerateEntities generates all static entities (no transactions)
-func (o *Orchestrator) GenerateEntities() (*GenerationResult, error) {
+// Seed returns the effective seed used by this orchestrator
+func (o *Orchestrator) Seed() int64 {
+	return o.config.Seed
+}
+
+// GenerateEntities generates the static entities (no transactions): all of
+// them, or the tables selected by Only. The context is checked between entity types.
+func (o *Orchestrator) GenerateEntities(ctx context.Context) (*GenerationResult, error) {
 	startTime := time.Now()
-	result := &GenerationResult{}
+	result := &GenerationResult{Seed: o.config.Seed}
+	o.log("Using seed %d", o.config.Seed)
 
 	// 1. Generate branches
 	o.log("Generating %d branches...", o.config.NumBranches)
-	branchGen := NewBranchGenerator(o.rng.Fork(), o.refData, BranchGeneratorConfig{
-		NumBranches: o.config.NumBranches,
-		NumATMs:     o.config.NumATMs,
-		BaseDate:    time.Now(),
-		YearsBack:   o.config.YearsOfHistory,
+	branchGen := NewBranchGenerator(o.rng.Derive("branches"), o.refData, BranchGeneratorConfig{
+		NumBranches:        o.config.NumBranches,
+		NumATMs:            o.config.NumATMs,
+		BaseDate:           o.entityAsOf(),
+		YearsBack:          o.entityYears(),
+		ClosedBranchRate:   o.config.ClosedBranchRate,
+		OfflineATMRate:     o.config.OfflineATMRate,
+		MaintenanceATMRate: o.config.MaintenanceATMRate,
 	})
 
 	branches := branchGen.GenerateBranches()
@@ -113,70 +348,186 @@ func (o *Orchestrator) GenerateEntities() (*GenerationResult, error) {
 	result.BranchCount = len(branches)
 	o.log("  Generated %d branches", result.BranchCount)
 
-	// Write branches CSV
-	if o.showProgress {
-		if err := WriteBranchesCSVWithProgress(branches, o.config.OutputDir, o.config.Compress); err != nil {
+	// Write branches CSV (a continuation keeps the existing file)
+	switch {
+	case o.config.Continuation != nil:
+	case o.showProgress:
+		if err := WriteBranchesCSVWithProgress(branches, o.tableWriterConfig("branches"), o.config.TableShards); err != nil {
 			return nil, fmt.Errorf("failed to write branches CSV: %w", err)
 		}
-	} else {
-		if err := WriteBranchesCSV(branches, o.config.OutputDir, o.config.Compress); err != nil {
+	default:
+		if err := WriteBranchesCSV(branches, o.tableWriterConfig("branches"), o.config.TableShards); err != nil {
 			return nil, fmt.Errorf("failed to write branches CSV: %w", err)
 		}
 		o.log("  Wrote branches.csv")
 	}
 
 	// 2. Generate ATMs
+	if o.config.Only.Has("atms") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := o.generateATMs(branchGen, branches, result); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Generate retail customers
+	var customers []GeneratedCustomer
+	if o.config.Only.Has("customers") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var err error
+		if customers, err = o.generateCustomers(branches, result); err != nil {
+			return nil, err
+		}
+	}
+
+	// 4. Generate businesses
+	var businesses []GeneratedBusiness
+	if o.config.Only.Has("businesses") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var err error
+		if businesses, err = o.generateBusinesses(branches, result); err != nil {
+			return nil, err
+		}
+	}
+
+	// 5. Generate accounts
+	if o.config.Only.Has("accounts") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := o.generateAccounts(branches, customers, businesses, result); err != nil {
+			return nil, err
+		}
+	}
+
+	// 6. Generate beneficiaries
+	if o.config.Only.Has("beneficiaries") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := o.generateBeneficiaries(customers, businesses, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if path := o.config.PIIMappingFile; path != "" && o.config.Continuation == nil {
+		if err := o.pii.WriteMapping(path); err != nil {
+			return nil, err
+		}
+		o.log("  Wrote PII mapping to %s", path)
+	}
+
+	if c := o.config.Continuation; c != nil {
+		if err := c.checkEntities(result); err != nil {
+			return nil, err
+		}
+		// New history draws from this run's seed so it doesn't replay the old one
+		o.rng = utils.NewRandom(o.config.Seed)
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// generateATMs generates and writes the ATMs of the branches, with their
+// events when ATMEvents is set
+func (o *Orchestrator) generateATMs(branchGen *BranchGenerator, branches []GeneratedBranch, result *GenerationResult) error {
 	o.log("Generating %d ATMs...", o.config.NumATMs)
 	atms := branchGen.GenerateATMs(branches)
 	o.atms = atms
 	result.ATMCount = len(atms)
 	o.log("  Generated %d ATMs", result.ATMCount)
 
-	// Write ATMs CSV
-	if o.showProgress {
-		if err := WriteATMsCSVWithProgress(atms, o.config.OutputDir, o.config.Compress); err != nil {
-			return nil, fmt.Errorf("failed to write ATMs CSV: %w", err)
+	// Simulate ATM operations before writing, since it settles each ATM's final status.
+	// A forked RNG keeps the rest of the data set identical with or without events.
+	if o.config.ATMEvents != nil {
+		eventConfig := *o.config.ATMEvents
+		eventConfig.StartDate = o.historyStart()
+		eventConfig.EndDate = o.config.AsOfDate
+		eventGen := NewATMEventGenerator(o.rng.Derive("atm_events"), eventConfig)
+		events := eventGen.GenerateEvents(atms)
+		result.ATMEventCount = len(events)
+		o.log("  Generated %d ATM events", result.ATMEventCount)
+
+		if err := WriteATMEventsCSV(events, o.tableWriterConfig("atm_events"), o.config.TableShards); err != nil {
+			return fmt.Errorf("failed to write ATM events CSV: %w", err)
+		}
+		o.log("  Wrote atm_events.csv")
+	}
+
+	// Write ATMs CSV (a continuation keeps the existing file)
+	switch {
+	case o.config.Continuation != nil:
+	case o.showProgress:
+		if err := WriteATMsCSVWithProgress(atms, o.tableWriterConfig("atms"), o.config.TableShards); err != nil {
+			return fmt.Errorf("failed to write ATMs CSV: %w", err)
 		}
-	} else {
-		if err := WriteATMsCSV(atms, o.config.OutputDir, o.config.Compress); err != nil {
-			return nil, fmt.Errorf("failed to write ATMs CSV: %w", err)
+	default:
+		if err := WriteATMsCSV(atms, o.tableWriterConfig("atms"), o.config.TableShards); err != nil {
+			return fmt.Errorf("failed to write ATMs CSV: %w", err)
 		}
 		o.log("  Wrote atms.csv")
 	}
+	return nil
+}
 
-	// 3. Generate retail customers
+// generateCustomers generates and writes the retail customers
+func (o *Orchestrator) generateCustomers(branches []GeneratedBranch, result *GenerationResult) ([]GeneratedCustomer, error) {
 	o.log("Generating %d customers...", o.config.NumCustomers)
-	customerGen := NewCustomerGenerator(o.rng.Fork(), o.refData, CustomerGeneratorConfig{
-		NumCustomers: o.config.NumCustomers,
-		Branches:     branches,
-		BaseDate:     time.Now(),
-		ParetoRatio:  0.2,
+	customerGen := NewCustomerGenerator(o.rng.Derive("customers"), o.refData, CustomerGeneratorConfig{
+		NumCustomers:     o.config.NumCustomers,
+		Branches:         branches,
+		BaseDate:         o.entityAsOf(),
+		ParetoRatio:      0.2,
+		ChurnRate:        o.config.ChurnRate,
+		ChurnClosedRatio: o.config.ChurnClosedRatio,
+		RelocationRate:   o.config.RelocationRate,
+		GeoClustering:    o.config.GeoClustering,
+		Passwords:        o.config.Passwords,
+		OfflineRate:      o.config.OfflineCustomerRate,
 	})
 
 	customers := customerGen.GenerateCustomers()
+	if o.config.KYC != nil {
+		applyKYC(o.rng.Derive("kyc"), customers, *o.config.KYC, o.entityAsOf())
+	}
 	o.customers = customers
 	result.CustomerCount = len(customers)
 	o.log("  Generated %d customers", result.CustomerCount)
 
-	// Write customers CSV
-	if o.showProgress {
-		if err := WriteCustomersCSVWithProgress(customers, o.config.OutputDir, o.config.Compress); err != nil {
+	// Write customers CSV (a continuation keeps the existing file)
+	switch {
+	case o.config.Continuation != nil:
+	case o.showProgress:
+		if err := WriteCustomersCSVWithProgress(o.pii.Customers(customers), o.tableWriterConfig("customers"), o.config.TableShards); err != nil {
 			return nil, fmt.Errorf("failed to write customers CSV: %w", err)
 		}
-	} else {
-		if err := WriteCustomersCSV(customers, o.config.OutputDir, o.config.Compress); err != nil {
+	default:
+		if err := WriteCustomersCSV(o.pii.Customers(customers), o.tableWriterConfig("customers"), o.config.TableShards); err != nil {
 			return nil, fmt.Errorf("failed to write customers CSV: %w", err)
 		}
 		o.log("  Wrote customers.csv")
 	}
+	return customers, nil
+}
 
-	// 4. Generate businesses
+// generateBusinesses generates and writes the businesses
+func (o *Orchestrator) generateBusinesses(branches []GeneratedBranch, result *GenerationResult) ([]GeneratedBusiness, error) {
 	o.log("Generating %d businesses...", o.config.NumBusinesses)
 	businessStartID := int64(o.config.NumCustomers + 1)
-	businessGen := NewBusinessGenerator(o.rng.Fork(), o.refData, BusinessGeneratorConfig{
+	businessGen := NewBusinessGenerator(o.rng.Derive("businesses"), o.refData, BusinessGeneratorConfig{
 		NumBusinesses: o.config.NumBusinesses,
 		StartID:       businessStartID,
 		Branches:      branches,
+		BaseDate:      o.entityAsOf(),
+		Passwords:     o.config.Passwords,
+		Mix:           o.config.BusinessMix,
 	})
 
 	businesses := businessGen.GenerateBusinesses()
@@ -184,22 +535,33 @@ func (o *Orchestrator) GenerateEntities() (*GenerationResult, error) {
 	result.BusinessCount = len(businesses)
 	o.log("  Generated %d businesses", result.BusinessCount)
 
-	// Write businesses CSV
-	if o.showProgress {
-		if err := WriteBusinessesCSVWithProgress(businesses, o.config.OutputDir, o.config.Compress); err != nil {
+	// Write businesses CSV (a continuation keeps the existing file)
+	switch {
+	case o.config.Continuation != nil:
+	case o.showProgress:
+		if err := WriteBusinessesCSVWithProgress(o.pii.Businesses(businesses), o.tableWriterConfig("businesses"), o.config.TableShards); err != nil {
 			return nil, fmt.Errorf("failed to write businesses CSV: %w", err)
 		}
-	} else {
-		if err := WriteBusinessesCSV(businesses, o.config.OutputDir, o.config.Compress); err != nil {
+	default:
+		if err := WriteBusinessesCSV(o.pii.Businesses(businesses), o.tableWriterConfig("businesses"), o.config.TableShards); err != nil {
 			return nil, fmt.Errorf("failed to write businesses CSV: %w", err)
 		}
 		o.log("  Wrote businesses.csv")
 	}
+	return businesses, nil
+}
 
-	// 5. Generate accounts for customers
+// generateAccounts generates and writes the customers' and businesses' accounts
+func (o *Orchestrator) generateAccounts(branches []GeneratedBranch, customers []GeneratedCustomer, businesses []GeneratedBusiness, result *GenerationResult) error {
 	o.log("Generating accounts for customers...")
-	accountGen := NewAccountGenerator(o.rng.Fork(), o.refData, AccountGeneratorConfig{
-		Branches: branches,
+	accountGen := NewAccountGenerator(o.rng.Derive("accounts"), o.refData, AccountGeneratorConfig{
+		Branches:            branches,
+		BaseDate:            o.entityAsOf(),
+		AccountMix:          o.config.AccountMix,
+		ForeignCurrencyRate: o.config.ForeignCurrencyRate,
+		ForeignCurrencies:   o.config.ForeignCurrencies,
+		KYC:                 o.config.KYC,
+		MinorUnits:          o.config.MinorUnits,
 	})
 
 	customerAccounts, nextAccountID := accountGen.GenerateAccountsForCustomers(customers, 1)
@@ -212,67 +574,164 @@ func (o *Orchestrator) GenerateEntities() (*GenerationResult, error) {
 
 	// Combine all accounts
 	allAccounts := append(customerAccounts, businessAccounts...)
+
+	// A continuation picks up from each account's final balance
+	if o.config.Continuation != nil {
+		for i := range allAccounts {
+			if balance, ok := o.config.Continuation.Balances[allAccounts[i].Account.ID]; ok {
+				allAccounts[i].Account.Balance = balance
+			}
+		}
+	}
 	o.accounts = allAccounts
 	result.AccountCount = len(allAccounts)
 
-	// Write accounts CSV
-	if o.showProgress {
-		if err := WriteAccountsCSVWithProgress(allAccounts, o.config.OutputDir, o.config.Compress); err != nil {
-			return nil, fmt.Errorf("failed to write accounts CSV: %w", err)
+	// Write accounts CSV (a continuation keeps the existing file)
+	switch {
+	case o.config.Continuation != nil:
+	case o.showProgress:
+		if err := WriteAccountsCSVWithProgress(allAccounts, o.tableWriterConfig("accounts"), o.config.TableShards); err != nil {
+			return fmt.Errorf("failed to write accounts CSV: %w", err)
 		}
-	} else {
-		if err := WriteAccountsCSV(allAccounts, o.config.OutputDir, o.config.Compress); err != nil {
-			return nil, fmt.Errorf("failed to write accounts CSV: %w", err)
+	default:
+		if err := WriteAccountsCSV(allAccounts, o.tableWriterConfig("accounts"), o.config.TableShards); err != nil {
+			return fmt.Errorf("failed to write accounts CSV: %w", err)
 		}
 		o.log("  Wrote accounts.csv")
 	}
+	return nil
+}
 
-	// 6. Generate beneficiaries
+// generateBeneficiaries generates and writes the customers' beneficiaries
+func (o *Orchestrator) generateBeneficiaries(customers []GeneratedCustomer, businesses []GeneratedBusiness, result *GenerationResult) error {
 	o.log("Generating beneficiaries...")
-	beneficiaryGen := NewBeneficiaryGenerator(o.rng.Fork(), o.refData, BeneficiaryGeneratorConfig{
+	beneficiaryGen := NewBeneficiaryGenerator(o.rng.Derive("beneficiaries"), o.refData, BeneficiaryGeneratorConfig{
 		AvgBeneficiariesPerCustomer: 5,
+		MinBeneficiaries:            o.config.MinBeneficiaries,
+		MaxBeneficiaries:            o.config.MaxBeneficiaries,
 		Businesses:                  businesses,
+		BaseDate:                    o.entityAsOf(),
 	})
 
 	beneficiaries, _ := beneficiaryGen.GenerateBeneficiariesForCustomers(customers, 1)
 	result.BeneficiaryCount = len(beneficiaries)
+	o.wireBeneficiaries = WireBeneficiariesByCustomer(beneficiaries)
 	o.log("  Generated %d beneficiaries", result.BeneficiaryCount)
 
-	// Write beneficiaries CSV
-	if o.showProgress {
-		if err := WriteBeneficiariesCSVWithProgress(beneficiaries, o.config.OutputDir, o.config.Compress); err != nil {
-			return nil, fmt.Errorf("failed to write beneficiaries CSV: %w", err)
+	// Write beneficiaries CSV (a continuation keeps the existing file)
+	switch {
+	case o.config.Continuation != nil:
+	case o.showProgress:
+		if err := WriteBeneficiariesCSVWithProgress(o.pii.Beneficiaries(beneficiaries), o.tableWriterConfig("beneficiaries"), o.config.TableShards); err != nil {
+			return fmt.Errorf("failed to write beneficiaries CSV: %w", err)
 		}
-	} else {
-		if err := WriteBeneficiariesCSV(beneficiaries, o.config.OutputDir, o.config.Compress); err != nil {
-			return nil, fmt.Errorf("failed to write beneficiaries CSV: %w", err)
+	default:
+		if err := WriteBeneficiariesCSV(o.pii.Beneficiaries(beneficiaries), o.tableWriterConfig("beneficiaries"), o.config.TableShards); err != nil {
+			return fmt.Errorf("failed to write beneficiaries CSV: %w", err)
 		}
 		o.log("  Wrote beneficiaries.csv")
 	}
+	return nil
+}
 
-	result.Duration = time.Since(startTime)
-	return result, nil
+// entityAsOf is the as-of date entities are generated at; a continuation
+// regenerates the entities of the data set it extends
+func (o *Orchestrator) entityAsOf() time.Time {
+	if o.config.Continuation != nil {
+		return o.config.Continuation.EntityAsOf
+	}
+	return o.config.AsOfDate
+}
+
+// entityYears is the history length entities are generated with
+func (o *Orchestrator) entityYears() int {
+	if o.config.Continuation != nil {
+		return o.config.Continuation.EntityYears
+	}
+	return o.config.YearsOfHistory
+}
+
+// tableDir returns the directory a table's files are written to. Partitioned
+// transactions always get their own directory to hold the partitions.
+func (o *Orchestrator) tableDir(table string) string {
+	if o.config.PerTableDir || (table == "transactions" && o.config.PartitionBy != PartitionNone) {
+		return JoinOutputPath(o.config.OutputDir, table)
+	}
+	return o.config.OutputDir
+}
+
+// tableWriterConfig returns the writer options an entity table is written
+// with: its directory and the run's compression
+func (o *Orchestrator) tableWriterConfig(table string) CSVWriterConfig {
+	return CSVWriterConfig{
+		OutputDir: o.tableDir(table),
+		Compress:  o.config.Compress,
+		XZPreset:  o.config.CompressLevel,
+	}
+}
+
+// historyStart is where transactions and sessions begin: the previous as-of
+// date for a continuation, then StartDate if set, otherwise YearsOfHistory
+// before AsOfDate
+func (o *Orchestrator) historyStart() time.Time {
+	if o.config.Continuation != nil {
+		return o.config.Continuation.Manifest.AsOfDate
+	}
+	if !o.config.StartDate.IsZero() {
+		return o.config.StartDate
+	}
+	return o.config.AsOfDate.AddDate(-o.config.YearsOfHistory, 0, 0)
+}
+
+// historyMonths is the length of the history period in whole months, rounded up
+func (o *Orchestrator) historyMonths() int {
+	start := o.historyStart()
+	months := (o.config.AsOfDate.Year()-start.Year())*12 + int(o.config.AsOfDate.Month()-start.Month())
+	if start.AddDate(0, months, 0).Before(o.config.AsOfDate) {
+		months++
+	}
+	return max(months, 1)
+}
+
+// spanYears returns the whole years needed to cover start to end, rounded up
+func spanYears(start, end time.Time) int {
+	years := end.Year() - start.Year()
+	if start.AddDate(years, 0, 0).Before(end) {
+		years++
+	}
+	return max(years, 1)
+}
+
+// lastIDs returns the last transaction and audit log IDs of a continued data set (0 otherwise)
+func (o *Orchestrator) lastIDs() (transactionID, auditLogID int64) {
+	if c := o.config.Continuation; c != nil {
+		return c.LastTransactionID, c.LastAuditLogID
+	}
+	return 0, 0
 }
 
 // GenerateTransactions generates historical transactions using parallel streaming.
-// Must be called after GenerateEntities.
-func (o *Orchestrator) GenerateTransactions() (*GenerationResult, error) {
+// Must be called after GenerateEntities. Cancelling the context stops all
+// workers; a failure in one worker cancels the others.
+func (o *Orchestrator) GenerateTransactions(ctx context.Context) (*GenerationResult, error) {
 	if len(o.accounts) == 0 {
 		return nil, fmt.Errorf("no accounts found - call GenerateEntities first")
 	}
 
 	startTime := time.Now()
-	result := &GenerationResult{}
+	result := &GenerationResult{Seed: o.config.Seed}
 
 	// Calculate date range for transaction history
-	endDate := time.Now()
-	startDate := endDate.AddDate(-o.config.YearsOfHistory, 0, 0)
+	endDate := o.config.AsOfDate
+	startDate := o.historyStart()
 
 	// Determine worker count
-	workerCount := GetWorkerCount(o.config.Workers)
+	workerCount := o.workerCount()
 
-	fmt.Printf("Generating transactions for %d years using %d workers...\n",
-		o.config.YearsOfHistory, workerCount)
+	if o.showProgress {
+		fmt.Printf("Generating transactions from %s to %s using %d workers...\n",
+			startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), workerCount)
+	}
 
 	// Set defaults if not configured
 	txnsPerMonth := o.config.TransactionsPerCustomerPerMonth
@@ -284,15 +743,50 @@ func (o *Orchestrator) GenerateTransactions() (*GenerationResult, error) {
 		paretoRatio = 0.2
 	}
 
-	// Partition accounts by customer across workers
-	workerAccounts := PartitionAccountsByCustomer(o.accounts, workerCount)
+	// Estimate total transactions for progress reporting
+	lastTxnID, lastAuditID := o.lastIDs()
+	estimatedTotal := EstimateTransactionCount(o.accounts, o.historyStart(), o.historyMonths(), txnsPerMonth, o.config.MonthlyCaps, o.config.RampUpMonths)
+
+	o.whales = o.config.WhaleAccountIDs
+	if o.whales == nil {
+		o.whales = SelectWhaleAccounts(o.accounts, o.config.WhaleAccounts)
+	}
+	if len(o.whales) > 0 {
+		o.log("Whale accounts: %v (%gx volume)", o.whales, o.config.WhaleMultiplier)
+		var whaleAccounts []GeneratedAccount
+		for _, acc := range o.accounts {
+			if slices.Contains(o.whales, acc.Account.ID) {
+				whaleAccounts = append(whaleAccounts, acc)
+			}
+		}
+		whaleTotal := EstimateTransactionCount(whaleAccounts, o.historyStart(), o.historyMonths(), txnsPerMonth, o.config.MonthlyCaps, o.config.RampUpMonths)
+		estimatedTotal += int64(float64(whaleTotal) * max(o.config.WhaleMultiplier-1, 0))
+	}
 
-	// Estimate total transactions for progress reporting and ID allocation
-	estimatedTotal := EstimateTransactionCount(len(o.accounts), o.config.YearsOfHistory, txnsPerMonth)
-	idRanges := CalculateIDRanges(estimatedTotal, workerCount)
+	// Transaction audit events are numbered from the transaction ID, each
+	// transaction reserving a fixed number of IDs from lastAuditID+1. Session
+	// audit logs are numbered after the highest of them once it is known.
+	auditSlots := auditSlotsPerTransaction(o.config.SessionTransactionRate)
+	auditIDBase := lastAuditID - auditSlots*lastTxnID
+
+	// Partition accounts by customer. Partition i numbers its transactions
+	// lastTxnID+1+i, then every GenerationPartitions IDs, so partitions never
+	// run into each other however many transactions they generate (and a
+	// continuation carries on after the IDs already used).
+	partitionAccounts := PartitionAccountsByCustomer(o.accounts, GenerationPartitions)
+	partitionRNGs := o.rng.Derive("transactions").ForkN(GenerationPartitions)
+	partitions := make([]GenerationPartition, GenerationPartitions)
+	for i := range partitions {
+		partitions[i] = GenerationPartition{
+			Accounts: partitionAccounts[i],
+			RNG:      partitionRNGs[i],
+			FirstID:  lastTxnID + 1 + int64(i),
+			IDStride: GenerationPartitions,
+		}
+	}
 
-	// Fork RNGs for each worker
-	workerRNGs := o.rng.ForkN(workerCount)
+	// Every worker's audit writer starts from the same stream
+	auditRNG := o.rng.Derive("transaction_audit_logs")
 
 	// Create progress reporter
 	var progress *AggregatedProgressReporter
@@ -305,7 +799,11 @@ func (o *Orchestrator) GenerateTransactions() (*GenerationResult, error) {
 		progress.Start()
 	}
 
-	// Launch workers
+	// Launch workers; the first failure cancels the remaining workers
+	accountIndex := o.accountIndex()
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	var wg sync.WaitGroup
 	results := make([]WorkerResult, workerCount)
 	errChan := make(chan error, workerCount)
@@ -320,24 +818,63 @@ func (o *Orchestrator) GenerateTransactions() (*GenerationResult, error) {
 				progressChan = progress.GetProgressChan()
 			}
 
-			gen, err := NewStreamingTransactionGenerator(workerRNGs[workerID], o.refData, StreamingTransactionConfig{
+			gen, err := NewStreamingTransactionGenerator(auditRNG.Clone(), o.refData, StreamingTransactionConfig{
 				StartDate:                       startDate,
 				EndDate:                         endDate,
 				TransactionsPerCustomerPerMonth: txnsPerMonth,
 				ParetoRatio:                     paretoRatio,
 				PayrollDay:                      o.config.PayrollDay,
+				Granularity:                     o.config.Granularity,
 				DeclinedTransactionRate:         o.config.DeclinedTransactionRate,
+				DeclineRetryRate:                o.config.DeclineRetryRate,
 				InsufficientFundsRate:           o.config.InsufficientFundsRate,
+				CashbackRate:                    o.config.CashbackRate,
+				ReversalRate:                    o.config.ReversalRate,
+				OverdraftFee:                    o.config.OverdraftFee,
+				OverdraftFeeDailyCap:            o.config.OverdraftFeeDailyCap,
+				BusinessHours:                   o.config.BusinessHours,
+				TransactionMix:                  o.config.TransactionMix,
+				FeeSchedule:                     o.config.FeeSchedule,
+				WireBeneficiaries:               o.wireBeneficiaries,
+				InternationalWireRate:           o.config.InternationalWireRate,
+				FarFromHomeRate:                 o.config.FarFromHomeRate,
+				GeoCoordinates:                  o.config.GeoCoordinates,
+				BranchHours:                     o.config.BranchHours,
+				WeekendVolume:                   o.config.WeekendVolume,
+				WhaleAccounts:                   o.whales,
+				WhaleMultiplier:                 o.config.WhaleMultiplier,
+				MonthlyCaps:                     o.config.MonthlyCaps,
+				MinorUnits:                      o.config.MinorUnits,
+				RampUpMonths:                    o.config.RampUpMonths,
+				InterestPostingDay:              o.config.InterestPostingDay,
+				SettlementLags:                  o.config.SettlementLags,
+				ATMDenominations:                o.config.ATMDenominations,
+				TransferGraph:                   o.config.TransferGraph,
 				Branches:                        o.branches,
 				ATMs:                            o.atms,
-				AllAccounts:                     o.accounts,
-				Businesses:                      o.businesses,
+				Accounts:                        accountIndex,
 				WorkerID:                        workerID,
 				WorkerCount:                     workerCount,
-				StartID:                         idRanges[workerID].Start,
-				EndID:                           idRanges[workerID].End,
-				OutputDir:                       o.config.OutputDir,
+				AuditIDBase:                     auditIDBase,
+				SessionTransactionRate:          o.config.SessionTransactionRate,
+				AuditActions:                    o.config.AuditActions,
+				DeferAudit:                      o.config.TransactionAuditFromShards,
+				OutputDir:                       o.tableDir("transactions"),
+				AuditOutputDir:                  o.tableDir("audit_logs"),
+				Transfers:                       o.config.Transfers,
+				TransferOutputDir:               o.tableDir("transfers"),
+				DisputeRate:                     o.config.DisputeRate,
+				DisputeOutputDir:                o.tableDir("disputes"),
+				PartitionBy:                     o.config.PartitionBy,
+				MaxFileRows:                     o.config.MaxFileRows,
+				ExpectedRows:                    workerShareEstimate(estimatedTotal, workerCount),
+				BufferSize:                      o.config.WriteBufferSize,
+				FlushInterval:                   o.config.FlushInterval,
 				Compress:                        o.config.Compress,
+				CompressLevel:                   o.config.CompressLevel,
+				Limiter:                         o.config.WriteLimiter,
+				Kafka:                           o.config.Kafka,
+				Avro:                            o.config.Avro,
 				ProgressChan:                    progressChan,
 			})
 			if err != nil {
@@ -346,15 +883,20 @@ func (o *Orchestrator) GenerateTransactions() (*GenerationResult, error) {
 			}
 
 			workerStart := time.Now()
-			count, err := gen.GenerateAndStream(workerAccounts[workerID])
+			count, err := gen.GenerateAndStream(workerCtx, WorkerPartitions(partitions, workerID, workerCount))
 			if err != nil {
 				errChan <- fmt.Errorf("worker %d: %w", workerID, err)
+				cancel()
 				return
 			}
 
 			results[workerID] = WorkerResult{
 				WorkerID:         workerID,
 				TransactionCount: count,
+				TransferCount:    gen.TransferCount(),
+				DisputeCount:     gen.DisputeCount(),
+				AuditLogCount:    gen.AuditCount(),
+				LastID:           gen.LastID(),
 				Duration:         time.Since(workerStart),
 				ShardFile:        gen.ShardFile(),
 			}
@@ -364,8 +906,16 @@ func (o *Orchestrator) GenerateTransactions() (*GenerationResult, error) {
 	wg.Wait()
 	close(errChan)
 
+	// Report the caller's cancellation in preference to per-worker errors
+	if err := ctx.Err(); err != nil {
+		if progress != nil {
+			progress.Finish()
+		}
+		return nil, err
+	}
+
 	// Check for errors
-	for err := range errChan {
+	if err := firstWorkerError(errChan); err != nil {
 		if progress != nil {
 			progress.Finish()
 		}
@@ -378,8 +928,31 @@ func (o *Orchestrator) GenerateTransactions() (*GenerationResult, error) {
 	}
 
 	// Sum up results
+	lastID := lastTxnID
 	for _, r := range results {
 		result.TransactionCount += int(r.TransactionCount)
+		result.TransferCount += int(r.TransferCount)
+		result.DisputeCount += int(r.DisputeCount)
+		result.AuditLogCount += int(r.AuditLogCount)
+		lastID = max(lastID, r.LastID)
+	}
+	o.lastTransactionAuditID = auditIDBase + auditSlots*lastID
+	o.transactionAuditIDBase = auditIDBase
+	o.pendingTransactionAudit = o.config.TransactionAuditFromShards
+
+	if o.sortsTransactions() {
+		if o.showProgress {
+			fmt.Println("Sorting transactions by timestamp...")
+		}
+		if _, err := SortTransactionShards(ctx, TransactionSortConfig{
+			Dir:            o.tableDir("transactions"),
+			Compress:       o.config.Compress,
+			Level:          o.config.CompressLevel,
+			RunRows:        o.config.SortRunRows,
+			GeoCoordinates: o.config.GeoCoordinates,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to sort transactions: %w", err)
+		}
 	}
 
 	result.Duration = time.Since(startTime)
@@ -387,23 +960,25 @@ func (o *Orchestrator) GenerateTransactions() (*GenerationResult, error) {
 }
 
 // GenerateAuditLogs generates audit trail entries using parallel streaming.
-// Must be called after GenerateEntities.
-func (o *Orchestrator) GenerateAuditLogs() (*GenerationResult, error) {
+// Must be called after GenerateEntities. Cancelling the context stops all workers.
+func (o *Orchestrator) GenerateAuditLogs(ctx context.Context) (*GenerationResult, error) {
 	if len(o.customers) == 0 {
 		return nil, fmt.Errorf("no customers found - call GenerateEntities first")
 	}
 
 	startTime := time.Now()
-	result := &GenerationResult{}
+	result := &GenerationResult{Seed: o.config.Seed}
 
 	// Calculate date range
-	endDate := time.Now()
-	startDate := endDate.AddDate(-o.config.YearsOfHistory, 0, 0)
+	endDate := o.config.AsOfDate
+	startDate := o.historyStart()
 
 	// Determine worker count
-	workerCount := GetWorkerCount(o.config.Workers)
+	workerCount := o.workerCount()
 
-	fmt.Printf("Generating audit logs using %d workers...\n", workerCount)
+	if o.showProgress {
+		fmt.Printf("Generating audit logs using %d workers...\n", workerCount)
+	}
 
 	// Set defaults if not configured
 	sessionsPerMonth := o.config.SessionsPerCustomerPerMonth
@@ -419,18 +994,30 @@ func (o *Orchestrator) GenerateAuditLogs() (*GenerationResult, error) {
 		failedLoginRate = 0.03
 	}
 
-	// Partition customers across workers
-	customersPerWorker := len(o.customers) / workerCount
-	if customersPerWorker < 1 {
-		customersPerWorker = 1
+	// Estimate total audit logs for progress
+	_, lastAuditID := o.lastIDs()
+	estimatedTotal := o.sessionAuditEstimate()
+
+	// Partition customers as their accounts were for transactions. Like
+	// transactions, partition i numbers its logs firstID+i, then every
+	// GenerationPartitions IDs, so partitions never run into each other
+	// however many sessions they generate. They follow the transaction audit
+	// events, whose highest ID is known once transactions are generated.
+	firstID := max(lastAuditID, o.lastTransactionAuditID) + 1
+	partitionCustomers := PartitionCustomers(o.customers, GenerationPartitions)
+	partitionRNGs := o.rng.Derive("audit_logs").ForkN(GenerationPartitions)
+	partitions := make([]GenerationPartition, GenerationPartitions)
+	for i := range partitions {
+		partitions[i] = GenerationPartition{
+			Customers: partitionCustomers[i],
+			RNG:       partitionRNGs[i],
+			FirstID:   firstID + int64(i),
+			IDStride:  GenerationPartitions,
+		}
 	}
 
-	// Estimate total audit logs for progress and ID allocation
-	estimatedTotal := EstimateAuditLogCount(0, len(o.customers), o.config.YearsOfHistory)
-	idRanges := CalculateIDRanges(estimatedTotal, workerCount)
-
-	// Fork RNGs for each worker
-	workerRNGs := o.rng.ForkN(workerCount)
+	// Every worker's IP address pools are drawn from the same stream
+	poolRNG := o.rng.Derive("audit_log_ip_pools")
 
 	// Create progress reporter
 	var progress *AggregatedProgressReporter
@@ -443,7 +1030,11 @@ func (o *Orchestrator) GenerateAuditLogs() (*GenerationResult, error) {
 		progress.Start()
 	}
 
-	// Launch workers
+	// Launch workers; the first failure cancels the remaining workers
+	accountIndex := o.accountIndex()
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	var wg sync.WaitGroup
 	results := make([]WorkerResult, workerCount)
 	errChan := make(chan error, workerCount)
@@ -453,27 +1044,22 @@ func (o *Orchestrator) GenerateAuditLogs() (*GenerationResult, error) {
 		go func(workerID int) {
 			defer wg.Done()
 
-			// Determine customer range for this worker
-			start := workerID * customersPerWorker
-			end := start + customersPerWorker
-			if workerID == workerCount-1 {
-				end = len(o.customers) // Last worker takes remainder
-			}
-			if start >= len(o.customers) {
+			// Determine the partitions for this worker
+			workerPartitions := WorkerPartitions(partitions, workerID, workerCount)
+			if len(workerPartitions[0].Customers) == 0 {
 				return // No customers for this worker
 			}
-			workerCustomers := o.customers[start:end]
 
 			var progressChan chan<- workerProgress
 			if progress != nil {
 				progressChan = progress.GetProgressChan()
 			}
 
-			gen, err := NewStreamingAuditGenerator(workerRNGs[workerID], o.refData, StreamingAuditConfig{
-				Customers:                      workerCustomers,
-				Accounts:                       o.accounts,
+			gen, err := NewStreamingAuditGenerator(poolRNG.Clone(), o.refData, StreamingAuditConfig{
+				Accounts:                       accountIndex,
 				ATMs:                           o.atms,
 				FailedLoginRate:                failedLoginRate,
+				NewDeviceRate:                  o.config.NewDeviceRate,
 				LockedAccountRate:              0.1,
 				SessionTimeoutRate:             0.15,
 				AvgSessionsPerCustomerPerMonth: sessionsPerMonth,
@@ -482,10 +1068,16 @@ func (o *Orchestrator) GenerateAuditLogs() (*GenerationResult, error) {
 				EndDate:                        endDate,
 				WorkerID:                       workerID,
 				WorkerCount:                    workerCount,
-				StartID:                        idRanges[workerID].Start,
-				EndID:                          idRanges[workerID].End,
-				OutputDir:                      o.config.OutputDir,
+				OutputDir:                      o.tableDir("audit_logs"),
 				Compress:                       o.config.Compress,
+				CompressLevel:                  o.config.CompressLevel,
+				MaxFileRows:                    o.config.MaxFileRows,
+				ExpectedRows:                   workerShareEstimate(estimatedTotal, workerCount),
+				BufferSize:                     o.config.WriteBufferSize,
+				FlushInterval:                  o.config.FlushInterval,
+				Limiter:                        o.config.WriteLimiter,
+				Avro:                           o.config.Avro,
+				Actions:                        o.config.AuditActions,
 				ProgressChan:                   progressChan,
 			})
 			if err != nil {
@@ -494,9 +1086,10 @@ func (o *Orchestrator) GenerateAuditLogs() (*GenerationResult, error) {
 			}
 
 			workerStart := time.Now()
-			count, err := gen.GenerateAndStream()
+			count, err := gen.GenerateAndStream(workerCtx, workerPartitions)
 			if err != nil {
 				errChan <- fmt.Errorf("worker %d: %w", workerID, err)
+				cancel()
 				return
 			}
 
@@ -512,8 +1105,16 @@ func (o *Orchestrator) GenerateAuditLogs() (*GenerationResult, error) {
 	wg.Wait()
 	close(errChan)
 
+	// Report the caller's cancellation in preference to per-worker errors
+	if err := ctx.Err(); err != nil {
+		if progress != nil {
+			progress.Finish()
+		}
+		return nil, err
+	}
+
 	// Check for errors
-	for err := range errChan {
+	if err := firstWorkerError(errChan); err != nil {
 		if progress != nil {
 			progress.Finish()
 		}
@@ -530,38 +1131,64 @@ func (o *Orchestrator) GenerateAuditLogs() (*GenerationResult, error) {
 		result.AuditLogCount += int(r.AuditLogCount)
 	}
 
+	if o.pendingTransactionAudit {
+		count, err := o.generateTransactionAuditFromShards(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write transaction audit events: %w", err)
+		}
+		result.AuditLogCount += int(count)
+		o.pendingTransactionAudit = false
+	}
+
 	result.Duration = time.Since(startTime)
 	return result, nil
 }
 
 // GenerateAll generates all entities, transactions, and audit logs in one call.
-func (o *Orchestrator) GenerateAll() (*GenerationResult, error) {
+func (o *Orchestrator) GenerateAll(ctx context.Context) (*GenerationResult, error) {
+	if o.config.Only != nil {
+		return nil, fmt.Errorf("transactions need every entity table, but generation is limited to %s", o.config.Only)
+	}
+
 	// Generate entities first
-	entityResult, err := o.GenerateEntities()
+	entityResult, err := o.GenerateEntities(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate transactions
-	txnResult, err := o.GenerateTransactions()
+	txnResult, err := o.GenerateTransactions(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate audit logs
-	auditResult, err := o.GenerateAuditLogs()
+	auditResult, err := o.GenerateAuditLogs(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Combine results
 	entityResult.TransactionCount = txnResult.TransactionCount
-	entityResult.AuditLogCount = auditResult.AuditLogCount
+	entityResult.TransferCount = txnResult.TransferCount
+	entityResult.DisputeCount = txnResult.DisputeCount
+	entityResult.AuditLogCount = txnResult.AuditLogCount + auditResult.AuditLogCount
 	entityResult.Duration += txnResult.Duration + auditResult.Duration
 
 	return entityResult, nil
 }
 
+// sortsTransactions reports whether transaction CSV shards are written and
+// then rewritten in timestamp order
+func (o *Orchestrator) sortsTransactions() bool {
+	return o.config.SortTransactions && (o.config.Kafka == nil || !o.config.Kafka.Only)
+}
+
+// sessionAuditEstimate estimates session audit logs for progress reporting
+func (o *Orchestrator) sessionAuditEstimate() int64 {
+	return EstimateAuditLogCount(0, len(o.customers), o.historyMonths())
+}
+
 // log prints a message if verbose mode is enabled
 func (o *Orchestrator) log(format string, args ...interface{}) {
 	if o.verbose {
@@ -575,13 +1202,23 @@ func PrintSummary(result *GenerationResult) {
 	fmt.Println("=== Generation Complete ===")
 	fmt.Printf("Branches:      %d\n", result.BranchCount)
 	fmt.Printf("ATMs:          %d\n", result.ATMCount)
+	if result.ATMEventCount > 0 {
+		fmt.Printf("ATM Events:    %d\n", result.ATMEventCount)
+	}
 	fmt.Printf("Customers:     %d\n", result.CustomerCount)
 	fmt.Printf("Businesses:    %d\n", result.BusinessCount)
 	fmt.Printf("Accounts:      %d\n", result.AccountCount)
 	fmt.Printf("Beneficiaries: %d\n", result.BeneficiaryCount)
 	fmt.Printf("Transactions:  %d\n", result.TransactionCount)
+	if result.TransferCount > 0 {
+		fmt.Printf("Transfers:     %d\n", result.TransferCount)
+	}
+	if result.DisputeCount > 0 {
+		fmt.Printf("Disputes:      %d\n", result.DisputeCount)
+	}
 	fmt.Printf("Audit Logs:    %d\n", result.AuditLogCount)
 	fmt.Printf("Duration:      %s\n", result.Duration.Round(time.Millisecond))
+	fmt.Printf("Seed:          %d\n", result.Seed)
 	fmt.Println()
 }
 
@@ -0,0 +1,240 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryRange(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	asOf := date("2025-05-01")
+
+	for _, tc := range []struct {
+		name       string
+		years      int
+		start      time.Time
+		wantStart  time.Time
+		wantMonths int
+	}{
+		{"Years", 2, time.Time{}, date("2023-05-01"), 24},
+		{"ExplicitStart", 2, date("2025-03-15"), date("2025-03-15"), 2},
+		{"WholeMonths", 1, date("2025-02-01"), date("2025-02-01"), 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &Orchestrator{config: OrchestratorConfig{AsOfDate: asOf, YearsOfHistory: tc.years, StartDate: tc.start}}
+			if got := o.historyStart(); !got.Equal(tc.wantStart) {
+				t.Errorf("Expected history to start %s, got %s", tc.wantStart, got)
+			}
+			if got := o.historyMonths(); got != tc.wantMonths {
+				t.Errorf("Expected %d months of history, got %d", tc.wantMonths, got)
+			}
+		})
+	}
+
+	if got := spanYears(date("2022-05-02"), asOf); got != 3 {
+		t.Errorf("Expected a range just over 2 years to need 3, got %d", got)
+	}
+	if got := spanYears(date("2025-03-15"), asOf); got != 1 {
+		t.Errorf("Expected a short range to need 1 year, got %d", got)
+	}
+}
+
+func TestGenerationCancelled(t *testing.T) {
+	o, err := NewOrchestrator(OrchestratorConfig{
+		NumCustomers:                    50,
+		NumBusinesses:                   2,
+		NumBranches:                     2,
+		NumATMs:                         2,
+		YearsOfHistory:                  1,
+		OutputDir:                       t.TempDir(),
+		Seed:                            3,
+		AsOfDate:                        time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		TransactionsPerCustomerPerMonth: 10,
+		PayrollDay:                      25,
+		Workers:                         2,
+	}, OrchestratorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := o.GenerateEntities(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GenerateEntities with a cancelled context returned %v, want context.Canceled", err)
+	}
+
+	// Each phase stops at its first check and reports the cancellation
+	if _, err := o.GenerateEntities(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.GenerateTransactions(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateTransactions with a cancelled context returned %v, want context.Canceled", err)
+	}
+	if _, err := o.GenerateAuditLogs(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateAuditLogs with a cancelled context returned %v, want context.Canceled", err)
+	}
+}
+
+func TestAuditIDsUniqueWithMoreSessionsThanEstimated(t *testing.T) {
+	dir := t.TempDir()
+	o, err := NewOrchestrator(OrchestratorConfig{
+		NumCustomers:                    60,
+		NumBusinesses:                   2,
+		NumBranches:                     2,
+		NumATMs:                         4,
+		YearsOfHistory:                  1,
+		OutputDir:                       dir,
+		Seed:                            9,
+		AsOfDate:                        time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		TransactionsPerCustomerPerMonth: 10,
+		PayrollDay:                      25,
+		SessionsPerCustomerPerMonth:     40, // The ID estimate assumes 3
+		SessionTransactionRate:          0.5,
+		Workers:                         3,
+	}, OrchestratorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := o.GenerateAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reads the IDs of one audit shard set, failing on any seen before
+	seen := make(map[int64]bool)
+	readIDs := func(basename string) (ids []int64) {
+		files, err := FindShardedFiles(dir, basename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if basename == "audit_logs" && strings.HasPrefix(filepath.Base(f), TransactionAuditBasename) {
+				continue
+			}
+			err := ReadCSVRows(ctx, f, []string{"id"}, func(row []string) error {
+				id, err := strconv.ParseInt(row[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				if seen[id] {
+					t.Fatalf("audit ID %d is used twice", id)
+				}
+				seen[id] = true
+				ids = append(ids, id)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ids
+	}
+	txnIDs := readIDs(TransactionAuditBasename)
+	sessionIDs := readIDs("audit_logs")
+	if int64(len(sessionIDs)) <= o.sessionAuditEstimate() {
+		t.Fatalf("generated %d session audit logs, want more than the estimate of %d", len(sessionIDs), o.sessionAuditEstimate())
+	}
+
+	// Session audit logs are numbered after every transaction audit event
+	var lastTxnAuditID int64
+	for _, id := range txnIDs {
+		lastTxnAuditID = max(lastTxnAuditID, id)
+	}
+	for _, id := range sessionIDs {
+		if id <= lastTxnAuditID {
+			t.Fatalf("session audit ID %d is not above the last transaction audit ID %d", id, lastTxnAuditID)
+		}
+	}
+}
+
+func TestTransactionAuditFromShards(t *testing.T) {
+	ctx := context.Background()
+	// Generates a data set and returns its transaction audit events by ID
+	generate := func(fromShards bool) (map[int64]string, int, string) {
+		dir := t.TempDir()
+		o, err := NewOrchestrator(OrchestratorConfig{
+			NumCustomers:                    40,
+			NumBusinesses:                   2,
+			NumBranches:                     2,
+			NumATMs:                         4,
+			YearsOfHistory:                  1,
+			OutputDir:                       dir,
+			Seed:                            5,
+			AsOfDate:                        time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			TransactionsPerCustomerPerMonth: 10,
+			PayrollDay:                      25,
+			Workers:                         2,
+			MaxFileRows:                     2000,
+			TransactionAuditFromShards:      fromShards,
+		}, OrchestratorOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := o.GenerateAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		events := make(map[int64]string)
+		files, err := FindShardedFiles(dir, TransactionAuditBasename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			err := ReadCSVRows(ctx, f, []string{"id", "action", "transaction_id", "customer_id", "outcome"}, func(row []string) error {
+				id, err := strconv.ParseInt(row[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				events[id] = strings.Join(row[1:], ",")
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return events, result.AuditLogCount, dir
+	}
+
+	inline, inlineCount, _ := generate(false)
+	read, readCount, dir := generate(true)
+	if len(inline) == 0 {
+		t.Fatal("no transaction audit events were generated")
+	}
+	if readCount != inlineCount {
+		t.Errorf("counted %d audit logs reading the shards back, want %d", readCount, inlineCount)
+	}
+	if len(read) != len(inline) {
+		t.Errorf("read back %d transaction audit events, want %d", len(read), len(inline))
+	}
+	for id, want := range inline {
+		if got := read[id]; got != want {
+			t.Fatalf("audit event %d = %q reading the shards back, want %q", id, got, want)
+		}
+	}
+
+	// One audit shard (rolling over into part files) per transaction file
+	txnFiles, _ := FindShardedFiles(dir, "transactions")
+	auditFiles, _ := FindShardedFiles(dir, TransactionAuditBasename)
+	if len(txnFiles) < 3 {
+		t.Fatalf("only %d transaction files; expected part files to be read back", len(txnFiles))
+	}
+	for i := range txnFiles {
+		shard := ShardFilename(TransactionAuditBasename, i+1, len(txnFiles))
+		if !slices.ContainsFunc(auditFiles, func(f string) bool { return strings.HasPrefix(filepath.Base(f), shard) }) {
+			t.Errorf("no %s audit shard for %s", shard, filepath.Base(txnFiles[i]))
+		}
+	}
+}
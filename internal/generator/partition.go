@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Partitioning selects how transaction shards are split on disk
+type Partitioning string
+
+// Supported partitionings
+const (
+	PartitionNone    Partitioning = ""      // One shard per worker (default)
+	PartitionByMonth Partitioning = "month" // transactions/YYYY-MM/transactions_YYYY-MM_NNN.csv
+)
+
+// ParsePartitioning validates a --partition-by value; "none" and an empty
+// string both mean unpartitioned
+func ParsePartitioning(s string) (Partitioning, error) {
+	switch p := Partitioning(s); p {
+	case "", "none":
+		return PartitionNone, nil
+	case PartitionByMonth:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid partitioning %q (expected none or month)", s)
+	}
+}
+
+// monthPartition returns the partition a timestamp belongs to, as it appears
+// in the CSV (local time), so each file only holds its month's rows
+func monthPartition(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// monthPartitionWriter routes one worker's rows to a shard in each month's
+// partition directory. Rows arrive roughly in time order, so partitions more
+// than a month behind the latest one seen are closed to bound the number of
+// open files (and xz processes).
+type monthPartitionWriter struct {
+	cfg         CSVWriterConfig // OutputDir is the partition root; Filename the basename
+	shardNum    int
+	totalShards int
+
+	open   map[string]*CSVWriter
+	closed map[string]bool
+	latest string
+}
+
+func newMonthPartitionWriter(cfg CSVWriterConfig, shardNum, totalShards int) *monthPartitionWriter {
+	return &monthPartitionWriter{
+		cfg:         cfg,
+		shardNum:    shardNum,
+		totalShards: totalShards,
+		open:        make(map[string]*CSVWriter),
+		closed:      make(map[string]bool),
+	}
+}
+
+// WriteRow writes row to the shard for partition
+func (w *monthPartitionWriter) WriteRow(partition string, row []string) error {
+	writer, ok := w.open[partition]
+	if !ok {
+		var err error
+		if writer, err = w.openPartition(partition); err != nil {
+			return err
+		}
+	}
+	return writer.WriteRow(row)
+}
+
+// openPartition creates the shard for a partition not yet written to
+func (w *monthPartitionWriter) openPartition(partition string) (*CSVWriter, error) {
+	if w.closed[partition] {
+		return nil, fmt.Errorf("partition %s was already closed (rows out of time order)", partition)
+	}
+
+	cfg := w.cfg
+	cfg.OutputDir = JoinOutputPath(w.cfg.OutputDir, partition)
+	cfg.Filename = w.cfg.Filename + "_" + partition
+	writer, err := NewShardedCSVWriter(cfg, w.shardNum, w.totalShards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create partition %s: %w", partition, err)
+	}
+	w.open[partition] = writer
+
+	if partition > w.latest {
+		w.latest = partition
+		if err := w.closeBefore(previousMonth(partition)); err != nil {
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+// closeBefore closes the open partitions older than partition
+func (w *monthPartitionWriter) closeBefore(partition string) error {
+	for p, writer := range w.open {
+		if p >= partition {
+			continue
+		}
+		delete(w.open, p)
+		w.closed[p] = true
+		if err := writer.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes every open partition
+func (w *monthPartitionWriter) Close() error {
+	var err error
+	for _, p := range w.Partitions() {
+		if writer, ok := w.open[p]; ok {
+			if closeErr := writer.Close(); err == nil {
+				err = closeErr
+			}
+			delete(w.open, p)
+			w.closed[p] = true
+		}
+	}
+	return err
+}
+
+// Partitions returns the partitions written so far, in order
+func (w *monthPartitionWriter) Partitions() []string {
+	var partitions []string
+	for p := range w.open {
+		partitions = append(partitions, p)
+	}
+	for p := range w.closed {
+		partitions = append(partitions, p)
+	}
+	sort.Strings(partitions)
+	return partitions
+}
+
+// previousMonth returns the YYYY-MM partition before partition
+func previousMonth(partition string) string {
+	t, err := time.Parse("2006-01", partition)
+	if err != nil {
+		return partition
+	}
+	return monthPartition(t.AddDate(0, -1, 0))
+}
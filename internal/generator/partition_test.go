@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePartitioning(t *testing.T) {
+	for in, want := range map[string]Partitioning{"": PartitionNone, "none": PartitionNone, "month": PartitionByMonth} {
+		if got, err := ParsePartitioning(in); err != nil || got != want {
+			t.Errorf("ParsePartitioning(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParsePartitioning("day"); err == nil {
+		t.Error("Expected ParsePartitioning(\"day\") to fail")
+	}
+}
+
+func TestMonthPartitionWriter(t *testing.T) {
+	dir := t.TempDir()
+	w := newMonthPartitionWriter(CSVWriterConfig{OutputDir: dir, Filename: "transactions", Headers: []string{"id", "timestamp"}}, 2, 4)
+
+	write := func(id string, ts time.Time) error {
+		return w.WriteRow(monthPartition(ts), []string{id, FormatTime(ts)})
+	}
+	jan := time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 1, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 1, 0, 0, 0, time.UTC)
+	for i, ts := range []time.Time{jan, feb, jan, mar, feb} { // Stragglers within a month are accepted
+		if err := write(FormatInt(i+1), ts); err != nil {
+			t.Fatalf("Row %d: %v", i+1, err)
+		}
+	}
+	if err := write("6", jan); err == nil {
+		t.Error("Expected a row for a closed partition to fail")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(w.Partitions(), " "); got != "2024-01 2024-02 2024-03" {
+		t.Errorf("Unexpected partitions %s", got)
+	}
+
+	files, err := FindShardedFiles(dir, "transactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		"2024-01/transactions_2024-01_002.csv": 2,
+		"2024-02/transactions_2024-02_002.csv": 2,
+		"2024-03/transactions_2024-03_002.csv": 1,
+	}
+	if len(files) != len(want) {
+		t.Fatalf("Expected %d partition files, got %v", len(want), files)
+	}
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f)
+		rows := 0
+		if err := ReadCSVRows(context.Background(), f, []string{"id"}, func([]string) error { rows++; return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if rows != want[filepath.ToSlash(rel)] {
+			t.Errorf("%s: expected %d rows, got %d", rel, want[filepath.ToSlash(rel)], rows)
+		}
+	}
+}
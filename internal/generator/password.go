@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// PasswordScheme is how generated password hashes are computed. Every scheme
+// is reproducible: salts are derived from the seeded random password.
+type PasswordScheme string
+
+// Supported password schemes
+const (
+	PasswordSchemeFast   PasswordScheme = "fast"   // Unsalted SHA-256 hex (default)
+	PasswordSchemeSHA256 PasswordScheme = "sha256" // Salted SHA-256: sha256$<salt>$<hash>
+	PasswordSchemeBcrypt PasswordScheme = "bcrypt" // $2a$ bcrypt, verifiable by any bcrypt library
+)
+
+// ParsePasswordScheme validates a scheme name; an empty string means fast
+func ParsePasswordScheme(s string) (PasswordScheme, error) {
+	switch scheme := PasswordScheme(s); scheme {
+	case "":
+		return PasswordSchemeFast, nil
+	case PasswordSchemeFast, PasswordSchemeSHA256, PasswordSchemeBcrypt:
+		return scheme, nil
+	default:
+		return "", fmt.Errorf("invalid password hash scheme %q (expected fast, sha256 or bcrypt)", s)
+	}
+}
+
+// bcryptMinCost is the lowest work factor bcrypt accepts
+const bcryptMinCost = 4
+
+// PasswordHasher hashes the passwords of generated customers and businesses.
+// The zero value uses PasswordSchemeFast.
+type PasswordHasher struct {
+	Scheme     PasswordScheme
+	BcryptCost int // Work factor for bcrypt (each step doubles the time; below 4 = 4)
+}
+
+// Hash returns the stored form of password under the configured scheme
+func (h PasswordHasher) Hash(password string) string {
+	switch h.Scheme {
+	case PasswordSchemeSHA256:
+		salt := passwordSalt(password)
+		sum := sha256.Sum256(append(salt, password...))
+		return "sha256$" + hex.EncodeToString(salt) + "$" + hex.EncodeToString(sum[:])
+	case PasswordSchemeBcrypt:
+		return bcryptHash(password, passwordSalt(password), max(h.BcryptCost, bcryptMinCost))
+	default:
+		sum := sha256.Sum256([]byte(password))
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// passwordSalt derives a 16-byte salt from the password itself, which is
+// random per user but the same on every run with the same seed
+func passwordSalt(password string) []byte {
+	sum := sha256.Sum256([]byte("salt:" + password))
+	return sum[:16]
+}
+
+// bcryptEncoding is bcrypt's base64 alphabet, written without padding
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+
+// bcryptHash computes a $2a$ bcrypt hash with a caller-chosen salt (the
+// bcrypt package always draws a random one). Passwords here are well under
+// bcrypt's 72-byte limit.
+func bcryptHash(password string, salt []byte, cost int) string {
+	// Like C bcrypt, the key includes the terminating NUL
+	key := append([]byte(password), 0)
+	c, err := blowfish.NewSaltedCipher(key, salt)
+	if err != nil {
+		panic(fmt.Sprintf("bcrypt: %v", err)) // Only fails for an empty key
+	}
+	for i := 0; i < 1<<cost; i++ {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(salt, c)
+	}
+
+	ciphertext := []byte("OrpheanBeholderScryDoubt")
+	for i := 0; i < len(ciphertext); i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(ciphertext[i:i+8], ciphertext[i:i+8])
+		}
+	}
+	// Only 23 of the 24 bytes are encoded, as in the original implementation
+	return fmt.Sprintf("$2a$%02d$%s%s", cost, bcryptEncoding.EncodeToString(salt), bcryptEncoding.EncodeToString(ciphertext[:23]))
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	const password = "hunter2hunter2"
+
+	fast := PasswordHasher{}.Hash(password)
+	if len(fast) != 64 || fast != (PasswordHasher{Scheme: PasswordSchemeFast}).Hash(password) {
+		t.Errorf("fast hash %q, expected 64 hex characters", fast)
+	}
+
+	salted := PasswordHasher{Scheme: PasswordSchemeSHA256}.Hash(password)
+	if parts := strings.Split(salted, "$"); len(parts) != 3 || parts[0] != "sha256" || len(parts[1]) != 32 || len(parts[2]) != 64 {
+		t.Errorf("sha256 hash %q, expected sha256$<salt>$<hash>", salted)
+	}
+	if other := (PasswordHasher{Scheme: PasswordSchemeSHA256}).Hash("different"); other[7:39] == salted[7:39] {
+		t.Error("expected different passwords to get different salts")
+	}
+
+	hasher := PasswordHasher{Scheme: PasswordSchemeBcrypt, BcryptCost: 5}
+	hash := hasher.Hash(password)
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		t.Fatalf("bcrypt hash %q does not verify: %v", hash, err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("wrong")); err == nil {
+		t.Error("expected the wrong password to fail verification")
+	}
+	if cost, _ := bcrypt.Cost([]byte(hash)); cost != 5 {
+		t.Errorf("bcrypt cost %d, expected 5", cost)
+	}
+	if again := hasher.Hash(password); again != hash {
+		t.Errorf("bcrypt hash not reproducible: %q then %q", hash, again)
+	}
+}
+
+func TestParsePasswordScheme(t *testing.T) {
+	if scheme, err := ParsePasswordScheme(""); err != nil || scheme != PasswordSchemeFast {
+		t.Errorf("empty scheme parsed as %q, %v", scheme, err)
+	}
+	if _, err := ParsePasswordScheme("argon2"); err == nil {
+		t.Error("expected an error for an unsupported scheme")
+	}
+}
@@ -247,6 +247,29 @@ func (dp *DailyPattern) ExpectedTransactionsPerHour(dailyTarget int) [24]float64
 	return result
 }
 
+// TimeOfDay returns a time anywhere in the day weighted by the pattern's
+// hourly multipliers, so night-time peaks are kept.
+// The returned hour is 0-23, and minute is 0-59.
+func (dp *DailyPattern) TimeOfDay(rngValue float64) (hour int, minute int) {
+	var totalWeight float64
+	for _, w := range dp.hourlyMultipliers {
+		totalWeight += w
+	}
+
+	target := rngValue * totalWeight
+	hour = 23
+	for h, w := range dp.hourlyMultipliers {
+		if target < w {
+			hour = h
+			break
+		}
+		target -= w
+	}
+
+	minuteFraction := math.Mod(rngValue*1000, 1.0)
+	return hour, int(minuteFraction * 60)
+}
+
 // TimeInActiveWindow returns a time within the active banking window (6 AM - 10 PM)
 // weighted by the pattern's hourly multipliers.
 // The returned hour is 0-23, and minute is 0-59.
@@ -321,6 +321,7 @@ type TransactionTypeAmounts struct {
 	SmallPurchase   *AmountDistribution // Coffee, snacks: $2-$20
 	MediumPurchase  *AmountDistribution // Groceries, meals: $20-$150
 	LargePurchase   *AmountDistribution // Electronics, clothes: $100-$1000
+	LuxuryPurchase  *AmountDistribution // Jewellery, designer goods, travel: $1000-$20000
 	ATMWithdrawal   *AmountDistribution // Typical ATM: $20-$500
 	BillPayment     *AmountDistribution // Utilities, subscriptions: $50-$500
 	RentMortgage    *AmountDistribution // Housing: $800-$3000
@@ -340,6 +341,9 @@ func NewTransactionTypeAmounts() *TransactionTypeAmounts {
 		// Large purchases: normal around $300
 		LargePurchase: NewNormalAmountRange(10000, 100000, 0.3, 0.3), // $100-$1000, mean ~$300
 
+		// Luxury purchases: exponential (mostly low thousands, occasionally much more)
+		LuxuryPurchase: NewExponentialAmountRange(100000, 2000000), // $1000-$20000
+
 		// ATM: bimodal ($20-$60 common, occasionally $200-$500)
 		ATMWithdrawal: NewNormalAmountRange(2000, 50000, 0.2, 0.25), // $20-$500, mean ~$100
 
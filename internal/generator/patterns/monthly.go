@@ -319,6 +319,17 @@ func (fp *FullPattern) GetMultiplier(t time.Time) float64 {
 	return math.Sqrt(combined)
 }
 
+// DayMultiplier returns the combined weekly and monthly multiplier for a date,
+// ignoring time of day. Uses the same sqrt smoothing as GetMultiplier.
+func (fp *FullPattern) DayMultiplier(t time.Time) float64 {
+	return math.Sqrt(fp.weekly.GetMultiplierForDate(t) * fp.monthly.GetMultiplierForDate(t))
+}
+
+// TimeOfDay returns a time of day drawn from the daily pattern.
+func (fp *FullPattern) TimeOfDay(rngValue float64) (hour int, minute int) {
+	return fp.daily.TimeOfDay(rngValue)
+}
+
 // GetRawMultiplier returns the raw combined multiplier without normalization.
 // Use for analysis or when extreme spikes are desired.
 func (fp *FullPattern) GetRawMultiplier(t time.Time) float64 {
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// Workforce size of each payroll account. Every payroll batch pays each
+// employee a salary, so the batch debit equals the sum of its credits.
+const (
+	payrollMinEmployees = 5
+	payrollMaxEmployees = 40
+)
+
+// payrollEmployees returns the employee checking accounts a payroll account
+// pays, sampling a stable workforce on first use. Employees are retail
+// checking accounts in the payroll account's currency.
+func (g *transactionCore) payrollEmployees(account GeneratedAccount) []int64 {
+	if employees, ok := g.payrollWorkforce[account.Account.ID]; ok {
+		return employees
+	}
+
+	pool := g.accounts.employeeIDs[account.Account.Currency]
+	n := min(g.rng.IntRange(payrollMinEmployees, payrollMaxEmployees), len(pool))
+	employees := make([]int64, 0, n)
+	picked := make(map[int]bool, n)
+	for len(employees) < n {
+		i := g.rng.IntN(len(pool))
+		if !picked[i] {
+			picked[i] = true
+			employees = append(employees, pool[i])
+		}
+	}
+	g.payrollWorkforce[account.Account.ID] = employees
+	return employees
+}
+
+// payrollShares draws a salary for each employee of a payroll run in currency
+func (g *transactionCore) payrollShares(currency models.Currency, employees []int64) []int64 {
+	shares := make([]int64, len(employees))
+	for i := range employees {
+		shares[i] = g.inMinorUnits(currency, g.amounts.Salary.GenerateAmount(g.rng.Float64(), g.rng.NormalFloat64()))
+	}
+	return shares
+}
+
+// payrollCredit creates one employee's salary credit from a payroll batch. It
+// shares the batch's reference and links to it; the employee's running
+// balance is only updated if it is being tracked.
+func (g *transactionCore) payrollCredit(
+	batch models.Transaction,
+	employeeID int64,
+	amount int64,
+	balances map[int64]int64,
+) models.Transaction {
+	balanceAfter := balances[employeeID]
+	if _, exists := balances[employeeID]; exists {
+		balanceAfter += amount
+		balances[employeeID] = balanceAfter
+	}
+
+	batchID := batch.ID
+	return models.Transaction{
+		ID:                    g.nextID(),
+		ReferenceNumber:       batch.ReferenceNumber,
+		AccountID:             employeeID,
+		CounterpartyAccountID: &batch.AccountID,
+		Type:                  models.TxTypeSalary,
+		Status:                models.TxStatusCompleted,
+		Channel:               models.ChannelACH,
+		Amount:                amount,
+		Currency:              batch.Currency,
+		BalanceAfter:          balanceAfter,
+		Description:           "Direct Deposit - Payroll",
+		Metadata:              "{}",
+		LinkedTransactionID:   &batchID,
+		Timestamp:             batch.Timestamp,
+		PostedAt:              batch.PostedAt,
+		ValueDate:             batch.ValueDate,
+	}
+}
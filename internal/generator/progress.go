@@ -403,11 +403,23 @@ type AggregatedProgressReporter struct {
 	lastPrint    time.Time
 	done         bool
 
+	// Recent (time, count) samples for the rolling throughput rate
+	samples []rateSample
+
 	// Channels
 	progressChan chan workerProgress
 	doneChan     chan struct{}
 }
 
+// rateWindow is how far back the rolling throughput rate looks
+const rateWindow = 10 * time.Second
+
+// rateSample is a point-in-time aggregated count
+type rateSample struct {
+	at    time.Time
+	count int64
+}
+
 // workerProgress represents a progress update from a worker
 type workerProgress struct {
 	workerID int
@@ -542,11 +554,38 @@ func (a *AggregatedProgressReporter) render() {
 	}
 	a.lastPrint = now
 
-	elapsed := time.Since(a.startTime)
-	rate := float64(a.current) / elapsed.Seconds()
+	fmt.Fprint(a.output, a.progressLine(now))
+}
+
+// rollingRate records a sample at now and returns rows/sec over the last
+// rateWindow, falling back to the overall average until the window has
+// at least a second of history.
+func (a *AggregatedProgressReporter) rollingRate(now time.Time) float64 {
+	a.samples = append(a.samples, rateSample{at: now, count: a.current})
+
+	// Drop samples that have aged out, keeping the newest one before the cutoff
+	cutoff := now.Add(-rateWindow)
+	drop := 0
+	for drop+1 < len(a.samples) && !a.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	a.samples = a.samples[drop:]
+
+	oldest := a.samples[0]
+	if span := now.Sub(oldest.at); span >= time.Second {
+		return float64(a.current-oldest.count) / span.Seconds()
+	}
+
+	elapsed := now.Sub(a.startTime)
 	if elapsed.Seconds() < 0.01 {
-		rate = 0
+		return 0
 	}
+	return float64(a.current) / elapsed.Seconds()
+}
+
+// progressLine formats the progress display for the given moment
+func (a *AggregatedProgressReporter) progressLine(now time.Time) string {
+	rate := a.rollingRate(now)
 
 	var sb strings.Builder
 
@@ -559,7 +598,13 @@ func (a *AggregatedProgressReporter) render() {
 		sb.WriteString(": ")
 	}
 
-	if a.total > 0 {
+	if a.total > 0 && a.current > a.total {
+		// The estimate was low; a percentage or ETA would be meaningless
+		sb.WriteString(fmt.Sprintf("%d (estimate of %d exceeded)", a.current, a.total))
+		if a.isTTY {
+			sb.WriteString(" [" + strings.Repeat("=", 20) + "]")
+		}
+	} else if a.total > 0 {
 		pct := float64(a.current) / float64(a.total) * 100
 		sb.WriteString(fmt.Sprintf("%d/%d (%.1f%%)", a.current, a.total, pct))
 
@@ -596,7 +641,7 @@ func (a *AggregatedProgressReporter) render() {
 		sb.WriteString("\n")
 	}
 
-	fmt.Fprint(a.output, sb.String())
+	return sb.String()
 }
 
 // Finish completes the aggregated progress and prints final stats
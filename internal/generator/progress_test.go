@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregatedProgressRollingRate(t *testing.T) {
+	a := NewAggregatedProgressReporter(AggregatedProgressConfig{Total: 10000, WorkerCount: 1, Output: io.Discard})
+	start := a.startTime
+
+	// A fast start followed by a slowdown: the rolling rate should reflect
+	// only the last rateWindow, not the overall average
+	a.current = 5000
+	a.rollingRate(start.Add(time.Second))
+	a.current = 5200
+	a.rollingRate(start.Add(20 * time.Second))
+	a.current = 5300
+	rate := a.rollingRate(start.Add(30 * time.Second))
+	if rate != 10 {
+		t.Errorf("rolling rate = %.2f, want 10", rate)
+	}
+
+	line := a.progressLine(start.Add(31 * time.Second))
+	if !strings.Contains(line, "5300/10000") || !strings.Contains(line, "ETA:") {
+		t.Errorf("progress line %q missing count or ETA", line)
+	}
+}
+
+func TestAggregatedProgressExceededEstimate(t *testing.T) {
+	a := NewAggregatedProgressReporter(AggregatedProgressConfig{Total: 100, WorkerCount: 2, Output: io.Discard})
+	a.current = 150
+
+	line := a.progressLine(a.startTime.Add(5 * time.Second))
+	if !strings.Contains(line, "estimate of 100 exceeded") {
+		t.Errorf("progress line %q does not flag the exceeded estimate", line)
+	}
+	if strings.Contains(line, "ETA") || strings.Contains(line, "%") {
+		t.Errorf("progress line %q shows a percentage or ETA past the estimate", line)
+	}
+}
@@ -0,0 +1,231 @@
+package generator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PIIMode is how personal fields (names, emails, phones and addresses) are
+// rewritten before entities are written, for data sets that will be shared
+type PIIMode string
+
+// Supported PII modes
+const (
+	PIIModeNone     PIIMode = ""         // Written as generated (default)
+	PIIModeTag      PIIMode = "tag"      // Prefixed with SYN-; emails moved to the reserved .invalid TLD
+	PIIModeTokenize PIIMode = "tokenize" // Format-preserving tokens; emails moved to example.com
+)
+
+// ParsePIIMode validates a mode name; an empty string or "none" disables pseudonymization
+func ParsePIIMode(s string) (PIIMode, error) {
+	switch mode := PIIMode(s); mode {
+	case "", "none":
+		return PIIModeNone, nil
+	case PIIModeTag, PIIModeTokenize:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid PII mode %q (expected none, tag or tokenize)", s)
+	}
+}
+
+// syntheticTag marks tagged values as synthetic
+const syntheticTag = "SYN-"
+
+// PIIMapping records one pseudonymized value so tokens can be traced back
+type PIIMapping struct {
+	Table     string
+	ID        int64
+	Field     string
+	Original  string
+	Pseudonym string
+}
+
+// Pseudonymizer rewrites the personal fields of generated entities on their
+// way to the writers; the generated models themselves are left untouched.
+// Tokens are keyed by the seed, so a value maps to the same token in every
+// table and every run with that seed. A nil Pseudonymizer passes entities through.
+type Pseudonymizer struct {
+	mode    PIIMode
+	key     []byte
+	mapping []PIIMapping
+}
+
+// NewPseudonymizer creates a pseudonymizer, or returns nil for PIIModeNone
+func NewPseudonymizer(mode PIIMode, seed int64) *Pseudonymizer {
+	if mode == PIIModeNone {
+		return nil
+	}
+	key := sha256.Sum256(binary.BigEndian.AppendUint64([]byte("loadgen-pii"), uint64(seed)))
+	return &Pseudonymizer{mode: mode, key: key[:]}
+}
+
+// Customers returns copies of the customers with personal fields rewritten
+func (p *Pseudonymizer) Customers(customers []GeneratedCustomer) []GeneratedCustomer {
+	if p == nil {
+		return customers
+	}
+	out := make([]GeneratedCustomer, len(customers))
+	for i, gc := range customers {
+		c := &gc.Customer
+		p.rewrite("customers", c.ID, "first_name", piiName, &c.FirstName)
+		p.rewrite("customers", c.ID, "last_name", piiName, &c.LastName)
+		// The username starts with the first name, so it is rebuilt from the pseudonym
+		if c.Username != "" {
+			original := c.Username
+			c.Username = customerUsername(c.FirstName, c.ID)
+			p.mapping = append(p.mapping, PIIMapping{Table: "customers", ID: c.ID, Field: "username", Original: original, Pseudonym: c.Username})
+		}
+		p.rewrite("customers", c.ID, "email", piiEmail, &c.Email)
+		p.rewrite("customers", c.ID, "phone", piiPhone, &c.Phone)
+		p.rewrite("customers", c.ID, "address_line1", piiAddress, &c.AddressLine1)
+		p.rewrite("customers", c.ID, "address_line2", piiAddress, &c.AddressLine2)
+		out[i] = gc
+	}
+	return out
+}
+
+// Businesses returns copies of the businesses with contact fields rewritten.
+// Business names are not personal data and are kept.
+func (p *Pseudonymizer) Businesses(businesses []GeneratedBusiness) []GeneratedBusiness {
+	if p == nil {
+		return businesses
+	}
+	out := make([]GeneratedBusiness, len(businesses))
+	for i, gb := range businesses {
+		c := &gb.Customer
+		p.rewrite("businesses", c.ID, "email", piiEmail, &c.Email)
+		p.rewrite("businesses", c.ID, "phone", piiPhone, &c.Phone)
+		p.rewrite("businesses", c.ID, "address_line1", piiAddress, &c.AddressLine1)
+		p.rewrite("businesses", c.ID, "address_line2", piiAddress, &c.AddressLine2)
+		out[i] = gb
+	}
+	return out
+}
+
+// Beneficiaries returns copies of the beneficiaries with names and addresses rewritten
+func (p *Pseudonymizer) Beneficiaries(beneficiaries []GeneratedBeneficiary) []GeneratedBeneficiary {
+	if p == nil {
+		return beneficiaries
+	}
+	out := make([]GeneratedBeneficiary, len(beneficiaries))
+	for i, gb := range beneficiaries {
+		b := &gb.Beneficiary
+		p.rewrite("beneficiaries", b.ID, "name", piiName, &b.Name)
+		p.rewrite("beneficiaries", b.ID, "nickname", piiName, &b.Nickname)
+		p.rewrite("beneficiaries", b.ID, "address_line1", piiAddress, &b.AddressLine1)
+		p.rewrite("beneficiaries", b.ID, "address_line2", piiAddress, &b.AddressLine2)
+		out[i] = gb
+	}
+	return out
+}
+
+// piiKind groups fields that share a token space, so a name is tokenized the
+// same way whether it is a customer's last name or part of a beneficiary's name
+type piiKind string
+
+const (
+	piiName    piiKind = "name"
+	piiEmail   piiKind = "email"
+	piiPhone   piiKind = "phone"
+	piiAddress piiKind = "address"
+)
+
+// rewrite pseudonymizes one field in place and records the mapping
+func (p *Pseudonymizer) rewrite(table string, id int64, field string, kind piiKind, value *string) {
+	if *value == "" {
+		return
+	}
+	original := *value
+	*value = p.pseudonym(kind, original)
+	p.mapping = append(p.mapping, PIIMapping{Table: table, ID: id, Field: field, Original: original, Pseudonym: *value})
+}
+
+// pseudonym returns the replacement for a value under the configured mode
+func (p *Pseudonymizer) pseudonym(kind piiKind, value string) string {
+	if kind == piiEmail {
+		if p.mode == PIIModeTag {
+			return value + ".invalid"
+		}
+		// The whole address keys the token, so addresses that differ
+		// only by domain still get distinct tokens
+		local, _, _ := strings.Cut(value, "@")
+		return p.token(kind, value, local) + "@example.com"
+	}
+	if p.mode == PIIModeTag {
+		return syntheticTag + value
+	}
+	return p.token(kind, value, value)
+}
+
+// token replaces each letter with a letter of the same case and each digit
+// with a digit, keeping spaces and punctuation, using an HMAC of source as
+// the keystream. Non-ASCII letters become ASCII ones.
+func (p *Pseudonymizer) token(kind piiKind, source, value string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(source))
+	stream := mac.Sum(nil)
+
+	var b strings.Builder
+	b.Grow(len(value))
+	i := 0
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+		if i == len(stream) {
+			next := sha256.Sum256(stream)
+			stream = append(stream, next[:]...)
+		}
+		n := stream[i]
+		i++
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteByte('0' + n%10)
+		case unicode.IsUpper(r):
+			b.WriteByte('A' + n%26)
+		default:
+			b.WriteByte('a' + n%26)
+		}
+	}
+	return b.String()
+}
+
+// PIIMappingHeaders returns the CSV headers for the mapping file
+func PIIMappingHeaders() []string {
+	return []string{"table", "id", "field", "original", "pseudonym"}
+}
+
+// WriteMapping writes every value pseudonymized so far to a CSV file at path.
+// Keep it apart from the shared data set: it reverses the pseudonymization.
+func (p *Pseudonymizer) WriteMapping(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create PII mapping file: %w", err)
+	}
+	w := csv.NewWriter(f)
+	w.Write(PIIMappingHeaders())
+	if p != nil {
+		for _, m := range p.mapping {
+			w.Write([]string{m.Table, strconv.FormatInt(m.ID, 10), m.Field, m.Original, m.Pseudonym})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write PII mapping file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close PII mapping file: %w", err)
+	}
+	return nil
+}
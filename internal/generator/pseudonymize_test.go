@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func testPIICustomers() []GeneratedCustomer {
+	return []GeneratedCustomer{
+		{Customer: models.Customer{ID: 1, FirstName: "María", LastName: "García", Username: "maria1", Email: "maria.garcia@gmail.com",
+			Phone: "+34 6123456789", AddressLine1: "12 Calle Mayor"}},
+		{Customer: models.Customer{ID: 2, FirstName: "Tom", LastName: "García", Username: "tom2", Email: "maria.garcia@yahoo.com",
+			Phone: "+1 2025550143", AddressLine1: "9 King Street", AddressLine2: "Apt 4"}},
+	}
+}
+
+func TestPseudonymizerTokenize(t *testing.T) {
+	customers := testPIICustomers()
+	p := NewPseudonymizer(PIIModeTokenize, 7)
+	got := p.Customers(customers)
+
+	if customers[0].Customer.FirstName != "María" {
+		t.Fatal("generated customers were modified")
+	}
+	a, b := got[0].Customer, got[1].Customer
+	if a.FirstName == "María" || a.Phone == customers[0].Customer.Phone || a.AddressLine1 == customers[0].Customer.AddressLine1 {
+		t.Errorf("fields not tokenized: %+v", a)
+	}
+	if len(a.FirstName) != 5 || a.FirstName[0] < 'A' || a.FirstName[0] > 'Z' || strings.ToLower(a.FirstName[1:]) != a.FirstName[1:] {
+		t.Errorf("first name token %q does not keep the name's shape", a.FirstName)
+	}
+	for i, c := range []models.Customer{a, b} {
+		original := strings.ToLower(customers[i].Customer.FirstName)
+		if strings.Contains(c.Username, original) || c.Username != customerUsername(c.FirstName, c.ID) {
+			t.Errorf("username %q not rebuilt from the first name token %q", c.Username, c.FirstName)
+		}
+	}
+	if !strings.HasPrefix(a.Phone, "+") || a.Phone[3] != ' ' || len(a.Phone) != len(customers[0].Customer.Phone) {
+		t.Errorf("phone token %q does not keep the number's format", a.Phone)
+	}
+	if a.LastName != b.LastName {
+		t.Errorf("same last name tokenized differently: %q and %q", a.LastName, b.LastName)
+	}
+	if !strings.HasSuffix(a.Email, "@example.com") || a.Email == b.Email {
+		t.Errorf("emails %q and %q, expected distinct example.com addresses", a.Email, b.Email)
+	}
+
+	again := NewPseudonymizer(PIIModeTokenize, 7).Customers(customers)
+	if again[0].Customer != a {
+		t.Error("tokens not reproducible with the same seed")
+	}
+	if other := NewPseudonymizer(PIIModeTokenize, 8).Customers(customers); other[0].Customer.LastName == a.LastName {
+		t.Error("expected a different seed to give different tokens")
+	}
+}
+
+func TestPseudonymizerTag(t *testing.T) {
+	got := NewPseudonymizer(PIIModeTag, 7).Customers(testPIICustomers())
+	c := got[1].Customer
+	if c.FirstName != "SYN-Tom" || c.AddressLine2 != "SYN-Apt 4" || c.Phone != "SYN-+1 2025550143" {
+		t.Errorf("unexpected tagged customer %+v", c)
+	}
+	if c.Email != "maria.garcia@yahoo.com.invalid" {
+		t.Errorf("tagged email %q, expected the .invalid TLD", c.Email)
+	}
+	if c.Username != "syn-2" {
+		t.Errorf("tagged username %q, expected one rebuilt from the tagged first name", c.Username)
+	}
+	if got[0].Customer.AddressLine2 != "" {
+		t.Error("empty fields should stay empty")
+	}
+}
+
+func TestPseudonymizerMapping(t *testing.T) {
+	if NewPseudonymizer(PIIModeNone, 7) != nil {
+		t.Fatal("expected no pseudonymizer for mode none")
+	}
+	var none *Pseudonymizer
+	if got := none.Customers(testPIICustomers()); got[0].Customer.FirstName != "María" {
+		t.Error("nil pseudonymizer should pass customers through")
+	}
+
+	p := NewPseudonymizer(PIIModeTokenize, 7)
+	got := p.Customers(testPIICustomers())
+	path := filepath.Join(t.TempDir(), "pii.csv")
+	if err := p.WriteMapping(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if lines[0] != strings.Join(PIIMappingHeaders(), ",") {
+		t.Errorf("header %q", lines[0])
+	}
+	// 6 fields for the first customer, 7 for the second
+	if len(lines) != 14 {
+		t.Fatalf("expected 13 mappings, got %d", len(lines)-1)
+	}
+	want := "customers,1,first_name,María," + got[0].Customer.FirstName
+	if lines[1] != want {
+		t.Errorf("first mapping %q, expected %q", lines[1], want)
+	}
+	want = "customers,1,username,maria1," + got[0].Customer.Username
+	if lines[3] != want {
+		t.Errorf("username mapping %q, expected %q", lines[3], want)
+	}
+}
+
+func TestParsePIIMode(t *testing.T) {
+	for in, want := range map[string]PIIMode{"": PIIModeNone, "none": PIIModeNone, "tag": PIIModeTag, "tokenize": PIIModeTokenize} {
+		if got, err := ParsePIIMode(in); err != nil || got != want {
+			t.Errorf("ParsePIIMode(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParsePIIMode("hash"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
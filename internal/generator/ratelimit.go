@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// writeLimiterSlack is how far ahead of schedule writers may run before
+// they sleep, so fast rates aren't paced with microsecond sleeps
+const writeLimiterSlack = 10 * time.Millisecond
+
+// WriteRate is a write throughput cap in rows or uncompressed bytes per second
+type WriteRate struct {
+	RowsPerSec  float64
+	BytesPerSec float64
+}
+
+// byteUnits maps the size suffixes ParseWriteRate accepts to bytes
+var byteUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseWriteRate parses a rate such as "50000" (rows/sec) or "20MB"
+// (bytes/sec; B, KB, MB and GB are accepted). An optional "/s" suffix is
+// ignored.
+func ParseWriteRate(s string) (WriteRate, error) {
+	v := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "/S")
+	unit := 0.0
+	for _, u := range byteUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v, unit = strings.TrimSpace(strings.TrimSuffix(v, u.suffix)), u.bytes
+			break
+		}
+	}
+	v = strings.TrimSpace(strings.TrimSuffix(v, "ROWS"))
+
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 {
+		return WriteRate{}, fmt.Errorf("invalid write rate %q (expected rows/sec like 50000, or bytes/sec like 20MB)", s)
+	}
+	if unit > 0 {
+		return WriteRate{BytesPerSec: n * unit}, nil
+	}
+	return WriteRate{RowsPerSec: n}, nil
+}
+
+// String formats the rate for display
+func (r WriteRate) String() string {
+	if r.BytesPerSec > 0 {
+		return fmt.Sprintf("%.1f MB/s", r.BytesPerSec/(1<<20))
+	}
+	return fmt.Sprintf("%.0f rows/s", r.RowsPerSec)
+}
+
+// WriteLimiter paces CSV writes to a WriteRate. One limiter is shared by
+// all writers, so the cap applies to the run as a whole. A nil
+// *WriteLimiter does not limit.
+type WriteLimiter struct {
+	rate WriteRate
+	mu   sync.Mutex
+	next time.Time // When the writes reserved so far are due to have finished
+}
+
+// NewWriteLimiter returns a limiter for rate
+func NewWriteLimiter(rate WriteRate) *WriteLimiter {
+	return &WriteLimiter{rate: rate}
+}
+
+// Wait blocks until rows rows totalling bytes bytes may be written
+func (l *WriteLimiter) Wait(rows, bytes int) {
+	if l == nil {
+		return
+	}
+	var cost float64
+	if l.rate.RowsPerSec > 0 {
+		cost = float64(rows) / l.rate.RowsPerSec
+	} else if l.rate.BytesPerSec > 0 {
+		cost = float64(bytes) / l.rate.BytesPerSec
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(cost * float64(time.Second)))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+
+	if delay > writeLimiterSlack {
+		time.Sleep(delay)
+	}
+}
+
+// rowBytes estimates a CSV row's size: its fields plus separators and newline
+func rowBytes(row []string) int {
+	n := len(row)
+	for _, field := range row {
+		n += len(field)
+	}
+	return n
+}
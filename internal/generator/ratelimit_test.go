@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWriteRate(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want WriteRate
+	}{
+		{"50000", WriteRate{RowsPerSec: 50000}},
+		{"2000 rows/s", WriteRate{RowsPerSec: 2000}},
+		{"20MB", WriteRate{BytesPerSec: 20 << 20}},
+		{"512kb/s", WriteRate{BytesPerSec: 512 << 10}},
+		{"1.5GB", WriteRate{BytesPerSec: 1.5 * (1 << 30)}},
+	} {
+		got, err := ParseWriteRate(tc.in)
+		if err != nil {
+			t.Errorf("ParseWriteRate(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseWriteRate(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+
+	for _, in := range []string{"", "fast", "0", "-5MB", "10TB"} {
+		if _, err := ParseWriteRate(in); err == nil {
+			t.Errorf("Expected ParseWriteRate(%q) to fail", in)
+		}
+	}
+}
+
+func TestWriteLimiterPaces(t *testing.T) {
+	l := NewWriteLimiter(WriteRate{RowsPerSec: 1000})
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		l.Wait(1, 0)
+	}
+	// 100 rows at 1000/s is 100ms, less the slack writers may run ahead
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond-writeLimiterSlack {
+		t.Errorf("Expected 100 rows to take about 100ms, took %v", elapsed)
+	}
+
+	var unlimited *WriteLimiter
+	unlimited.Wait(1000000, 0) // A nil limiter never blocks
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// uploadRowBytes is a generous average size of a CSV or Avro row, used to
+// turn a file's expected row count into an expected upload size
+const uploadRowBytes = 256
+
+// S3Writer streams data to an S3 object through the external aws CLI, which
+// must be installed. It spawns `aws s3 cp - s3://bucket/key`, which reads
+// stdin and uploads it with multipart upload, so nothing is staged on local
+// disk. The CLI's default 8 MB parts cap a stream at 10,000 parts (~80 GB,
+// and it warns from 50 GB), so large files pass --expected-size and get
+// proportionally larger parts.
+type S3Writer struct {
+	cmd     *exec.Cmd      // aws subprocess
+	stdin   io.WriteCloser // Pipe to aws stdin
+	url     string         // Destination s3:// URL
+	mu      sync.Mutex
+	closed  bool
+	waitErr error         // Error from aws process
+	waitCh  chan struct{} // Signal when aws completes
+}
+
+// IsS3Path returns true if path is an s3:// URL
+func IsS3Path(path string) bool {
+	return strings.HasPrefix(path, "s3://")
+}
+
+// JoinOutputPath joins an output directory (local path or s3:// prefix) and a filename
+func JoinOutputPath(outputDir, name string) string {
+	if IsS3Path(outputDir) {
+		return strings.TrimSuffix(outputDir, "/") + "/" + name
+	}
+	return filepath.Join(outputDir, name)
+}
+
+// expectedUploadSize estimates the bytes of a file expected to hold rows rows,
+// or at most maxRows when files roll over (0 = unknown or unlimited)
+func expectedUploadSize(rows, maxRows int64) int64 {
+	if maxRows > 0 && (rows <= 0 || rows > maxRows) {
+		rows = maxRows
+	}
+	return max(rows, 0) * uploadRowBytes
+}
+
+// workerShareEstimate is an upper estimate of one worker's share of total
+// rows, allowing for partitions of uneven size
+func workerShareEstimate(total int64, workers int) int64 {
+	return 2 * total / int64(max(workers, 1))
+}
+
+// s3CopyArgs returns the aws CLI arguments uploading stdin to url. An
+// expectedSize above 0 is passed as --expected-size; it may overestimate.
+func s3CopyArgs(url string, expectedSize int64) []string {
+	args := []string{"s3", "cp", "--only-show-errors"}
+	if expectedSize > 0 {
+		args = append(args, "--expected-size", strconv.FormatInt(expectedSize, 10))
+	}
+	return append(args, "-", url)
+}
+
+// NewS3Writer starts an upload to the given s3:// URL. expectedSize is an
+// upper estimate of the object's size in bytes (0 = unknown and small).
+func NewS3Writer(url string, expectedSize int64) (*S3Writer, error) {
+	if !IsS3Path(url) || len(strings.TrimPrefix(url, "s3://")) == 0 {
+		return nil, fmt.Errorf("invalid S3 URL %q (expected s3://bucket/key)", url)
+	}
+
+	cmd := exec.Command("aws", s3CopyArgs(url, expectedSize)...)
+	cmd.Stderr = os.Stderr // Surface aws errors to user
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		stdin.Close()
+		return nil, fmt.Errorf("failed to start aws: %w", err)
+	}
+
+	w := &S3Writer{
+		cmd:    cmd,
+		stdin:  stdin,
+		url:    url,
+		waitCh: make(chan struct{}),
+	}
+
+	// Wait for aws in background goroutine
+	go func() {
+		w.waitErr = cmd.Wait()
+		close(w.waitCh)
+	}()
+
+	return w, nil
+}
+
+// Write implements io.Writer, streaming data to the upload
+func (w *S3Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, fmt.Errorf("writer is closed")
+	}
+
+	return w.stdin.Write(p)
+}
+
+// Close signals EOF and waits for the upload to complete
+func (w *S3Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	stdinErr := w.stdin.Close()
+	<-w.waitCh
+
+	if w.waitErr != nil {
+		return fmt.Errorf("upload to %s failed: %w", w.url, w.waitErr)
+	}
+	if stdinErr != nil {
+		return fmt.Errorf("failed to close aws stdin: %w", stdinErr)
+	}
+	return nil
+}
+
+// Path returns the destination s3:// URL
+func (w *S3Writer) Path() string {
+	return w.url
+}
+
+// CheckAWSCLIAvailable verifies that the aws CLI is installed and accessible.
+// Credentials and region come from the usual AWS environment/config files.
+func CheckAWSCLIAvailable() error {
+	cmd := exec.Command("aws", "--version")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("aws CLI not found: %w\nInstall from https://aws.amazon.com/cli/ to write to s3:// outputs", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestS3CopyArgs(t *testing.T) {
+	if got, want := s3CopyArgs("s3://bucket/a.csv", 0), []string{"s3", "cp", "--only-show-errors", "-", "s3://bucket/a.csv"}; !slices.Equal(got, want) {
+		t.Errorf("s3CopyArgs without a size = %q, want %q", got, want)
+	}
+	got := s3CopyArgs("s3://bucket/a.csv", 200<<30)
+	if want := []string{"s3", "cp", "--only-show-errors", "--expected-size", "214748364800", "-", "s3://bucket/a.csv"}; !slices.Equal(got, want) {
+		t.Errorf("s3CopyArgs with a size = %q, want %q", got, want)
+	}
+
+	// Rolled-over part files are bounded by MaxRows
+	for _, tc := range []struct{ rows, maxRows, want int64 }{
+		{0, 0, 0},
+		{1000, 0, 1000 * uploadRowBytes},
+		{1000, 100, 100 * uploadRowBytes},
+		{0, 100, 100 * uploadRowBytes},
+	} {
+		if got := expectedUploadSize(tc.rows, tc.maxRows); got != tc.want {
+			t.Errorf("expectedUploadSize(%d, %d) = %d, want %d", tc.rows, tc.maxRows, got, tc.want)
+		}
+	}
+	if got := workerShareEstimate(1000, 4); got != 500 {
+		t.Errorf("workerShareEstimate(1000, 4) = %d, want 500", got)
+	}
+}
+
+func TestS3WriterUploadsThroughAWSCLI(t *testing.T) {
+	// A stand-in aws records its arguments and what it was sent
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat > " + filepath.Join(dir, "body") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "aws"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	w, err := NewCSVWriter(CSVWriterConfig{
+		OutputDir:    "s3://bucket/run1/",
+		Filename:     "transactions_001",
+		Headers:      []string{"id", "amount"},
+		ExpectedRows: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"1", "250"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if got, want := strings.TrimSpace(string(args)), "s3 cp --only-show-errors --expected-size 256000 - s3://bucket/run1/transactions_001.csv"; got != want {
+		t.Errorf("aws called with %q, want %q", got, want)
+	}
+	if body, _ := os.ReadFile(filepath.Join(dir, "body")); string(body) != "id,amount\n1,250\n" {
+		t.Errorf("uploaded %q", body)
+	}
+
+	if _, err := NewS3Writer("s3://", 0); err == nil {
+		t.Error("NewS3Writer accepted a URL without a bucket")
+	}
+}
@@ -0,0 +1,253 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// DataSchema is a machine-readable description of the generated CSV tables
+type DataSchema struct {
+	SchemaVersion int           `json:"schema_version"`
+	Tables        []TableSchema `json:"tables"`
+}
+
+// TableSchema describes one table's columns, in CSV order
+type TableSchema struct {
+	Name    string         `json:"name"`
+	Columns []ColumnSchema `json:"columns"`
+}
+
+// ColumnSchema describes a column. Type is integer, number, boolean, string,
+// date or datetime; nullable columns are empty in the CSV when null.
+type ColumnSchema struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Format   string   `json:"format,omitempty"`
+	Nullable bool     `json:"nullable"`
+	Enum     []string `json:"enum,omitempty"`
+}
+
+// Columns written as dates rather than datetimes
+var dateColumns = map[string]bool{"date_of_birth": true, "value_date": true}
+
+// Columns of an enumerated type that also hold values outside it:
+// beneficiaries abroad are paid in their country's currency, whichever it is
+var openEnumColumns = map[string]bool{"beneficiaries.currency": true}
+
+// schemaTables pairs each table with its model and CSV headers
+var schemaTables = []struct {
+	name    string
+	model   any
+	headers func() []string
+}{
+	{"branches", models.Branch{}, BranchHeaders},
+	{"atms", models.ATM{}, ATMHeaders},
+	{"customers", models.Customer{}, CustomerHeaders},
+	{"businesses", models.Customer{}, CustomerHeaders},
+	{"accounts", models.Account{}, AccountHeaders},
+	{"beneficiaries", models.Beneficiary{}, BeneficiaryHeaders},
+	{"transactions", models.Transaction{}, TransactionHeaders},
+	{"audit_logs", models.AuditLog{}, AuditLogHeaders},
+	{"atm_events", models.ATMEvent{}, ATMEventHeaders},
+	{"transfers", models.Transfer{}, TransferHeaders},
+	{"disputes", models.Dispute{}, DisputeHeaders},
+}
+
+// schemaEnum is the set of values of an enumerated column type
+type schemaEnum struct {
+	typ    reflect.Type
+	values []string
+}
+
+func enum[T ~string](values ...T) schemaEnum {
+	e := schemaEnum{typ: reflect.TypeOf(values).Elem()}
+	for _, v := range values {
+		e.values = append(e.values, string(v))
+	}
+	return e
+}
+
+// schemaEnums lists the values of every enumerated type in models
+var schemaEnums = []schemaEnum{
+	enum(models.AccountTypeChecking, models.AccountTypeSavings, models.AccountTypeCreditCard, models.AccountTypeLoan,
+		models.AccountTypeMortgage, models.AccountTypeInvestment, models.AccountTypeBusiness, models.AccountTypeMerchant,
+		models.AccountTypePayroll),
+	enum(models.AccountStatusActive, models.AccountStatusDormant, models.AccountStatusFrozen, models.AccountStatusClosed,
+		models.AccountStatusPending),
+	enum(models.SupportedCurrencies...),
+	enum(models.AuditLoginSuccess, models.AuditLoginFailed, models.AuditLogout, models.AuditPINSuccess, models.AuditPINFailed,
+		models.AuditPasswordChanged, models.AuditAccountLocked, models.AuditTransactionInitiated, models.AuditTransactionCompleted,
+		models.AuditTransactionFailed, models.AuditTransactionDeclined, models.AuditAccountOpened, models.AuditAccountClosed,
+		models.AuditAccountUpdated, models.AuditBeneficiaryAdded, models.AuditBeneficiaryRemoved, models.AuditCustomerSuspended,
+		models.AuditCustomerClosed, models.AuditKYCSubmitted, models.AuditKYCVerified, models.AuditKYCRejected,
+		models.AuditProfileViewed, models.AuditProfileUpdated, models.AuditAddressChanged, models.AuditContactChanged,
+		models.AuditSessionStarted, models.AuditSessionEnded, models.AuditSessionTimeout, models.AuditBalanceInquiry,
+		models.AuditStatementViewed, models.AuditHistoryViewed),
+	enum(models.OutcomeSuccess, models.OutcomeFailure, models.OutcomeDenied, models.OutcomeError),
+	enum(models.AuditChannelOnline, models.AuditChannelATM, models.AuditChannelBranch, models.AuditChannelMobile,
+		models.AuditChannelPhone, models.AuditChannelAPI, models.AuditChannelSystem),
+	enum(models.BeneficiaryTypeIndividual, models.BeneficiaryTypeBusiness, models.BeneficiaryTypeUtility,
+		models.BeneficiaryTypeGovernment),
+	enum(models.BeneficiaryStatusPending, models.BeneficiaryStatusVerified, models.BeneficiaryStatusBlocked),
+	enum(models.BranchTypeFull, models.BranchTypeLimited, models.BranchTypeATMOnly, models.BranchTypeHeadquarter,
+		models.BranchTypeRegional),
+	enum(models.BranchStatusOpen, models.BranchStatusClosed, models.BranchStatusRenovation, models.BranchStatusRelocating),
+	enum(models.ATMStatusOnline, models.ATMStatusOffline, models.ATMStatusMaintenance, models.ATMStatusOutOfCash),
+	enum(models.ATMEventCashLow, models.ATMEventOffline, models.ATMEventOnline),
+	enum(models.SegmentRegular, models.SegmentPremium, models.SegmentPrivate, models.SegmentBusiness, models.SegmentCorporate),
+	enum(models.CustomerStatusActive, models.CustomerStatusInactive, models.CustomerStatusSuspended, models.CustomerStatusClosed),
+	enum(models.KYCStatusUnverified, models.KYCStatusPending, models.KYCStatusVerified, models.KYCStatusRejected),
+	enum(models.KYCDocumentPassport, models.KYCDocumentNationalID, models.KYCDocumentDriversLicense,
+		models.KYCDocumentResidencePermit),
+	enum(models.TxTypeDeposit, models.TxTypeSalary, models.TxTypeTransferIn, models.TxTypeInterestCredit, models.TxTypeRefund,
+		models.TxTypeCashback, models.TxTypeWithdrawal, models.TxTypePurchase, models.TxTypeTransferOut, models.TxTypeBillPayment,
+		models.TxTypeInterestDebit, models.TxTypeFee, models.TxTypeLoanPayment, models.TxTypePayrollBatch),
+	enum(models.TxStatusPending, models.TxStatusCompleted, models.TxStatusFailed, models.TxStatusReversed, models.TxStatusDeclined),
+	enum(models.ChannelOnline, models.ChannelATM, models.ChannelBranch, models.ChannelPOS, models.ChannelACH, models.ChannelWire,
+		models.ChannelInternal),
+	enum(models.DisputeFraud, models.DisputeNotReceived, models.DisputeNotAsDescribed, models.DisputeDuplicate,
+		models.DisputeIncorrectAmount),
+	enum(models.DisputeStatusFiled, models.DisputeStatusUnderReview, models.DisputeStatusWon, models.DisputeStatusLost),
+}
+
+// BuildDataSchema describes every generated table, deriving column types and
+// nullability from the models' db tags and column order from the CSV headers
+func BuildDataSchema() (DataSchema, error) {
+	enums := make(map[reflect.Type][]string, len(schemaEnums))
+	for _, e := range schemaEnums {
+		enums[e.typ] = e.values
+	}
+
+	s := DataSchema{SchemaVersion: SchemaVersion}
+	for _, table := range schemaTables {
+		fields := dbFields(table.model)
+		typ := reflect.TypeOf(table.model)
+
+		t := TableSchema{Name: table.name}
+		for _, name := range table.headers() {
+			fieldType, ok := fields[name]
+			if !ok {
+				return DataSchema{}, fmt.Errorf("table %s: no %s field for column %s", table.name, typ.Name(), name)
+			}
+			column := ColumnSchema{Name: name}
+			if fieldType.Kind() == reflect.Pointer {
+				column.Nullable = true
+				fieldType = fieldType.Elem()
+			}
+			switch {
+			case fieldType == reflect.TypeOf(time.Time{}) && dateColumns[name]:
+				column.Type, column.Format = "date", "YYYY-MM-DD"
+			case fieldType == reflect.TypeOf(time.Time{}):
+				column.Type, column.Format = "datetime", "YYYY-MM-DD HH:MM:SS"
+			case fieldType.Kind() == reflect.Bool:
+				column.Type, column.Format = "boolean", "0 or 1"
+			case fieldType.Kind() == reflect.Int || fieldType.Kind() == reflect.Int64:
+				column.Type = "integer"
+			case fieldType.Kind() == reflect.Float64:
+				column.Type = "number"
+			case fieldType.Kind() == reflect.String:
+				column.Type = "string"
+				if !openEnumColumns[table.name+"."+name] {
+					column.Enum = enums[fieldType]
+				}
+			default:
+				return DataSchema{}, fmt.Errorf("table %s: column %s has unsupported type %s", table.name, name, fieldType)
+			}
+			t.Columns = append(t.Columns, column)
+		}
+		s.Tables = append(s.Tables, t)
+	}
+	return s, nil
+}
+
+// DataSchemaJSON returns BuildDataSchema as indented JSON
+func DataSchemaJSON() ([]byte, error) {
+	s, err := BuildDataSchema()
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// dbFields maps a model's db column names to their field types
+func dbFields(model any) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	typ := reflect.TypeOf(model)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if tag, _, _ := strings.Cut(f.Tag.Get("db"), ","); tag != "" && tag != "-" {
+			fields[tag] = f.Type
+		}
+	}
+	return fields
+}
+
+// EnumDictionaryCSV returns a data dictionary of every enumerated type in
+// models as CSV: one row per allowed value, with the type's name (e.g.
+// transaction_channel) and the table.column pairs that hold it, separated by
+// semicolons.
+func EnumDictionaryCSV() ([]byte, error) {
+	columns := make(map[reflect.Type][]string)
+	for _, table := range schemaTables {
+		fields := dbFields(table.model)
+		for _, name := range table.headers() {
+			typ := fields[name]
+			if typ != nil && typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+			if column := table.name + "." + name; !openEnumColumns[column] {
+				columns[typ] = append(columns[typ], column)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"enum", "value", "columns"}); err != nil {
+		return nil, err
+	}
+	for _, e := range schemaEnums {
+		name := snakeCase(e.typ.Name())
+		for _, value := range e.values {
+			if err := w.Write([]string{name, value, strings.Join(columns[e.typ], ";")}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to encode enum dictionary: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// snakeCase converts a Go type name such as KYCDocumentType to kyc_document_type
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && i > 0 {
+			prevLower := name[i-1] >= 'a' && name[i-1] <= 'z'
+			nextLower := i+1 < len(name) && name[i+1] >= 'a' && name[i+1] <= 'z'
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+		}
+		if upper {
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"context"
+	"encoding/csv"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildDataSchema(t *testing.T) {
+	s, err := BuildDataSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := make(map[string]ColumnSchema)
+	for _, table := range s.Tables {
+		for _, c := range table.Columns {
+			columns[table.Name+"."+c.Name] = c
+		}
+	}
+
+	for name, want := range map[string]ColumnSchema{
+		"transactions.amount":         {Name: "amount", Type: "integer"},
+		"transactions.value_date":     {Name: "value_date", Type: "date", Format: "YYYY-MM-DD"},
+		"transactions.branch_id":      {Name: "branch_id", Type: "integer", Nullable: true},
+		"branches.closed_at":          {Name: "closed_at", Type: "datetime", Format: "YYYY-MM-DD HH:MM:SS", Nullable: true},
+		"atms.is_24_hours":            {Name: "is_24_hours", Type: "boolean", Format: "0 or 1"},
+		"customers.activity_score":    {Name: "activity_score", Type: "number"},
+		"transactions.failure_reason": {Name: "failure_reason", Type: "string", Nullable: true},
+		"beneficiaries.date_of_birth": {},
+	} {
+		got, ok := columns[name]
+		if want.Name == "" {
+			if ok {
+				t.Errorf("unexpected column %s", name)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %+v, expected %+v", name, got, want)
+		}
+	}
+	if enum := columns["transactions.channel"].Enum; !slices.Contains(enum, "pos") || !slices.Contains(enum, "wire") {
+		t.Errorf("transaction channel enum %v", enum)
+	}
+}
+
+// Every constant of an enumerated type in models must be listed in schemaEnums
+func TestSchemaEnumsComplete(t *testing.T) {
+	listed := make(map[string][]string)
+	for _, e := range schemaEnums {
+		listed[e.typ.Name()] = e.values
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "../models", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					vs := spec.(*ast.ValueSpec)
+					typ, ok := vs.Type.(*ast.Ident)
+					if !ok || len(vs.Values) != 1 {
+						continue
+					}
+					lit, ok := vs.Values[0].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					value := lit.Value[1 : len(lit.Value)-1]
+					if !slices.Contains(listed[typ.Name], value) {
+						t.Errorf("%s value %q (%s) missing from schemaEnums", typ.Name, value, vs.Names[0].Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEnumDictionaryCSV(t *testing.T) {
+	out, err := EnumDictionaryCSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(out))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rows[0], []string{"enum", "value", "columns"}) {
+		t.Fatalf("header %v", rows[0])
+	}
+	values := 0
+	for _, e := range schemaEnums {
+		values += len(e.values)
+	}
+	if len(rows)-1 != values {
+		t.Errorf("%d dictionary rows, expected one per enum value (%d)", len(rows)-1, values)
+	}
+	for _, want := range [][]string{
+		{"transaction_channel", "pos", "transactions.channel"},
+		{"kyc_document_type", "passport", "customers.kyc_document_type;businesses.kyc_document_type"},
+		{"atm_event_type", "cash_low", "atm_events.type"},
+	} {
+		if !slices.ContainsFunc(rows, func(row []string) bool { return slices.Equal(row, want) }) {
+			t.Errorf("dictionary has no row %v", want)
+		}
+	}
+	for _, row := range rows[1:] {
+		if row[2] == "" {
+			t.Errorf("%s is not held by any column", row[0])
+		}
+	}
+}
+
+// Every enumerated column of a generated data set holds only dictionary values
+func TestGeneratedEnumValuesKnown(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates a full data set")
+	}
+
+	dir := t.TempDir()
+	o, err := NewOrchestrator(OrchestratorConfig{
+		NumCustomers:                    150,
+		NumBusinesses:                   8,
+		NumBranches:                     3,
+		NumATMs:                         6,
+		YearsOfHistory:                  1,
+		OutputDir:                       dir,
+		Seed:                            7,
+		AsOfDate:                        time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		TransactionsPerCustomerPerMonth: 10,
+		PayrollDay:                      25,
+		DeclinedTransactionRate:         0.01,
+		ReversalRate:                    0.01,
+		FailedLoginRate:                 0.02,
+		ChurnRate:                       0.05,
+		ClosedBranchRate:                0.3,
+		OfflineATMRate:                  0.2,
+		ATMEvents:                       &ATMEventGeneratorConfig{CashCapacity: 500, ReplenishDays: 7, CashLowPercent: 20, FaultsPerYear: 4, MaintenancePerYear: 2},
+		KYC:                             &KYCConfig{UnverifiedRate: 0.1, RejectedRate: 0.2, MaxReviewDays: 5},
+		Transfers:                       true,
+		DisputeRate:                     0.05,
+	}, OrchestratorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := o.GenerateAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := BuildDataSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range s.Tables {
+		files, err := FindShardedFiles(dir, table.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path := filepath.Join(dir, table.Name+".csv"); len(files) == 0 {
+			if _, err := os.Stat(path); err != nil {
+				t.Fatalf("no %s written", table.Name)
+			}
+			files = []string{path}
+		}
+		var columns []string
+		var enums [][]string
+		for _, c := range table.Columns {
+			if c.Enum != nil {
+				columns = append(columns, c.Name)
+				enums = append(enums, c.Enum)
+			}
+		}
+		if columns == nil {
+			continue
+		}
+		for _, file := range files {
+			err := ReadCSVRows(ctx, file, columns, func(row []string) error {
+				for i, v := range row {
+					if v != "" && !slices.Contains(enums[i], v) {
+						t.Errorf("%s.%s holds %q, not in the enum dictionary", table.Name, columns[i], v)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
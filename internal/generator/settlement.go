@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// SettlementLag is how many business days after a transaction is made it
+// posts to the account and takes value. A posting lag of 0 posts it within a
+// minute; a value lag of 0 value-dates it the day it was made.
+type SettlementLag struct {
+	PostingDays int `json:"posting_days"`
+	ValueDays   int `json:"value_days"`
+}
+
+// SettlementLags holds the settlement lag of each channel. Channels without
+// an entry settle immediately.
+type SettlementLags map[models.TransactionChannel]SettlementLag
+
+// DefaultSettlementLags approximates real settlement: ACH posts and takes
+// value the next business day, card sales post two days later once the
+// merchant settles but keep the purchase date as their value date, and wires,
+// ATM, branch and online transactions settle the same day.
+func DefaultSettlementLags() SettlementLags {
+	return SettlementLags{
+		models.ChannelACH: {PostingDays: 1, ValueDays: 1},
+		models.ChannelPOS: {PostingDays: 2, ValueDays: 0},
+	}
+}
+
+// settlementChannels are the channels --settlement-lags accepts, in the order
+// they are formatted
+var settlementChannels = []models.TransactionChannel{
+	models.ChannelOnline, models.ChannelATM, models.ChannelBranch, models.ChannelPOS,
+	models.ChannelACH, models.ChannelWire, models.ChannelInternal,
+}
+
+// ParseSettlementLags parses a --settlement-lags value such as
+// "ach=1,pos=2:0,wire=0": business days to posting, then optionally to the
+// value date (default the posting lag). Listed channels replace their default.
+func ParseSettlementLags(s string) (SettlementLags, error) {
+	lags := DefaultSettlementLags()
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid settlement lag entry %q (expected channel=days or channel=days:days)", part)
+		}
+		channel := models.TransactionChannel(strings.ToLower(strings.TrimSpace(name)))
+		if !slices.Contains(settlementChannels, channel) {
+			return nil, fmt.Errorf("unknown channel %q in settlement lags", name)
+		}
+		posting, valueDays, hasValue := strings.Cut(strings.TrimSpace(value), ":")
+		lag, err := parseLagDays(name, posting)
+		if err != nil {
+			return nil, err
+		}
+		valueLag := lag
+		if hasValue {
+			if valueLag, err = parseLagDays(name, valueDays); err != nil {
+				return nil, err
+			}
+		}
+		lags[channel] = SettlementLag{PostingDays: lag, ValueDays: valueLag}
+	}
+	return lags, nil
+}
+
+// parseLagDays parses one lag in business days
+func parseLagDays(channel, s string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || days < 0 || days > 30 {
+		return 0, fmt.Errorf("invalid %s settlement lag %q (expected 0 to 30 business days)", channel, s)
+	}
+	return days, nil
+}
+
+// String formats the lags as a --settlement-lags value
+func (l SettlementLags) String() string {
+	parts := make([]string, 0, len(l))
+	for _, channel := range settlementChannels {
+		if lag, ok := l[channel]; ok {
+			part := fmt.Sprintf("%s=%d", channel, lag.PostingDays)
+			if lag.ValueDays != lag.PostingDays {
+				part += fmt.Sprintf(":%d", lag.ValueDays)
+			}
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// settle returns when a transaction made at ts on a channel posts, a few
+// seconds after the same time of day on its posting day, and its value date
+func (l SettlementLags) settle(rng *utils.Random, channel models.TransactionChannel, ts time.Time) (postedAt, valueDate time.Time) {
+	lag := l[channel]
+	postedAt = addBusinessDays(ts, lag.PostingDays).Add(time.Duration(rng.IntRange(0, 60)) * time.Second)
+	return postedAt, addBusinessDays(ts, lag.ValueDays)
+}
+
+// addBusinessDays moves t forward n weekdays, keeping its time of day
+func addBusinessDays(t time.Time, n int) time.Time {
+	for n > 0 {
+		t = t.AddDate(0, 0, 1)
+		if !weekend(t) {
+			n--
+		}
+	}
+	return t
+}
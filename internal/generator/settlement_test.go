@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestParseSettlementLags(t *testing.T) {
+	lags, err := ParseSettlementLags("ach=2, wire=1:0, pos=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SettlementLags{
+		models.ChannelACH:  {PostingDays: 2, ValueDays: 2},
+		models.ChannelWire: {PostingDays: 1, ValueDays: 0},
+		models.ChannelPOS:  {PostingDays: 0, ValueDays: 0},
+	}
+	if lags.String() != want.String() {
+		t.Errorf("parsed %s, want %s", lags, want)
+	}
+	if got := DefaultSettlementLags().String(); got != "pos=2:0,ach=1" {
+		t.Errorf("default lags format as %q", got)
+	}
+
+	for _, value := range []string{"ach", "swift=1", "ach=-1", "ach=1:x", "pos=31"} {
+		if _, err := ParseSettlementLags(value); err == nil {
+			t.Errorf("ParseSettlementLags(%q) should fail", value)
+		}
+	}
+}
+
+func TestSettleSkipsWeekends(t *testing.T) {
+	lags := DefaultSettlementLags()
+	friday := time.Date(2025, 3, 7, 16, 30, 0, 0, time.UTC)
+	rng := utils.NewRandom(1)
+
+	for _, tc := range []struct {
+		channel   models.TransactionChannel
+		posted    time.Time // Posting day, at the transaction's time of day
+		valueDate time.Time
+	}{
+		{models.ChannelACH, time.Date(2025, 3, 10, 16, 30, 0, 0, time.UTC), time.Date(2025, 3, 10, 16, 30, 0, 0, time.UTC)},
+		{models.ChannelPOS, time.Date(2025, 3, 11, 16, 30, 0, 0, time.UTC), friday},
+		{models.ChannelATM, friday, friday},
+	} {
+		postedAt, valueDate := lags.settle(rng, tc.channel, friday)
+		if postedAt.Before(tc.posted) || postedAt.After(tc.posted.Add(time.Minute)) {
+			t.Errorf("%s made %s posted %s, want within a minute of %s", tc.channel, friday, postedAt, tc.posted)
+		}
+		if !valueDate.Equal(tc.valueDate) {
+			t.Errorf("%s made %s value-dated %s, want %s", tc.channel, friday, valueDate, tc.valueDate)
+		}
+	}
+}
+
+func TestDeclinedTransactionsPostWhenMade(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	account := GeneratedAccount{Account: models.Account{
+		ID:       1,
+		Type:     models.AccountTypeChecking,
+		Status:   models.AccountStatusActive,
+		Currency: "USD",
+		Balance:  500000,
+		OpenedAt: start,
+	}, Customer: GeneratedCustomer{Customer: models.Customer{ID: 1, ActivityScore: 1}}}
+
+	g := newTestTransactionGenerator(t, start, start.AddDate(0, 2, 0))
+	g.settings.DeclinedTransactionRate = 0.3
+	g.settings.SettlementLags = SettlementLags{
+		models.ChannelACH: {PostingDays: 2, ValueDays: 2},
+		models.ChannelPOS: {PostingDays: 2},
+	}
+	txns, _ := g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+
+	var declined, lagged int
+	for _, gt := range txns {
+		txn := gt.Transaction
+		switch {
+		case txn.Status == models.TxStatusDeclined || txn.Status == models.TxStatusFailed:
+			declined++
+			if !txn.PostedAt.Equal(txn.Timestamp) {
+				t.Errorf("%s transaction %d made %s posted %s, want when made", txn.Status, txn.ID, txn.Timestamp, txn.PostedAt)
+			}
+		case txn.Channel == models.ChannelACH || txn.Channel == models.ChannelPOS:
+			if txn.PostedAt.Sub(txn.Timestamp) >= 48*time.Hour {
+				lagged++
+			}
+		}
+	}
+	if declined == 0 || lagged == 0 {
+		t.Errorf("expected declined and lagged transactions, got %d and %d", declined, lagged)
+	}
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Summary is the machine-readable form of a generation run: the manifest's
+// seed, parameters and row counts plus timing and output file sizes
+type Summary struct {
+	Manifest
+	OutputDir  string        `json:"output_dir"`
+	DurationMS int64         `json:"duration_ms"`
+	Files      []SummaryFile `json:"files,omitempty"` // Local output directories only
+}
+
+// SummaryFile is one file in the output directory
+type SummaryFile struct {
+	Name  string `json:"name"` // Relative to the output directory (e.g. transactions/transactions_001.csv)
+	Bytes int64  `json:"bytes"`
+}
+
+// Summary describes this run for tooling. Output file sizes are read back
+// from a local output directory; S3 outputs have none.
+func (o *Orchestrator) Summary(result *GenerationResult) (*Summary, error) {
+	s := &Summary{
+		Manifest:   o.manifest(result),
+		OutputDir:  o.config.OutputDir,
+		DurationMS: result.Duration.Milliseconds(),
+	}
+	if IsS3Path(o.config.OutputDir) {
+		return s, nil
+	}
+
+	// Walk rather than list so per-table subdirectories are included
+	err := filepath.WalkDir(o.config.OutputDir, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		name, err := filepath.Rel(o.config.OutputDir, path)
+		if err != nil {
+			return err
+		}
+		s.Files = append(s.Files, SummaryFile{Name: filepath.ToSlash(name), Bytes: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list output files: %w", err)
+	}
+	return s, nil
+}
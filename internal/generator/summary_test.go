@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	dir := t.TempDir()
+	o, err := NewOrchestrator(OrchestratorConfig{
+		NumCustomers:                    20,
+		NumBranches:                     2,
+		NumATMs:                         2,
+		YearsOfHistory:                  1,
+		TransactionsPerCustomerPerMonth: 2,
+		PayrollDay:                      25,
+		Workers:                         1,
+		Seed:                            5,
+		AsOfDate:                        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		OutputDir:                       dir,
+	}, OrchestratorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := o.GenerateAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := o.Summary(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Seed != 5 || s.OutputDir != dir || s.DurationMS != result.Duration.Milliseconds() {
+		t.Errorf("Unexpected run details: seed %d, dir %q, duration %dms", s.Seed, s.OutputDir, s.DurationMS)
+	}
+	if s.Counts.Customers != result.CustomerCount || s.Counts.Accounts != result.AccountCount || s.Counts.Transactions != result.TransactionCount {
+		t.Errorf("Counts %+v do not match the result %+v", s.Counts, *result)
+	}
+
+	sizes := make(map[string]int64)
+	for _, f := range s.Files {
+		sizes[f.Name] = f.Bytes
+	}
+	for _, name := range []string{"branches.csv", "customers.csv", "accounts.csv"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := sizes[name]; !ok || got != info.Size() {
+			t.Errorf("Expected %s listed at %d bytes, got %d (listed %v)", name, info.Size(), got, ok)
+		}
+	}
+
+	raw, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	// The manifest is embedded, so its fields sit beside the summary's own
+	for _, key := range []string{"seed", "rng_version", "as_of", "counts", "output_dir", "duration_ms", "files"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("Summary JSON is missing %q", key)
+		}
+	}
+	if _, ok := got["Manifest"]; ok {
+		t.Error("Expected the manifest fields inline, not nested")
+	}
+
+	var files []map[string]json.RawMessage
+	if err := json.Unmarshal(got["files"], &files); err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("Expected files in the summary JSON")
+	}
+	for _, key := range []string{"name", "bytes"} {
+		if _, ok := files[0][key]; !ok {
+			t.Errorf("Summary file entry is missing %q", key)
+		}
+	}
+}
+
+func TestSummaryPerTableDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "customers"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "customers", "customers_0001.csv"), []byte("id\n1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := &Orchestrator{config: OrchestratorConfig{OutputDir: dir}}
+	s, err := o.Summary(&GenerationResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Files) != 1 || s.Files[0] != (SummaryFile{Name: "customers/customers_0001.csv", Bytes: 5}) {
+		t.Errorf("Expected the subdirectory file with a slash-separated name, got %+v", s.Files)
+	}
+}
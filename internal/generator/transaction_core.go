@@ -0,0 +1,1494 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/generator/patterns"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// transactionCore holds the generation logic shared by the batch and
+// streaming transaction generators. Both embed it and differ only in where
+// generated transactions go, so the same seed and accounts produce the same
+// transactions from either.
+type transactionCore struct {
+	rng      *utils.Random
+	refData  *data.ReferenceData
+	settings transactionSettings
+
+	// Patterns for realistic distribution
+	retailPattern   *patterns.FullPattern
+	atmPattern      *patterns.FullPattern
+	onlinePattern   *patterns.FullPattern
+	businessPattern *patterns.FullPattern
+
+	// Off-hours suppression per account type (absent = none)
+	businessHours map[models.AccountType]*BusinessHours
+
+	// Transaction type mix per account type (absent = online deposits)
+	typePickers map[models.AccountType]*WeightedTypePicker
+
+	// Fee types charged by fee transactions
+	fees *feePicker
+
+	// Whale accounts, whose volume is scaled by WhaleMultiplier
+	whales map[int64]bool
+
+	// Activity distribution
+	activityDist *patterns.ActivityDistribution
+
+	// Amount distributions
+	amounts *patterns.TransactionTypeAmounts
+
+	// Reference data
+	branches []GeneratedBranch
+	atms     []GeneratedATM
+
+	// Shared account lookups for counterparty transactions
+	accounts *AccountIndex
+	// Employees paid by each payroll account, sampled on its first batch
+	payrollWorkforce map[int64][]int64
+	// Payees of each retail account, sampled on its first transfer
+	transferPayees map[int64][]int64
+
+	// Partition being generated; rng is its random stream
+	partition *transactionPartition
+
+	// Time of the last interest posting per account (for accrual)
+	interestAccruedAt map[int64]time.Time
+
+	// Completed purchase volume per credit card account since the last cashback posting
+	purchaseVolume map[int64]int64
+
+	// Reversals scheduled per account, in due order, not yet emitted
+	pendingReversals map[int64][]pendingReversal
+
+	// Overdraft fees charged per account on its most recent fee day
+	overdraftFees map[int64]overdraftFeeDay
+
+	// emit receives each generated transaction with its account, in generation order
+	emit func(txn models.Transaction, account GeneratedAccount) error
+
+	// emitTransfer receives each internal transfer after its legs (nil = not recorded)
+	emitTransfer func(transfer models.Transfer) error
+
+	// emitDispute receives each dispute case filed against a purchase (nil = not generated)
+	emitDispute func(dispute models.Dispute) error
+}
+
+// transactionSettings holds the generation settings both generators share
+type transactionSettings struct {
+	StartDate                       time.Time
+	EndDate                         time.Time
+	TransactionsPerCustomerPerMonth int
+	ParetoRatio                     float64
+	Granularity                     Granularity
+	DeclinedTransactionRate         float64
+	DeclineRetryRate                float64
+	InsufficientFundsRate           float64
+	CashbackRate                    float64
+	ReversalRate                    float64
+	OverdraftFee                    int64
+	OverdraftFeeDailyCap            int
+	BusinessHours                   map[models.AccountType]BusinessHours
+	TransactionMix                  map[models.AccountType][]TransactionTypeWeight
+	FeeSchedule                     FeeSchedule
+	WireBeneficiaries               map[int64][]WireBeneficiary
+	InternationalWireRate           float64
+	FarFromHomeRate                 float64
+	GeoCoordinates                  bool
+	BranchHours                     bool
+	WeekendVolume                   *WeekendVolume
+	WhaleAccounts                   []int64
+	WhaleMultiplier                 float64
+	MonthlyCaps                     MonthlyCaps
+	MinorUnits                      bool
+	RampUpMonths                    int
+	InterestPostingDay              int
+	DisputeRate                     float64
+	SettlementLags                  SettlementLags
+	ATMDenominations                ATMDenominations
+	TransferGraph                   TransferGraph
+
+	Branches []GeneratedBranch
+	ATMs     []GeneratedATM
+	Accounts *AccountIndex // All accounts, for counterparty lookups
+}
+
+// transactionPartition is the generation state of one partition of customers.
+// Partitions don't share random streams or running balances, so each
+// generates the same transactions whichever others a worker also runs.
+type transactionPartition struct {
+	rng      *utils.Random
+	auditRNG *utils.Random // Transaction audit events (streaming generator only)
+
+	// Next transaction ID to assign, and the step to the one after
+	nextID   int64
+	idStride int64
+
+	accounts         []GeneratedAccount
+	customerAccounts map[int64][]GeneratedAccount // Grouped for coordinated generation
+	balances         map[int64]int64              // Running balances of the accounts being generated
+
+	// Active retail checking account IDs, and those by home branch, for
+	// transfer payees. Payees stay within the partition so both legs of a
+	// transfer move running balances it tracks.
+	peerIDs       []int64
+	peersByBranch map[int64][]int64
+}
+
+// newTransactionPartition creates the state for generating accounts from
+// rng, numbering transactions from firstID in steps of idStride
+func newTransactionPartition(rng *utils.Random, accounts []GeneratedAccount, firstID, idStride int64) *transactionPartition {
+	p := &transactionPartition{
+		rng:              rng,
+		nextID:           firstID,
+		idStride:         max(idStride, 1),
+		accounts:         accounts,
+		customerAccounts: make(map[int64][]GeneratedAccount),
+		balances:         make(map[int64]int64, len(accounts)),
+		peersByBranch:    make(map[int64][]int64),
+	}
+	for _, acc := range accounts {
+		p.customerAccounts[acc.Account.CustomerID] = append(p.customerAccounts[acc.Account.CustomerID], acc)
+		p.balances[acc.Account.ID] = acc.Account.Balance
+		if acc.Account.Type == models.AccountTypeChecking && acc.Account.Status == models.AccountStatusActive &&
+			!acc.Customer.Customer.IsBusinessCustomer() {
+			branch := acc.Customer.Customer.HomeBranch
+			p.peerIDs = append(p.peerIDs, acc.Account.ID)
+			p.peersByBranch[branch] = append(p.peersByBranch[branch], acc.Account.ID)
+		}
+	}
+	return p
+}
+
+// newTransactionCore creates the shared generation state. Random draws come
+// from the partition being generated.
+func newTransactionCore(refData *data.ReferenceData, settings transactionSettings) transactionCore {
+	return transactionCore{
+		refData:  refData,
+		settings: settings,
+
+		retailPattern:   patterns.NewDefaultFullPattern(),
+		atmPattern:      patterns.NewATMFullPattern(),
+		onlinePattern:   patterns.NewOnlineFullPattern(),
+		businessPattern: patterns.NewBusinessFullPattern(),
+		businessHours:   businessHoursByType(settings.BusinessHours),
+		typePickers:     typePickersByType(settings.transactionMix()),
+		fees:            newFeePicker(settings.FeeSchedule),
+		whales:          whaleSet(settings.WhaleAccounts),
+
+		activityDist: patterns.NewParetoDistribution(settings.ParetoRatio),
+		amounts:      patterns.NewTransactionTypeAmounts(),
+
+		branches: settings.Branches,
+		atms:     settings.ATMs,
+		accounts: settings.Accounts,
+
+		payrollWorkforce: make(map[int64][]int64),
+		transferPayees:   make(map[int64][]int64),
+
+		interestAccruedAt: make(map[int64]time.Time),
+		purchaseVolume:    make(map[int64]int64),
+		pendingReversals:  make(map[int64][]pendingReversal),
+		overdraftFees:     make(map[int64]overdraftFeeDay),
+	}
+}
+
+// generateHistory generates transactions for the partitions month by month
+// from StartDate to EndDate, passing each to emit. Partitions take turns each
+// month so output stays roughly in time order. The context is checked between
+// months and accounts.
+func (g *transactionCore) generateHistory(ctx context.Context, partitions []*transactionPartition) error {
+	// Generate month by month
+	currentMonth := g.settings.StartDate
+	for currentMonth.Before(g.settings.EndDate) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		monthEnd := currentMonth.AddDate(0, 1, 0)
+		if monthEnd.After(g.settings.EndDate) {
+			monthEnd = g.settings.EndDate
+		}
+
+		for _, p := range partitions {
+			g.partition, g.rng = p, p.rng
+			if err := g.generateMonthTransactions(ctx, p.accounts, p.customerAccounts, p.balances, currentMonth, monthEnd); err != nil {
+				return err
+			}
+		}
+
+		currentMonth = currentMonth.AddDate(0, 1, 0)
+	}
+
+	return nil
+}
+
+// generateMonthTransactions generates transactions for a single month,
+// split into periods of the configured granularity
+func (g *transactionCore) generateMonthTransactions(
+	ctx context.Context,
+	accounts []GeneratedAccount,
+	customerAccounts map[int64][]GeneratedAccount,
+	balances map[int64]int64,
+	monthStart, monthEnd time.Time,
+) error {
+	// Credit cards earn cashback on last month's purchases at the start of the month
+	for _, account := range accounts {
+		if account.Account.Type != models.AccountTypeCreditCard || !account.Customer.ActiveAt(monthStart) {
+			continue
+		}
+		if amount := cashbackAmount(g.purchaseVolume[account.Account.ID], g.settings.CashbackRate); amount > 0 {
+			if err := g.emit(g.cashbackTransaction(account, balances, amount, monthStart), account); err != nil {
+				return err
+			}
+		}
+		delete(g.purchaseVolume, account.Account.ID)
+	}
+
+	// Monthly counts are drawn once per account and apportioned across periods
+	monthlyCounts := make([]int, len(accounts))
+	for i := range monthlyCounts {
+		monthlyCounts[i] = -1
+	}
+
+	for periodStart := monthStart; periodStart.Before(monthEnd); {
+		periodEnd := g.settings.Granularity.periodEnd(periodStart, monthEnd)
+		wholeMonth := periodStart.Equal(monthStart) && periodEnd.Equal(monthEnd)
+		type shareKey struct {
+			pattern       *patterns.FullPattern
+			hours         *BusinessHours
+			weekendVolume float64
+		}
+		shares := make(map[shareKey]float64)
+
+		for i, account := range accounts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			// Skip accounts opened after this period or whose customer has already churned
+			if account.Account.OpenedAt.After(periodEnd) || !account.Customer.ActiveAt(monthStart) {
+				continue
+			}
+
+			// Determine transaction count based on activity score and account type
+			if monthlyCounts[i] < 0 {
+				monthlyCounts[i] = g.calculateMonthlyTransactionCount(account, monthStart)
+			}
+			txnCount := monthlyCounts[i]
+			if !wholeMonth {
+				key := shareKey{g.selectPattern(account), g.businessHours[account.Account.Type], g.weekendVolume(account)}
+				share, ok := shares[key]
+				if !ok {
+					share = periodShare(key.pattern, key.hours, key.weekendVolume, monthStart, monthEnd, periodStart, periodEnd)
+					shares[key] = share
+				}
+				txnCount = scaleCount(g.rng, txnCount, share)
+			}
+
+			// Generate transactions distributed across the period
+			if err := g.generateAccountPeriodTransactions(
+				account, customerAccounts, balances, periodStart, periodEnd, txnCount,
+			); err != nil {
+				return err
+			}
+		}
+
+		periodStart = periodEnd
+	}
+
+	return nil
+}
+
+// calculateMonthlyTransactionCount determines how many transactions an account should have in the month starting at month
+func (g *transactionCore) calculateMonthlyTransactionCount(account GeneratedAccount, month time.Time) int {
+	baseCount := g.settings.TransactionsPerCustomerPerMonth
+
+	// Adjust by activity score (from Pareto distribution)
+	activityScore := account.Customer.Customer.ActivityScore
+	adjustedCount := g.activityDist.TransactionsPerMonth(activityScore, baseCount)
+
+	// Adjust by account type
+	switch account.Account.Type {
+	case models.AccountTypeChecking:
+		adjustedCount = int(float64(adjustedCount) * 1.2) // Primary transaction account
+	case models.AccountTypeSavings:
+		adjustedCount = int(float64(adjustedCount) * 0.3) // Less activity
+	case models.AccountTypeCreditCard:
+		adjustedCount = int(float64(adjustedCount) * 1.5) // Many purchases
+	case models.AccountTypeBusiness:
+		adjustedCount = int(float64(adjustedCount) * 2.0) // High volume
+	case models.AccountTypeMerchant:
+		adjustedCount = int(float64(adjustedCount) * 5.0) // Very high volume
+	case models.AccountTypePayroll:
+		adjustedCount = int(float64(adjustedCount) * 0.5) // Concentrated payroll events
+	default:
+		// Use base adjustment
+	}
+
+	// Whale accounts dwarf even the most active customers
+	whaleMultiplier := 1.0
+	if g.whales[account.Account.ID] {
+		whaleMultiplier = g.settings.WhaleMultiplier
+		adjustedCount = int(float64(adjustedCount) * whaleMultiplier)
+	}
+
+	// Young accounts are still ramping up to their full activity
+	adjustedCount = int(float64(adjustedCount) * rampUpFactor(account.Account.OpenedAt, month, g.settings.RampUpMonths))
+
+	// Minimum 1 transaction per month for active accounts
+	if adjustedCount < 1 {
+		adjustedCount = 1
+	}
+
+	// Add some randomness, then hold the count to its type's cap (scaled for whales)
+	variance := g.rng.IntRange(-adjustedCount/4, adjustedCount/4)
+	return g.settings.MonthlyCaps.apply(account.Account.Type, adjustedCount+variance, whaleMultiplier)
+}
+
+// rampUpFactor returns the share of its full monthly activity an account
+// opened at openedAt has in month: rising linearly over its first rampUpMonths
+// months (1/4, 2/4, 3/4 for 3), then 1. The month it opens counts as the first.
+func rampUpFactor(openedAt, month time.Time, rampUpMonths int) float64 {
+	if rampUpMonths <= 0 || openedAt.IsZero() {
+		return 1
+	}
+	age := (month.Year()-openedAt.Year())*12 + int(month.Month()-openedAt.Month())
+	if age >= rampUpMonths {
+		return 1
+	}
+	return float64(max(age, 0)+1) / float64(rampUpMonths+1)
+}
+
+// generateAccountPeriodTransactions generates transactions for one account in one period
+func (g *transactionCore) generateAccountPeriodTransactions(
+	account GeneratedAccount,
+	customerAccounts map[int64][]GeneratedAccount,
+	balances map[int64]int64,
+	periodStart, periodEnd time.Time,
+	targetCount int,
+) error {
+	// Select pattern based on customer segment and account type
+	pattern := g.selectPattern(account)
+
+	// Generate transaction timestamps distributed across the period, then
+	// choose each one's type and channel and its time on the channel's curve
+	timestamps := g.generateTimestamps(periodStart, periodEnd, targetCount, pattern, account)
+	planned := g.planTransactions(account, pattern, timestamps, periodStart, periodEnd)
+
+	for i, p := range planned {
+		ts, txnType, channel := p.ts, p.txnType, p.channel
+
+		// No activity once the customer has been suspended or closed
+		if !account.Customer.ActiveAt(ts) {
+			break
+		}
+
+		// Post interest and reversals that fall due before this transaction
+		if err := g.emitDueInterest(account, balances, ts); err != nil {
+			return err
+		}
+		if err := g.emitDueReversals(account, balances, ts); err != nil {
+			return err
+		}
+
+		// A fee's type sets both its amount and its description
+		var fee FeeRate
+		if txnType == models.TxTypeFee {
+			fee = g.fees.Pick(g.rng)
+		}
+
+		// Generate amount. A payroll batch is the sum of its employees' salaries.
+		var employees []int64
+		var salaries []int64
+		if txnType == models.TxTypePayrollBatch {
+			employees = g.payrollEmployees(account)
+		}
+		var amount int64
+		if len(employees) > 0 {
+			salaries = g.payrollShares(account.Account.Currency, employees)
+			for _, s := range salaries {
+				amount += s
+			}
+		} else {
+			amount = g.generateAmount(txnType, channel, fee, account, balances[account.Account.ID], ts)
+		}
+
+		// Check if this should be a declined transaction
+		status := models.TxStatusCompleted
+		var failureReason *string
+		requested := amount
+		if g.shouldDecline(txnType, balances[account.Account.ID], amount) {
+			status = models.TxStatusDeclined
+			reason := "insufficient_funds"
+			failureReason = &reason
+			amount = 0 // Declined transactions have no effect
+		}
+
+		// Get counterparty if applicable
+		var counterpartyID *int64
+		var beneficiaryID *int64
+		counterpartyID, beneficiaryID = g.selectCounterparty(txnType, account, customerAccounts)
+
+		// Wires carry SWIFT details; international ones pay a beneficiary abroad
+		var wire *wireTransfer
+		if channel == models.ChannelWire {
+			wire = g.wireTransfer(txnType, account, counterpartyID != nil)
+			if wire.beneficiary != nil {
+				beneficiaryID = &wire.beneficiary.ID
+			}
+		}
+
+		// Update balance for successful transactions
+		balanceAfter := balances[account.Account.ID]
+		if status == models.TxStatusCompleted && amount > 0 {
+			if isDebitType(txnType) {
+				balanceAfter -= amount
+			} else {
+				balanceAfter += amount
+			}
+			balances[account.Account.ID] = balanceAfter
+			if txnType == models.TxTypePurchase && account.Account.Type == models.AccountTypeCreditCard {
+				g.purchaseVolume[account.Account.ID] += amount
+			}
+		}
+
+		// Generate transaction description
+		description := g.generateDescription(txnType, fee, channel, account)
+		metadata := "{}"
+		if wire != nil {
+			wire.convert(g.rng, account.Account.Currency, amount, g.settings.MinorUnits)
+			if wire.International {
+				description = "International Wire Transfer"
+			}
+			metadata = wire.metadata()
+		}
+
+		// Some declined purchases are retried moments later on another of the
+		// customer's cards or checking accounts, before the account's next
+		// transaction; both attempts share a correlation ID
+		var retryAt time.Time
+		var retryAccount GeneratedAccount
+		if status == models.TxStatusDeclined && txnType == models.TxTypePurchase &&
+			g.rng.Probability(g.settings.DeclineRetryRate) {
+			retryAt = ts.Add(time.Duration(g.rng.IntRange(declineRetryMinDelay, declineRetryMaxDelay)) * time.Second)
+			var ok bool
+			if retryAt.Before(g.settings.EndDate) && (i+1 == len(planned) || retryAt.Before(planned[i+1].ts)) {
+				retryAccount, ok = g.purchaseRetryAccount(account, customerAccounts, balances, requested, retryAt)
+			}
+			if ok {
+				metadata = correlationMetadata(g.uetr())
+			} else {
+				retryAt = time.Time{}
+			}
+		}
+
+		// Get branch/ATM IDs and where the transaction was made
+		branchID, atmID := g.selectLocation(channel, account, ts)
+		var latitude, longitude *float64
+		if g.settings.GeoCoordinates {
+			latitude, longitude = g.transactionLocation(channel, account, branchID, atmID)
+		}
+
+		id := g.nextID()
+		postedAt, valueDate := g.settings.SettlementLags.settle(g.rng, channel, ts)
+		// Declined and failed transactions never settle, so they post when made
+		if status == models.TxStatusDeclined || status == models.TxStatusFailed {
+			postedAt = ts
+		}
+		txn := models.Transaction{
+			ID:                    id,
+			ReferenceNumber:       g.generateReferenceNumber(id, ts),
+			AccountID:             account.Account.ID,
+			CounterpartyAccountID: counterpartyID,
+			BeneficiaryID:         beneficiaryID,
+			Type:                  txnType,
+			Status:                status,
+			Channel:               channel,
+			Amount:                amount,
+			Currency:              account.Account.Currency,
+			BalanceAfter:          balanceAfter,
+			Description:           description,
+			Metadata:              metadata,
+			BranchID:              branchID,
+			ATMID:                 atmID,
+			Timestamp:             ts,
+			PostedAt:              postedAt,
+			ValueDate:             valueDate,
+			FailureReason:         failureReason,
+			Latitude:              latitude,
+			Longitude:             longitude,
+		}
+		if err := g.emit(txn, account); err != nil {
+			return err
+		}
+		if !retryAt.IsZero() {
+			if err := g.emitPurchaseRetry(txn, retryAccount, requested, retryAt, balances); err != nil {
+				return err
+			}
+		}
+
+		// Generate the counterparty side of the transaction for internal transfers
+		var counterTxn *models.Transaction
+		if counterpartyID != nil && status == models.TxStatusCompleted {
+			leg := g.counterpartyTransaction(txn, *counterpartyID, balances)
+			if err := g.emit(leg, g.accounts.byID[*counterpartyID]); err != nil {
+				return err
+			}
+			counterTxn = &leg
+		}
+		if g.emitTransfer != nil && counterpartyID != nil && isTransferType(txnType) &&
+			(counterTxn != nil || status == models.TxStatusDeclined) {
+			if err := g.emitTransfer(newTransfer(txn, counterTxn)); err != nil {
+				return err
+			}
+		}
+
+		// A completed payroll batch fans out into each employee's salary credit
+		if status == models.TxStatusCompleted {
+			for i, employeeID := range employees {
+				credit := g.payrollCredit(txn, employeeID, salaries[i], balances)
+				if err := g.emit(credit, g.accounts.byID[employeeID]); err != nil {
+					return err
+				}
+			}
+		}
+
+		if status == models.TxStatusCompleted && amount > 0 && isReversibleType(txnType) &&
+			g.rng.Probability(g.settings.ReversalRate) {
+			g.scheduleReversal(txn)
+		} else if g.emitDispute != nil && txnType == models.TxTypePurchase && status == models.TxStatusCompleted &&
+			g.rng.Probability(g.settings.DisputeRate) {
+			// Some purchases that stand are later disputed by the customer
+			if dispute, ok := g.openDispute(txn, account); ok {
+				if err := g.emitDispute(dispute); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Outgoing wires are charged the wire fee
+		if fee, ok := g.wireFee(account, balances, txn, wire); ok {
+			if err := g.emit(fee, account); err != nil {
+				return err
+			}
+		}
+
+		// A debit that overdraws the account is charged an overdraft fee
+		if fee, ok := g.overdraftFee(account, balances, txn); ok {
+			if err := g.emit(fee, account); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Interest and reversals due later in the period are posted at their own time
+	if err := g.emitDueInterest(account, balances, periodEnd); err != nil {
+		return err
+	}
+	return g.emitDueReversals(account, balances, periodEnd)
+}
+
+// nextID returns the partition's next transaction ID
+func (g *transactionCore) nextID() int64 {
+	id := g.partition.nextID
+	g.partition.nextID += g.partition.idStride
+	return id
+}
+
+// counterpartyTransaction creates the other side of a transfer. The
+// counterparty's running balance is only updated if it is being tracked.
+func (g *transactionCore) counterpartyTransaction(
+	original models.Transaction,
+	counterpartyID int64,
+	balances map[int64]int64,
+) models.Transaction {
+	// Determine the counterparty transaction type
+	var counterType models.TransactionType
+	if isDebitType(original.Type) {
+		counterType = models.TxTypeTransferIn // Debit on source = Credit on destination
+	} else {
+		counterType = models.TxTypeTransferOut // Credit on source = Debit on destination
+	}
+
+	// Update counterparty balance (only if we track it)
+	balanceAfter := balances[counterpartyID]
+	if _, exists := balances[counterpartyID]; exists {
+		amount := g.counterpartyAmount(original, counterpartyID)
+		if isDebitType(counterType) {
+			balanceAfter -= amount
+		} else {
+			balanceAfter += amount
+		}
+		balances[counterpartyID] = balanceAfter
+	}
+
+	linkedID := original.ID
+	return models.Transaction{
+		ID:                    g.nextID(),
+		ReferenceNumber:       original.ReferenceNumber, // All legs share the first leg's reference
+		AccountID:             counterpartyID,
+		CounterpartyAccountID: &original.AccountID,
+		Type:                  counterType,
+		Status:                original.Status,
+		Channel:               original.Channel,
+		Amount:                original.Amount,
+		Currency:              original.Currency,
+		BalanceAfter:          balanceAfter,
+		Description:           "Transfer from " + original.ReferenceNumber,
+		Metadata:              original.Metadata, // Wire details apply to both legs
+		LinkedTransactionID:   &linkedID,
+		Timestamp:             original.Timestamp,
+		PostedAt:              original.PostedAt,
+		ValueDate:             original.ValueDate,
+	}
+}
+
+// counterpartyAmount returns what a leg of amount in the original's currency
+// moves on the counterparty's balance: the same amount, restated in the
+// counterparty currency's minor units when they differ (e.g. a USD transfer
+// of 10.00 moves a JPY balance by 10)
+func (g *transactionCore) counterpartyAmount(original models.Transaction, counterpartyID int64) int64 {
+	if !g.settings.MinorUnits || g.accounts == nil {
+		return original.Amount
+	}
+	counterparty, ok := g.accounts.byID[counterpartyID]
+	if !ok {
+		return original.Amount
+	}
+	return convertMinorUnits(original.Amount, original.Currency, counterparty.Account.Currency)
+}
+
+// Seconds between a declined purchase and its successful retry
+const (
+	declineRetryMinDelay = 5
+	declineRetryMaxDelay = 90
+)
+
+// correlationMetadata is the metadata of a declined purchase and its retry
+func correlationMetadata(id string) string {
+	return fmt.Sprintf(`{"correlation_id":%q}`, id)
+}
+
+// purchaseRetryAccount picks another of the customer's active cards or
+// checking accounts in the same currency with the funds, counting its credit
+// or overdraft limit, to retry a declined purchase of amount on at ts. Returns
+// false when the customer has none, so the purchase isn't retried.
+func (g *transactionCore) purchaseRetryAccount(
+	declined GeneratedAccount,
+	customerAccounts map[int64][]GeneratedAccount,
+	balances map[int64]int64,
+	amount int64,
+	ts time.Time,
+) (GeneratedAccount, bool) {
+	var candidates []GeneratedAccount
+	for _, acc := range customerAccounts[declined.Account.CustomerID] {
+		a := acc.Account
+		if a.ID == declined.Account.ID || a.Status != models.AccountStatusActive || a.Currency != declined.Account.Currency ||
+			a.OpenedAt.After(ts) {
+			continue
+		}
+		var available int64
+		switch a.Type {
+		case models.AccountTypeCreditCard:
+			available = balances[a.ID] + a.CreditLimit
+		case models.AccountTypeChecking:
+			available = balances[a.ID] + a.OverdraftLimit
+		default:
+			continue
+		}
+		if available >= amount {
+			candidates = append(candidates, acc)
+		}
+	}
+	if len(candidates) == 0 {
+		return GeneratedAccount{}, false
+	}
+	return candidates[g.rng.IntN(len(candidates))], true
+}
+
+// emitPurchaseRetry emits the successful retry of a declined purchase on
+// account: the requested amount at the same merchant at ts, with the declined
+// attempt's metadata, followed by the merchant's leg and any overdraft fee
+func (g *transactionCore) emitPurchaseRetry(
+	declined models.Transaction,
+	account GeneratedAccount,
+	amount int64,
+	ts time.Time,
+	balances map[int64]int64,
+) error {
+	balanceAfter := balances[account.Account.ID] - amount
+	balances[account.Account.ID] = balanceAfter
+	if account.Account.Type == models.AccountTypeCreditCard {
+		g.purchaseVolume[account.Account.ID] += amount
+	}
+
+	id := g.nextID()
+	retry := declined
+	retry.ID = id
+	retry.ReferenceNumber = g.generateReferenceNumber(id, ts)
+	retry.AccountID = account.Account.ID
+	retry.Status = models.TxStatusCompleted
+	retry.Amount = amount
+	retry.BalanceAfter = balanceAfter
+	retry.Timestamp = ts
+	retry.PostedAt, retry.ValueDate = g.settings.SettlementLags.settle(g.rng, retry.Channel, ts)
+	retry.FailureReason = nil
+	if err := g.emit(retry, account); err != nil {
+		return err
+	}
+
+	if retry.CounterpartyAccountID != nil {
+		counterID := *retry.CounterpartyAccountID
+		if err := g.emit(g.counterpartyTransaction(retry, counterID, balances), g.accounts.byID[counterID]); err != nil {
+			return err
+		}
+	}
+	if fee, ok := g.overdraftFee(account, balances, retry); ok {
+		return g.emit(fee, account)
+	}
+	return nil
+}
+
+// reversalMaxDelay is the longest a reversal is posted after the original debit
+const reversalMaxDelay = 72 * time.Hour
+
+// pendingReversal is a completed debit waiting for its reversal to fall due
+type pendingReversal struct {
+	original models.Transaction
+	due      time.Time
+}
+
+// scheduleReversal queues a reversal of a completed debit for later in the history
+func (g *transactionCore) scheduleReversal(original models.Transaction) {
+	due := original.Timestamp.Add(g.rng.Duration(time.Hour, reversalMaxDelay))
+	if !due.Before(g.settings.EndDate) {
+		return
+	}
+
+	pending := g.pendingReversals[original.AccountID]
+	i := sort.Search(len(pending), func(i int) bool { return pending[i].due.After(due) })
+	pending = append(pending, pendingReversal{})
+	copy(pending[i+1:], pending[i:])
+	pending[i] = pendingReversal{original: original, due: due}
+	g.pendingReversals[original.AccountID] = pending
+}
+
+// emitDueReversals emits the account's scheduled reversals due before the given
+// time, crediting the amount back onto the running balance at that point so
+// activity since the original debit is preserved. Reversals falling after the
+// customer has churned are dropped.
+func (g *transactionCore) emitDueReversals(account GeneratedAccount, balances map[int64]int64, before time.Time) error {
+	pending := g.pendingReversals[account.Account.ID]
+	n := 0
+	for n < len(pending) && pending[n].due.Before(before) {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	if n == len(pending) {
+		delete(g.pendingReversals, account.Account.ID)
+	} else {
+		g.pendingReversals[account.Account.ID] = pending[n:]
+	}
+
+	for _, p := range pending[:n] {
+		if !account.Customer.ActiveAt(p.due) {
+			continue
+		}
+
+		reversal := g.reversalTransaction(account, p.original, balances, p.due)
+		if err := g.emit(reversal, account); err != nil {
+			return err
+		}
+		if counterpartyID := reversal.CounterpartyAccountID; counterpartyID != nil {
+			counterTxn := g.counterpartyTransaction(reversal, *counterpartyID, balances)
+			if err := g.emit(counterTxn, g.accounts.byID[*counterpartyID]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reversalTransaction creates the credit that reverses a completed debit,
+// linked to it, and updates the running balance
+func (g *transactionCore) reversalTransaction(
+	account GeneratedAccount,
+	original models.Transaction,
+	balances map[int64]int64,
+	ts time.Time,
+) models.Transaction {
+	balances[account.Account.ID] += original.Amount
+
+	// A reversed purchase no longer earns cashback
+	if original.Type == models.TxTypePurchase && account.Account.Type == models.AccountTypeCreditCard {
+		g.purchaseVolume[account.Account.ID] = max(g.purchaseVolume[account.Account.ID]-original.Amount, 0)
+	}
+
+	// A reversal can't post before the debit it reverses
+	postedAt := ts
+	if original.PostedAt.After(ts) {
+		postedAt = original.PostedAt
+	}
+
+	id := g.nextID()
+	linkedID := original.ID
+	return models.Transaction{
+		ID:                    id,
+		ReferenceNumber:       g.generateReferenceNumber(id, ts),
+		AccountID:             account.Account.ID,
+		CounterpartyAccountID: original.CounterpartyAccountID,
+		BeneficiaryID:         original.BeneficiaryID,
+		Type:                  models.TxTypeRefund,
+		Status:                models.TxStatusCompleted,
+		Channel:               models.ChannelInternal,
+		Amount:                original.Amount,
+		Currency:              original.Currency,
+		BalanceAfter:          balances[account.Account.ID],
+		Description:           "Reversal of " + original.ReferenceNumber,
+		Metadata:              "{}",
+		LinkedTransactionID:   &linkedID,
+		Timestamp:             ts,
+		PostedAt:              postedAt,
+		ValueDate:             original.ValueDate, // Back-valued to the original debit
+	}
+}
+
+// overdraftFeeDay counts the overdraft fees charged to an account on one day
+type overdraftFeeDay struct {
+	day   string // Customer-local date, YYYY-MM-DD
+	count int
+}
+
+// overdraftFee charges the configured fee when a completed debit leaves an
+// account with an overdraft limit below zero. Every overdrawing debit is
+// charged, up to the daily cap, unless the fee would take the balance past
+// the overdraft limit. Returns false when no fee is due.
+func (g *transactionCore) overdraftFee(
+	account GeneratedAccount,
+	balances map[int64]int64,
+	debit models.Transaction,
+) (models.Transaction, bool) {
+	fee := g.inMinorUnits(account.Account.Currency, g.settings.OverdraftFee)
+	balance := balances[account.Account.ID]
+	if fee <= 0 || account.Account.OverdraftLimit <= 0 || debit.Status != models.TxStatusCompleted ||
+		debit.Amount == 0 || !isDebitType(debit.Type) || balance >= 0 || balance-fee < -account.Account.OverdraftLimit {
+		return models.Transaction{}, false
+	}
+
+	day := debit.Timestamp.Format("2006-01-02")
+	charged := g.overdraftFees[account.Account.ID]
+	if charged.day != day {
+		charged = overdraftFeeDay{day: day}
+	}
+	if limit := g.settings.OverdraftFeeDailyCap; limit > 0 && charged.count >= limit {
+		return models.Transaction{}, false
+	}
+	charged.count++
+	g.overdraftFees[account.Account.ID] = charged
+
+	balances[account.Account.ID] -= fee
+	id := g.nextID()
+	linkedID := debit.ID
+	return models.Transaction{
+		ID:                  id,
+		ReferenceNumber:     g.generateReferenceNumber(id, debit.Timestamp),
+		AccountID:           account.Account.ID,
+		Type:                models.TxTypeFee,
+		Status:              models.TxStatusCompleted,
+		Channel:             models.ChannelInternal,
+		Amount:              fee,
+		Currency:            account.Account.Currency,
+		BalanceAfter:        balances[account.Account.ID],
+		Description:         "Overdraft Fee",
+		Metadata:            "{}",
+		LinkedTransactionID: &linkedID,
+		Timestamp:           debit.Timestamp,
+		PostedAt:            debit.PostedAt,
+		ValueDate:           debit.ValueDate,
+	}, true
+}
+
+// cashbackTransaction creates a completed cashback credit and updates the running balance
+func (g *transactionCore) cashbackTransaction(
+	account GeneratedAccount,
+	balances map[int64]int64,
+	amount int64,
+	ts time.Time,
+) models.Transaction {
+	balances[account.Account.ID] += amount
+	id := g.nextID()
+	return models.Transaction{
+		ID:              id,
+		ReferenceNumber: g.generateReferenceNumber(id, ts),
+		AccountID:       account.Account.ID,
+		Type:            models.TxTypeCashback,
+		Status:          models.TxStatusCompleted,
+		Channel:         models.ChannelInternal,
+		Amount:          amount,
+		Currency:        account.Account.Currency,
+		BalanceAfter:    balances[account.Account.ID],
+		Description:     g.generateDescription(models.TxTypeCashback, FeeRate{}, models.ChannelInternal, account),
+		Metadata:        "{}",
+		Timestamp:       ts,
+		PostedAt:        ts,
+		ValueDate:       ts,
+	}
+}
+
+// selectPattern chooses the appropriate time pattern for an account
+func (g *transactionCore) selectPattern(account GeneratedAccount) *patterns.FullPattern {
+	if account.Customer.Customer.IsBusinessCustomer() {
+		return g.businessPattern
+	}
+
+	switch account.Account.Type {
+	case models.AccountTypeMerchant, models.AccountTypeBusiness, models.AccountTypePayroll:
+		return g.businessPattern
+	default:
+		return g.retailPattern
+	}
+}
+
+// weekendVolume returns the weekend volume multiplier for the account's pattern
+func (g *transactionCore) weekendVolume(account GeneratedAccount) float64 {
+	return g.settings.WeekendVolume.multiplier(g.selectPattern(account) == g.businessPattern)
+}
+
+// generateTimestamps creates realistic timestamps distributed across a period
+func (g *transactionCore) generateTimestamps(
+	start, end time.Time,
+	count int,
+	pattern *patterns.FullPattern,
+	account GeneratedAccount,
+) []time.Time {
+	timestamps := make([]time.Time, 0, count)
+	duration := end.Sub(start)
+	hours := g.businessHours[account.Account.Type]
+	// Weekday and weekend weights are scaled so the larger is 1
+	weekendVolume := g.weekendVolume(account)
+	peak := max(weekendVolume, 1)
+
+	for i := 0; i < count; i++ {
+		// Generate a random point in the period
+		offset := time.Duration(g.rng.Float64() * float64(duration))
+		ts := start.Add(offset)
+
+		// Adjust to realistic hours using daily pattern
+		hour, minute := patterns.NewDailyPattern().TimeInActiveWindow(g.rng.Float64())
+		ts = time.Date(ts.Year(), ts.Month(), ts.Day(), hour, minute, g.rng.IntRange(0, 59), 0, time.UTC)
+
+		// Apply timezone offset for the customer
+		if tz, err := time.LoadLocation(account.Customer.Customer.Timezone); err == nil {
+			ts = ts.In(tz)
+		}
+
+		// Accept based on pattern multiplier (rejection sampling), weighting
+		// weekends by the weekend volume and suppressing weekends and nights
+		// for account types with business hours
+		if g.rng.Float64() < pattern.GetMultiplier(ts)*hours.factor(ts)*weekendWeight(ts, weekendVolume)/peak {
+			timestamps = append(timestamps, ts)
+		} else {
+			// Retry with another timestamp
+			i--
+		}
+	}
+
+	// Process events in time order so running balances (and interest on them)
+	// evolve chronologically within the period
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].Before(timestamps[j]) })
+
+	return timestamps
+}
+
+// plannedTransaction is a transaction's time, type and channel, chosen
+// before its amount and counterparty
+type plannedTransaction struct {
+	ts      time.Time
+	txnType models.TransactionType
+	channel models.TransactionChannel
+}
+
+// planTransactions selects the type and channel for each timestamp. Retail
+// accounts then redraw the time of day from the channel's intraday curve (ATM
+// at lunch and evening, online late in the evening, branch in office hours),
+// keeping the day. The plan is returned in time order.
+func (g *transactionCore) planTransactions(
+	account GeneratedAccount,
+	pattern *patterns.FullPattern,
+	timestamps []time.Time,
+	periodStart, periodEnd time.Time,
+) []plannedTransaction {
+	planned := make([]plannedTransaction, len(timestamps))
+	for i, ts := range timestamps {
+		txnType, channel := g.selectTransactionType(account, ts)
+		// Customers without online banking do at a branch what others do online
+		if channel == models.ChannelOnline && account.Customer.Offline {
+			channel = models.ChannelBranch
+		}
+		if pattern == g.retailPattern {
+			if curve := g.channelPattern(channel); curve != nil {
+				hour, minute := curve.TimeOfDay(g.rng.Float64())
+				moved := time.Date(ts.Year(), ts.Month(), ts.Day(), hour, minute, ts.Second(), 0, ts.Location())
+				if !moved.Before(periodStart) && moved.Before(periodEnd) {
+					ts = moved
+				}
+			}
+		}
+		if channel == models.ChannelBranch && g.settings.BranchHours {
+			ts, channel = g.duringBranchHours(account, txnType, ts, periodStart, periodEnd)
+		}
+		planned[i] = plannedTransaction{ts: ts, txnType: txnType, channel: channel}
+	}
+
+	sort.SliceStable(planned, func(i, j int) bool { return planned[i].ts.Before(planned[j].ts) })
+	return planned
+}
+
+// duringBranchHours moves a branch transaction into the operating hours of
+// the customer's home branch on the same day, in the branch's local time.
+// When the branch is closed all that day (or the move would leave the
+// period), the customer uses the ATM or the app instead, or the ATM without
+// online banking.
+func (g *transactionCore) duringBranchHours(
+	account GeneratedAccount,
+	txnType models.TransactionType,
+	ts, periodStart, periodEnd time.Time,
+) (time.Time, models.TransactionChannel) {
+	home := branchByID(g.branches, account.Customer.HomeBranchAt(ts))
+	if home == nil || !home.OpenAt(ts) || home.InHoursAt(ts) {
+		return ts, models.ChannelBranch
+	}
+	if opens, closes, ok := home.HoursOn(ts); ok {
+		moved := opens.Add(time.Duration(g.rng.Float64() * float64(closes.Sub(opens)))).In(ts.Location())
+		if !moved.Before(periodStart) && moved.Before(periodEnd) {
+			return moved, models.ChannelBranch
+		}
+	}
+	if txnType == models.TxTypeWithdrawal || account.Customer.Offline {
+		return ts, models.ChannelATM
+	}
+	return ts, models.ChannelOnline
+}
+
+// channelPattern returns the intraday pattern for a channel, or nil if the
+// channel's timing follows the account's pattern
+func (g *transactionCore) channelPattern(channel models.TransactionChannel) *patterns.FullPattern {
+	switch channel {
+	case models.ChannelATM:
+		return g.atmPattern
+	case models.ChannelOnline:
+		return g.onlinePattern
+	case models.ChannelBranch:
+		return g.businessPattern
+	case models.ChannelPOS:
+		return g.retailPattern
+	}
+	return nil
+}
+
+// selectTransactionType chooses an appropriate transaction type for the account
+func (g *transactionCore) selectTransactionType(account GeneratedAccount, ts time.Time) (models.TransactionType, models.TransactionChannel) {
+	// Check for payroll day
+	monthlyPattern := patterns.NewMonthlyPattern()
+	if monthlyPattern.IsPayrollDay(ts.Day()) && account.Account.Type == models.AccountTypePayroll {
+		return models.TxTypePayrollBatch, models.ChannelInternal
+	}
+
+	if account.Account.Type == models.AccountTypeChecking {
+		return g.selectCheckingTransactionType(account, ts)
+	}
+	return g.pickTransactionType(account)
+}
+
+// pickTransactionType draws from the account type's transaction mix
+func (g *transactionCore) pickTransactionType(account GeneratedAccount) (models.TransactionType, models.TransactionChannel) {
+	picker, ok := g.typePickers[account.Account.Type]
+	if !ok {
+		return models.TxTypeDeposit, models.ChannelOnline
+	}
+	return picker.Pick(g.rng)
+}
+
+// selectCheckingTransactionType chooses transaction type for checking accounts
+func (g *transactionCore) selectCheckingTransactionType(account GeneratedAccount, ts time.Time) (models.TransactionType, models.TransactionChannel) {
+	r := g.rng.Float64()
+
+	// Monthly patterns influence transaction types
+	monthlyPattern := patterns.NewMonthlyPattern()
+
+	// Salary deposits around payroll days
+	if monthlyPattern.IsPayrollDay(ts.Day()) && r < 0.15 {
+		return models.TxTypeSalary, models.ChannelACH
+	}
+
+	// Bill payments at start of month
+	if monthlyPattern.IsStartOfMonth(ts) && r < 0.25 {
+		return models.TxTypeBillPayment, models.ChannelOnline
+	}
+
+	return g.pickTransactionType(account)
+}
+
+// generateAmount creates a realistic transaction amount.
+// fee is the fee type drawn for fee transactions; balance is the account's
+// running balance at ts (used for interest). ATM withdrawals are converted
+// from US dollars and rounded to the currency's note.
+func (g *transactionCore) generateAmount(txnType models.TransactionType, channel models.TransactionChannel, fee FeeRate, account GeneratedAccount, balance int64, ts time.Time) int64 {
+	// Interest accrues on the balance, already in the currency's minor units
+	if txnType == models.TxTypeInterestCredit || txnType == models.TxTypeInterestDebit {
+		return g.interestAmount(account, balance, ts)
+	}
+	cents := g.amountInCents(txnType, fee, account)
+	// ATMs dispense whole notes, so the amount drawn in dollars is converted
+	// first: at par a JPY withdrawal would almost always be one ¥1000 note
+	if txnType == models.TxTypeWithdrawal && channel == models.ChannelATM {
+		currency := account.Account.Currency
+		cents = int64(float64(cents) * fxRate(models.CurrencyUSD, currency))
+		cents = g.settings.ATMDenominations.dispense(currency, cents)
+	}
+	return g.inMinorUnits(account.Account.Currency, cents)
+}
+
+// inMinorUnits converts an amount in hundredths to the currency's minor
+// units when MinorUnits is set
+func (g *transactionCore) inMinorUnits(currency models.Currency, cents int64) int64 {
+	if !g.settings.MinorUnits {
+		return cents
+	}
+	return toMinorUnits(cents, currency)
+}
+
+// amountInCents draws the amount of a transaction, in hundredths of the account's currency
+func (g *transactionCore) amountInCents(txnType models.TransactionType, fee FeeRate, account GeneratedAccount) int64 {
+	var dist *patterns.AmountDistribution
+
+	switch txnType {
+	case models.TxTypeWithdrawal:
+		dist = g.amounts.ATMWithdrawal
+	case models.TxTypePurchase:
+		// Vary by purchase size, which shifts with the customer's segment
+		profile := merchantProfileFor(account.Customer.Customer.Segment)
+		dist = profile.purchaseDistribution(g.amounts, g.rng.Float64())
+	case models.TxTypeBillPayment:
+		dist = g.amounts.BillPayment
+	case models.TxTypeSalary:
+		dist = g.amounts.Salary
+	case models.TxTypeTransferIn, models.TxTypeTransferOut:
+		dist = g.amounts.InternalTransfer
+	case models.TxTypePayrollBatch:
+		// Large payroll amount, used when there are no employee accounts to pay
+		return g.rng.Int64Range(50000000, 500000000) // $500k - $5M
+	case models.TxTypeFee:
+		return fee.Amount(g.rng)
+	case models.TxTypeRefund:
+		dist = g.amounts.MediumPurchase // Refunds are usually for previous purchases
+	case models.TxTypeCashback:
+		return g.rng.Int64Range(100, 2000) // $1 - $20
+	default:
+		dist = g.amounts.MediumPurchase
+	}
+
+	if dist == nil {
+		return g.rng.Int64Range(1000, 10000)
+	}
+
+	return dist.GenerateAmount(g.rng.Float64(), g.rng.NormalFloat64())
+}
+
+// shouldDecline determines if a transaction should be declined
+func (g *transactionCore) shouldDecline(txnType models.TransactionType, balance, amount int64) bool {
+	// Only decline debit transactions
+	if !isDebitType(txnType) {
+		return false
+	}
+
+	// Random decline based on configured rate
+	if g.rng.Probability(g.settings.DeclinedTransactionRate) {
+		return true
+	}
+
+	// Insufficient funds check
+	if g.rng.Probability(g.settings.InsufficientFundsRate) && balance < amount {
+		return true
+	}
+
+	return false
+}
+
+// interestAmount calculates interest accrued on the running balance since the
+// account's last interest posting, compounding monthly at the account's rate.
+func (g *transactionCore) interestAmount(account GeneratedAccount, balance int64, ts time.Time) int64 {
+	if balance < 0 {
+		balance = -balance
+	}
+
+	from, ok := g.interestAccruedAt[account.Account.ID]
+	if !ok {
+		from = g.settings.StartDate
+		if account.Account.OpenedAt.After(from) {
+			from = account.Account.OpenedAt
+		}
+	}
+	if ts.After(from) {
+		g.interestAccruedAt[account.Account.ID] = ts
+	}
+
+	return accruedInterest(balance, account.Account.InterestRate, from, ts)
+}
+
+// selectCounterparty selects a counterparty account for transfers
+func (g *transactionCore) selectCounterparty(
+	txnType models.TransactionType,
+	account GeneratedAccount,
+	customerAccounts map[int64][]GeneratedAccount,
+) (*int64, *int64) {
+	switch txnType {
+	case models.TxTypeTransferIn, models.TxTypeTransferOut:
+		// Internal transfer with one of the account's usual counterparties
+		if g.settings.TransferGraph.Payees > 0 {
+			if id, ok := g.transferCounterparty(account, customerAccounts); ok {
+				return &id, nil
+			}
+			return nil, nil
+		}
+
+		// Otherwise with the customer's first other account
+		accounts := customerAccounts[account.Account.CustomerID]
+		for _, acc := range accounts {
+			if acc.Account.ID != account.Account.ID {
+				id := acc.Account.ID
+				return &id, nil
+			}
+		}
+
+	case models.TxTypePurchase:
+		// Purchase goes to a merchant
+		if len(g.accounts.merchantIDs) > 0 {
+			id := g.accounts.merchantIDs[g.rng.IntN(len(g.accounts.merchantIDs))]
+			return &id, nil
+		}
+
+	case models.TxTypeBillPayment:
+		// Bill payment to utility
+		if len(g.accounts.utilityIDs) > 0 {
+			id := g.accounts.utilityIDs[g.rng.IntN(len(g.accounts.utilityIDs))]
+			return &id, nil
+		}
+
+	case models.TxTypeSalary:
+		// Salary from employer
+		if len(g.accounts.employerIDs) > 0 {
+			id := g.accounts.employerIDs[g.rng.IntN(len(g.accounts.employerIDs))]
+			return &id, nil
+		}
+
+	case models.TxTypePayrollBatch:
+		// Payroll batches fan out into one salary credit per employee
+		// (see payrollCredit) rather than a single counterparty
+		return nil, nil
+	}
+
+	return nil, nil
+}
+
+// homeBranchVisitRate is the fraction of branch transactions made at the
+// customer's home branch (the rest are at any open branch)
+const homeBranchVisitRate = 0.8
+
+// selectLocation picks a branch or ATM serving customers at ts for the transaction
+func (g *transactionCore) selectLocation(channel models.TransactionChannel, account GeneratedAccount, ts time.Time) (*int64, *int64) {
+	switch channel {
+	case models.ChannelATM:
+		if atm := pickATMInService(g.rng, g.atms, ts); atm != nil {
+			return nil, &atm.ATM.ID
+		}
+	case models.ChannelBranch:
+		// Most branch visits are to the customer's home branch at the time
+		home := branchByID(g.branches, account.Customer.HomeBranchAt(ts))
+		homeOpen := home != nil && home.OpenAt(ts) && (!g.settings.BranchHours || home.InHoursAt(ts))
+		if g.rng.Probability(homeBranchVisitRate) && homeOpen {
+			return &home.Branch.ID, nil
+		}
+		if branch := pickOpenBranch(g.rng, g.branches, ts, g.settings.BranchHours); branch != nil {
+			return &branch.Branch.ID, nil
+		}
+		// With branch hours, the visit was planned for the home branch's hours
+		if g.settings.BranchHours && homeOpen {
+			return &home.Branch.ID, nil
+		}
+	}
+	return nil, nil
+}
+
+// generateDescription creates a realistic transaction description.
+// fee is the fee type drawn for fee transactions.
+func (g *transactionCore) generateDescription(
+	txnType models.TransactionType,
+	fee FeeRate,
+	channel models.TransactionChannel,
+	account GeneratedAccount,
+) string {
+	switch txnType {
+	case models.TxTypeWithdrawal:
+		return fmt.Sprintf("ATM Withdrawal - %s", g.pickLocation(account))
+	case models.TxTypePurchase:
+		return fmt.Sprintf("POS Purchase - %s", g.pickMerchantName(account))
+	case models.TxTypeBillPayment:
+		return fmt.Sprintf("Bill Payment - %s", g.pickUtilityName())
+	case models.TxTypeSalary:
+		return "Direct Deposit - Payroll"
+	case models.TxTypeTransferIn:
+		return "Transfer from linked account"
+	case models.TxTypeTransferOut:
+		return "Transfer to linked account"
+	case models.TxTypeDeposit:
+		if channel == models.ChannelBranch {
+			return "Branch Deposit"
+		}
+		return "Mobile Deposit"
+	case models.TxTypeInterestCredit:
+		return "Interest Payment"
+	case models.TxTypeInterestDebit:
+		return "Interest Charge"
+	case models.TxTypeFee:
+		return fee.Description
+	case models.TxTypeRefund:
+		return "Refund - " + g.pickMerchantName(account)
+	case models.TxTypeCashback:
+		return "Cashback Reward"
+	case models.TxTypePayrollBatch:
+		return "Payroll Disbursement"
+	case models.TxTypeLoanPayment:
+		return "Loan Payment"
+	default:
+		return "Transaction"
+	}
+}
+
+// pickLocation returns a location name for ATM withdrawals
+func (g *transactionCore) pickLocation(account GeneratedAccount) string {
+	locations := []string{
+		"Main Street", "Downtown", "Airport Terminal", "Mall",
+		"University", "Hospital", "Train Station", "Shopping Center",
+	}
+	city := account.Account.Currency // Use currency as proxy for location variety
+	return fmt.Sprintf("%s - %s", locations[g.rng.IntN(len(locations))], city)
+}
+
+// pickMerchantName returns a realistic merchant name for the account holder's segment
+func (g *transactionCore) pickMerchantName(account GeneratedAccount) string {
+	merchants := merchantProfileFor(account.Customer.Customer.Segment).merchants
+	return merchants[g.rng.IntN(len(merchants))]
+}
+
+// pickUtilityName returns a utility company name
+func (g *transactionCore) pickUtilityName() string {
+	utilities := []string{
+		"Electric Company", "Gas & Power", "Water Services",
+		"Internet Provider", "Phone Company", "Insurance Co",
+		"City Services", "Waste Management",
+	}
+	return utilities[g.rng.IntN(len(utilities))]
+}
+
+// generateReferenceNumber creates the reference for a logical transaction
+// from its first leg: TXN<yyyymmdd><12-digit id>. Every other leg (the
+// counterparty side of a transfer, a card purchase credited to a merchant,
+// salary debited from payroll) copies the first leg's reference; reversals and
+// cashback are new logical transactions with their own reference, linked
+// through linked_transaction_id. Transaction IDs never repeat across workers
+// or continuation runs, so references are unique per logical transaction.
+func (g *transactionCore) generateReferenceNumber(id int64, ts time.Time) string {
+	return fmt.Sprintf("TXN%s%012d", ts.Format("20060102"), id)
+}
+
+// transactionRow formats a transaction as a CSV row matching TransactionHeaders,
+// or TransactionGeoHeaders if geo is set
+func transactionRow(t models.Transaction, geo bool) []string {
+	row := []string{
+		FormatInt64(t.ID),
+		t.ReferenceNumber,
+		FormatInt64(t.AccountID),
+		FormatInt64Ptr(t.CounterpartyAccountID),
+		FormatInt64Ptr(t.BeneficiaryID),
+		string(t.Type),
+		string(t.Status),
+		string(t.Channel),
+		FormatInt64(t.Amount),
+		string(t.Currency),
+		FormatInt64(t.BalanceAfter),
+		t.Description,
+		t.Metadata,
+		FormatInt64Ptr(t.BranchID),
+		FormatInt64Ptr(t.ATMID),
+		FormatInt64Ptr(t.LinkedTransactionID),
+		FormatTime(t.Timestamp),
+		FormatTime(t.PostedAt),
+		FormatDate(t.ValueDate),
+		formatStringPtr(t.FailureReason),
+	}
+	if geo {
+		row = append(row, FormatFloat64Ptr(t.Latitude), FormatFloat64Ptr(t.Longitude))
+	}
+	return row
+}
+
+// accruedInterest returns the interest earned (or charged) on balance between
+// from and to at an annual rate in basis points, compounded monthly. Elapsed time
+// is measured in fractional months so postings at any interval sum consistently.
+func accruedInterest(balance int64, annualRateBps int, from, to time.Time) int64 {
+	if balance <= 0 || annualRateBps <= 0 || !to.After(from) {
+		return 0
+	}
+	monthlyRate := float64(annualRateBps) / 10000 / 12
+	months := to.Sub(from).Hours() / (24 * 365.25 / 12)
+	return int64(float64(balance) * (math.Pow(1+monthlyRate, months) - 1))
+}
+
+// cashbackAmount returns the reward earned on a purchase volume at rate, rounded to the nearest cent
+func cashbackAmount(purchases int64, rate float64) int64 {
+	if purchases <= 0 || rate <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(purchases) * rate))
+}
+
+// isReversibleType returns true for debits that may later be reversed (erroneous
+// charges, failed dispenses, disputed payments)
+func isReversibleType(txnType models.TransactionType) bool {
+	switch txnType {
+	case models.TxTypePurchase, models.TxTypeWithdrawal, models.TxTypeTransferOut,
+		models.TxTypeBillPayment, models.TxTypeFee:
+		return true
+	default:
+		return false
+	}
+}
+
+// isReversal returns true if t is the credit reversing an earlier transaction
+func isReversal(t models.Transaction) bool {
+	return t.Type == models.TxTypeRefund && t.LinkedTransactionID != nil
+}
+
+// isDebitType returns true if the transaction type is a debit
+func isDebitType(txnType models.TransactionType) bool {
+	switch txnType {
+	case models.TxTypeWithdrawal, models.TxTypePurchase, models.TxTypeTransferOut,
+		models.TxTypeBillPayment, models.TxTypeInterestDebit, models.TxTypeFee,
+		models.TxTypeLoanPayment, models.TxTypePayrollBatch:
+		return true
+	default:
+		return false
+	}
+}
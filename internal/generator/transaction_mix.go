@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// TransactionTypeWeight is one row of an account type's transaction mix: how
+// often (relative to the other rows) a transaction is of this type and channel
+type TransactionTypeWeight struct {
+	Type    models.TransactionType    `json:"type"`
+	Channel models.TransactionChannel `json:"channel"`
+	Weight  int                       `json:"weight"`
+}
+
+// DefaultTransactionTypeWeights returns the built-in transaction mix for each
+// account type (weights are percentages). Account types without a table get
+// online deposits. Payroll day salaries, bill payments and payroll batches
+// are chosen before the table is consulted.
+func DefaultTransactionTypeWeights() map[models.AccountType][]TransactionTypeWeight {
+	return map[models.AccountType][]TransactionTypeWeight{
+		models.AccountTypeChecking: {
+			{models.TxTypeWithdrawal, models.ChannelATM, 20},
+			{models.TxTypePurchase, models.ChannelPOS, 15},
+			{models.TxTypeTransferOut, models.ChannelOnline, 15},
+			{models.TxTypeBillPayment, models.ChannelOnline, 10},
+			{models.TxTypeDeposit, models.ChannelBranch, 15},
+			{models.TxTypeTransferIn, models.ChannelOnline, 10},
+			{models.TxTypeSalary, models.ChannelACH, 10},
+			{models.TxTypeFee, models.ChannelInternal, 5},
+		},
+		models.AccountTypeSavings: {
+			{models.TxTypeTransferIn, models.ChannelOnline, 40},  // Deposits from checking
+			{models.TxTypeTransferOut, models.ChannelOnline, 30}, // Withdrawals to checking
+			{models.TxTypeInterestCredit, models.ChannelInternal, 15},
+			{models.TxTypeDeposit, models.ChannelBranch, 10},
+			{models.TxTypeFee, models.ChannelInternal, 5},
+		},
+		models.AccountTypeCreditCard: {
+			{models.TxTypePurchase, models.ChannelPOS, 65},
+			{models.TxTypePurchase, models.ChannelOnline, 15},
+			{models.TxTypeDeposit, models.ChannelOnline, 10}, // Payment
+			{models.TxTypeRefund, models.ChannelPOS, 5},
+			{models.TxTypeInterestDebit, models.ChannelInternal, 5},
+		},
+		models.AccountTypeBusiness: {
+			{models.TxTypeDeposit, models.ChannelACH, 30},      // Customer payments
+			{models.TxTypeTransferOut, models.ChannelWire, 20}, // Supplier payments
+			{models.TxTypeBillPayment, models.ChannelOnline, 15},
+			{models.TxTypeTransferIn, models.ChannelACH, 15},
+			{models.TxTypeWithdrawal, models.ChannelBranch, 10},
+			{models.TxTypeFee, models.ChannelInternal, 10},
+		},
+		models.AccountTypeMerchant: {
+			{models.TxTypeDeposit, models.ChannelPOS, 100}, // Merchants receive payments
+		},
+		models.AccountTypePayroll: {
+			// Outside payroll days, mainly deposits to fund payroll
+			{models.TxTypeTransferIn, models.ChannelInternal, 70},
+			{models.TxTypeFee, models.ChannelInternal, 30},
+		},
+	}
+}
+
+// WeightedTypePicker picks a transaction type and channel from a weight table
+type WeightedTypePicker struct {
+	rows    []TransactionTypeWeight
+	weights []int
+}
+
+// NewWeightedTypePicker creates a picker over rows, which must have at least
+// one positive weight
+func NewWeightedTypePicker(rows []TransactionTypeWeight) *WeightedTypePicker {
+	p := &WeightedTypePicker{rows: rows, weights: make([]int, len(rows))}
+	for i, row := range rows {
+		p.weights[i] = row.Weight
+	}
+	return p
+}
+
+// Pick draws a transaction type and channel
+func (p *WeightedTypePicker) Pick(rng *utils.Random) (models.TransactionType, models.TransactionChannel) {
+	row := p.rows[rng.WeightedPick(p.weights)]
+	return row.Type, row.Channel
+}
+
+// typePickersByType builds a picker per account type, falling back to
+// DefaultTransactionTypeWeights when no mix is configured
+func typePickersByType(config map[models.AccountType][]TransactionTypeWeight) map[models.AccountType]*WeightedTypePicker {
+	if config == nil {
+		config = DefaultTransactionTypeWeights()
+	}
+	pickers := make(map[models.AccountType]*WeightedTypePicker, len(config))
+	for accountType, rows := range config {
+		pickers[accountType] = NewWeightedTypePicker(rows)
+	}
+	return pickers
+}
+
+// Transaction types and channels a mix may use. Payroll batches and cashback
+// are generated on their own schedules.
+var (
+	mixTransactionTypes = []models.TransactionType{
+		models.TxTypeDeposit, models.TxTypeSalary, models.TxTypeTransferIn, models.TxTypeInterestCredit,
+		models.TxTypeRefund, models.TxTypeWithdrawal, models.TxTypePurchase, models.TxTypeTransferOut,
+		models.TxTypeBillPayment, models.TxTypeInterestDebit, models.TxTypeFee, models.TxTypeLoanPayment,
+	}
+	mixChannels = []models.TransactionChannel{
+		models.ChannelOnline, models.ChannelATM, models.ChannelBranch, models.ChannelPOS,
+		models.ChannelACH, models.ChannelWire, models.ChannelInternal,
+	}
+	mixAccountTypes = []models.AccountType{
+		models.AccountTypeChecking, models.AccountTypeSavings, models.AccountTypeCreditCard,
+		models.AccountTypeLoan, models.AccountTypeMortgage, models.AccountTypeInvestment,
+		models.AccountTypeBusiness, models.AccountTypeMerchant, models.AccountTypePayroll,
+	}
+)
+
+// LoadTransactionMixFile reads a transaction mix override file and merges it
+// over DefaultTransactionTypeWeights. Each account type lists rows to add or
+// reweight, matched by type and channel; a weight of 0 removes the row.
+func LoadTransactionMixFile(path string) (map[models.AccountType][]TransactionTypeWeight, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction mix file: %w", err)
+	}
+	var overrides map[string][]TransactionTypeWeight
+	if err := json.Unmarshal(raw, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction mix file: %w", err)
+	}
+
+	mix := DefaultTransactionTypeWeights()
+	var errs []string
+	for name, rows := range overrides {
+		accountType := models.AccountType(strings.ToLower(name))
+		if !slices.Contains(mixAccountTypes, accountType) {
+			errs = append(errs, fmt.Sprintf("unknown account type %q", name))
+			continue
+		}
+		table := mix[accountType]
+		for _, row := range rows {
+			switch {
+			case !slices.Contains(mixTransactionTypes, row.Type):
+				errs = append(errs, fmt.Sprintf("%s: unsupported transaction type %q", name, row.Type))
+				continue
+			case !slices.Contains(mixChannels, row.Channel):
+				errs = append(errs, fmt.Sprintf("%s: unknown channel %q", name, row.Channel))
+				continue
+			case row.Weight < 0:
+				errs = append(errs, fmt.Sprintf("%s: %s/%s weight %d is negative", name, row.Type, row.Channel, row.Weight))
+				continue
+			}
+			table = setTypeWeight(table, row)
+		}
+		if totalWeight(table) == 0 {
+			errs = append(errs, fmt.Sprintf("%s: no transaction type has a positive weight", name))
+		}
+		mix[accountType] = table
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("invalid transaction mix: %s", strings.Join(errs, "; "))
+	}
+	return mix, nil
+}
+
+// setTypeWeight replaces the weight of row's type and channel in table, adding
+// the row if it is new and dropping it if the weight is 0
+func setTypeWeight(table []TransactionTypeWeight, row TransactionTypeWeight) []TransactionTypeWeight {
+	out := make([]TransactionTypeWeight, 0, len(table)+1)
+	found := false
+	for _, existing := range table {
+		if existing.Type == row.Type && existing.Channel == row.Channel {
+			found = true
+			existing.Weight = row.Weight
+		}
+		if existing.Weight > 0 {
+			out = append(out, existing)
+		}
+	}
+	if !found && row.Weight > 0 {
+		out = append(out, row)
+	}
+	return out
+}
+
+func totalWeight(table []TransactionTypeWeight) int {
+	total := 0
+	for _, row := range table {
+		total += row.Weight
+	}
+	return total
+}
@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestLoadTransactionMixFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "mix.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	mix, err := LoadTransactionMixFile(write(`{"Checking": [
+		{"type": "withdrawal", "channel": "atm", "weight": 40},
+		{"type": "purchase", "channel": "pos", "weight": 0},
+		{"type": "withdrawal", "channel": "branch", "weight": 5}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultTransactionTypeWeights()
+	checking := mix[models.AccountTypeChecking]
+	if len(checking) != len(defaults[models.AccountTypeChecking]) {
+		t.Fatalf("checking has %d rows, expected one removed and one added: %+v", len(checking), checking)
+	}
+	weights := make(map[string]int)
+	for _, row := range checking {
+		weights[string(row.Type)+"/"+string(row.Channel)] = row.Weight
+	}
+	if weights["withdrawal/atm"] != 40 || weights["withdrawal/branch"] != 5 || weights["fee/internal"] != 5 {
+		t.Errorf("unexpected checking weights %v", weights)
+	}
+	if _, ok := weights["purchase/pos"]; ok {
+		t.Error("expected the zero-weight row to be removed")
+	}
+	if len(mix[models.AccountTypeSavings]) != len(defaults[models.AccountTypeSavings]) {
+		t.Errorf("savings mix %+v, expected the default", mix[models.AccountTypeSavings])
+	}
+
+	_, err = LoadTransactionMixFile(write(`{"vault": [], "savings": [
+		{"type": "payroll_batch", "channel": "internal", "weight": 1},
+		{"type": "deposit", "channel": "teller", "weight": 1}
+	], "merchant": [{"type": "deposit", "channel": "pos", "weight": 0}]}`))
+	for _, want := range []string{`unknown account type "vault"`, `unsupported transaction type "payroll_batch"`, `unknown channel "teller"`, "merchant: no transaction type"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestWeightedTypePicker(t *testing.T) {
+	picker := NewWeightedTypePicker([]TransactionTypeWeight{
+		{models.TxTypeWithdrawal, models.ChannelATM, 3},
+		{models.TxTypePurchase, models.ChannelPOS, 1},
+		{models.TxTypeFee, models.ChannelInternal, 0},
+	})
+	rng := utils.NewRandom(1)
+	counts := make(map[models.TransactionChannel]int)
+	for i := 0; i < 4000; i++ {
+		txnType, channel := picker.Pick(rng)
+		if txnType == models.TxTypeFee {
+			t.Fatal("picked a zero-weight row")
+		}
+		counts[channel]++
+	}
+	if atm := counts[models.ChannelATM]; atm < 2800 || atm > 3200 {
+		t.Errorf("%d of 4000 picks were ATM withdrawals, expected about 3000", atm)
+	}
+}
+
+func TestTransactionMixShiftsGeneratedTypes(t *testing.T) {
+	cashHeavy := DefaultTransactionTypeWeights()
+	cashHeavy[models.AccountTypeChecking] = []TransactionTypeWeight{
+		{models.TxTypeWithdrawal, models.ChannelATM, 90},
+		{models.TxTypeDeposit, models.ChannelBranch, 10},
+	}
+
+	count := func(mix map[models.AccountType][]TransactionTypeWeight) (withdrawals, purchases int) {
+		g := newTestTransactionGenerator(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+		g.typePickers = typePickersByType(mix)
+		account := GeneratedAccount{Account: models.Account{
+			ID:       1,
+			Type:     models.AccountTypeChecking,
+			Currency: "USD",
+			Balance:  1_000_000,
+			OpenedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}}
+		txns, _ := g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+		for _, gt := range txns {
+			switch gt.Transaction.Type {
+			case models.TxTypeWithdrawal:
+				withdrawals++
+			case models.TxTypePurchase:
+				purchases++
+			}
+		}
+		return withdrawals, purchases
+	}
+
+	defaultWithdrawals, defaultPurchases := count(nil)
+	withdrawals, purchases := count(cashHeavy)
+	if defaultPurchases == 0 || purchases != 0 {
+		t.Errorf("purchases: %d by default, %d with the cash-heavy mix (expected none)", defaultPurchases, purchases)
+	}
+	if withdrawals <= defaultWithdrawals {
+		t.Errorf("withdrawals: %d by default, %d with the cash-heavy mix", defaultWithdrawals, withdrawals)
+	}
+}
@@ -0,0 +1,352 @@
+package generator
+
+import (
+	"bufio"
+	"cmp"
+	"container/heap"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+const (
+	defaultSortRunRows = 500000
+	// sortMergeFanIn caps the runs merged at once; more are merged in passes
+	sortMergeFanIn = 128
+)
+
+// sortTimestampColumn is the position of timestamp in TransactionHeaders
+var sortTimestampColumn = slices.Index(TransactionHeaders(), "timestamp")
+
+// TransactionSortConfig configures SortTransactionShards
+type TransactionSortConfig struct {
+	Dir      string // Directory holding the transaction shards (or their partition directories)
+	Compress bool   // Write the sorted shards as .csv.xz
+	Level    int    // xz preset of the sorted shards
+	RunRows  int    // Rows sorted in memory at a time (default 500,000)
+
+	// The shards have the latitude and longitude columns of TransactionGeoHeaders
+	GeoCoordinates bool
+}
+
+// SortTransactionShards rewrites the transaction shards under cfg.Dir so rows
+// are ordered by timestamp, then id, across shards: the first shard holds the
+// earliest rows and each shard continues where the previous one ends. The
+// shard count and file names are kept. Partition directories already cover
+// disjoint months, so each is sorted on its own.
+//
+// This is an external merge sort: runs of RunRows rows are sorted in memory
+// and spilled to uncompressed temporary files beside the shards, then merged.
+// Returns the number of rows sorted.
+func SortTransactionShards(ctx context.Context, cfg TransactionSortConfig) (int64, error) {
+	files, err := FindShardedFiles(cfg.Dir, "transactions")
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, fmt.Errorf("no transaction shards found in %s", cfg.Dir)
+	}
+	runRows := cfg.RunRows
+	if runRows <= 0 {
+		runRows = defaultSortRunRows
+	}
+
+	// FindShardedFiles returns files sorted by path, so each directory's
+	// shards are contiguous
+	var total int64
+	for start := 0; start < len(files); {
+		dir := filepath.Dir(files[start])
+		end := start + 1
+		for end < len(files) && filepath.Dir(files[end]) == dir {
+			end++
+		}
+		rows, err := sortShardDir(ctx, dir, files[start:end], transactionHeaders(cfg.GeoCoordinates), cfg.Compress, cfg.Level, runRows, sortMergeFanIn)
+		if err != nil {
+			return total, err
+		}
+		total += rows
+		start = end
+	}
+	return total, nil
+}
+
+// sortShardDir sorts the shards in one directory, whose columns are headers,
+// replacing them only once the sorted copies are complete
+func sortShardDir(ctx context.Context, dir string, files, headers []string, compress bool, level, runRows, fanIn int) (int64, error) {
+	tmp, err := os.MkdirTemp(dir, ".sort-")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create sort directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	spill := &runSpiller{dir: tmp, limit: runRows}
+	for _, file := range files {
+		if err := ReadCSVRows(ctx, file, headers, spill.add); err != nil {
+			return 0, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	if err := spill.flush(); err != nil {
+		return 0, err
+	}
+
+	// Merge in passes until the remaining runs can be opened at once
+	runs := spill.runs
+	for len(runs) > fanIn {
+		var merged []string
+		for i := 0; i < len(runs); i += fanIn {
+			group := runs[i:min(i+fanIn, len(runs))]
+			path := spill.nextPath()
+			if err := mergeRunsToFile(ctx, group, path); err != nil {
+				return 0, err
+			}
+			for _, run := range group {
+				os.Remove(run)
+			}
+			merged = append(merged, path)
+		}
+		runs = merged
+	}
+
+	shards := max(min(len(files), int(spill.rows)), 1)
+	out := &tableWriter{
+		cfg:    CSVWriterConfig{OutputDir: tmp, Filename: "transactions", Headers: headers, Compress: compress, XZPreset: level},
+		shards: shards,
+		rows:   int(spill.rows),
+	}
+	if err := out.openShard(1); err != nil {
+		return 0, err
+	}
+	if err := mergeRuns(ctx, runs, out.WriteRow); err != nil {
+		out.Close()
+		return 0, err
+	}
+	if err := out.Close(); err != nil {
+		return 0, err
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return 0, fmt.Errorf("failed to remove %s: %w", filepath.Base(file), err)
+		}
+	}
+	for shard := 1; shard <= shards; shard++ {
+		from := ShardFilePath(tmp, "transactions", shard, shards, compress)
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			continue // Fewer rows than shards
+		}
+		if err := os.Rename(from, filepath.Join(dir, filepath.Base(from))); err != nil {
+			return 0, fmt.Errorf("failed to move sorted shard: %w", err)
+		}
+	}
+	return spill.rows, nil
+}
+
+// sortRow is a transaction row with its sort key parsed
+type sortRow struct {
+	ts     string // FormatTime output, which sorts lexically
+	id     int64
+	fields []string
+}
+
+func newSortRow(fields []string) (sortRow, error) {
+	id, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return sortRow{}, fmt.Errorf("invalid id %q", fields[0])
+	}
+	return sortRow{ts: fields[sortTimestampColumn], id: id, fields: fields}, nil
+}
+
+func compareSortRows(a, b sortRow) int {
+	if c := cmp.Compare(a.ts, b.ts); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+// runSpiller collects rows and writes each full batch as a sorted run file
+type runSpiller struct {
+	dir   string
+	limit int
+	buf   []sortRow
+	runs  []string
+	files int
+	rows  int64
+}
+
+func (s *runSpiller) add(row []string) error {
+	r, err := newSortRow(slices.Clone(row))
+	if err != nil {
+		return err
+	}
+	s.buf = append(s.buf, r)
+	s.rows++
+	if len(s.buf) >= s.limit {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *runSpiller) nextPath() string {
+	s.files++
+	return filepath.Join(s.dir, fmt.Sprintf("run_%06d.csv", s.files))
+}
+
+// flush sorts and writes the buffered rows as a new run
+func (s *runSpiller) flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	sort.Slice(s.buf, func(i, j int) bool { return compareSortRows(s.buf[i], s.buf[j]) < 0 })
+
+	path := s.nextPath()
+	w, err := newRunWriter(path)
+	if err != nil {
+		return err
+	}
+	for _, r := range s.buf {
+		if err := w.write(r.fields); err != nil {
+			w.close()
+			return err
+		}
+	}
+	if err := w.close(); err != nil {
+		return err
+	}
+	s.runs = append(s.runs, path)
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// runWriter writes headerless CSV run files
+type runWriter struct {
+	file   *os.File
+	buffer *bufio.Writer
+	csv    *csv.Writer
+}
+
+func newRunWriter(path string) (*runWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sort run: %w", err)
+	}
+	buffer := bufio.NewWriterSize(f, 1024*1024)
+	return &runWriter{file: f, buffer: buffer, csv: csv.NewWriter(buffer)}, nil
+}
+
+func (w *runWriter) write(row []string) error {
+	return w.csv.Write(row)
+}
+
+func (w *runWriter) close() error {
+	w.csv.Flush()
+	err := w.csv.Error()
+	if err == nil {
+		err = w.buffer.Flush()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func mergeRunsToFile(ctx context.Context, runs []string, path string) error {
+	w, err := newRunWriter(path)
+	if err != nil {
+		return err
+	}
+	if err := mergeRuns(ctx, runs, w.write); err != nil {
+		w.close()
+		return err
+	}
+	return w.close()
+}
+
+// runCursor is an open run and its current row
+type runCursor struct {
+	file   *os.File
+	reader *csv.Reader
+	row    sortRow
+}
+
+func (c *runCursor) next() (bool, error) {
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	c.row, err = newSortRow(record)
+	return err == nil, err
+}
+
+// runHeap orders open runs by their current row
+type runHeap []*runCursor
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return compareSortRows(h[i].row, h[j].row) < 0 }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)        { *h = append(*h, x.(*runCursor)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// mergeRuns k-way merges sorted run files, calling emit with each row in order
+func mergeRuns(ctx context.Context, runs []string, emit func(row []string) error) error {
+	h := make(runHeap, 0, len(runs))
+	defer func() {
+		for _, c := range h {
+			c.file.Close()
+		}
+	}()
+
+	for _, run := range runs {
+		f, err := os.Open(run)
+		if err != nil {
+			return fmt.Errorf("failed to open sort run: %w", err)
+		}
+		c := &runCursor{file: f, reader: csv.NewReader(bufio.NewReaderSize(f, 256*1024))}
+		ok, err := c.next()
+		if err != nil || !ok {
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", filepath.Base(run), err)
+			}
+			continue
+		}
+		h = append(h, c)
+	}
+	heap.Init(&h)
+
+	for n := 1; h.Len() > 0; n++ {
+		if n%100000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		c := h[0]
+		if err := emit(c.row.fields); err != nil {
+			return err
+		}
+		ok, err := c.next()
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(c.file.Name()), err)
+		}
+		if ok {
+			heap.Fix(&h, 0)
+		} else {
+			c.file.Close()
+			heap.Pop(&h)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readSortedIDs returns the ids and timestamps of every row across files, in order
+func readSortedIDs(t *testing.T, files []string) (ids, timestamps []string) {
+	t.Helper()
+	for _, file := range files {
+		err := ReadCSVRows(context.Background(), file, []string{"id", "timestamp"}, func(row []string) error {
+			ids = append(ids, row[0])
+			timestamps = append(timestamps, row[1])
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ids, timestamps
+}
+
+func TestSortTransactionShards(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		fanIn int
+	}{
+		{"single merge", sortMergeFanIn},
+		{"merge passes", 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeVerifyFixture(t, dir, "transactions_001.csv", TransactionHeaders(),
+				verifyTxnRow("1", "1", "deposit", "completed", "100", "100", "2024-03-01 09:00:00"),
+				verifyTxnRow("2", "1", "withdrawal", "completed", "10", "90", "2024-01-15 12:00:00"),
+				verifyTxnRow("3", "1", "deposit", "completed", "5", "95", "2024-02-01 08:00:00"),
+			)
+			writeVerifyFixture(t, dir, "transactions_002.csv", TransactionHeaders(),
+				verifyTxnRow("4", "2", "deposit", "completed", "50", "50", "2024-01-01 00:00:00"),
+				verifyTxnRow("6", "2", "deposit", "completed", "50", "100", "2024-02-01 08:00:00"),
+			)
+			writeVerifyFixture(t, dir, "transactions_003.csv", TransactionHeaders(),
+				verifyTxnRow("5", "3", "deposit", "completed", "70", "70", "2024-02-01 08:00:00"),
+			)
+
+			files, err := FindShardedFiles(dir, "transactions")
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows, err := sortShardDir(context.Background(), dir, files, TransactionHeaders(), false, 0, 2, tc.fanIn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rows != 6 {
+				t.Errorf("sorted %d rows, expected 6", rows)
+			}
+
+			files, err = FindShardedFiles(dir, "transactions")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 3 {
+				t.Fatalf("got %d shards, expected the original 3", len(files))
+			}
+			ids, _ := readSortedIDs(t, files)
+			want := []string{"4", "2", "3", "5", "6", "1"}
+			if len(ids) != len(want) {
+				t.Fatalf("got ids %v, expected %v", ids, want)
+			}
+			for i := range want {
+				if ids[i] != want[i] {
+					t.Fatalf("got ids %v, expected %v", ids, want)
+				}
+			}
+
+			// Temporary runs are cleaned up
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 3 {
+				t.Errorf("got %d entries in the output directory, expected only the shards", len(entries))
+			}
+		})
+	}
+}
+
+func TestSortTransactionShardsPerPartition(t *testing.T) {
+	dir := t.TempDir()
+	for _, month := range []string{"2024-01", "2024-02"} {
+		if err := os.MkdirAll(filepath.Join(dir, "transactions", month), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeVerifyFixture(t, filepath.Join(dir, "transactions", "2024-01"), "transactions_001.csv", TransactionHeaders(),
+		verifyTxnRow("2", "1", "deposit", "completed", "10", "10", "2024-01-20 00:00:00"),
+		verifyTxnRow("1", "1", "deposit", "completed", "10", "20", "2024-01-10 00:00:00"),
+	)
+	writeVerifyFixture(t, filepath.Join(dir, "transactions", "2024-02"), "transactions_001.csv", TransactionHeaders(),
+		verifyTxnRow("4", "1", "deposit", "completed", "10", "30", "2024-02-20 00:00:00"),
+		verifyTxnRow("3", "1", "deposit", "completed", "10", "40", "2024-02-10 00:00:00"),
+	)
+
+	rows, err := SortTransactionShards(context.Background(), TransactionSortConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 4 {
+		t.Errorf("sorted %d rows, expected 4", rows)
+	}
+	files, err := FindShardedFiles(dir, "transactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, timestamps := readSortedIDs(t, files)
+	for i := 1; i < len(timestamps); i++ {
+		if timestamps[i] < timestamps[i-1] {
+			t.Fatalf("timestamps out of order: %v", timestamps)
+		}
+	}
+}
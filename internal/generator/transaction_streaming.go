@@ -1,59 +1,40 @@
 package generator
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/willfong/load-generator/internal/data"
-	"github.com/willfong/load-generator/internal/generator/patterns"
 	"github.com/willfong/load-generator/internal/models"
 	"github.com/willfong/load-generator/internal/utils"
 )
 
 // StreamingTransactionGenerator generates transactions and writes them directly
-// to a CSV file, minimizing memory usage for large datasets.
+// to a CSV file (and/or a Kafka topic), minimizing memory usage for large datasets.
+// Generation logic lives in the embedded transactionCore.
 type StreamingTransactionGenerator struct {
-	rng     *utils.Random
-	refData *data.ReferenceData
-	config  StreamingTransactionConfig
-
-	// Patterns for realistic distribution
-	retailPattern   *patterns.FullPattern
-	atmPattern      *patterns.FullPattern
-	onlinePattern   *patterns.FullPattern
-	businessPattern *patterns.FullPattern
-
-	// Activity distribution
-	activityDist *patterns.ActivityDistribution
-
-	// Amount distributions
-	amounts *patterns.TransactionTypeAmounts
-
-	// Reference data
-	branches []GeneratedBranch
-	atms     []GeneratedATM
-
-	// Account lookups for counterparty transactions
-	accountsByID map[int64]GeneratedAccount
-
-	// Merchant account IDs for purchase destinations
-	merchantAccountIDs []int64
-	// Employer account IDs for salary sources
-	employerAccountIDs []int64
-	// Utility account IDs for bill payments
-	utilityAccountIDs []int64
-
-	// Streaming output
-	writer   *CSVWriter
-	workerID int
+	transactionCore
+	config StreamingTransactionConfig
+
+	// Streaming output (writer is nil when publishing to Kafka only or
+	// partitioning by month, which writes through partitions instead)
+	writer     *CSVWriter
+	partitions *monthPartitionWriter
+	avro       *AvroWriter // Optional Avro shard (nil unless Avro is set)
+	producer   *KafkaProducer
+	audit      *StreamingAuditGenerator // Transaction audit events (nil with DeferAudit)
+	transfers  *CSVWriter               // Optional transfers shard (nil unless Transfers is set)
+	disputes   *CSVWriter               // Optional disputes shard (nil unless DisputeRate is set)
+	workerID   int
 
 	// Progress reporting
-	progressChan chan<- workerProgress
-	count        int64
-
-	// ID tracking
-	currentID int64
-	endID     int64
+	progressChan  chan<- workerProgress
+	count         int64
+	transferCount int64
+	disputeCount  int64
+	lastID        int64 // Highest transaction ID written
 }
 
 // StreamingTransactionConfig holds settings for streaming transaction generation
@@ -71,25 +52,119 @@ type StreamingTransactionConfig struct {
 	// Day of month for payroll processing (1-31)
 	PayrollDay int
 
+	// Period length transactions are generated in (empty = monthly)
+	Granularity Granularity
+
 	// Error injection rates (0.0-1.0)
 	DeclinedTransactionRate float64
 	InsufficientFundsRate   float64
 
+	// Fraction of declined purchases retried successfully moments later (0 = none)
+	DeclineRetryRate float64
+
+	// Credit card cashback as a fraction of the prior month's purchases (0 = disabled)
+	CashbackRate float64
+
+	// Fraction of completed debits later reversed (0 = none)
+	ReversalRate float64
+
+	// Fee in cents for each debit that overdraws a checking account (0 = none),
+	// charged at most OverdraftFeeDailyCap times per account per day (0 = no cap)
+	OverdraftFee         int64
+	OverdraftFeeDailyCap int
+
+	// Off-hours suppression per account type (nil = none)
+	BusinessHours map[models.AccountType]BusinessHours
+
+	// Transaction type mix per account type (nil = DefaultTransactionTypeWeights)
+	TransactionMix map[models.AccountType][]TransactionTypeWeight
+
+	// Fee types charged by fee transactions (nil = DefaultFeeSchedule)
+	FeeSchedule FeeSchedule
+
+	// Wire beneficiaries per customer, and the fraction of outgoing wires
+	// paying one of them rather than a domestic bank
+	WireBeneficiaries     map[int64][]WireBeneficiary
+	InternationalWireRate float64
+
+	// Write where each transaction was made in latitude and longitude columns
+	GeoCoordinates bool
+
+	// Fraction of card and online transactions located far from the customer's home
+	FarFromHomeRate float64
+
+	// Keep branch transactions within the branches' operating hours
+	BranchHours bool
+
+	// Weekend volume relative to weekdays (nil = weekends weighted as weekdays)
+	WeekendVolume *WeekendVolume
+
+	// Accounts whose monthly volume is scaled by WhaleMultiplier (nil = none)
+	WhaleAccounts   []int64
+	WhaleMultiplier float64
+
+	// Most transactions per account per month, by account type (nil = no caps)
+	MonthlyCaps MonthlyCaps
+
+	// Store amounts in each currency's own minor units (false = hundredths for all)
+	MinorUnits bool
+
+	// Months over which a new account ramps up to its full activity (0 = none)
+	RampUpMonths int
+
+	// Day of the month interest is posted on (0 = drawn from the transaction mix)
+	InterestPostingDay int
+
+	// Fraction of completed purchases later disputed by the customer (0 = none)
+	DisputeRate float64
+
+	// Business days each channel takes to post and take value (nil = immediately)
+	SettlementLags SettlementLags
+
+	// Note each currency's ATM withdrawals are multiples of (nil = any amount)
+	ATMDenominations ATMDenominations
+
+	// Payees retail accounts transfer with besides their own accounts (zero value = none)
+	TransferGraph TransferGraph
+
 	// Reference data
-	Branches   []GeneratedBranch
-	ATMs       []GeneratedATM
-	AllAccounts []GeneratedAccount // All accounts for counterparty lookups
-	Businesses []GeneratedBusiness
+	Branches []GeneratedBranch
+	ATMs     []GeneratedATM
+	Accounts *AccountIndex // All accounts for counterparty lookups, shared read-only by every worker
 
-	// Worker configuration
+	// Worker configuration (transaction IDs come from the generated partitions)
 	WorkerID    int
 	WorkerCount int
-	StartID     int64
-	EndID       int64
+
+	// Audit IDs for the initiated/outcome events are derived from transaction IDs above this base
+	AuditIDBase int64
+
+	// Fraction of online and ATM transactions made inside a login session (0 = none)
+	SessionTransactionRate float64
+
+	// Audit actions written to the transaction audit shards (nil = all)
+	AuditActions AuditActionFilter
+
+	// Leave the transaction audit events to a later pass over the written
+	// shards (see Orchestrator.TransactionAuditFromShards)
+	DeferAudit bool
 
 	// Output configuration
-	OutputDir string
-	Compress  bool
+	OutputDir         string
+	AuditOutputDir    string // Directory for the transaction audit shards (default OutputDir)
+	Transfers         bool   // Also write a transfers shard, one row per internal transfer
+	TransferOutputDir string // Directory for the transfers shards (default OutputDir)
+	DisputeOutputDir  string // Directory for the disputes shards (default OutputDir)
+	Compress          bool
+	CompressLevel     int           // xz preset and Avro deflate level with Compress
+	Kafka             *KafkaConfig  // Optional Kafka sink (nil = CSV only)
+	Limiter           *WriteLimiter // Optional write rate cap (nil = unlimited)
+	Avro              bool          // Also write Avro OCF shards (transactions and their audit events)
+	PartitionBy       Partitioning  // Split transaction shards into month directories under OutputDir
+	MaxFileRows       int64         // Roll each shard over into part files of this many rows (0 = unlimited)
+	ExpectedRows      int64         // Transactions this worker is expected to write, at most, sizing S3 uploads (0 = unknown)
+	BufferSize        int           // Write buffer per shard in bytes (0 = 1MB)
+	FlushInterval     time.Duration // Flush shards at least this often (0 = only when the buffer fills)
 
 	// Progress channel
 	ProgressChan chan<- workerProgress
@@ -105,280 +180,338 @@ func TransactionHeaders() []string {
 	}
 }
 
-// NewStreamingTransactionGenerator creates a new streaming transaction generator
-func NewStreamingTransactionGenerator(rng *utils.Random, refData *data.ReferenceData, config StreamingTransactionConfig) (*StreamingTransactionGenerator, error) {
-	// Create shard writer
-	writer, err := NewShardedCSVWriter(CSVWriterConfig{
-		OutputDir: config.OutputDir,
-		Filename:  "transactions",
-		Headers:   TransactionHeaders(),
-		Compress:  config.Compress,
-	}, config.WorkerID+1, config.WorkerCount) // 1-indexed shard numbers
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create shard writer: %w", err)
-	}
+// TransactionGeoHeaders returns the CSV headers for transactions generated
+// with GeoCoordinates: TransactionHeaders followed by latitude and longitude
+func TransactionGeoHeaders() []string {
+	return append(TransactionHeaders(), "latitude", "longitude")
+}
 
-	// Build account lookup map
-	accountsByID := make(map[int64]GeneratedAccount)
-	for _, acc := range config.AllAccounts {
-		accountsByID[acc.Account.ID] = acc
+// transactionHeaders returns TransactionGeoHeaders if geo is set, else TransactionHeaders
+func transactionHeaders(geo bool) []string {
+	if geo {
+		return TransactionGeoHeaders()
 	}
+	return TransactionHeaders()
+}
 
-	stg := &StreamingTransactionGenerator{
-		rng:     rng,
-		refData: refData,
-		config:  config,
-
-		retailPattern:   patterns.NewDefaultFullPattern(),
-		atmPattern:      patterns.NewATMFullPattern(),
-		onlinePattern:   patterns.NewOnlineFullPattern(),
-		businessPattern: patterns.NewBusinessFullPattern(),
-
-		activityDist: patterns.NewParetoDistribution(config.ParetoRatio),
-		amounts:      patterns.NewTransactionTypeAmounts(),
-
-		branches:     config.Branches,
-		atms:         config.ATMs,
-		accountsByID: accountsByID,
+// NewStreamingTransactionGenerator creates a new streaming transaction
+// generator. rng seeds the worker's audit writer; transactions draw from the
+// partitions passed to GenerateAndStream.
+func NewStreamingTransactionGenerator(rng *utils.Random, refData *data.ReferenceData, config StreamingTransactionConfig) (*StreamingTransactionGenerator, error) {
+	core := newTransactionCore(refData, transactionSettings{
+		StartDate:                       config.StartDate,
+		EndDate:                         config.EndDate,
+		TransactionsPerCustomerPerMonth: config.TransactionsPerCustomerPerMonth,
+		ParetoRatio:                     config.ParetoRatio,
+		Granularity:                     config.Granularity,
+		DeclinedTransactionRate:         config.DeclinedTransactionRate,
+		DeclineRetryRate:                config.DeclineRetryRate,
+		InsufficientFundsRate:           config.InsufficientFundsRate,
+		CashbackRate:                    config.CashbackRate,
+		ReversalRate:                    config.ReversalRate,
+		OverdraftFee:                    config.OverdraftFee,
+		OverdraftFeeDailyCap:            config.OverdraftFeeDailyCap,
+		BusinessHours:                   config.BusinessHours,
+		TransactionMix:                  config.TransactionMix,
+		FeeSchedule:                     config.FeeSchedule,
+		WireBeneficiaries:               config.WireBeneficiaries,
+		InternationalWireRate:           config.InternationalWireRate,
+		FarFromHomeRate:                 config.FarFromHomeRate,
+		GeoCoordinates:                  config.GeoCoordinates,
+		BranchHours:                     config.BranchHours,
+		WeekendVolume:                   config.WeekendVolume,
+		WhaleAccounts:                   config.WhaleAccounts,
+		WhaleMultiplier:                 config.WhaleMultiplier,
+		MonthlyCaps:                     config.MonthlyCaps,
+		MinorUnits:                      config.MinorUnits,
+		RampUpMonths:                    config.RampUpMonths,
+		InterestPostingDay:              config.InterestPostingDay,
+		DisputeRate:                     config.DisputeRate,
+		SettlementLags:                  config.SettlementLags,
+		ATMDenominations:                config.ATMDenominations,
+		TransferGraph:                   config.TransferGraph,
+		Branches:                        config.Branches,
+		ATMs:                            config.ATMs,
+		Accounts:                        config.Accounts,
+	})
 
-		writer:       writer,
-		workerID:     config.WorkerID,
-		progressChan: config.ProgressChan,
-		currentID:    config.StartID,
-		endID:        config.EndID,
+	// Create shard writer
+	var writer *CSVWriter
+	var partitions *monthPartitionWriter
+	if config.Kafka == nil || !config.Kafka.Only {
+		cfg := CSVWriterConfig{
+			OutputDir:     config.OutputDir,
+			Filename:      "transactions",
+			Headers:       transactionHeaders(config.GeoCoordinates),
+			Compress:      config.Compress,
+			XZPreset:      config.CompressLevel,
+			Limiter:       config.Limiter,
+			MaxRows:       config.MaxFileRows,
+			ExpectedRows:  config.ExpectedRows,
+			BufferSize:    streamingBuffer(config.BufferSize),
+			FlushInterval: config.FlushInterval,
+		}
+		if config.PartitionBy == PartitionByMonth {
+			// Partition files are created as their first row arrives
+			partitions = newMonthPartitionWriter(cfg, config.WorkerID+1, config.WorkerCount)
+		} else {
+			var err error
+			writer, err = NewShardedCSVWriter(cfg, config.WorkerID+1, config.WorkerCount) // 1-indexed shard numbers
+			if err != nil {
+				return nil, fmt.Errorf("failed to create shard writer: %w", err)
+			}
+		}
 	}
 
-	// Categorize business accounts by type
-	for _, acc := range config.AllAccounts {
-		switch acc.Account.Type {
-		case models.AccountTypeMerchant:
-			stg.merchantAccountIDs = append(stg.merchantAccountIDs, acc.Account.ID)
-		case models.AccountTypePayroll:
-			stg.employerAccountIDs = append(stg.employerAccountIDs, acc.Account.ID)
+	auditDir := config.AuditOutputDir
+	if auditDir == "" {
+		auditDir = config.OutputDir
+	}
+
+	// Transaction audit events go to their own shard set
+	var audit *StreamingAuditGenerator
+	if !config.DeferAudit {
+		var err error
+		audit, err = NewStreamingAuditGenerator(rng.Fork(), refData, StreamingAuditConfig{
+			ATMs:          config.ATMs,
+			WorkerID:      config.WorkerID,
+			WorkerCount:   config.WorkerCount,
+			OutputDir:     auditDir,
+			Compress:      config.Compress,
+			CompressLevel: config.CompressLevel,
+			Limiter:       config.Limiter,
+			Avro:          config.Avro,
+			Filename:      TransactionAuditBasename,
+			MaxFileRows:   config.MaxFileRows,
+			ExpectedRows:  2 * config.ExpectedRows, // Initiated and completed events
+			BufferSize:    config.BufferSize,
+			FlushInterval: config.FlushInterval,
+
+			TransactionAuditIDBase: config.AuditIDBase,
+			SessionTransactionRate: config.SessionTransactionRate,
+			Actions:                config.AuditActions,
+		})
+		if err != nil {
+			if writer != nil {
+				writer.Close()
+			}
+			return nil, fmt.Errorf("failed to create transaction audit writer: %w", err)
 		}
 	}
 
-	// Add utility company accounts from businesses
-	for _, biz := range config.Businesses {
-		if biz.BusinessType == BusinessTypeUtility {
-			for _, acc := range config.AllAccounts {
-				if acc.Account.CustomerID == biz.Customer.ID {
-					stg.utilityAccountIDs = append(stg.utilityAccountIDs, acc.Account.ID)
-					break
-				}
+	var avro *AvroWriter
+	var err error
+	if config.Avro {
+		avro, err = NewAvroWriter(AvroWriterConfig{
+			OutputDir: config.OutputDir,
+			Filename:  ShardFilename("transactions", config.WorkerID+1, config.WorkerCount),
+			Record:    transactionAvro,
+			Deflate:   config.Compress,
+			Level:     config.CompressLevel,
+
+			ExpectedRows: config.ExpectedRows,
+		})
+		if err != nil {
+			if writer != nil {
+				writer.Close()
 			}
+			if audit != nil {
+				audit.Close()
+			}
+			return nil, fmt.Errorf("failed to create avro writer: %w", err)
 		}
 	}
 
-	return stg, nil
-}
-
-// GenerateAndStream generates transactions for the assigned accounts and streams them to CSV.
-// Returns the number of transactions generated.
-func (g *StreamingTransactionGenerator) GenerateAndStream(accounts []GeneratedAccount) (int64, error) {
-	defer g.writer.Close()
-
-	// Group accounts by customer for coordinated generation
-	customerAccounts := make(map[int64][]GeneratedAccount)
-	for _, acc := range accounts {
-		customerAccounts[acc.Account.CustomerID] = append(customerAccounts[acc.Account.CustomerID], acc)
+	// Each worker runs its own producer
+	var producer *KafkaProducer
+	if config.Kafka != nil {
+		producer, err = NewKafkaProducer(*config.Kafka)
+		if err != nil {
+			if writer != nil {
+				writer.Close()
+			}
+			if avro != nil {
+				avro.Close()
+			}
+			if audit != nil {
+				audit.Close()
+			}
+			return nil, fmt.Errorf("failed to create kafka producer: %w", err)
+		}
 	}
 
-	// Track running balances for accounts in this worker
-	balances := make(map[int64]int64)
-	for _, acc := range accounts {
-		balances[acc.Account.ID] = acc.Account.Balance
+	var transfers *CSVWriter
+	if config.Transfers {
+		transferDir := config.TransferOutputDir
+		if transferDir == "" {
+			transferDir = config.OutputDir
+		}
+		transfers, err = NewShardedCSVWriter(CSVWriterConfig{
+			OutputDir:     transferDir,
+			Filename:      "transfers",
+			Headers:       TransferHeaders(),
+			Compress:      config.Compress,
+			XZPreset:      config.CompressLevel,
+			Limiter:       config.Limiter,
+			MaxRows:       config.MaxFileRows,
+			ExpectedRows:  config.ExpectedRows,
+			BufferSize:    streamingBuffer(config.BufferSize),
+			FlushInterval: config.FlushInterval,
+		}, config.WorkerID+1, config.WorkerCount)
+		if err != nil {
+			if writer != nil {
+				writer.Close()
+			}
+			if avro != nil {
+				avro.Close()
+			}
+			if producer != nil {
+				producer.Close()
+			}
+			if audit != nil {
+				audit.Close()
+			}
+			return nil, fmt.Errorf("failed to create transfers writer: %w", err)
+		}
 	}
 
-	// Generate month by month
-	currentMonth := g.config.StartDate
-	for currentMonth.Before(g.config.EndDate) {
-		monthEnd := currentMonth.AddDate(0, 1, 0)
-		if monthEnd.After(g.config.EndDate) {
-			monthEnd = g.config.EndDate
+	var disputes *CSVWriter
+	if config.DisputeRate > 0 {
+		disputeDir := config.DisputeOutputDir
+		if disputeDir == "" {
+			disputeDir = config.OutputDir
 		}
-
-		if err := g.generateMonthTransactions(accounts, customerAccounts, balances, currentMonth, monthEnd); err != nil {
-			return g.count, err
+		disputes, err = NewShardedCSVWriter(CSVWriterConfig{
+			OutputDir:     disputeDir,
+			Filename:      "disputes",
+			Headers:       DisputeHeaders(),
+			Compress:      config.Compress,
+			XZPreset:      config.CompressLevel,
+			Limiter:       config.Limiter,
+			MaxRows:       config.MaxFileRows,
+			ExpectedRows:  config.ExpectedRows,
+			BufferSize:    streamingBuffer(config.BufferSize),
+			FlushInterval: config.FlushInterval,
+		}, config.WorkerID+1, config.WorkerCount)
+		if err != nil {
+			if writer != nil {
+				writer.Close()
+			}
+			if avro != nil {
+				avro.Close()
+			}
+			if producer != nil {
+				producer.Close()
+			}
+			if transfers != nil {
+				transfers.Close()
+			}
+			if audit != nil {
+				audit.Close()
+			}
+			return nil, fmt.Errorf("failed to create disputes writer: %w", err)
 		}
-
-		currentMonth = currentMonth.AddDate(0, 1, 0)
 	}
 
-	return g.count, nil
-}
+	stg := &StreamingTransactionGenerator{
+		transactionCore: core,
+		config:          config,
 
-// generateMonthTransactions generates and streams transactions for a single month
-func (g *StreamingTransactionGenerator) generateMonthTransactions(
-	accounts []GeneratedAccount,
-	customerAccounts map[int64][]GeneratedAccount,
-	balances map[int64]int64,
-	monthStart, monthEnd time.Time,
-) error {
-	for _, account := range accounts {
-		// Skip closed accounts or accounts opened after this month
-		if account.Account.OpenedAt.After(monthEnd) {
-			continue
+		writer:       writer,
+		partitions:   partitions,
+		avro:         avro,
+		producer:     producer,
+		audit:        audit,
+		transfers:    transfers,
+		disputes:     disputes,
+		workerID:     config.WorkerID,
+		progressChan: config.ProgressChan,
+	}
+	stg.emit = func(txn models.Transaction, _ GeneratedAccount) error {
+		return stg.writeTransaction(txn)
+	}
+	if transfers != nil {
+		stg.emitTransfer = func(t models.Transfer) error {
+			stg.transferCount++
+			return transfers.WriteRow(transferRow(t))
 		}
-
-		// Determine transaction count based on activity score and account type
-		txnCount := g.calculateMonthlyTransactionCount(account)
-
-		// Generate and write transactions for this account this month
-		if err := g.generateAccountMonthTransactions(
-			account, customerAccounts, balances, monthStart, monthEnd, txnCount,
-		); err != nil {
-			return err
+	}
+	if disputes != nil {
+		stg.emitDispute = func(d models.Dispute) error {
+			stg.disputeCount++
+			return disputes.WriteRow(disputeRow(d))
 		}
 	}
 
-	return nil
+	return stg, nil
 }
 
-// calculateMonthlyTransactionCount determines how many transactions an account should have
-func (g *StreamingTransactionGenerator) calculateMonthlyTransactionCount(account GeneratedAccount) int {
-	baseCount := g.config.TransactionsPerCustomerPerMonth
-
-	// Adjust by activity score (from Pareto distribution)
-	activityScore := account.Customer.Customer.ActivityScore
-	adjustedCount := g.activityDist.TransactionsPerMonth(activityScore, baseCount)
-
-	// Adjust by account type
-	switch account.Account.Type {
-	case models.AccountTypeChecking:
-		adjustedCount = int(float64(adjustedCount) * 1.2)
-	case models.AccountTypeSavings:
-		adjustedCount = int(float64(adjustedCount) * 0.3)
-	case models.AccountTypeCreditCard:
-		adjustedCount = int(float64(adjustedCount) * 1.5)
-	case models.AccountTypeBusiness:
-		adjustedCount = int(float64(adjustedCount) * 2.0)
-	case models.AccountTypeMerchant:
-		adjustedCount = int(float64(adjustedCount) * 5.0)
-	case models.AccountTypePayroll:
-		adjustedCount = int(float64(adjustedCount) * 0.5)
-	}
+// GenerateAndStream generates transactions for the accounts of the assigned
+// partitions and streams them to CSV. Returns the number of transactions
+// generated. The context is checked between months and accounts; on
+// cancellation the shard writer is closed and ctx.Err() returned.
+func (g *StreamingTransactionGenerator) GenerateAndStream(ctx context.Context, partitions []GenerationPartition) (count int64, err error) {
+	defer func() {
+		if closeErr := g.close(); err == nil {
+			err = closeErr
+		}
+	}()
 
-	if adjustedCount < 1 {
-		adjustedCount = 1
+	parts := make([]*transactionPartition, len(partitions))
+	for i, p := range partitions {
+		// Transactions and their audit events draw from separate forks
+		parts[i] = newTransactionPartition(p.RNG.Fork(), p.Accounts, p.FirstID, p.IDStride)
+		parts[i].auditRNG = p.RNG.Fork()
 	}
 
-	variance := g.rng.IntRange(-adjustedCount/4, adjustedCount/4)
-	return adjustedCount + variance
+	if err := g.generateHistory(ctx, parts); err != nil {
+		return g.count, err
+	}
+	return g.count, nil
 }
 
-// generateAccountMonthTransactions generates and writes transactions for one account in one month
-func (g *StreamingTransactionGenerator) generateAccountMonthTransactions(
-	account GeneratedAccount,
-	customerAccounts map[int64][]GeneratedAccount,
-	balances map[int64]int64,
-	monthStart, monthEnd time.Time,
-	targetCount int,
-) error {
-	pattern := g.selectPattern(account)
-	timestamps := g.generateTimestamps(monthStart, monthEnd, targetCount, pattern, account)
-
-	for _, ts := range timestamps {
-		txnType, channel := g.selectTransactionType(account, ts)
-		amount := g.generateAmount(txnType, account)
-
-		status := models.TxStatusCompleted
-		var failureReason *string
-		if g.shouldDecline(txnType, balances[account.Account.ID], amount) {
-			status = models.TxStatusDeclined
-			reason := "insufficient_funds"
-			failureReason = &reason
-			amount = 0
-		}
-
-		var counterpartyID *int64
-		var beneficiaryID *int64
-		counterpartyID, beneficiaryID = g.selectCounterparty(txnType, account, customerAccounts)
-
-		balanceAfter := balances[account.Account.ID]
-		if status == models.TxStatusCompleted && amount > 0 {
-			if isDebitType(txnType) {
-				balanceAfter -= amount
-			} else {
-				balanceAfter += amount
-			}
-			balances[account.Account.ID] = balanceAfter
-		}
+// writeTransaction formats and writes a transaction to CSV
+func (g *StreamingTransactionGenerator) writeTransaction(t models.Transaction) error {
+	row := transactionRow(t, g.settings.GeoCoordinates)
 
-		description := g.generateDescription(txnType, channel, account)
-		branchID, atmID := g.selectLocation(channel, account)
-
-		txn := models.Transaction{
-			ID:                    g.currentID,
-			ReferenceNumber:       g.generateReferenceNumber(g.currentID, ts),
-			AccountID:             account.Account.ID,
-			CounterpartyAccountID: counterpartyID,
-			BeneficiaryID:         beneficiaryID,
-			Type:                  txnType,
-			Status:                status,
-			Channel:               channel,
-			Amount:                amount,
-			Currency:              account.Account.Currency,
-			BalanceAfter:          balanceAfter,
-			Description:           description,
-			Metadata:              "{}",
-			BranchID:              branchID,
-			ATMID:                 atmID,
-			Timestamp:             ts,
-			PostedAt:              ts.Add(time.Duration(g.rng.IntRange(0, 60)) * time.Second),
-			ValueDate:             ts,
-			FailureReason:         failureReason,
+	if g.writer != nil {
+		if err := g.writer.WriteRow(row); err != nil {
+			return err
 		}
-
-		g.currentID++
-
-		// Write transaction immediately
-		if err := g.writeTransaction(txn); err != nil {
+	}
+	if g.partitions != nil {
+		if err := g.partitions.WriteRow(monthPartition(t.Timestamp), row); err != nil {
 			return err
 		}
+	}
 
-		// Generate counterparty transaction for internal transfers
-		if counterpartyID != nil && status == models.TxStatusCompleted {
-			if err := g.generateAndWriteCounterpartyTransaction(txn, *counterpartyID, balances); err != nil {
-				return err
-			}
+	if g.avro != nil {
+		if err := g.avro.Write(t); err != nil {
+			return err
 		}
 	}
 
-	return nil
-}
-
-// writeTransaction formats and writes a transaction to CSV
-func (g *StreamingTransactionGenerator) writeTransaction(t models.Transaction) error {
-	row := []string{
-		FormatInt64(t.ID),
-		t.ReferenceNumber,
-		FormatInt64(t.AccountID),
-		FormatInt64Ptr(t.CounterpartyAccountID),
-		FormatInt64Ptr(t.BeneficiaryID),
-		string(t.Type),
-		string(t.Status),
-		string(t.Channel),
-		FormatInt64(t.Amount),
-		string(t.Currency),
-		FormatInt64(t.BalanceAfter),
-		t.Description,
-		t.Metadata,
-		FormatInt64Ptr(t.BranchID),
-		FormatInt64Ptr(t.ATMID),
-		FormatInt64Ptr(t.LinkedTransactionID),
-		FormatTime(t.Timestamp),
-		FormatTime(t.PostedAt),
-		FormatDate(t.ValueDate),
-		formatStringPtr(t.FailureReason),
+	if g.producer != nil {
+		msg, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Errorf("failed to encode transaction %d: %w", t.ID, err)
+		}
+		// Keyed by account so each account's transactions stay in order on one partition
+		if err := g.producer.Publish(t.AccountID, msg); err != nil {
+			return err
+		}
 	}
 
-	if err := g.writer.WriteRow(row); err != nil {
-		return err
+	// Initiated/outcome audit events, as the batch AuditGenerator produces
+	if acc, ok := g.accounts.byID[t.AccountID]; ok && g.audit != nil {
+		g.audit.rng = g.partition.auditRNG
+		if err := g.audit.WriteTransactionAuditLogs(t, acc.Customer); err != nil {
+			return err
+		}
 	}
 
 	g.count++
+	g.lastID = max(g.lastID, t.ID)
 
 	// Report progress every 1000 transactions
 	if g.progressChan != nil && g.count%1000 == 0 {
@@ -392,416 +525,82 @@ func (g *StreamingTransactionGenerator) writeTransaction(t models.Transaction) e
 	return nil
 }
 
-// generateAndWriteCounterpartyTransaction creates and writes the other side of a transfer
-func (g *StreamingTransactionGenerator) generateAndWriteCounterpartyTransaction(
-	original models.Transaction,
-	counterpartyID int64,
-	balances map[int64]int64,
-) error {
-	var counterType models.TransactionType
-	if isDebitType(original.Type) {
-		counterType = models.TxTypeTransferIn
-	} else {
-		counterType = models.TxTypeTransferOut
+// close flushes the shard writers and the Kafka producer, if any
+func (g *StreamingTransactionGenerator) close() error {
+	var err error
+	if g.writer != nil {
+		err = g.writer.Close()
 	}
-
-	// Update counterparty balance (only if we track it in this worker)
-	balanceAfter := balances[counterpartyID]
-	if _, exists := balances[counterpartyID]; exists {
-		if isDebitType(counterType) {
-			balanceAfter -= original.Amount
-		} else {
-			balanceAfter += original.Amount
+	if g.partitions != nil {
+		if partitionErr := g.partitions.Close(); err == nil {
+			err = partitionErr
 		}
-		balances[counterpartyID] = balanceAfter
-	}
-
-	linkedID := original.ID
-	counterTxn := models.Transaction{
-		ID:                    g.currentID,
-		ReferenceNumber:       original.ReferenceNumber,
-		AccountID:             counterpartyID,
-		CounterpartyAccountID: &original.AccountID,
-		Type:                  counterType,
-		Status:                original.Status,
-		Channel:               original.Channel,
-		Amount:                original.Amount,
-		Currency:              original.Currency,
-		BalanceAfter:          balanceAfter,
-		Description:           "Transfer from " + original.ReferenceNumber,
-		Metadata:              "{}",
-		LinkedTransactionID:   &linkedID,
-		Timestamp:             original.Timestamp,
-		PostedAt:              original.PostedAt,
-		ValueDate:             original.ValueDate,
-	}
-	g.currentID++
-
-	return g.writeTransaction(counterTxn)
-}
-
-// selectPattern chooses the appropriate time pattern for an account
-func (g *StreamingTransactionGenerator) selectPattern(account GeneratedAccount) *patterns.FullPattern {
-	if account.Customer.Customer.IsBusinessCustomer() {
-		return g.businessPattern
-	}
-
-	switch account.Account.Type {
-	case models.AccountTypeMerchant, models.AccountTypeBusiness, models.AccountTypePayroll:
-		return g.businessPattern
-	default:
-		return g.retailPattern
 	}
-}
-
-// generateTimestamps creates realistic timestamps distributed across a month
-func (g *StreamingTransactionGenerator) generateTimestamps(
-	start, end time.Time,
-	count int,
-	pattern *patterns.FullPattern,
-	account GeneratedAccount,
-) []time.Time {
-	timestamps := make([]time.Time, 0, count)
-	duration := end.Sub(start)
-
-	for i := 0; i < count; i++ {
-		offset := time.Duration(g.rng.Float64() * float64(duration))
-		ts := start.Add(offset)
-
-		hour, minute := patterns.NewDailyPattern().TimeInActiveWindow(g.rng.Float64())
-		ts = time.Date(ts.Year(), ts.Month(), ts.Day(), hour, minute, g.rng.IntRange(0, 59), 0, time.UTC)
-
-		if tz, err := time.LoadLocation(account.Customer.Customer.Timezone); err == nil {
-			ts = ts.In(tz)
+	if g.avro != nil {
+		if avroErr := g.avro.Close(); err == nil {
+			err = avroErr
 		}
-
-		if g.rng.Float64() < pattern.GetMultiplier(ts) {
-			timestamps = append(timestamps, ts)
-		} else {
-			i--
-		}
-	}
-
-	return timestamps
-}
-
-// selectTransactionType chooses an appropriate transaction type for the account
-func (g *StreamingTransactionGenerator) selectTransactionType(account GeneratedAccount, ts time.Time) (models.TransactionType, models.TransactionChannel) {
-	monthlyPattern := patterns.NewMonthlyPattern()
-	if monthlyPattern.IsPayrollDay(ts.Day()) && account.Account.Type == models.AccountTypePayroll {
-		return models.TxTypePayrollBatch, models.ChannelInternal
 	}
-
-	switch account.Account.Type {
-	case models.AccountTypeChecking:
-		return g.selectCheckingTransactionType(ts)
-	case models.AccountTypeSavings:
-		return g.selectSavingsTransactionType()
-	case models.AccountTypeCreditCard:
-		return g.selectCreditCardTransactionType()
-	case models.AccountTypeBusiness:
-		return g.selectBusinessTransactionType(ts)
-	case models.AccountTypeMerchant:
-		return models.TxTypeDeposit, models.ChannelPOS
-	case models.AccountTypePayroll:
-		return g.selectPayrollTransactionType(ts)
-	default:
-		return models.TxTypeDeposit, models.ChannelOnline
-	}
-}
-
-func (g *StreamingTransactionGenerator) selectCheckingTransactionType(ts time.Time) (models.TransactionType, models.TransactionChannel) {
-	r := g.rng.Float64()
-	monthlyPattern := patterns.NewMonthlyPattern()
-
-	if monthlyPattern.IsPayrollDay(ts.Day()) && r < 0.15 {
-		return models.TxTypeSalary, models.ChannelACH
-	}
-	if monthlyPattern.IsStartOfMonth(ts) && r < 0.25 {
-		return models.TxTypeBillPayment, models.ChannelOnline
-	}
-
-	switch {
-	case r < 0.20:
-		return models.TxTypeWithdrawal, models.ChannelATM
-	case r < 0.35:
-		return models.TxTypePurchase, models.ChannelPOS
-	case r < 0.50:
-		return models.TxTypeTransferOut, models.ChannelOnline
-	case r < 0.60:
-		return models.TxTypeBillPayment, models.ChannelOnline
-	case r < 0.75:
-		return models.TxTypeDeposit, models.ChannelBranch
-	case r < 0.85:
-		return models.TxTypeTransferIn, models.ChannelOnline
-	case r < 0.95:
-		return models.TxTypeSalary, models.ChannelACH
-	default:
-		return models.TxTypeFee, models.ChannelInternal
-	}
-}
-
-func (g *StreamingTransactionGenerator) selectSavingsTransactionType() (models.TransactionType, models.TransactionChannel) {
-	r := g.rng.Float64()
-	switch {
-	case r < 0.40:
-		return models.TxTypeTransferIn, models.ChannelOnline
-	case r < 0.70:
-		return models.TxTypeTransferOut, models.ChannelOnline
-	case r < 0.85:
-		return models.TxTypeInterestCredit, models.ChannelInternal
-	case r < 0.95:
-		return models.TxTypeDeposit, models.ChannelBranch
-	default:
-		return models.TxTypeFee, models.ChannelInternal
-	}
-}
-
-func (g *StreamingTransactionGenerator) selectCreditCardTransactionType() (models.TransactionType, models.TransactionChannel) {
-	r := g.rng.Float64()
-	switch {
-	case r < 0.65:
-		return models.TxTypePurchase, models.ChannelPOS
-	case r < 0.80:
-		return models.TxTypePurchase, models.ChannelOnline
-	case r < 0.90:
-		return models.TxTypeDeposit, models.ChannelOnline
-	case r < 0.95:
-		return models.TxTypeRefund, models.ChannelPOS
-	default:
-		return models.TxTypeInterestDebit, models.ChannelInternal
-	}
-}
-
-func (g *StreamingTransactionGenerator) selectBusinessTransactionType(ts time.Time) (models.TransactionType, models.TransactionChannel) {
-	r := g.rng.Float64()
-	switch {
-	case r < 0.30:
-		return models.TxTypeDeposit, models.ChannelACH
-	case r < 0.50:
-		return models.TxTypeTransferOut, models.ChannelWire
-	case r < 0.65:
-		return models.TxTypeBillPayment, models.ChannelOnline
-	case r < 0.80:
-		return models.TxTypeTransferIn, models.ChannelACH
-	case r < 0.90:
-		return models.TxTypeWithdrawal, models.ChannelBranch
-	default:
-		return models.TxTypeFee, models.ChannelInternal
-	}
-}
-
-func (g *StreamingTransactionGenerator) selectPayrollTransactionType(ts time.Time) (models.TransactionType, models.TransactionChannel) {
-	monthlyPattern := patterns.NewMonthlyPattern()
-	if monthlyPattern.IsPayrollDay(ts.Day()) {
-		return models.TxTypePayrollBatch, models.ChannelInternal
-	}
-	r := g.rng.Float64()
-	if r < 0.7 {
-		return models.TxTypeTransferIn, models.ChannelInternal
-	}
-	return models.TxTypeFee, models.ChannelInternal
-}
-
-// generateAmount creates a realistic transaction amount
-func (g *StreamingTransactionGenerator) generateAmount(txnType models.TransactionType, account GeneratedAccount) int64 {
-	var dist *patterns.AmountDistribution
-
-	switch txnType {
-	case models.TxTypeWithdrawal:
-		dist = g.amounts.ATMWithdrawal
-	case models.TxTypePurchase:
-		r := g.rng.Float64()
-		switch {
-		case r < 0.5:
-			dist = g.amounts.SmallPurchase
-		case r < 0.85:
-			dist = g.amounts.MediumPurchase
-		default:
-			dist = g.amounts.LargePurchase
-		}
-	case models.TxTypeBillPayment:
-		dist = g.amounts.BillPayment
-	case models.TxTypeSalary:
-		dist = g.amounts.Salary
-	case models.TxTypeTransferIn, models.TxTypeTransferOut:
-		dist = g.amounts.InternalTransfer
-	case models.TxTypePayrollBatch:
-		return g.rng.Int64Range(50000000, 500000000)
-	case models.TxTypeInterestCredit, models.TxTypeInterestDebit:
-		balance := account.Account.Balance
-		if balance < 0 {
-			balance = -balance
+	if g.audit != nil {
+		if auditErr := g.audit.Close(); err == nil {
+			err = auditErr
 		}
-		rate := float64(account.Account.InterestRate) / 10000 / 12
-		return int64(float64(balance) * rate)
-	case models.TxTypeFee:
-		return g.rng.Int64Range(500, 5000)
-	case models.TxTypeRefund:
-		dist = g.amounts.MediumPurchase
-	case models.TxTypeCashback:
-		return g.rng.Int64Range(100, 2000)
-	default:
-		dist = g.amounts.MediumPurchase
 	}
-
-	if dist == nil {
-		return g.rng.Int64Range(1000, 10000)
-	}
-
-	return dist.GenerateAmount(g.rng.Float64(), g.rng.NormalFloat64())
-}
-
-func (g *StreamingTransactionGenerator) shouldDecline(txnType models.TransactionType, balance, amount int64) bool {
-	if !isDebitType(txnType) {
-		return false
-	}
-	if g.rng.Probability(g.config.DeclinedTransactionRate) {
-		return true
-	}
-	if g.rng.Probability(g.config.InsufficientFundsRate) && balance < amount {
-		return true
-	}
-	return false
-}
-
-func (g *StreamingTransactionGenerator) selectCounterparty(
-	txnType models.TransactionType,
-	account GeneratedAccount,
-	customerAccounts map[int64][]GeneratedAccount,
-) (*int64, *int64) {
-	switch txnType {
-	case models.TxTypeTransferIn, models.TxTypeTransferOut:
-		accounts := customerAccounts[account.Account.CustomerID]
-		for _, acc := range accounts {
-			if acc.Account.ID != account.Account.ID {
-				id := acc.Account.ID
-				return &id, nil
-			}
-		}
-	case models.TxTypePurchase:
-		if len(g.merchantAccountIDs) > 0 {
-			id := g.merchantAccountIDs[g.rng.IntN(len(g.merchantAccountIDs))]
-			return &id, nil
-		}
-	case models.TxTypeBillPayment:
-		if len(g.utilityAccountIDs) > 0 {
-			id := g.utilityAccountIDs[g.rng.IntN(len(g.utilityAccountIDs))]
-			return &id, nil
-		}
-	case models.TxTypeSalary:
-		if len(g.employerAccountIDs) > 0 {
-			id := g.employerAccountIDs[g.rng.IntN(len(g.employerAccountIDs))]
-			return &id, nil
+	if g.transfers != nil {
+		if transferErr := g.transfers.Close(); err == nil {
+			err = transferErr
 		}
 	}
-	return nil, nil
-}
-
-func (g *StreamingTransactionGenerator) selectLocation(channel models.TransactionChannel, account GeneratedAccount) (*int64, *int64) {
-	switch channel {
-	case models.ChannelATM:
-		if len(g.atms) > 0 {
-			atm := g.atms[g.rng.IntN(len(g.atms))]
-			return nil, &atm.ATM.ID
-		}
-	case models.ChannelBranch:
-		if len(g.branches) > 0 {
-			branch := g.branches[g.rng.IntN(len(g.branches))]
-			return &branch.Branch.ID, nil
+	if g.disputes != nil {
+		if disputeErr := g.disputes.Close(); err == nil {
+			err = disputeErr
 		}
 	}
-	return nil, nil
-}
-
-func (g *StreamingTransactionGenerator) generateDescription(
-	txnType models.TransactionType,
-	channel models.TransactionChannel,
-	account GeneratedAccount,
-) string {
-	switch txnType {
-	case models.TxTypeWithdrawal:
-		return fmt.Sprintf("ATM Withdrawal - %s", g.pickLocation(account))
-	case models.TxTypePurchase:
-		return fmt.Sprintf("POS Purchase - %s", g.pickMerchantName())
-	case models.TxTypeBillPayment:
-		return fmt.Sprintf("Bill Payment - %s", g.pickUtilityName())
-	case models.TxTypeSalary:
-		return "Direct Deposit - Payroll"
-	case models.TxTypeTransferIn:
-		return "Transfer from linked account"
-	case models.TxTypeTransferOut:
-		return "Transfer to linked account"
-	case models.TxTypeDeposit:
-		if channel == models.ChannelBranch {
-			return "Branch Deposit"
+	if g.producer != nil {
+		if producerErr := g.producer.Close(); err == nil {
+			err = producerErr
 		}
-		return "Mobile Deposit"
-	case models.TxTypeInterestCredit:
-		return "Interest Payment"
-	case models.TxTypeInterestDebit:
-		return "Interest Charge"
-	case models.TxTypeFee:
-		return g.pickFeeName()
-	case models.TxTypeRefund:
-		return "Refund - " + g.pickMerchantName()
-	case models.TxTypeCashback:
-		return "Cashback Reward"
-	case models.TxTypePayrollBatch:
-		return "Payroll Disbursement"
-	case models.TxTypeLoanPayment:
-		return "Loan Payment"
-	default:
-		return "Transaction"
 	}
+	return err
 }
 
-func (g *StreamingTransactionGenerator) pickLocation(account GeneratedAccount) string {
-	locations := []string{
-		"Main Street", "Downtown", "Airport Terminal", "Mall",
-		"University", "Hospital", "Train Station", "Shopping Center",
+// ShardFile returns the path to the shard file created by this generator
+// (the partition root when partitioning by month, empty when publishing to
+// Kafka only)
+func (g *StreamingTransactionGenerator) ShardFile() string {
+	if g.partitions != nil {
+		return g.config.OutputDir
 	}
-	city := account.Account.Currency
-	return fmt.Sprintf("%s - %s", locations[g.rng.IntN(len(locations))], city)
-}
-
-func (g *StreamingTransactionGenerator) pickMerchantName() string {
-	merchants := []string{
-		"AMAZON", "WALMART", "TARGET", "STARBUCKS", "UBER",
-		"NETFLIX", "SPOTIFY", "APPLE", "GOOGLE", "DOORDASH",
-		"COSTCO", "WHOLE FOODS", "CVS PHARMACY", "SHELL GAS",
-		"MCDONALDS", "SUBWAY", "HOME DEPOT", "BEST BUY",
+	if g.writer == nil {
+		return ""
 	}
-	return merchants[g.rng.IntN(len(merchants))]
+	return g.writer.Path()
 }
 
-func (g *StreamingTransactionGenerator) pickUtilityName() string {
-	utilities := []string{
-		"Electric Company", "Gas & Power", "Water Services",
-		"Internet Provider", "Phone Company", "Insurance Co",
-		"City Services", "Waste Management",
-	}
-	return utilities[g.rng.IntN(len(utilities))]
+// Count returns the number of transactions written
+func (g *StreamingTransactionGenerator) Count() int64 {
+	return g.count
 }
 
-func (g *StreamingTransactionGenerator) pickFeeName() string {
-	fees := []string{
-		"Monthly Maintenance Fee", "ATM Fee", "Wire Transfer Fee",
-		"Overdraft Fee", "Paper Statement Fee", "Foreign Transaction Fee",
-	}
-	return fees[g.rng.IntN(len(fees))]
+// TransferCount returns the number of transfers written
+func (g *StreamingTransactionGenerator) TransferCount() int64 {
+	return g.transferCount
 }
 
-func (g *StreamingTransactionGenerator) generateReferenceNumber(id int64, ts time.Time) string {
-	return fmt.Sprintf("TXN%s%012d", ts.Format("20060102"), id)
+// DisputeCount returns the number of dispute cases written
+func (g *StreamingTransactionGenerator) DisputeCount() int64 {
+	return g.disputeCount
 }
 
-// ShardFile returns the path to the shard file created by this generator
-func (g *StreamingTransactionGenerator) ShardFile() string {
-	return g.writer.Path()
+// LastID returns the highest transaction ID written (0 = none)
+func (g *StreamingTransactionGenerator) LastID() int64 {
+	return g.lastID
 }
 
-// Count returns the number of transactions written
-func (g *StreamingTransactionGenerator) Count() int64 {
-	return g.count
+// AuditCount returns the number of transaction audit events written
+func (g *StreamingTransactionGenerator) AuditCount() int64 {
+	if g.audit == nil {
+		return 0
+	}
+	return g.audit.Count()
 }
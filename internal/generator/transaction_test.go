@@ -0,0 +1,888 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func newTestTransactionGenerator(t *testing.T, start, end time.Time) *TransactionGenerator {
+	t.Helper()
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+	return NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       start,
+		EndDate:                         end,
+		TransactionsPerCustomerPerMonth: 15,
+		ParetoRatio:                     0.2,
+	})
+}
+
+func TestAccruedInterest(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Duration(24 * 365.25 / 12 * float64(time.Hour))) // one average month
+
+	// 12% annual = 1% per month
+	if got := accruedInterest(1000000, 1200, from, to); got < 9999 || got > 10001 {
+		t.Errorf("expected ~10000 cents for one month at 1%%, got %d", got)
+	}
+	if got := accruedInterest(0, 1200, from, to); got != 0 {
+		t.Errorf("expected no interest on zero balance, got %d", got)
+	}
+	if got := accruedInterest(1000000, 0, from, to); got != 0 {
+		t.Errorf("expected no interest at zero rate, got %d", got)
+	}
+	if got := accruedInterest(1000000, 1200, to, from); got != 0 {
+		t.Errorf("expected no interest for negative interval, got %d", got)
+	}
+}
+
+func TestInterestTracksRunningBalance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestTransactionGenerator(t, start, start.AddDate(1, 0, 0))
+
+	// Opening balance is deliberately tiny: interest must come from the live balance
+	account := GeneratedAccount{Account: models.Account{
+		ID:           1,
+		Type:         models.AccountTypeSavings,
+		Balance:      100,
+		InterestRate: 240, // 2.4% annual
+		OpenedAt:     start,
+	}}
+
+	balance := int64(1000000) // $10,000 running balance
+	var interest []int64
+	for month := 1; month <= 12; month++ {
+		if month == 7 {
+			balance += 1000000 // Deposit doubles the balance mid-year
+		}
+		amount := g.generateAmount(models.TxTypeInterestCredit, models.ChannelInternal, FeeRate{}, account, balance, start.AddDate(0, month, 0))
+		interest = append(interest, amount)
+		balance += amount
+	}
+
+	// Interest is based on the $10,000 running balance, not the $1 opening balance
+	if interest[0] < 1500 {
+		t.Fatalf("expected interest from running balance (~2000 cents), got %d", interest[0])
+	}
+
+	// Doubling the balance roughly doubles the monthly posting
+	ratio := float64(interest[6]) / float64(interest[5])
+	if ratio < 1.8 || ratio > 2.2 {
+		t.Errorf("expected interest to double after deposit, got %d -> %d (ratio %.2f)", interest[5], interest[6], ratio)
+	}
+}
+
+func TestInterestCompoundsMonthly(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestTransactionGenerator(t, start, start.AddDate(1, 0, 0))
+
+	account := GeneratedAccount{Account: models.Account{
+		ID:           2,
+		Type:         models.AccountTypeSavings,
+		InterestRate: 600, // 6% annual
+		OpenedAt:     start,
+	}}
+
+	opening := int64(10000000) // $100,000
+	balance := opening
+	for month := 1; month <= 12; month++ {
+		balance += g.generateAmount(models.TxTypeInterestCredit, models.ChannelInternal, FeeRate{}, account, balance, start.AddDate(0, month, 0))
+	}
+
+	// One year of monthly compounding at 0.5%/month (calendar months vs average months
+	// and per-posting truncation account for the small tolerance)
+	expected := float64(opening) * math.Pow(1.005, 12)
+	if diff := math.Abs(float64(balance)-expected) / expected; diff > 0.001 {
+		t.Errorf("expected balance ~%.0f after a year of compounding, got %d (off by %.4f%%)", expected, balance, diff*100)
+	}
+}
+
+func TestCashbackFromPriorMonthPurchases(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestTransactionGenerator(t, start, start.AddDate(0, 4, 0))
+	g.settings.CashbackRate = 0.01
+
+	account := GeneratedAccount{Account: models.Account{
+		ID:       3,
+		Type:     models.AccountTypeCreditCard,
+		Currency: "USD",
+		OpenedAt: start,
+	}}
+
+	txns, _ := g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+
+	purchases := make(map[time.Month]int64)
+	cashback := make(map[time.Month]int64)
+	for _, gt := range txns {
+		txn := gt.Transaction
+		month := txn.Timestamp.UTC().Month()
+		switch {
+		case txn.Type == models.TxTypePurchase && txn.Status == models.TxStatusCompleted:
+			purchases[month] += txn.Amount
+		case txn.Type == models.TxTypeCashback:
+			if !txn.Timestamp.Equal(time.Date(2024, month, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("expected cashback at the start of the month, got %s", txn.Timestamp)
+			}
+			cashback[month] += txn.Amount
+		}
+	}
+
+	if purchases[time.January] == 0 {
+		t.Fatal("expected completed purchases in the first month")
+	}
+	if cashback[time.January] != 0 {
+		t.Errorf("expected no cashback in the first month, got %d", cashback[time.January])
+	}
+	for month := time.February; month <= time.April; month++ {
+		if want := cashbackAmount(purchases[month-1], 0.01); cashback[month] != want {
+			t.Errorf("%s: expected cashback %d on %d of purchases, got %d", month, want, purchases[month-1], cashback[month])
+		}
+	}
+
+	// A zero rate disables cashback
+	g = newTestTransactionGenerator(t, start, start.AddDate(0, 4, 0))
+	txns, _ = g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+	for _, gt := range txns {
+		if gt.Transaction.Type == models.TxTypeCashback {
+			t.Fatalf("expected no cashback with a zero rate, got txn %d", gt.Transaction.ID)
+		}
+	}
+}
+
+func TestReversalsRestoreBalance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestTransactionGenerator(t, start, start.AddDate(0, 6, 0))
+	g.settings.ReversalRate = 0.2
+	g.settings.Granularity = GranularityWeekly
+
+	account := GeneratedAccount{Account: models.Account{
+		ID:       4,
+		Type:     models.AccountTypeChecking,
+		Currency: "USD",
+		Balance:  1000000,
+		OpenedAt: start,
+	}}
+
+	txns, _ := g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+
+	byID := make(map[int64]models.Transaction)
+	var history []models.Transaction
+	for _, gt := range txns {
+		if gt.Transaction.AccountID == account.Account.ID {
+			byID[gt.Transaction.ID] = gt.Transaction
+			history = append(history, gt.Transaction)
+		}
+	}
+
+	reversed := make(map[int64]bool)
+	for _, txn := range history {
+		if !isReversal(txn) {
+			continue
+		}
+		original, ok := byID[*txn.LinkedTransactionID]
+		if !ok {
+			t.Fatalf("reversal %d links to unknown transaction %d", txn.ID, *txn.LinkedTransactionID)
+		}
+		if reversed[original.ID] {
+			t.Errorf("transaction %d reversed twice", original.ID)
+		}
+		reversed[original.ID] = true
+
+		if !isReversibleType(original.Type) || original.Status != models.TxStatusCompleted {
+			t.Errorf("reversal %d of %s %s transaction %d", txn.ID, original.Status, original.Type, original.ID)
+		}
+		if txn.Amount != original.Amount {
+			t.Errorf("reversal %d amount %d, original %d", txn.ID, txn.Amount, original.Amount)
+		}
+		if delay := txn.Timestamp.Sub(original.Timestamp); delay <= 0 || delay > reversalMaxDelay {
+			t.Errorf("reversal %d posted %s after the original", txn.ID, delay)
+		}
+	}
+	if len(reversed) == 0 {
+		t.Fatal("expected reversals at a 20% rate")
+	}
+
+	// Replaying in time order, every balance_after (reversals included) matches
+	slices.SortStableFunc(history, func(a, b models.Transaction) int { return a.Timestamp.Compare(b.Timestamp) })
+	running := account.Account.Balance
+	for _, txn := range history {
+		if txn.Status == models.TxStatusCompleted {
+			if isDebitType(txn.Type) {
+				running -= txn.Amount
+			} else {
+				running += txn.Amount
+			}
+		}
+		if txn.BalanceAfter != running {
+			t.Fatalf("txn %d (%s): balance_after %d, expected %d", txn.ID, txn.Type, txn.BalanceAfter, running)
+		}
+	}
+}
+
+func TestDeclinedPurchaseRetries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	customer := GeneratedCustomer{Customer: models.Customer{ID: 5, ActivityScore: 1}}
+	card := GeneratedAccount{Account: models.Account{
+		ID:         5,
+		CustomerID: 5,
+		Type:       models.AccountTypeCreditCard,
+		Status:     models.AccountStatusActive,
+		Currency:   "USD",
+		OpenedAt:   start,
+	}, Customer: customer}
+	checking := GeneratedAccount{Account: models.Account{
+		ID:         6,
+		CustomerID: 5,
+		Type:       models.AccountTypeChecking,
+		Status:     models.AccountStatusActive,
+		Currency:   "USD",
+		Balance:    500000,
+		OpenedAt:   start,
+	}, Customer: customer}
+	generate := func(rate float64, accounts ...GeneratedAccount) []GeneratedTransaction {
+		g := newTestTransactionGenerator(t, start, start.AddDate(0, 3, 0))
+		g.settings.DeclinedTransactionRate = 0.2
+		g.settings.DeclineRetryRate = rate
+		txns, _ := g.GenerateTransactionsForAccounts(accounts, 1)
+		return txns
+	}
+
+	declined := make(map[string]models.Transaction)
+	retries := 0
+	for _, gt := range generate(1, card, checking) {
+		txn := gt.Transaction
+		if txn.Type != models.TxTypePurchase || txn.Metadata == "{}" {
+			continue
+		}
+		if txn.Status == models.TxStatusDeclined {
+			declined[txn.Metadata] = txn
+			continue
+		}
+		retries++
+		first, ok := declined[txn.Metadata]
+		if !ok {
+			t.Fatalf("purchase %d has metadata %s but follows no declined attempt", txn.ID, txn.Metadata)
+		}
+		if delay := txn.Timestamp.Sub(first.Timestamp); delay < declineRetryMinDelay*time.Second || delay > declineRetryMaxDelay*time.Second {
+			t.Errorf("purchase %d retried %s after the decline", txn.ID, delay)
+		}
+		if txn.Status != models.TxStatusCompleted || txn.Amount <= 0 || txn.FailureReason != nil {
+			t.Errorf("retry %d is not a completed purchase: %+v", txn.ID, txn)
+		}
+		if !reflect.DeepEqual(txn.CounterpartyAccountID, first.CounterpartyAccountID) || txn.Channel != first.Channel {
+			t.Errorf("retry %d is not at the declined purchase's merchant", txn.ID)
+		}
+		// The retry goes on another account that had the funds
+		if txn.AccountID == first.AccountID || gt.Account.Account.ID != txn.AccountID ||
+			gt.Account.Account.CustomerID != customer.Customer.ID {
+			t.Errorf("retry %d on account %d, declined on %d", txn.ID, txn.AccountID, first.AccountID)
+		}
+		if txn.BalanceAfter < -gt.Account.Account.OverdraftLimit {
+			t.Errorf("retry %d of %d succeeded with a balance of %d", txn.ID, txn.Amount, txn.BalanceAfter+txn.Amount)
+		}
+	}
+	if retries == 0 {
+		t.Fatal("expected declined purchases to be retried")
+	}
+
+	// Without another card or checking account, and with a zero rate, nothing is retried
+	for name, txns := range map[string][]GeneratedTransaction{
+		"card only": generate(1, card),
+		"zero rate": generate(0, card, checking),
+	} {
+		for _, gt := range txns {
+			if gt.Transaction.Type == models.TxTypePurchase && gt.Transaction.Metadata != "{}" {
+				t.Fatalf("%s: expected no retries, got txn %d", name, gt.Transaction.ID)
+			}
+		}
+	}
+}
+
+func TestBatchAndStreamingGenerateSameTransactions(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	start := asOf.AddDate(0, -4, 0)
+	rng := utils.NewRandom(7)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 3, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 20, Branches: branches, BaseDate: asOf, ChurnRate: 0.2,
+	}).GenerateCustomers()
+	accounts, _ := NewAccountGenerator(rng.Fork(), refData, AccountGeneratorConfig{
+		Branches: branches, BaseDate: asOf,
+	}).GenerateAccountsForCustomers(customers, 1)
+
+	batch := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       start,
+		EndDate:                         asOf,
+		TransactionsPerCustomerPerMonth: 15,
+		ParetoRatio:                     0.2,
+		Granularity:                     GranularityWeekly,
+		DeclinedTransactionRate:         0.01,
+		InsufficientFundsRate:           0.02,
+		CashbackRate:                    0.01,
+		ReversalRate:                    0.01,
+		GeoCoordinates:                  true,
+		Accounts:                        accounts,
+	})
+	want, _ := batch.GenerateTransactionsForAccounts(accounts, 1)
+	if len(want) == 0 {
+		t.Fatal("expected batch transactions")
+	}
+
+	stream, err := NewStreamingTransactionGenerator(utils.NewRandom(42), refData, StreamingTransactionConfig{
+		StartDate:                       start,
+		EndDate:                         asOf,
+		TransactionsPerCustomerPerMonth: 15,
+		ParetoRatio:                     0.2,
+		Granularity:                     GranularityWeekly,
+		DeclinedTransactionRate:         0.01,
+		InsufficientFundsRate:           0.02,
+		CashbackRate:                    0.01,
+		ReversalRate:                    0.01,
+		GeoCoordinates:                  true,
+		Accounts:                        NewAccountIndex(accounts, nil),
+		WorkerCount:                     1,
+		OutputDir:                       t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create streaming generator: %v", err)
+	}
+	ctx := context.Background()
+	partition := GenerationPartition{Accounts: accounts, RNG: utils.NewRandom(42), FirstID: 1}
+	if _, err := stream.GenerateAndStream(ctx, []GenerationPartition{partition}); err != nil {
+		t.Fatalf("streaming generation failed: %v", err)
+	}
+
+	var rows int
+	err = ReadCSVRows(ctx, stream.ShardFile(), TransactionGeoHeaders(), func(row []string) error {
+		if rows < len(want) {
+			if expected := transactionRow(want[rows].Transaction, true); !slices.Equal(row, expected) {
+				t.Fatalf("row %d differs:\n batch:  %v\n stream: %v", rows, expected, row)
+			}
+		}
+		rows++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read shard: %v", err)
+	}
+	if rows != len(want) {
+		t.Errorf("expected %d streamed transactions, got %d", len(want), rows)
+	}
+}
+
+func TestChunkedBatchMatchesInMemory(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	accounts := []GeneratedAccount{
+		{Account: models.Account{ID: 1, Type: models.AccountTypeChecking, Currency: "USD", Balance: 500000, OpenedAt: start}},
+		{Account: models.Account{ID: 2, Type: models.AccountTypeCreditCard, Currency: "USD", OpenedAt: start}},
+	}
+	want, wantNext := newTestTransactionGenerator(t, start, start.AddDate(0, 6, 0)).GenerateTransactionsForAccounts(accounts, 1)
+
+	var got []GeneratedTransaction
+	chunks := 0
+	next, err := newTestTransactionGenerator(t, start, start.AddDate(0, 6, 0)).GenerateTransactionsInChunks(accounts, 1, 7,
+		func(chunk []GeneratedTransaction) error {
+			if len(chunk) == 0 || len(chunk) > 7 {
+				t.Fatalf("flushed a chunk of %d transactions", len(chunk))
+			}
+			chunks++
+			got = append(got, chunk...)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != wantNext || len(got) != len(want) {
+		t.Fatalf("chunked generation gave %d transactions (next ID %d), want %d (next ID %d)", len(got), next, len(want), wantNext)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i].Transaction, want[i].Transaction) {
+			t.Fatalf("transaction %d differs:\n in memory: %+v\n chunked:   %+v", i, want[i].Transaction, got[i].Transaction)
+		}
+	}
+	if wantChunks := (len(want) + 6) / 7; chunks != wantChunks {
+		t.Errorf("flushed %d chunks, want %d", chunks, wantChunks)
+	}
+
+	// A failed flush stops generation
+	failed := errors.New("disk full")
+	flushes := 0
+	_, err = newTestTransactionGenerator(t, start, start.AddDate(0, 6, 0)).GenerateTransactionsInChunks(accounts, 1, 7,
+		func([]GeneratedTransaction) error {
+			flushes++
+			return failed
+		})
+	if !errors.Is(err, failed) || flushes != 1 {
+		t.Errorf("expected generation to stop at the failed flush, got %v after %d flushes", err, flushes)
+	}
+}
+
+func TestReferenceNumbersGroupLegs(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(11)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 3, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 40, Branches: branches, BaseDate: asOf,
+	}).GenerateCustomers()
+	accounts, _ := NewAccountGenerator(rng.Fork(), refData, AccountGeneratorConfig{
+		Branches: branches, BaseDate: asOf,
+	}).GenerateAccountsForCustomers(customers, 1)
+
+	gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       asOf.AddDate(0, -3, 0),
+		EndDate:                         asOf,
+		TransactionsPerCustomerPerMonth: 20,
+		ParetoRatio:                     0.2,
+		CashbackRate:                    0.01,
+		ReversalRate:                    0.02,
+		Accounts:                        accounts,
+	})
+	txns, _ := gen.GenerateTransactionsForAccounts(accounts, 1)
+
+	legs := make(map[string][]models.Transaction)
+	for _, gt := range txns {
+		legs[gt.Transaction.ReferenceNumber] = append(legs[gt.Transaction.ReferenceNumber], gt.Transaction)
+	}
+
+	var multiLeg int
+	for ref, group := range legs {
+		slices.SortFunc(group, func(a, b models.Transaction) int { return int(a.ID - b.ID) })
+		first := group[0]
+		if want := gen.generateReferenceNumber(first.ID, first.Timestamp); ref != want {
+			t.Errorf("reference %s on first leg %d, expected %s", ref, first.ID, want)
+		}
+
+		// Payroll batches fan out into salary credits (see TestPayrollBatchFansOutToSalaries)
+		if first.Type == models.TxTypePayrollBatch {
+			for _, credit := range group[1:] {
+				if credit.Type != models.TxTypeSalary || credit.LinkedTransactionID == nil || *credit.LinkedTransactionID != first.ID {
+					t.Errorf("%s: leg %d (%s) is not a salary credit of payroll batch %d", ref, credit.ID, credit.Type, first.ID)
+				}
+			}
+			continue
+		}
+
+		switch len(group) {
+		case 1:
+			if first.CounterpartyAccountID != nil && first.Status == models.TxStatusCompleted {
+				t.Errorf("completed transaction %d with counterparty %d has no counterparty leg",
+					first.ID, *first.CounterpartyAccountID)
+			}
+		case 2:
+			multiLeg++
+			other := group[1]
+			if other.LinkedTransactionID == nil || *other.LinkedTransactionID != first.ID {
+				t.Errorf("%s: second leg %d does not link to first leg %d", ref, other.ID, first.ID)
+			}
+			if first.CounterpartyAccountID == nil || *first.CounterpartyAccountID != other.AccountID ||
+				other.CounterpartyAccountID == nil || *other.CounterpartyAccountID != first.AccountID {
+				t.Errorf("%s: legs %d and %d are not each other's counterparty", ref, first.ID, other.ID)
+			}
+			if other.Amount != first.Amount || !other.Timestamp.Equal(first.Timestamp) {
+				t.Errorf("%s: legs %d and %d differ in amount or time", ref, first.ID, other.ID)
+			}
+			if isDebitType(other.Type) == isDebitType(first.Type) {
+				t.Errorf("%s: legs %d (%s) and %d (%s) move money the same way", ref, first.ID, first.Type, other.ID, other.Type)
+			}
+		default:
+			t.Errorf("reference %s shared by %d transactions", ref, len(group))
+		}
+	}
+	if multiLeg == 0 {
+		t.Fatal("expected transactions with a counterparty leg")
+	}
+}
+
+func TestPayrollBatchFansOutToSalaries(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	employer := GeneratedAccount{
+		Account: models.Account{
+			ID: 1, CustomerID: 1, Type: models.AccountTypePayroll, Currency: "USD",
+			Balance: 10000000000, OpenedAt: start.AddDate(-1, 0, 0),
+		},
+		Customer: GeneratedCustomer{Customer: models.Customer{
+			ID: 1, Segment: models.SegmentBusiness, ActivityScore: 1, Timezone: "America/New_York",
+		}},
+	}
+	accounts := []GeneratedAccount{employer}
+	isEmployeeAccount := make(map[int64]bool)
+	for id := int64(2); id <= 60; id++ {
+		accounts = append(accounts, GeneratedAccount{
+			Account: models.Account{ID: id, CustomerID: id, Type: models.AccountTypeChecking, Currency: "USD", OpenedAt: start},
+			Customer: GeneratedCustomer{Customer: models.Customer{
+				ID: id, Segment: models.SegmentRegular, ActivityScore: 0.5, Timezone: "America/New_York",
+			}},
+		})
+		isEmployeeAccount[id] = true
+	}
+	// A checking account in another currency is never on this payroll
+	accounts = append(accounts, GeneratedAccount{
+		Account:  models.Account{ID: 99, CustomerID: 99, Type: models.AccountTypeChecking, Currency: "EUR", OpenedAt: start},
+		Customer: GeneratedCustomer{Customer: models.Customer{ID: 99, Segment: models.SegmentRegular}},
+	})
+
+	gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       start,
+		EndDate:                         start.AddDate(0, 3, 0),
+		TransactionsPerCustomerPerMonth: 30,
+		ParetoRatio:                     0.2,
+		Accounts:                        accounts,
+	})
+	txns, _ := gen.GenerateTransactionsForAccounts([]GeneratedAccount{employer}, 1)
+
+	batches := make(map[int64]models.Transaction)
+	credits := make(map[int64][]models.Transaction)
+	for _, gt := range txns {
+		txn := gt.Transaction
+		switch {
+		case txn.Type == models.TxTypePayrollBatch && txn.Status == models.TxStatusCompleted:
+			batches[txn.ID] = txn
+		case txn.Type == models.TxTypeSalary && txn.LinkedTransactionID != nil:
+			credits[*txn.LinkedTransactionID] = append(credits[*txn.LinkedTransactionID], txn)
+		}
+	}
+	if len(batches) == 0 {
+		t.Fatal("expected completed payroll batches")
+	}
+
+	var workforce []int64
+	for id, batch := range batches {
+		paid := credits[id]
+		if len(paid) < payrollMinEmployees || len(paid) > payrollMaxEmployees {
+			t.Fatalf("batch %d paid %d employees, expected %d-%d", id, len(paid), payrollMinEmployees, payrollMaxEmployees)
+		}
+
+		var total int64
+		var employees []int64
+		for _, credit := range paid {
+			total += credit.Amount
+			employees = append(employees, credit.AccountID)
+			if !isEmployeeAccount[credit.AccountID] {
+				t.Errorf("batch %d paid account %d, which is not a USD retail checking account", id, credit.AccountID)
+			}
+			if credit.ReferenceNumber != batch.ReferenceNumber || !credit.Timestamp.Equal(batch.Timestamp) ||
+				credit.CounterpartyAccountID == nil || *credit.CounterpartyAccountID != employer.Account.ID {
+				t.Errorf("salary credit %d does not match batch %d", credit.ID, id)
+			}
+		}
+		if total != batch.Amount {
+			t.Errorf("batch %d debited %d but credited %d", id, batch.Amount, total)
+		}
+
+		// The same workforce is paid every time
+		slices.Sort(employees)
+		if workforce == nil {
+			workforce = employees
+		} else if !slices.Equal(employees, workforce) {
+			t.Errorf("batch %d paid %v, earlier batches paid %v", id, employees, workforce)
+		}
+	}
+}
+
+func TestOverdraftFees(t *testing.T) {
+	core := newTransactionCore(nil, transactionSettings{OverdraftFee: 3500, OverdraftFeeDailyCap: 2})
+	core.partition = newTransactionPartition(utils.NewRandom(1), nil, 100, 1)
+	core.rng = core.partition.rng
+	account := GeneratedAccount{Account: models.Account{ID: 1, Type: models.AccountTypeChecking, OverdraftLimit: 12000}}
+	balances := map[int64]int64{1: -1000}
+
+	day := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	debit := func(id int64, ts time.Time) models.Transaction {
+		return models.Transaction{ID: id, Type: models.TxTypeWithdrawal, Status: models.TxStatusCompleted, Amount: 1000, Timestamp: ts}
+	}
+
+	// Two fees on the first day, then the cap applies until the next day
+	var charged []int64
+	for i, ts := range []time.Time{day, day.Add(time.Hour), day.Add(2 * time.Hour), day.AddDate(0, 0, 1)} {
+		if fee, ok := core.overdraftFee(account, balances, debit(int64(i+1), ts)); ok {
+			if fee.Type != models.TxTypeFee || fee.Amount != 3500 || fee.BalanceAfter != balances[1] ||
+				fee.LinkedTransactionID == nil || *fee.LinkedTransactionID != int64(i+1) {
+				t.Errorf("unexpected fee %+v", fee)
+			}
+			charged = append(charged, *fee.LinkedTransactionID)
+		}
+	}
+	if !slices.Equal(charged, []int64{1, 2, 4}) {
+		t.Errorf("fees charged for debits %v, expected [1 2 4]", charged)
+	}
+	if balances[1] != -1000-3*3500 {
+		t.Errorf("balance %d, expected fees deducted", balances[1])
+	}
+
+	// No fee past the overdraft limit, on a positive balance, or on a credit
+	if _, ok := core.overdraftFee(account, balances, debit(5, day.AddDate(0, 0, 2))); ok {
+		t.Error("expected no fee beyond the overdraft limit")
+	}
+	balances[1] = 500
+	if _, ok := core.overdraftFee(account, balances, debit(6, day.AddDate(0, 0, 3))); ok {
+		t.Error("expected no fee on a positive balance")
+	}
+	balances[1] = -500
+	credit := debit(7, day.AddDate(0, 0, 4))
+	credit.Type = models.TxTypeDeposit
+	if _, ok := core.overdraftFee(account, balances, credit); ok {
+		t.Error("expected no fee on a credit")
+	}
+}
+
+func TestClosedBranchesAndOfflineATMsNotUsed(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(5)
+	branchGen := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{
+		NumBranches:        8,
+		NumATMs:            12,
+		BaseDate:           asOf,
+		YearsBack:          1,
+		ClosedBranchRate:   0.5,
+		OfflineATMRate:     0.3,
+		MaintenanceATMRate: 0.3,
+	})
+	branches := branchGen.GenerateBranches()
+	atms := branchGen.GenerateATMs(branches)
+
+	var closed, down int
+	for _, b := range branches {
+		if b.Branch.ClosedAt != nil {
+			closed++
+			if b.Branch.Status != models.BranchStatusClosed {
+				t.Errorf("branch %d closed at %s with status %s", b.Branch.ID, b.Branch.ClosedAt, b.Branch.Status)
+			}
+		}
+	}
+	for _, a := range atms {
+		if a.OutOfServiceSince != nil {
+			down++
+			if a.ATM.Status == models.ATMStatusOnline {
+				t.Errorf("ATM %d out of service since %s but online", a.ATM.ID, a.OutOfServiceSince)
+			}
+		}
+	}
+	if closed == 0 || down == 0 {
+		t.Fatalf("expected closed branches and out of service ATMs, got %d and %d", closed, down)
+	}
+
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 60, Branches: branches, BaseDate: asOf,
+	}).GenerateCustomers()
+	accounts, _ := NewAccountGenerator(rng.Fork(), refData, AccountGeneratorConfig{
+		Branches: branches, BaseDate: asOf,
+	}).GenerateAccountsForCustomers(customers, 1)
+
+	gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       asOf.AddDate(-1, 0, 0),
+		EndDate:                         asOf,
+		TransactionsPerCustomerPerMonth: 20,
+		ParetoRatio:                     0.2,
+		Accounts:                        accounts,
+		Branches:                        branches,
+		ATMs:                            atms,
+	})
+	txns, _ := gen.GenerateTransactionsForAccounts(accounts, 1)
+
+	branchByID := make(map[int64]GeneratedBranch)
+	for _, b := range branches {
+		branchByID[b.Branch.ID] = b
+	}
+	atmByID := make(map[int64]GeneratedATM)
+	for _, a := range atms {
+		atmByID[a.ATM.ID] = a
+	}
+
+	var located int
+	for _, gt := range txns {
+		txn := gt.Transaction
+		if txn.BranchID != nil {
+			located++
+			if b := branchByID[*txn.BranchID]; !b.OpenAt(txn.Timestamp) {
+				t.Errorf("transaction %d at %s uses branch %d closed at %s", txn.ID, txn.Timestamp, b.Branch.ID, b.Branch.ClosedAt)
+			}
+		}
+		if txn.ATMID != nil {
+			located++
+			if a := atmByID[*txn.ATMID]; !a.InServiceAt(txn.Timestamp) {
+				t.Errorf("transaction %d at %s uses ATM %d out of service since %s", txn.ID, txn.Timestamp, a.ATM.ID, a.OutOfServiceSince)
+			}
+		}
+	}
+	if located == 0 {
+		t.Fatal("no transactions at a branch or ATM")
+	}
+}
+
+func TestBranchHoursEnforced(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(9)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{
+		NumBranches: 6, BaseDate: asOf, YearsBack: 1,
+	}).GenerateBranches()
+	// Every branch is open weekdays and Saturday mornings, closed on Sundays
+	for i := range branches {
+		b := &branches[i].Branch
+		b.MondayHours, b.TuesdayHours, b.WednesdayHours, b.ThursdayHours, b.FridayHours = "09:00-17:00", "09:00-17:00", "09:00-17:00", "09:00-17:00", "09:00-17:00"
+		b.SaturdayHours, b.SundayHours = "09:00-13:00", ""
+	}
+
+	sunday := time.Date(2024, 6, 16, 11, 0, 0, 0, time.UTC)
+	if local := sunday.In(zone(branches[0].Branch.Timezone)); local.Weekday() == time.Sunday && branches[0].InHoursAt(sunday) {
+		t.Errorf("branch %d open at %s on a Sunday", branches[0].Branch.ID, local)
+	}
+
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 80, Branches: branches, BaseDate: asOf,
+	}).GenerateCustomers()
+	accounts, _ := NewAccountGenerator(rng.Fork(), refData, AccountGeneratorConfig{
+		Branches: branches, BaseDate: asOf,
+	}).GenerateAccountsForCustomers(customers, 1)
+
+	gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       asOf.AddDate(-1, 0, 0),
+		EndDate:                         asOf,
+		TransactionsPerCustomerPerMonth: 20,
+		ParetoRatio:                     0.2,
+		BranchHours:                     true,
+		Accounts:                        accounts,
+		Branches:                        branches,
+	})
+	txns, _ := gen.GenerateTransactionsForAccounts(accounts, 1)
+
+	var atBranch int
+	for _, gt := range txns {
+		txn := gt.Transaction
+		if txn.Channel != models.ChannelBranch {
+			continue
+		}
+		if txn.BranchID == nil {
+			t.Errorf("branch transaction %d at %s has no branch", txn.ID, txn.Timestamp)
+			continue
+		}
+		atBranch++
+		b := branchByID(branches, *txn.BranchID)
+		if local := txn.Timestamp.In(zone(b.Branch.Timezone)); !b.InHoursAt(txn.Timestamp) || local.Weekday() == time.Sunday {
+			t.Errorf("transaction %d at branch %d at %s (%s), outside its hours", txn.ID, b.Branch.ID, local, local.Weekday())
+		}
+	}
+	if atBranch == 0 {
+		t.Fatal("no branch transactions")
+	}
+}
+
+func TestChannelsPeakAtDifferentHours(t *testing.T) {
+	g := newTestTransactionGenerator(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	var accounts []GeneratedAccount
+	for id := int64(1); id <= 20; id++ {
+		accounts = append(accounts, GeneratedAccount{
+			Account: models.Account{
+				ID: id, CustomerID: id, Type: models.AccountTypeChecking, Currency: "USD",
+				Balance: 10_000_000, OpenedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			Customer: GeneratedCustomer{Customer: models.Customer{ID: id, ActivityScore: 1, Timezone: "UTC"}},
+		})
+	}
+	txns, _ := g.GenerateTransactionsForAccounts(accounts, 1)
+
+	// Share of each channel's transactions made in the evening (19:00-21:59)
+	evening := func(channel models.TransactionChannel) float64 {
+		total, late := 0, 0
+		for _, gt := range txns {
+			if gt.Transaction.Channel != channel {
+				continue
+			}
+			total++
+			if h := gt.Transaction.Timestamp.Hour(); h >= 19 && h <= 21 {
+				late++
+			}
+		}
+		if total == 0 {
+			t.Fatalf("no %s transactions", channel)
+		}
+		return float64(late) / float64(total)
+	}
+	online, branch := evening(models.ChannelOnline), evening(models.ChannelBranch)
+	if online < 0.15 || branch > online/2 {
+		t.Errorf("evening share online %.2f, branch %.2f; expected online to peak in the evening", online, branch)
+	}
+}
+
+func TestNewAccountRampUp(t *testing.T) {
+	opened := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		month time.Time
+		want  float64
+	}{
+		{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 0.25}, // opening month
+		{time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), 0.5},
+		{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), 0.75},
+		{time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 1},
+	} {
+		if got := rampUpFactor(opened, tc.month, 3); got != tc.want {
+			t.Errorf("ramp-up in %s = %g, want %g", tc.month.Format("2006-01"), got, tc.want)
+		}
+	}
+	if got := rampUpFactor(opened, opened, 0); got != 1 {
+		t.Errorf("ramp-up disabled = %g, want 1", got)
+	}
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestTransactionGenerator(t, start, start.AddDate(0, 1, 0))
+	g.settings.TransactionsPerCustomerPerMonth = 100
+	g.settings.RampUpMonths = 3
+	customer := GeneratedCustomer{Customer: models.Customer{ActivityScore: 1}}
+	established := GeneratedAccount{Account: models.Account{ID: 1, Type: models.AccountTypeChecking, OpenedAt: opened.AddDate(-2, 0, 0)}, Customer: customer}
+	young := GeneratedAccount{Account: models.Account{ID: 2, Type: models.AccountTypeChecking, OpenedAt: opened}, Customer: customer}
+
+	var full, ramping int
+	for i := 0; i < 50; i++ {
+		full += g.calculateMonthlyTransactionCount(established, start)
+		ramping += g.calculateMonthlyTransactionCount(young, start)
+	}
+	if ratio := float64(ramping) / float64(full); ratio < 0.2 || ratio > 0.3 {
+		t.Errorf("account in its opening month has %d transactions to an established account's %d (%.2f), expected about a quarter", ramping, full, ratio)
+	}
+
+	// The progress estimate ramps up the same way: a quarter, a half, three
+	// quarters and a full month over the first four months
+	accounts := []GeneratedAccount{established, young}
+	if got, want := EstimateTransactionCount(accounts, start, 4, 100, nil, 3), int64(float64(4*100+(0.25+0.5+0.75+1)*100)*1.5); got != want {
+		t.Errorf("estimate with ramp-up = %d, want %d", got, want)
+	}
+	if got, want := EstimateTransactionCount(accounts, start, 4, 100, nil, 0), int64(2*4*100*1.5); got != want {
+		t.Errorf("estimate without ramp-up = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// TransferHeaders returns the CSV headers for transfers
+func TransferHeaders() []string {
+	return []string{
+		"id", "reference_number", "from_account_id", "to_account_id", "amount", "currency",
+		"status", "debit_transaction_id", "credit_transaction_id", "timestamp",
+	}
+}
+
+// isTransferType reports whether a transaction type moves money between two
+// of a customer's accounts when it has a counterparty
+func isTransferType(txnType models.TransactionType) bool {
+	return txnType == models.TxTypeTransferOut || txnType == models.TxTypeTransferIn
+}
+
+// newTransfer normalizes an internal transfer from its originating leg and
+// the counterparty leg. counter is nil only when a transfer out was declined.
+func newTransfer(leg models.Transaction, counter *models.Transaction) models.Transfer {
+	debit, credit := &leg, counter
+	if !isDebitType(leg.Type) {
+		// A transfer in was initiated on the receiving account
+		debit, credit = counter, &leg
+	}
+	t := models.Transfer{
+		ID:                 leg.ID,
+		ReferenceNumber:    leg.ReferenceNumber,
+		FromAccountID:      debit.AccountID,
+		ToAccountID:        *debit.CounterpartyAccountID,
+		Amount:             leg.Amount,
+		Currency:           leg.Currency,
+		Status:             leg.Status,
+		DebitTransactionID: debit.ID,
+		Timestamp:          leg.Timestamp,
+	}
+	if credit != nil {
+		t.CreditTransactionID = &credit.ID
+	}
+	return t
+}
+
+// transferRow formats a transfer as a CSV row
+func transferRow(t models.Transfer) []string {
+	return []string{
+		FormatInt64(t.ID),
+		t.ReferenceNumber,
+		FormatInt64(t.FromAccountID),
+		FormatInt64(t.ToAccountID),
+		FormatInt64(t.Amount),
+		string(t.Currency),
+		string(t.Status),
+		FormatInt64(t.DebitTransactionID),
+		FormatInt64Ptr(t.CreditTransactionID),
+		FormatTime(t.Timestamp),
+	}
+}
@@ -0,0 +1,81 @@
+package generator
+
+// TransferGraph shapes who internal transfers move money between. Each retail
+// account gets a small stable set of payees among other customers' checking
+// accounts in its generation partition, mostly customers of its own home
+// branch, so transfers form repeated edges and local clusters rather than one
+// edge per customer.
+type TransferGraph struct {
+	// Payees per retail account (0 = transfers only between the customer's own accounts)
+	Payees int `json:"payees"`
+	// Fraction of a retail account's transfers with its payees rather than its own accounts
+	P2PRate float64 `json:"p2p_rate"`
+}
+
+// transferGraphLocalRate is the fraction of payees drawn from customers of
+// the account's home branch (the rest from anywhere)
+const transferGraphLocalRate = 0.7
+
+// rankWeights weights n counterparties by rank, 1/(rank+1), so the first is
+// used most and the rest progressively less
+func rankWeights(n int) []int {
+	weights := make([]int, n)
+	for i := range weights {
+		weights[i] = 1000 / (i + 1)
+	}
+	return weights
+}
+
+// transferCounterparty picks the other side of an internal transfer from the
+// account's counterparty distribution: one of its payees (retail accounts
+// only) or one of the customer's own other accounts, each ranked so a few
+// dominate. ok is false when the account has no one to transfer with.
+func (g *transactionCore) transferCounterparty(account GeneratedAccount, customerAccounts map[int64][]GeneratedAccount) (id int64, ok bool) {
+	if !account.Customer.Customer.IsBusinessCustomer() {
+		payees := g.payeesOf(account)
+		if len(payees) > 0 && g.rng.Probability(g.settings.TransferGraph.P2PRate) {
+			return payees[g.rng.WeightedPick(rankWeights(len(payees)))], true
+		}
+	}
+
+	var own []int64
+	for _, acc := range customerAccounts[account.Account.CustomerID] {
+		if acc.Account.ID != account.Account.ID {
+			own = append(own, acc.Account.ID)
+		}
+	}
+	if len(own) == 0 {
+		return 0, false
+	}
+	return own[g.rng.WeightedPick(rankWeights(len(own)))], true
+}
+
+// payeesOf returns the account's payees, sampling them on first use from the
+// partition being generated
+func (g *transactionCore) payeesOf(account GeneratedAccount) []int64 {
+	if payees, ok := g.transferPayees[account.Account.ID]; ok {
+		return payees
+	}
+
+	n := g.settings.TransferGraph.Payees
+	local := g.partition.peersByBranch[account.Customer.Customer.HomeBranch]
+	payees := make([]int64, 0, n)
+	picked := make(map[int64]bool, n)
+	for attempts := 0; len(payees) < n && attempts < n*10; attempts++ {
+		pool := g.partition.peerIDs
+		if len(local) > 0 && g.rng.Probability(transferGraphLocalRate) {
+			pool = local
+		}
+		if len(pool) == 0 {
+			break
+		}
+		id := pool[g.rng.IntN(len(pool))]
+		if picked[id] || g.accounts.byID[id].Account.CustomerID == account.Account.CustomerID {
+			continue
+		}
+		picked[id] = true
+		payees = append(payees, id)
+	}
+	g.transferPayees[account.Account.ID] = payees
+	return payees
+}
@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestTransferGraphPayees(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(21)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 4, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 80, Branches: branches, BaseDate: asOf,
+	}).GenerateCustomers()
+	accounts, _ := NewAccountGenerator(rng.Fork(), refData, AccountGeneratorConfig{
+		Branches: branches, BaseDate: asOf,
+	}).GenerateAccountsForCustomers(customers, 1)
+	index := NewAccountIndex(accounts, nil)
+
+	const payees = 3
+	gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       asOf.AddDate(0, -6, 0),
+		EndDate:                         asOf,
+		TransactionsPerCustomerPerMonth: 20,
+		ParetoRatio:                     0.2,
+		TransferGraph:                   TransferGraph{Payees: payees, P2PRate: 0.5},
+		Accounts:                        accounts,
+	})
+	txns, _ := gen.GenerateTransactionsForAccounts(accounts, 1)
+
+	// Each account's transfers with other customers stay within its few payees
+	counterparties := make(map[int64]map[int64]int)
+	local, p2p, own := 0, 0, 0
+	for _, gt := range txns {
+		txn := gt.Transaction
+		if !isTransferType(txn.Type) || txn.CounterpartyAccountID == nil || txn.LinkedTransactionID != nil {
+			continue
+		}
+		counterparty := index.byID[*txn.CounterpartyAccountID]
+		if counterparty.Account.CustomerID == gt.Account.Account.CustomerID {
+			own++
+			continue
+		}
+		p2p++
+		if counterparty.Customer.Customer.HomeBranch == gt.Account.Customer.Customer.HomeBranch {
+			local++
+		}
+		if counterparty.Account.Type != models.AccountTypeChecking {
+			t.Errorf("transaction %d pays a %s account", txn.ID, counterparty.Account.Type)
+		}
+		if counterparties[txn.AccountID] == nil {
+			counterparties[txn.AccountID] = make(map[int64]int)
+		}
+		counterparties[txn.AccountID][counterparty.Account.ID]++
+	}
+	if p2p == 0 || own == 0 {
+		t.Fatalf("expected transfers with payees and between own accounts, got %d and %d", p2p, own)
+	}
+	for id, c := range counterparties {
+		if len(c) > payees {
+			t.Errorf("account %d transferred with %d other customers' accounts, want at most %d", id, len(c), payees)
+		}
+	}
+	if float64(local)/float64(p2p) < 0.5 {
+		t.Errorf("expected most payees at the account's home branch, got %d of %d", local, p2p)
+	}
+}
+
+func TestTransferPayeeBalances(t *testing.T) {
+	dir := t.TempDir()
+	o, err := NewOrchestrator(OrchestratorConfig{
+		NumCustomers:                    300,
+		NumBranches:                     3,
+		NumATMs:                         4,
+		YearsOfHistory:                  1,
+		OutputDir:                       dir,
+		Seed:                            5,
+		AsOfDate:                        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		TransactionsPerCustomerPerMonth: 10,
+		PayrollDay:                      25,
+		Workers:                         2,
+		TransferGraph:                   TransferGraph{Payees: 3, P2PRate: 0.5},
+	}, OrchestratorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := o.GenerateAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := readVerifyAccounts(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := findTableFiles(dir, "transactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each account's rows and which of them are counterparty transfer_in legs
+	history := make(map[int64][]verifyTxn)
+	transferIn := make(map[int64]bool)
+	for _, f := range files {
+		err := ReadCSVRows(ctx, f, []string{"id", "account_id", "type", "status", "amount", "balance_after", "timestamp", "linked_transaction_id"},
+			func(row []string) error {
+				txn, accountID, err := parseVerifyTxn(row)
+				if err != nil {
+					return err
+				}
+				history[accountID] = append(history[accountID], txn)
+				if models.TransactionType(row[2]) == models.TxTypeTransferIn && row[7] != "" {
+					transferIn[txn.id] = true
+				}
+				return nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(transferIn) == 0 {
+		t.Fatal("no transfer_in legs were generated")
+	}
+
+	// Replayed in the order they were generated, every transfer_in leg
+	// carries the payee's running balance. Without businesses no salaries or
+	// purchases come from other partitions, so every row is tracked.
+	for accountID, txns := range history {
+		slices.SortFunc(txns, func(a, b verifyTxn) int { return cmp.Compare(a.id, b.id) })
+		running := accounts[accountID].opening
+		for _, txn := range txns {
+			running += txn.delta
+			if transferIn[txn.id] && txn.balanceAfter != running {
+				t.Fatalf("account %d: transfer_in %d has balance_after %d, want %d", accountID, txn.id, txn.balanceAfter, running)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestTransfersReferenceTheirLegs(t *testing.T) {
+	refData, err := data.Load()
+	if err != nil {
+		t.Fatalf("failed to load reference data: %v", err)
+	}
+
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	rng := utils.NewRandom(13)
+	branches := NewBranchGenerator(rng.Fork(), refData, BranchGeneratorConfig{NumBranches: 3, BaseDate: asOf}).GenerateBranches()
+	customers := NewCustomerGenerator(rng.Fork(), refData, CustomerGeneratorConfig{
+		NumCustomers: 40, Branches: branches, BaseDate: asOf,
+	}).GenerateCustomers()
+	accounts, _ := NewAccountGenerator(rng.Fork(), refData, AccountGeneratorConfig{
+		Branches: branches, BaseDate: asOf,
+	}).GenerateAccountsForCustomers(customers, 1)
+
+	gen := NewTransactionGenerator(utils.NewRandom(42), refData, TransactionGeneratorConfig{
+		StartDate:                       asOf.AddDate(0, -3, 0),
+		EndDate:                         asOf,
+		TransactionsPerCustomerPerMonth: 20,
+		ParetoRatio:                     0.2,
+		DeclinedTransactionRate:         0.1,
+		Accounts:                        accounts,
+	})
+	var transfers []models.Transfer
+	gen.emitTransfer = func(tr models.Transfer) error {
+		transfers = append(transfers, tr)
+		return nil
+	}
+	txns, _ := gen.GenerateTransactionsForAccounts(accounts, 1)
+
+	byID := make(map[int64]models.Transaction, len(txns))
+	internal := 0
+	for _, gt := range txns {
+		txn := gt.Transaction
+		byID[txn.ID] = txn
+		if isTransferType(txn.Type) && txn.CounterpartyAccountID != nil && txn.LinkedTransactionID == nil &&
+			(txn.Status == models.TxStatusCompleted || txn.Status == models.TxStatusDeclined) {
+			internal++
+		}
+	}
+	if len(transfers) == 0 || len(transfers) != internal {
+		t.Fatalf("expected one transfer per internal transfer (%d), got %d", internal, len(transfers))
+	}
+
+	declined := 0
+	for _, tr := range transfers {
+		debit, ok := byID[tr.DebitTransactionID]
+		if !ok || debit.Type != models.TxTypeTransferOut || debit.AccountID != tr.FromAccountID {
+			t.Fatalf("transfer %d: debit leg %d is not a transfer out of account %d", tr.ID, tr.DebitTransactionID, tr.FromAccountID)
+		}
+		if debit.ReferenceNumber != tr.ReferenceNumber || debit.Amount != tr.Amount || debit.Status != tr.Status {
+			t.Errorf("transfer %d does not match its debit leg %d", tr.ID, debit.ID)
+		}
+		if tr.Status == models.TxStatusDeclined {
+			declined++
+			if tr.CreditTransactionID != nil {
+				t.Errorf("declined transfer %d has a credit leg", tr.ID)
+			}
+			continue
+		}
+		if tr.CreditTransactionID == nil {
+			t.Fatalf("completed transfer %d has no credit leg", tr.ID)
+		}
+		credit := byID[*tr.CreditTransactionID]
+		if credit.Type != models.TxTypeTransferIn || credit.AccountID != tr.ToAccountID || credit.ReferenceNumber != tr.ReferenceNumber {
+			t.Errorf("transfer %d: credit leg %d is not a transfer into account %d", tr.ID, credit.ID, tr.ToAccountID)
+		}
+	}
+	if declined == 0 {
+		t.Error("expected declined transfers")
+	}
+}
@@ -0,0 +1,266 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// Balance violation kinds
+const (
+	ViolationBalanceMismatch = "balance_mismatch" // balance_after differs from the recomputed running balance
+	ViolationLimitExceeded   = "limit_exceeded"   // running balance below the overdraft/credit limit
+	ViolationUnknownAccount  = "unknown_account"  // transaction references an account not in accounts.csv
+)
+
+// BalanceViolation describes one failed balance check
+type BalanceViolation struct {
+	Kind          string
+	AccountID     int64
+	TransactionID int64
+	Timestamp     time.Time
+	Expected      int64 // Recomputed balance, or the limit floor for limit_exceeded
+	Actual        int64 // balance_after from the file, or the running balance for limit_exceeded
+}
+
+func (v BalanceViolation) String() string {
+	switch v.Kind {
+	case ViolationLimitExceeded:
+		return fmt.Sprintf("account %d: txn %d at %s leaves balance %d below limit %d",
+			v.AccountID, v.TransactionID, FormatTime(v.Timestamp), v.Actual, v.Expected)
+	case ViolationUnknownAccount:
+		return fmt.Sprintf("account %d: txn %d references an unknown account", v.AccountID, v.TransactionID)
+	default:
+		return fmt.Sprintf("account %d: txn %d at %s has balance_after %d, expected %d",
+			v.AccountID, v.TransactionID, FormatTime(v.Timestamp), v.Actual, v.Expected)
+	}
+}
+
+// BalanceReport summarizes a balance verification pass
+type BalanceReport struct {
+	Accounts       int
+	Transactions   int64
+	ViolationCount int64
+	ByKind         map[string]int64   // Violation counts per kind
+	Violations     []BalanceViolation // First maxReported violations, ordered by account then time
+}
+
+// OK returns true if no violations were found
+func (r *BalanceReport) OK() bool {
+	return r.ViolationCount == 0
+}
+
+// verifyAccount holds the fields needed to replay an account's history
+type verifyAccount struct {
+	opening int64
+	floor   int64
+	limited bool // false for loans/mortgages, which carry no overdraft/credit limit
+}
+
+// verifyTxn is the compact per-row record kept in memory during verification
+type verifyTxn struct {
+	id           int64
+	ts           time.Time
+	delta        int64
+	balanceAfter int64
+}
+
+// VerifyBalances reads accounts and transactions back from outputDir and
+// replays each account's transactions in timestamp order, checking every
+// row's balance_after against the recomputed running balance and the
+// account's overdraft/credit limit. At most maxReported violations are kept.
+func VerifyBalances(ctx context.Context, outputDir string, maxReported int) (*BalanceReport, error) {
+	accounts, err := readVerifyAccounts(ctx, outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := findTableFiles(outputDir, "transactions")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BalanceReport{Accounts: len(accounts), ByKind: make(map[string]int64)}
+	record := func(v BalanceViolation) {
+		report.ViolationCount++
+		report.ByKind[v.Kind]++
+		if len(report.Violations) < maxReported {
+			report.Violations = append(report.Violations, v)
+		}
+	}
+
+	history := make(map[int64][]verifyTxn)
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		err := ReadCSVRows(ctx, file, []string{"id", "account_id", "type", "status", "amount", "balance_after", "timestamp"},
+			func(row []string) error {
+				t, accountID, err := parseVerifyTxn(row)
+				if err != nil {
+					return err
+				}
+				report.Transactions++
+				history[accountID] = append(history[accountID], t)
+				return nil
+			})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+
+	// Walk accounts in ID order so the reported violations are deterministic
+	accountIDs := make([]int64, 0, len(history))
+	for id := range history {
+		accountIDs = append(accountIDs, id)
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+	for _, accountID := range accountIDs {
+		txns := history[accountID]
+		acc, ok := accounts[accountID]
+		if !ok {
+			record(BalanceViolation{Kind: ViolationUnknownAccount, AccountID: accountID, TransactionID: txns[0].id})
+			continue
+		}
+
+		sort.Slice(txns, func(i, j int) bool {
+			if !txns[i].ts.Equal(txns[j].ts) {
+				return txns[i].ts.Before(txns[j].ts)
+			}
+			return txns[i].id < txns[j].id
+		})
+
+		running := acc.opening
+		for _, t := range txns {
+			running += t.delta
+			if t.balanceAfter != running {
+				record(BalanceViolation{
+					Kind: ViolationBalanceMismatch, AccountID: accountID, TransactionID: t.id,
+					Timestamp: t.ts, Expected: running, Actual: t.balanceAfter,
+				})
+				// Resync so one bad row doesn't flag the rest of the history
+				running = t.balanceAfter
+			}
+			if acc.limited && t.delta < 0 && running < acc.floor {
+				record(BalanceViolation{
+					Kind: ViolationLimitExceeded, AccountID: accountID, TransactionID: t.id,
+					Timestamp: t.ts, Expected: acc.floor, Actual: running,
+				})
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// parseVerifyTxn converts id, account_id, type, status, amount, balance_after, timestamp
+func parseVerifyTxn(row []string) (verifyTxn, int64, error) {
+	var t verifyTxn
+	var err error
+	if t.id, err = strconv.ParseInt(row[0], 10, 64); err != nil {
+		return t, 0, fmt.Errorf("invalid id %q: %w", row[0], err)
+	}
+	accountID, err := strconv.ParseInt(row[1], 10, 64)
+	if err != nil {
+		return t, 0, fmt.Errorf("txn %d: invalid account_id %q: %w", t.id, row[1], err)
+	}
+	amount, err := strconv.ParseInt(row[4], 10, 64)
+	if err != nil {
+		return t, 0, fmt.Errorf("txn %d: invalid amount %q: %w", t.id, row[4], err)
+	}
+	if t.balanceAfter, err = strconv.ParseInt(row[5], 10, 64); err != nil {
+		return t, 0, fmt.Errorf("txn %d: invalid balance_after %q: %w", t.id, row[5], err)
+	}
+	if t.ts, err = time.Parse("2006-01-02 15:04:05", row[6]); err != nil {
+		return t, 0, fmt.Errorf("txn %d: invalid timestamp %q: %w", t.id, row[6], err)
+	}
+
+	// Only completed transactions move the balance
+	if models.TransactionStatus(row[3]) == models.TxStatusCompleted {
+		if isDebitType(models.TransactionType(row[2])) {
+			t.delta = -amount
+		} else {
+			t.delta = amount
+		}
+	}
+	return t, accountID, nil
+}
+
+// readVerifyAccounts loads opening balances and limit floors from accounts.csv (or its shards)
+func readVerifyAccounts(ctx context.Context, outputDir string) (map[int64]verifyAccount, error) {
+	files, err := findTableFiles(outputDir, "accounts")
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[int64]verifyAccount)
+	for _, path := range files {
+		if err := readVerifyAccountFile(ctx, path, accounts); err != nil {
+			return nil, err
+		}
+	}
+	return accounts, nil
+}
+
+func readVerifyAccountFile(ctx context.Context, path string, accounts map[int64]verifyAccount) error {
+	err := ReadCSVRows(ctx, path, []string{"id", "type", "balance", "credit_limit", "overdraft_limit"},
+		func(row []string) error {
+			var vals [4]int64
+			for i, col := range []int{0, 2, 3, 4} {
+				v, err := strconv.ParseInt(row[col], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid value %q: %w", row[col], err)
+				}
+				vals[i] = v
+			}
+
+			acc := verifyAccount{opening: vals[1], limited: true}
+			switch models.AccountType(row[1]) {
+			case models.AccountTypeCreditCard:
+				acc.floor = -vals[2]
+			case models.AccountTypeLoan, models.AccountTypeMortgage:
+				acc.limited = false
+			default:
+				acc.floor = -vals[3]
+			}
+			accounts[vals[0]] = acc
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// findTableFiles returns a table's shard files, or its single CSV file
+func findTableFiles(dir, basename string) ([]string, error) {
+	files, err := FindShardedFiles(dir, basename)
+	if err != nil || len(files) > 0 {
+		return files, err
+	}
+	single, err := findCSVFile(dir, basename)
+	if err != nil {
+		return nil, err
+	}
+	return []string{single}, nil
+}
+
+// findCSVFile returns the path of basename.csv or basename.csv.xz in dir
+// or its per-table subdirectory
+func findCSVFile(dir, basename string) (string, error) {
+	dir = TableDir(dir, basename)
+	for _, ext := range []string{".csv", ".csv.xz"} {
+		path := filepath.Join(dir, basename+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no %s.csv or %s.csv.xz found in %s", basename, basename, dir)
+}
@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeVerifyFixture(t *testing.T, dir, name string, header []string, rows ...string) {
+	t.Helper()
+	content := strings.Join(header, ",") + "\n" + strings.Join(rows, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// verifyTxnRow builds a transactions.csv row with only the columns VerifyBalances reads
+func verifyTxnRow(id, accountID, txnType, status, amount, balanceAfter, ts string) string {
+	row := make([]string, len(TransactionHeaders()))
+	for i, h := range TransactionHeaders() {
+		switch h {
+		case "id":
+			row[i] = id
+		case "account_id":
+			row[i] = accountID
+		case "type":
+			row[i] = txnType
+		case "status":
+			row[i] = status
+		case "amount":
+			row[i] = amount
+		case "balance_after":
+			row[i] = balanceAfter
+		case "timestamp":
+			row[i] = ts
+		}
+	}
+	return strings.Join(row, ",")
+}
+
+func TestVerifyBalances(t *testing.T) {
+	dir := t.TempDir()
+	accountHeader := []string{"id", "type", "balance", "credit_limit", "overdraft_limit"}
+	writeVerifyFixture(t, dir, "accounts.csv", accountHeader,
+		"1,checking,10000,0,5000",
+		"2,credit_card,-1000,2000,0",
+	)
+
+	t.Run("Consistent", func(t *testing.T) {
+		// Rows are shuffled across shards; verification sorts by timestamp
+		writeVerifyFixture(t, dir, "transactions_001.csv", TransactionHeaders(),
+			verifyTxnRow("3", "1", "purchase", "completed", "2500", "9500", "2024-01-03 09:00:00"),
+			verifyTxnRow("1", "1", "deposit", "completed", "2000", "12000", "2024-01-01 09:00:00"),
+		)
+		writeVerifyFixture(t, dir, "transactions_002.csv", TransactionHeaders(),
+			verifyTxnRow("2", "1", "withdrawal", "declined", "0", "12000", "2024-01-02 09:00:00"),
+			verifyTxnRow("4", "2", "purchase", "completed", "500", "-1500", "2024-01-01 10:00:00"),
+		)
+
+		report, err := VerifyBalances(context.Background(), dir, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !report.OK() {
+			t.Fatalf("expected no violations, got %v", report.Violations)
+		}
+		if report.Transactions != 4 || report.Accounts != 2 {
+			t.Errorf("expected 4 transactions across 2 accounts, got %d/%d", report.Transactions, report.Accounts)
+		}
+	})
+
+	t.Run("Violations", func(t *testing.T) {
+		writeVerifyFixture(t, dir, "transactions_001.csv", TransactionHeaders(),
+			verifyTxnRow("1", "1", "deposit", "completed", "2000", "11000", "2024-01-01 09:00:00"),
+			verifyTxnRow("2", "1", "purchase", "completed", "1000", "10000", "2024-01-02 09:00:00"),
+			verifyTxnRow("3", "2", "purchase", "completed", "1500", "-2500", "2024-01-01 10:00:00"),
+			verifyTxnRow("4", "9", "deposit", "completed", "100", "100", "2024-01-01 10:00:00"),
+		)
+		os.Remove(filepath.Join(dir, "transactions_002.csv"))
+
+		report, err := VerifyBalances(context.Background(), dir, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Row 1 mismatches, row 2 is checked against the resynced balance
+		if report.ByKind[ViolationBalanceMismatch] != 1 {
+			t.Errorf("expected 1 balance mismatch, got %d", report.ByKind[ViolationBalanceMismatch])
+		}
+		if report.ByKind[ViolationLimitExceeded] != 1 {
+			t.Errorf("expected credit limit violation, got %d", report.ByKind[ViolationLimitExceeded])
+		}
+		if report.ByKind[ViolationUnknownAccount] != 1 {
+			t.Errorf("expected unknown account violation, got %d", report.ByKind[ViolationUnknownAccount])
+		}
+		if report.ViolationCount != 3 || len(report.Violations) != 2 {
+			t.Errorf("expected 3 violations with 2 reported, got %d/%d", report.ViolationCount, len(report.Violations))
+		}
+		if v := report.Violations[0]; v.AccountID != 1 || v.TransactionID != 1 || v.Expected != 12000 || v.Actual != 11000 {
+			t.Errorf("unexpected first violation: %+v", v)
+		}
+	})
+}
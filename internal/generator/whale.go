@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"slices"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+// whaleAccountTypes are the account types whale accounts are chosen from,
+// most preferred first
+var whaleAccountTypes = []models.AccountType{
+	models.AccountTypeMerchant, models.AccountTypeBusiness, models.AccountTypeChecking,
+}
+
+// SelectWhaleAccounts picks n accounts to generate outsized volume: merchant
+// accounts first, then business and checking accounts, spread evenly over
+// each type by ID. The pick depends only on the accounts, so a continuation
+// of the same data set selects the same whales. Returns sorted IDs.
+func SelectWhaleAccounts(accounts []GeneratedAccount, n int) []int64 {
+	if n <= 0 {
+		return nil
+	}
+	var whales []int64
+	for _, accountType := range whaleAccountTypes {
+		var ids []int64
+		for _, acc := range accounts {
+			if acc.Account.Type == accountType && acc.Account.Status != models.AccountStatusClosed {
+				ids = append(ids, acc.Account.ID)
+			}
+		}
+		slices.Sort(ids)
+		want := min(n-len(whales), len(ids))
+		for i := 0; i < want; i++ {
+			whales = append(whales, ids[i*len(ids)/want])
+		}
+		if len(whales) == n {
+			break
+		}
+	}
+	slices.Sort(whales)
+	return whales
+}
+
+// whaleSet indexes whale account IDs (nil when there are none)
+func whaleSet(ids []int64) map[int64]bool {
+	if len(ids) == 0 {
+		return nil
+	}
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
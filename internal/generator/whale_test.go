@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+)
+
+func TestSelectWhaleAccounts(t *testing.T) {
+	var accounts []GeneratedAccount
+	for id := int64(1); id <= 12; id++ {
+		accountType := models.AccountTypeChecking
+		switch {
+		case id <= 4:
+			accountType = models.AccountTypeMerchant
+		case id <= 6:
+			accountType = models.AccountTypeBusiness
+		}
+		accounts = append(accounts, GeneratedAccount{Account: models.Account{ID: id, Type: accountType, Status: models.AccountStatusActive}})
+	}
+	accounts[1].Account.Status = models.AccountStatusClosed
+
+	if got := SelectWhaleAccounts(accounts, 2); !slices.Equal(got, []int64{1, 3}) {
+		t.Errorf("2 whales = %v, expected merchant accounts [1 3]", got)
+	}
+	if got := SelectWhaleAccounts(accounts, 5); !slices.Equal(got, []int64{1, 3, 4, 5, 6}) {
+		t.Errorf("5 whales = %v, expected the open merchant and business accounts", got)
+	}
+	if got := SelectWhaleAccounts(accounts, 0); got != nil {
+		t.Errorf("0 whales = %v", got)
+	}
+}
+
+func TestWhaleAccountVolume(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestTransactionGenerator(t, start, start.AddDate(0, 1, 0))
+	g.whales = whaleSet([]int64{2})
+	g.settings.WhaleMultiplier = 100
+
+	account := func(id int64) GeneratedAccount {
+		return GeneratedAccount{
+			Account:  models.Account{ID: id, Type: models.AccountTypeMerchant},
+			Customer: GeneratedCustomer{Customer: models.Customer{ActivityScore: 1}},
+		}
+	}
+	normal, whale := g.calculateMonthlyTransactionCount(account(1), start), g.calculateMonthlyTransactionCount(account(2), start)
+	if whale < 50*normal {
+		t.Errorf("whale account gets %d transactions a month, normal %d", whale, normal)
+	}
+}
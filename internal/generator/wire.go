@@ -0,0 +1,299 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/willfong/load-generator/internal/data"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+// wireBank is a bank that sends or receives wires
+type wireBank struct {
+	name string
+	bic  string // SWIFT/BIC code; characters 5-6 are the country
+}
+
+// wireBanks lists well-known banks per country. Countries without an entry
+// get a national bank with a generated BIC (see bankForCountry).
+var wireBanks = map[string][]wireBank{
+	"US": {{"Chase Bank", "CHASUS33"}, {"Bank of America", "BOFAUS3N"}, {"Wells Fargo", "WFBIUS6S"}, {"Citibank", "CITIUS33"}},
+	"CA": {{"Royal Bank of Canada", "ROYCCAT2"}, {"TD Canada Trust", "TDOMCATT"}},
+	"GB": {{"HSBC", "HSBCGB2L"}, {"Barclays", "BARCGB22"}, {"Lloyds Bank", "LOYDGB2L"}},
+	"IE": {{"AIB", "AIBKIE2D"}, {"Bank of Ireland", "BOFIIE2D"}},
+	"DE": {{"Deutsche Bank", "DEUTDEFF"}, {"Commerzbank", "COBADEFF"}},
+	"FR": {{"BNP Paribas", "BNPAFRPP"}, {"Societe Generale", "SOGEFRPP"}},
+	"NL": {{"ING", "INGBNL2A"}, {"ABN AMRO", "ABNANL2A"}},
+	"CH": {{"UBS", "UBSWCHZH"}},
+	"ES": {{"Santander", "BSCHESMM"}, {"BBVA", "BBVAESMM"}},
+	"IT": {{"UniCredit", "UNCRITMM"}, {"Intesa Sanpaolo", "BCITITMM"}},
+	"JP": {{"MUFG Bank", "BOTKJPJT"}, {"Mizuho Bank", "MHCBJPJT"}},
+	"CN": {{"Bank of China", "BKCHCNBJ"}, {"ICBC", "ICBKCNBJ"}},
+	"IN": {{"State Bank of India", "SBININBB"}, {"HDFC Bank", "HDFCINBB"}},
+	"SG": {{"DBS Bank", "DBSSSGSG"}, {"OCBC Bank", "OCBCSGSG"}},
+	"HK": {{"HSBC Hong Kong", "HSBCHKHH"}, {"Bank of China (Hong Kong)", "BKCHHKHH"}},
+	"AU": {{"Commonwealth Bank", "CTBAAU2S"}, {"National Australia Bank", "NATAAU33"}},
+	"BR": {{"Banco do Brasil", "BRASBRRJ"}, {"Itau Unibanco", "ITAUBRSP"}},
+	"MX": {{"Banamex", "BNMXMXMM"}, {"BBVA Mexico", "BCMRMXMM"}},
+}
+
+// correspondentBICs are the banks that settle international wires in each
+// currency. Currencies without an entry settle through USD correspondents.
+var correspondentBICs = map[models.Currency][]string{
+	models.CurrencyUSD: {"CITIUS33", "CHASUS33", "BOFAUS3N"},
+	models.CurrencyEUR: {"DEUTDEFF", "BNPAFRPP", "INGBNL2A"},
+	models.CurrencyGBP: {"BARCGB22", "HSBCGB2L"},
+	models.CurrencyJPY: {"BOTKJPJT", "MHCBJPJT"},
+	models.CurrencyCHF: {"UBSWCHZH"},
+	models.CurrencyCAD: {"ROYCCAT2"},
+	models.CurrencyAUD: {"CTBAAU2S"},
+	models.CurrencyCNY: {"BKCHCNBJ"},
+	models.CurrencySGD: {"DBSSSGSG"},
+	models.CurrencyHKD: {"HSBCHKHH"},
+}
+
+// usdRates are approximate mid-market units of each currency per US dollar.
+// Currencies without a rate are treated as pegged 1:1.
+var usdRates = map[models.Currency]float64{
+	"USD": 1, "EUR": 0.92, "GBP": 0.79, "JPY": 150, "CHF": 0.88, "CAD": 1.36, "AUD": 1.52,
+	"INR": 83, "CNY": 7.2, "SGD": 1.34, "HKD": 7.8, "BRL": 5.0, "MXN": 17, "PLN": 4.0,
+	"CZK": 23, "HUF": 360, "RON": 4.6, "SEK": 10.5, "NOK": 10.6, "DKK": 6.9, "AED": 3.67,
+	"SAR": 3.75, "QAR": 3.64, "ILS": 3.7, "TRY": 32, "PKR": 280, "BDT": 110, "LKR": 300,
+	"KRW": 1350, "TWD": 32, "THB": 36, "MYR": 4.7, "IDR": 15800, "PHP": 56, "VND": 25000,
+	"ARS": 850, "COP": 3900, "CLP": 950, "ZAR": 18.5, "NGN": 1500, "KES": 130, "EGP": 48,
+	"MAD": 10, "GHS": 13, "NZD": 1.65,
+}
+
+// fxSpread is the most a wire's FX rate strays from the mid-market rate
+const fxSpread = 0.005
+
+// bankForCountry picks a bank in the country, inventing a national bank with
+// a BIC of the right shape when none is listed
+func bankForCountry(rng *utils.Random, country *data.Country) wireBank {
+	if banks := wireBanks[country.Code]; len(banks) > 0 {
+		return banks[rng.IntN(len(banks))]
+	}
+	prefix := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return -1
+	}, strings.ToUpper(country.Name)) + "XXX"
+	return wireBank{
+		name: country.Name + " National Bank",
+		bic:  fmt.Sprintf("%sN%s%c%c", prefix[:3], country.Code, rng.Letter(), rng.Digit()),
+	}
+}
+
+// ownBIC is the generated bank's BIC in a country, matching the GBNK bank
+// code of internal beneficiaries
+func ownBIC(countryCode string) string {
+	return "GBNK" + countryCode + "22"
+}
+
+// fxRate returns the mid-market rate converting from into to
+func fxRate(from, to models.Currency) float64 {
+	fromRate, ok := usdRates[from]
+	if !ok {
+		fromRate = 1
+	}
+	toRate, ok := usdRates[to]
+	if !ok {
+		toRate = 1
+	}
+	return toRate / fromRate
+}
+
+// WireBeneficiary is a beneficiary paid by international wire
+type WireBeneficiary struct {
+	ID       int64
+	BIC      string
+	Country  string
+	Currency models.Currency
+}
+
+// WireBeneficiariesByCustomer indexes the beneficiaries with the wire
+// payment method by the customer who added them
+func WireBeneficiariesByCustomer(beneficiaries []GeneratedBeneficiary) map[int64][]WireBeneficiary {
+	index := make(map[int64][]WireBeneficiary)
+	for _, gb := range beneficiaries {
+		b := gb.Beneficiary
+		if b.PaymentMethod != "wire" {
+			continue
+		}
+		index[b.CustomerID] = append(index[b.CustomerID], WireBeneficiary{
+			ID:       b.ID,
+			BIC:      b.BankCode,
+			Country:  b.Country,
+			Currency: b.Currency,
+		})
+	}
+	return index
+}
+
+// wireTransfer holds the wire-specific details of a transaction
+type wireTransfer struct {
+	beneficiary *WireBeneficiary // International wires to one of the customer's beneficiaries
+	currency    models.Currency  // Currency the payee's bank holds (international wires)
+
+	International    bool    `json:"international"`
+	UETR             string  `json:"uetr"` // Unique end-to-end transaction reference
+	OrderingBIC      string  `json:"ordering_bic"`
+	BeneficiaryBIC   string  `json:"beneficiary_bic"`
+	CorrespondentBIC string  `json:"correspondent_bic,omitempty"`
+	Charges          string  `json:"charges"` // SWIFT charge bearer: OUR, SHA or BEN
+	FX               *wireFX `json:"fx,omitempty"`
+}
+
+// wireFX is the currency conversion on an international wire
+type wireFX struct {
+	From   models.Currency `json:"from"`
+	To     models.Currency `json:"to"`
+	Rate   float64         `json:"rate"`
+	Amount int64           `json:"amount"` // Converted amount, in minor units of To
+}
+
+// wireCharges are the SWIFT charge bearer options, most common first
+var wireCharges = []string{"SHA", "OUR", "BEN"}
+
+// wireTransfer draws the wire details of a transaction. At
+// InternationalWireRate, outgoing wires to another bank go abroad: to one of
+// the customer's wire beneficiaries if they have any, otherwise to a bank in
+// a country drawn by weight. Wires between the customer's own accounts stay
+// within the bank.
+func (g *transactionCore) wireTransfer(txnType models.TransactionType, account GeneratedAccount, ownAccount bool) *wireTransfer {
+	country := account.Customer.Country
+	if country == nil {
+		country = &data.Country{Code: "US", Name: "United States"}
+	}
+	w := &wireTransfer{
+		UETR:        g.uetr(),
+		OrderingBIC: ownBIC(country.Code),
+		Charges:     wireCharges[g.rng.WeightedPick([]int{70, 20, 10})],
+	}
+
+	switch {
+	case ownAccount:
+		w.BeneficiaryBIC = w.OrderingBIC
+		return w
+	case !isDebitType(txnType):
+		// Incoming wires are sent by a domestic bank
+		w.OrderingBIC, w.BeneficiaryBIC = bankForCountry(g.rng, country).bic, w.OrderingBIC
+		return w
+	case !g.rng.Probability(g.settings.InternationalWireRate):
+		// Domestic wires settle directly between the two banks
+		w.BeneficiaryBIC = bankForCountry(g.rng, country).bic
+		return w
+	}
+
+	if payees := g.settings.WireBeneficiaries[account.Account.CustomerID]; len(payees) > 0 {
+		payee := payees[g.rng.IntN(len(payees))]
+		w.beneficiary = &payee
+		w.BeneficiaryBIC = payee.BIC
+		w.currency = payee.Currency
+	} else {
+		abroad := g.refData.CountryByWeight(g.rng.IntRange(1, g.refData.TotalWeight()))
+		if abroad == nil || abroad.Code == country.Code {
+			w.BeneficiaryBIC = bankForCountry(g.rng, country).bic
+			return w
+		}
+		w.BeneficiaryBIC = bankForCountry(g.rng, abroad).bic
+		w.currency = models.Currency(abroad.Currency)
+	}
+	w.International = true
+	correspondents, ok := correspondentBICs[w.currency]
+	if !ok {
+		correspondents = correspondentBICs[models.CurrencyUSD]
+	}
+	w.CorrespondentBIC = correspondents[g.rng.IntN(len(correspondents))]
+	return w
+}
+
+// convert records the FX on an international wire of amount (in the
+// account's currency) to a payee holding another currency. With ownMinorUnits
+// the converted amount is in the payee currency's minor units, otherwise in
+// hundredths like amount.
+func (w *wireTransfer) convert(rng *utils.Random, from models.Currency, amount int64, ownMinorUnits bool) {
+	if !w.International || w.currency == from || amount == 0 {
+		return
+	}
+	rate := fxRate(from, w.currency) * (1 + fxSpread*(2*rng.Float64()-1))
+	rate = math.Round(rate*1e6) / 1e6
+	converted := float64(amount) * rate
+	if ownMinorUnits {
+		converted *= math.Pow10(minorUnits(w.currency) - minorUnits(from))
+	}
+	w.FX = &wireFX{
+		From:   from,
+		To:     w.currency,
+		Rate:   rate,
+		Amount: int64(math.Round(converted)),
+	}
+}
+
+// metadata returns the transaction metadata JSON for the wire
+func (w *wireTransfer) metadata() string {
+	out, err := json.Marshal(map[string]*wireTransfer{"wire": w})
+	if err != nil {
+		return "{}"
+	}
+	return string(out)
+}
+
+// uetr returns a random version 4 UUID, the format SWIFT gpi uses for
+// end-to-end references
+func (g *transactionCore) uetr() string {
+	b := make([]byte, 16)
+	for i := 0; i < 16; i += 8 {
+		v := g.rng.Int64N(math.MaxInt64)
+		for j := 0; j < 8; j++ {
+			b[i+j] = byte(v >> (8 * j))
+		}
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// wireFee charges the fee schedule's wire fee (or international wire fee)
+// on a completed outgoing wire. Returns false when no fee is due.
+func (g *transactionCore) wireFee(
+	account GeneratedAccount,
+	balances map[int64]int64,
+	wire models.Transaction,
+	w *wireTransfer,
+) (models.Transaction, bool) {
+	if w == nil || wire.Status != models.TxStatusCompleted || wire.Amount == 0 || !isDebitType(wire.Type) {
+		return models.Transaction{}, false
+	}
+	feeType := FeeWire
+	if w.International {
+		feeType = FeeInternationalWire
+	}
+	rate := g.feeRate(feeType)
+	fee := rate.Amount(g.rng)
+
+	balances[account.Account.ID] -= fee
+	id := g.nextID()
+	linkedID := wire.ID
+	return models.Transaction{
+		ID:                  id,
+		ReferenceNumber:     g.generateReferenceNumber(id, wire.Timestamp),
+		AccountID:           account.Account.ID,
+		Type:                models.TxTypeFee,
+		Status:              models.TxStatusCompleted,
+		Channel:             models.ChannelInternal,
+		Amount:              fee,
+		Currency:            account.Account.Currency,
+		BalanceAfter:        balances[account.Account.ID],
+		Description:         rate.Description,
+		Metadata:            "{}",
+		LinkedTransactionID: &linkedID,
+		Timestamp:           wire.Timestamp,
+		PostedAt:            wire.PostedAt,
+		ValueDate:           wire.ValueDate,
+	}, true
+}
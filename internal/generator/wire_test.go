@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestInternationalWires(t *testing.T) {
+	g := newTestTransactionGenerator(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+	g.typePickers = typePickersByType(map[models.AccountType][]TransactionTypeWeight{
+		models.AccountTypeChecking: {{models.TxTypeTransferOut, models.ChannelWire, 1}},
+	})
+	payee := WireBeneficiary{ID: 70, BIC: "DEUTDEFF", Country: "DE", Currency: models.CurrencyEUR}
+	g.settings.WireBeneficiaries = map[int64][]WireBeneficiary{7: {payee}}
+	g.settings.InternationalWireRate = 1
+
+	us, ok := g.refData.GetCountry("US")
+	if !ok {
+		t.Fatal("US missing from reference data")
+	}
+	account := GeneratedAccount{Account: models.Account{
+		ID:         1,
+		CustomerID: 7,
+		Type:       models.AccountTypeChecking,
+		Currency:   models.CurrencyUSD,
+		Balance:    1_000_000_000,
+		OpenedAt:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, Customer: GeneratedCustomer{Customer: models.Customer{ID: 7, ActivityScore: 1}, Country: us}}
+	txns, _ := g.GenerateTransactionsForAccounts([]GeneratedAccount{account}, 1)
+
+	fees := make(map[int64]models.Transaction)
+	for _, gt := range txns {
+		if txn := gt.Transaction; txn.Type == models.TxTypeFee && txn.LinkedTransactionID != nil {
+			fees[*txn.LinkedTransactionID] = txn
+		}
+	}
+
+	wires := 0
+	for _, gt := range txns {
+		txn := gt.Transaction
+		if txn.Channel != models.ChannelWire || txn.Status != models.TxStatusCompleted {
+			continue
+		}
+		wires++
+		var metadata struct {
+			Wire struct {
+				International    bool    `json:"international"`
+				UETR             string  `json:"uetr"`
+				OrderingBIC      string  `json:"ordering_bic"`
+				BeneficiaryBIC   string  `json:"beneficiary_bic"`
+				CorrespondentBIC string  `json:"correspondent_bic"`
+				FX               *wireFX `json:"fx"`
+			} `json:"wire"`
+		}
+		if err := json.Unmarshal([]byte(txn.Metadata), &metadata); err != nil {
+			t.Fatalf("wire %d metadata %q: %v", txn.ID, txn.Metadata, err)
+		}
+		w := metadata.Wire
+		if !w.International || txn.BeneficiaryID == nil || *txn.BeneficiaryID != payee.ID {
+			t.Fatalf("wire %d is not an international wire to the beneficiary: %s", txn.ID, txn.Metadata)
+		}
+		if w.OrderingBIC != "GBNKUS22" || w.BeneficiaryBIC != payee.BIC || len(w.UETR) != 36 {
+			t.Errorf("wire %d has unexpected routing: %s", txn.ID, txn.Metadata)
+		}
+		if w.CorrespondentBIC == "" {
+			t.Errorf("wire %d has no correspondent bank", txn.ID)
+		}
+		if w.FX == nil || w.FX.From != models.CurrencyUSD || w.FX.To != models.CurrencyEUR {
+			t.Fatalf("wire %d has no USD to EUR conversion: %s", txn.ID, txn.Metadata)
+		}
+		if mid := fxRate(models.CurrencyUSD, models.CurrencyEUR); math.Abs(w.FX.Rate/mid-1) > fxSpread+1e-6 {
+			t.Errorf("wire %d rate %g is too far from %g", txn.ID, w.FX.Rate, mid)
+		}
+		if want := int64(math.Round(float64(txn.Amount) * w.FX.Rate)); w.FX.Amount != want {
+			t.Errorf("wire %d converted %d to %d, expected %d", txn.ID, txn.Amount, w.FX.Amount, want)
+		}
+
+		fee, ok := fees[txn.ID]
+		rate := DefaultFeeSchedule()[FeeInternationalWire]
+		if !ok || fee.Description != rate.Description || fee.Amount < rate.MinCents || fee.Amount > rate.MaxCents {
+			t.Errorf("wire %d was not charged the international wire fee: %+v", txn.ID, fee)
+		}
+	}
+	if wires == 0 {
+		t.Fatal("no completed wires generated")
+	}
+}
+
+func TestBankForCountry(t *testing.T) {
+	g := newTestTransactionGenerator(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	rng := utils.NewRandom(1)
+	for _, country := range g.refData.Countries.Countries {
+		bank := bankForCountry(rng, &country)
+		if len(bank.bic) != 8 || bank.bic[4:6] != country.Code {
+			t.Errorf("%s: BIC %q does not name the country", country.Code, bank.bic)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package generator
 
 import (
+	"context"
+	"errors"
 	"runtime"
+	"slices"
 	"sort"
 	"time"
 
+	"github.com/willfong/load-generator/internal/models"
 	"github.com/willfong/load-generator/internal/utils"
 )
 
@@ -25,12 +29,40 @@ type WorkerConfig struct {
 type WorkerResult struct {
 	WorkerID         int
 	TransactionCount int64
+	TransferCount    int64
+	DisputeCount     int64
 	AuditLogCount    int64
+	LastID           int64 // Highest transaction ID written
 	Duration         time.Duration
 	Error            error
 	ShardFile        string // Path to the shard file created
 }
 
+// GenerationPartitions is the fixed number of partitions customers are split
+// into for transaction and audit log generation. Each partition has its own
+// random stream and IDs and workers take whole partitions, so the rows
+// generated don't depend on the worker count. It also caps the worker count.
+const GenerationPartitions = 64
+
+// GenerationPartition is one partition's share of the work
+type GenerationPartition struct {
+	Accounts  []GeneratedAccount  // Accounts to generate transactions for
+	Customers []GeneratedCustomer // Customers to generate sessions for
+	RNG       *utils.Random       // The partition's own random stream
+	FirstID   int64               // ID of the partition's first row
+	IDStride  int64               // Step between its IDs (0 or 1 = consecutive)
+}
+
+// WorkerPartitions returns the partitions a worker generates: every
+// workerCount-th, starting at its own ID
+func WorkerPartitions(partitions []GenerationPartition, workerID, workerCount int) []GenerationPartition {
+	var assigned []GenerationPartition
+	for i := workerID; i < len(partitions); i += workerCount {
+		assigned = append(assigned, partitions[i])
+	}
+	return assigned
+}
+
 // IDRange represents a pre-allocated range of IDs for a worker
 type IDRange struct {
 	Start int64 // First ID (inclusive)
@@ -39,15 +71,13 @@ type IDRange struct {
 
 // GetWorkerCount returns the number of workers to use.
 // If configured workers is 0, auto-detects using runtime.NumCPU().
+// More workers than GenerationPartitions would have nothing to do.
 func GetWorkerCount(configured int) int {
-	if configured > 0 {
-		return configured
-	}
-	cpus := runtime.NumCPU()
-	if cpus < 1 {
-		return 1
+	workers := configured
+	if workers <= 0 {
+		workers = max(runtime.NumCPU(), 1)
 	}
-	return cpus
+	return min(workers, GenerationPartitions)
 }
 
 // PartitionAccountsByCustomer groups accounts by customer, then distributes
@@ -92,15 +122,53 @@ func PartitionAccountsByCustomer(accounts []GeneratedAccount, workerCount int) [
 	return workerAccounts
 }
 
+// PartitionCustomers distributes customers round-robin in ID order, as
+// PartitionAccountsByCustomer does with their accounts
+func PartitionCustomers(customers []GeneratedCustomer, n int) [][]GeneratedCustomer {
+	if n <= 0 {
+		n = 1
+	}
+	sorted := slices.Clone(customers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Customer.ID < sorted[j].Customer.ID
+	})
+
+	partitions := make([][]GeneratedCustomer, n)
+	for i, c := range sorted {
+		partitions[i%n] = append(partitions[i%n], c)
+	}
+	return partitions
+}
+
 // EstimateTransactionCount estimates the total number of transactions that will
-// be generated based on account count, years of history, and transactions per month.
-// Includes a buffer for counterparty transactions (internal transfers).
-func EstimateTransactionCount(accountCount int, yearsOfHistory int, txnsPerCustomerPerMonth int) int64 {
-	months := yearsOfHistory * 12
+// be generated for accounts over months of history from start, based on
+// transactions per month, held to each type's monthly cap and scaled down
+// for accounts still ramping up over their first rampUpMonths. Includes a
+// buffer for counterparty transactions (internal transfers) and the salary
+// credits payroll batches fan out into.
+func EstimateTransactionCount(accounts []GeneratedAccount, start time.Time, months int, txnsPerCustomerPerMonth int, caps MonthlyCaps, rampUpMonths int) int64 {
 	// Each account generates approximately txnsPerCustomerPerMonth transactions
-	// Add 50% buffer for counterparty transactions from internal transfers
-	baseCount := int64(accountCount) * int64(txnsPerCustomerPerMonth) * int64(months)
-	return int64(float64(baseCount) * 1.5)
+	// in a month of full activity. Add 50% buffer for counterparty
+	// transactions from internal transfers
+	var baseCount, payrollMonths float64
+	for _, acc := range accounts {
+		perMonth := txnsPerCustomerPerMonth
+		if limit, ok := caps[acc.Account.Type]; ok {
+			perMonth = min(perMonth, limit)
+		}
+		var activeMonths float64
+		for m := range months {
+			activeMonths += rampUpFactor(acc.Account.OpenedAt, start.AddDate(0, m, 0), rampUpMonths)
+		}
+		baseCount += float64(perMonth) * activeMonths
+		if acc.Account.Type == models.AccountTypePayroll {
+			payrollMonths += activeMonths
+		}
+	}
+
+	// About 4 payroll batches a month, each paying the account's whole workforce
+	payrollCredits := int64(payrollMonths * 4 * (payrollMinEmployees + payrollMaxEmployees) / 2)
+	return int64(baseCount*1.5) + payrollCredits
 }
 
 // CalculateIDRanges pre-allocates non-overlapping ID ranges for each worker.
@@ -131,8 +199,9 @@ func CalculateIDRanges(estimatedTotal int64, workerCount int) []IDRange {
 		}
 	}
 
-	// Last worker gets extra buffer for any overflow
-	ranges[workerCount-1].End = bufferedTotal + rangeSize
+	// Last worker gets extra buffer for any overflow (its range already ends
+	// past bufferedTotal when the minimum size applies)
+	ranges[workerCount-1].End = max(ranges[workerCount-1].End, bufferedTotal) + rangeSize
 
 	return ranges
 }
@@ -140,10 +209,26 @@ func CalculateIDRanges(estimatedTotal int64, workerCount int) []IDRange {
 // EstimateAuditLogCount estimates the total number of audit log entries
 // based on transaction count. Audit logs include login events, balance checks,
 // and transaction-related events.
-func EstimateAuditLogCount(transactionCount int64, customerCount int, yearsOfHistory int) int64 {
-	months := yearsOfHistory * 12
+func EstimateAuditLogCount(transactionCount int64, customerCount int, months int) int64 {
 	// Estimate: ~3 sessions per customer per month, ~4 events per session
 	sessionEvents := int64(customerCount) * int64(months) * 3 * 4
 	// Plus transaction-related audit events (roughly 1 per transaction)
 	return sessionEvents + transactionCount
 }
+
+// firstWorkerError drains a closed worker error channel and returns the most
+// useful error: a real failure is preferred over the context.Canceled errors
+// reported by workers that were stopped because of it.
+func firstWorkerError(errChan <-chan error) error {
+	var canceled error
+	for err := range errChan {
+		if errors.Is(err, context.Canceled) {
+			if canceled == nil {
+				canceled = err
+			}
+			continue
+		}
+		return err
+	}
+	return canceled
+}
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCalculateIDRangesDoNotOverlap(t *testing.T) {
+	// Small estimates hit the minimum range size
+	for _, estimate := range []int64{1000, 50_000_000} {
+		ranges := CalculateIDRanges(estimate, GenerationPartitions)
+		for i, r := range ranges {
+			if r.End <= r.Start {
+				t.Errorf("estimate %d: range %d is empty: %+v", estimate, i, r)
+			}
+			if i > 0 && r.Start < ranges[i-1].End {
+				t.Errorf("estimate %d: range %d overlaps the previous: %+v", estimate, i, r)
+			}
+		}
+	}
+}
+
+func TestWorkerPartitions(t *testing.T) {
+	partitions := make([]GenerationPartition, 10)
+	for i := range partitions {
+		partitions[i].FirstID = int64(i)
+	}
+
+	seen := make(map[int64]int)
+	for worker := 0; worker < 4; worker++ {
+		for _, p := range WorkerPartitions(partitions, worker, 4) {
+			if int(p.FirstID)%4 != worker {
+				t.Errorf("worker %d assigned partition %d", worker, p.FirstID)
+			}
+			seen[p.FirstID]++
+		}
+	}
+	if len(seen) != len(partitions) {
+		t.Errorf("expected every partition assigned once, got %v", seen)
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("partition %d assigned %d times", id, n)
+		}
+	}
+
+	if got := GetWorkerCount(GenerationPartitions * 2); got != GenerationPartitions {
+		t.Errorf("expected workers capped at %d, got %d", GenerationPartitions, got)
+	}
+}
+
+func TestFirstWorkerError(t *testing.T) {
+	// A real failure is reported over the cancellations it caused in other workers
+	failure := errors.New("disk full")
+	errChan := make(chan error, 3)
+	errChan <- fmt.Errorf("worker 0: %w", context.Canceled)
+	errChan <- fmt.Errorf("worker 1: %w", failure)
+	errChan <- fmt.Errorf("worker 2: %w", context.Canceled)
+	close(errChan)
+	if err := firstWorkerError(errChan); !errors.Is(err, failure) {
+		t.Errorf("firstWorkerError = %v, want the worker 1 failure", err)
+	}
+
+	errChan = make(chan error, 1)
+	errChan <- fmt.Errorf("worker 0: %w", context.Canceled)
+	close(errChan)
+	if err := firstWorkerError(errChan); !errors.Is(err, context.Canceled) {
+		t.Errorf("firstWorkerError = %v, want context.Canceled", err)
+	}
+}
@@ -12,10 +12,10 @@ import (
 // XZWriter streams data through the xz compressor to produce .xz files.
 // It spawns an external xz process and pipes data through stdin.
 type XZWriter struct {
-	file    *os.File       // Output .xz file
+	out     io.WriteCloser // Output .xz file or S3 upload
 	cmd     *exec.Cmd      // xz subprocess
 	stdin   io.WriteCloser // Pipe to xz stdin
-	path    string         // Full path to output file
+	path    string         // Full path (or s3:// URL) of the output
 	mu      sync.Mutex
 	closed  bool
 	waitErr error         // Error from xz process
@@ -28,13 +28,25 @@ type XZWriterConfig struct {
 	OutputDir string
 	// Filename without extension (e.g., "customers" -> "customers.csv.xz")
 	Filename string
-	// Compression preset 0-9 (default: 6). Higher = smaller but slower
+	// Compression preset 0-9 (default: 0, the fastest). Higher = smaller but slower
 	Preset int
+	// Upper estimate of an S3 upload's size in bytes (0 = unknown and small)
+	ExpectedSize int64
 }
 
 // NewXZWriter creates a streaming XZ compressor that pipes data through
 // the external xz command. The output file will have .csv.xz extension.
+// An s3:// OutputDir uploads the compressed stream instead of writing a file.
 func NewXZWriter(cfg XZWriterConfig) (*XZWriter, error) {
+	if IsS3Path(cfg.OutputDir) {
+		url := JoinOutputPath(cfg.OutputDir, cfg.Filename+".csv.xz")
+		upload, err := NewS3Writer(url, cfg.ExpectedSize)
+		if err != nil {
+			return nil, err
+		}
+		return newXZWriterTo(upload, url, cfg.Preset, func() {})
+	}
+
 	// Ensure output directory exists
 	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
@@ -46,9 +58,13 @@ func NewXZWriter(cfg XZWriterConfig) (*XZWriter, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file %s: %w", path, err)
 	}
+	return newXZWriterTo(file, path, cfg.Preset, func() { os.Remove(path) })
+}
 
-	// Set compression preset (default to 6 if not specified or invalid)
-	preset := cfg.Preset
+// newXZWriterTo starts xz with its compressed output going to out.
+// cleanup is called if xz cannot be started.
+func newXZWriterTo(out io.WriteCloser, path string, preset int, cleanup func()) (*XZWriter, error) {
+	// Fall back to xz's own default preset if out of range
 	if preset < 0 || preset > 9 {
 		preset = 6
 	}
@@ -56,27 +72,27 @@ func NewXZWriter(cfg XZWriterConfig) (*XZWriter, error) {
 	// Set up xz command: reads from stdin, writes to stdout
 	// -c = write to stdout, -<N> = compression level
 	cmd := exec.Command("xz", "-c", fmt.Sprintf("-%d", preset))
-	cmd.Stdout = file
+	cmd.Stdout = out
 	cmd.Stderr = os.Stderr // Surface xz errors to user
 
 	// Create stdin pipe
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		file.Close()
-		os.Remove(path)
+		out.Close()
+		cleanup()
 		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
 	}
 
 	// Start xz process
 	if err := cmd.Start(); err != nil {
 		stdin.Close()
-		file.Close()
-		os.Remove(path)
+		out.Close()
+		cleanup()
 		return nil, fmt.Errorf("failed to start xz: %w", err)
 	}
 
 	w := &XZWriter{
-		file:   file,
+		out:    out,
 		cmd:    cmd,
 		stdin:  stdin,
 		path:   path,
@@ -118,15 +134,15 @@ func (w *XZWriter) Close() error {
 
 	// Close stdin to signal EOF to xz
 	if err := w.stdin.Close(); err != nil {
-		w.file.Close()
+		w.out.Close()
 		return fmt.Errorf("failed to close xz stdin: %w", err)
 	}
 
 	// Wait for xz to finish processing all data
 	<-w.waitCh
 
-	// Close output file
-	fileErr := w.file.Close()
+	// Close output file (or finish the upload)
+	fileErr := w.out.Close()
 
 	// Return any errors (xz error takes precedence)
 	if w.waitErr != nil {
@@ -139,7 +155,7 @@ func (w *XZWriter) Close() error {
 	return nil
 }
 
-// Path returns the full path to the .xz file
+// Path returns the full path (or s3:// URL) of the .xz file
 func (w *XZWriter) Path() string {
 	return w.path
 }
@@ -49,6 +49,12 @@ const (
 	CurrencyMXN Currency = "MXN"
 )
 
+// SupportedCurrencies lists every currency accounts can be held in
+var SupportedCurrencies = []Currency{
+	CurrencyUSD, CurrencyEUR, CurrencyGBP, CurrencyJPY, CurrencyCHF, CurrencyCAD, CurrencyAUD,
+	CurrencyINR, CurrencyCNY, CurrencySGD, CurrencyHKD, CurrencyBRL, CurrencyMXN,
+}
+
 // Account represents a bank account
 type Account struct {
 	// Primary identifier
@@ -30,6 +30,13 @@ const (
 	AuditBeneficiaryAdded  AuditAction = "beneficiary_added"
 	AuditBeneficiaryRemoved AuditAction = "beneficiary_removed"
 
+	// Customer lifecycle actions
+	AuditCustomerSuspended AuditAction = "customer_suspended"
+	AuditCustomerClosed    AuditAction = "customer_closed"
+	AuditKYCSubmitted      AuditAction = "kyc_submitted"
+	AuditKYCVerified       AuditAction = "kyc_verified"
+	AuditKYCRejected       AuditAction = "kyc_rejected"
+
 	// Profile actions
 	AuditProfileViewed   AuditAction = "profile_viewed"
 	AuditProfileUpdated  AuditAction = "profile_updated"
@@ -125,6 +125,30 @@ type ATM struct {
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// ATMEventType represents an operational event on an ATM
+type ATMEventType string
+
+const (
+	ATMEventCashLow ATMEventType = "cash_low" // Cash fell below the low-water mark
+	ATMEventOffline ATMEventType = "offline"  // Stopped serving customers
+	ATMEventOnline  ATMEventType = "online"   // Back in service
+)
+
+// ATMEvent records an operational event for ops monitoring
+type ATMEvent struct {
+	ID    int64        `db:"id" json:"id"`
+	ATMID int64        `db:"atm_id" json:"atm_id"` // References atms.id
+	Type  ATMEventType `db:"type" json:"type"`
+
+	// Status the ATM is in after the event (out_of_cash, maintenance or offline when it goes down)
+	Status ATMStatus `db:"status" json:"status"`
+
+	// Remaining cash as a percentage of capacity
+	CashLevel int `db:"cash_level" json:"cash_level"`
+
+	Timestamp time.Time `db:"timestamp" json:"timestamp"`
+}
+
 // IsOperational returns true if the ATM can process transactions
 func (a *ATM) IsOperational() bool {
 	return a.Status == ATMStatusOnline
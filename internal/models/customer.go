@@ -25,6 +25,26 @@ const (
 	CustomerStatusClosed   CustomerStatus = "closed"
 )
 
+// KYCStatus is where a customer stands in know-your-customer verification
+type KYCStatus string
+
+const (
+	KYCStatusUnverified KYCStatus = "unverified" // No documents submitted
+	KYCStatusPending    KYCStatus = "pending"    // Documents under review
+	KYCStatusVerified   KYCStatus = "verified"
+	KYCStatusRejected   KYCStatus = "rejected"
+)
+
+// KYCDocumentType is the identity document a customer submitted for verification
+type KYCDocumentType string
+
+const (
+	KYCDocumentPassport        KYCDocumentType = "passport"
+	KYCDocumentNationalID      KYCDocumentType = "national_id"
+	KYCDocumentDriversLicense  KYCDocumentType = "drivers_license"
+	KYCDocumentResidencePermit KYCDocumentType = "residence_permit"
+)
+
 // Customer represents a bank customer with all their personal information
 type Customer struct {
 	// Primary identifier
@@ -62,6 +82,24 @@ type Customer struct {
 	// Metadata
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	// Know-your-customer verification (nil unless generated with KYC)
+	KYCStatus       *KYCStatus       `db:"kyc_status" json:"kyc_status"`
+	KYCDocumentType *KYCDocumentType `db:"kyc_document_type" json:"kyc_document_type"` // nil if never submitted
+	KYCVerifiedAt   *time.Time       `db:"kyc_verified_at" json:"kyc_verified_at"`     // Set once verified
+}
+
+// Device is a phone or browser a customer uses for digital banking.
+// Audit events on the mobile and online channels carry its user agent.
+type Device struct {
+	Channel   AuditChannel `json:"channel"` // mobile or online
+	UserAgent string       `json:"user_agent"`
+}
+
+// KYCVerified reports whether the customer has passed KYC verification.
+// Customers generated without KYC count as verified.
+func (c *Customer) KYCVerified() bool {
+	return c.KYCStatus == nil || *c.KYCStatus == KYCStatusVerified
 }
 
 // IsBusinessCustomer returns true if this is a business/corporate customer
@@ -105,6 +105,12 @@ type Transaction struct {
 
 	// Error info for failed/declined transactions
 	FailureReason *string `db:"failure_reason" json:"failure_reason"`
+
+	// Where the transaction was made: the ATM or branch, or near the customer's
+	// home for card and online payments (nil for ACH, wire and internal, and
+	// for every transaction unless generated with GeoCoordinates)
+	Latitude  *float64 `db:"latitude" json:"latitude"`
+	Longitude *float64 `db:"longitude" json:"longitude"`
 }
 
 // IsCredit returns true if this transaction adds money to the account
@@ -136,3 +142,68 @@ func (t *Transaction) SignedAmount() int64 {
 func (t *Transaction) IsInternal() bool {
 	return t.CounterpartyAccountID != nil
 }
+
+// Transfer is one internal transfer between two accounts, normalized from
+// its double-entry transaction legs
+type Transfer struct {
+	// The originating leg's transaction ID, which its reference number embeds
+	ID              int64  `db:"id" json:"id"`
+	ReferenceNumber string `db:"reference_number" json:"reference_number"`
+
+	FromAccountID int64 `db:"from_account_id" json:"from_account_id"`
+	ToAccountID   int64 `db:"to_account_id" json:"to_account_id"`
+
+	Amount   int64             `db:"amount" json:"amount"`
+	Currency Currency          `db:"currency" json:"currency"`
+	Status   TransactionStatus `db:"status" json:"status"`
+
+	// The from account's debit leg and the to account's credit leg (nil when
+	// the transfer was declined and never reached the to account)
+	DebitTransactionID  int64  `db:"debit_transaction_id" json:"debit_transaction_id"`
+	CreditTransactionID *int64 `db:"credit_transaction_id" json:"credit_transaction_id"`
+
+	Timestamp time.Time `db:"timestamp" json:"timestamp"`
+}
+
+// DisputeReason is why a customer disputed a purchase
+type DisputeReason string
+
+const (
+	DisputeFraud           DisputeReason = "fraud"
+	DisputeNotReceived     DisputeReason = "not_received"
+	DisputeNotAsDescribed  DisputeReason = "not_as_described"
+	DisputeDuplicate       DisputeReason = "duplicate"
+	DisputeIncorrectAmount DisputeReason = "incorrect_amount"
+)
+
+// DisputeStatus is a dispute case's stage in its lifecycle
+type DisputeStatus string
+
+const (
+	DisputeStatusFiled       DisputeStatus = "filed"
+	DisputeStatusUnderReview DisputeStatus = "under_review"
+	DisputeStatusWon         DisputeStatus = "won"  // Resolved in the customer's favor
+	DisputeStatusLost        DisputeStatus = "lost" // Resolved in the merchant's favor
+)
+
+// Dispute is a customer's dispute case against a completed purchase, with the
+// time it reached each stage of its lifecycle
+type Dispute struct {
+	// The disputed purchase's transaction ID
+	ID         int64  `db:"id" json:"id"`
+	CaseNumber string `db:"case_number" json:"case_number"`
+
+	TransactionID int64 `db:"transaction_id" json:"transaction_id"`
+	AccountID     int64 `db:"account_id" json:"account_id"`
+	CustomerID    int64 `db:"customer_id" json:"customer_id"`
+
+	Amount   int64         `db:"amount" json:"amount"`
+	Currency Currency      `db:"currency" json:"currency"`
+	Reason   DisputeReason `db:"reason" json:"reason"`
+	Status   DisputeStatus `db:"status" json:"status"`
+
+	// Stages not yet reached by the end of the history are nil
+	FiledAt         time.Time  `db:"filed_at" json:"filed_at"`
+	ReviewStartedAt *time.Time `db:"review_started_at" json:"review_started_at"`
+	ResolvedAt      *time.Time `db:"resolved_at" json:"resolved_at"`
+}
@@ -43,13 +43,19 @@ func NewActivityCalculator(activeStart, activeEnd int) *ActivityCalculator {
 	}
 }
 
-// SetSessionTypeRatios configures the session type distribution
+// SetSessionTypeRatios configures the base session type weights, which
+// GetRecommendedSessionType adjusts for the time of day
 func (ac *ActivityCalculator) SetSessionTypeRatios(atm, online, business float64) {
 	ac.atmSessionRatio = atm
 	ac.onlineSessionRatio = online
 	ac.businessSessionRatio = business
 }
 
+// SetClock sets the time source for activity calculations
+func (ac *ActivityCalculator) SetClock(clock *Clock) {
+	ac.timezone.SetClock(clock)
+}
+
 // SetPayrollBurst configures the multiplier for payroll day activity
 func (ac *ActivityCalculator) SetPayrollBurst(multiplier float64) {
 	ac.payrollBurst = multiplier
@@ -180,45 +186,50 @@ func (ac *ActivityCalculator) IsLunchHour(timezone string) bool {
 	return hour >= 12 && hour < 14
 }
 
+// Time-of-day boosts applied to the configured session weights. With the
+// default 30/50/20 mix they give 65% ATM over lunch and 80% online in the
+// morning.
+const (
+	lunchATMBoost      = 13.0 / 3 // ATM usage spikes for lunchtime cash withdrawals
+	morningOnlineBoost = 4.0      // People check accounts online before work
+)
+
 // GetRecommendedSessionType suggests a session type based on time of day
 // and customer segment. This provides more realistic session type distribution.
 func (ac *ActivityCalculator) GetRecommendedSessionType(customer *models.Customer, rng *utils.Random) SessionType {
-	hour := ac.timezone.GetLocalHour(customer.Timezone)
-	isLunch := hour >= 12 && hour < 14
-
 	// Business customers almost always do online banking, not ATM
 	if customer.Segment == "business" || customer.Segment == "corporate" {
 		return SessionTypeBusiness
 	}
 
-	// During lunch hours, ATM usage spikes for cash withdrawals
-	if isLunch {
-		r := rng.Float64()
-		if r < 0.5 { // 50% chance of ATM during lunch
-			return SessionTypeATM
-		}
-	}
+	atm, online, business := ac.sessionWeightsAt(ac.timezone.GetLocalHour(customer.Timezone))
+	return pickSessionType(rng, atm, online, business)
+}
 
-	// Morning hours: More online banking as people check accounts
+// sessionWeightsAt blends the configured session weights with the time-of-day
+// boosts for a local hour
+func (ac *ActivityCalculator) sessionWeightsAt(hour int) (atm, online, business float64) {
+	atm, online, business = ac.atmSessionRatio, ac.onlineSessionRatio, ac.businessSessionRatio
+	if hour >= 12 && hour < 14 {
+		atm *= lunchATMBoost
+	}
 	if hour >= 8 && hour < 10 {
-		r := rng.Float64()
-		if r < 0.6 { // 60% online during morning
-			return SessionTypeOnline
-		}
+		online *= morningOnlineBoost
 	}
-
-	// Default distribution
-	return ac.pickDefaultSessionType(rng)
+	return atm, online, business
 }
 
-// pickDefaultSessionType chooses session type based on configured ratios
-func (ac *ActivityCalculator) pickDefaultSessionType(rng *utils.Random) SessionType {
-	r := rng.Float64()
-	// Use cumulative probabilities based on configured ratios
-	if r < ac.atmSessionRatio {
+// pickSessionType chooses a session type in proportion to the weights
+func pickSessionType(rng *utils.Random, atm, online, business float64) SessionType {
+	total := atm + online + business
+	if total <= 0 {
+		return SessionTypeOnline
+	}
+	r := rng.Float64() * total
+	if r < atm {
 		return SessionTypeATM
 	}
-	if r < ac.atmSessionRatio+ac.onlineSessionRatio {
+	if r < atm+online {
 		return SessionTypeOnline
 	}
 	return SessionTypeBusiness
@@ -258,7 +269,7 @@ type GlobalActivitySnapshot struct {
 
 // GetGlobalActivitySnapshot returns current global activity state
 func (ac *ActivityCalculator) GetGlobalActivitySnapshot() GlobalActivitySnapshot {
-	now := time.Now()
+	now := ac.timezone.clock.Now()
 
 	// Check major regions for activity
 	regions := map[string]string{
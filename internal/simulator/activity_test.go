@@ -272,3 +272,44 @@ func TestActivityCalculator_GlobalActivitySnapshot(t *testing.T) {
 		}
 	}
 }
+
+func TestActivityCalculator_SessionWeightsAt(t *testing.T) {
+	ac := NewActivityCalculator(8, 16)
+
+	share := func(hour int) (atm, online, business float64) {
+		atm, online, business = ac.sessionWeightsAt(hour)
+		total := atm + online + business
+		return atm / total, online / total, business / total
+	}
+
+	// Default weights keep the lunch ATM spike and morning online peak
+	if atm, _, _ := share(12); atm < 0.64 || atm > 0.66 {
+		t.Errorf("expected 65%% ATM at lunch, got %.2f", atm)
+	}
+	if _, online, _ := share(9); online < 0.79 || online > 0.81 {
+		t.Errorf("expected 80%% online in the morning, got %.2f", online)
+	}
+	if atm, online, business := share(15); atm != 0.3 || online != 0.5 || business != 0.2 {
+		t.Errorf("expected base mix in the afternoon, got %.2f/%.2f/%.2f", atm, online, business)
+	}
+
+	// A mostly-online mix stays mostly online even over lunch
+	ac.SetSessionTypeRatios(0.05, 0.9, 0.05)
+	if _, online, _ := share(12); online < 0.75 {
+		t.Errorf("expected a mostly-online mix at lunch, got %.2f online", online)
+	}
+}
+
+func TestPickSessionType_Proportions(t *testing.T) {
+	rng := utils.NewRandom(7)
+	counts := make(map[SessionType]int)
+	for i := 0; i < 10000; i++ {
+		counts[pickSessionType(rng, 1, 8, 1)]++
+	}
+	if counts[SessionTypeOnline] < 7500 || counts[SessionTypeOnline] > 8500 {
+		t.Errorf("expected ~80%% online, got %d/10000", counts[SessionTypeOnline])
+	}
+	if counts[SessionTypeATM] == 0 || counts[SessionTypeBusiness] == 0 {
+		t.Errorf("expected some ATM and business sessions, got %v", counts)
+	}
+}
@@ -3,8 +3,6 @@ package simulator
 import (
 	"context"
 	"fmt"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/willfong/load-generator/internal/database"
@@ -13,35 +11,8 @@ import (
 
 // AuditWriter provides buffered, async writing of audit logs
 type AuditWriter struct {
-	pool *database.Pool
-
-	// Buffered channel for incoming audit logs
-	buffer chan *models.AuditLog
-
-	// Configuration
-	batchSize    int
-	flushInterval time.Duration
-	workers      int
-
-	// Lifecycle
-	ctx    context.Context
-	cancel context.CancelFunc
-	wg     sync.WaitGroup
-
-	// Statistics
-	stats AuditStats
-}
-
-// AuditStats tracks audit writing statistics
-type AuditStats struct {
-	logsReceived    atomic.Int64
-	logsWritten     atomic.Int64
-	batchesWritten  atomic.Int64
-	writeErrors     atomic.Int64
-	droppedLogs     atomic.Int64
-	lastFlushTime   atomic.Value // time.Time
-	avgBatchSize    atomic.Int64
-	totalBatches    atomic.Int64
+	pool    *database.Pool
+	batches *batcher[*models.AuditLog]
 }
 
 // AuditWriterConfig holds configuration for the audit writer
@@ -64,130 +35,29 @@ func DefaultAuditWriterConfig() AuditWriterConfig {
 
 // NewAuditWriter creates a new buffered audit writer
 func NewAuditWriter(pool *database.Pool, cfg AuditWriterConfig) *AuditWriter {
-	ctx, cancel := context.WithCancel(context.Background())
-
-	aw := &AuditWriter{
-		pool:          pool,
-		buffer:        make(chan *models.AuditLog, cfg.BufferSize),
-		batchSize:     cfg.BatchSize,
-		flushInterval: cfg.FlushInterval,
-		workers:       cfg.Workers,
-		ctx:           ctx,
-		cancel:        cancel,
-	}
-
-	aw.stats.lastFlushTime.Store(time.Now())
-
+	aw := &AuditWriter{pool: pool}
+	aw.batches = newBatcher(cfg, aw.writeBatch)
 	return aw
 }
 
 // Start begins the background write workers
 func (aw *AuditWriter) Start() {
-	for i := 0; i < aw.workers; i++ {
-		aw.wg.Add(1)
-		go aw.writeWorker(i)
-	}
+	aw.batches.start()
 }
 
 // Write queues an audit log for async writing
 // Returns immediately; the log will be written in the background
 func (aw *AuditWriter) Write(log *models.AuditLog) {
-	aw.stats.logsReceived.Add(1)
-
-	select {
-	case aw.buffer <- log:
-		// Successfully queued
-	default:
-		// Buffer full - drop the log and record it
-		aw.stats.droppedLogs.Add(1)
-	}
-}
-
-// writeWorker processes logs from the buffer and writes them in batches
-func (aw *AuditWriter) writeWorker(workerID int) {
-	defer aw.wg.Done()
-
-	batch := make([]*models.AuditLog, 0, aw.batchSize)
-	ticker := time.NewTicker(aw.flushInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case log := <-aw.buffer:
-			batch = append(batch, log)
-			if len(batch) >= aw.batchSize {
-				aw.writeBatch(batch)
-				batch = batch[:0]
-			}
-
-		case <-ticker.C:
-			// Periodic flush of incomplete batches
-			if len(batch) > 0 {
-				aw.writeBatch(batch)
-				batch = batch[:0]
-			}
-
-		case <-aw.ctx.Done():
-			// Drain remaining buffer on shutdown
-			aw.drainBuffer(batch)
-			return
-		}
-	}
-}
-
-// drainBuffer writes all remaining logs during shutdown
-func (aw *AuditWriter) drainBuffer(currentBatch []*models.AuditLog) {
-	// First write any current batch
-	if len(currentBatch) > 0 {
-		aw.writeBatch(currentBatch)
-	}
-
-	// Then drain the channel
-	batch := make([]*models.AuditLog, 0, aw.batchSize)
-	for {
-		select {
-		case log := <-aw.buffer:
-			batch = append(batch, log)
-			if len(batch) >= aw.batchSize {
-				aw.writeBatch(batch)
-				batch = batch[:0]
-			}
-		default:
-			// Channel empty
-			if len(batch) > 0 {
-				aw.writeBatch(batch)
-			}
-			return
-		}
-	}
+	aw.batches.write(log)
 }
 
 // writeBatch performs a bulk insert of audit logs
-func (aw *AuditWriter) writeBatch(batch []*models.AuditLog) {
-	if len(batch) == 0 {
-		return
-	}
-
+func (aw *AuditWriter) writeBatch(batch []*models.AuditLog) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	err := aw.batchInsert(ctx, batch)
-	if err != nil {
-		aw.stats.writeErrors.Add(1)
-		// Optionally log the error, but don't block
-		// Individual log insert fallback could be added here
-		return
-	}
-
-	aw.stats.logsWritten.Add(int64(len(batch)))
-	aw.stats.batchesWritten.Add(1)
-	aw.stats.lastFlushTime.Store(time.Now())
-
-	// Update average batch size
-	totalBatches := aw.stats.totalBatches.Add(1)
-	currentAvg := aw.stats.avgBatchSize.Load()
-	newAvg := currentAvg + (int64(len(batch))-currentAvg)/totalBatches
-	aw.stats.avgBatchSize.Store(newAvg)
+	// Individual log insert fallback could be added here
+	return aw.batchInsert(ctx, batch)
 }
 
 // batchInsert performs a multi-row insert for efficiency
@@ -230,40 +100,14 @@ func (aw *AuditWriter) batchInsert(ctx context.Context, logs []*models.AuditLog)
 	return err
 }
 
-// Stop gracefully shuts down the audit writer
+// Stop gracefully shuts down the audit writer, draining remaining logs
 func (aw *AuditWriter) Stop() error {
-	// Signal workers to stop
-	aw.cancel()
-
-	// Wait for workers with timeout
-	done := make(chan struct{})
-	go func() {
-		aw.wg.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		return nil
-	case <-time.After(30 * time.Second):
-		return fmt.Errorf("audit writer shutdown timed out")
-	}
+	return aw.batches.stop("audit writer")
 }
 
 // GetStats returns current audit writing statistics
 func (aw *AuditWriter) GetStats() AuditStatsSnapshot {
-	lastFlush, _ := aw.stats.lastFlushTime.Load().(time.Time)
-	return AuditStatsSnapshot{
-		LogsReceived:   aw.stats.logsReceived.Load(),
-		LogsWritten:    aw.stats.logsWritten.Load(),
-		BatchesWritten: aw.stats.batchesWritten.Load(),
-		WriteErrors:    aw.stats.writeErrors.Load(),
-		DroppedLogs:    aw.stats.droppedLogs.Load(),
-		BufferSize:     len(aw.buffer),
-		BufferCapacity: cap(aw.buffer),
-		LastFlushTime:  lastFlush,
-		AvgBatchSize:   aw.stats.avgBatchSize.Load(),
-	}
+	return aw.batches.snapshot()
 }
 
 // AuditStatsSnapshot is a point-in-time view of audit stats
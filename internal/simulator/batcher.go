@@ -0,0 +1,203 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// batcher provides buffered, async writing of items in batches: Write queues
+// an item without blocking (dropping it if the buffer is full), and workers
+// hand full batches, or whatever has built up each flush interval, to flush.
+// The audit writer and the operation log are both built on it.
+type batcher[T any] struct {
+	flush func([]T) error
+
+	// Buffered channel for incoming items
+	buffer chan T
+
+	// Configuration
+	batchSize     int
+	flushInterval time.Duration
+	workers       int
+
+	// Lifecycle
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Statistics
+	stats batchStats
+}
+
+// batchStats tracks batch writing statistics
+type batchStats struct {
+	received       atomic.Int64
+	written        atomic.Int64
+	batchesWritten atomic.Int64
+	writeErrors    atomic.Int64
+	dropped        atomic.Int64
+	lastFlushTime  atomic.Value // time.Time
+	avgBatchSize   atomic.Int64
+	totalBatches   atomic.Int64
+}
+
+// newBatcher creates a batcher writing batches with flush
+func newBatcher[T any](cfg AuditWriterConfig, flush func([]T) error) *batcher[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &batcher[T]{
+		flush:         flush,
+		buffer:        make(chan T, cfg.BufferSize),
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		workers:       cfg.Workers,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+
+	b.stats.lastFlushTime.Store(time.Now())
+
+	return b
+}
+
+// start begins the background write workers
+func (b *batcher[T]) start() {
+	for i := 0; i < b.workers; i++ {
+		b.wg.Add(1)
+		go b.writeWorker()
+	}
+}
+
+// write queues an item for async writing
+// Returns immediately; the item will be written in the background
+func (b *batcher[T]) write(item T) {
+	b.stats.received.Add(1)
+
+	select {
+	case b.buffer <- item:
+		// Successfully queued
+	default:
+		// Buffer full - drop the item and record it
+		b.stats.dropped.Add(1)
+	}
+}
+
+// writeWorker processes items from the buffer and writes them in batches
+func (b *batcher[T]) writeWorker() {
+	defer b.wg.Done()
+
+	batch := make([]T, 0, b.batchSize)
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case item := <-b.buffer:
+			batch = append(batch, item)
+			if len(batch) >= b.batchSize {
+				b.writeBatch(batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			// Periodic flush of incomplete batches
+			if len(batch) > 0 {
+				b.writeBatch(batch)
+				batch = batch[:0]
+			}
+
+		case <-b.ctx.Done():
+			// Drain remaining buffer on shutdown
+			b.drainBuffer(batch)
+			return
+		}
+	}
+}
+
+// drainBuffer writes all remaining items during shutdown
+func (b *batcher[T]) drainBuffer(currentBatch []T) {
+	// First write any current batch
+	if len(currentBatch) > 0 {
+		b.writeBatch(currentBatch)
+	}
+
+	// Then drain the channel
+	batch := make([]T, 0, b.batchSize)
+	for {
+		select {
+		case item := <-b.buffer:
+			batch = append(batch, item)
+			if len(batch) >= b.batchSize {
+				b.writeBatch(batch)
+				batch = batch[:0]
+			}
+		default:
+			// Channel empty
+			if len(batch) > 0 {
+				b.writeBatch(batch)
+			}
+			return
+		}
+	}
+}
+
+// writeBatch flushes a batch and updates the statistics
+func (b *batcher[T]) writeBatch(batch []T) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := b.flush(batch); err != nil {
+		b.stats.writeErrors.Add(1)
+		// Optionally log the error, but don't block
+		return
+	}
+
+	b.stats.written.Add(int64(len(batch)))
+	b.stats.batchesWritten.Add(1)
+	b.stats.lastFlushTime.Store(time.Now())
+
+	// Update average batch size
+	totalBatches := b.stats.totalBatches.Add(1)
+	currentAvg := b.stats.avgBatchSize.Load()
+	newAvg := currentAvg + (int64(len(batch))-currentAvg)/totalBatches
+	b.stats.avgBatchSize.Store(newAvg)
+}
+
+// stop signals the workers to drain the buffer and waits up to 30 seconds
+// for them to finish
+func (b *batcher[T]) stop(name string) error {
+	b.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(30 * time.Second):
+		return fmt.Errorf("%s shutdown timed out", name)
+	}
+}
+
+// snapshot returns current statistics
+func (b *batcher[T]) snapshot() AuditStatsSnapshot {
+	lastFlush, _ := b.stats.lastFlushTime.Load().(time.Time)
+	return AuditStatsSnapshot{
+		LogsReceived:   b.stats.received.Load(),
+		LogsWritten:    b.stats.written.Load(),
+		BatchesWritten: b.stats.batchesWritten.Load(),
+		WriteErrors:    b.stats.writeErrors.Load(),
+		DroppedLogs:    b.stats.dropped.Load(),
+		BufferSize:     len(b.buffer),
+		BufferCapacity: cap(b.buffer),
+		LastFlushTime:  lastFlush,
+		AvgBatchSize:   b.stats.avgBatchSize.Load(),
+	}
+}
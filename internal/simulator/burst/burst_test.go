@@ -142,6 +142,42 @@ func TestPayrollBurst_SetPayrollDays(t *testing.T) {
 	}
 }
 
+func TestPayrollBurst_UsesClock(t *testing.T) {
+	for _, tc := range []struct {
+		now     time.Time
+		payroll bool
+	}{
+		{time.Date(2024, 3, 26, 9, 30, 0, 0, time.UTC), true},
+		{time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC), false},
+	} {
+		pb := NewPayrollBurst(BurstConfig{Enabled: true, Multiplier: 3.0})
+		pb.SetClock(func() time.Time { return tc.now })
+
+		if got := pb.IsPayrollPeriod("UTC"); got != tc.payroll {
+			t.Errorf("%s: IsPayrollPeriod = %t, expected %t", tc.now.Format("Jan 2"), got, tc.payroll)
+		}
+		if event := pb.CheckBurst("UTC"); (event != nil) != tc.payroll {
+			t.Errorf("%s: CheckBurst = %v, expected a burst: %t", tc.now.Format("Jan 2"), event, tc.payroll)
+		}
+	}
+}
+
+func TestLunchBurst_UsesClock(t *testing.T) {
+	for _, tc := range []struct {
+		now   time.Time
+		lunch bool
+	}{
+		{time.Date(2024, 3, 12, 12, 5, 0, 0, time.UTC), true},
+		{time.Date(2024, 3, 12, 3, 30, 0, 0, time.UTC), false},
+	} {
+		lb := NewLunchBurst(BurstConfig{Enabled: true, Multiplier: 2.0})
+		lb.SetClock(func() time.Time { return tc.now })
+		if event := lb.CheckBurst("UTC"); (event != nil) != tc.lunch {
+			t.Errorf("%s: CheckBurst = %v, expected a burst: %t", tc.now.Format("15:04"), event, tc.lunch)
+		}
+	}
+}
+
 func TestRandomBurst_Type(t *testing.T) {
 	rb := NewRandomBurst(BurstConfig{Enabled: true, Probability: 0.1}, 42)
 	if rb.Type() != BurstTypeRandom {
@@ -18,6 +18,9 @@ type LunchBurst struct {
 	// Time parsing cache for efficiency
 	locationCache map[string]*time.Location
 	cacheMu       sync.RWMutex
+
+	// Time source for lunch hours (see SetClock)
+	now func() time.Time
 }
 
 // NewLunchBurst creates a new lunch-time ATM burst provider
@@ -26,9 +29,18 @@ func NewLunchBurst(cfg BurstConfig) *LunchBurst {
 		config:         cfg,
 		triggeredToday: make(map[string]time.Time),
 		locationCache:  make(map[string]*time.Location),
+		now:            time.Now,
 	}
 }
 
+// SetClock sets the time source lunch hours are judged by, such as the
+// scheduler's accelerated clock. Bursts still last wall-clock durations.
+func (lb *LunchBurst) SetClock(now func() time.Time) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	lb.now = now
+}
+
 // Type implements BurstProvider
 func (lb *LunchBurst) Type() BurstType {
 	return BurstTypeLunch
@@ -123,12 +135,12 @@ func (lb *LunchBurst) getLocalTime(timezone string) time.Time {
 		lb.cacheMu.Unlock()
 	}
 
-	return time.Now().In(loc)
+	return lb.now().In(loc)
 }
 
 // cleanupOldTriggers removes trigger records older than 7 days
 func (lb *LunchBurst) cleanupOldTriggers() {
-	cutoff := time.Now().AddDate(0, 0, -7)
+	cutoff := lb.now().AddDate(0, 0, -7)
 	for tz, triggered := range lb.triggeredToday {
 		if triggered.Before(cutoff) {
 			delete(lb.triggeredToday, tz)
@@ -22,6 +22,9 @@ type PayrollBurst struct {
 	// Time parsing cache
 	locationCache map[string]*time.Location
 	cacheMu       sync.RWMutex
+
+	// Time source for payroll days (see SetClock)
+	now func() time.Time
 }
 
 // NewPayrollBurst creates a new payroll burst provider
@@ -32,9 +35,18 @@ func NewPayrollBurst(cfg BurstConfig) *PayrollBurst {
 		payrollDays:        []int{25, 26, 27, 28, 29, 30, 31},
 		triggeredThisMonth: make(map[string]time.Time),
 		locationCache:      make(map[string]*time.Location),
+		now:                time.Now,
 	}
 }
 
+// SetClock sets the time source payroll days are judged by, such as the
+// scheduler's accelerated clock. Bursts still last wall-clock durations.
+func (pb *PayrollBurst) SetClock(now func() time.Time) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.now = now
+}
+
 // Type implements BurstProvider
 func (pb *PayrollBurst) Type() BurstType {
 	return BurstTypePayroll
@@ -154,12 +166,12 @@ func (pb *PayrollBurst) getLocalTime(timezone string) time.Time {
 		pb.cacheMu.Unlock()
 	}
 
-	return time.Now().In(loc)
+	return pb.now().In(loc)
 }
 
 // cleanupOldTriggers removes trigger records older than 60 days
 func (pb *PayrollBurst) cleanupOldTriggers() {
-	cutoff := time.Now().AddDate(0, -2, 0)
+	cutoff := pb.now().AddDate(0, -2, 0)
 	for tz, triggered := range pb.triggeredThisMonth {
 		if triggered.Before(cutoff) {
 			delete(pb.triggeredThisMonth, tz)
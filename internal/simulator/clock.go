@@ -0,0 +1,37 @@
+package simulator
+
+import "time"
+
+// Clock is the scheduler's time source. With an acceleration factor above 1,
+// simulated time runs that many times faster than wall-clock time from the
+// moment the clock is created, so a day's activity pattern (timezone windows,
+// intraday curve, weekends, payroll days) plays out in minutes with the same
+// relative shape. Lunch and payroll bursts trigger by it but last wall-clock
+// durations. A nil Clock is real time.
+type Clock struct {
+	start  time.Time
+	factor float64
+}
+
+// NewClock creates a clock running factor times faster than real time.
+// Factors of 1 or less return nil (real time).
+func NewClock(factor float64) *Clock {
+	if factor <= 1 {
+		return nil
+	}
+	return &Clock{start: time.Now(), factor: factor}
+}
+
+// Now returns the current simulated time
+func (c *Clock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	elapsed := time.Since(c.start)
+	return c.start.Add(time.Duration(float64(elapsed) * c.factor))
+}
+
+// Accelerated reports whether simulated time runs faster than real time
+func (c *Clock) Accelerated() bool {
+	return c != nil
+}
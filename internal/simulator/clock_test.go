@@ -0,0 +1,64 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock_RealTime(t *testing.T) {
+	for _, factor := range []float64{0, 1} {
+		clock := NewClock(factor)
+		if clock.Accelerated() {
+			t.Errorf("NewClock(%g) should run in real time", factor)
+		}
+		if d := time.Since(clock.Now()); d < 0 || d > time.Second {
+			t.Errorf("NewClock(%g).Now() is %s from real time", factor, d)
+		}
+	}
+}
+
+func TestClock_Accelerated(t *testing.T) {
+	clock := NewClock(3600) // An hour per second
+	if !clock.Accelerated() {
+		t.Fatal("NewClock(3600) should be accelerated")
+	}
+
+	start := time.Now()
+	time.Sleep(20 * time.Millisecond)
+	simulated := clock.Now().Sub(start)
+	wall := time.Since(start)
+
+	// At least 20ms * 3600 = 72s of simulated time has passed, and no more than wall * 3600
+	if simulated < 72*time.Second || simulated > time.Duration(float64(wall)*3600)+time.Second {
+		t.Errorf("Expected ~%s of simulated time, got %s", time.Duration(float64(wall)*3600), simulated)
+	}
+}
+
+func TestTimezoneManager_UsesClock(t *testing.T) {
+	tm := NewTimezoneManager(8, 16)
+	tm.SetClock(NewClock(86400)) // A day per second
+
+	before := tm.GetLocalTime("UTC")
+	time.Sleep(50 * time.Millisecond)
+	after := tm.GetLocalTime("UTC")
+
+	if gap := after.Sub(before); gap < time.Hour {
+		t.Errorf("Expected local time to advance at least an hour, advanced %s", gap)
+	}
+}
+
+func TestCustomerSession_PayrollPeriodUsesClock(t *testing.T) {
+	for _, tc := range []struct {
+		now     time.Time
+		payroll bool
+	}{
+		{time.Date(2024, 3, 26, 10, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC), false},
+	} {
+		// A clock that never advances
+		s := &CustomerSession{clock: &Clock{start: tc.now}}
+		if got := s.isPayrollPeriod(); got != tc.payroll {
+			t.Errorf("%s: isPayrollPeriod = %t, expected %t", tc.now.Format("Jan 2"), got, tc.payroll)
+		}
+	}
+}
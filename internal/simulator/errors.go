@@ -21,22 +21,30 @@ var (
 	ErrInvalidBeneficiary = errors.New("invalid beneficiary")
 	ErrDailyLimitExceeded = errors.New("daily limit exceeded")
 	ErrServiceUnavailable = errors.New("service temporarily unavailable")
+
+	// Injected write failures (see InjectWriteFailure)
+	ErrDeadlock        = errors.New("deadlock found when trying to get lock (simulated)")
+	ErrDuplicateKey    = errors.New("duplicate key (simulated)")
+	ErrConnectionReset = errors.New("connection reset by peer (simulated)")
 )
 
 // ErrorType categorizes errors for metrics and reporting
 type ErrorType string
 
 const (
-	ErrorTypeAuth          ErrorType = "auth"
-	ErrorTypeFunds         ErrorType = "funds"
-	ErrorTypeTimeout       ErrorType = "timeout"
-	ErrorTypeRateLimit     ErrorType = "rate_limit"
-	ErrorTypeAccountLock   ErrorType = "account_lock"
-	ErrorTypeBeneficiary   ErrorType = "beneficiary"
-	ErrorTypeDailyLimit    ErrorType = "daily_limit"
-	ErrorTypeService       ErrorType = "service"
-	ErrorTypeDatabase      ErrorType = "database"
-	ErrorTypeUnknown       ErrorType = "unknown"
+	ErrorTypeAuth            ErrorType = "auth"
+	ErrorTypeFunds           ErrorType = "funds"
+	ErrorTypeTimeout         ErrorType = "timeout"
+	ErrorTypeRateLimit       ErrorType = "rate_limit"
+	ErrorTypeAccountLock     ErrorType = "account_lock"
+	ErrorTypeBeneficiary     ErrorType = "beneficiary"
+	ErrorTypeDailyLimit      ErrorType = "daily_limit"
+	ErrorTypeService         ErrorType = "service"
+	ErrorTypeDeadlock        ErrorType = "deadlock"
+	ErrorTypeDuplicateKey    ErrorType = "duplicate_key"
+	ErrorTypeConnectionReset ErrorType = "connection_reset"
+	ErrorTypeDatabase        ErrorType = "database"
+	ErrorTypeUnknown         ErrorType = "unknown"
 )
 
 // IsSimulatedErrorType returns true for error types that represent
@@ -46,7 +54,8 @@ func IsSimulatedErrorType(errType ErrorType) bool {
 	switch errType {
 	case ErrorTypeAuth, ErrorTypeFunds, ErrorTypeTimeout,
 		ErrorTypeRateLimit, ErrorTypeAccountLock, ErrorTypeBeneficiary,
-		ErrorTypeDailyLimit, ErrorTypeService:
+		ErrorTypeDailyLimit, ErrorTypeService,
+		ErrorTypeDeadlock, ErrorTypeDuplicateKey, ErrorTypeConnectionReset:
 		return true
 	default:
 		return false
@@ -68,7 +77,10 @@ func IsInfrastructureError(err error) bool {
 		errors.Is(err, ErrAccountLocked) ||
 		errors.Is(err, ErrInvalidBeneficiary) ||
 		errors.Is(err, ErrDailyLimitExceeded) ||
-		errors.Is(err, ErrServiceUnavailable) {
+		errors.Is(err, ErrServiceUnavailable) ||
+		errors.Is(err, ErrDeadlock) ||
+		errors.Is(err, ErrDuplicateKey) ||
+		errors.Is(err, ErrConnectionReset) {
 		return false
 	}
 	// Everything else is infrastructure (database errors, connection issues, etc.)
@@ -133,6 +145,24 @@ func (e *ErrorSimulator) ShouldSimulateTimeout(rng *utils.Random) bool {
 	return rng.Float64() < e.config.TimeoutRate
 }
 
+// InjectWriteFailure returns a forced failure for a write attempt, or nil.
+// Deadlocks, connection resets and timeouts are transient and retried by
+// DefaultRetryConfig; duplicate-key collisions are not.
+func (e *ErrorSimulator) InjectWriteFailure(rng *utils.Random) error {
+	switch {
+	case rng.Float64() < e.config.DeadlockRate:
+		return ErrDeadlock
+	case rng.Float64() < e.config.DuplicateKeyRate:
+		return ErrDuplicateKey
+	case rng.Float64() < e.config.ConnectionResetRate:
+		return ErrConnectionReset
+	case e.ShouldSimulateTimeout(rng):
+		return ErrTimeout
+	default:
+		return nil
+	}
+}
+
 // SimulateTimeout artificially delays an operation to trigger a timeout
 func (e *ErrorSimulator) SimulateTimeout(ctx context.Context, rng *utils.Random) error {
 	if !e.ShouldSimulateTimeout(rng) {
@@ -203,9 +233,11 @@ func DefaultRetryConfig() RetryConfig {
 		MaxDelay:   2 * time.Second,
 		Jitter:     true,
 		RetryableCheck: func(err error) bool {
-			// By default, only retry timeout and service errors
+			// By default, only retry transient errors
 			return errors.Is(err, ErrTimeout) ||
 				   errors.Is(err, ErrServiceUnavailable) ||
+				   errors.Is(err, ErrDeadlock) ||
+				   errors.Is(err, ErrConnectionReset) ||
 				   errors.Is(err, context.DeadlineExceeded)
 		},
 	}
@@ -308,9 +340,9 @@ func (e *ErrorSimulator) GetRetryStats() RetryStats {
 
 // RetryStats holds retry operation statistics
 type RetryStats struct {
-	TotalRetries      int64
-	SuccessfulRetries int64
-	ExhaustedRetries  int64
+	TotalRetries      int64 `json:"total"`
+	SuccessfulRetries int64 `json:"successful"`
+	ExhaustedRetries  int64 `json:"exhausted"`
 }
 
 // ClassifyError determines the error type from an error
@@ -336,6 +368,12 @@ func ClassifyError(err error) ErrorType {
 		return ErrorTypeDailyLimit
 	case errors.Is(err, ErrServiceUnavailable):
 		return ErrorTypeService
+	case errors.Is(err, ErrDeadlock):
+		return ErrorTypeDeadlock
+	case errors.Is(err, ErrDuplicateKey):
+		return ErrorTypeDuplicateKey
+	case errors.Is(err, ErrConnectionReset):
+		return ErrorTypeConnectionReset
 	default:
 		// Check for common database error patterns
 		errStr := err.Error()
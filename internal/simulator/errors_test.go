@@ -0,0 +1,90 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/config"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestInjectWriteFailure_ClassifiesEachType(t *testing.T) {
+	cfg := config.DefaultConfig().Simulate
+	cfg.DeadlockRate = 0.1
+	cfg.DuplicateKeyRate = 0.1
+	cfg.ConnectionResetRate = 0.1
+	cfg.TimeoutRate = 0.1
+
+	errSim := NewErrorSimulator(cfg)
+	rng := utils.NewRandom(1)
+
+	counts := make(map[ErrorType]int)
+	for i := 0; i < 10000; i++ {
+		if err := errSim.InjectWriteFailure(rng); err != nil {
+			counts[ClassifyError(err)]++
+		}
+	}
+
+	for _, errType := range []ErrorType{ErrorTypeDeadlock, ErrorTypeDuplicateKey, ErrorTypeConnectionReset, ErrorTypeTimeout} {
+		if counts[errType] == 0 {
+			t.Errorf("Expected injected %s errors, got none", errType)
+		}
+		if !IsSimulatedErrorType(errType) {
+			t.Errorf("Expected %s to be a simulated error type", errType)
+		}
+	}
+}
+
+func TestInjectWriteFailure_ZeroRates(t *testing.T) {
+	cfg := config.DefaultConfig().Simulate
+	cfg.DeadlockRate = 0
+	cfg.DuplicateKeyRate = 0
+	cfg.ConnectionResetRate = 0
+	cfg.TimeoutRate = 0
+
+	errSim := NewErrorSimulator(cfg)
+	rng := utils.NewRandom(1)
+	for i := 0; i < 1000; i++ {
+		if err := errSim.InjectWriteFailure(rng); err != nil {
+			t.Fatalf("Expected no injected failure, got %v", err)
+		}
+	}
+}
+
+func TestRetryableOperation_RetriesTransientFailures(t *testing.T) {
+	errSim := NewErrorSimulator(config.DefaultConfig().Simulate)
+	retryCfg := DefaultRetryConfig()
+	retryCfg.BaseDelay = time.Millisecond
+	op := NewRetryableOperation(errSim, utils.NewRandom(1), retryCfg)
+
+	// A deadlock then a connection reset, then success
+	failures := []error{ErrDeadlock, ErrConnectionReset}
+	err := op.Execute(context.Background(), func(ctx context.Context) error {
+		if len(failures) > 0 {
+			err := failures[0]
+			failures = failures[1:]
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+
+	// Duplicate keys are not retried
+	attempts := 0
+	err = op.Execute(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return ErrDuplicateKey
+	})
+	if !errors.Is(err, ErrDuplicateKey) || attempts != 1 {
+		t.Errorf("Expected one attempt failing with duplicate key, got %d attempts and %v", attempts, err)
+	}
+
+	stats := errSim.GetRetryStats()
+	if stats.TotalRetries != 2 || stats.SuccessfulRetries != 1 || stats.ExhaustedRetries != 0 {
+		t.Errorf("Unexpected retry stats: %+v", stats)
+	}
+}
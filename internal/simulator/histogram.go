@@ -0,0 +1,203 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/bits"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Latencies are bucketed HDR-style in microseconds: exactly below
+// 2^histogramSubBits µs, then 2^(histogramSubBits-1) linear sub-buckets per
+// power of two, so every bucket is within 1/64 (about 1.6%) of its values.
+const (
+	histogramSubBits = 7
+	histogramSubHalf = 1 << (histogramSubBits - 1)
+	histogramLinear  = 1 << histogramSubBits
+)
+
+// histogramMaxMicros caps recorded latencies at an hour; anything slower
+// lands in the last bucket
+const histogramMaxMicros = uint64(time.Hour / time.Microsecond)
+
+// LatencyHistogram counts latencies in log-linear buckets covering the whole
+// run, so any percentile and the tail can be read back at ~1.6% precision
+// without keeping samples. It is not safe for concurrent use; LatencyTracker
+// guards it.
+type LatencyHistogram struct {
+	counts []int64
+	total  int64
+	min    time.Duration
+	max    time.Duration
+}
+
+// HistogramBucket is one non-empty histogram bucket: Count latencies in
+// [Lower, Upper]
+type HistogramBucket struct {
+	Lower time.Duration
+	Upper time.Duration
+	Count int64
+}
+
+// NewLatencyHistogram creates an empty histogram
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{counts: make([]int64, histogramIndex(histogramMaxMicros)+1)}
+}
+
+// histogramIndex returns the bucket holding a latency of micros
+func histogramIndex(micros uint64) int {
+	if micros < histogramLinear {
+		return int(micros)
+	}
+	shift := bits.Len64(micros) - histogramSubBits
+	return histogramLinear + (shift-1)*histogramSubHalf + int(micros>>shift) - histogramSubHalf
+}
+
+// histogramBounds returns the lowest and highest latency, in microseconds,
+// bucket i holds
+func histogramBounds(i int) (lower, upper uint64) {
+	if i < histogramLinear {
+		return uint64(i), uint64(i)
+	}
+	shift := (i-histogramLinear)/histogramSubHalf + 1
+	mantissa := uint64((i-histogramLinear)%histogramSubHalf + histogramSubHalf)
+	return mantissa << shift, (mantissa+1)<<shift - 1
+}
+
+// Record counts a latency
+func (h *LatencyHistogram) Record(latency time.Duration) {
+	micros := uint64(max(latency, 0) / time.Microsecond)
+	h.counts[histogramIndex(min(micros, histogramMaxMicros))]++
+	if h.total == 0 || latency < h.min {
+		h.min = latency
+	}
+	if latency > h.max {
+		h.max = latency
+	}
+	h.total++
+}
+
+// Merge adds other's counts to h
+func (h *LatencyHistogram) Merge(other *LatencyHistogram) {
+	if other.total == 0 {
+		return
+	}
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	if h.total == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	h.max = max(h.max, other.max)
+	h.total += other.total
+}
+
+// Clone returns a copy of the histogram
+func (h *LatencyHistogram) Clone() *LatencyHistogram {
+	c := *h
+	c.counts = slices.Clone(h.counts)
+	return &c
+}
+
+// Count returns the number of latencies recorded
+func (h *LatencyHistogram) Count() int64 {
+	return h.total
+}
+
+// Max returns the highest latency recorded
+func (h *LatencyHistogram) Max() time.Duration {
+	return h.max
+}
+
+// Percentile returns the p-th percentile latency: the highest value of the
+// bucket holding it, capped at the highest latency recorded
+func (h *LatencyHistogram) Percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(float64(h.total-1)*p/100.0) + 1
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			_, upper := histogramBounds(i)
+			return min(time.Duration(upper)*time.Microsecond+time.Microsecond-1, h.max)
+		}
+	}
+	return h.max
+}
+
+// Buckets returns the non-empty buckets in increasing latency order
+func (h *LatencyHistogram) Buckets() []HistogramBucket {
+	var buckets []HistogramBucket
+	for i, c := range h.counts {
+		if c == 0 {
+			continue
+		}
+		lower, upper := histogramBounds(i)
+		buckets = append(buckets, HistogramBucket{
+			Lower: time.Duration(lower) * time.Microsecond,
+			Upper: time.Duration(upper) * time.Microsecond,
+			Count: c,
+		})
+	}
+	return buckets
+}
+
+// HistogramHeader returns the CSV header written by WriteLatencyHistograms
+func HistogramHeader() []string {
+	return []string{"operation", "lower_us", "upper_us", "count", "cumulative_count", "percentile"}
+}
+
+// WriteLatencyHistograms writes each operation type's latency histogram, and
+// all operations' combined as "all", as CSV rows of non-empty buckets with
+// their cumulative count and percentile
+func (m *EnhancedMetrics) WriteLatencyHistograms(w io.Writer) error {
+	histograms := m.Histograms()
+	ops := make([]string, 0, len(histograms))
+	all := NewLatencyHistogram()
+	for op, h := range histograms {
+		ops = append(ops, string(op))
+		all.Merge(h)
+	}
+	slices.Sort(ops)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(HistogramHeader()); err != nil {
+		return err
+	}
+	writeHistogram := func(name string, h *LatencyHistogram) error {
+		var cumulative int64
+		for _, b := range h.Buckets() {
+			cumulative += b.Count
+			err := out.Write([]string{
+				name,
+				strconv.FormatInt(b.Lower.Microseconds(), 10),
+				strconv.FormatInt(b.Upper.Microseconds(), 10),
+				strconv.FormatInt(b.Count, 10),
+				strconv.FormatInt(cumulative, 10),
+				strconv.FormatFloat(float64(cumulative)*100/float64(h.Count()), 'f', 4, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, op := range ops {
+		if err := writeHistogram(op, histograms[OperationType(op)]); err != nil {
+			return err
+		}
+	}
+	if err := writeHistogram("all", all); err != nil {
+		return err
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write latency histograms: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogramBucketsCoverEveryValue(t *testing.T) {
+	prevUpper := int64(-1)
+	for i := 0; i <= histogramIndex(histogramMaxMicros); i++ {
+		lower, upper := histogramBounds(i)
+		if int64(lower) != prevUpper+1 {
+			t.Fatalf("bucket %d starts at %d, previous ended at %d", i, lower, prevUpper)
+		}
+		if histogramIndex(lower) != i || histogramIndex(upper) != i {
+			t.Fatalf("bucket %d [%d, %d] maps its bounds to %d and %d", i, lower, upper, histogramIndex(lower), histogramIndex(upper))
+		}
+		if width := float64(upper - lower + 1); width > 1 && width/float64(lower) > 1.0/histogramSubHalf {
+			t.Fatalf("bucket %d [%d, %d] is wider than 1/%d of its values", i, lower, upper, histogramSubHalf)
+		}
+		prevUpper = int64(upper)
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	h := NewLatencyHistogram()
+	// 1ms..10s, one sample per millisecond
+	for ms := 1; ms <= 10000; ms++ {
+		h.Record(time.Duration(ms) * time.Millisecond)
+	}
+	for _, tc := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 5 * time.Second},
+		{95, 9500 * time.Millisecond},
+		{99, 9900 * time.Millisecond},
+		{99.9, 9990 * time.Millisecond},
+		{100, 10 * time.Second},
+	} {
+		got := h.Percentile(tc.p)
+		if rel := math.Abs(float64(got-tc.want)) / float64(tc.want); rel > 1.0/histogramSubHalf {
+			t.Errorf("p%g = %s, want %s within %.1f%%", tc.p, got, tc.want, 100.0/histogramSubHalf)
+		}
+	}
+	if h.Percentile(100) != h.Max() || h.Max() != 10*time.Second {
+		t.Errorf("p100 = %s, max = %s, want 10s", h.Percentile(100), h.Max())
+	}
+}
+
+func TestWriteLatencyHistograms(t *testing.T) {
+	m := NewEnhancedMetrics(nil)
+	for i := 0; i < 100; i++ {
+		m.RecordOperation(OpTransfer, true, time.Duration(i+1)*time.Millisecond)
+	}
+	m.RecordOperation(OpBalanceCheck, false, 2*time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := m.WriteLatencyHistograms(&buf); err != nil {
+		t.Fatalf("WriteLatencyHistograms: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := make(map[string][]string)
+	for _, row := range rows[1:] {
+		last[row[0]] = row
+	}
+	for op, wantCount := range map[string]string{"transfer": "100", "balance_check": "1", "all": "101"} {
+		row, ok := last[op]
+		if !ok {
+			t.Errorf("no %s histogram", op)
+			continue
+		}
+		if row[4] != wantCount || row[5] != "100.0000" {
+			t.Errorf("%s histogram ends at cumulative %s (%s%%), want %s (100%%)", op, row[4], row[5], wantCount)
+		}
+	}
+	if _, ok := last[string(OpWithdrawal)]; ok {
+		t.Error("histogram written for an operation type that never ran")
+	}
+}
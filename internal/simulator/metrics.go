@@ -2,7 +2,6 @@ package simulator
 
 import (
 	"fmt"
-	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,7 +13,9 @@ type OperationType string
 const (
 	OpBalanceCheck    OperationType = "balance_check"
 	OpHistoryView     OperationType = "history_view"
+	OpHistoryPage     OperationType = "history_page"
 	OpTransfer        OperationType = "transfer"
+	OpBillPay         OperationType = "bill_pay"
 	OpWithdrawal      OperationType = "withdrawal"
 	OpDeposit         OperationType = "deposit"
 	OpBatchPayroll    OperationType = "batch_payroll"
@@ -52,13 +53,11 @@ type EnhancedMetrics struct {
 	recentErrors  *RollingWindow
 }
 
-// LatencyTracker maintains latency samples for percentile calculation
+// LatencyTracker keeps an operation's latency histogram for percentile calculation
 type LatencyTracker struct {
-	mu       sync.Mutex
-	samples  []time.Duration
-	maxSize  int
-	totalNs  int64
-	count    int64
+	mu      sync.Mutex
+	hist    *LatencyHistogram
+	totalNs int64
 }
 
 // RollingWindow tracks counts within a sliding time window
@@ -87,9 +86,9 @@ func NewEnhancedMetrics(errorSim *ErrorSimulator) *EnhancedMetrics {
 	}
 
 	// Initialize operation counters
-	for _, op := range []OperationType{OpBalanceCheck, OpHistoryView, OpTransfer, OpWithdrawal, OpDeposit, OpBatchPayroll, OpAccountSweep, OpLogin, OpAuditLog} {
+	for _, op := range []OperationType{OpBalanceCheck, OpHistoryView, OpHistoryPage, OpTransfer, OpBillPay, OpWithdrawal, OpDeposit, OpBatchPayroll, OpAccountSweep, OpLogin, OpAuditLog} {
 		m.opCounts[op] = &atomic.Int64{}
-		m.opLatency[op] = NewLatencyTracker(10000) // Keep last 10k samples per operation
+		m.opLatency[op] = NewLatencyTracker()
 	}
 
 	// Initialize session counters
@@ -100,12 +99,9 @@ func NewEnhancedMetrics(errorSim *ErrorSimulator) *EnhancedMetrics {
 	return m
 }
 
-// NewLatencyTracker creates a new latency tracker with reservoir sampling
-func NewLatencyTracker(maxSize int) *LatencyTracker {
-	return &LatencyTracker{
-		samples: make([]time.Duration, 0, maxSize),
-		maxSize: maxSize,
-	}
+// NewLatencyTracker creates a new latency tracker
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{hist: NewLatencyHistogram()}
 }
 
 // Record adds a latency sample
@@ -114,35 +110,14 @@ func (lt *LatencyTracker) Record(latency time.Duration) {
 	defer lt.mu.Unlock()
 
 	lt.totalNs += latency.Nanoseconds()
-	lt.count++
-
-	// Reservoir sampling: keep maxSize samples with uniform probability
-	if len(lt.samples) < lt.maxSize {
-		lt.samples = append(lt.samples, latency)
-	} else {
-		// Random replacement for reservoir sampling
-		// For simplicity, replace oldest entry (FIFO) when full
-		copy(lt.samples, lt.samples[1:])
-		lt.samples[len(lt.samples)-1] = latency
-	}
+	lt.hist.Record(latency)
 }
 
-// Percentile returns the p-th percentile latency
+// Percentile returns the p-th percentile latency over the whole run
 func (lt *LatencyTracker) Percentile(p float64) time.Duration {
 	lt.mu.Lock()
 	defer lt.mu.Unlock()
-
-	if len(lt.samples) == 0 {
-		return 0
-	}
-
-	// Sort samples (on copy to avoid modifying original)
-	sorted := make([]time.Duration, len(lt.samples))
-	copy(sorted, lt.samples)
-	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
-
-	idx := int(float64(len(sorted)-1) * p / 100.0)
-	return sorted[idx]
+	return lt.hist.Percentile(p)
 }
 
 // Average returns the average latency
@@ -150,17 +125,24 @@ func (lt *LatencyTracker) Average() time.Duration {
 	lt.mu.Lock()
 	defer lt.mu.Unlock()
 
-	if lt.count == 0 {
+	if lt.hist.Count() == 0 {
 		return 0
 	}
-	return time.Duration(lt.totalNs / lt.count)
+	return time.Duration(lt.totalNs / lt.hist.Count())
 }
 
 // Count returns the total number of samples recorded
 func (lt *LatencyTracker) Count() int64 {
 	lt.mu.Lock()
 	defer lt.mu.Unlock()
-	return lt.count
+	return lt.hist.Count()
+}
+
+// Histogram returns a copy of the latency histogram
+func (lt *LatencyTracker) Histogram() *LatencyHistogram {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	return lt.hist.Clone()
 }
 
 // NewRollingWindow creates a new rolling window for TPS calculation
@@ -260,6 +242,11 @@ func (m *EnhancedMetrics) RecordError(errType ErrorType) {
 	}
 }
 
+// TotalOperations returns the number of operations recorded so far
+func (m *EnhancedMetrics) TotalOperations() int64 {
+	return m.totalOperations.Load()
+}
+
 // RecordSessionComplete records a completed session
 func (m *EnhancedMetrics) RecordSessionComplete(sessionType SessionType) {
 	m.totalSessions.Add(1)
@@ -325,8 +312,7 @@ func (m *EnhancedMetrics) Snapshot() EnhancedSnapshot {
 
 	// Calculate overall latency from all operation latencies
 	var totalNs int64
-	var totalCount int64
-	allSamples := make([]time.Duration, 0, 10000)
+	all := NewLatencyHistogram()
 
 	m.opMu.RLock()
 	opStats := make(map[OperationType]OperationStat)
@@ -340,26 +326,20 @@ func (m *EnhancedMetrics) Snapshot() EnhancedSnapshot {
 		}
 		opStats[opType] = stat
 
-		// Collect samples for overall percentiles
+		// Merge histograms for overall percentiles
 		tracker.mu.Lock()
-		allSamples = append(allSamples, tracker.samples...)
+		all.Merge(tracker.hist)
 		totalNs += tracker.totalNs
-		totalCount += tracker.count
 		tracker.mu.Unlock()
 	}
 	m.opMu.RUnlock()
 
 	// Calculate overall percentiles
-	var avgLatency, p50, p95, p99 time.Duration
-	if totalCount > 0 {
-		avgLatency = time.Duration(totalNs / totalCount)
-	}
-	if len(allSamples) > 0 {
-		sort.Slice(allSamples, func(i, j int) bool { return allSamples[i] < allSamples[j] })
-		p50 = allSamples[len(allSamples)*50/100]
-		p95 = allSamples[len(allSamples)*95/100]
-		p99 = allSamples[len(allSamples)*99/100]
+	var avgLatency time.Duration
+	if all.Count() > 0 {
+		avgLatency = time.Duration(totalNs / all.Count())
 	}
+	p50, p95, p99 := all.Percentile(50), all.Percentile(95), all.Percentile(99)
 
 	// Session stats
 	m.sessionMu.RLock()
@@ -395,6 +375,18 @@ func (m *EnhancedMetrics) Snapshot() EnhancedSnapshot {
 	}
 }
 
+// Histograms returns a copy of each operation type's latency histogram
+func (m *EnhancedMetrics) Histograms() map[OperationType]*LatencyHistogram {
+	m.opMu.RLock()
+	defer m.opMu.RUnlock()
+
+	histograms := make(map[OperationType]*LatencyHistogram, len(m.opLatency))
+	for opType, tracker := range m.opLatency {
+		histograms[opType] = tracker.Histogram()
+	}
+	return histograms
+}
+
 // FormatMetricsLine returns a formatted one-line metrics summary
 func (m *EnhancedMetrics) FormatMetricsLine() string {
 	snap := m.Snapshot()
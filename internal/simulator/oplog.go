@@ -0,0 +1,182 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// OpLogEntry is one executed operation in the operation log
+type OpLogEntry struct {
+	Timestamp  time.Time // When the operation started
+	SessionID  string
+	CustomerID int64
+	Operation  OperationType
+	AccountID  *int64        // nil for operations not on an account (login)
+	Amount     int64         // Cents moved, 0 for reads
+	Result     string        // "ok", or the ErrorType of the failure
+	Latency    time.Duration // Whole operation, simulated timeouts and retries included
+	Error      string        // Failure message, empty on success
+}
+
+// OpLogResultOK is the result of an operation that succeeded
+const OpLogResultOK = "ok"
+
+// opLogHeader are the columns of the operation log CSV
+var opLogHeader = []string{
+	"timestamp", "session_id", "customer_id", "operation", "account_id",
+	"amount", "result", "latency_us", "error",
+}
+
+// opLogWriterConfig batches op log rows: larger batches than audit inserts
+// since they are cheap file writes, and one worker so rows aren't interleaved
+var opLogWriterConfig = AuditWriterConfig{
+	BufferSize:    10000,
+	BatchSize:     500,
+	FlushInterval: time.Second,
+	Workers:       1,
+}
+
+// OpLog records every executed operation to a CSV file, so a failing
+// scenario hidden by the aggregate metrics can be inspected or replayed.
+// Rows are written in the background like audit logs.
+type OpLog struct {
+	file    *os.File
+	out     *csv.Writer
+	batches *batcher[OpLogEntry]
+}
+
+// NewOpLog creates the operation log file at path and writes its header
+func NewOpLog(path string) (*OpLog, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create op log: %w", err)
+	}
+	ol := &OpLog{file: file, out: csv.NewWriter(file)}
+	if err := ol.out.Write(opLogHeader); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write op log header: %w", err)
+	}
+	ol.batches = newBatcher(opLogWriterConfig, ol.writeBatch)
+	return ol, nil
+}
+
+// Start begins the background writer
+func (ol *OpLog) Start() {
+	ol.batches.start()
+}
+
+// Record queues an operation for writing
+func (ol *OpLog) Record(entry OpLogEntry) {
+	ol.batches.write(entry)
+}
+
+// writeBatch appends a batch of operations to the file
+func (ol *OpLog) writeBatch(batch []OpLogEntry) error {
+	for _, e := range batch {
+		if err := ol.out.Write(opLogRow(e)); err != nil {
+			return err
+		}
+	}
+	ol.out.Flush()
+	return ol.out.Error()
+}
+
+// Stop drains the remaining operations and closes the file
+func (ol *OpLog) Stop() error {
+	err := ol.batches.stop("op log")
+	ol.out.Flush()
+	if flushErr := ol.out.Error(); err == nil {
+		err = flushErr
+	}
+	if closeErr := ol.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// GetStats returns current op log writing statistics
+func (ol *OpLog) GetStats() AuditStatsSnapshot {
+	return ol.batches.snapshot()
+}
+
+// opLogRow formats an operation as a CSV row
+func opLogRow(e OpLogEntry) []string {
+	accountID := ""
+	if e.AccountID != nil {
+		accountID = strconv.FormatInt(*e.AccountID, 10)
+	}
+	return []string{
+		e.Timestamp.UTC().Format(time.RFC3339Nano),
+		e.SessionID,
+		strconv.FormatInt(e.CustomerID, 10),
+		string(e.Operation),
+		accountID,
+		strconv.FormatInt(e.Amount, 10),
+		e.Result,
+		strconv.FormatInt(e.Latency.Microseconds(), 10),
+		e.Error,
+	}
+}
+
+// ReadOpLog reads back an operation log written by OpLog
+func ReadOpLog(r io.Reader) ([]OpLogEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(opLogHeader)
+	if _, err := cr.Read(); err != nil {
+		return nil, fmt.Errorf("failed to read op log header: %w", err)
+	}
+
+	var entries []OpLogEntry
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry, err := parseOpLogRow(row)
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("op log line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// parseOpLogRow parses a CSV row written by opLogRow
+func parseOpLogRow(row []string) (OpLogEntry, error) {
+	e := OpLogEntry{
+		SessionID: row[1],
+		Operation: OperationType(row[3]),
+		Result:    row[6],
+		Error:     row[8],
+	}
+	var err error
+	if e.Timestamp, err = time.Parse(time.RFC3339Nano, row[0]); err != nil {
+		return e, err
+	}
+	if e.CustomerID, err = strconv.ParseInt(row[2], 10, 64); err != nil {
+		return e, err
+	}
+	if row[4] != "" {
+		accountID, err := strconv.ParseInt(row[4], 10, 64)
+		if err != nil {
+			return e, err
+		}
+		e.AccountID = &accountID
+	}
+	if e.Amount, err = strconv.ParseInt(row[5], 10, 64); err != nil {
+		return e, err
+	}
+	latency, err := strconv.ParseInt(row[7], 10, 64)
+	if err != nil {
+		return e, err
+	}
+	e.Latency = time.Duration(latency) * time.Microsecond
+	return e, nil
+}
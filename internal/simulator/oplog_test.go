@@ -0,0 +1,133 @@
+package simulator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/config"
+	"github.com/willfong/load-generator/internal/models"
+	"github.com/willfong/load-generator/internal/utils"
+)
+
+func TestOpLogRecordsOperations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ops.csv")
+	opLog, err := NewOpLog(path)
+	if err != nil {
+		t.Fatalf("NewOpLog: %v", err)
+	}
+	opLog.Start()
+
+	session := &CustomerSession{ID: "SIM-1", Customer: &models.Customer{ID: 42}, opLog: opLog}
+	transfer := func() (err error) {
+		defer session.beginOp(OpTransfer)(&err)
+		session.opTarget(7, 2500)
+		return ErrInsufficientFunds
+	}
+	balance := func() (err error) {
+		defer session.beginOp(OpBalanceCheck)(&err)
+		session.opTarget(8, 0)
+		return nil
+	}
+	if err := transfer(); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("transfer returned %v", err)
+	}
+	if err := balance(); err != nil {
+		t.Fatalf("balance check returned %v", err)
+	}
+	session.beginOp(OpLogin)(nil)
+
+	if err := opLog.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if stats := opLog.GetStats(); stats.LogsWritten != 3 || stats.DroppedLogs != 0 {
+		t.Errorf("wrote %d operations (%d dropped), expected 3", stats.LogsWritten, stats.DroppedLogs)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := ReadOpLog(f)
+	if err != nil {
+		t.Fatalf("ReadOpLog: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("read %d operations, expected 3", len(entries))
+	}
+
+	type summary struct {
+		op      OperationType
+		account *int64
+		amount  int64
+		result  string
+		errMsg  string
+	}
+	account := func(id int64) *int64 { return &id }
+	want := []summary{
+		{OpTransfer, account(7), 2500, string(ErrorTypeFunds), ErrInsufficientFunds.Error()},
+		{OpBalanceCheck, account(8), 0, OpLogResultOK, ""},
+		{OpLogin, nil, 0, OpLogResultOK, ""},
+	}
+	for i, e := range entries {
+		got := summary{e.Operation, e.AccountID, e.Amount, e.Result, e.Error}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("operation %d = %+v, want %+v", i, got, want[i])
+		}
+		if e.SessionID != "SIM-1" || e.CustomerID != 42 {
+			t.Errorf("operation %d is for session %s customer %d", i, e.SessionID, e.CustomerID)
+		}
+		if e.Timestamp.IsZero() || time.Since(e.Timestamp) > time.Minute || e.Latency < 0 {
+			t.Errorf("operation %d has timestamp %s and latency %s", i, e.Timestamp, e.Latency)
+		}
+	}
+}
+
+func TestOpLogRecordsEarlyTransferFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ops.csv")
+	opLog, err := NewOpLog(path)
+	if err != nil {
+		t.Fatalf("NewOpLog: %v", err)
+	}
+	opLog.Start()
+
+	// Every transfer fails the simulated funds check before reaching the database
+	errorSim := NewErrorSimulator(config.SimulateConfig{InsufficientFundsRate: 1})
+	session := &CustomerSession{
+		ID:          "SIM-2",
+		Customer:    &models.Customer{ID: 42},
+		Accounts:    []*models.Account{{ID: 9}},
+		Type:        SessionTypeOnline,
+		rng:         utils.NewRandom(1),
+		errorSim:    errorSim,
+		metrics:     NewEnhancedMetrics(errorSim),
+		auditWriter: NewAuditWriter(nil, DefaultAuditWriterConfig()),
+		opLog:       opLog,
+	}
+	if err := session.executeTransfer(); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("transfer returned %v", err)
+	}
+	if err := opLog.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := ReadOpLog(f)
+	if err != nil {
+		t.Fatalf("ReadOpLog: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("read %d operations, expected 1", len(entries))
+	}
+	if e := entries[0]; e.AccountID == nil || *e.AccountID != 9 || e.Amount <= 0 {
+		t.Errorf("failed transfer logged with account %v and amount %d, expected account 9 and its amount", e.AccountID, e.Amount)
+	}
+}
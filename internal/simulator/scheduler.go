@@ -18,6 +18,7 @@ type Scheduler struct {
 	queries  *database.Queries
 	config   config.SimulateConfig
 	activity *ActivityCalculator
+	clock    *Clock // Simulated time source (nil = real time)
 
 	// Customer cache for weighted selection
 	customersByTZ map[string][]int64 // timezone -> customer IDs
@@ -50,10 +51,14 @@ func NewScheduler(queries *database.Queries, cfg config.SimulateConfig) *Schedul
 	activity.SetSessionTypeRatios(cfg.ATMSessionRatio, cfg.OnlineSessionRatio, cfg.BusinessSessionRatio)
 	activity.SetPayrollBurst(cfg.BurstMultiplier)
 
+	clock := NewClock(cfg.ClockAcceleration)
+	activity.SetClock(clock)
+
 	return &Scheduler{
 		queries:         queries,
 		config:          cfg,
 		activity:        activity,
+		clock:           clock,
 		customersByTZ:   make(map[string][]int64),
 		burstEnabled:    cfg.EnablePayrollBurst || cfg.EnableLunchBurst,
 		burstMultiplier: cfg.BurstMultiplier,
@@ -68,6 +73,16 @@ func (s *Scheduler) GetActivityCalculator() *ActivityCalculator {
 	return s.activity
 }
 
+// Now returns the scheduler's current (possibly accelerated) time
+func (s *Scheduler) Now() time.Time {
+	return s.clock.Now()
+}
+
+// IsAccelerated reports whether the scheduler runs on an accelerated clock
+func (s *Scheduler) IsAccelerated() bool {
+	return s.clock.Accelerated()
+}
+
 // RefreshCustomerCache loads customer IDs grouped by timezone.
 // This should be called periodically to handle new customers.
 func (s *Scheduler) RefreshCustomerCache(ctx context.Context) error {
@@ -294,7 +309,7 @@ func (s *Scheduler) ScheduleNextSession(ctx context.Context, rng *utils.Random)
 		Customer:         customer,
 		SessionType:      decision.RecommendedType,
 		ActivityDecision: decision,
-		ScheduledAt:      time.Now(),
+		ScheduledAt:      s.clock.Now(),
 	}, nil
 }
 
@@ -1,9 +1,12 @@
 package simulator
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -40,10 +43,32 @@ type SessionManager struct {
 	// Metrics and audit
 	metrics     *EnhancedMetrics
 	auditWriter *AuditWriter
+	opLog       *OpLog // Every executed operation, if config.OpLogFile is set
 
 	// Graceful shutdown
 	drainTimeout time.Duration
 	stopping     atomic.Bool
+	stopped      chan struct{} // Closed when Stop completes
+	drain        DrainReport
+
+	// Stop conditions (MaxOperations, Duration)
+	startTime time.Time
+
+	// Connection pool health checks
+	healthChecks   atomic.Int64
+	evictedConns   atomic.Int64
+	healthCheckErr atomic.Int64
+}
+
+// DrainReport describes how the last shutdown ended. A truncated shutdown hit
+// the drain timeout with sessions still running; Abandoned counts what they
+// were doing ("idle" for sessions between operations).
+type DrainReport struct {
+	Duration  time.Duration
+	TimedOut  bool
+	InFlight  int
+	Abandoned map[OperationType]int
+	BySession map[SessionType]int
 }
 
 // NewSessionManager creates a new session manager
@@ -53,6 +78,9 @@ func NewSessionManager(pool *database.Pool, cfg config.SimulateConfig, seed int6
 	rng := utils.NewRandom(seed)
 	queries := database.NewQueries(pool)
 
+	// Bursts trigger on the scheduler's clock, so they follow an accelerated day
+	scheduler := NewScheduler(queries, cfg)
+
 	// Initialize burst manager with configured providers
 	burstMgr := burst.NewManager()
 
@@ -66,7 +94,9 @@ func NewSessionManager(pool *database.Pool, cfg config.SimulateConfig, seed int6
 		if lunchCfg.Multiplier == 0 {
 			lunchCfg.Multiplier = cfg.BurstMultiplier
 		}
-		burstMgr.RegisterProvider(burst.NewLunchBurst(lunchCfg))
+		lunchBurst := burst.NewLunchBurst(lunchCfg)
+		lunchBurst.SetClock(scheduler.Now)
+		burstMgr.RegisterProvider(lunchBurst)
 	}
 
 	// Register payroll burst provider
@@ -79,7 +109,9 @@ func NewSessionManager(pool *database.Pool, cfg config.SimulateConfig, seed int6
 		if payrollCfg.Multiplier == 0 {
 			payrollCfg.Multiplier = cfg.BurstMultiplier
 		}
-		burstMgr.RegisterProvider(burst.NewPayrollBurst(payrollCfg))
+		payrollBurst := burst.NewPayrollBurst(payrollCfg)
+		payrollBurst.SetClock(scheduler.Now)
+		burstMgr.RegisterProvider(payrollBurst)
 	}
 
 	// Register random burst provider
@@ -106,12 +138,17 @@ func NewSessionManager(pool *database.Pool, cfg config.SimulateConfig, seed int6
 	// Initialize audit writer
 	auditWriter := NewAuditWriter(pool, DefaultAuditWriterConfig())
 
+	drainTimeout := cfg.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = config.GracefulShutdownTimeout
+	}
+
 	return &SessionManager{
 		pool:         pool,
 		queries:      queries,
 		config:       cfg,
 		rng:          rng,
-		scheduler:    NewScheduler(queries, cfg),
+		scheduler:    scheduler,
 		burstMgr:     burstMgr,
 		loadCtrl:     loadCtrl,
 		errorSim:     errorSim,
@@ -119,47 +156,63 @@ func NewSessionManager(pool *database.Pool, cfg config.SimulateConfig, seed int6
 		cancel:       cancel,
 		metrics:      NewEnhancedMetrics(errorSim),
 		auditWriter:  auditWriter,
-		drainTimeout: 30 * time.Second,
+		drainTimeout: drainTimeout,
+		stopped:      make(chan struct{}),
 	}
 }
 
 // Start launches the simulation with the configured number of concurrent sessions
 func (sm *SessionManager) Start() error {
-	fmt.Printf("Starting simulation with %d concurrent sessions...\n", sm.config.NumSessions)
+	sm.logf("Starting simulation with %d concurrent sessions...\n", sm.config.NumSessions)
+	sm.startTime = time.Now()
 
 	// Start audit writer
 	sm.auditWriter.Start()
-	fmt.Println("Audit writer started")
+	sm.logf("Audit writer started\n")
+
+	if sm.config.OpLogFile != "" {
+		opLog, err := NewOpLog(sm.config.OpLogFile)
+		if err != nil {
+			return err
+		}
+		sm.opLog = opLog
+		sm.opLog.Start()
+		sm.logf("Recording operations to %s\n", sm.config.OpLogFile)
+	}
 
 	// Initialize scheduler's customer cache for weighted timezone selection
-	fmt.Println("Building timezone-aware customer cache...")
+	sm.logf("Building timezone-aware customer cache...\n")
 	if err := sm.scheduler.RefreshCustomerCache(sm.ctx); err != nil {
 		fmt.Printf("Warning: Could not build customer cache (will use random selection): %v\n", err)
 	} else {
 		cacheStats := sm.scheduler.GetCacheStats()
-		fmt.Printf("Cached %d customers across %d timezones\n",
+		sm.logf("Cached %d customers across %d timezones\n",
 			cacheStats.TotalCustomers, cacheStats.TimezoneCount)
 	}
 
+	if sm.config.WarmUpDuration > 0 {
+		sm.warmUpPool()
+	}
+
 	// Show initial global activity snapshot
 	activity := sm.scheduler.GetGlobalActivitySummary()
-	fmt.Printf("Current global activity level: %s\n", activity)
+	sm.logf("Current global activity level: %s\n", activity)
 
 	// Display burst configuration
 	sm.printBurstConfig()
 
 	// Launch burst manager background monitoring
 	go sm.burstMgr.Run(sm.ctx, 30*time.Second, func(event *burst.BurstEvent) {
-		fmt.Printf("[BURST] %s triggered: %.1fx multiplier for %s\n",
+		sm.logf("[BURST] %s triggered: %.1fx multiplier for %s\n",
 			event.Type, event.Multiplier, event.RemainingDuration().Round(time.Second))
 	})
 
 	// Launch load controller if ramp is enabled
 	if sm.config.EnableRamp {
-		fmt.Printf("Load ramping enabled: %s ramp-up, %s ramp-down\n",
+		sm.logf("Load ramping enabled: %s ramp-up, %s ramp-down\n",
 			sm.config.RampUpDuration, sm.config.RampDownDuration)
 		sm.loadCtrl.SetOnPhaseChange(func(phase LoadPhase) {
-			fmt.Printf("[LOAD] Phase changed: %s\n", phase)
+			sm.logf("[LOAD] Phase changed: %s\n", phase)
 		})
 		go sm.loadCtrl.Run(sm.ctx)
 	} else {
@@ -173,13 +226,17 @@ func (sm *SessionManager) Start() error {
 	// Launch periodic cache refresh (every 5 minutes)
 	go sm.refreshCachePeriodically()
 
+	if sm.config.HealthCheckInterval > 0 {
+		go sm.checkPoolHealth()
+	}
+
 	// Launch session workers
 	for i := 0; i < sm.config.NumSessions; i++ {
 		sm.wg.Add(1)
 		go sm.runSession(i)
 	}
 
-	fmt.Println("All sessions started. Press Ctrl+C to stop.")
+	sm.logf("All sessions started. Press Ctrl+C to stop.\n")
 	return nil
 }
 
@@ -187,7 +244,7 @@ func (sm *SessionManager) Start() error {
 func (sm *SessionManager) TriggerManualBurst(multiplier float64, duration time.Duration, extraSessions int) *burst.BurstEvent {
 	event := sm.burstMgr.TriggerManualBurst(multiplier, duration, extraSessions)
 	if event != nil {
-		fmt.Printf("[BURST] Manual burst triggered: %.1fx multiplier for %s\n",
+		sm.logf("[BURST] Manual burst triggered: %.1fx multiplier for %s\n",
 			multiplier, duration)
 	}
 	return event
@@ -222,9 +279,65 @@ func (sm *SessionManager) printBurstConfig() {
 	}
 
 	if len(enabled) > 0 {
-		fmt.Printf("Burst scenarios enabled: %v\n", enabled)
+		sm.logf("Burst scenarios enabled: %v\n", enabled)
 	} else {
-		fmt.Println("No burst scenarios enabled")
+		sm.logf("No burst scenarios enabled\n")
+	}
+}
+
+// logf prints a status line unless the config asks for quiet output
+func (sm *SessionManager) logf(format string, args ...any) {
+	if !sm.config.Quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// warmUpPool opens and pings the pool's connections before any session
+// starts. Metrics and the run's stop conditions start afresh afterwards, so
+// neither the warm-up nor cold connections skew early latency and TPS.
+func (sm *SessionManager) warmUpPool() {
+	target := sm.config.WarmUpConnections
+	if target <= 0 {
+		target = cmp.Or(sm.pool.DB().Stats().MaxOpenConnections, config.DBMaxOpenConns)
+	}
+	sm.logf("Warming up %d connections for %s...\n", target, sm.config.WarmUpDuration)
+
+	report, err := sm.pool.WarmUp(sm.ctx, target, sm.config.WarmUpDuration)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: connection pool warm-up failed: %v\n", err)
+	} else {
+		sm.logf("Warmed up %d/%d connections (%d pings) in %s\n",
+			report.Opened, report.Target, report.Pings, report.Duration.Round(time.Millisecond))
+	}
+
+	sm.metrics = NewEnhancedMetrics(sm.errorSim)
+	sm.startTime = time.Now()
+}
+
+// checkPoolHealth periodically pings idle pool connections, evicting dead ones
+func (sm *SessionManager) checkPoolHealth() {
+	ticker := time.NewTicker(sm.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(sm.ctx, 10*time.Second)
+			_, evicted, err := sm.pool.HealthCheck(ctx)
+			cancel()
+			sm.healthChecks.Add(1)
+			if err != nil {
+				// The pool may be busy; try again next time
+				sm.healthCheckErr.Add(1)
+				continue
+			}
+			if evicted > 0 {
+				sm.evictedConns.Add(int64(evicted))
+				sm.logf("[POOL] Health check evicted %d dead connections\n", evicted)
+			}
+		case <-sm.ctx.Done():
+			return
+		}
 	}
 }
 
@@ -246,13 +359,34 @@ func (sm *SessionManager) refreshCachePeriodically() {
 	}
 }
 
-// Stop gracefully shuts down all sessions
+// Done returns a channel that is closed once the simulation has stopped,
+// whether by Stop or by reaching a stop condition
+func (sm *SessionManager) Done() <-chan struct{} {
+	return sm.stopped
+}
+
+// stopCondition returns why the run should end, or "" while no configured
+// limit (MaxOperations, Duration) has been reached
+func (sm *SessionManager) stopCondition() string {
+	if sm.config.MaxOperations > 0 && sm.metrics.TotalOperations() >= sm.config.MaxOperations {
+		return fmt.Sprintf("max operations (%d) reached", sm.config.MaxOperations)
+	}
+	if sm.config.Duration > 0 && time.Since(sm.startTime) >= sm.config.Duration {
+		return fmt.Sprintf("max duration (%s) reached", sm.config.Duration)
+	}
+	return ""
+}
+
+// Stop gracefully shuts down all sessions. Concurrent callers wait for the
+// first to finish.
 func (sm *SessionManager) Stop() {
 	if sm.stopping.Swap(true) {
-		return // Already stopping
+		<-sm.stopped // Already stopping
+		return
 	}
+	defer close(sm.stopped)
 
-	fmt.Println("\nInitiating graceful shutdown...")
+	sm.logf("\nInitiating graceful shutdown...\n")
 	startTime := time.Now()
 
 	// Signal all sessions to stop
@@ -267,23 +401,73 @@ func (sm *SessionManager) Stop() {
 
 	select {
 	case <-done:
-		fmt.Printf("All sessions stopped in %s\n", time.Since(startTime).Round(time.Millisecond))
+		sm.drain = DrainReport{Duration: time.Since(startTime)}
+		sm.logf("All sessions stopped in %s\n", sm.drain.Duration.Round(time.Millisecond))
 	case <-time.After(sm.drainTimeout):
-		fmt.Printf("Warning: Timeout waiting for sessions after %s\n", sm.drainTimeout)
+		sm.drain = sm.inFlightReport()
+		sm.drain.Duration = time.Since(startTime)
+		fmt.Printf("Warning: Timeout waiting for sessions after %s: %d still in flight (%s)\n",
+			sm.drainTimeout, sm.drain.InFlight, formatOpCounts(sm.drain.Abandoned))
 	}
 
 	// Stop audit writer (drains remaining logs)
-	fmt.Println("Draining audit log buffer...")
+	sm.logf("Draining audit log buffer...\n")
 	if err := sm.auditWriter.Stop(); err != nil {
 		fmt.Printf("Warning: Audit writer shutdown error: %v\n", err)
 	} else {
-		fmt.Println("Audit writer stopped")
+		sm.logf("Audit writer stopped\n")
+	}
+	if sm.opLog != nil {
+		if err := sm.opLog.Stop(); err != nil {
+			fmt.Printf("Warning: Op log shutdown error: %v\n", err)
+		}
 	}
 
-	fmt.Println("Shutdown complete.")
+	sm.logf("Shutdown complete.\n")
 	sm.printFinalStats()
 }
 
+// GetDrainReport returns how the last shutdown ended (zero before Stop)
+func (sm *SessionManager) GetDrainReport() DrainReport {
+	return sm.drain
+}
+
+// inFlightReport counts the sessions still running and the operations they are in
+func (sm *SessionManager) inFlightReport() DrainReport {
+	report := DrainReport{
+		TimedOut:  true,
+		Abandoned: make(map[OperationType]int),
+		BySession: make(map[SessionType]int),
+	}
+	sm.sessions.Range(func(_, value interface{}) bool {
+		session := value.(*CustomerSession)
+		op := session.inFlightOp()
+		if op == "" {
+			op = "idle"
+		}
+		report.InFlight++
+		report.Abandoned[op]++
+		report.BySession[session.Type]++
+		return true
+	})
+	return report
+}
+
+// formatOpCounts renders operation counts as "transfer=2, withdrawal=1" in a stable order
+func formatOpCounts(counts map[OperationType]int) string {
+	ops := make([]string, 0, len(counts))
+	for op := range counts {
+		ops = append(ops, string(op))
+	}
+	sort.Strings(ops)
+
+	parts := make([]string, len(ops))
+	for i, op := range ops {
+		parts[i] = fmt.Sprintf("%s=%d", op, counts[OperationType(op)])
+	}
+	return strings.Join(parts, ", ")
+}
+
 // Wait blocks until all sessions complete (when Stop is called)
 func (sm *SessionManager) Wait() {
 	sm.wg.Wait()
@@ -358,6 +542,8 @@ func (sm *SessionManager) createSession(workerID int, rng *utils.Random) (*Custo
 		metrics:     sm.metrics,
 		errorSim:    sm.errorSim,
 		auditWriter: sm.auditWriter,
+		opLog:       sm.opLog,
+		clock:       sm.scheduler.clock,
 		ctx:         sm.ctx,
 	}
 
@@ -422,8 +608,22 @@ func (sm *SessionManager) reportMetrics() {
 	ticker := time.NewTicker(sm.config.MetricsInterval)
 	defer ticker.Stop()
 
+	// Stop conditions are checked more often than metrics are printed
+	var limitCh <-chan time.Time
+	if sm.config.MaxOperations > 0 || sm.config.Duration > 0 {
+		limitTicker := time.NewTicker(100 * time.Millisecond)
+		defer limitTicker.Stop()
+		limitCh = limitTicker.C
+	}
+
 	for {
 		select {
+		case <-limitCh:
+			if reason := sm.stopCondition(); reason != "" {
+				sm.logf("\nStop condition: %s\n", reason)
+				sm.Stop()
+				return
+			}
 		case <-ticker.C:
 			stats := sm.metrics.Snapshot()
 			globalActivity := sm.scheduler.GetGlobalActivitySummary()
@@ -444,7 +644,13 @@ func (sm *SessionManager) reportMetrics() {
 				loadStatus = fmt.Sprintf(" | Load: %s", sm.loadCtrl.StatusString())
 			}
 
-			fmt.Printf("[%s] Activity: %-12s | Sessions: %d | TPS: %.1f (recent: %.1f) | Errors: %d | Latency: avg=%s p95=%s%s%s\n",
+			// Simulated time when the clock is accelerated
+			clockInfo := ""
+			if sm.scheduler.IsAccelerated() {
+				clockInfo = fmt.Sprintf(" | Sim clock: %s", sm.scheduler.Now().UTC().Format("Mon Jan 2 15:04 UTC"))
+			}
+
+			sm.logf("[%s] Activity: %-12s | Sessions: %d | TPS: %.1f (recent: %.1f) | Errors: %d | Latency: avg=%s p95=%s%s%s%s\n",
 				time.Now().Format("15:04:05"),
 				globalActivity,
 				sm.countActiveSessions(),
@@ -455,6 +661,7 @@ func (sm *SessionManager) reportMetrics() {
 				stats.P95Latency.Round(time.Microsecond),
 				burstInfo,
 				loadStatus,
+				clockInfo,
 			)
 		case <-sm.ctx.Done():
 			return
@@ -570,6 +777,42 @@ func (sm *SessionManager) printFinalStats() {
 		fmt.Printf("Dropped Logs:       %d\n", auditStats.DroppedLogs)
 	}
 
+	if sm.opLog != nil {
+		opStats := sm.opLog.GetStats()
+		fmt.Println("\n--- Operation Log ---")
+		fmt.Printf("File:               %s\n", sm.config.OpLogFile)
+		fmt.Printf("Operations Written: %d\n", opStats.LogsWritten)
+		if opStats.WriteErrors > 0 {
+			fmt.Printf("Write Errors:       %d\n", opStats.WriteErrors)
+		}
+		if opStats.DroppedLogs > 0 {
+			fmt.Printf("Dropped:            %d\n", opStats.DroppedLogs)
+		}
+	}
+
+	if sm.config.HealthCheckInterval > 0 {
+		fmt.Println("\n--- Connection Pool ---")
+		fmt.Printf("Health Checks:      %d (every %s)\n", sm.healthChecks.Load(), sm.config.HealthCheckInterval)
+		fmt.Printf("Evicted:            %d dead connections\n", sm.evictedConns.Load())
+		if failed := sm.healthCheckErr.Load(); failed > 0 {
+			fmt.Printf("Failed Checks:      %d\n", failed)
+		}
+	}
+
+	// Shutdown drain
+	fmt.Println("\n--- Shutdown ---")
+	if sm.drain.TimedOut {
+		fmt.Printf("Result:             truncated after %s (drain timeout %s)\n",
+			sm.drain.Duration.Round(time.Millisecond), sm.drainTimeout)
+		fmt.Printf("In-flight Sessions: %d\n", sm.drain.InFlight)
+		for st, count := range sm.drain.BySession {
+			fmt.Printf("  %-15s: %d\n", st.String(), count)
+		}
+		fmt.Printf("Abandoned:          %s\n", formatOpCounts(sm.drain.Abandoned))
+	} else {
+		fmt.Printf("Result:             clean in %s\n", sm.drain.Duration.Round(time.Millisecond))
+	}
+
 	// Show top 5 timezones by activity
 	if len(schedStats.ScheduledByTimezone) > 0 {
 		fmt.Println("\nTop Timezones by Session Count:")
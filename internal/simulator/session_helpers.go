@@ -8,6 +8,8 @@
 // - checkBalance: Queries balance for primary account
 // - checkBalanceForAccount: Queries balance for specific account
 // - thinkTime: Waits for realistic user delay
+// - withInjectedFailures: Runs a write with injected failures and retries
+// - beginOp/inFlightOp: Track the operation in progress for shutdown reporting and the op log
 // - recordAuditLog: Creates audit log entries
 // - generateFakeIP: Creates plausible IP addresses
 // - generateUserAgent: Returns user agent strings
@@ -39,7 +41,9 @@ func (s *CustomerSession) checkBalance() error {
 }
 
 // checkBalanceForAccount queries the balance of a specific account
-func (s *CustomerSession) checkBalanceForAccount(account *models.Account) error {
+func (s *CustomerSession) checkBalanceForAccount(account *models.Account) (err error) {
+	defer s.beginOp(OpBalanceCheck)(&err)
+	s.opTarget(account.ID, 0)
 	start := s.startTimer()
 
 	// Check for simulated timeout
@@ -52,7 +56,7 @@ func (s *CustomerSession) checkBalanceForAccount(account *models.Account) error
 	ctx, cancel := s.timeoutContext(5)
 	defer cancel()
 
-	_, err := s.queries.GetAccountBalance(ctx, account.ID)
+	_, err = s.queries.GetAccountBalance(ctx, account.ID)
 	latency := s.elapsed(start)
 
 	if err != nil {
@@ -84,6 +88,75 @@ func (s *CustomerSession) thinkTime() {
 	}
 }
 
+// withInjectedFailures runs a write through RetryableOperation, failing
+// attempts at the configured deadlock, duplicate-key, connection-reset and
+// timeout rates. Failures that were retried are recorded here; the caller
+// records the error it gets back as before.
+func (s *CustomerSession) withInjectedFailures(ctx context.Context, write func(ctx context.Context) error) error {
+	var injected []error
+	err := NewRetryableOperation(s.errorSim, s.rng, DefaultRetryConfig()).Execute(ctx, func(ctx context.Context) error {
+		if err := s.errorSim.InjectWriteFailure(s.rng); err != nil {
+			injected = append(injected, err)
+			return err
+		}
+		return write(ctx)
+	})
+
+	for i, injectedErr := range injected {
+		if i == len(injected)-1 && err == injectedErr {
+			break
+		}
+		s.metrics.RecordError(ClassifyError(injectedErr))
+	}
+	return err
+}
+
+// beginOp marks op as in progress and returns a func that clears it and
+// records the operation to the op log, if there is one. Operations pass
+// their (named) error result so the log has the outcome:
+//
+//	func (s *CustomerSession) executeTransfer() (err error) {
+//		defer s.beginOp(OpTransfer)(&err)
+func (s *CustomerSession) beginOp(op OperationType) func(err *error) {
+	s.currentOp.Store(op)
+	s.opStart, s.opAccountID, s.opAmount = time.Now(), nil, 0
+	return func(err *error) {
+		s.currentOp.Store(OperationType(""))
+		if s.opLog == nil {
+			return
+		}
+		entry := OpLogEntry{
+			Timestamp: s.opStart,
+			SessionID: s.ID,
+			Operation: op,
+			AccountID: s.opAccountID,
+			Amount:    s.opAmount,
+			Result:    OpLogResultOK,
+			Latency:   time.Since(s.opStart),
+		}
+		if s.Customer != nil {
+			entry.CustomerID = s.Customer.ID
+		}
+		if err != nil && *err != nil {
+			entry.Result = string(ClassifyError(*err))
+			entry.Error = (*err).Error()
+		}
+		s.opLog.Record(entry)
+	}
+}
+
+// opTarget records the account and amount (0 for reads) the operation in
+// progress acts on, for the op log
+func (s *CustomerSession) opTarget(accountID, amount int64) {
+	s.opAccountID, s.opAmount = &accountID, amount
+}
+
+// inFlightOp returns the operation in progress, or "" between operations
+func (s *CustomerSession) inFlightOp() OperationType {
+	op, _ := s.currentOp.Load().(OperationType)
+	return op
+}
+
 // recordAuditLog creates an audit log entry for the action
 func (s *CustomerSession) recordAuditLog(action models.AuditAction, outcome models.AuditOutcome, accountID *int64, reason string) {
 	var channel models.AuditChannel
@@ -0,0 +1,58 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInFlightReport(t *testing.T) {
+	sm := &SessionManager{}
+
+	transfer := &CustomerSession{ID: "a", Type: SessionTypeOnline}
+	transfer.beginOp(OpTransfer)
+	withdrawal := &CustomerSession{ID: "b", Type: SessionTypeATM}
+	withdrawal.beginOp(OpWithdrawal)
+	idle := &CustomerSession{ID: "c", Type: SessionTypeOnline}
+	done := idle.beginOp(OpBalanceCheck)
+	done(nil)
+
+	for _, s := range []*CustomerSession{transfer, withdrawal, idle} {
+		sm.sessions.Store(s.ID, s)
+	}
+
+	report := sm.inFlightReport()
+	if !report.TimedOut || report.InFlight != 3 {
+		t.Fatalf("Expected a timed-out report with 3 sessions, got %+v", report)
+	}
+	if report.BySession[SessionTypeOnline] != 2 || report.BySession[SessionTypeATM] != 1 {
+		t.Errorf("Unexpected session types: %v", report.BySession)
+	}
+	if got, want := formatOpCounts(report.Abandoned), "idle=1, transfer=1, withdrawal=1"; got != want {
+		t.Errorf("Abandoned = %q, want %q", got, want)
+	}
+}
+
+func TestStopCondition(t *testing.T) {
+	sm := &SessionManager{metrics: NewEnhancedMetrics(nil), startTime: time.Now()}
+	if reason := sm.stopCondition(); reason != "" {
+		t.Fatalf("Expected no stop condition without limits, got %q", reason)
+	}
+
+	sm.config.MaxOperations = 2
+	sm.metrics.RecordOperation(OpBalanceCheck, false, time.Millisecond)
+	if reason := sm.stopCondition(); reason != "" {
+		t.Fatalf("Expected to keep running after 1 of 2 operations, got %q", reason)
+	}
+	sm.metrics.RecordOperation(OpTransfer, true, time.Millisecond)
+	if reason := sm.stopCondition(); !strings.Contains(reason, "max operations") {
+		t.Errorf("Expected max operations stop, got %q", reason)
+	}
+
+	sm.config.MaxOperations = 0
+	sm.config.Duration = time.Minute
+	sm.startTime = time.Now().Add(-2 * time.Minute)
+	if reason := sm.stopCondition(); !strings.Contains(reason, "max duration") {
+		t.Errorf("Expected max duration stop, got %q", reason)
+	}
+}
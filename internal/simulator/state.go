@@ -21,6 +21,7 @@ package simulator
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/willfong/load-generator/internal/config"
@@ -83,6 +84,15 @@ type CustomerSession struct {
 	// For ATM sessions
 	ATM *models.ATM
 
+	// Operation in progress, read by the shutdown drain report
+	currentOp atomic.Value // OperationType
+
+	// Operation in progress as recorded to the op log: when it started and
+	// the account and amount it acts on
+	opStart     time.Time
+	opAccountID *int64
+	opAmount    int64
+
 	// Dependencies
 	rng         *utils.Random
 	queries     *database.Queries
@@ -90,11 +100,15 @@ type CustomerSession struct {
 	metrics     *EnhancedMetrics
 	errorSim    *ErrorSimulator
 	auditWriter *AuditWriter
+	opLog       *OpLog // nil unless operations are logged
+	clock       *Clock // Scheduler's time source, for payroll days (nil = real time)
 	ctx         context.Context
 }
 
 // Authenticate simulates login or PIN verification
 func (s *CustomerSession) Authenticate() bool {
+	var err error
+	defer s.beginOp(OpLogin)(&err)
 	s.State = StateAuthenticating
 	start := time.Now()
 
@@ -103,6 +117,7 @@ func (s *CustomerSession) Authenticate() bool {
 		s.recordAuditLog(models.AuditLoginFailed, models.OutcomeFailure, nil, "Invalid credentials")
 		s.metrics.RecordError(ErrorTypeAuth)
 		s.errorSim.RecordError(ErrorTypeAuth)
+		err = ErrAuthenticationFailed
 		s.State = StateFailed
 		s.thinkTime()
 		return false
@@ -0,0 +1,117 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Summary is the machine-readable form of the final simulation statistics
+type Summary struct {
+	Seed            int64          `json:"seed"`
+	Concurrency     int            `json:"concurrency"`
+	UptimeMS        int64          `json:"uptime_ms"`
+	TotalSessions   int64          `json:"total_sessions"`
+	TotalOperations int64          `json:"total_operations"`
+	ReadOps         int64          `json:"read_operations"`
+	WriteOps        int64          `json:"write_operations"`
+	TotalErrors     int64          `json:"total_errors"`
+	TPS             float64        `json:"tps"`
+	LatencyMS       LatencySummary `json:"latency_ms"`
+
+	OperationStats map[OperationType]OperationSummary `json:"operations"`
+	SessionTypes   map[string]int64                   `json:"session_types"`
+	ErrorStats     map[ErrorType]int64                `json:"errors,omitempty"`
+	Retries        RetryStats                         `json:"retries"`
+	Bursts         int64                              `json:"bursts"`
+	AuditLogs      int64                              `json:"audit_logs_written"`
+	DroppedLogs    int64                              `json:"audit_logs_dropped"`
+	Shutdown       ShutdownSummary                    `json:"shutdown"`
+}
+
+// LatencySummary holds latency percentiles in milliseconds
+type LatencySummary struct {
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+// OperationSummary holds one operation type's count and latency
+type OperationSummary struct {
+	Count     int64          `json:"count"`
+	LatencyMS LatencySummary `json:"latency_ms"`
+}
+
+// ShutdownSummary is the JSON form of the DrainReport
+type ShutdownSummary struct {
+	DurationMS int64                 `json:"duration_ms"`
+	TimedOut   bool                  `json:"timed_out"`
+	InFlight   int                   `json:"in_flight,omitempty"`
+	Abandoned  map[OperationType]int `json:"abandoned,omitempty"`
+}
+
+func latencySummary(avg, p50, p95, p99 time.Duration) LatencySummary {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return LatencySummary{Avg: ms(avg), P50: ms(p50), P95: ms(p95), P99: ms(p99)}
+}
+
+// Summary collects the final statistics printed on Stop for tooling
+func (sm *SessionManager) Summary() Summary {
+	stats := sm.metrics.Snapshot()
+	auditStats := sm.auditWriter.GetStats()
+
+	s := Summary{
+		Seed:            sm.config.Seed,
+		Concurrency:     sm.config.NumSessions,
+		UptimeMS:        stats.Uptime.Milliseconds(),
+		TotalSessions:   stats.TotalSessions,
+		TotalOperations: stats.TotalOperations,
+		ReadOps:         stats.ReadOps,
+		WriteOps:        stats.WriteOps,
+		TotalErrors:     stats.TotalErrors,
+		TPS:             stats.TPS,
+		LatencyMS:       latencySummary(stats.AvgLatency, stats.P50Latency, stats.P95Latency, stats.P99Latency),
+		OperationStats:  make(map[OperationType]OperationSummary),
+		SessionTypes:    make(map[string]int64),
+		ErrorStats:      stats.ErrorStats,
+		Retries:         sm.errorSim.GetRetryStats(),
+		Bursts:          sm.burstMgr.GetStats().TotalBurstsTriggered,
+		AuditLogs:       auditStats.LogsWritten,
+		DroppedLogs:     auditStats.DroppedLogs,
+		Shutdown: ShutdownSummary{
+			DurationMS: sm.drain.Duration.Milliseconds(),
+			TimedOut:   sm.drain.TimedOut,
+			InFlight:   sm.drain.InFlight,
+			Abandoned:  sm.drain.Abandoned,
+		},
+	}
+	for op, stat := range stats.OperationStats {
+		if stat.Count > 0 {
+			s.OperationStats[op] = OperationSummary{
+				Count:     stat.Count,
+				LatencyMS: latencySummary(stat.AvgLatency, stat.P50Latency, stat.P95Latency, stat.P99Latency),
+			}
+		}
+	}
+	for st, count := range stats.SessionStats {
+		if count > 0 {
+			s.SessionTypes[st.String()] = count
+		}
+	}
+	return s
+}
+
+// WriteLatencyHistograms writes the run's latency histograms, per operation
+// type and combined, to a CSV file at path
+func (sm *SessionManager) WriteLatencyHistograms(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create latency histogram file: %w", err)
+	}
+	if err := sm.metrics.WriteLatencyHistograms(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
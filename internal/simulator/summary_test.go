@@ -0,0 +1,105 @@
+package simulator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/willfong/load-generator/internal/config"
+)
+
+func TestSummary(t *testing.T) {
+	sm := NewSessionManager(nil, config.SimulateConfig{NumSessions: 4, Seed: 9}, 9)
+
+	sm.metrics.RecordOperation(OpBalanceCheck, false, 2*time.Millisecond)
+	sm.metrics.RecordOperation(OpBalanceCheck, false, 4*time.Millisecond)
+	sm.metrics.RecordOperation(OpTransfer, true, 10*time.Millisecond)
+	sm.metrics.RecordSessionComplete(SessionTypeOnline)
+	sm.metrics.RecordSessionComplete(SessionTypeATM)
+	sm.metrics.RecordError(ErrorTypeDeadlock)
+	sm.metrics.RecordError(ErrorTypeDatabase)
+	sm.burstMgr.TriggerManualBurst(2, time.Minute, 1)
+	sm.drain = DrainReport{Duration: 1500 * time.Millisecond, TimedOut: true, InFlight: 1, Abandoned: map[OperationType]int{OpTransfer: 1}}
+
+	s := sm.Summary()
+	if s.Seed != 9 || s.Concurrency != 4 {
+		t.Errorf("Expected seed 9 and concurrency 4, got %d and %d", s.Seed, s.Concurrency)
+	}
+	if s.TotalOperations != 3 || s.ReadOps != 2 || s.WriteOps != 1 {
+		t.Errorf("Expected 3 operations (2 reads, 1 write), got %d (%d, %d)", s.TotalOperations, s.ReadOps, s.WriteOps)
+	}
+	if s.TotalSessions != 2 || s.SessionTypes["Online"] != 1 || s.SessionTypes["ATM"] != 1 || len(s.SessionTypes) != 2 {
+		t.Errorf("Unexpected sessions: %d %v", s.TotalSessions, s.SessionTypes)
+	}
+	if len(s.OperationStats) != 2 || s.OperationStats[OpBalanceCheck].Count != 2 || s.OperationStats[OpTransfer].Count != 1 {
+		t.Errorf("Expected only the recorded operation types, got %v", s.OperationStats)
+	}
+	if got := s.OperationStats[OpTransfer].LatencyMS.Avg; got != 10 {
+		t.Errorf("Expected a 10ms average transfer latency, got %v", got)
+	}
+	// Simulated errors are broken down but only infrastructure ones count as errors
+	if s.TotalErrors != 1 || s.ErrorStats[ErrorTypeDeadlock] != 1 || s.ErrorStats[ErrorTypeDatabase] != 1 {
+		t.Errorf("Expected 1 counted error of 2 recorded, got %d %v", s.TotalErrors, s.ErrorStats)
+	}
+	if s.Bursts != 1 {
+		t.Errorf("Expected 1 burst, got %d", s.Bursts)
+	}
+	if s.Shutdown.DurationMS != 1500 || !s.Shutdown.TimedOut || s.Shutdown.InFlight != 1 || s.Shutdown.Abandoned[OpTransfer] != 1 {
+		t.Errorf("Unexpected shutdown summary: %+v", s.Shutdown)
+	}
+
+	raw, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{
+		"seed", "concurrency", "uptime_ms", "total_sessions", "total_operations", "read_operations",
+		"write_operations", "total_errors", "tps", "latency_ms", "operations", "session_types", "errors",
+		"retries", "bursts", "audit_logs_written", "audit_logs_dropped", "shutdown",
+	} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("Summary JSON is missing %q", key)
+		}
+	}
+	if len(got) != 18 {
+		t.Errorf("Expected 18 summary fields, got %d: %s", len(got), raw)
+	}
+
+	var nested struct {
+		LatencyMS  map[string]float64 `json:"latency_ms"`
+		Operations map[string]struct {
+			Count     int64              `json:"count"`
+			LatencyMS map[string]float64 `json:"latency_ms"`
+		} `json:"operations"`
+		Retries  map[string]int64           `json:"retries"`
+		Shutdown map[string]json.RawMessage `json:"shutdown"`
+	}
+	if err := json.Unmarshal(raw, &nested); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"avg", "p50", "p95", "p99"} {
+		if _, ok := nested.LatencyMS[key]; !ok {
+			t.Errorf("latency_ms is missing %q", key)
+		}
+		if _, ok := nested.Operations["transfer"].LatencyMS[key]; !ok {
+			t.Errorf("operations.transfer.latency_ms is missing %q", key)
+		}
+	}
+	if nested.Operations["balance_check"].Count != 2 {
+		t.Errorf("Expected operations keyed by type, got %s", raw)
+	}
+	for _, key := range []string{"total", "successful", "exhausted"} {
+		if _, ok := nested.Retries[key]; !ok {
+			t.Errorf("retries is missing %q", key)
+		}
+	}
+	for _, key := range []string{"duration_ms", "timed_out", "in_flight", "abandoned"} {
+		if _, ok := nested.Shutdown[key]; !ok {
+			t.Errorf("shutdown is missing %q", key)
+		}
+	}
+}
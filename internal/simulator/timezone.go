@@ -18,6 +18,9 @@ type TimezoneManager struct {
 	// Intraday pattern weights (24 hours)
 	// Higher weight = more likely to be active during that hour
 	intradayWeights [24]float64
+
+	// Time source (nil = real time)
+	clock *Clock
 }
 
 // NewTimezoneManager creates a new timezone manager with the given active window
@@ -69,6 +72,11 @@ func (tm *TimezoneManager) initIntradayWeights() {
 	tm.intradayWeights = weights
 }
 
+// SetClock sets the time source used for local time lookups
+func (tm *TimezoneManager) SetClock(clock *Clock) {
+	tm.clock = clock
+}
+
 // GetActiveStart returns the hour when activity begins (0-23)
 func (tm *TimezoneManager) GetActiveStart() int {
 	return tm.activeStart
@@ -102,7 +110,7 @@ func (tm *TimezoneManager) GetLocation(timezone string) *time.Location {
 // GetLocalTime returns the current time in the customer's timezone
 func (tm *TimezoneManager) GetLocalTime(timezone string) time.Time {
 	loc := tm.GetLocation(timezone)
-	return time.Now().In(loc)
+	return tm.clock.Now().In(loc)
 }
 
 // GetLocalHour returns the current hour (0-23) in the customer's timezone
@@ -17,6 +17,7 @@
 package simulator
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -74,7 +75,8 @@ func (s *CustomerSession) RunATMWorkflow() {
 }
 
 // withdraw performs an ATM withdrawal
-func (s *CustomerSession) withdraw() error {
+func (s *CustomerSession) withdraw() (err error) {
+	defer s.beginOp(OpWithdrawal)(&err)
 	if len(s.Accounts) == 0 {
 		return fmt.Errorf("no accounts available")
 	}
@@ -101,6 +103,7 @@ func (s *CustomerSession) withdraw() error {
 	// Generate realistic withdrawal amount (multiples of 20)
 	amounts := []int64{2000, 4000, 6000, 8000, 10000, 20000} // cents
 	amount := amounts[s.rng.IntN(len(amounts))]
+	s.opTarget(account.ID, amount)
 
 	start := s.startTimer()
 
@@ -114,7 +117,12 @@ func (s *CustomerSession) withdraw() error {
 		atmID = &s.ATM.ID
 	}
 
-	txnID, err := s.queries.ExecuteWithdrawal(ctx, account.ID, amount, atmID, description)
+	var txnID int64
+	err = s.withInjectedFailures(ctx, func(ctx context.Context) error {
+		var err error
+		txnID, err = s.queries.ExecuteWithdrawal(ctx, account.ID, amount, atmID, description)
+		return err
+	})
 	latency := s.elapsed(start)
 
 	if err != nil {
@@ -141,7 +149,8 @@ func (s *CustomerSession) withdraw() error {
 }
 
 // deposit performs an ATM deposit
-func (s *CustomerSession) deposit() error {
+func (s *CustomerSession) deposit() (err error) {
+	defer s.beginOp(OpDeposit)(&err)
 	if len(s.Accounts) == 0 {
 		return fmt.Errorf("no accounts available")
 	}
@@ -162,6 +171,7 @@ func (s *CustomerSession) deposit() error {
 	// Deposits tend to be rounder numbers and larger than withdrawals
 	amounts := []int64{5000, 10000, 20000, 50000, 10000, 25000, 50000} // cents
 	amount := amounts[s.rng.IntN(len(amounts))]
+	s.opTarget(account.ID, amount)
 
 	start := s.startTimer()
 
@@ -175,7 +185,12 @@ func (s *CustomerSession) deposit() error {
 		atmID = &s.ATM.ID
 	}
 
-	txnID, err := s.queries.ExecuteDeposit(ctx, account.ID, amount, atmID, models.ChannelATM, description)
+	var txnID int64
+	err = s.withInjectedFailures(ctx, func(ctx context.Context) error {
+		var err error
+		txnID, err = s.queries.ExecuteDeposit(ctx, account.ID, amount, atmID, models.ChannelATM, description)
+		return err
+	})
 	latency := s.elapsed(start)
 
 	if err != nil {
@@ -19,9 +19,9 @@
 package simulator
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/willfong/load-generator/internal/database"
 	"github.com/willfong/load-generator/internal/models"
@@ -86,15 +86,17 @@ func (s *CustomerSession) RunBusinessWorkflow() {
 	s.recordAuditLog(models.AuditSessionEnded, models.OutcomeSuccess, nil, "")
 }
 
-// isPayrollPeriod checks if we're in the payroll window (days 25-28 of month)
+// isPayrollPeriod checks if the scheduler's clock is in the payroll window
+// (days 25-28 of month)
 func (s *CustomerSession) isPayrollPeriod() bool {
-	day := time.Now().Day()
+	day := s.clock.Now().Day()
 	return day >= 25 && day <= 28
 }
 
 // executeBatchPayroll performs a batch payroll payment (for business sessions)
 // This simulates a company paying multiple employees in a single batch
-func (s *CustomerSession) executeBatchPayroll() error {
+func (s *CustomerSession) executeBatchPayroll() (err error) {
+	defer s.beginOp(OpBatchPayroll)(&err)
 	if len(s.Accounts) == 0 {
 		return fmt.Errorf("no accounts available")
 	}
@@ -110,6 +112,7 @@ func (s *CustomerSession) executeBatchPayroll() error {
 	if sourceAccount == nil {
 		sourceAccount = s.Accounts[0] // Fall back to first account
 	}
+	s.opTarget(sourceAccount.ID, 0)
 
 	ctx, cancel := s.timeoutContext(30)
 	defer cancel()
@@ -131,6 +134,7 @@ func (s *CustomerSession) executeBatchPayroll() error {
 
 	// Build payment batch with realistic salary amounts
 	payments := make([]database.PayrollPayment, len(employeeAccounts))
+	var total int64
 	for i, empAcctID := range employeeAccounts {
 		// Realistic salary range: $2,000 - $8,000 per pay period
 		salary := int64(200000 + s.rng.IntN(600000)) // cents
@@ -138,12 +142,19 @@ func (s *CustomerSession) executeBatchPayroll() error {
 			DestAccountID: empAcctID,
 			Amount:        salary,
 		}
+		total += salary
 	}
+	s.opTarget(sourceAccount.ID, total)
 
 	start := s.startTimer()
 	description := fmt.Sprintf("Payroll Batch - Session %s", s.ID[:8])
 
-	result, err := s.queries.ExecuteBatchPayroll(ctx, sourceAccount.ID, payments, description)
+	var result *database.BatchPayrollResult
+	err = s.withInjectedFailures(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.queries.ExecuteBatchPayroll(ctx, sourceAccount.ID, payments, description)
+		return err
+	})
 	latency := s.elapsed(start)
 
 	if err != nil {
@@ -171,7 +182,8 @@ func (s *CustomerSession) executeBatchPayroll() error {
 
 // executeAccountSweep performs an automated cash sweep between accounts
 // This simulates treasury management where excess funds are moved to savings/investment
-func (s *CustomerSession) executeAccountSweep() error {
+func (s *CustomerSession) executeAccountSweep() (err error) {
+	defer s.beginOp(OpAccountSweep)(&err)
 	if len(s.Accounts) < 2 {
 		return fmt.Errorf("need at least 2 accounts for sweep")
 	}
@@ -201,6 +213,7 @@ func (s *CustomerSession) executeAccountSweep() error {
 	if destAccount == nil {
 		return fmt.Errorf("no destination account for sweep")
 	}
+	s.opTarget(sourceAccount.ID, 0)
 
 	// Target balance: keep some operating funds in checking
 	// Sweep anything above target to savings
@@ -212,7 +225,12 @@ func (s *CustomerSession) executeAccountSweep() error {
 	start := s.startTimer()
 	description := fmt.Sprintf("Account Sweep - Session %s", s.ID[:8])
 
-	result, err := s.queries.ExecuteSweep(ctx, sourceAccount.ID, destAccount.ID, targetBalance, description)
+	var result *database.TransferResult
+	err = s.withInjectedFailures(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.queries.ExecuteSweep(ctx, sourceAccount.ID, destAccount.ID, targetBalance, description)
+		return err
+	})
 	latency := s.elapsed(start)
 
 	if err != nil {
@@ -231,6 +249,7 @@ func (s *CustomerSession) executeAccountSweep() error {
 	}
 
 	sweepAmount := result.NewDestBalance - result.NewSourceBalance
+	s.opAmount = sweepAmount
 	s.recordAuditLog(models.AuditTransactionCompleted, models.OutcomeSuccess, &sourceAccount.ID,
 		fmt.Sprintf("Sweep $%.2f to savings, new balance $%.2f",
 			float64(sweepAmount)/100, float64(result.NewSourceBalance)/100))
@@ -7,7 +7,9 @@
 // KEY FUNCTIONS:
 // - RunOnlineWorkflow: Executes a complete online banking session
 // - viewTransactionHistory: Queries recent transactions
+// - browseTransactionHistory: Pages back through an account's history
 // - executeTransfer: Performs an internal transfer
+// - payBill: Pays one of the customer's beneficiaries
 //
 // RELATED FILES:
 // - state.go: Base session types and authentication
@@ -17,9 +19,12 @@
 package simulator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/willfong/load-generator/internal/database"
 	"github.com/willfong/load-generator/internal/models"
 )
 
@@ -42,16 +47,22 @@ func (s *CustomerSession) RunOnlineWorkflow() {
 
 		if isRead {
 			// Read actions: balance check, transaction history
-			switch s.rng.IntN(2) {
+			switch s.rng.IntN(3) {
 			case 0:
 				s.checkBalance()
 			case 1:
 				s.viewTransactionHistory()
+			case 2:
+				s.browseTransactionHistory()
 			}
 		} else {
-			// Write actions: transfer
+			// Write actions: transfer or bill payment
 			s.State = StateTransacting
-			s.executeTransfer()
+			if s.rng.Float64() < billPayProb {
+				s.payBill()
+			} else {
+				s.executeTransfer()
+			}
 			s.State = StateBrowsing
 		}
 
@@ -63,12 +74,14 @@ func (s *CustomerSession) RunOnlineWorkflow() {
 }
 
 // viewTransactionHistory queries recent transactions
-func (s *CustomerSession) viewTransactionHistory() error {
+func (s *CustomerSession) viewTransactionHistory() (err error) {
+	defer s.beginOp(OpHistoryView)(&err)
 	if len(s.Accounts) == 0 {
 		return fmt.Errorf("no accounts available")
 	}
 
 	account := s.Accounts[s.rng.IntN(len(s.Accounts))]
+	s.opTarget(account.ID, 0)
 	start := s.startTimer()
 
 	// Check for simulated timeout
@@ -81,7 +94,7 @@ func (s *CustomerSession) viewTransactionHistory() error {
 	ctx, cancel := s.timeoutContext(10)
 	defer cancel()
 
-	_, err := s.queries.GetTransactionHistory(ctx, account.ID, 20)
+	_, err = s.queries.GetTransactionHistory(ctx, account.ID, 20, 0)
 	latency := s.elapsed(start)
 
 	if err != nil {
@@ -99,8 +112,66 @@ func (s *CustomerSession) viewTransactionHistory() error {
 	return nil
 }
 
+// History paging: customers scrolling back through an account's history
+const (
+	historyPageSize     = 25
+	historyMaxPages     = 5
+	historyNextPageProb = 0.4 // Chance of loading the next page after each one
+)
+
+// browseTransactionHistory pages back through an account's transactions,
+// stopping at historyMaxPages, a short page, or when the customer loses interest
+func (s *CustomerSession) browseTransactionHistory() (err error) {
+	defer s.beginOp(OpHistoryPage)(&err)
+	if len(s.Accounts) == 0 {
+		return fmt.Errorf("no accounts available")
+	}
+
+	account := s.Accounts[s.rng.IntN(len(s.Accounts))]
+	s.opTarget(account.ID, 0)
+	for page := 0; page < historyMaxPages; page++ {
+		if page > 0 {
+			if !s.rng.Probability(historyNextPageProb) {
+				return nil
+			}
+			s.thinkTime()
+		}
+
+		start := s.startTimer()
+		if s.errorSim.ShouldSimulateTimeout(s.rng) {
+			s.recordAuditLog(models.AuditHistoryViewed, models.OutcomeFailure, &account.ID, "Operation timeout")
+			s.metrics.RecordError(ErrorTypeTimeout)
+			return ErrTimeout
+		}
+
+		ctx, cancel := s.timeoutContext(10)
+		var txns []*models.Transaction
+		txns, err = s.queries.GetTransactionHistory(ctx, account.ID, historyPageSize, page*historyPageSize)
+		cancel()
+		latency := s.elapsed(start)
+
+		if err != nil {
+			if IsInfrastructureError(err) {
+				fmt.Fprintf(os.Stderr, "\nFatal: transaction history page query failed: %v\n", err)
+				os.Exit(1)
+			}
+			s.recordAuditLog(models.AuditHistoryViewed, models.OutcomeFailure, &account.ID, err.Error())
+			s.metrics.RecordError(ClassifyError(err))
+			return err
+		}
+
+		s.recordAuditLog(models.AuditHistoryViewed, models.OutcomeSuccess, &account.ID, "")
+		s.metrics.RecordOperation(OpHistoryPage, false, latency)
+		if len(txns) < historyPageSize {
+			return nil // Reached the start of the account's history
+		}
+	}
+	return nil
+}
+
 // executeTransfer performs an internal transfer
-func (s *CustomerSession) executeTransfer() error {
+func (s *CustomerSession) executeTransfer() (err error) {
+	defer s.beginOp(OpTransfer)(&err)
 	if len(s.Accounts) == 0 {
 		return fmt.Errorf("no accounts available")
 	}
@@ -108,6 +179,10 @@ func (s *CustomerSession) executeTransfer() error {
 	// Source: pick a random account with balance
 	sourceAccount := s.Accounts[s.rng.IntN(len(s.Accounts))]
 
+	// Generate transfer amount; the op log records it even if the transfer fails early
+	amount := s.generateTransferAmount()
+	s.opTarget(sourceAccount.ID, amount)
+
 	// Check for simulated insufficient funds
 	if s.errorSim.ShouldSimulateInsufficientFunds(s.rng) {
 		s.recordAuditLog(models.AuditTransactionDeclined, models.OutcomeDenied, &sourceAccount.ID, "Insufficient funds (simulated)")
@@ -139,9 +214,6 @@ func (s *CustomerSession) executeTransfer() error {
 		}
 	}
 
-	// Generate transfer amount
-	amount := s.generateTransferAmount()
-
 	start := s.startTimer()
 
 	description := fmt.Sprintf("Transfer - Session %s", s.ID[:8])
@@ -150,7 +222,12 @@ func (s *CustomerSession) executeTransfer() error {
 		channel = models.ChannelATM
 	}
 
-	result, err := s.queries.ExecuteTransfer(ctx, sourceAccount.ID, destAccount.ID, amount, description, channel)
+	var result *database.TransferResult
+	err = s.withInjectedFailures(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.queries.ExecuteTransfer(ctx, sourceAccount.ID, destAccount.ID, amount, description, channel)
+		return err
+	})
 	latency := s.elapsed(start)
 
 	if err != nil {
@@ -175,3 +252,70 @@ func (s *CustomerSession) executeTransfer() error {
 	s.metrics.RecordOperation(OpTransfer, true, latency)
 	return nil
 }
+
+// Bill payments: a share of online writes pay a saved beneficiary instead of
+// transferring to a business account
+const (
+	billPayProb      = 0.3
+	billPayMinAmount = 2000  // $20
+	billPayMaxAmount = 50000 // $500
+)
+
+// payBill pays one of the customer's beneficiaries
+func (s *CustomerSession) payBill() (err error) {
+	defer s.beginOp(OpBillPay)(&err)
+	if len(s.Accounts) == 0 {
+		return fmt.Errorf("no accounts available")
+	}
+
+	account := s.Accounts[s.rng.IntN(len(s.Accounts))]
+
+	// Check for simulated insufficient funds
+	if s.errorSim.ShouldSimulateInsufficientFunds(s.rng) {
+		s.recordAuditLog(models.AuditTransactionDeclined, models.OutcomeDenied, &account.ID, "Insufficient funds (simulated)")
+		s.metrics.RecordError(ErrorTypeFunds)
+		return ErrInsufficientFunds
+	}
+
+	amount := s.rng.Int64Range(billPayMinAmount, billPayMaxAmount)
+	s.opTarget(account.ID, amount)
+
+	start := s.startTimer()
+
+	ctx, cancel := s.timeoutContext(10)
+	defer cancel()
+
+	var result *database.BillPaymentResult
+	err = s.withInjectedFailures(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.queries.PayBeneficiary(ctx, s.Customer.ID, account.ID, amount, models.ChannelOnline)
+		return err
+	})
+	latency := s.elapsed(start)
+
+	if errors.Is(err, database.ErrNoBeneficiary) {
+		// Nobody to pay; the customer just moves on
+		return err
+	}
+	if err != nil {
+		errStr := err.Error()
+		if len(errStr) >= 17 && errStr[:17] == "insufficient fund" {
+			s.recordAuditLog(models.AuditTransactionDeclined, models.OutcomeDenied, &account.ID, "Insufficient funds")
+			s.metrics.RecordError(ErrorTypeFunds)
+			return ErrInsufficientFunds
+		}
+		if IsInfrastructureError(err) {
+			fmt.Fprintf(os.Stderr, "\nFatal: bill payment failed: %v\n", err)
+			os.Exit(1)
+		}
+		s.recordAuditLog(models.AuditTransactionFailed, models.OutcomeFailure, &account.ID, err.Error())
+		s.metrics.RecordError(ClassifyError(err))
+		return err
+	}
+
+	s.recordAuditLog(models.AuditTransactionCompleted, models.OutcomeSuccess, &account.ID,
+		fmt.Sprintf("Bill payment $%.2f to beneficiary %d, txn=%d",
+			float64(amount)/100, result.BeneficiaryID, result.TransactionID))
+	s.metrics.RecordOperation(OpBillPay, true, latency)
+	return nil
+}
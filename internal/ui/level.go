@@ -0,0 +1,76 @@
+package ui
+
+import "fmt"
+
+// Level controls how much the UI prints. Summaries and errors are always shown.
+type Level int
+
+const (
+	LevelQuiet   Level = iota // Final summaries and errors only
+	LevelNormal               // Progress, spinners and settings
+	LevelVerbose              // Per-step details
+	LevelDebug                // Statements and internals
+)
+
+var levelNames = []string{"quiet", "normal", "verbose", "debug"}
+
+// String returns the level's flag name.
+func (l Level) String() string {
+	if l < LevelQuiet || int(l) >= len(levelNames) {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses a --log-level value.
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if s == name {
+			return Level(i), nil
+		}
+	}
+	return LevelNormal, fmt.Errorf("invalid log level %q (expected quiet, normal, verbose or debug)", s)
+}
+
+// SetLevel sets how much the UI prints.
+func (u *UI) SetLevel(level Level) {
+	u.Level = level
+}
+
+// Enabled reports whether messages at level are printed.
+func (u *UI) Enabled(level Level) bool {
+	return u.Level >= level
+}
+
+// Quiet reports whether only summaries and errors are printed.
+func (u *UI) Quiet() bool {
+	return u.Level <= LevelQuiet
+}
+
+// Printf prints a progress message unless quiet.
+func (u *UI) Printf(format string, args ...any) {
+	if u.Enabled(LevelNormal) {
+		fmt.Printf(format, args...)
+	}
+}
+
+// Println prints a progress message unless quiet.
+func (u *UI) Println(args ...any) {
+	if u.Enabled(LevelNormal) {
+		fmt.Println(args...)
+	}
+}
+
+// Verbosef prints a message at the verbose level or above.
+func (u *UI) Verbosef(format string, args ...any) {
+	if u.Enabled(LevelVerbose) {
+		fmt.Printf(format, args...)
+	}
+}
+
+// Debugf prints a message at the debug level.
+func (u *UI) Debugf(format string, args ...any) {
+	if u.Enabled(LevelDebug) {
+		fmt.Printf(format, args...)
+	}
+}
@@ -400,6 +400,9 @@ func (p *IndexProgressDisplay) Update(current int) {
 	p.current = current
 	p.mu.Unlock()
 
+	if p.ui.Quiet() {
+		return
+	}
 	if !p.ui.shouldStyle() {
 		// For non-TTY, use carriage return like before
 		fmt.Printf("  [%d/%d] Creating index/constraint...\r", current, p.total)
@@ -424,6 +427,9 @@ func (p *IndexProgressDisplay) Update(current int) {
 
 // Complete finishes with success.
 func (p *IndexProgressDisplay) Complete() {
+	if p.ui.Quiet() {
+		return
+	}
 	if !p.ui.shouldStyle() {
 		fmt.Println()
 		return
@@ -454,8 +460,12 @@ func FormatBytes(b int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
-// PrintTableLoadResult prints a table load result line.
+// PrintTableLoadResult prints a table load result line. Failures are printed
+// even when quiet.
 func (u *UI) PrintTableLoadResult(name string, rows int64, duration time.Duration, shards int, err error) {
+	if err == nil && u.Quiet() {
+		return
+	}
 	if !u.shouldStyle() {
 		if err != nil {
 			fmt.Printf("  %-15s FAILED\n", name+":")
@@ -502,6 +512,9 @@ func formatRowCount(rows int64) string {
 
 // PrintShardLoading prints a "loading N shards" message.
 func (u *UI) PrintShardLoading(name string, shardCount int) {
+	if u.Quiet() {
+		return
+	}
 	if !u.shouldStyle() {
 		fmt.Printf("  %-15s loading %d shards...\n", name+":", shardCount)
 		return
@@ -517,6 +530,9 @@ func (u *UI) PrintShardLoading(name string, shardCount int) {
 
 // Section prints a section header.
 func (u *UI) Section(title string) {
+	if u.Quiet() {
+		return
+	}
 	if !u.shouldStyle() {
 		fmt.Printf("\n%s\n", title)
 		return
@@ -525,13 +541,16 @@ func (u *UI) Section(title string) {
 	fmt.Printf("\n%s\n", lipgloss.NewStyle().Bold(true).Render(title))
 }
 
-// Print prints a regular message.
+// Print prints a regular message unless quiet.
 func (u *UI) Print(msg string) {
-	fmt.Println(msg)
+	u.Println(msg)
 }
 
 // PrintSkipped prints a skipped message.
 func (u *UI) PrintSkipped(name string, reason string) {
+	if u.Quiet() {
+		return
+	}
 	if !u.shouldStyle() {
 		fmt.Printf("  %-15s SKIPPED (%s)\n", name+":", reason)
 		return
@@ -55,6 +55,9 @@ func (p *ProgressBar) render() {
 	total := p.total
 	p.mu.Unlock()
 
+	if p.ui.Quiet() {
+		return
+	}
 	if !p.ui.shouldStyle() {
 		// Non-TTY: print progress updates periodically
 		if !p.rendered {
@@ -86,6 +89,9 @@ func (p *ProgressBar) Complete() {
 	total := p.total
 	p.mu.Unlock()
 
+	if p.ui.Quiet() {
+		return
+	}
 	if !p.ui.shouldStyle() {
 		fmt.Printf("%d/%d done\n", current, total)
 		return
@@ -41,6 +41,9 @@ func (s *Spinner) Start() {
 	s.started = true
 	s.mu.Unlock()
 
+	if s.ui.Quiet() {
+		return
+	}
 	if !s.ui.shouldStyle() {
 		// Non-TTY: just print the message once
 		fmt.Printf("%s...", s.label)
@@ -83,7 +86,7 @@ func (s *Spinner) Stop() {
 	close(s.done)
 	s.wg.Wait()
 
-	if s.ui.shouldStyle() {
+	if s.ui.shouldStyle() && !s.ui.Quiet() {
 		// Clear the line
 		fmt.Fprint(os.Stdout, "\r\033[K")
 	}
@@ -106,6 +109,9 @@ func (s *Spinner) Success(msg string) {
 	}
 	s.wg.Wait()
 
+	if s.ui.Quiet() {
+		return
+	}
 	if !s.ui.shouldStyle() {
 		fmt.Printf(" %s\n", msg)
 		return
@@ -135,6 +141,11 @@ func (s *Spinner) Error(msg string) {
 	}
 	s.wg.Wait()
 
+	// Quiet spinners never printed their label
+	if s.ui.Quiet() {
+		fmt.Fprintln(os.Stderr, s.ui.Error(s.label+": "+msg))
+		return
+	}
 	if !s.ui.shouldStyle() {
 		fmt.Printf(" %s\n", msg)
 		return
@@ -17,6 +17,7 @@ type UI struct {
 	IsTTY   bool
 	Width   int
 	NoColor bool
+	Level   Level
 }
 
 // KV represents a key-value pair for summary displays.
@@ -42,6 +43,7 @@ func New() *UI {
 		IsTTY:   isTTY,
 		Width:   width,
 		NoColor: noColorEnv,
+		Level:   LevelNormal,
 	}
 }
 
@@ -0,0 +1,63 @@
+package utils
+
+import "strconv"
+
+// GenerateLuhnNumber returns a numeric string of the given length that starts
+// with prefix, fills the middle with random digits and ends in a Luhn check
+// digit. The prefix is returned unchanged if it leaves no room for one.
+func (r *Random) GenerateLuhnNumber(prefix string, length int) string {
+	if len(prefix) >= length {
+		return prefix
+	}
+	body := prefix + r.NumericString(length-len(prefix)-1)
+	return body + strconv.Itoa(LuhnCheckDigit(body))
+}
+
+// CardNumber returns a Luhn-valid 16-digit card number in a real network's
+// BIN range: Visa (4), or Mastercard (51-55 or 2221-2720)
+func (r *Random) CardNumber() string {
+	var prefix string
+	switch r.IntN(3) {
+	case 0, 1:
+		prefix = "4"
+	default:
+		if r.Bool() {
+			prefix = strconv.Itoa(r.IntRange(51, 55))
+		} else {
+			prefix = strconv.Itoa(r.IntRange(2221, 2720))
+		}
+	}
+	return r.GenerateLuhnNumber(prefix, 16)
+}
+
+// LuhnCheckDigit returns the digit that makes body followed by it pass the
+// Luhn check
+func LuhnCheckDigit(body string) int {
+	sum := 0
+	// Double every second digit from the right, starting with the last digit
+	// of body (the check digit's neighbour)
+	for i := len(body) - 1; i >= 0; i -= 2 {
+		d := int(body[i]-'0') * 2
+		if d > 9 {
+			d -= 9
+		}
+		sum += d
+		if i > 0 {
+			sum += int(body[i-1] - '0')
+		}
+	}
+	return (10 - sum%10) % 10
+}
+
+// LuhnValid reports whether a numeric string passes the Luhn check
+func LuhnValid(number string) bool {
+	if len(number) < 2 {
+		return false
+	}
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return LuhnCheckDigit(number[:len(number)-1]) == int(number[len(number)-1]-'0')
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLuhnValid(t *testing.T) {
+	for _, number := range []string{"4111111111111111", "5555555555554444", "2223003122003222", "79927398713"} {
+		if !LuhnValid(number) {
+			t.Errorf("Expected %s to pass the Luhn check", number)
+		}
+	}
+	for _, number := range []string{"4111111111111112", "79927398710", "4111-1111", ""} {
+		if LuhnValid(number) {
+			t.Errorf("Expected %q to fail the Luhn check", number)
+		}
+	}
+}
+
+func TestGenerateLuhnNumber(t *testing.T) {
+	rng := NewRandom(42)
+	for i := 0; i < 1000; i++ {
+		n := rng.GenerateLuhnNumber("400000", 16)
+		if len(n) != 16 || !strings.HasPrefix(n, "400000") || !LuhnValid(n) {
+			t.Fatalf("Invalid number %s", n)
+		}
+	}
+}
+
+func TestCardNumber(t *testing.T) {
+	rng := NewRandom(42)
+	networks := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		n := rng.CardNumber()
+		if len(n) != 16 || !LuhnValid(n) {
+			t.Fatalf("Invalid card number %s", n)
+		}
+		two, _ := strconv.Atoi(n[:2])
+		four, _ := strconv.Atoi(n[:4])
+		switch {
+		case n[0] == '4':
+			networks["visa"]++
+		case two >= 51 && two <= 55, four >= 2221 && four <= 2720:
+			networks["mastercard"]++
+		default:
+			t.Fatalf("Card number %s is outside the Visa and Mastercard BIN ranges", n)
+		}
+	}
+	if networks["visa"] == 0 || networks["mastercard"] == 0 {
+		t.Errorf("Expected both networks, got %v", networks)
+	}
+}
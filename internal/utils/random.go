@@ -3,6 +3,7 @@ package utils
 import (
 	crand "crypto/rand"
 	"encoding/binary"
+	"hash/fnv"
 	"math/rand/v2"
 	"sync"
 	"time"
@@ -13,10 +14,21 @@ import (
 // reproducible given the same seed.
 type Random struct {
 	rng  *rand.Rand
+	src  *rand.PCG
 	seed uint64
 	mu   sync.Mutex
 }
 
+// newPCGRandom creates a Random drawing from a PCG source with the given state
+func newPCGRandom(seed, seq uint64) *Random {
+	src := rand.NewPCG(seed, seq)
+	return &Random{
+		rng:  rand.New(src),
+		src:  src,
+		seed: seed,
+	}
+}
+
 // NewRandom creates a new Random instance with the given seed.
 // If seed is 0, a cryptographically random seed is generated.
 func NewRandom(seed int64) *Random {
@@ -27,9 +39,21 @@ func NewRandom(seed int64) *Random {
 		actualSeed = uint64(seed)
 	}
 
-	return &Random{
-		rng:  rand.New(rand.NewPCG(actualSeed, actualSeed^0xDEADBEEF)),
-		seed: actualSeed,
+	return newPCGRandom(actualSeed, actualSeed^0xDEADBEEF)
+}
+
+// ResolveSeed returns the seed a run should actually use. A non-zero seed is
+// returned unchanged; seed 0 is replaced with a random positive seed so the
+// run can be reported and reproduced later with --seed.
+func ResolveSeed(seed int64) int64 {
+	if seed != 0 {
+		return seed
+	}
+	for {
+		// Keep the result positive so it round-trips through the CLI flag
+		if s := int64(generateRandomSeed() >> 1); s != 0 {
+			return s
+		}
 	}
 }
 
@@ -57,9 +81,36 @@ func (r *Random) Fork() *Random {
 
 	// Generate a new seed from the current RNG
 	newSeed := r.rng.Uint64()
+	return newPCGRandom(newSeed, newSeed^0xCAFEBABE)
+}
+
+// Derive creates a Random seeded from this RNG's seed and a name alone, so a
+// named stream comes out the same however much of this RNG has been consumed
+// and whichever other streams were derived first. Useful for giving each
+// generation phase its own stream, so one phase can be regenerated on its own.
+func (r *Random) Derive(name string) *Random {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], r.seed)
+	h := fnv.New64a()
+	h.Write(b[:])
+	h.Write([]byte(name))
+
+	newSeed := h.Sum64()
+	return newPCGRandom(newSeed, newSeed^0x5EEDF00D)
+}
+
+// Clone creates a Random that continues from the current state, so it and r
+// produce the same sequence from here on. Useful for giving several workers
+// identical streams.
+func (r *Random) Clone() *Random {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	src := *r.src
 	return &Random{
-		rng:  rand.New(rand.NewPCG(newSeed, newSeed^0xCAFEBABE)),
-		seed: newSeed,
+		rng:  rand.New(&src),
+		src:  &src,
+		seed: r.seed,
 	}
 }
 
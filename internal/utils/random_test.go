@@ -79,6 +79,31 @@ func TestRandomSeedStorage(t *testing.T) {
 	}
 }
 
+func TestResolveSeed(t *testing.T) {
+	// Explicit seeds pass through unchanged
+	if got := ResolveSeed(12345); got != 12345 {
+		t.Errorf("Expected seed 12345, got %d", got)
+	}
+
+	// Seed 0 resolves to a positive seed
+	resolved := ResolveSeed(0)
+	if resolved <= 0 {
+		t.Fatalf("Expected positive resolved seed, got %d", resolved)
+	}
+
+	// A resolved seed must reproduce the same forked streams
+	forks1 := NewRandom(resolved).ForkN(3)
+	forks2 := NewRandom(resolved).ForkN(3)
+	for i := range forks1 {
+		for j := 0; j < 100; j++ {
+			if forks1[i].IntN(1000) != forks2[i].IntN(1000) {
+				t.Errorf("Fork %d sequences don't match for resolved seed %d", i, resolved)
+				return
+			}
+		}
+	}
+}
+
 func TestRandomFork(t *testing.T) {
 	seed := int64(42)
 	rng1 := NewRandom(seed)
@@ -122,6 +147,45 @@ func TestRandomForkN(t *testing.T) {
 	}
 }
 
+func TestRandomDerive(t *testing.T) {
+	rng1 := NewRandom(42)
+	rng2 := NewRandom(42)
+
+	// Consuming the parent or deriving in another order changes nothing
+	rng1.IntN(1000)
+	rng1.Fork()
+	customers1 := rng1.Derive("customers")
+	rng2.Derive("branches")
+	customers2 := rng2.Derive("customers")
+	for i := 0; i < 100; i++ {
+		if a, b := customers1.IntN(1000), customers2.IntN(1000); a != b {
+			t.Fatalf("Derived sequences diverged at iteration %d: %d != %d", i, a, b)
+		}
+	}
+
+	if NewRandom(42).Derive("customers").Seed() == NewRandom(42).Derive("accounts").Seed() {
+		t.Error("Different names derived the same seed")
+	}
+	if NewRandom(42).Derive("customers").Seed() == NewRandom(43).Derive("customers").Seed() {
+		t.Error("Different seeds derived the same seed")
+	}
+}
+
+func TestRandomClone(t *testing.T) {
+	rng := NewRandom(42)
+	rng.IntN(1000) // Clone mid-sequence
+
+	clone := rng.Clone()
+	for i := 0; i < 100; i++ {
+		if a, b := rng.IntN(1000), clone.IntN(1000); a != b {
+			t.Fatalf("Clone diverged at iteration %d: %d != %d", i, a, b)
+		}
+	}
+	if rng.Fork().Seed() != clone.Fork().Seed() {
+		t.Error("Clone forks have different seeds")
+	}
+}
+
 func TestRandomRanges(t *testing.T) {
 	rng := NewRandom(42)
 
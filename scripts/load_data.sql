@@ -78,7 +78,8 @@ LINES TERMINATED BY '\n'
 IGNORE 1 LINES
 (id, first_name, last_name, email, @phone, @date_of_birth, @address_line1, @address_line2,
  @city, @state, @postal_code, country, timezone, @home_branch_id, segment, status,
- activity_score, username, password_hash, pin, created_at, updated_at)
+ activity_score, username, password_hash, pin, created_at, updated_at,
+ @kyc_status, @kyc_document_type, @kyc_verified_at)
 SET
     phone = NULLIF(@phone, ''),
     date_of_birth = NULLIF(@date_of_birth, ''),
@@ -87,7 +88,10 @@ SET
     city = NULLIF(@city, ''),
     state = NULLIF(@state, ''),
     postal_code = NULLIF(@postal_code, ''),
-    home_branch_id = NULLIF(@home_branch_id, '');
+    home_branch_id = NULLIF(@home_branch_id, ''),
+    kyc_status = NULLIF(@kyc_status, ''),
+    kyc_document_type = NULLIF(@kyc_document_type, ''),
+    kyc_verified_at = NULLIF(@kyc_verified_at, '');
 
 SELECT 'Customers loaded' AS status, COUNT(*) AS count FROM customers;
 
@@ -143,6 +147,9 @@ SELECT 'Beneficiaries loaded' AS status, COUNT(*) AS count FROM beneficiaries;
 -- ============================================
 -- LOAD TRANSACTIONS
 -- ============================================
+-- Output generated with --geo-coordinates has two more columns: append
+-- ", @latitude, @longitude" to the column list and set
+-- latitude = NULLIF(@latitude, ''), longitude = NULLIF(@longitude, '')
 LOAD DATA LOCAL INFILE 'transactions.csv'
 INTO TABLE transactions
 FIELDS TERMINATED BY ','